@@ -19,6 +19,7 @@ import (
 
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/controller/api"
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
 	"scroll-tech/bridge-history-api/internal/route"
 )
 
@@ -49,6 +50,13 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to init db", "err", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
 	defer func() {
 		if deferErr := database.CloseDB(db); deferErr != nil {
 			log.Error("failed to close db", "err", err)
@@ -59,6 +59,32 @@ func init() {
 				},
 			},
 		},
+		{
+			Name:   "export-snapshot",
+			Usage:  "Export a snapshot of the database, the fetchers of a new deployment are bootstrapped from it.",
+			Action: exportSnapshot,
+			Flags: []cli.Flag{
+				&utils.ConfigFileFlag,
+				&cli.StringFlag{
+					Name:     "output",
+					Usage:    "File the snapshot is written to.",
+					Required: true,
+				},
+			},
+		},
+		{
+			Name:   "import-snapshot",
+			Usage:  "Import a snapshot into the empty database.",
+			Action: importSnapshot,
+			Flags: []cli.Flag{
+				&utils.ConfigFileFlag,
+				&cli.StringFlag{
+					Name:     "source",
+					Usage:    "File path or http(s) url of the snapshot.",
+					Required: true,
+				},
+			},
+		},
 	}
 }
 
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 	"gorm.io/gorm"
@@ -9,6 +13,7 @@ import (
 	"scroll-tech/common/utils"
 
 	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/logic"
 	"scroll-tech/bridge-history-api/internal/orm/migrate"
 )
 
@@ -118,3 +123,53 @@ func rollbackDB(ctx *cli.Context) error {
 	version := ctx.Int64("version")
 	return migrate.Rollback(db, &version)
 }
+
+// exportSnapshot export a snapshot of the database
+func exportSnapshot(ctx *cli.Context) error {
+	cfg, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+	gormDB, err := initDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	output := ctx.String("output")
+	f, err := os.Create(filepath.Clean(output))
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file %v: %w", output, err)
+	}
+	defer f.Close()
+
+	header, err := logic.NewSnapshotLogic(gormDB).Export(ctx.Context, f)
+	if err != nil {
+		return err
+	}
+	log.Info("exported snapshot", "output", output, "db version", header.DBVersion, "L1 sync height", header.L1SyncHeight, "L2 sync height", header.L2SyncHeight)
+	return nil
+}
+
+// importSnapshot import a snapshot into the empty database
+func importSnapshot(ctx *cli.Context) error {
+	cfg, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+	gormDB, err := initDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	source := ctx.String("source")
+	r, err := logic.OpenSnapshot(ctx.Context, source)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot %v: %w", source, err)
+	}
+	defer r.Close()
+
+	header, err := logic.NewSnapshotLogic(gormDB).Import(ctx.Context, r)
+	if err != nil {
+		return err
+	}
+	log.Info("imported snapshot", "source", source, "created at", header.CreatedAt, "L1 sync height", header.L1SyncHeight, "L2 sync height", header.L2SyncHeight)
+	return nil
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"scroll-tech/common/database"
 	"scroll-tech/common/observability"
@@ -16,10 +18,17 @@ import (
 
 	"scroll-tech/bridge-history-api/internal/config"
 	"scroll-tech/bridge-history-api/internal/controller/fetcher"
+	"scroll-tech/bridge-history-api/internal/logic"
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
 )
 
 var app *cli.App
 
+var fastSyncSnapshotFlag = cli.StringFlag{
+	Name:  "fast-sync-snapshot",
+	Usage: "File path or http(s) url of the snapshot the empty database is bootstrapped from before syncing the events after it",
+}
+
 func init() {
 	app = cli.NewApp()
 
@@ -27,6 +36,7 @@ func init() {
 	app.Name = "Scroll Bridge History API Message Fetcher"
 	app.Usage = "The Scroll Bridge History API Message Fetcher"
 	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &fastSyncSnapshotFlag)
 	app.Commands = []*cli.Command{}
 
 	app.Before = func(ctx *cli.Context) error {
@@ -57,6 +67,13 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to init db", "err", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
 	defer func() {
 		if deferErr := database.CloseDB(db); deferErr != nil {
 			log.Error("failed to close db", "err", err)
@@ -66,12 +83,18 @@ func action(ctx *cli.Context) error {
 		log.Crit("failed to connect to db", "config file", cfgFile, "error", err)
 	}
 
+	if source := ctx.String(fastSyncSnapshotFlag.Name); source != "" {
+		fastSync(subCtx, db, source)
+	}
+
 	observability.Server(ctx, db)
 
 	l1MessageFetcher := fetcher.NewL1MessageFetcher(subCtx, cfg.L1, db, l1Client)
+	observability.RegisterStateDumper("l1_message_fetcher", l1MessageFetcher.DebugState)
 	go l1MessageFetcher.Start()
 
 	l2MessageFetcher := fetcher.NewL2MessageFetcher(subCtx, cfg.L2, db, l2Client)
+	observability.RegisterStateDumper("l2_message_fetcher", l2MessageFetcher.DebugState)
 	go l2MessageFetcher.Start()
 
 	// Catch CTRL-C to ensure a graceful shutdown.
@@ -84,6 +107,26 @@ func action(ctx *cli.Context) error {
 	return nil
 }
 
+// fastSync bootstraps the empty database from the snapshot, a database synced already is kept.
+func fastSync(ctx context.Context, db *gorm.DB, source string) {
+	r, err := logic.OpenSnapshot(ctx, source)
+	if err != nil {
+		log.Crit("failed to open fast sync snapshot", "source", source, "err", err)
+	}
+	defer r.Close()
+
+	header, err := logic.NewSnapshotLogic(db).Import(ctx, r)
+	if errors.Is(err, logic.ErrSnapshotDBNotEmpty) {
+		log.Info("skip fast sync, the database is synced already", "reason", err)
+		return
+	}
+	if err != nil {
+		log.Crit("failed to import fast sync snapshot", "source", source, "err", err)
+	}
+	log.Info("fast synced from snapshot", "source", source, "created at", header.CreatedAt,
+		"L1 sync height", header.L1SyncHeight, "L2 sync height", header.L2SyncHeight)
+}
+
 // Run event watcher cmd instance.
 func Run() {
 	if err := app.Run(os.Args); err != nil {
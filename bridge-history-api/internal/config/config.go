@@ -28,6 +28,35 @@ type FetcherConfig struct {
 	ScrollChainAddr          string `json:"ScrollChainAddr"`
 	GatewayRouterAddr        string `json:"GatewayRouterAddr"`
 	MessageQueueAddr         string `json:"MessageQueueAddr"`
+	// WithdrawTrieSnapshotInterval is the number of L2 withdrawals between two persisted withdraw trie snapshots,
+	// only used by the L2 fetcher, 0 disables the snapshots.
+	WithdrawTrieSnapshotInterval uint64 `json:"withdrawTrieSnapshotInterval,omitempty"`
+	// DepositLimits are the deposit limits enforced by the L1 fetcher, nil disables them.
+	DepositLimits *DepositLimitConfig `json:"depositLimits,omitempty"`
+}
+
+// DepositLimitConfig is the configuration of the deposit limit policy of the L1 fetcher.
+type DepositLimitConfig struct {
+	// WindowSec is the length of the sliding window the deposit volumes are accumulated over.
+	WindowSec uint64 `json:"windowSec"`
+	// Tokens are the limits per L1 token.
+	Tokens []*TokenDepositLimit `json:"tokens"`
+	// PauseWebhook is the endpoint notified of the exceeded limits whose action is "pause", e.g. a guardian service
+	// holding the pauser role of the gateways. Without it these limits are only flagged.
+	PauseWebhook string `json:"pauseWebhook,omitempty"`
+}
+
+// TokenDepositLimit is the deposit limit of an ETH or ERC20 token, the volumes are decimal amounts in the
+// smallest unit of the token and an empty volume is not limited.
+type TokenDepositLimit struct {
+	// Token is the L1 token address, the zero address stands for ETH.
+	Token string `json:"token"`
+	// MaxVolume is the maximum volume deposited by all addresses in the window.
+	MaxVolume string `json:"maxVolume,omitempty"`
+	// MaxAddressVolume is the maximum volume deposited by a single address in the window.
+	MaxAddressVolume string `json:"maxAddressVolume,omitempty"`
+	// Action is taken once a limit is exceeded, "flag" (the default) or "pause".
+	Action string `json:"action,omitempty"`
 }
 
 // RedisConfig redis config
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
@@ -92,3 +94,42 @@ func (c *HistoryController) PostQueryTxsByHashes(ctx *gin.Context) {
 	resultData := &types.ResultData{Results: results, Total: uint64(len(results))}
 	types.RenderSuccess(ctx, resultData)
 }
+
+// GetL2WithdrawalProofs defines the http get method behavior of the withdrawal proofs export
+func (c *HistoryController) GetL2WithdrawalProofs(ctx *gin.Context) {
+	var req types.QueryWithdrawalProofsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+
+	if req.BatchIndex == nil && (req.EndTime == 0 || req.StartTime > req.EndTime) {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, errors.New("either batch_index or a valid start_time and end_time must be given"))
+		return
+	}
+
+	proofs, total, err := c.historyLogic.GetL2WithdrawalProofs(ctx, &req)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetWithdrawalProofsError, err)
+		return
+	}
+
+	resultData := &types.WithdrawalProofsResultData{Results: proofs, Total: total}
+	types.RenderCompressedJSON(ctx, types.Success, nil, resultData)
+}
+
+// GetL2MessageProof defines the http get method behavior of the merkle proof of an L2 withdrawal
+func (c *HistoryController) GetL2MessageProof(ctx *gin.Context) {
+	var req types.QueryMessageProofRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+
+	proof, err := c.historyLogic.GetMessageProof(ctx, req.MessageHash, *req.Nonce)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrGetMessageProofError, err)
+		return
+	}
+	types.RenderSuccess(ctx, proof)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/docker"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+	"scroll-tech/bridge-history-api/internal/types"
+)
+
+var (
+	base *docker.App
+
+	db *gorm.DB
+)
+
+func TestMain(m *testing.M) {
+	t := &testing.T{}
+	base = docker.NewDockerApp()
+	base.RunDBImage(t)
+	var err error
+	db, err = database.InitDB(
+		&database.Config{
+			DSN:        base.DBConfig.DSN,
+			DriverName: base.DBConfig.DriverName,
+			MaxOpenNum: base.DBConfig.MaxOpenNum,
+			MaxIdleNum: base.DBConfig.MaxIdleNum,
+		},
+	)
+	if err != nil {
+		base.Free()
+		fmt.Println("failed to init db", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	_ = database.CloseDB(db)
+	base.Free()
+	os.Exit(code)
+}
+
+// withdrawalProofsResponse is the response of the withdrawal proofs export api with the typed data.
+type withdrawalProofsResponse struct {
+	ErrCode int                              `json:"errcode"`
+	ErrMsg  string                           `json:"errmsg"`
+	Data    types.WithdrawalProofsResultData `json:"data"`
+}
+
+func TestGetL2WithdrawalProofs(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	var messages []*orm.CrossMessage
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		messages = append(messages, &orm.CrossMessage{
+			MessageType:    int(orm.MessageTypeL2SentMessage),
+			RollupStatus:   int(orm.RollupStatusTypeFinalized),
+			MessageHash:    fmt.Sprintf("0x%064x", nonce),
+			L2TxHash:       fmt.Sprintf("0x%064x", nonce+1000),
+			TokenAmounts:   "1",
+			BlockTimestamp: 100 + nonce,
+			MessageFrom:    "0x0000000000000000000000000000000000000001",
+			MessageTo:      "0x0000000000000000000000000000000000000002",
+			MessageValue:   "1",
+			MessageNonce:   nonce,
+			MessageData:    "0x",
+			MerkleProof:    []byte{byte(nonce)},
+			BatchIndex:     1,
+		})
+	}
+	assert.NoError(t, orm.NewCrossMessage(db).InsertOrUpdateL2Messages(context.Background(), messages))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/l2/withdrawals/proofs", NewHistoryController(db, nil).GetL2WithdrawalProofs)
+
+	tests := []struct {
+		name         string
+		query        string
+		gzip         bool
+		expectCode   int
+		expectNonces []string
+		expectTotal  uint64
+	}{
+		{name: "MissingPage", query: "batch_index=1&page_size=2", expectCode: types.ErrParameterInvalidNo},
+		{name: "PageSizeTooLarge", query: "batch_index=1&page=1&page_size=1001", expectCode: types.ErrParameterInvalidNo},
+		{name: "MissingRange", query: "page=1&page_size=2", expectCode: types.ErrParameterInvalidNo},
+		{name: "InvalidTimeRange", query: "start_time=102&end_time=101&page=1&page_size=2", expectCode: types.ErrParameterInvalidNo},
+		{name: "FirstPage", query: "batch_index=1&page=1&page_size=2", expectCode: types.Success, expectNonces: []string{"0", "1"}, expectTotal: 3},
+		{name: "LastPage", query: "batch_index=1&page=2&page_size=2", expectCode: types.Success, expectNonces: []string{"2"}, expectTotal: 3},
+		{name: "PageAfterLast", query: "batch_index=1&page=3&page_size=2", expectCode: types.Success, expectNonces: []string{}, expectTotal: 3},
+		{name: "EmptyBatch", query: "batch_index=2&page=1&page_size=2", expectCode: types.Success, expectNonces: []string{}, expectTotal: 0},
+		{name: "TimeRange", query: "start_time=101&end_time=102&page=1&page_size=2", expectCode: types.Success, expectNonces: []string{"1", "2"}, expectTotal: 2},
+		{name: "Gzip", query: "batch_index=1&page=1&page_size=3", gzip: true, expectCode: types.Success, expectNonces: []string{"0", "1", "2"}, expectTotal: 3},
+		{name: "GzipEmptyBatch", query: "batch_index=2&page=1&page_size=3", gzip: true, expectCode: types.Success, expectNonces: []string{}, expectTotal: 0},
+		{name: "GzipInvalidParameter", query: "page=1&page_size=3", gzip: true, expectCode: types.ErrParameterInvalidNo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/l2/withdrawals/proofs?"+tt.query, nil)
+			if tt.gzip {
+				req.Header.Set("Accept-Encoding", "gzip, deflate")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			// only the successful exports are compressed
+			var body io.Reader = w.Body
+			if tt.gzip && tt.expectCode == types.Success {
+				assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+				gz, err := gzip.NewReader(w.Body)
+				assert.NoError(t, err)
+				defer gz.Close()
+				body = gz
+			} else {
+				assert.Empty(t, w.Header().Get("Content-Encoding"))
+			}
+
+			var resp withdrawalProofsResponse
+			assert.NoError(t, json.NewDecoder(body).Decode(&resp))
+			assert.Equal(t, tt.expectCode, resp.ErrCode, resp.ErrMsg)
+			if tt.expectCode != types.Success {
+				return
+			}
+			assert.Equal(t, tt.expectTotal, resp.Data.Total)
+			nonces := make([]string, 0, len(resp.Data.Results))
+			for _, proof := range resp.Data.Results {
+				nonces = append(nonces, proof.Nonce)
+				assert.Equal(t, "1", proof.Proof.BatchIndex)
+			}
+			assert.Equal(t, tt.expectNonces, nonces)
+		})
+	}
+
+	// the exported proof carries the data needed to relay the withdrawal
+	req := httptest.NewRequest(http.MethodGet, "/api/l2/withdrawals/proofs?batch_index=1&page=1&page_size=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var resp withdrawalProofsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data.Results, 1)
+	assert.Equal(t, &types.WithdrawalProof{
+		MessageHash: messages[0].MessageHash,
+		L2TxHash:    messages[0].L2TxHash,
+		From:        messages[0].MessageFrom,
+		To:          messages[0].MessageTo,
+		Value:       messages[0].MessageValue,
+		Nonce:       "0",
+		Message:     messages[0].MessageData,
+		Proof:       types.L2MessageProof{BatchIndex: "1", MerkleProof: "0x00"},
+	}, resp.Data.Results[0])
+}
@@ -3,6 +3,7 @@ package fetcher
 import (
 	"context"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -23,11 +24,12 @@ type L1MessageFetcher struct {
 	cfg    *config.FetcherConfig
 	client *ethclient.Client
 
-	l1SyncHeight        uint64
+	l1SyncHeight        atomic.Uint64
 	l1LastSyncBlockHash common.Hash
 
 	eventUpdateLogic *logic.EventUpdateLogic
 	l1FetcherLogic   *logic.L1FetcherLogic
+	depositPolicy    *logic.DepositPolicy
 
 	l1MessageFetcherRunningTotal prometheus.Counter
 	l1MessageFetcherReorgTotal   prometheus.Counter
@@ -45,6 +47,14 @@ func NewL1MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gor
 	}
 
 	reg := prometheus.DefaultRegisterer
+	if cfg.DepositLimits != nil {
+		depositPolicy, err := logic.NewDepositPolicy(cfg.DepositLimits, db, reg)
+		if err != nil {
+			log.Crit("invalid L1 deposit limits", "err", err)
+		}
+		c.depositPolicy = depositPolicy
+	}
+
 	c.l1MessageFetcherRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
 		Name: "L1_message_fetcher_running_total",
 		Help: "Current count of running L1 message fetcher instances.",
@@ -91,7 +101,19 @@ func (c *L1MessageFetcher) Start() {
 
 	c.updateL1SyncHeight(l1SyncHeight, header.Hash())
 
-	log.Info("Start L1 message fetcher", "message synced height", messageSyncedHeight, "batch synced height", batchSyncedHeight, "config start height", c.cfg.StartHeight, "sync start height", c.l1SyncHeight+1)
+	if c.depositPolicy != nil {
+		// The window spans at most this many blocks, empty slots only make the blocks sparser.
+		windowBlocks := c.cfg.DepositLimits.WindowSec / uint64(c.cfg.BlockTime)
+		var startBlock uint64
+		if l1SyncHeight > windowBlocks {
+			startBlock = l1SyncHeight - windowBlocks
+		}
+		if err := c.depositPolicy.Load(c.ctx, startBlock); err != nil {
+			log.Crit("failed to load the deposits of the deposit limit window", "err", err)
+		}
+	}
+
+	log.Info("Start L1 message fetcher", "message synced height", messageSyncedHeight, "batch synced height", batchSyncedHeight, "config start height", c.cfg.StartHeight, "sync start height", c.l1SyncHeight.Load()+1)
 
 	tick := time.NewTicker(time.Duration(c.cfg.BlockTime) * time.Second)
 	go func() {
@@ -108,7 +130,7 @@ func (c *L1MessageFetcher) Start() {
 }
 
 func (c *L1MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
-	startHeight := c.l1SyncHeight + 1
+	startHeight := c.l1SyncHeight.Load() + 1
 	endHeight, rpcErr := utils.GetBlockNumber(c.ctx, c.client, confirmation)
 	if rpcErr != nil {
 		log.Error("failed to get L1 block number", "confirmation", confirmation, "err", rpcErr)
@@ -142,6 +164,10 @@ func (c *L1MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
 			return
 		}
 
+		if c.depositPolicy != nil {
+			c.depositPolicy.Observe(c.ctx, l1FetcherResult.DepositMessages)
+		}
+
 		c.updateL1SyncHeight(to, lastBlockHash)
 		c.l1MessageFetcherRunningTotal.Inc()
 	}
@@ -150,5 +176,14 @@ func (c *L1MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
 func (c *L1MessageFetcher) updateL1SyncHeight(height uint64, blockHash common.Hash) {
 	c.l1MessageFetcherSyncHeight.Set(float64(height))
 	c.l1LastSyncBlockHash = blockHash
-	c.l1SyncHeight = height
+	c.l1SyncHeight.Store(height)
+}
+
+// DebugState returns the state of the L1 message fetcher for the debug server.
+func (c *L1MessageFetcher) DebugState() interface{} {
+	return map[string]interface{}{
+		"sync_height":  c.l1SyncHeight.Load(),
+		"confirmation": c.cfg.Confirmation,
+		"fetch_limit":  c.cfg.FetchLimit,
+	}
 }
@@ -3,6 +3,7 @@ package fetcher
 import (
 	"context"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -23,7 +24,7 @@ type L2MessageFetcher struct {
 	cfg                 *config.FetcherConfig
 	db                  *gorm.DB
 	client              *ethclient.Client
-	l2SyncHeight        uint64
+	l2SyncHeight        atomic.Uint64
 	l2LastSyncBlockHash common.Hash
 
 	eventUpdateLogic *logic.EventUpdateLogic
@@ -45,6 +46,8 @@ func NewL2MessageFetcher(ctx context.Context, cfg *config.FetcherConfig, db *gor
 		l2FetcherLogic:   logic.NewL2FetcherLogic(cfg, db, client),
 	}
 
+	c.eventUpdateLogic.SetWithdrawTrieSnapshotInterval(cfg.WithdrawTrieSnapshotInterval)
+
 	reg := prometheus.DefaultRegisterer
 	c.l2MessageFetcherRunningTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
 		Name: "L2_message_fetcher_running_total",
@@ -103,7 +106,7 @@ func (c *L2MessageFetcher) Start() {
 }
 
 func (c *L2MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
-	startHeight := c.l2SyncHeight + 1
+	startHeight := c.l2SyncHeight.Load() + 1
 	endHeight, rpcErr := utils.GetBlockNumber(c.ctx, c.client, confirmation)
 	if rpcErr != nil {
 		log.Error("failed to get L2 block number", "confirmation", confirmation, "err", rpcErr)
@@ -136,7 +139,7 @@ func (c *L2MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
 			return
 		}
 
-		if updateErr := c.eventUpdateLogic.UpdateL1BatchIndexAndStatus(c.ctx, c.l2SyncHeight); updateErr != nil {
+		if updateErr := c.eventUpdateLogic.UpdateL1BatchIndexAndStatus(c.ctx, c.l2SyncHeight.Load()); updateErr != nil {
 			log.Error("failed to update L1 batch index and status", "from", from, "to", to, "err", updateErr)
 			return
 		}
@@ -149,5 +152,14 @@ func (c *L2MessageFetcher) fetchAndSaveEvents(confirmation uint64) {
 func (c *L2MessageFetcher) updateL2SyncHeight(height uint64, blockHash common.Hash) {
 	c.l2MessageFetcherSyncHeight.Set(float64(height))
 	c.l2LastSyncBlockHash = blockHash
-	c.l2SyncHeight = height
+	c.l2SyncHeight.Store(height)
+}
+
+// DebugState returns the state of the L2 message fetcher for the debug server.
+func (c *L2MessageFetcher) DebugState() interface{} {
+	return map[string]interface{}{
+		"sync_height":  c.l2SyncHeight.Load(),
+		"confirmation": c.cfg.Confirmation,
+		"fetch_limit":  c.cfg.FetchLimit,
+	}
 }
@@ -0,0 +1,330 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+// The actions taken once a deposit limit is exceeded.
+const (
+	DepositLimitActionFlag  = "flag"
+	DepositLimitActionPause = "pause"
+)
+
+// pauseWebhookTimeout bounds the pause webhook request, the L1 fetcher waits for it.
+const pauseWebhookTimeout = 10 * time.Second
+
+// DepositLimitViolation is an exceeded deposit limit, it is the payload posted to the pause webhook.
+type DepositLimitViolation struct {
+	Token string `json:"token"`
+	// Address is the depositor exceeding its limit, empty if the total volume of the token exceeds its limit.
+	Address   string `json:"address,omitempty"`
+	Volume    string `json:"volume"`
+	Limit     string `json:"limit"`
+	WindowSec uint64 `json:"window_sec"`
+	L1TxHash  string `json:"l1_tx_hash"`
+	Action    string `json:"action"`
+}
+
+type depositLimit struct {
+	maxVolume        *big.Int
+	maxAddressVolume *big.Int
+	action           string
+}
+
+type deposit struct {
+	timestamp uint64
+	amount    *big.Int
+}
+
+// depositVolume is the volume deposited in the window, exceeded is set once the volume exceeds its limit
+// and cleared once the volume is back within it, so a limit is enforced once per excess.
+type depositVolume struct {
+	deposits []deposit
+	total    *big.Int
+	exceeded bool
+}
+
+func (v *depositVolume) add(timestamp uint64, amount *big.Int) {
+	v.deposits = append(v.deposits, deposit{timestamp: timestamp, amount: amount})
+	v.total.Add(v.total, amount)
+}
+
+// prune removes the deposits before the cutoff timestamp and re-arms the limit once the volume is within it.
+func (v *depositVolume) prune(cutoff uint64, limit *big.Int) {
+	var n int
+	for n < len(v.deposits) && v.deposits[n].timestamp < cutoff {
+		v.total.Sub(v.total, v.deposits[n].amount)
+		n++
+	}
+	v.deposits = v.deposits[n:]
+	if v.exceeded && v.total.Cmp(limit) <= 0 {
+		v.exceeded = false
+	}
+}
+
+// DepositPolicy tracks the ETH and ERC20 deposit volumes per token and per depositor in a sliding window of block
+// timestamps, and flags or asks for a pause once they exceed the configured limits. Deposits dropped by a reorg
+// stay counted until they leave the window, so the volumes err on the high side.
+type DepositPolicy struct {
+	windowSec    uint64
+	limits       map[common.Address]*depositLimit
+	pauseWebhook string
+	httpClient   *http.Client
+
+	crossMessageOrm *orm.CrossMessage
+
+	// seen are the block timestamps of the counted deposits by message hash, the deposits fetched again
+	// after a restart or a reorg are not counted twice.
+	seen            map[string]uint64
+	volumes         map[common.Address]*depositVolume
+	addressVolumes  map[common.Address]map[common.Address]*depositVolume
+	latestTimestamp uint64
+
+	depositPolicyExceededTotal     *prometheus.CounterVec
+	depositPolicyPauseFailureTotal prometheus.Counter
+}
+
+// NewDepositPolicy creates the deposit policy of the configured limits.
+func NewDepositPolicy(cfg *config.DepositLimitConfig, db *gorm.DB, reg prometheus.Registerer) (*DepositPolicy, error) {
+	if cfg.WindowSec == 0 {
+		return nil, errors.New("deposit limit window must be positive")
+	}
+
+	limits := make(map[common.Address]*depositLimit, len(cfg.Tokens))
+	for _, tokenCfg := range cfg.Tokens {
+		if !common.IsHexAddress(tokenCfg.Token) {
+			return nil, fmt.Errorf("invalid deposit limit token %q", tokenCfg.Token)
+		}
+		token := common.HexToAddress(tokenCfg.Token)
+		if _, ok := limits[token]; ok {
+			return nil, fmt.Errorf("duplicate deposit limit of token %v", token)
+		}
+
+		limit := &depositLimit{action: tokenCfg.Action}
+		if limit.action == "" {
+			limit.action = DepositLimitActionFlag
+		}
+		if limit.action != DepositLimitActionFlag && limit.action != DepositLimitActionPause {
+			return nil, fmt.Errorf("invalid deposit limit action %q of token %v", tokenCfg.Action, token)
+		}
+		var err error
+		if limit.maxVolume, err = parseDepositVolume(tokenCfg.MaxVolume); err != nil {
+			return nil, fmt.Errorf("invalid max volume of token %v: %w", token, err)
+		}
+		if limit.maxAddressVolume, err = parseDepositVolume(tokenCfg.MaxAddressVolume); err != nil {
+			return nil, fmt.Errorf("invalid max address volume of token %v: %w", token, err)
+		}
+		if limit.maxVolume == nil && limit.maxAddressVolume == nil {
+			return nil, fmt.Errorf("deposit limit of token %v has no volume", token)
+		}
+		if limit.action == DepositLimitActionPause && cfg.PauseWebhook == "" {
+			log.Warn("deposit limit with pause action but no pause webhook, it is only flagged", "token", token)
+		}
+		limits[token] = limit
+	}
+
+	return &DepositPolicy{
+		windowSec:       cfg.WindowSec,
+		limits:          limits,
+		pauseWebhook:    cfg.PauseWebhook,
+		httpClient:      &http.Client{Timeout: pauseWebhookTimeout},
+		crossMessageOrm: orm.NewCrossMessage(db),
+		seen:            make(map[string]uint64),
+		volumes:         make(map[common.Address]*depositVolume),
+		addressVolumes:  make(map[common.Address]map[common.Address]*depositVolume),
+
+		depositPolicyExceededTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "deposit_policy_limit_exceeded_total",
+			Help: "Total number of deposit limits exceeded, by token, scope (total or address) and action.",
+		}, []string{"token", "scope", "action"}),
+		depositPolicyPauseFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "deposit_policy_pause_failure_total",
+			Help: "Total number of failed pause webhook requests.",
+		}),
+	}, nil
+}
+
+// Load counts the deposits of the current window saved in the database from the start block on, the limits
+// they exceed are marked without taking their actions again.
+func (p *DepositPolicy) Load(ctx context.Context, startBlock uint64) error {
+	var cutoff uint64
+	if now := uint64(time.Now().Unix()); now > p.windowSec {
+		cutoff = now - p.windowSec
+	}
+	deposits, err := p.crossMessageOrm.GetL1DepositsSince(ctx, startBlock, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to load L1 deposits, error: %w", err)
+	}
+	p.observe(ctx, deposits, false)
+	log.Info("deposit policy loaded the deposits of the window", "start block", startBlock, "deposits", len(deposits), "window", p.windowSec)
+	return nil
+}
+
+// Observe counts the fetched deposits and takes the actions of the limits they exceed.
+func (p *DepositPolicy) Observe(ctx context.Context, deposits []*orm.CrossMessage) {
+	p.observe(ctx, deposits, true)
+}
+
+func (p *DepositPolicy) observe(ctx context.Context, deposits []*orm.CrossMessage, enforce bool) {
+	for _, message := range deposits {
+		if message.TokenType != int(orm.TokenTypeETH) && message.TokenType != int(orm.TokenTypeERC20) {
+			continue
+		}
+		token := common.HexToAddress(message.L1TokenAddress)
+		limit, ok := p.limits[token]
+		if !ok {
+			continue
+		}
+		if _, ok := p.seen[message.MessageHash]; ok {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(message.TokenAmounts, 10)
+		if !ok {
+			log.Warn("deposit policy skipped a deposit of invalid amount", "message hash", message.MessageHash, "amount", message.TokenAmounts)
+			continue
+		}
+
+		p.seen[message.MessageHash] = message.BlockTimestamp
+		if message.BlockTimestamp > p.latestTimestamp {
+			p.latestTimestamp = message.BlockTimestamp
+			p.prune()
+		}
+
+		if limit.maxVolume != nil {
+			volume := p.volumes[token]
+			if volume == nil {
+				volume = &depositVolume{total: new(big.Int)}
+				p.volumes[token] = volume
+			}
+			volume.add(message.BlockTimestamp, amount)
+			p.check(ctx, volume, limit, limit.maxVolume, token, "", message, enforce)
+		}
+
+		if limit.maxAddressVolume != nil {
+			sender := common.HexToAddress(message.Sender)
+			if p.addressVolumes[token] == nil {
+				p.addressVolumes[token] = make(map[common.Address]*depositVolume)
+			}
+			volume := p.addressVolumes[token][sender]
+			if volume == nil {
+				volume = &depositVolume{total: new(big.Int)}
+				p.addressVolumes[token][sender] = volume
+			}
+			volume.add(message.BlockTimestamp, amount)
+			p.check(ctx, volume, limit, limit.maxAddressVolume, token, sender.Hex(), message, enforce)
+		}
+	}
+}
+
+// prune removes the deposits which left the window ending at the latest deposit.
+func (p *DepositPolicy) prune() {
+	if p.latestTimestamp <= p.windowSec {
+		return
+	}
+	cutoff := p.latestTimestamp - p.windowSec
+
+	for hash, timestamp := range p.seen {
+		if timestamp < cutoff {
+			delete(p.seen, hash)
+		}
+	}
+	for token, volume := range p.volumes {
+		volume.prune(cutoff, p.limits[token].maxVolume)
+	}
+	for token, volumes := range p.addressVolumes {
+		for sender, volume := range volumes {
+			volume.prune(cutoff, p.limits[token].maxAddressVolume)
+			if len(volume.deposits) == 0 {
+				delete(volumes, sender)
+			}
+		}
+	}
+}
+
+func (p *DepositPolicy) check(ctx context.Context, volume *depositVolume, limit *depositLimit, max *big.Int, token common.Address, address string, message *orm.CrossMessage, enforce bool) {
+	if volume.exceeded || volume.total.Cmp(max) <= 0 {
+		return
+	}
+	volume.exceeded = true
+	if !enforce {
+		return
+	}
+
+	violation := &DepositLimitViolation{
+		Token:     token.Hex(),
+		Address:   address,
+		Volume:    volume.total.String(),
+		Limit:     max.String(),
+		WindowSec: p.windowSec,
+		L1TxHash:  message.L1TxHash,
+		Action:    limit.action,
+	}
+	scope := "total"
+	if address != "" {
+		scope = "address"
+	}
+	p.depositPolicyExceededTotal.WithLabelValues(violation.Token, scope, violation.Action).Inc()
+	log.Error("deposit limit exceeded", "token", violation.Token, "address", address, "volume", violation.Volume,
+		"limit", violation.Limit, "window", p.windowSec, "l1 tx hash", violation.L1TxHash, "action", violation.Action)
+
+	if limit.action != DepositLimitActionPause || p.pauseWebhook == "" {
+		return
+	}
+	if err := p.requestPause(ctx, violation); err != nil {
+		p.depositPolicyPauseFailureTotal.Inc()
+		log.Error("failed to request a pause of the exceeded deposit limit", "token", violation.Token, "address", address, "err", err)
+	}
+}
+
+func (p *DepositPolicy) requestPause(ctx context.Context, violation *DepositLimitViolation) error {
+	body, err := json.Marshal(violation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal deposit limit violation, error: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.pauseWebhook, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create pause webhook request, error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post pause webhook, error: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Warn("failed to close pause webhook response body", "err", closeErr)
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pause webhook responded with status %v", resp.Status)
+	}
+	return nil
+}
+
+func parseDepositVolume(volume string) (*big.Int, error) {
+	if volume == "" {
+		return nil, nil
+	}
+	value, ok := new(big.Int).SetString(volume, 10)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not a non-negative decimal amount", volume)
+	}
+	return value, nil
+}
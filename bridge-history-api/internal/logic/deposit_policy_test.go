@@ -0,0 +1,78 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scroll-tech/bridge-history-api/internal/config"
+	"scroll-tech/bridge-history-api/internal/orm"
+)
+
+func TestDepositPolicy(t *testing.T) {
+	var violations []*DepositLimitViolation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var violation DepositLimitViolation
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&violation))
+		violations = append(violations, &violation)
+	}))
+	defer server.Close()
+
+	const token = "0x0000000000000000000000000000000000000001"
+	cfg := &config.DepositLimitConfig{
+		WindowSec: 100,
+		Tokens: []*config.TokenDepositLimit{
+			{Token: "0x0000000000000000000000000000000000000000", MaxVolume: "10"},
+			{Token: token, MaxAddressVolume: "5", Action: DepositLimitActionPause},
+		},
+		PauseWebhook: server.URL,
+	}
+	policy, err := NewDepositPolicy(cfg, nil, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	eth := func(hash string, timestamp uint64, amount string) *orm.CrossMessage {
+		return &orm.CrossMessage{MessageHash: hash, TokenType: int(orm.TokenTypeETH), Sender: "0xa", TokenAmounts: amount, BlockTimestamp: timestamp}
+	}
+	erc20 := func(hash, sender string, timestamp uint64, amount string) *orm.CrossMessage {
+		return &orm.CrossMessage{MessageHash: hash, TokenType: int(orm.TokenTypeERC20), L1TokenAddress: token, Sender: sender, TokenAmounts: amount, BlockTimestamp: timestamp}
+	}
+
+	// the deposits fetched twice are counted once, a flagged limit does not call the webhook
+	policy.Observe(context.Background(), []*orm.CrossMessage{eth("0x01", 1000, "6"), eth("0x01", 1000, "6"), eth("0x02", 1050, "5")})
+	assert.Equal(t, "11", policy.volumes[common.Address{}].total.String())
+	assert.True(t, policy.volumes[common.Address{}].exceeded)
+	assert.Empty(t, violations)
+
+	// the limit is re-armed once the volume is back within it
+	policy.Observe(context.Background(), []*orm.CrossMessage{eth("0x03", 1101, "1")})
+	assert.Equal(t, "6", policy.volumes[common.Address{}].total.String())
+	assert.False(t, policy.volumes[common.Address{}].exceeded)
+
+	// the address limit asks for a pause once per excess, other addresses are tracked apart
+	policy.Observe(context.Background(), []*orm.CrossMessage{
+		erc20("0x11", "0xb", 1101, "3"),
+		erc20("0x12", "0xc", 1101, "3"),
+		erc20("0x13", "0xb", 1102, "3"),
+		erc20("0x14", "0xb", 1103, "3"),
+	})
+	require.Len(t, violations, 1)
+	assert.Equal(t, common.HexToAddress("0xb").Hex(), violations[0].Address)
+	assert.Equal(t, "6", violations[0].Volume)
+	assert.Equal(t, "5", violations[0].Limit)
+	assert.Equal(t, DepositLimitActionPause, violations[0].Action)
+
+	// invalid limits are rejected
+	cfg.Tokens = append(cfg.Tokens, &config.TokenDepositLimit{Token: token, MaxVolume: "1"})
+	_, err = NewDepositPolicy(cfg, nil, prometheus.NewRegistry())
+	assert.Error(t, err)
+	cfg.Tokens = []*config.TokenDepositLimit{{Token: token, MaxVolume: "-1"}}
+	_, err = NewDepositPolicy(cfg, nil, prometheus.NewRegistry())
+	assert.Error(t, err)
+}
@@ -10,26 +10,40 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/withdrawtrie"
+
 	"scroll-tech/bridge-history-api/internal/orm"
-	"scroll-tech/bridge-history-api/internal/utils"
 )
 
+// withdrawTrieReplayBatchSize is the number of finalized withdrawals loaded at once when replaying the withdraw trie.
+const withdrawTrieReplayBatchSize = 10000
+
 // EventUpdateLogic the logic of insert/update the database
 type EventUpdateLogic struct {
-	db              *gorm.DB
-	crossMessageOrm *orm.CrossMessage
-	batchEventOrm   *orm.BatchEvent
+	db                      *gorm.DB
+	crossMessageOrm         *orm.CrossMessage
+	batchEventOrm           *orm.BatchEvent
+	withdrawTrieSnapshotOrm *orm.WithdrawTrieSnapshot
+
+	// withdrawTrie is kept between the batches when the withdraw trie snapshots are enabled,
+	// it is loaded from the latest snapshot on first use and reset on failures.
+	withdrawTrie                 *withdrawtrie.WithdrawTrie
+	withdrawTrieSnapshotInterval uint64
+	withdrawTrieSnapshotNonce    uint64
 
 	eventUpdateLogicL1FinalizeBatchEventL2BlockUpdateHeight prometheus.Gauge
 	eventUpdateLogicL2MessageNonceUpdateHeight              prometheus.Gauge
+	eventUpdateLogicWithdrawTrieSnapshotMessageNonce        prometheus.Gauge
+	eventUpdateLogicWithdrawRootMismatchTotal               prometheus.Counter
 }
 
 // NewEventUpdateLogic creates a EventUpdateLogic instance
 func NewEventUpdateLogic(db *gorm.DB, isL1 bool) *EventUpdateLogic {
 	b := &EventUpdateLogic{
-		db:              db,
-		crossMessageOrm: orm.NewCrossMessage(db),
-		batchEventOrm:   orm.NewBatchEvent(db),
+		db:                      db,
+		crossMessageOrm:         orm.NewCrossMessage(db),
+		batchEventOrm:           orm.NewBatchEvent(db),
+		withdrawTrieSnapshotOrm: orm.NewWithdrawTrieSnapshot(db),
 	}
 
 	if !isL1 {
@@ -42,11 +56,26 @@ func NewEventUpdateLogic(db *gorm.DB, isL1 bool) *EventUpdateLogic {
 			Name: "event_update_logic_L2_message_nonce_update_height",
 			Help: "L2 message nonce height in the latest L1 batch event that has been finalized and updated in the message_table.",
 		})
+		b.eventUpdateLogicWithdrawTrieSnapshotMessageNonce = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "event_update_logic_withdraw_trie_snapshot_message_nonce",
+			Help: "Next L2 message nonce of the latest persisted withdraw trie snapshot.",
+		})
+		b.eventUpdateLogicWithdrawRootMismatchTotal = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "event_update_logic_withdraw_root_mismatch_total",
+			Help: "Total number of finalized batches whose withdraw root mismatches the withdraw trie rebuilt from the L2 withdrawals.",
+		})
 	}
 
 	return b
 }
 
+// SetWithdrawTrieSnapshotInterval enables the withdraw trie snapshots, a snapshot is persisted each time the
+// next message nonce crosses a multiple of interval, so the trie is rebuilt on startup from the latest snapshot
+// and the finalized withdrawals after it instead of depending on the merkle proof of the latest one.
+func (b *EventUpdateLogic) SetWithdrawTrieSnapshotInterval(interval uint64) {
+	b.withdrawTrieSnapshotInterval = interval
+}
+
 // GetL1SyncHeight gets the l1 sync height from db
 func (b *EventUpdateLogic) GetL1SyncHeight(ctx context.Context) (uint64, uint64, error) {
 	messageSyncedHeight, err := b.crossMessageOrm.GetMessageSyncedHeightInDB(ctx, orm.MessageTypeL1SentMessage)
@@ -103,7 +132,7 @@ func (b *EventUpdateLogic) L1InsertOrUpdate(ctx context.Context, l1FetcherResult
 	return nil
 }
 
-func (b *EventUpdateLogic) updateL2WithdrawMessageInfos(ctx context.Context, batchIndex, startBlock, endBlock uint64) error {
+func (b *EventUpdateLogic) updateL2WithdrawMessageInfos(ctx context.Context, batchIndex, startBlock, endBlock uint64, withdrawRoot string) error {
 	l2WithdrawMessages, err := b.crossMessageOrm.GetL2WithdrawalsByBlockRange(ctx, startBlock, endBlock)
 	if err != nil {
 		log.Error("failed to get L2 withdrawals by batch index", "batch index", batchIndex, "err", err)
@@ -114,20 +143,16 @@ func (b *EventUpdateLogic) updateL2WithdrawMessageInfos(ctx context.Context, bat
 		return nil
 	}
 
-	withdrawTrie := utils.NewWithdrawTrie()
-	lastMessage, err := b.crossMessageOrm.GetL2LatestFinalizedWithdrawal(ctx)
+	withdrawTrie, err := b.getWithdrawTrie(ctx)
 	if err != nil {
-		log.Error("failed to get latest L2 finalized sent message event", "err", err)
+		log.Error("failed to get withdraw trie", "err", err)
 		return err
 	}
 
-	if lastMessage != nil {
-		withdrawTrie.Initialize(lastMessage.MessageNonce, common.HexToHash(lastMessage.MessageHash), lastMessage.MerkleProof)
-	}
-
 	if withdrawTrie.NextMessageNonce != l2WithdrawMessages[0].MessageNonce {
 		log.Error("nonce mismatch", "expected next message nonce", withdrawTrie.NextMessageNonce, "actuall next message nonce", l2WithdrawMessages[0].MessageNonce)
-		return fmt.Errorf("nonce mismatch")
+		b.withdrawTrie = nil
+		return fmt.Errorf("%w: expected: %v, got: %v", withdrawtrie.ErrMessageNonceMismatch, withdrawTrie.NextMessageNonce, l2WithdrawMessages[0].MessageNonce)
 	}
 
 	messageHashes := make([]common.Hash, len(l2WithdrawMessages))
@@ -137,6 +162,17 @@ func (b *EventUpdateLogic) updateL2WithdrawMessageInfos(ctx context.Context, bat
 
 	proofs := withdrawTrie.AppendMessages(messageHashes)
 
+	// the proofs of a trie diverging from the finalized withdraw root can't be relayed, the batch is retried
+	// until the withdrawals are fixed, batches finalized before the withdraw root was stored aren't checked
+	if withdrawRoot != "" {
+		if verifyErr := withdrawTrie.VerifyRoot(common.HexToHash(withdrawRoot)); verifyErr != nil {
+			log.Error("withdraw trie diverges from the finalized batch", "batch index", batchIndex, "start", startBlock, "end", endBlock, "err", verifyErr)
+			b.eventUpdateLogicWithdrawRootMismatchTotal.Inc()
+			b.withdrawTrie = nil
+			return verifyErr
+		}
+	}
+
 	for i, message := range l2WithdrawMessages {
 		message.MerkleProof = proofs[i]
 		message.RollupStatus = int(orm.RollupStatusTypeFinalized)
@@ -145,10 +181,116 @@ func (b *EventUpdateLogic) updateL2WithdrawMessageInfos(ctx context.Context, bat
 
 	if dbErr := b.crossMessageOrm.UpdateBatchIndexRollupStatusMerkleProofOfL2Messages(ctx, l2WithdrawMessages); dbErr != nil {
 		log.Error("failed to update batch index and rollup status and merkle proof of L2 messages", "err", dbErr)
+		b.withdrawTrie = nil
 		return dbErr
 	}
 
 	b.eventUpdateLogicL2MessageNonceUpdateHeight.Set(float64(withdrawTrie.NextMessageNonce - 1))
+
+	// a missed snapshot only makes the next startup replay more withdrawals
+	if snapshotErr := b.snapshotWithdrawTrie(ctx, withdrawTrie, batchIndex); snapshotErr != nil {
+		log.Error("failed to persist withdraw trie snapshot", "batch index", batchIndex, "err", snapshotErr)
+	}
+	return nil
+}
+
+// getWithdrawTrie returns the withdraw trie including all the finalized withdrawals.
+func (b *EventUpdateLogic) getWithdrawTrie(ctx context.Context) (*withdrawtrie.WithdrawTrie, error) {
+	if b.withdrawTrieSnapshotInterval == 0 {
+		return b.initializeWithdrawTrie(ctx)
+	}
+	if b.withdrawTrie == nil {
+		withdrawTrie, err := b.loadWithdrawTrie(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.withdrawTrie = withdrawTrie
+	}
+	return b.withdrawTrie, nil
+}
+
+// initializeWithdrawTrie initializes the withdraw trie from the merkle proof of the latest finalized withdrawal.
+func (b *EventUpdateLogic) initializeWithdrawTrie(ctx context.Context) (*withdrawtrie.WithdrawTrie, error) {
+	withdrawTrie := withdrawtrie.NewWithdrawTrie()
+	lastMessage, err := b.crossMessageOrm.GetL2LatestFinalizedWithdrawal(ctx)
+	if err != nil {
+		log.Error("failed to get latest L2 finalized sent message event", "err", err)
+		return nil, err
+	}
+
+	if lastMessage != nil {
+		withdrawTrie.Initialize(lastMessage.MessageNonce, common.HexToHash(lastMessage.MessageHash), lastMessage.MerkleProof)
+	}
+	return withdrawTrie, nil
+}
+
+// loadWithdrawTrie restores the withdraw trie from the latest snapshot and replays the finalized withdrawals after it.
+func (b *EventUpdateLogic) loadWithdrawTrie(ctx context.Context) (*withdrawtrie.WithdrawTrie, error) {
+	withdrawTrie := withdrawtrie.NewWithdrawTrie()
+	snapshot, err := b.withdrawTrieSnapshotOrm.GetLatestWithdrawTrieSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		withdrawTrie.Restore(snapshot.NextMessageNonce, snapshot.Height, snapshot.Branches)
+		b.withdrawTrieSnapshotNonce = snapshot.NextMessageNonce
+		b.eventUpdateLogicWithdrawTrieSnapshotMessageNonce.Set(float64(snapshot.NextMessageNonce))
+	}
+
+	var replayed uint64
+	for {
+		messages, err := b.crossMessageOrm.GetL2FinalizedWithdrawalsGENonce(ctx, withdrawTrie.NextMessageNonce, withdrawTrieReplayBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) == 0 {
+			break
+		}
+		for _, message := range messages {
+			if message.MessageNonce != withdrawTrie.NextMessageNonce {
+				return nil, fmt.Errorf("finalized withdrawals are not contiguous, expected message nonce: %v, actual: %v", withdrawTrie.NextMessageNonce, message.MessageNonce)
+			}
+			withdrawTrie.AppendMessage(common.HexToHash(message.MessageHash))
+		}
+		replayed += uint64(len(messages))
+	}
+
+	// cross-check the replayed trie against the merkle proof stored with the latest finalized withdrawal
+	provedTrie, err := b.initializeWithdrawTrie(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if provedTrie.NextMessageNonce != withdrawTrie.NextMessageNonce || provedTrie.MessageRoot() != withdrawTrie.MessageRoot() {
+		log.Error("withdraw trie replayed from snapshot mismatches the latest finalized withdrawal, falling back to its merkle proof",
+			"replayed next nonce", withdrawTrie.NextMessageNonce, "replayed root", withdrawTrie.MessageRoot(),
+			"proved next nonce", provedTrie.NextMessageNonce, "proved root", provedTrie.MessageRoot())
+		return provedTrie, nil
+	}
+
+	log.Info("loaded withdraw trie", "snapshot next nonce", b.withdrawTrieSnapshotNonce, "replayed withdrawals", replayed, "next nonce", withdrawTrie.NextMessageNonce)
+	return withdrawTrie, nil
+}
+
+// snapshotWithdrawTrie persists the withdraw trie when its next message nonce crossed a multiple of the snapshot interval.
+func (b *EventUpdateLogic) snapshotWithdrawTrie(ctx context.Context, withdrawTrie *withdrawtrie.WithdrawTrie, batchIndex uint64) error {
+	if b.withdrawTrieSnapshotInterval == 0 || withdrawTrie.NextMessageNonce/b.withdrawTrieSnapshotInterval <= b.withdrawTrieSnapshotNonce/b.withdrawTrieSnapshotInterval {
+		return nil
+	}
+
+	height, branches := withdrawTrie.Snapshot()
+	snapshot := &orm.WithdrawTrieSnapshot{
+		NextMessageNonce: withdrawTrie.NextMessageNonce,
+		BatchIndex:       batchIndex,
+		MessageRoot:      withdrawTrie.MessageRoot().String(),
+		Height:           height,
+		Branches:         branches,
+	}
+	if err := b.withdrawTrieSnapshotOrm.InsertWithdrawTrieSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	b.withdrawTrieSnapshotNonce = withdrawTrie.NextMessageNonce
+	b.eventUpdateLogicWithdrawTrieSnapshotMessageNonce.Set(float64(withdrawTrie.NextMessageNonce))
+	log.Info("persisted withdraw trie snapshot", "batch index", batchIndex, "next nonce", withdrawTrie.NextMessageNonce, "root", snapshot.MessageRoot)
 	return nil
 }
 
@@ -162,7 +304,7 @@ func (b *EventUpdateLogic) UpdateL1BatchIndexAndStatus(ctx context.Context, heig
 
 	for _, finalizedBatch := range finalizedBatches {
 		log.Info("update finalized batch info of L2 withdrawals", "index", finalizedBatch.BatchIndex, "start", finalizedBatch.StartBlockNumber, "end", finalizedBatch.EndBlockNumber)
-		if updateErr := b.updateL2WithdrawMessageInfos(ctx, finalizedBatch.BatchIndex, finalizedBatch.StartBlockNumber, finalizedBatch.EndBlockNumber); updateErr != nil {
+		if updateErr := b.updateL2WithdrawMessageInfos(ctx, finalizedBatch.BatchIndex, finalizedBatch.StartBlockNumber, finalizedBatch.EndBlockNumber, finalizedBatch.WithdrawRoot); updateErr != nil {
 			log.Error("failed to update L2 withdraw message infos", "index", finalizedBatch.BatchIndex, "start", finalizedBatch.StartBlockNumber, "end", finalizedBatch.EndBlockNumber, "error", updateErr)
 			return updateErr
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
 	"time"
@@ -14,6 +15,8 @@ import (
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/withdrawtrie"
+
 	"scroll-tech/bridge-history-api/internal/orm"
 	"scroll-tech/bridge-history-api/internal/types"
 	"scroll-tech/bridge-history-api/internal/utils"
@@ -33,6 +36,15 @@ const (
 	cacheKeyExpiredTime                        = 1 * time.Minute
 )
 
+var (
+	// ErrWithdrawalNotFound is returned when the L2 withdrawal of a message hash doesn't exist.
+	ErrWithdrawalNotFound = errors.New("withdrawal not found")
+	// ErrWithdrawalNonceMismatch is returned when the nonce doesn't match the nonce of the L2 withdrawal.
+	ErrWithdrawalNonceMismatch = errors.New("withdrawal nonce mismatch")
+	// ErrWithdrawalNotFinalized is returned when the batch of the L2 withdrawal isn't finalized yet.
+	ErrWithdrawalNotFinalized = errors.New("withdrawal not finalized")
+)
+
 // HistoryLogic services.
 type HistoryLogic struct {
 	crossMessageOrm *orm.CrossMessage
@@ -260,6 +272,80 @@ func (h *HistoryLogic) GetTxsByHashes(ctx context.Context, txHashes []string) ([
 	return results, nil
 }
 
+// GetL2WithdrawalProofs exports the proofs of the finalized L2 withdrawals in a batch or in a block timestamp range.
+func (h *HistoryLogic) GetL2WithdrawalProofs(ctx context.Context, req *types.QueryWithdrawalProofsRequest) ([]*types.WithdrawalProof, uint64, error) {
+	offset := int((req.Page - 1) * req.PageSize)
+	limit := int(req.PageSize)
+
+	var messages []*orm.CrossMessage
+	var total uint64
+	var err error
+	if req.BatchIndex != nil {
+		messages, total, err = h.crossMessageOrm.GetL2FinalizedWithdrawalsByBatchIndex(ctx, *req.BatchIndex, offset, limit)
+	} else {
+		messages, total, err = h.crossMessageOrm.GetL2FinalizedWithdrawalsByTimeRange(ctx, req.StartTime, req.EndTime, offset, limit)
+	}
+	if err != nil {
+		log.Error("failed to get L2 finalized withdrawals", "batch index", req.BatchIndex, "start time", req.StartTime, "end time", req.EndTime, "error", err)
+		return nil, 0, err
+	}
+
+	proofs := make([]*types.WithdrawalProof, 0, len(messages))
+	for _, message := range messages {
+		proofs = append(proofs, getWithdrawalProof(message))
+	}
+	return proofs, total, nil
+}
+
+// GetMessageProof returns the merkle proof of the finalized L2 withdrawal of the message hash and nonce, the
+// proof relayMessageWithProof is called with on L1. The proof is checked against the withdraw root of its batch.
+func (h *HistoryLogic) GetMessageProof(ctx context.Context, messageHash string, nonce uint64) (*types.WithdrawalProof, error) {
+	messageHash = common.HexToHash(messageHash).String()
+	message, err := h.crossMessageOrm.GetL2WithdrawalByMessageHash(ctx, messageHash)
+	if err != nil {
+		log.Error("failed to get L2 withdrawal", "message hash", messageHash, "error", err)
+		return nil, err
+	}
+	if message == nil {
+		return nil, fmt.Errorf("%w, message hash: %v", ErrWithdrawalNotFound, messageHash)
+	}
+	if message.MessageNonce != nonce {
+		return nil, fmt.Errorf("%w, message hash: %v, nonce: %v, message nonce: %v", ErrWithdrawalNonceMismatch, messageHash, nonce, message.MessageNonce)
+	}
+	if orm.RollupStatusType(message.RollupStatus) != orm.RollupStatusTypeFinalized || len(message.MerkleProof) == 0 {
+		return nil, fmt.Errorf("%w, message hash: %v", ErrWithdrawalNotFinalized, messageHash)
+	}
+
+	batch, err := h.batchEventOrm.GetFinalizedBatchByIndex(ctx, message.BatchIndex)
+	if err != nil {
+		log.Error("failed to get finalized batch", "batch index", message.BatchIndex, "error", err)
+		return nil, err
+	}
+	// the batches finalized before the withdraw root was stored aren't checked
+	if batch != nil && batch.WithdrawRoot != "" &&
+		!withdrawtrie.VerifyMerkleProof(common.HexToHash(batch.WithdrawRoot), common.HexToHash(message.MessageHash), message.MessageNonce, message.MerkleProof) {
+		log.Error("merkle proof of L2 withdrawal mismatches the withdraw root of its batch", "message hash", messageHash, "batch index", message.BatchIndex, "withdraw root", batch.WithdrawRoot)
+		return nil, fmt.Errorf("%w, message hash: %v, batch index: %v", withdrawtrie.ErrWithdrawRootMismatch, messageHash, message.BatchIndex)
+	}
+	return getWithdrawalProof(message), nil
+}
+
+func getWithdrawalProof(message *orm.CrossMessage) *types.WithdrawalProof {
+	return &types.WithdrawalProof{
+		MessageHash: message.MessageHash,
+		L2TxHash:    message.L2TxHash,
+		From:        message.MessageFrom,
+		To:          message.MessageTo,
+		Value:       message.MessageValue,
+		Nonce:       strconv.FormatUint(message.MessageNonce, 10),
+		Message:     message.MessageData,
+		Proof: types.L2MessageProof{
+			BatchIndex:  strconv.FormatUint(message.BatchIndex, 10),
+			MerkleProof: "0x" + common.Bytes2Hex(message.MerkleProof),
+		},
+	}
+}
+
 func getTxHistoryInfo(message *orm.CrossMessage) *types.TxHistoryInfo {
 	txHistory := &types.TxHistoryInfo{
 		MessageHash:    message.MessageHash,
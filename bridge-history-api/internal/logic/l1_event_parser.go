@@ -221,6 +221,7 @@ func (e *L1EventParser) ParseL1BatchEventLogs(ctx context.Context, logs []types.
 				BatchStatus:   int(orm.BatchStatusTypeFinalized),
 				BatchIndex:    event.BatchIndex.Uint64(),
 				BatchHash:     event.BatchHash.String(),
+				WithdrawRoot:  event.WithdrawRoot.String(),
 				L1BlockNumber: vlog.BlockNumber,
 			})
 		}
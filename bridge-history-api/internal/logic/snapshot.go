@@ -0,0 +1,338 @@
+package logic
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/bridge-history-api/internal/orm"
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+)
+
+// snapshotFormatVersion is the version of the snapshot file format.
+const snapshotFormatVersion = 1
+
+// snapshotBatchSize is the number of rows exported or imported at once.
+const snapshotBatchSize = 1000
+
+// ErrSnapshotDBNotEmpty the snapshot is only imported into an empty database.
+var ErrSnapshotDBNotEmpty = errors.New("database is not empty")
+
+// snapshotTables are the tables of the snapshot, in the order they are exported.
+var snapshotTables = []string{
+	(&orm.CrossMessage{}).TableName(),
+	(&orm.BatchEvent{}).TableName(),
+	(&orm.WithdrawTrieSnapshot{}).TableName(),
+}
+
+// SnapshotHeader is the first line of a snapshot.
+type SnapshotHeader struct {
+	FormatVersion int   `json:"format_version"`
+	DBVersion     int64 `json:"db_version"`
+	// L1SyncHeight and L2SyncHeight are the heights the fetchers resume the incremental sync from.
+	L1SyncHeight uint64    `json:"l1_sync_height"`
+	L2SyncHeight uint64    `json:"l2_sync_height"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// snapshotRecord is a line of a snapshot after the header, a row of a table.
+type snapshotRecord struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// SnapshotLogic exports and imports the snapshots of the database, a fresh indexer is bootstrapped from a
+// published snapshot and syncs the events after it, instead of scanning the logs from the deployment height.
+//
+// A snapshot is gzip compressed json lines, the SnapshotHeader followed by the rows of the tables.
+type SnapshotLogic struct {
+	db *gorm.DB
+}
+
+// NewSnapshotLogic creates a SnapshotLogic instance.
+func NewSnapshotLogic(db *gorm.DB) *SnapshotLogic {
+	return &SnapshotLogic{db: db}
+}
+
+// Export writes a consistent snapshot of the database to w.
+func (s *SnapshotLogic) Export(ctx context.Context, w io.Writer) (*SnapshotHeader, error) {
+	dbVersion, err := s.dbVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	zw := gzip.NewWriter(w)
+	encoder := json.NewEncoder(zw)
+	header := &SnapshotHeader{FormatVersion: snapshotFormatVersion, DBVersion: dbVersion, CreatedAt: time.Now().UTC()}
+
+	// the repeatable read transaction sees the tables at the same point while the fetchers keep running
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		eventUpdateLogic := &EventUpdateLogic{crossMessageOrm: orm.NewCrossMessage(tx), batchEventOrm: orm.NewBatchEvent(tx)}
+		messageSyncedHeight, batchSyncedHeight, heightErr := eventUpdateLogic.GetL1SyncHeight(ctx)
+		if heightErr != nil {
+			return heightErr
+		}
+		header.L1SyncHeight = messageSyncedHeight
+		if batchSyncedHeight > header.L1SyncHeight {
+			header.L1SyncHeight = batchSyncedHeight
+		}
+		if header.L2SyncHeight, heightErr = eventUpdateLogic.GetL2MessageSyncedHeightInDB(ctx); heightErr != nil {
+			return heightErr
+		}
+		if encodeErr := encoder.Encode(header); encodeErr != nil {
+			return encodeErr
+		}
+
+		for _, table := range snapshotTables {
+			if exportErr := exportTable(tx, encoder, table); exportErr != nil {
+				return fmt.Errorf("failed to export table %v: %w", table, exportErr)
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// Import loads the snapshot read from r into the empty database.
+func (s *SnapshotLogic) Import(ctx context.Context, r io.Reader) (*SnapshotHeader, error) {
+	dbVersion, err := s.dbVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer zr.Close()
+	decoder := json.NewDecoder(bufio.NewReader(zr))
+
+	var header SnapshotHeader
+	if err = decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot header: %w", err)
+	}
+	if header.FormatVersion != snapshotFormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format version %v", header.FormatVersion)
+	}
+	if header.DBVersion != dbVersion {
+		return nil, fmt.Errorf("snapshot db version %v doesn't match the db version %v", header.DBVersion, dbVersion)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range snapshotTables {
+			var count int64
+			if countErr := tx.Table(table).Count(&count).Error; countErr != nil {
+				return countErr
+			}
+			if count > 0 {
+				return fmt.Errorf("%w, table %v has %v rows", ErrSnapshotDBNotEmpty, table, count)
+			}
+		}
+
+		batch := newSnapshotBatch(tx)
+		var rows int
+		for {
+			var record snapshotRecord
+			if decodeErr := decoder.Decode(&record); decodeErr == io.EOF {
+				break
+			} else if decodeErr != nil {
+				return fmt.Errorf("failed to decode snapshot record %v: %w", rows, decodeErr)
+			}
+			if addErr := batch.add(&record); addErr != nil {
+				return fmt.Errorf("failed to import snapshot record %v: %w", rows, addErr)
+			}
+			rows++
+		}
+		if flushErr := batch.flush(); flushErr != nil {
+			return flushErr
+		}
+
+		// the rows keep their ids, the sequences continue after them
+		for _, table := range snapshotTables {
+			sequenceSQL := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %s", table, table)
+			if execErr := tx.Exec(sequenceSQL).Error; execErr != nil {
+				return fmt.Errorf("failed to reset the id sequence of table %v: %w", table, execErr)
+			}
+		}
+		log.Info("imported snapshot rows", "rows", rows)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+func (s *SnapshotLogic) dbVersion() (int64, error) {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return 0, err
+	}
+	return migrate.Current(sqlDB)
+}
+
+// OpenSnapshot opens the snapshot published at the http(s) url or stored at the file path.
+func OpenSnapshot(ctx context.Context, source string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.Open(filepath.Clean(source))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to download snapshot, status: %v", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func exportTable(tx *gorm.DB, encoder *json.Encoder, table string) error {
+	var afterID uint64
+	for {
+		rows, lastID, err := loadSnapshotRows(tx, table, afterID)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			if err = encoder.Encode(&snapshotRecord{Table: table, Row: data}); err != nil {
+				return err
+			}
+		}
+		afterID = lastID
+	}
+}
+
+// loadSnapshotRows loads a batch of rows of the table with ids greater than afterID, it returns the rows and the last id.
+func loadSnapshotRows(tx *gorm.DB, table string, afterID uint64) ([]interface{}, uint64, error) {
+	db := tx.Table(table).Where("id > ?", afterID).Order("id asc").Limit(snapshotBatchSize)
+
+	var rows []interface{}
+	var lastID uint64
+	switch table {
+	case (&orm.CrossMessage{}).TableName():
+		var messages []*orm.CrossMessage
+		if err := db.Find(&messages).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, message := range messages {
+			rows, lastID = append(rows, message), message.ID
+		}
+	case (&orm.BatchEvent{}).TableName():
+		var events []*orm.BatchEvent
+		if err := db.Find(&events).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, event := range events {
+			rows, lastID = append(rows, event), event.ID
+		}
+	case (&orm.WithdrawTrieSnapshot{}).TableName():
+		var snapshots []*orm.WithdrawTrieSnapshot
+		if err := db.Find(&snapshots).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, snapshot := range snapshots {
+			rows, lastID = append(rows, snapshot), snapshot.ID
+		}
+	default:
+		return nil, 0, fmt.Errorf("unknown snapshot table %v", table)
+	}
+	return rows, lastID, nil
+}
+
+// snapshotBatch buffers the imported rows and inserts them in batches.
+type snapshotBatch struct {
+	tx *gorm.DB
+
+	crossMessages         []*orm.CrossMessage
+	batchEvents           []*orm.BatchEvent
+	withdrawTrieSnapshots []*orm.WithdrawTrieSnapshot
+}
+
+func newSnapshotBatch(tx *gorm.DB) *snapshotBatch {
+	return &snapshotBatch{tx: tx}
+}
+
+func (b *snapshotBatch) add(record *snapshotRecord) error {
+	switch record.Table {
+	case (&orm.CrossMessage{}).TableName():
+		var message orm.CrossMessage
+		if err := json.Unmarshal(record.Row, &message); err != nil {
+			return err
+		}
+		b.crossMessages = append(b.crossMessages, &message)
+	case (&orm.BatchEvent{}).TableName():
+		var event orm.BatchEvent
+		if err := json.Unmarshal(record.Row, &event); err != nil {
+			return err
+		}
+		b.batchEvents = append(b.batchEvents, &event)
+	case (&orm.WithdrawTrieSnapshot{}).TableName():
+		var snapshot orm.WithdrawTrieSnapshot
+		if err := json.Unmarshal(record.Row, &snapshot); err != nil {
+			return err
+		}
+		b.withdrawTrieSnapshots = append(b.withdrawTrieSnapshots, &snapshot)
+	default:
+		return fmt.Errorf("unknown snapshot table %v", record.Table)
+	}
+
+	if len(b.crossMessages)+len(b.batchEvents)+len(b.withdrawTrieSnapshots) >= snapshotBatchSize {
+		return b.flush()
+	}
+	return nil
+}
+
+func (b *snapshotBatch) flush() error {
+	if len(b.crossMessages) > 0 {
+		if err := b.tx.Create(&b.crossMessages).Error; err != nil {
+			return fmt.Errorf("failed to insert cross messages: %w", err)
+		}
+		b.crossMessages = nil
+	}
+	if len(b.batchEvents) > 0 {
+		if err := b.tx.Create(&b.batchEvents).Error; err != nil {
+			return fmt.Errorf("failed to insert batch events: %w", err)
+		}
+		b.batchEvents = nil
+	}
+	if len(b.withdrawTrieSnapshots) > 0 {
+		if err := b.tx.Create(&b.withdrawTrieSnapshots).Error; err != nil {
+			return fmt.Errorf("failed to insert withdraw trie snapshots: %w", err)
+		}
+		b.withdrawTrieSnapshots = nil
+	}
+	return nil
+}
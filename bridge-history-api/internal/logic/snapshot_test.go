@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSnapshot(t *testing.T) {
+	content := []byte("snapshot")
+
+	path := filepath.Join(t.TempDir(), "snapshot.jsonl.gz")
+	require.NoError(t, os.WriteFile(path, content, 0600))
+	r, err := OpenSnapshot(context.Background(), path)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, content, data)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/snapshot.jsonl.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	r, err = OpenSnapshot(context.Background(), server.URL+"/snapshot.jsonl.gz")
+	require.NoError(t, err)
+	data, err = io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, content, data)
+
+	_, err = OpenSnapshot(context.Background(), server.URL+"/missing")
+	assert.ErrorContains(t, err, "404")
+	_, err = OpenSnapshot(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
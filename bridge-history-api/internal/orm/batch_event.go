@@ -40,6 +40,7 @@ type BatchEvent struct {
 	BatchHash        string     `json:"batch_hash" gorm:"column:batch_hash"`
 	StartBlockNumber uint64     `json:"start_block_number" gorm:"column:start_block_number"`
 	EndBlockNumber   uint64     `json:"end_block_number" gorm:"column:end_block_number"`
+	WithdrawRoot     string     `json:"withdraw_root" gorm:"column:withdraw_root"`
 	UpdateStatus     int        `json:"update_status" gorm:"column:update_status"`
 	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at"`
@@ -71,6 +72,22 @@ func (c *BatchEvent) GetBatchEventSyncedHeightInDB(ctx context.Context) (uint64,
 	return batch.L1BlockNumber, nil
 }
 
+// GetFinalizedBatchByIndex returns the finalized batch of the batch index, nil if it doesn't exist.
+func (c *BatchEvent) GetFinalizedBatchByIndex(ctx context.Context, batchIndex uint64) (*BatchEvent, error) {
+	var batch BatchEvent
+	db := c.db.WithContext(ctx)
+	db = db.Model(&BatchEvent{})
+	db = db.Where("batch_index = ?", batchIndex)
+	db = db.Where("batch_status = ?", BatchStatusTypeFinalized)
+	if err := db.First(&batch).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get finalized batch by index, batch index: %v, error: %w", batchIndex, err)
+	}
+	return &batch, nil
+}
+
 // GetFinalizedBatchesLEBlockHeight returns the finalized batches with end block <= given block height in db.
 func (c *BatchEvent) GetFinalizedBatchesLEBlockHeight(ctx context.Context, blockHeight uint64) ([]*BatchEvent, error) {
 	var batches []*BatchEvent
@@ -110,6 +127,7 @@ func (c *BatchEvent) InsertOrUpdateBatchEvents(ctx context.Context, l1BatchEvent
 			db = db.Where("batch_index = ?", l1BatchEvent.BatchIndex)
 			db = db.Where("batch_hash = ?", l1BatchEvent.BatchHash)
 			updateFields["batch_status"] = BatchStatusTypeFinalized
+			updateFields["withdraw_root"] = l1BatchEvent.WithdrawRoot
 			if err := db.Updates(updateFields).Error; err != nil {
 				return fmt.Errorf("failed to update batch event, error: %w", err)
 			}
@@ -176,6 +176,38 @@ func (c *CrossMessage) GetL2LatestFinalizedWithdrawal(ctx context.Context) (*Cro
 	return &message, nil
 }
 
+// GetL2WithdrawalByMessageHash returns the L2 withdrawal of the message hash, nil if it doesn't exist.
+func (c *CrossMessage) GetL2WithdrawalByMessageHash(ctx context.Context, messageHash string) (*CrossMessage, error) {
+	var message CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+	db = db.Where("message_hash = ?", messageHash)
+	if err := db.First(&message).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get L2 withdrawal by message hash, message hash: %v, error: %w", messageHash, err)
+	}
+	return &message, nil
+}
+
+// GetL2FinalizedWithdrawalsGENonce returns up to limit finalized L2 withdrawals with message nonce >= the given nonce, sorted by message nonce.
+func (c *CrossMessage) GetL2FinalizedWithdrawalsGENonce(ctx context.Context, nonce uint64, limit int) ([]*CrossMessage, error) {
+	var messages []*CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+	db = db.Where("rollup_status = ?", RollupStatusTypeFinalized)
+	db = db.Where("message_nonce >= ?", nonce)
+	db = db.Order("message_nonce asc")
+	db = db.Limit(limit)
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get L2 finalized withdrawals >= message nonce %v, error: %w", nonce, err)
+	}
+	return messages, nil
+}
+
 // GetL2WithdrawalsByBlockRange returns the L2 withdrawals by block range from the database.
 func (c *CrossMessage) GetL2WithdrawalsByBlockRange(ctx context.Context, startBlock, endBlock uint64) ([]*CrossMessage, error) {
 	var messages []*CrossMessage
@@ -195,6 +227,70 @@ func (c *CrossMessage) GetL2WithdrawalsByBlockRange(ctx context.Context, startBl
 	return messages, nil
 }
 
+// GetL1DepositsSince returns the L1 deposits from the start block on with a block timestamp >= the given timestamp,
+// sorted by block timestamp. The start block bounds the scan of the l1 block number index.
+func (c *CrossMessage) GetL1DepositsSince(ctx context.Context, startBlock, timestamp uint64) ([]*CrossMessage, error) {
+	var messages []*CrossMessage
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("l1_block_number >= ?", startBlock)
+	db = db.Where("block_timestamp >= ?", timestamp)
+	db = db.Where("tx_status != ?", TxStatusTypeSentTxReverted)
+	db = db.Where("message_type = ?", MessageTypeL1SentMessage)
+	db = db.Order("block_timestamp asc")
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get L1 deposits since timestamp %v, error: %w", timestamp, err)
+	}
+	return messages, nil
+}
+
+// GetL2FinalizedWithdrawalsByBatchIndex returns a page of the finalized L2 withdrawals in the given batch sorted by message nonce,
+// together with the total number of the finalized L2 withdrawals in the batch.
+func (c *CrossMessage) GetL2FinalizedWithdrawalsByBatchIndex(ctx context.Context, batchIndex uint64, offset, limit int) ([]*CrossMessage, uint64, error) {
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+	db = db.Where("rollup_status = ?", RollupStatusTypeFinalized)
+	db = db.Where("batch_index = ?", batchIndex)
+	messages, total, err := c.findPage(db, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get L2 finalized withdrawals by batch index, batch index: %v, error: %w", batchIndex, err)
+	}
+	return messages, total, nil
+}
+
+// GetL2FinalizedWithdrawalsByTimeRange returns a page of the finalized L2 withdrawals whose block timestamps are in [startTime, endTime]
+// sorted by message nonce, together with the total number of the finalized L2 withdrawals in the time range.
+func (c *CrossMessage) GetL2FinalizedWithdrawalsByTimeRange(ctx context.Context, startTime, endTime uint64, offset, limit int) ([]*CrossMessage, uint64, error) {
+	db := c.db.WithContext(ctx)
+	db = db.Model(&CrossMessage{})
+	db = db.Where("message_type = ?", MessageTypeL2SentMessage)
+	db = db.Where("rollup_status = ?", RollupStatusTypeFinalized)
+	db = db.Where("block_timestamp >= ?", startTime)
+	db = db.Where("block_timestamp <= ?", endTime)
+	messages, total, err := c.findPage(db, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get L2 finalized withdrawals by time range, start time: %v, end time: %v, error: %w", startTime, endTime, err)
+	}
+	return messages, total, nil
+}
+
+// findPage counts the messages matched by the query and returns the requested page sorted by message nonce.
+func (c *CrossMessage) findPage(db *gorm.DB, offset, limit int) ([]*CrossMessage, uint64, error) {
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var messages []*CrossMessage
+	db = db.Order("message_nonce asc")
+	db = db.Offset(offset)
+	db = db.Limit(limit)
+	if err := db.Find(&messages).Error; err != nil {
+		return nil, 0, err
+	}
+	return messages, uint64(total), nil
+}
+
 // GetMessagesByTxHashes retrieves all cross messages from the database that match the provided transaction hashes.
 func (c *CrossMessage) GetMessagesByTxHashes(ctx context.Context, txHashes []string) ([]*CrossMessage, error) {
 	var messages []*CrossMessage
@@ -0,0 +1,140 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/docker"
+
+	"scroll-tech/bridge-history-api/internal/orm/migrate"
+)
+
+var (
+	base *docker.App
+
+	db *gorm.DB
+)
+
+func TestMain(m *testing.M) {
+	t := &testing.T{}
+	base = docker.NewDockerApp()
+	base.RunDBImage(t)
+	var err error
+	db, err = database.InitDB(
+		&database.Config{
+			DSN:        base.DBConfig.DSN,
+			DriverName: base.DBConfig.DriverName,
+			MaxOpenNum: base.DBConfig.MaxOpenNum,
+			MaxIdleNum: base.DBConfig.MaxIdleNum,
+		},
+	)
+	if err != nil {
+		base.Free()
+		fmt.Println("failed to init db", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	_ = database.CloseDB(db)
+	base.Free()
+	os.Exit(code)
+}
+
+func newWithdrawal(nonce, batchIndex, blockTimestamp uint64, rollupStatus RollupStatusType) *CrossMessage {
+	return &CrossMessage{
+		MessageType:    int(MessageTypeL2SentMessage),
+		RollupStatus:   int(rollupStatus),
+		MessageHash:    fmt.Sprintf("0x%064x", nonce),
+		L2TxHash:       fmt.Sprintf("0x%064x", nonce+1000),
+		TokenAmounts:   "1",
+		BlockTimestamp: blockTimestamp,
+		MessageNonce:   nonce,
+		MerkleProof:    []byte{byte(nonce)},
+		BatchIndex:     batchIndex,
+	}
+}
+
+func messageNonces(messages []*CrossMessage) []uint64 {
+	nonces := make([]uint64, 0, len(messages))
+	for _, message := range messages {
+		nonces = append(nonces, message.MessageNonce)
+	}
+	return nonces
+}
+
+func TestCrossMessageFinalizedWithdrawalsPagination(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	crossMessageOrm := NewCrossMessage(db)
+	// the nonces 0~4 are finalized in batch 1, the nonce 5 of batch 1 is not finalized yet and the nonce 6 is finalized in batch 2,
+	// the messages are inserted out of order to check the pages are sorted by nonce.
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL2Messages(context.Background(), []*CrossMessage{
+		newWithdrawal(3, 1, 103, RollupStatusTypeFinalized),
+		newWithdrawal(0, 1, 100, RollupStatusTypeFinalized),
+		newWithdrawal(6, 2, 106, RollupStatusTypeFinalized),
+		newWithdrawal(4, 1, 104, RollupStatusTypeFinalized),
+		newWithdrawal(5, 1, 105, RollupStatusTypeUnknown),
+		newWithdrawal(1, 1, 101, RollupStatusTypeFinalized),
+		newWithdrawal(2, 1, 102, RollupStatusTypeFinalized),
+	}))
+	// a deposit is never exported even if its columns match
+	deposit := newWithdrawal(7, 1, 102, RollupStatusTypeFinalized)
+	deposit.MessageType = int(MessageTypeL1SentMessage)
+	assert.NoError(t, crossMessageOrm.InsertOrUpdateL1Messages(context.Background(), []*CrossMessage{deposit}))
+
+	batchTests := []struct {
+		name         string
+		batchIndex   uint64
+		offset       int
+		limit        int
+		expectNonces []uint64
+		expectTotal  uint64
+	}{
+		{name: "FirstPage", batchIndex: 1, offset: 0, limit: 2, expectNonces: []uint64{0, 1}, expectTotal: 5},
+		{name: "MiddlePage", batchIndex: 1, offset: 2, limit: 2, expectNonces: []uint64{2, 3}, expectTotal: 5},
+		{name: "PartialLastPage", batchIndex: 1, offset: 4, limit: 2, expectNonces: []uint64{4}, expectTotal: 5},
+		{name: "ExactLastPage", batchIndex: 1, offset: 0, limit: 5, expectNonces: []uint64{0, 1, 2, 3, 4}, expectTotal: 5},
+		{name: "PageAfterLast", batchIndex: 1, offset: 6, limit: 2, expectNonces: []uint64{}, expectTotal: 5},
+		{name: "OtherBatch", batchIndex: 2, offset: 0, limit: 2, expectNonces: []uint64{6}, expectTotal: 1},
+		{name: "UnknownBatch", batchIndex: 9, offset: 0, limit: 2, expectNonces: []uint64{}, expectTotal: 0},
+	}
+	for _, tt := range batchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, total, err := crossMessageOrm.GetL2FinalizedWithdrawalsByBatchIndex(context.Background(), tt.batchIndex, tt.offset, tt.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectTotal, total)
+			assert.Equal(t, tt.expectNonces, messageNonces(messages))
+		})
+	}
+
+	timeRangeTests := []struct {
+		name         string
+		startTime    uint64
+		endTime      uint64
+		offset       int
+		limit        int
+		expectNonces []uint64
+		expectTotal  uint64
+	}{
+		{name: "InclusiveBounds", startTime: 101, endTime: 106, offset: 0, limit: 10, expectNonces: []uint64{1, 2, 3, 4, 6}, expectTotal: 5},
+		{name: "SecondPage", startTime: 101, endTime: 106, offset: 3, limit: 3, expectNonces: []uint64{4, 6}, expectTotal: 5},
+		{name: "SingleTimestamp", startTime: 106, endTime: 106, offset: 0, limit: 10, expectNonces: []uint64{6}, expectTotal: 1},
+		{name: "OnlyUnfinalized", startTime: 105, endTime: 105, offset: 0, limit: 10, expectNonces: []uint64{}, expectTotal: 0},
+		{name: "OutOfRange", startTime: 200, endTime: 300, offset: 0, limit: 10, expectNonces: []uint64{}, expectTotal: 0},
+	}
+	for _, tt := range timeRangeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, total, err := crossMessageOrm.GetL2FinalizedWithdrawalsByTimeRange(context.Background(), tt.startTime, tt.endTime, tt.offset, tt.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectTotal, total)
+			assert.Equal(t, tt.expectNonces, messageNonces(messages))
+		})
+	}
+}
@@ -3,8 +3,12 @@ package migrate
 import (
 	"database/sql"
 	"embed"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/pressly/goose/v3"
 )
@@ -15,6 +19,9 @@ var embedMigrations embed.FS
 // MigrationsDir migration dir
 const MigrationsDir string = "migrations"
 
+// ErrIncompatibleVersion the db schema version is not the one the binary is built for.
+var ErrIncompatibleVersion = errors.New("incompatible db schema version")
+
 func init() {
 	goose.SetBaseFS(embedMigrations)
 	goose.SetSequential(true)
@@ -59,3 +66,45 @@ func Status(db *sql.DB) error {
 func Create(db *sql.DB, name, migrationType string) error {
 	return goose.Create(db, MigrationsDir, name, migrationType)
 }
+
+// Latest get the version of the latest migration embedded in the binary
+func Latest() (int64, error) {
+	migrations, err := goose.CollectMigrations(MigrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		return 0, err
+	}
+	last, err := migrations.Last()
+	if err != nil {
+		return 0, err
+	}
+	return last.Version, nil
+}
+
+// CheckVersion checks the db schema is at the latest migration embedded in the binary, the only version the binary
+// supports. A binary started against a db migrated by another release refuses to run instead of corrupting the data.
+func CheckVersion(db *sql.DB) error {
+	latest, err := Latest()
+	if err != nil {
+		return err
+	}
+	current, err := Current(db)
+	if err != nil {
+		return err
+	}
+
+	if current > latest {
+		return fmt.Errorf("%w: db version %d is newer than the version %d supported by the binary, upgrade the binary", ErrIncompatibleVersion, current, latest)
+	}
+	if current < latest {
+		migrations, err := goose.CollectMigrations(MigrationsDir, current, latest)
+		if err != nil {
+			return err
+		}
+		pending := make([]string, 0, len(migrations))
+		for _, migration := range migrations {
+			pending = append(pending, filepath.Base(migration.Source))
+		}
+		return fmt.Errorf("%w: db version %d is older than the required version %d, run the migrations %s", ErrIncompatibleVersion, current, latest, strings.Join(pending, ", "))
+	}
+	return nil
+}
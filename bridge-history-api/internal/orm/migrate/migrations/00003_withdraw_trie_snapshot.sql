@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE withdraw_trie_snapshot
+(
+    id                  BIGSERIAL     PRIMARY KEY,
+    next_message_nonce  BIGINT        NOT NULL,
+    batch_index         BIGINT        NOT NULL,
+    message_root        VARCHAR       NOT NULL,
+    height              INTEGER       NOT NULL,
+    branches            BYTEA         NOT NULL,
+    created_at          TIMESTAMP(0)  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0)  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at          TIMESTAMP(0)  DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_idx_wts_next_message_nonce ON withdraw_trie_snapshot (next_message_nonce);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS withdraw_trie_snapshot;
+-- +goose StatementEnd
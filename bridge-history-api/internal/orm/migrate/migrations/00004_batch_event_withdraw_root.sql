@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE batch_event_v2 ADD COLUMN withdraw_root VARCHAR NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE batch_event_v2 DROP COLUMN IF EXISTS withdraw_root;
+-- +goose StatementEnd
@@ -0,0 +1,64 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithdrawTrieSnapshot represents a persisted state of the withdraw trie after the finalized withdrawals of a batch.
+type WithdrawTrieSnapshot struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID               uint64     `json:"id" gorm:"column:id;primary_key"`
+	NextMessageNonce uint64     `json:"next_message_nonce" gorm:"column:next_message_nonce"`
+	BatchIndex       uint64     `json:"batch_index" gorm:"column:batch_index"`
+	MessageRoot      string     `json:"message_root" gorm:"column:message_root"`
+	Height           int        `json:"height" gorm:"column:height"`
+	Branches         []byte     `json:"branches" gorm:"column:branches"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
+}
+
+// TableName returns the table name for the WithdrawTrieSnapshot model.
+func (*WithdrawTrieSnapshot) TableName() string {
+	return "withdraw_trie_snapshot"
+}
+
+// NewWithdrawTrieSnapshot returns a new instance of WithdrawTrieSnapshot.
+func NewWithdrawTrieSnapshot(db *gorm.DB) *WithdrawTrieSnapshot {
+	return &WithdrawTrieSnapshot{db: db}
+}
+
+// GetLatestWithdrawTrieSnapshot returns the snapshot with the highest next message nonce, nil if there is none.
+func (c *WithdrawTrieSnapshot) GetLatestWithdrawTrieSnapshot(ctx context.Context) (*WithdrawTrieSnapshot, error) {
+	var snapshot WithdrawTrieSnapshot
+	db := c.db.WithContext(ctx)
+	db = db.Model(&WithdrawTrieSnapshot{})
+	db = db.Order("next_message_nonce desc")
+	if err := db.First(&snapshot).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest withdraw trie snapshot, error: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// InsertWithdrawTrieSnapshot inserts a snapshot, an existing snapshot at the same next message nonce is kept.
+func (c *WithdrawTrieSnapshot) InsertWithdrawTrieSnapshot(ctx context.Context, snapshot *WithdrawTrieSnapshot) error {
+	db := c.db.WithContext(ctx)
+	db = db.Model(&WithdrawTrieSnapshot{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "next_message_nonce"}},
+		DoNothing: true,
+	})
+	if err := db.Create(snapshot).Error; err != nil {
+		return fmt.Errorf("failed to insert withdraw trie snapshot, next message nonce: %v, error: %w", snapshot.NextMessageNonce, err)
+	}
+	return nil
+}
@@ -30,6 +30,8 @@ func Route(router *gin.Engine, conf *config.Config, reg prometheus.Registerer) {
 	r.GET("/txs", api.HistoryCtrler.GetTxsByAddress)
 	r.GET("/l2/withdrawals", api.HistoryCtrler.GetL2WithdrawalsByAddress)
 	r.GET("/l2/unclaimed/withdrawals", api.HistoryCtrler.GetL2UnclaimedWithdrawalsByAddress)
+	r.GET("/l2/withdrawals/proofs", api.HistoryCtrler.GetL2WithdrawalProofs)
+	r.GET("/l2/withdrawal/proof", api.HistoryCtrler.GetL2MessageProof)
 
 	r.POST("/txsbyhashes", api.HistoryCtrler.PostQueryTxsByHashes)
 }
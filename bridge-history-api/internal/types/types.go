@@ -1,7 +1,10 @@
 package types
 
 import (
+	"compress/gzip"
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -23,6 +26,10 @@ const (
 	ErrGetTxsError = 40004
 	// ErrGetTxsByHashError represents an error when trying to get transactions by hash list.
 	ErrGetTxsByHashError = 40005
+	// ErrGetWithdrawalProofsError represents an error when trying to export withdrawal proofs.
+	ErrGetWithdrawalProofsError = 40006
+	// ErrGetMessageProofError represents an error when trying to get the merkle proof of an L2 withdrawal.
+	ErrGetMessageProofError = 40007
 )
 
 // QueryByAddressRequest the request parameter of address api
@@ -37,6 +44,40 @@ type QueryByHashRequest struct {
 	Txs []string `json:"txs" binding:"required,min=1,max=100"`
 }
 
+// QueryWithdrawalProofsRequest the request parameter of withdrawal proofs export api,
+// either the batch index or the block timestamp range must be given.
+type QueryWithdrawalProofsRequest struct {
+	BatchIndex *uint64 `form:"batch_index"`
+	StartTime  uint64  `form:"start_time"`
+	EndTime    uint64  `form:"end_time"`
+	Page       uint64  `form:"page" binding:"required,min=1"`
+	PageSize   uint64  `form:"page_size" binding:"required,min=1,max=1000"`
+}
+
+// QueryMessageProofRequest the request parameter of the message proof api
+type QueryMessageProofRequest struct {
+	MessageHash string  `form:"message_hash" binding:"required"`
+	Nonce       *uint64 `form:"nonce" binding:"required"`
+}
+
+// WithdrawalProof is the schema of the data needed to relay a finalized L2 withdrawal on L1
+type WithdrawalProof struct {
+	MessageHash string         `json:"message_hash"`
+	L2TxHash    string         `json:"l2_tx_hash"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Value       string         `json:"value"`
+	Nonce       string         `json:"nonce"`
+	Message     string         `json:"message"`
+	Proof       L2MessageProof `json:"proof"`
+}
+
+// WithdrawalProofsResultData contains return withdrawal proofs and total
+type WithdrawalProofsResultData struct {
+	Results []*WithdrawalProof `json:"results"`
+	Total   uint64             `json:"total"`
+}
+
 // ResultData contains return txs and total
 type ResultData struct {
 	Results []*TxHistoryInfo `json:"results"`
@@ -106,6 +147,41 @@ func RenderJSON(ctx *gin.Context, errCode int, err error, data interface{}) {
 	ctx.JSON(http.StatusOK, renderData)
 }
 
+// RenderCompressedJSON renders response with json, the response is gzip compressed if the client accepts it
+func RenderCompressedJSON(ctx *gin.Context, errCode int, err error, data interface{}) {
+	if !strings.Contains(ctx.GetHeader("Accept-Encoding"), "gzip") {
+		RenderJSON(ctx, errCode, err, data)
+		return
+	}
+
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+	renderData := Response{
+		ErrCode: errCode,
+		ErrMsg:  errMsg,
+		Data:    data,
+	}
+	body, marshalErr := json.Marshal(renderData)
+	if marshalErr != nil {
+		RenderFatal(ctx, marshalErr)
+		return
+	}
+
+	ctx.Header("Content-Encoding", "gzip")
+	ctx.Header("Vary", "Accept-Encoding")
+	ctx.Header("Content-Type", "application/json; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	gz := gzip.NewWriter(ctx.Writer)
+	if _, writeErr := gz.Write(body); writeErr != nil {
+		_ = ctx.Error(writeErr)
+	}
+	if closeErr := gz.Close(); closeErr != nil {
+		_ = ctx.Error(closeErr)
+	}
+}
+
 // RenderSuccess renders success response with json
 func RenderSuccess(ctx *gin.Context, data interface{}) {
 	RenderJSON(ctx, Success, nil, data)
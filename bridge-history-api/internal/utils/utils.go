@@ -16,6 +16,8 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"golang.org/x/sync/errgroup"
 
+	"scroll-tech/common/withdrawtrie"
+
 	backendabi "scroll-tech/bridge-history-api/abi"
 )
 
@@ -62,8 +64,7 @@ func ComputeMessageHash(
 	messageNonce *big.Int,
 	message []byte,
 ) common.Hash {
-	data, _ := backendabi.IL2ScrollMessengerABI.Pack("relayMessage", sender, target, value, messageNonce, message)
-	return common.BytesToHash(crypto.Keccak256(data))
+	return withdrawtrie.ComputeMessageHash(sender, target, value, messageNonce, message)
 }
 
 type commitBatchArgs struct {
@@ -0,0 +1,69 @@
+//go:build ccc
+
+package ccc
+
+/*
+#cgo LDFLAGS: -lzkp -lm -ldl -lzktrie -L${SRCDIR}/lib/ -Wl,-rpath=${SRCDIR}/lib
+#include <stdlib.h>
+#include "./lib/libzkp.h"
+*/
+import "C" //nolint:typecheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// CheckBlock estimates the row usage of the block by the circuit capacity checker.
+func CheckBlock(trace *types.BlockTrace) (*RowUsage, error) {
+	if trace == nil {
+		return nil, errors.New("nil block trace")
+	}
+	traceByt, err := json.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+
+	traceStr := C.CString(string(traceByt))
+	defer C.free(unsafe.Pointer(traceStr))
+
+	cResult := C.check_block_capacity(traceStr)
+	defer C.free_c_chars(cResult)
+	return parseCapacityResult(C.GoString(cResult))
+}
+
+// CheckChunk estimates the row usage of the chunk of the blocks, in order, by the circuit capacity checker.
+func CheckChunk(traces []*types.BlockTrace) (*RowUsage, error) {
+	if len(traces) == 0 {
+		return nil, errors.New("no block traces")
+	}
+	tracesByt, err := json.Marshal(traces)
+	if err != nil {
+		return nil, err
+	}
+
+	tracesStr := C.CString(string(tracesByt))
+	defer C.free(unsafe.Pointer(tracesStr))
+
+	cResult := C.check_chunk_capacity(tracesStr)
+	defer C.free_c_chars(cResult)
+	return parseCapacityResult(C.GoString(cResult))
+}
+
+func parseCapacityResult(resultStr string) (*RowUsage, error) {
+	var result capacityResult
+	if err := json.Unmarshal([]byte(resultStr), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse capacity result: %w", err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("failed to check circuit capacity: %s", result.Error)
+	}
+	if result.RowUsage == nil {
+		return nil, errors.New("failed to check circuit capacity: empty row usage")
+	}
+	return result.RowUsage, nil
+}
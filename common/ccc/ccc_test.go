@@ -0,0 +1,41 @@
+//go:build ccc
+
+package ccc
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func readBlockTrace(t *testing.T, path string) *types.BlockTrace {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	trace := &types.BlockTrace{}
+	assert.NoError(t, json.Unmarshal(data, trace))
+	return trace
+}
+
+func TestCheckCapacity(t *testing.T) {
+	trace2 := readBlockTrace(t, "../testdata/blockTrace_02.json")
+	trace3 := readBlockTrace(t, "../testdata/blockTrace_03.json")
+
+	blockUsage, err := CheckBlock(trace2)
+	assert.NoError(t, err)
+	assert.True(t, blockUsage.IsOK)
+	assert.NotEmpty(t, blockUsage.RowUsageDetails)
+	for _, subCircuit := range blockUsage.RowUsageDetails {
+		assert.LessOrEqual(t, subCircuit.RowNumber, blockUsage.RowNumber)
+	}
+
+	chunkUsage, err := CheckChunk([]*types.BlockTrace{trace2, trace3})
+	assert.NoError(t, err)
+	assert.True(t, chunkUsage.IsOK)
+	assert.GreaterOrEqual(t, chunkUsage.RowNumber, blockUsage.RowNumber)
+
+	_, err = CheckChunk(nil)
+	assert.Error(t, err)
+}
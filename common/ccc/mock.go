@@ -0,0 +1,17 @@
+//go:build !ccc
+
+package ccc
+
+import (
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// CheckBlock returns ErrUnavailable, the circuit capacity checker is linked with the ccc build tag.
+func CheckBlock(*types.BlockTrace) (*RowUsage, error) {
+	return nil, ErrUnavailable
+}
+
+// CheckChunk returns ErrUnavailable, the circuit capacity checker is linked with the ccc build tag.
+func CheckChunk([]*types.BlockTrace) (*RowUsage, error) {
+	return nil, ErrUnavailable
+}
@@ -0,0 +1,31 @@
+// Package ccc asks the circuit capacity checker of libzkp whether blocks fit in the circuit, instead of trusting
+// the row consumption recorded by the sequencer.
+//
+// The checker is linked with the ccc build tag, the lib directory of the package holding libzkp (make libzkp),
+// it is unavailable otherwise.
+package ccc
+
+import (
+	"errors"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// ErrUnavailable is returned when the binary is built without the circuit capacity checker.
+var ErrUnavailable = errors.New("circuit capacity checker unavailable, build with the ccc tag")
+
+// RowUsage is the row usage of a block or a chunk estimated by the circuit capacity checker.
+type RowUsage struct {
+	// IsOK is false if any sub-circuit exceeds the rows of the circuit.
+	IsOK bool `json:"is_ok"`
+	// RowNumber is the row number of the sub-circuit using the most rows.
+	RowNumber uint64 `json:"row_number"`
+	// RowUsageDetails is the row number of every sub-circuit.
+	RowUsageDetails types.RowConsumption `json:"row_usage_details"`
+}
+
+// capacityResult is the CapacityResult returned by libzkp.
+type capacityResult struct {
+	RowUsage *RowUsage `json:"row_usage,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
@@ -10,8 +10,11 @@ import (
 
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 
 	"scroll-tech/common/docker"
 	"scroll-tech/common/version"
@@ -60,3 +63,29 @@ func TestDB(t *testing.T) {
 
 	assert.NoError(t, CloseDB(db))
 }
+
+func TestCountCallsWithoutDeadline(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	assert.NoError(t, err)
+	reg := prometheus.NewRegistry()
+	assert.NoError(t, CountCallsWithoutDeadline(db, reg))
+
+	type Batch struct {
+		Index uint64
+	}
+	var batches []Batch
+	assert.NoError(t, db.WithContext(context.Background()).Find(&batches).Error)
+	assert.NoError(t, db.WithContext(context.Background()).Where("index = ?", 1).Delete(&Batch{}).Error)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assert.NoError(t, db.WithContext(ctx).Find(&batches).Error)
+
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 1)
+	counts := make(map[string]float64)
+	for _, m := range families[0].GetMetric() {
+		counts[m.GetLabel()[0].GetValue()+" "+m.GetLabel()[1].GetValue()] = m.GetCounter().GetValue()
+	}
+	assert.Equal(t, map[string]float64{"query batches": 1, "delete batches": 1}, counts)
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// CountCallsWithoutDeadline counts the db calls made with a context without deadline, by operation and table.
+// Such a call blocks its caller for as long as the database does not answer, so the counter is expected to
+// stay at zero for the calls made by the loops.
+func CountCallsWithoutDeadline(db *gorm.DB, reg prometheus.Registerer) error {
+	dbCallsWithoutDeadlineTotal := promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "db_calls_without_deadline_total",
+		Help: "Total number of db calls made with a context without deadline.",
+	}, []string{"operation", "table"})
+
+	check := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Statement == nil || tx.Statement.Context == nil {
+				return
+			}
+			if _, ok := tx.Statement.Context.Deadline(); !ok {
+				dbCallsWithoutDeadlineTotal.WithLabelValues(operation, tx.Statement.Table).Inc()
+			}
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("deadline:create", check("create")); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("deadline:query", check("query")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("deadline:update", check("update")); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("deadline:delete", check("delete")); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("deadline:row", check("row")); err != nil {
+		return err
+	}
+	return callbacks.Raw().Before("gorm:raw").Register("deadline:raw", check("raw"))
+}
@@ -0,0 +1,68 @@
+use crate::{
+    types::CapacityResult,
+    utils::{c_char_to_vec, panic_catch, vec_to_c_char},
+};
+use libc::c_char;
+use prover::{
+    zkevm::{CircuitCapacityChecker, RowUsage},
+    BlockTrace,
+};
+use std::ptr::null;
+
+/// # Safety
+#[no_mangle]
+pub unsafe extern "C" fn check_block_capacity(block_trace: *const c_char) -> *const c_char {
+    let row_usage_result = panic_catch(|| {
+        let block_trace = c_char_to_vec(block_trace);
+        let block_trace = serde_json::from_slice::<BlockTrace>(&block_trace)
+            .map_err(|e| format!("failed to deserialize block trace: {e:?}"))?;
+
+        estimate_circuit_capacity(vec![block_trace])
+    })
+    .unwrap_or_else(|e| Err(format!("unwind error: {e:?}")));
+
+    capacity_result_to_c_char(row_usage_result)
+}
+
+/// # Safety
+#[no_mangle]
+pub unsafe extern "C" fn check_chunk_capacity(block_traces: *const c_char) -> *const c_char {
+    let row_usage_result = panic_catch(|| {
+        let block_traces = c_char_to_vec(block_traces);
+        let block_traces = serde_json::from_slice::<Vec<BlockTrace>>(&block_traces)
+            .map_err(|e| format!("failed to deserialize block traces: {e:?}"))?;
+
+        estimate_circuit_capacity(block_traces)
+    })
+    .unwrap_or_else(|e| Err(format!("unwind error: {e:?}")));
+
+    capacity_result_to_c_char(row_usage_result)
+}
+
+// A checker is created per call, so the row usage of the traces isn't accumulated into the previous calls.
+fn estimate_circuit_capacity(block_traces: Vec<BlockTrace>) -> Result<RowUsage, String> {
+    if block_traces.is_empty() {
+        return Err("no block traces to check".to_string());
+    }
+
+    let mut checker = CircuitCapacityChecker::new();
+    checker.light_mode = false;
+    checker
+        .estimate_circuit_capacity(&block_traces)
+        .map_err(|e| format!("failed to estimate circuit capacity: {e:?}"))
+}
+
+fn capacity_result_to_c_char(row_usage_result: Result<RowUsage, String>) -> *const c_char {
+    let r = match row_usage_result {
+        Ok(row_usage) => CapacityResult {
+            row_usage: Some(row_usage),
+            error: None,
+        },
+        Err(err) => CapacityResult {
+            row_usage: None,
+            error: Some(err),
+        },
+    };
+
+    serde_json::to_vec(&r).map_or(null(), vec_to_c_char)
+}
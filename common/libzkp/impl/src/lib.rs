@@ -1,6 +1,7 @@
 #![feature(once_cell)]
 
 mod batch;
+mod ccc;
 mod chunk;
 mod types;
 mod utils;
@@ -1,3 +1,4 @@
+use prover::zkevm::RowUsage;
 use serde::{Deserialize, Serialize};
 
 // Represents the result of a chunk proof checking operation.
@@ -20,3 +21,14 @@ pub struct ProofResult {
     #[serde(skip_serializing_if = "Option::is_none")]
     pub error: Option<String>,
 }
+
+// Encapsulates the result from estimating the circuit capacity of blocks.
+// `row_usage` holds the row usage of the blocks, the usage of every sub-circuit included.
+// `error` provides additional details in case the estimation failed.
+#[derive(Debug, Clone, Deserialize, Serialize)]
+pub struct CapacityResult {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub row_usage: Option<RowUsage>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub error: Option<String>,
+}
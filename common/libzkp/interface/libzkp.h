@@ -11,5 +11,8 @@ char* get_chunk_vk();
 char* gen_chunk_proof(char* block_traces);
 char verify_chunk_proof(char* proof);
 
+char* check_block_capacity(char* block_trace);
+char* check_chunk_capacity(char* block_traces);
+
 char* block_traces_to_chunk_info(char* block_traces);
 void free_c_chars(char* ptr);
@@ -0,0 +1,137 @@
+package observability
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/utils"
+)
+
+// StateDumpTimeout bounds the queries a state dumper runs against the database or a node.
+const StateDumpTimeout = 5 * time.Second
+
+// StateDumper returns the state of a pipeline component for the debug server, it is called
+// concurrently with the component and the state must be json serializable.
+type StateDumper func() interface{}
+
+var (
+	stateDumpersMu sync.RWMutex
+	stateDumpers   = make(map[string]StateDumper)
+)
+
+// RegisterStateDumper registers the state dumper of a pipeline component, a dumper registered
+// under the same name is replaced.
+func RegisterStateDumper(name string, dumper StateDumper) {
+	stateDumpersMu.Lock()
+	defer stateDumpersMu.Unlock()
+	stateDumpers[name] = dumper
+}
+
+// DebugServer starts the debug server serving pprof, goroutine dumps, gc stats and the pipeline
+// state on its own port, every request must carry the debug token as bearer token.
+func DebugServer(c *cli.Context) {
+	if !c.Bool(utils.DebugEnabled.Name) {
+		return
+	}
+	token := c.String(utils.DebugToken.Name)
+	if token == "" {
+		log.Error("debug server is enabled without debug token, not starting it")
+		return
+	}
+
+	address := fmt.Sprintf("%s:%d", c.String(utils.DebugAddr.Name), c.Int(utils.DebugPort.Name))
+	server := &http.Server{
+		Addr:              address,
+		Handler:           newDebugRouter(token),
+		ReadHeaderTimeout: time.Minute,
+	}
+	log.Info("Starting debug server", "address", address)
+
+	go func() {
+		if runServerErr := server.ListenAndServe(); runServerErr != nil && !errors.Is(runServerErr, http.ErrServerClosed) {
+			log.Crit("run debug http server failure", "error", runServerErr)
+		}
+	}()
+}
+
+func newDebugRouter(token string) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery(), debugAuthMiddleware(token))
+	pprof.Register(r)
+	r.GET("/debug/goroutines", dumpGoroutines)
+	r.GET("/debug/gc", dumpGCStats)
+	r.GET("/debug/pipeline", dumpPipelineState)
+	return r
+}
+
+func debugAuthMiddleware(token string) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// dumpGoroutines writes the stacks of all goroutines in the format of an unrecovered panic.
+func dumpGoroutines(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	if err := runtimepprof.Lookup("goroutine").WriteTo(c.Writer, 2); err != nil {
+		log.Warn("failed to dump goroutines", "error", err)
+	}
+}
+
+func dumpGCStats(c *gin.Context) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+
+	c.JSON(http.StatusOK, gin.H{
+		"goroutines":      runtime.NumGoroutine(),
+		"heap_alloc":      memStats.HeapAlloc,
+		"heap_inuse":      memStats.HeapInuse,
+		"heap_objects":    memStats.HeapObjects,
+		"sys":             memStats.Sys,
+		"next_gc":         memStats.NextGC,
+		"num_gc":          gcStats.NumGC,
+		"last_gc":         gcStats.LastGC,
+		"pause_total":     gcStats.PauseTotal.String(),
+		"gc_cpu_fraction": memStats.GCCPUFraction,
+	})
+}
+
+func dumpPipelineState(c *gin.Context) {
+	stateDumpersMu.RLock()
+	names := make([]string, 0, len(stateDumpers))
+	for name := range stateDumpers {
+		names = append(names, name)
+	}
+	dumpers := make([]StateDumper, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		dumpers = append(dumpers, stateDumpers[name])
+	}
+	stateDumpersMu.RUnlock()
+
+	state := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		state[name] = dumpers[i]()
+	}
+	c.JSON(http.StatusOK, state)
+}
@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	RegisterStateDumper("test", func() interface{} {
+		return map[string]interface{}{"height": 42}
+	})
+	router := newDebugRouter("secret")
+
+	serve := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/debug/pipeline", "/debug/gc", "/debug/goroutines", "/debug/pprof/"} {
+		assert.Equal(t, http.StatusUnauthorized, serve(path, "").Code, path)
+		assert.Equal(t, http.StatusUnauthorized, serve(path, "Bearer wrong").Code, path)
+		assert.Equal(t, http.StatusUnauthorized, serve(path, "secret").Code, path)
+		assert.Equal(t, http.StatusOK, serve(path, "Bearer secret").Code, path)
+	}
+
+	w := serve("/debug/pipeline", "Bearer secret")
+	var pipeline map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pipeline))
+	assert.Equal(t, map[string]interface{}{"height": float64(42)}, pipeline["test"])
+
+	w = serve("/debug/gc", "Bearer secret")
+	var gcStats map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &gcStats))
+	assert.Contains(t, gcStats, "goroutines")
+	assert.Contains(t, gcStats, "num_gc")
+
+	w = serve("/debug/goroutines", "Bearer secret")
+	assert.Contains(t, w.Body.String(), "goroutine")
+}
@@ -6,12 +6,8 @@ import (
 	"net/http"
 	"time"
 
-	// enable the pprof
-	_ "net/http/pprof"
-
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 	"gorm.io/gorm"
@@ -20,17 +16,25 @@ import (
 )
 
 // Server starts the metrics server on the given address, will be closed when the given
-// context is canceled.
+// context is canceled. The profiles are served by the authenticated debug server, they are only
+// served here too with the deprecated metrics.pprof flag, and the metrics are pushed to the StatsD
+// agent if configured.
 func Server(c *cli.Context, db *gorm.DB) {
+	DebugServer(c)
+	StatsDExporter(c)
+
 	if !c.Bool(utils.MetricsEnabled.Name) {
 		return
 	}
 
 	r := gin.New()
 	r.Use(gin.Recovery())
-	pprof.Register(r)
+	if c.Bool(utils.MetricsPprofEnabled.Name) {
+		log.Warn("serving pprof on the metrics server without authentication, use the debug server instead", "flag", utils.MetricsPprofEnabled.Name)
+		pprof.Register(r)
+	}
 	r.GET("/metrics", func(context *gin.Context) {
-		promhttp.Handler().ServeHTTP(context.Writer, context.Request)
+		utils.MetricsHandler().ServeHTTP(context.Writer, context.Request)
 	})
 
 	probeController := NewProbesController(db)
@@ -0,0 +1,167 @@
+package observability
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/utils"
+)
+
+// statsdMaxPacketSize keeps a udp packet of metric lines under the usual MTU.
+const statsdMaxPacketSize = 1432
+
+// statsdReplacer replaces the characters of the StatsD line format in the metric names and tags.
+var statsdReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", " ", "_", "\n", "_")
+
+// StatsDExporter pushes the metrics of the default registry to the StatsD or DogStatsD agent of the
+// metrics.statsd.addr flag, for the operators whose observability stack isn't Prometheus based.
+// The counters are pushed as the increments since the last push, the gauges as their values and the
+// histograms and summaries as the _sum and _count counters.
+func StatsDExporter(c *cli.Context) {
+	address := c.String(utils.MetricsStatsDAddr.Name)
+	if address == "" {
+		return
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		log.Error("failed to dial statsd agent, not pushing metrics", "address", address, "err", err)
+		return
+	}
+
+	exporter := newStatsDExporter(utils.MetricsGatherer(), c.String(utils.MetricsStatsDPrefix.Name), c.Bool(utils.MetricsStatsDTags.Name))
+	interval := c.Duration(utils.MetricsStatsDInterval.Name)
+	log.Info("Starting statsd metrics exporter", "address", address, "interval", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			packets, exportErr := exporter.export()
+			if exportErr != nil {
+				log.Warn("failed to gather metrics for statsd", "err", exportErr)
+			}
+			for _, packet := range packets {
+				if _, writeErr := conn.Write(packet); writeErr != nil {
+					log.Warn("failed to push metrics to statsd agent", "address", address, "err", writeErr)
+					break
+				}
+			}
+		}
+	}()
+}
+
+type statsDExporter struct {
+	gatherer prometheus.Gatherer
+	prefix   string
+	tags     bool
+
+	// counters are the last pushed values of the counters, by metric name and tags.
+	counters map[string]float64
+}
+
+func newStatsDExporter(gatherer prometheus.Gatherer, prefix string, tags bool) *statsDExporter {
+	return &statsDExporter{
+		gatherer: gatherer,
+		prefix:   prefix,
+		tags:     tags,
+		counters: make(map[string]float64),
+	}
+}
+
+// export gathers the metrics and returns the udp packets of their StatsD lines, the metrics gathered
+// despite a gathering error are exported as well.
+func (e *statsDExporter) export() ([][]byte, error) {
+	families, err := e.gatherer.Gather()
+
+	var lines []string
+	for _, family := range families {
+		name := family.GetName()
+		for _, metric := range family.Metric {
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				lines = e.appendCounter(lines, name, metric.Label, metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				lines = e.appendLine(lines, name, metric.Label, metric.GetGauge().GetValue(), "g")
+			case dto.MetricType_UNTYPED:
+				lines = e.appendLine(lines, name, metric.Label, metric.GetUntyped().GetValue(), "g")
+			case dto.MetricType_HISTOGRAM:
+				lines = e.appendCounter(lines, name+"_sum", metric.Label, metric.GetHistogram().GetSampleSum())
+				lines = e.appendCounter(lines, name+"_count", metric.Label, float64(metric.GetHistogram().GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				lines = e.appendCounter(lines, name+"_sum", metric.Label, metric.GetSummary().GetSampleSum())
+				lines = e.appendCounter(lines, name+"_count", metric.Label, float64(metric.GetSummary().GetSampleCount()))
+			}
+		}
+	}
+
+	var packets [][]byte
+	var packet bytes.Buffer
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacketSize {
+			packets = append(packets, append([]byte(nil), packet.Bytes()...))
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() > 0 {
+		packets = append(packets, packet.Bytes())
+	}
+	return packets, err
+}
+
+// appendCounter appends the increment of the counter since the last push, a counter below its last value
+// was reset and its value is the increment. An unchanged counter isn't pushed.
+func (e *statsDExporter) appendCounter(lines []string, name string, labels []*dto.LabelPair, value float64) []string {
+	metricName, tags := e.formatName(name, labels), e.formatTags(labels)
+	key := metricName + tags
+	last, exists := e.counters[key]
+	e.counters[key] = value
+	delta := value
+	if exists && value >= last {
+		delta = value - last
+	}
+	if delta == 0 {
+		return lines
+	}
+	return append(lines, metricName+":"+strconv.FormatFloat(delta, 'f', -1, 64)+"|c"+tags)
+}
+
+func (e *statsDExporter) appendLine(lines []string, name string, labels []*dto.LabelPair, value float64, metricType string) []string {
+	return append(lines, e.formatName(name, labels)+":"+strconv.FormatFloat(value, 'f', -1, 64)+"|"+metricType+e.formatTags(labels))
+}
+
+// formatName returns the metric name, the label values are appended to it if the labels aren't sent as tags.
+func (e *statsDExporter) formatName(name string, labels []*dto.LabelPair) string {
+	name = statsdReplacer.Replace(e.prefix + name)
+	if e.tags {
+		return name
+	}
+	for _, label := range labels {
+		name += "." + statsdReplacer.Replace(label.GetValue())
+	}
+	return name
+}
+
+// formatTags returns the DogStatsD tags of the labels, empty if the labels aren't sent as tags.
+func (e *statsDExporter) formatTags(labels []*dto.LabelPair) string {
+	if !e.tags || len(labels) == 0 {
+		return ""
+	}
+	tags := make([]string, len(labels))
+	for i, label := range labels {
+		tags[i] = fmt.Sprintf("%s:%s", statsdReplacer.Replace(label.GetName()), statsdReplacer.Replace(label.GetValue()))
+	}
+	return "|#" + strings.Join(tags, ",")
+}
@@ -0,0 +1,66 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsDExporter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "test"}, []string{"kind"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Help: "test"})
+	reg.MustRegister(counter, gauge, histogram)
+
+	counter.WithLabelValues("a").Add(3)
+	counter.WithLabelValues("b|c").Add(1)
+	gauge.Set(1.5)
+	histogram.Observe(2)
+
+	exporter := newStatsDExporter(reg, "scroll.", true)
+	packets, err := exporter.export()
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	assert.Equal(t, []string{
+		"scroll.test_gauge:1.5|g",
+		"scroll.test_seconds_sum:2|c",
+		"scroll.test_seconds_count:1|c",
+		"scroll.test_total:3|c|#kind:a",
+		"scroll.test_total:1|c|#kind:b_c",
+	}, strings.Split(string(packets[0]), "\n"))
+
+	// the counters are pushed as their increments, the unchanged ones are left out
+	counter.WithLabelValues("a").Add(2)
+	gauge.Set(0)
+	packets, err = exporter.export()
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	assert.Equal(t, []string{
+		"scroll.test_gauge:0|g",
+		"scroll.test_total:2|c|#kind:a",
+	}, strings.Split(string(packets[0]), "\n"))
+
+	// the label values are appended to the metric names without tags
+	packets, err = newStatsDExporter(reg, "", false).export()
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	assert.Contains(t, strings.Split(string(packets[0]), "\n"), "test_total.b_c:1|c")
+
+	// the lines are split into packets under the max packet size
+	for i := 0; i < 200; i++ {
+		counter.WithLabelValues(strings.Repeat("x", i)).Inc()
+	}
+	packets, err = newStatsDExporter(reg, "", true).export()
+	require.NoError(t, err)
+	assert.Greater(t, len(packets), 1)
+	var lines int
+	for _, packet := range packets {
+		assert.LessOrEqual(t, len(packet), statsdMaxPacketSize)
+		lines += len(strings.Split(string(packet), "\n"))
+	}
+	assert.Equal(t, 1+2+202, lines)
+}
@@ -0,0 +1,166 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// ErrL1MessageHashMismatch is returned when the hash of an L1 message transaction doesn't match its fields,
+// the hash the rollup contract loads from the L1 message queue.
+var ErrL1MessageHashMismatch = errors.New("l1 message hash mismatch")
+
+// Batch is the chunks committed by a commitBatch transaction.
+type Batch struct {
+	Index                      uint64
+	TotalL1MessagePoppedBefore uint64
+	ParentBatchHash            common.Hash
+	Chunks                     []*Chunk
+}
+
+// DataHash computes the data hash of the batch committed with the encoded chunks the way ScrollChain does in
+// commitBatch, so the bytes about to be committed are checked against the batch header before the commit gas is spent.
+//
+// The contract hashes the block contexts and the L2 transactions read from the chunk bytes, an L2 transaction by the
+// keccak of its payload, and the L1 messages popped by a block by the hashes of the L1 message queue at their queue
+// indexes, the skipped ones left out. The L1 messages included by the chunks of the batch stand for the queue, so the
+// hash of every L1 message transaction is recomputed from its fields.
+func (b *Batch) DataHash(encodedChunks [][]byte) (common.Hash, error) {
+	return b.dataHash(encodedChunks, true)
+}
+
+// BlobDataHash computes the data hash of the batch committed with the encoded chunks of a batch version carrying the
+// L2 transactions in a blob, the encoded chunks hold the block contexts only and the L2 transactions are not hashed.
+func (b *Batch) BlobDataHash(encodedChunks [][]byte) (common.Hash, error) {
+	return b.dataHash(encodedChunks, false)
+}
+
+func (b *Batch) dataHash(encodedChunks [][]byte, withL2Transactions bool) (common.Hash, error) {
+	if len(b.Chunks) == 0 {
+		return common.Hash{}, errors.New("batch has no chunks")
+	}
+	if len(encodedChunks) != len(b.Chunks) {
+		return common.Hash{}, fmt.Errorf("batch %v has %v chunks but %v encoded chunks", b.Index, len(b.Chunks), len(encodedChunks))
+	}
+
+	l1MessageHashes := make(map[uint64]common.Hash)
+	for i, chunk := range b.Chunks {
+		for _, block := range chunk.Blocks {
+			for _, txData := range block.Transactions {
+				if txData.Type != types.L1MessageTxType {
+					continue
+				}
+				if err := checkL1MessageHash(txData); err != nil {
+					return common.Hash{}, fmt.Errorf("chunk %v of batch %v: %w", i, b.Index, err)
+				}
+				l1MessageHashes[txData.Nonce] = common.HexToHash(txData.TxHash)
+			}
+		}
+	}
+
+	totalL1MessagePoppedBefore := b.TotalL1MessagePoppedBefore
+	chunkHashes := make([]common.Hash, len(encodedChunks))
+	for i, encodedChunk := range encodedChunks {
+		chunkHash, numL1Messages, err := hashEncodedChunk(encodedChunk, totalL1MessagePoppedBefore, l1MessageHashes, withL2Transactions)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to hash chunk %v of batch %v: %w", i, b.Index, err)
+		}
+		chunkHashes[i] = chunkHash
+		totalL1MessagePoppedBefore += numL1Messages
+	}
+	return BatchDataHash(chunkHashes), nil
+}
+
+// hashEncodedChunk computes the hash of the chunk encoding the way ScrollChain does in commitChunk and returns it
+// along with the number of L1 messages popped by the chunk, the skipped ones included. A queue index popped by
+// the chunk without an L1 message hash is a skipped L1 message. The L2 transactions are left out of the hash and the
+// encoding unless withL2Transactions.
+func hashEncodedChunk(data []byte, totalL1MessagePoppedBefore uint64, l1MessageHashes map[uint64]common.Hash, withL2Transactions bool) (common.Hash, uint64, error) {
+	if len(data) == 0 {
+		return common.Hash{}, 0, errors.New("chunk is empty")
+	}
+	numBlocks := int(data[0])
+	if numBlocks == 0 {
+		return common.Hash{}, 0, errors.New("number of blocks is 0")
+	}
+	if len(data) < 1+60*numBlocks {
+		return common.Hash{}, 0, fmt.Errorf("chunk of %v blocks is %v bytes long", numBlocks, len(data))
+	}
+
+	hasher := NewKeccakWriter()
+	blockContexts := make([]*BlockContext, numBlocks)
+	for i := range blockContexts {
+		blockBytes := data[1+60*i : 1+60*(i+1)]
+		blockContext, err := DecodeBlockContext(blockBytes)
+		if err != nil {
+			return common.Hash{}, 0, err
+		}
+		if blockContext.NumL1Messages > blockContext.NumTransactions {
+			return common.Hash{}, 0, fmt.Errorf("block %v has %v transactions but %v l1 messages", blockContext.Number, blockContext.NumTransactions, blockContext.NumL1Messages)
+		}
+		blockContexts[i] = blockContext
+		_, _ = hasher.Write(blockBytes[:58])
+	}
+
+	queueIndex := totalL1MessagePoppedBefore
+	l2TxData := data[1+60*numBlocks:]
+	for _, blockContext := range blockContexts {
+		for i := uint16(0); i < blockContext.NumL1Messages; i++ {
+			if l1MessageHash, ok := l1MessageHashes[queueIndex]; ok {
+				_, _ = hasher.Write(l1MessageHash.Bytes())
+			}
+			queueIndex++
+		}
+		if !withL2Transactions {
+			continue
+		}
+		for i := blockContext.NumL1Messages; i < blockContext.NumTransactions; i++ {
+			if len(l2TxData) < 4 {
+				return common.Hash{}, 0, fmt.Errorf("missing length of l2 tx %v of block %v", i, blockContext.Number)
+			}
+			txLen := binary.BigEndian.Uint32(l2TxData[0:4])
+			if uint64(len(l2TxData)-4) < uint64(txLen) {
+				return common.Hash{}, 0, fmt.Errorf("l2 tx %v of block %v exceeds the chunk", i, blockContext.Number)
+			}
+			_, _ = hasher.Write(crypto.Keccak256(l2TxData[4 : 4+txLen]))
+			l2TxData = l2TxData[4+txLen:]
+		}
+	}
+	if len(l2TxData) != 0 {
+		return common.Hash{}, 0, fmt.Errorf("%v unexpected bytes after the l2 transactions", len(l2TxData))
+	}
+	return hasher.Hash(), queueIndex - totalL1MessagePoppedBefore, nil
+}
+
+// checkL1MessageHash checks the hash of the L1 message transaction is the one of the message enqueued with its
+// queue index, sender, target, value, gas limit and data.
+func checkL1MessageHash(txData *types.TransactionData) error {
+	decoder := GetHexDecoder()
+	defer decoder.Release()
+	data, err := decoder.Decode(txData.Data)
+	if err != nil {
+		return fmt.Errorf("failed to decode data of l1 message %v: %w", txData.Nonce, err)
+	}
+
+	value := new(big.Int)
+	if txData.Value != nil {
+		value = txData.Value.ToInt()
+	}
+	tx := types.NewTx(&types.L1MessageTx{
+		QueueIndex: txData.Nonce,
+		Gas:        txData.Gas,
+		To:         txData.To,
+		Value:      value,
+		Data:       data,
+		Sender:     txData.From,
+	})
+	if tx.Hash() != common.HexToHash(txData.TxHash) {
+		return fmt.Errorf("%w, queue index: %v, expected: %v, got: %v", ErrL1MessageHashMismatch, txData.Nonce, tx.Hash().Hex(), txData.TxHash)
+	}
+	return nil
+}
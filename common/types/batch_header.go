@@ -3,7 +3,11 @@ package types
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
 	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/types"
@@ -18,6 +22,90 @@ type BatchMeta struct {
 	EndChunkHash              string
 	TotalL1CommitGas          uint64
 	TotalL1CommitCalldataSize uint32
+	// CodecVersion is the codec version of the batch, a batch keeps the higher version of its parent.
+	CodecVersion uint8
+}
+
+// BatchHeaderV0FixedSize is the size of the BatchHeaderV0Codec encoding without the skipped L1 message bitmap.
+const BatchHeaderV0FixedSize = 89
+
+// maxL1MessagePoppedPerBatch bounds the L1 messages popped by a batch, the 128 KiB bitmap of more messages doesn't
+// fit in the commitBatch calldata, so a corrupted queue index fails instead of allocating a huge bitmap.
+const maxL1MessagePoppedPerBatch = 1 << 20
+
+// BatchHeaderSize returns the size of the encoded header of a batch popping l1MessagePopped L1 messages.
+func BatchHeaderSize(l1MessagePopped uint64) uint64 {
+	return BatchHeaderV0FixedSize + SkippedL1MessageBitmapSize(l1MessagePopped)
+}
+
+// SkippedL1MessageBitmapSize returns the size of the skipped L1 message bitmap of a batch popping
+// l1MessagePopped L1 messages, one 256-bit bitmap per 256 messages.
+func SkippedL1MessageBitmapSize(l1MessagePopped uint64) uint64 {
+	return 32 * ((l1MessagePopped + 255) / 256)
+}
+
+// ConstructSkippedBitmap constructs the skipped L1 message bitmap of a batch including the L1 messages of the
+// given queue indexes after totalL1MessagePoppedBefore messages were popped, the bitmap checked by commitBatch.
+// It returns the bitmap, one 256-bit big-endian bitmap per 256 popped messages with the bits of the skipped
+// messages set, and the total number of L1 messages popped after the batch.
+func ConstructSkippedBitmap(queueIndexes []uint64, totalL1MessagePoppedBefore uint64) ([]byte, uint64, error) {
+	// skipped L1 message bitmap, an array of 256-bit bitmaps
+	var skippedBitmap []*big.Int
+
+	// the first queue index that belongs to this batch
+	baseIndex := totalL1MessagePoppedBefore
+
+	// the next queue index that we need to process
+	nextIndex := totalL1MessagePoppedBefore
+
+	for _, currentIndex := range queueIndexes {
+		if currentIndex < nextIndex {
+			return nil, 0, fmt.Errorf("unexpected queue index, expected: %d, got: %d", nextIndex, currentIndex)
+		}
+		if currentIndex == math.MaxUint64 {
+			return nil, 0, fmt.Errorf("queue index %d overflows the total number of popped L1 messages", currentIndex)
+		}
+		if currentIndex-baseIndex >= maxL1MessagePoppedPerBatch {
+			return nil, 0, fmt.Errorf("queue index %d exceeds the %d L1 messages a batch pops at most after %d", currentIndex, uint64(maxL1MessagePoppedPerBatch), baseIndex)
+		}
+
+		// mark skipped messages
+		for skippedIndex := nextIndex; skippedIndex < currentIndex; skippedIndex++ {
+			quo := int((skippedIndex - baseIndex) / 256)
+			rem := int((skippedIndex - baseIndex) % 256)
+			for len(skippedBitmap) <= quo {
+				bitmap := big.NewInt(0)
+				skippedBitmap = append(skippedBitmap, bitmap)
+			}
+			skippedBitmap[quo].SetBit(skippedBitmap[quo], rem, 1)
+		}
+
+		// process included message
+		quo := int((currentIndex - baseIndex) / 256)
+		for len(skippedBitmap) <= quo {
+			bitmap := big.NewInt(0)
+			skippedBitmap = append(skippedBitmap, bitmap)
+		}
+
+		nextIndex = currentIndex + 1
+	}
+
+	bitmapBytes := make([]byte, len(skippedBitmap)*32)
+	for ii, num := range skippedBitmap {
+		bytes := num.Bytes()
+		padding := 32 - len(bytes)
+		copy(bitmapBytes[32*ii+padding:], bytes)
+	}
+	return bitmapBytes, nextIndex, nil
+}
+
+// BatchDataHash returns the data hash of a batch, the hash of its chunk hashes in chunk order.
+func BatchDataHash(chunkHashes []common.Hash) common.Hash {
+	dataBytes := make([]byte, 0, len(chunkHashes)*common.HashLength)
+	for _, chunkHash := range chunkHashes {
+		dataBytes = append(dataBytes, chunkHash.Bytes()...)
+	}
+	return crypto.Keccak256Hash(dataBytes)
 }
 
 // BatchHeader contains batch header info to be committed.
@@ -32,30 +120,34 @@ type BatchHeader struct {
 	skippedL1MessageBitmap []byte
 }
 
+// ChunkHashFunc returns the hash of a chunk the batch data hash is computed over.
+type ChunkHashFunc func(chunk *Chunk, totalL1MessagePoppedBefore uint64) (common.Hash, error)
+
 // NewBatchHeader creates a new BatchHeader
 func NewBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk) (*BatchHeader, error) {
-	// buffer for storing chunk hashes in order to compute the batch data hash
-	var dataBytes []byte
+	return NewBatchHeaderWithChunkHash(version, batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks, (*Chunk).Hash)
+}
 
-	// skipped L1 message bitmap, an array of 256-bit bitmaps
-	var skippedBitmap []*big.Int
+// NewBatchHeaderWithChunkHash creates a new BatchHeader whose data hash is computed over the chunk hashes of chunkHash,
+// for the batch versions hashing their chunks differently.
+func NewBatchHeaderWithChunkHash(version uint8, batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk, chunkHash ChunkHashFunc) (*BatchHeader, error) {
+	return newBatchHeader(version, batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks, chunkHash, runtime.GOMAXPROCS(0))
+}
 
-	// the first queue index that belongs to this batch
-	baseIndex := totalL1MessagePoppedBefore
+// newBatchHeader creates a new BatchHeader, the chunk hashes are computed by the given number of goroutines.
+func newBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*Chunk, chunkHash ChunkHashFunc, workers int) (*BatchHeader, error) {
+	// the total number of L1 messages popped before each chunk, the input of its hash
+	chunkL1MessagePoppedBefore := make([]uint64, len(chunks))
+
+	// the queue indexes of the L1 messages included in the batch, in order
+	var queueIndexes []uint64
 
 	// the next queue index that we need to process
 	nextIndex := totalL1MessagePoppedBefore
 
 	for chunkID, chunk := range chunks {
-		// build data hash
-		totalL1MessagePoppedBeforeChunk := nextIndex
-		chunkHash, err := chunk.Hash(totalL1MessagePoppedBeforeChunk)
-		if err != nil {
-			return nil, err
-		}
-		dataBytes = append(dataBytes, chunkHash.Bytes()...)
+		chunkL1MessagePoppedBefore[chunkID] = nextIndex
 
-		// build skip bitmap
 		for blockID, block := range chunk.Blocks {
 			for _, tx := range block.Transactions {
 				if tx.Type != types.L1MessageTxType {
@@ -67,45 +159,29 @@ func NewBatchHeader(version uint8, batchIndex, totalL1MessagePoppedBefore uint64
 					return nil, fmt.Errorf("unexpected batch payload, expected queue index: %d, got: %d. Batch index: %d, chunk index in batch: %d, block index in chunk: %d, block hash: %v, transaction hash: %v", nextIndex, currentIndex, batchIndex, chunkID, blockID, block.Header.Hash(), tx.TxHash)
 				}
 
-				// mark skipped messages
-				for skippedIndex := nextIndex; skippedIndex < currentIndex; skippedIndex++ {
-					quo := int((skippedIndex - baseIndex) / 256)
-					rem := int((skippedIndex - baseIndex) % 256)
-					for len(skippedBitmap) <= quo {
-						bitmap := big.NewInt(0)
-						skippedBitmap = append(skippedBitmap, bitmap)
-					}
-					skippedBitmap[quo].SetBit(skippedBitmap[quo], rem, 1)
-				}
-
-				// process included message
-				quo := int((currentIndex - baseIndex) / 256)
-				for len(skippedBitmap) <= quo {
-					bitmap := big.NewInt(0)
-					skippedBitmap = append(skippedBitmap, bitmap)
-				}
-
+				queueIndexes = append(queueIndexes, currentIndex)
 				nextIndex = currentIndex + 1
 			}
 		}
 	}
 
-	// compute data hash
-	dataHash := crypto.Keccak256Hash(dataBytes)
+	bitmapBytes, totalL1MessagePopped, err := ConstructSkippedBitmap(queueIndexes, totalL1MessagePoppedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct skipped L1 message bitmap of batch %d: %w", batchIndex, err)
+	}
 
-	// compute skipped bitmap
-	bitmapBytes := make([]byte, len(skippedBitmap)*32)
-	for ii, num := range skippedBitmap {
-		bytes := num.Bytes()
-		padding := 32 - len(bytes)
-		copy(bitmapBytes[32*ii+padding:], bytes)
+	// compute data hash over the chunk hashes in chunk order
+	chunkHashes, err := hashChunks(chunks, chunkL1MessagePoppedBefore, chunkHash, workers)
+	if err != nil {
+		return nil, err
 	}
+	dataHash := BatchDataHash(chunkHashes)
 
 	return &BatchHeader{
 		version:                version,
 		batchIndex:             batchIndex,
-		l1MessagePopped:        nextIndex - totalL1MessagePoppedBefore,
-		totalL1MessagePopped:   nextIndex,
+		l1MessagePopped:        totalL1MessagePopped - totalL1MessagePoppedBefore,
+		totalL1MessagePopped:   totalL1MessagePopped,
 		dataHash:               dataHash,
 		parentBatchHash:        parentBatchHash,
 		skippedL1MessageBitmap: bitmapBytes,
@@ -122,11 +198,26 @@ func (b *BatchHeader) BatchIndex() uint64 {
 	return b.batchIndex
 }
 
+// L1MessagePopped returns the number of L1 messages popped in this batch.
+func (b *BatchHeader) L1MessagePopped() uint64 {
+	return b.l1MessagePopped
+}
+
 // TotalL1MessagePopped returns the total number of L1 messages popped in the BatchHeader.
 func (b *BatchHeader) TotalL1MessagePopped() uint64 {
 	return b.totalL1MessagePopped
 }
 
+// DataHash returns the data hash of the BatchHeader.
+func (b *BatchHeader) DataHash() common.Hash {
+	return b.dataHash
+}
+
+// ParentBatchHash returns the parent batch hash of the BatchHeader.
+func (b *BatchHeader) ParentBatchHash() common.Hash {
+	return b.parentBatchHash
+}
+
 // SkippedL1MessageBitmap returns the skipped L1 message bitmap in the BatchHeader.
 func (b *BatchHeader) SkippedL1MessageBitmap() []byte {
 	return b.skippedL1MessageBitmap
@@ -134,14 +225,14 @@ func (b *BatchHeader) SkippedL1MessageBitmap() []byte {
 
 // Encode encodes the BatchHeader into RollupV2 BatchHeaderV0Codec Encoding.
 func (b *BatchHeader) Encode() []byte {
-	batchBytes := make([]byte, 89+len(b.skippedL1MessageBitmap))
+	batchBytes := make([]byte, BatchHeaderV0FixedSize+len(b.skippedL1MessageBitmap))
 	batchBytes[0] = b.version
 	binary.BigEndian.PutUint64(batchBytes[1:], b.batchIndex)
 	binary.BigEndian.PutUint64(batchBytes[9:], b.l1MessagePopped)
 	binary.BigEndian.PutUint64(batchBytes[17:], b.totalL1MessagePopped)
 	copy(batchBytes[25:], b.dataHash[:])
 	copy(batchBytes[57:], b.parentBatchHash[:])
-	copy(batchBytes[89:], b.skippedL1MessageBitmap[:])
+	copy(batchBytes[BatchHeaderV0FixedSize:], b.skippedL1MessageBitmap[:])
 	return batchBytes
 }
 
@@ -152,7 +243,7 @@ func (b *BatchHeader) Hash() common.Hash {
 
 // DecodeBatchHeader attempts to decode the given byte slice into a BatchHeader.
 func DecodeBatchHeader(data []byte) (*BatchHeader, error) {
-	if len(data) < 89 {
+	if len(data) < BatchHeaderV0FixedSize {
 		return nil, fmt.Errorf("insufficient data for BatchHeader")
 	}
 	b := &BatchHeader{
@@ -162,7 +253,46 @@ func DecodeBatchHeader(data []byte) (*BatchHeader, error) {
 		totalL1MessagePopped:   binary.BigEndian.Uint64(data[17:25]),
 		dataHash:               common.BytesToHash(data[25:57]),
 		parentBatchHash:        common.BytesToHash(data[57:89]),
-		skippedL1MessageBitmap: data[89:],
+		skippedL1MessageBitmap: data[BatchHeaderV0FixedSize:],
 	}
 	return b, nil
 }
+
+// hashChunks computes the chunk hashes with up to the given number of goroutines, the hashes are in chunk
+// order and the error is the one of the first failing chunk, so the result does not depend on the scheduling.
+func hashChunks(chunks []*Chunk, l1MessagePoppedBefore []uint64, chunkHash ChunkHashFunc, workers int) ([]common.Hash, error) {
+	hashes := make([]common.Hash, len(chunks))
+	errs := make([]error, len(chunks))
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	if workers <= 1 {
+		for i, chunk := range chunks {
+			if hashes[i], errs[i] = chunkHash(chunk, l1MessagePoppedBefore[i]); errs[i] != nil {
+				return nil, errs[i]
+			}
+		}
+		return hashes, nil
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(chunks); i = int(next.Add(1) - 1) {
+				hashes[i], errs[i] = chunkHash(chunks[i], l1MessagePoppedBefore[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
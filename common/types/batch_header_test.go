@@ -2,7 +2,9 @@ package types
 
 import (
 	"encoding/json"
+	"math"
 	"os"
+	"runtime"
 	"testing"
 
 	"github.com/scroll-tech/go-ethereum/common"
@@ -213,7 +215,7 @@ func TestBatchHeaderHash(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, batchHeader2)
 	hash2 := batchHeader2.Hash()
-	assert.Equal(t, "34de600163aa745d4513113137a5b54960d13f0d3f2849e490c4b875028bf930", common.Bytes2Hex(hash2.Bytes()))
+	assert.Equal(t, "d8df91197f2791c38a2cb3ab9e55e7a4f96fb06e1f22fd0e91158b5b6500ead6", common.Bytes2Hex(hash2.Bytes()))
 
 	// With L1 Msg
 	templateBlockTrace3, err := os.ReadFile("../testdata/blockTrace_04.json")
@@ -249,3 +251,150 @@ func TestBatchHeaderDecode(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, header, decoded)
 }
+
+func TestBatchHeaderAccessors(t *testing.T) {
+	header := &BatchHeader{
+		version:                1,
+		batchIndex:             10,
+		l1MessagePopped:        20,
+		totalL1MessagePopped:   30,
+		dataHash:               common.HexToHash("0x01"),
+		parentBatchHash:        common.HexToHash("0x02"),
+		skippedL1MessageBitmap: []byte{0x01, 0x02, 0x03},
+	}
+
+	assert.Equal(t, uint8(1), header.Version())
+	assert.Equal(t, uint64(10), header.BatchIndex())
+	assert.Equal(t, uint64(20), header.L1MessagePopped())
+	assert.Equal(t, uint64(30), header.TotalL1MessagePopped())
+	assert.Equal(t, common.HexToHash("0x01"), header.DataHash())
+	assert.Equal(t, common.HexToHash("0x02"), header.ParentBatchHash())
+	assert.Equal(t, []byte{0x01, 0x02, 0x03}, header.SkippedL1MessageBitmap())
+}
+
+func TestNewBatchHeaderParallel(t *testing.T) {
+	var chunks []*Chunk
+	for _, file := range []string{"blockTrace_02.json", "blockTrace_03.json", "blockTrace_04.json", "blockTrace_05.json", "blockTrace_06.json", "blockTrace_07.json"} {
+		templateBlockTrace, err := os.ReadFile("../testdata/" + file)
+		assert.NoError(t, err)
+		wrappedBlock := &WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		chunks = append(chunks, &Chunk{Blocks: []*WrappedBlock{wrappedBlock}})
+	}
+
+	// the data hash does not depend on the number of workers
+	expected, err := newBatchHeader(1, 1, 0, common.Hash{}, chunks[:3], (*Chunk).Hash, 1)
+	assert.NoError(t, err)
+	for _, workers := range []int{2, 3, 16} {
+		batchHeader, err := newBatchHeader(1, 1, 0, common.Hash{}, chunks[:3], (*Chunk).Hash, workers)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.DataHash(), batchHeader.DataHash())
+		assert.Equal(t, expected.Hash(), batchHeader.Hash())
+	}
+
+	// the error is the one of the first failing chunk
+	tooManyBlocks := &Chunk{}
+	for i := 0; i < 256; i++ {
+		tooManyBlocks.Blocks = append(tooManyBlocks.Blocks, chunks[0].Blocks[0])
+	}
+	failing := []*Chunk{chunks[0], chunks[1], tooManyBlocks, {}, chunks[0]}
+	for _, workers := range []int{1, 4} {
+		_, err = newBatchHeader(1, 1, 0, common.Hash{}, failing, (*Chunk).Hash, workers)
+		assert.ErrorContains(t, err, "number of blocks exceeds 1 byte")
+	}
+}
+
+// BenchmarkNewBatchHeader measures the batch header construction of a full batch, with the chunk
+// hashes computed sequentially and in parallel.
+func BenchmarkNewBatchHeader(b *testing.B) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_03.json")
+	assert.NoError(b, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(b, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	chunks := make([]*Chunk, 45)
+	for i := range chunks {
+		chunks[i] = &Chunk{}
+		for j := 0; j < 100; j++ {
+			chunks[i].Blocks = append(chunks[i].Blocks, wrappedBlock)
+		}
+	}
+
+	for _, bench := range []struct {
+		name    string
+		workers int
+	}{{"sequential", 1}, {"parallel", runtime.GOMAXPROCS(0)}} {
+		workers := bench.workers
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := newBatchHeader(1, 1, 0, common.Hash{}, chunks, (*Chunk).Hash, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchHeaderSize(t *testing.T) {
+	for _, tt := range []struct {
+		l1MessagePopped uint64
+		bitmapSize      uint64
+	}{
+		{0, 0},
+		{1, 32},
+		{256, 32},
+		{257, 64},
+	} {
+		assert.Equal(t, tt.bitmapSize, SkippedL1MessageBitmapSize(tt.l1MessagePopped))
+		assert.Equal(t, BatchHeaderV0FixedSize+tt.bitmapSize, BatchHeaderSize(tt.l1MessagePopped))
+	}
+
+	// the header built by NewBatchHeader has the size expected by the rollup contract
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_04.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	chunk := &Chunk{Blocks: []*WrappedBlock{wrappedBlock}}
+	batchHeader, err := NewBatchHeader(0, 1, 0, common.Hash{}, []*Chunk{chunk})
+	assert.NoError(t, err)
+	assert.Equal(t, BatchHeaderSize(batchHeader.L1MessagePopped()), uint64(len(batchHeader.Encode())))
+
+	chunkHash, err := chunk.Hash(0)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchDataHash([]common.Hash{chunkHash}), batchHeader.DataHash())
+}
+
+func TestConstructSkippedBitmap(t *testing.T) {
+	// no L1 messages
+	bitmap, totalL1MessagePopped, err := ConstructSkippedBitmap(nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, bitmap)
+	assert.Equal(t, uint64(10), totalL1MessagePopped)
+
+	// 10 and 12 are included, 11 and 13 skipped before 14
+	bitmap, totalL1MessagePopped, err = ConstructSkippedBitmap([]uint64{10, 12, 14}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(15), totalL1MessagePopped)
+	assert.Equal(t, "000000000000000000000000000000000000000000000000000000000000000a", common.Bytes2Hex(bitmap))
+
+	// the skipped messages before the first included one, spanning two bitmaps
+	bitmap, totalL1MessagePopped, err = ConstructSkippedBitmap([]uint64{300}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(301), totalL1MessagePopped)
+	assert.Len(t, bitmap, 64)
+	assert.Equal(t, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", common.Bytes2Hex(bitmap[:32]))
+	assert.Equal(t, "00000000000000000000000000000000000000000000000000000fffffffffff", common.Bytes2Hex(bitmap[32:]))
+	assert.Equal(t, SkippedL1MessageBitmapSize(totalL1MessagePopped), uint64(len(bitmap)))
+
+	// the queue indexes are included in order after the popped ones
+	_, _, err = ConstructSkippedBitmap([]uint64{5}, 10)
+	assert.ErrorContains(t, err, "unexpected queue index, expected: 10, got: 5")
+	_, _, err = ConstructSkippedBitmap([]uint64{12, 12}, 10)
+	assert.ErrorContains(t, err, "unexpected queue index, expected: 13, got: 12")
+
+	// overflows
+	_, _, err = ConstructSkippedBitmap([]uint64{math.MaxUint64}, math.MaxUint64-1)
+	assert.ErrorContains(t, err, "overflows")
+	_, _, err = ConstructSkippedBitmap([]uint64{10 + maxL1MessagePoppedPerBatch}, 10)
+	assert.ErrorContains(t, err, "exceeds")
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func readBatchTestChunk(t *testing.T, file string) *Chunk {
+	templateBlockTrace, err := os.ReadFile("../testdata/" + file)
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	return &Chunk{Blocks: []*WrappedBlock{wrappedBlock}}
+}
+
+func TestBatchDataHash(t *testing.T) {
+	// blockTrace_05 includes the L1 messages 37 to 41, the ones before are skipped
+	chunks := []*Chunk{
+		readBatchTestChunk(t, "blockTrace_02.json"),
+		readBatchTestChunk(t, "blockTrace_03.json"),
+		readBatchTestChunk(t, "blockTrace_05.json"),
+	}
+	encodeChunks := func(chunks []*Chunk, totalL1MessagePoppedBefore uint64) [][]byte {
+		var encodedChunks [][]byte
+		for _, chunk := range chunks {
+			chunkBytes, err := chunk.Encode(totalL1MessagePoppedBefore)
+			assert.NoError(t, err)
+			encodedChunks = append(encodedChunks, chunkBytes)
+			totalL1MessagePoppedBefore += chunk.NumL1Messages(totalL1MessagePoppedBefore)
+		}
+		return encodedChunks
+	}
+	encodedChunks := encodeChunks(chunks, 0)
+	batch := &Batch{Index: 1, TotalL1MessagePoppedBefore: 0, Chunks: chunks}
+	dataHash, err := batch.DataHash(encodedChunks)
+	assert.NoError(t, err)
+
+	batchHeader, err := NewBatchHeader(0, 1, 0, common.Hash{}, chunks)
+	assert.NoError(t, err)
+	assert.Equal(t, batchHeader.DataHash(), dataHash)
+
+	// the data hash depends on the L1 messages popped before the batch
+	batch.TotalL1MessagePoppedBefore = 30
+	otherDataHash, err := batch.DataHash(encodeChunks(chunks, 30))
+	assert.NoError(t, err)
+	assert.NotEqual(t, dataHash, otherDataHash)
+
+	// an l2 tx is hashed by its committed payload, not by the hash of the trace
+	tampered := append([]byte{}, encodedChunks[1]...)
+	tampered[len(tampered)-1] ^= 1
+	batch = &Batch{Index: 1, Chunks: chunks}
+	tamperedDataHash, err := batch.DataHash([][]byte{encodedChunks[0], tampered, encodedChunks[2]})
+	assert.NoError(t, err)
+	assert.NotEqual(t, batchHeader.DataHash(), tamperedDataHash)
+
+	// the chunk bytes must be the block contexts and the l2 txs they count
+	_, err = batch.DataHash([][]byte{encodedChunks[0], encodedChunks[1][:len(encodedChunks[1])-1], encodedChunks[2]})
+	assert.ErrorContains(t, err, "exceeds the chunk")
+	_, err = batch.DataHash([][]byte{encodedChunks[0], append(append([]byte{}, encodedChunks[1]...), 0), encodedChunks[2]})
+	assert.ErrorContains(t, err, "unexpected bytes after the l2 transactions")
+	_, err = batch.DataHash(encodedChunks[:2])
+	assert.ErrorContains(t, err, "3 chunks but 2 encoded chunks")
+
+	// the hash of an L1 message must be the one of its fields
+	tamperedChunk := readBatchTestChunk(t, "blockTrace_05.json")
+	tamperedChunk.Blocks[0].Transactions[1].Gas++
+	batch = &Batch{Index: 1, Chunks: []*Chunk{chunks[0], tamperedChunk}}
+	_, err = batch.DataHash([][]byte{encodedChunks[0], encodedChunks[2]})
+	assert.ErrorIs(t, err, ErrL1MessageHashMismatch)
+
+	_, err = (&Batch{Index: 1}).DataHash(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+)
+
+const (
+	// BlobFieldElements is the number of field elements of an EIP-4844 blob.
+	BlobFieldElements = 4096
+	// BlobBytesPerFieldElement is the number of payload bytes packed into a field element, the leading
+	// byte of every 32-byte field element is left zero so the element is below the BLS12-381 modulus.
+	BlobBytesPerFieldElement = 31
+	// MaxBlobDataSize is the number of payload bytes a blob holds.
+	MaxBlobDataSize = BlobFieldElements * BlobBytesPerFieldElement
+	// MaxBlobChunks is the number of chunks the blob metadata has room for.
+	MaxBlobChunks = 15
+
+	// blobMetadataSize is the size of the blob metadata, numChunks (2 bytes) || chunkSize (4 bytes) * MaxBlobChunks.
+	blobMetadataSize = 2 + 4*MaxBlobChunks
+)
+
+// TxPayload returns the rlp-encoded L2 transactions of the block concatenated.
+func (w *WrappedBlock) TxPayload() ([]byte, error) {
+	var payload []byte
+	for _, txData := range w.Transactions {
+		if txData.Type == types.L1MessageTxType {
+			continue
+		}
+		rlpTxData, err := convertTxDataToRLPEncoding(txData, w.typedTxFields(txData))
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, rlpTxData...)
+	}
+	return payload, nil
+}
+
+// TxPayload returns the rlp-encoded L2 transactions of the chunk concatenated in block order,
+// it is the part of the chunk committed in the blob instead of the calldata.
+func (c *Chunk) TxPayload() ([]byte, error) {
+	var payload bytes.Buffer
+	if _, err := c.WriteTxPayloadTo(&payload); err != nil {
+		return nil, err
+	}
+	return payload.Bytes(), nil
+}
+
+// NewBatchPayload returns the payload of the batch committed in the blob,
+//
+//	numChunks (2 bytes) || chunkSize (4 bytes) * MaxBlobChunks || chunkTxPayload * numChunks
+//
+// where chunkSize is the size of the tx payload of the chunk, the sizes past numChunks are zero.
+func NewBatchPayload(chunks []*Chunk) ([]byte, error) {
+	var payload bytes.Buffer
+	if _, err := WriteBatchPayload(&payload, chunks); err != nil {
+		return nil, err
+	}
+	return payload.Bytes(), nil
+}
+
+// BatchBlob is the blob of a batch committed by a blob-carrying transaction.
+type BatchBlob struct {
+	Blob          *kzg4844.Blob
+	Commitment    kzg4844.Commitment
+	VersionedHash common.Hash
+}
+
+// NewBatchBlob packs the batch payload of the chunks into a blob, see NewBatchPayload.
+func NewBatchBlob(chunks []*Chunk) (*BatchBlob, error) {
+	payload, err := NewBatchPayload(chunks)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchBlob(payload)
+}
+
+// NewCompressedBatchBlob packs the zstd compressed batch payload of the chunks into a blob.
+func NewCompressedBatchBlob(chunks []*Chunk) (*BatchBlob, error) {
+	payload, err := NewBatchPayload(chunks)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchBlob(CompressBatchPayload(payload))
+}
+
+func newBatchBlob(data []byte) (*BatchBlob, error) {
+	blob, err := NewBlob(data)
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := kzg4844.BlobToCommitment(*blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the blob commitment: %w", err)
+	}
+	return &BatchBlob{
+		Blob:          blob,
+		Commitment:    commitment,
+		VersionedHash: kzg4844.CalcBlobHashV1(sha256.New(), &commitment),
+	}, nil
+}
+
+// Sidecar returns the sidecar of the blob-carrying transaction committing the blob.
+func (b *BatchBlob) Sidecar() (*types.BlobTxSidecar, error) {
+	proof, err := kzg4844.ComputeBlobProof(*b.Blob, b.Commitment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the blob proof: %w", err)
+	}
+	return &types.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{*b.Blob},
+		Commitments: []kzg4844.Commitment{b.Commitment},
+		Proofs:      []kzg4844.Proof{proof},
+	}, nil
+}
+
+// DecodeBatchBlob returns the tx payloads of the chunks packed into the blob by NewBatchBlob.
+func DecodeBatchBlob(blob *kzg4844.Blob) ([][]byte, error) {
+	data, err := DecodeBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	return readBatchPayload(bytes.NewReader(data))
+}
+
+// DecodeCompressedBatchBlob returns the tx payloads of the chunks packed into the blob by NewCompressedBatchBlob.
+func DecodeCompressedBatchBlob(blob *kzg4844.Blob) ([][]byte, error) {
+	data, err := DecodeBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newBatchPayloadReader(data)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return readBatchPayload(reader)
+}
+
+// readBatchPayload reads the tx payloads of the chunks of a batch payload, see NewBatchPayload,
+// the bytes past the payload are left unread.
+func readBatchPayload(r io.Reader) ([][]byte, error) {
+	metadata := make([]byte, blobMetadataSize)
+	if _, err := io.ReadFull(r, metadata); err != nil {
+		return nil, fmt.Errorf("failed to read the batch payload metadata: %w", err)
+	}
+
+	numChunks := int(binary.BigEndian.Uint16(metadata[0:2]))
+	if numChunks == 0 || numChunks > MaxBlobChunks {
+		return nil, fmt.Errorf("invalid number of chunks %v", numChunks)
+	}
+	payloads := make([][]byte, numChunks)
+	for i := 0; i < numChunks; i++ {
+		size := binary.BigEndian.Uint32(metadata[2+4*i : 6+4*i])
+		// the size is checked before the allocation, a decompressed payload is bounded all the same
+		if size > maxDecompressedBatchPayloadSize {
+			return nil, fmt.Errorf("tx payload of chunk %v exceeds the blob", i)
+		}
+		payloads[i] = make([]byte, size)
+		if _, err := io.ReadFull(r, payloads[i]); err != nil {
+			return nil, fmt.Errorf("tx payload of chunk %v exceeds the blob: %w", i, err)
+		}
+	}
+	return payloads, nil
+}
+
+// NewBlob packs the data into the field elements of a blob, 31 bytes per field element.
+func NewBlob(data []byte) (*kzg4844.Blob, error) {
+	if len(data) > MaxBlobDataSize {
+		return nil, fmt.Errorf("blob data size %v exceeds the limit %v", len(data), MaxBlobDataSize)
+	}
+	var blob kzg4844.Blob
+	for i := 0; i*BlobBytesPerFieldElement < len(data); i++ {
+		end := (i + 1) * BlobBytesPerFieldElement
+		if end > len(data) {
+			end = len(data)
+		}
+		copy(blob[32*i+1:32*(i+1)], data[i*BlobBytesPerFieldElement:end])
+	}
+	return &blob, nil
+}
+
+// DecodeBlob unpacks the MaxBlobDataSize bytes of data packed into the field elements of the blob.
+func DecodeBlob(blob *kzg4844.Blob) ([]byte, error) {
+	data := make([]byte, 0, MaxBlobDataSize)
+	for i := 0; i < BlobFieldElements; i++ {
+		if blob[32*i] != 0 {
+			return nil, fmt.Errorf("field element %v has a non-zero leading byte", i)
+		}
+		data = append(data, blob[32*i+1:32*(i+1)]...)
+	}
+	return data, nil
+}
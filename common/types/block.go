@@ -2,19 +2,27 @@ package types
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/common/hexutil"
 	"github.com/scroll-tech/go-ethereum/core/types"
-	"github.com/scroll-tech/go-ethereum/log"
 )
 
 // CalldataNonZeroByteGas is the gas consumption per non zero byte in calldata.
 const CalldataNonZeroByteGas = 16
 
+// CalldataZeroByteGas is the gas consumption per zero byte in calldata.
+const CalldataZeroByteGas = 4
+
 // GetKeccak256Gas calculates the gas cost for computing the keccak256 hash of a given size.
 func GetKeccak256Gas(size uint64) uint64 {
 	return GetMemoryExpansionCost(size) + 30 + 6*((size+31)/32)
@@ -27,16 +35,374 @@ func GetMemoryExpansionCost(memoryByteSize uint64) uint64 {
 	return memoryCost
 }
 
+var (
+	// ErrBlockHeaderMissing is returned by Validate when the block has no header.
+	ErrBlockHeaderMissing = errors.New("block header is missing")
+	// ErrBlockNumberNotUint64 is returned when the block number doesn't fit in the BlockContext.
+	ErrBlockNumberNotUint64 = errors.New("block number is not uint64")
+	// ErrBlockNotChildOfParent is returned by Validate when the block number doesn't follow the parent one.
+	ErrBlockNotChildOfParent = errors.New("block is not the child of its parent")
+	// ErrBlockTimestampNotMonotonic is returned by Validate when the block timestamp is before the parent one.
+	ErrBlockTimestampNotMonotonic = errors.New("block timestamp is before its parent")
+	// ErrL1MessagesNotContiguous is returned by Validate when an L2 transaction precedes an L1 message.
+	ErrL1MessagesNotContiguous = errors.New("L1 messages are not contiguous at the start of the block")
+	// ErrL1MessagesNotSorted is returned by Validate when the queue indexes of the L1 messages don't increase
+	// from the number of L1 messages popped before the block.
+	ErrL1MessagesNotSorted = errors.New("L1 messages are not sorted by queue index")
+	// ErrRowConsumptionMissing is returned by Validate when the row consumption is required but unknown.
+	ErrRowConsumptionMissing = errors.New("block row consumption is missing")
+	// ErrWrappedBlockMalformed is returned by UnmarshalJSON when the block json is missing a field or has a malformed one.
+	ErrWrappedBlockMalformed = errors.New("wrapped block json is malformed")
+	// ErrTxNotReproducible is returned when the l2 transaction rebuilt from the trace doesn't hash to the signed one,
+	// e.g. a typed transaction whose fee caps or access list are unknown, so its encoding would not be the signed one.
+	ErrTxNotReproducible = errors.New("tx is not reproducible from the trace")
+)
+
+// TypedTxFields are the fields of the EIP-2930 and EIP-1559 transactions which types.TransactionData lacks.
+type TypedTxFields struct {
+	GasTipCap  *hexutil.Big     `json:"gasTipCap,omitempty"`
+	GasFeeCap  *hexutil.Big     `json:"gasFeeCap,omitempty"`
+	AccessList types.AccessList `json:"accessList,omitempty"`
+}
+
+// NewTypedTxFields returns the typed fields of the transaction, nil for a legacy transaction or an L1 message.
+func NewTypedTxFields(tx *types.Transaction) *TypedTxFields {
+	switch tx.Type() {
+	case types.AccessListTxType:
+		return &TypedTxFields{AccessList: tx.AccessList()}
+	case types.DynamicFeeTxType:
+		return &TypedTxFields{
+			GasTipCap:  (*hexutil.Big)(tx.GasTipCap()),
+			GasFeeCap:  (*hexutil.Big)(tx.GasFeeCap()),
+			AccessList: tx.AccessList(),
+		}
+	default:
+		return nil
+	}
+}
+
+// transactionDataJSON is the json of a transaction of WrappedBlock, the trace fields followed by the typed ones.
+type transactionDataJSON struct {
+	*types.TransactionData
+	*TypedTxFields
+}
+
+// WrappedBlockSchemaVersion is the version of the canonical json schema of WrappedBlock.
+const WrappedBlockSchemaVersion = 1
+
 // WrappedBlock contains the block's Header, Transactions and WithdrawTrieRoot hash.
+// Its json encoding is the canonical schema of wrappedBlockJSON.
 type WrappedBlock struct {
 	Header *types.Header `json:"header"`
 	// Transactions is only used for recover types.Transactions, the from of types.TransactionData field is missing.
-	Transactions         []*types.TransactionData `json:"transactions"`
-	WithdrawRoot         common.Hash              `json:"withdraw_trie_root,omitempty"`
-	RowConsumption       *types.RowConsumption    `json:"row_consumption"`
+	// RecoverTxSender recovers the from of a transaction from its signature.
+	Transactions   []*types.TransactionData `json:"transactions"`
+	WithdrawRoot   common.Hash              `json:"withdraw_trie_root"`
+	RowConsumption *types.RowConsumption    `json:"row_consumption,omitempty"`
+	// TypedTxFields holds the typed fields of the l2 transactions by tx hash, they are encoded inline with
+	// the transactions.
+	TypedTxFields map[common.Hash]*TypedTxFields `json:"-"`
+
+	// withdrawRootAbsent is set if the block was decoded from a legacy json without withdraw trie root,
+	// so its zero WithdrawRoot is not taken for the root of an empty trie.
+	withdrawRootAbsent bool
+
+	// txPayloadLengthCache is filled lazily by the estimations, which may run concurrently
+	// on the same block, e.g. the chunk proposer and the rollup api.
+	txPayloadLengthMu    sync.Mutex
 	txPayloadLengthCache map[string]uint64
 }
 
+// wrappedBlockJSON is the canonical json schema of WrappedBlock. The fields are encoded in this order, the
+// hash-critical fields are always present, with explicit zero values, and the row consumption is omitted
+// when unknown. The json without schema version is the legacy schema, whose fields may all be absent.
+type wrappedBlockJSON struct {
+	SchemaVersion  *uint64                `json:"schema_version"`
+	Header         *types.Header          `json:"header"`
+	Transactions   []*transactionDataJSON `json:"transactions"`
+	WithdrawRoot   *common.Hash           `json:"withdraw_trie_root"`
+	RowConsumption *types.RowConsumption  `json:"row_consumption,omitempty"`
+}
+
+// MarshalJSON encodes the block in the canonical json schema.
+func (w *WrappedBlock) MarshalJSON() ([]byte, error) {
+	if w.Header == nil {
+		return nil, errors.New("wrapped block header is missing")
+	}
+	version := uint64(WrappedBlockSchemaVersion)
+	return json.Marshal(&wrappedBlockJSON{
+		SchemaVersion:  &version,
+		Header:         w.Header,
+		Transactions:   w.transactionsJSON(),
+		WithdrawRoot:   &w.WithdrawRoot,
+		RowConsumption: w.RowConsumption,
+	})
+}
+
+// UnmarshalJSON decodes the block in the canonical or the legacy json schema, the canonical json
+// must contain all the hash-critical fields. The header and the transactions are required in both
+// schemas and every transaction is checked, so a malformed trace fails here with an ErrWrappedBlockMalformed
+// naming the offending field instead of failing later on a nil field.
+func (w *WrappedBlock) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		SchemaVersion  *uint64           `json:"schema_version"`
+		Header         json.RawMessage   `json:"header"`
+		Transactions   []json.RawMessage `json:"transactions"`
+		WithdrawRoot   json.RawMessage   `json:"withdraw_trie_root"`
+		RowConsumption json.RawMessage   `json:"row_consumption,omitempty"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return fmt.Errorf("%w: %v", ErrWrappedBlockMalformed, err)
+	}
+
+	if dec.SchemaVersion != nil && *dec.SchemaVersion != WrappedBlockSchemaVersion {
+		return fmt.Errorf("unsupported wrapped block schema version: %v", *dec.SchemaVersion)
+	}
+	switch {
+	case isJSONNull(dec.Header):
+		return fmt.Errorf("%w: wrapped block header is missing", ErrWrappedBlockMalformed)
+	case dec.Transactions == nil:
+		return fmt.Errorf("%w: wrapped block transactions are missing", ErrWrappedBlockMalformed)
+	case dec.SchemaVersion != nil && isJSONNull(dec.WithdrawRoot):
+		return fmt.Errorf("%w: wrapped block withdraw trie root is missing", ErrWrappedBlockMalformed)
+	}
+
+	header := new(types.Header)
+	if err := json.Unmarshal(dec.Header, header); err != nil {
+		return fmt.Errorf("%w: header: %v", ErrWrappedBlockMalformed, err)
+	}
+	transactions, typedTxFields, err := decodeTransactions(dec.Transactions)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWrappedBlockMalformed, err)
+	}
+	var withdrawRoot common.Hash
+	if !isJSONNull(dec.WithdrawRoot) {
+		if err := json.Unmarshal(dec.WithdrawRoot, &withdrawRoot); err != nil {
+			return fmt.Errorf("%w: withdraw_trie_root: %v", ErrWrappedBlockMalformed, err)
+		}
+	}
+	var rowConsumption *types.RowConsumption
+	if !isJSONNull(dec.RowConsumption) {
+		if err := json.Unmarshal(dec.RowConsumption, &rowConsumption); err != nil {
+			return fmt.Errorf("%w: row_consumption: %v", ErrWrappedBlockMalformed, err)
+		}
+	}
+
+	w.Header = header
+	w.Transactions = transactions
+	w.TypedTxFields = typedTxFields
+	w.WithdrawRoot = withdrawRoot
+	w.withdrawRootAbsent = isJSONNull(dec.WithdrawRoot)
+	w.RowConsumption = rowConsumption
+
+	w.txPayloadLengthMu.Lock()
+	w.txPayloadLengthCache = nil
+	w.txPayloadLengthMu.Unlock()
+	return nil
+}
+
+// MarshalTransactions encodes the transactions of the block along with their typed fields, as in MarshalJSON.
+func (w *WrappedBlock) MarshalTransactions() ([]byte, error) {
+	return json.Marshal(w.transactionsJSON())
+}
+
+// UnmarshalTransactions decodes the transactions of MarshalTransactions, or the ones of a trace, into the block.
+func (w *WrappedBlock) UnmarshalTransactions(input []byte) error {
+	var rawTxs []json.RawMessage
+	if err := json.Unmarshal(input, &rawTxs); err != nil {
+		return err
+	}
+	transactions, typedTxFields, err := decodeTransactions(rawTxs)
+	if err != nil {
+		return err
+	}
+	w.Transactions = transactions
+	w.TypedTxFields = typedTxFields
+
+	w.txPayloadLengthMu.Lock()
+	w.txPayloadLengthCache = nil
+	w.txPayloadLengthMu.Unlock()
+	return nil
+}
+
+func (w *WrappedBlock) transactionsJSON() []*transactionDataJSON {
+	transactions := make([]*transactionDataJSON, len(w.Transactions))
+	for i, txData := range w.Transactions {
+		transactions[i] = &transactionDataJSON{TransactionData: txData, TypedTxFields: w.typedTxFields(txData)}
+	}
+	return transactions
+}
+
+// typedTxFields returns the typed fields of the transaction, nil if unknown.
+func (w *WrappedBlock) typedTxFields(txData *types.TransactionData) *TypedTxFields {
+	return w.TypedTxFields[common.HexToHash(txData.TxHash)]
+}
+
+// decodeTransactions decodes the transactions of a block json and collects their typed fields by tx hash.
+func decodeTransactions(rawTxs []json.RawMessage) ([]*types.TransactionData, map[common.Hash]*TypedTxFields, error) {
+	transactions := make([]*types.TransactionData, len(rawTxs))
+	var typedTxFields map[common.Hash]*TypedTxFields
+	for i, rawTx := range rawTxs {
+		txData, fields, err := decodeTransactionData(rawTx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transactions[%d]: %v", i, err)
+		}
+		transactions[i] = txData
+		if fields != nil {
+			if typedTxFields == nil {
+				typedTxFields = make(map[common.Hash]*TypedTxFields)
+			}
+			typedTxFields[common.HexToHash(txData.TxHash)] = fields
+		}
+	}
+	return transactions, typedTxFields, nil
+}
+
+// decodeTransactionData decodes a transaction of the trace and its typed fields, nil if it has none,
+// and checks the fields its type is encoded with.
+func decodeTransactionData(input json.RawMessage) (*types.TransactionData, *TypedTxFields, error) {
+	if isJSONNull(input) {
+		return nil, nil, errors.New("transaction is null")
+	}
+	var txData types.TransactionData
+	if err := json.Unmarshal(input, &txData); err != nil {
+		return nil, nil, jsonFieldError(input, err, &txData)
+	}
+	var fields TypedTxFields
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return nil, nil, jsonFieldError(input, err, &fields)
+	}
+
+	switch txData.Type {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+		required := []struct {
+			name  string
+			value *hexutil.Big
+		}{
+			{"gasPrice", txData.GasPrice},
+			{"value", txData.Value},
+			{"v", txData.V},
+			{"r", txData.R},
+			{"s", txData.S},
+		}
+		for _, field := range required {
+			if field.value == nil {
+				return nil, nil, fmt.Errorf("%s is missing", field.name)
+			}
+		}
+		if txData.Type != types.LegacyTxType && txData.ChainId == nil {
+			return nil, nil, errors.New("chainId is missing")
+		}
+	case types.L1MessageTxType:
+	default:
+		return nil, nil, fmt.Errorf("type: unsupported tx type %d", txData.Type)
+	}
+
+	if hash, err := hexutil.Decode(txData.TxHash); err != nil || len(hash) != common.HashLength {
+		return nil, nil, fmt.Errorf("txHash: malformed hash %q", txData.TxHash)
+	}
+	decoder := GetHexDecoder()
+	defer decoder.Release()
+	if _, err := decoder.Decode(txData.Data); err != nil {
+		return nil, nil, fmt.Errorf("data: %w", err)
+	}
+	if err := checkTypedTxFields(txData.Type, &fields); err != nil {
+		return nil, nil, err
+	}
+	if fields.GasTipCap == nil && fields.GasFeeCap == nil && fields.AccessList == nil {
+		return &txData, nil, nil
+	}
+	return &txData, &fields, nil
+}
+
+// checkTypedTxFields checks the typed fields of a transaction are the ones of its type, the fee caps going together.
+func checkTypedTxFields(txType uint8, fields *TypedTxFields) error {
+	if txType != types.AccessListTxType && txType != types.DynamicFeeTxType && fields.AccessList != nil {
+		return fmt.Errorf("accessList: not a field of tx type %d", txType)
+	}
+	if txType != types.DynamicFeeTxType && (fields.GasTipCap != nil || fields.GasFeeCap != nil) {
+		return fmt.Errorf("gasTipCap, gasFeeCap: not fields of tx type %d", txType)
+	}
+	if (fields.GasTipCap == nil) != (fields.GasFeeCap == nil) {
+		return errors.New("gasTipCap, gasFeeCap: only one of the fee caps is set")
+	}
+	return nil
+}
+
+// jsonFieldError finds the field of the json that failed to decode into the struct pointed by v with err.
+func jsonFieldError(input json.RawMessage, err error, v interface{}) error {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(input, &fields) != nil {
+		return err
+	}
+	value := reflect.ValueOf(v).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if fieldErr := json.Unmarshal(raw, value.Field(i).Addr().Interface()); fieldErr != nil {
+			return fmt.Errorf("%s: %v", name, fieldErr)
+		}
+	}
+	return err
+}
+
+func isJSONNull(input json.RawMessage) bool {
+	return len(input) == 0 || string(input) == "null"
+}
+
+// HasWithdrawRoot returns whether the withdraw trie root of the block is known, it is false only for
+// the blocks decoded from a legacy json without withdraw trie root.
+func (w *WrappedBlock) HasWithdrawRoot() bool {
+	return !w.withdrawRootAbsent
+}
+
+// Validate checks the invariants the encoding and the prover rely on, so an invalid block is reported when it is
+// chunked instead of when its chunk fails to encode or to be proven. The parent header is optional, the L1 messages
+// must follow the totalL1MessagePoppedBefore popped ones. The row consumption is checked if requireRowConsumption,
+// the blocks chunked under a circuit capacity limit must have it. The errors wrap the Err* errors of the failed checks.
+func (w *WrappedBlock) Validate(parent *types.Header, totalL1MessagePoppedBefore uint64, requireRowConsumption bool) error {
+	if w.Header == nil {
+		return ErrBlockHeaderMissing
+	}
+	if w.Header.Number == nil || !w.Header.Number.IsUint64() {
+		return fmt.Errorf("%w: %v", ErrBlockNumberNotUint64, w.Header.Number)
+	}
+
+	if parent != nil {
+		if parent.Number == nil || new(big.Int).Add(parent.Number, big.NewInt(1)).Cmp(w.Header.Number) != 0 {
+			return fmt.Errorf("%w: block number %v, parent number %v", ErrBlockNotChildOfParent, w.Header.Number, parent.Number)
+		}
+		if w.Header.Time < parent.Time {
+			return fmt.Errorf("%w: block %v timestamp %v, parent timestamp %v", ErrBlockTimestampNotMonotonic, w.Header.Number, w.Header.Time, parent.Time)
+		}
+	}
+
+	nextQueueIndex := totalL1MessagePoppedBefore
+	var l2TxSeen bool
+	for i, txData := range w.Transactions {
+		if txData.Type != types.L1MessageTxType {
+			l2TxSeen = true
+			continue
+		}
+		if l2TxSeen {
+			return fmt.Errorf("%w: block %v transaction %v", ErrL1MessagesNotContiguous, w.Header.Number, i)
+		}
+		// the queue index of an L1 message is stored in the nonce
+		if txData.Nonce < nextQueueIndex || txData.Nonce == math.MaxUint64 {
+			return fmt.Errorf("%w: block %v transaction %v, expected queue index: %v, got: %v", ErrL1MessagesNotSorted, w.Header.Number, i, nextQueueIndex, txData.Nonce)
+		}
+		nextQueueIndex = txData.Nonce + 1
+	}
+
+	if requireRowConsumption && w.RowConsumption == nil {
+		return fmt.Errorf("%w: block %v, hash %v", ErrRowConsumptionMissing, w.Header.Number, w.Header.Hash().Hex())
+	}
+	return nil
+}
+
 // NumL1Messages returns the number of L1 messages in this block.
 // This number is the sum of included and skipped L1 messages.
 func (w *WrappedBlock) NumL1Messages(totalL1MessagePoppedBefore uint64) uint64 {
@@ -65,12 +431,20 @@ func (w *WrappedBlock) NumL2Transactions() uint64 {
 	return count
 }
 
+// IsBaseFeeEncoded returns whether the BlockContext of the block carries its base fee, baseFeeForkBlock is the
+// first block whose BlockContext carries the base fee of the block header. The base fee is never encoded if it is nil.
+func (w *WrappedBlock) IsBaseFeeEncoded(baseFeeForkBlock *uint64) bool {
+	return w.Header.BaseFee != nil && baseFeeForkBlock != nil && w.Header.Number.Uint64() >= *baseFeeForkBlock
+}
+
 // Encode encodes the WrappedBlock into RollupV2 BlockContext Encoding.
-func (w *WrappedBlock) Encode(totalL1MessagePoppedBefore uint64) ([]byte, error) {
+// A block without transactions is encoded as a BlockContext with zero transactions. The blocks before
+// baseFeeForkBlock keep encoding a zero base fee so the hashes of the committed batches don't change.
+func (w *WrappedBlock) Encode(totalL1MessagePoppedBefore uint64, baseFeeForkBlock *uint64) ([]byte, error) {
 	bytes := make([]byte, 60)
 
 	if !w.Header.Number.IsUint64() {
-		return nil, errors.New("block number is not uint64")
+		return nil, ErrBlockNumberNotUint64
 	}
 
 	// note: numL1Messages includes skipped messages
@@ -88,7 +462,14 @@ func (w *WrappedBlock) Encode(totalL1MessagePoppedBefore uint64) ([]byte, error)
 
 	binary.BigEndian.PutUint64(bytes[0:], w.Header.Number.Uint64())
 	binary.BigEndian.PutUint64(bytes[8:], w.Header.Time)
-	// TODO: [16:47] Currently, baseFee is 0, because we disable EIP-1559.
+	// [16:48] baseFee is 0 before the base fee fork, because EIP-1559 is disabled.
+	if w.IsBaseFeeEncoded(baseFeeForkBlock) {
+		baseFee := w.Header.BaseFee
+		if baseFee.Sign() < 0 || baseFee.BitLen() > 256 {
+			return nil, fmt.Errorf("base fee is not uint256: %v", baseFee)
+		}
+		baseFee.FillBytes(bytes[16:48])
+	}
 	binary.BigEndian.PutUint64(bytes[48:], w.Header.GasLimit)
 	binary.BigEndian.PutUint16(bytes[56:], uint16(numTransactions))
 	binary.BigEndian.PutUint16(bytes[58:], uint16(numL1Messages))
@@ -96,37 +477,84 @@ func (w *WrappedBlock) Encode(totalL1MessagePoppedBefore uint64) ([]byte, error)
 	return bytes, nil
 }
 
+// BlockContext is the RollupV2 BlockContext of a block, as committed in the chunk encoding.
+type BlockContext struct {
+	Number    uint64
+	Timestamp uint64
+	BaseFee   *big.Int
+	GasLimit  uint64
+	// NumTransactions includes the L1 messages, skipped ones included.
+	NumTransactions uint16
+	// NumL1Messages includes the skipped L1 messages.
+	NumL1Messages uint16
+}
+
+// DecodeBlockContext decodes the 60-byte RollupV2 BlockContext Encoding produced by WrappedBlock.Encode.
+func DecodeBlockContext(data []byte) (*BlockContext, error) {
+	if len(data) != 60 {
+		return nil, fmt.Errorf("block context is not 60 bytes long: %v", len(data))
+	}
+	return &BlockContext{
+		Number:          binary.BigEndian.Uint64(data[0:8]),
+		Timestamp:       binary.BigEndian.Uint64(data[8:16]),
+		BaseFee:         new(big.Int).SetBytes(data[16:48]),
+		GasLimit:        binary.BigEndian.Uint64(data[48:56]),
+		NumTransactions: binary.BigEndian.Uint16(data[56:58]),
+		NumL1Messages:   binary.BigEndian.Uint16(data[58:60]),
+	}, nil
+}
+
 // EstimateL1CommitCalldataSize calculates the calldata size in l1 commit approximately.
-// TODO: The calculation could be more accurate by using 58 + len(l2TxDataBytes) (see Chunk).
-// This needs to be adjusted in the future.
-func (w *WrappedBlock) EstimateL1CommitCalldataSize() uint64 {
+// It relies on the cached tx payload lengths and is meant for quick checks,
+// use L1CommitCalldataSize to get the exact size.
+func (w *WrappedBlock) EstimateL1CommitCalldataSize() (uint64, error) {
+	txPayloadLengths, err := w.l2TxPayloadLengths()
+	if err != nil {
+		return 0, err
+	}
 	var size uint64
-	for _, txData := range w.Transactions {
-		if txData.Type == types.L1MessageTxType {
-			continue
-		}
+	for _, txPayloadLength := range txPayloadLengths {
 		size += 4 // 4 bytes payload length
-		size += w.getTxPayloadLength(txData)
+		size += txPayloadLength
 	}
 	size += 60 //  60 bytes BlockContext
-	return size
+	return size, nil
 }
 
-// EstimateL1CommitGas calculates the total L1 commit gas for this block approximately.
-func (w *WrappedBlock) EstimateL1CommitGas() uint64 {
-	var total uint64
-	var numL1Messages uint64
+// L1CommitCalldataSize calculates the exact size of this block in the chunk encoding of l1 commit calldata,
+// that is the 60-byte BlockContext plus a 4-byte payload length and the RLP encoding of each L2 transaction.
+func (w *WrappedBlock) L1CommitCalldataSize() (uint64, error) {
+	size := uint64(60) // 60 bytes BlockContext
 	for _, txData := range w.Transactions {
 		if txData.Type == types.L1MessageTxType {
-			numL1Messages++
 			continue
 		}
+		rlpTxData, err := convertTxDataToRLPEncoding(txData, w.typedTxFields(txData))
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode tx %s: %w", txData.TxHash, err)
+		}
+		size += 4 // 4 bytes payload length
+		size += uint64(len(rlpTxData))
+	}
+	return size, nil
+}
 
-		txPayloadLength := w.getTxPayloadLength(txData)
+// EstimateL1CommitGas calculates the total L1 commit gas for this block approximately,
+// a block without transactions costs only the calldata of its BlockContext. Every byte of the BlockContext
+// is charged as non-zero, which covers the base fee from the base fee fork on, and the typed transactions
+// are sized by their EIP-2718 envelope.
+func (w *WrappedBlock) EstimateL1CommitGas() (uint64, error) {
+	txPayloadLengths, err := w.l2TxPayloadLengths()
+	if err != nil {
+		return 0, err
+	}
+	var total uint64
+	for _, txPayloadLength := range txPayloadLengths {
 		total += CalldataNonZeroByteGas * txPayloadLength // an over-estimate: treat each byte as non-zero
 		total += CalldataNonZeroByteGas * 4               // 4 bytes payload length
 		total += GetKeccak256Gas(txPayloadLength)         // l2 tx hash
 	}
+	numL1Messages := uint64(len(w.Transactions) - len(txPayloadLengths))
 
 	// 60 bytes BlockContext calldata
 	total += CalldataNonZeroByteGas * 60
@@ -144,46 +572,161 @@ func (w *WrappedBlock) EstimateL1CommitGas() uint64 {
 	total += 100 * numL1Messages                        // access impl
 	total += GetMemoryExpansionCost(36) * numL1Messages // delegatecall to impl
 
-	return total
+	return total, nil
 }
 
-func (w *WrappedBlock) getTxPayloadLength(txData *types.TransactionData) uint64 {
-	if w.txPayloadLengthCache == nil {
-		w.txPayloadLengthCache = make(map[string]uint64)
+// TxL1CommitCost is the estimated and actual l1 commit calldata cost of an l2 transaction.
+type TxL1CommitCost struct {
+	TxType uint8
+	// Target is nil for contract creation.
+	Target        *common.Address
+	EstimatedSize uint64
+	ActualSize    uint64
+	EstimatedGas  uint64
+	ActualGas     uint64
+}
+
+// L1CommitCosts compares, for each l2 transaction of this block, the estimates used by
+// EstimateL1CommitCalldataSize and EstimateL1CommitGas with the cost of its actual encoding.
+func (w *WrappedBlock) L1CommitCosts() ([]*TxL1CommitCost, error) {
+	var costs []*TxL1CommitCost
+	for _, txData := range w.Transactions {
+		if txData.Type == types.L1MessageTxType {
+			continue
+		}
+
+		rlpTxData, err := convertTxDataToRLPEncoding(txData, w.typedTxFields(txData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tx %s: %w", txData.TxHash, err)
+		}
+
+		estimatedLength, err := w.getTxPayloadLength(txData)
+		if err != nil {
+			return nil, err
+		}
+		actualLength := uint64(len(rlpTxData))
+		var lengthBytes [4]byte
+		binary.BigEndian.PutUint32(lengthBytes[:], uint32(actualLength))
+
+		costs = append(costs, &TxL1CommitCost{
+			TxType:        txData.Type,
+			Target:        txData.To,
+			EstimatedSize: 4 + estimatedLength,
+			ActualSize:    4 + actualLength,
+			EstimatedGas:  CalldataNonZeroByteGas*(4+estimatedLength) + GetKeccak256Gas(estimatedLength),
+			ActualGas:     getCalldataGas(lengthBytes[:]) + getCalldataGas(rlpTxData) + GetKeccak256Gas(actualLength),
+		})
+	}
+	return costs, nil
+}
+
+func getCalldataGas(data []byte) uint64 {
+	var gas uint64
+	for _, b := range data {
+		if b == 0 {
+			gas += CalldataZeroByteGas
+		} else {
+			gas += CalldataNonZeroByteGas
+		}
+	}
+	return gas
+}
+
+// txEstimationWorkers is the number of goroutines sizing the l2 transactions of a block for the l1 commit
+// estimations, it is set once by SetTxEstimationWorkers before the services start.
+var txEstimationWorkers int
+
+// minParallelTxNum is the number of l2 transactions from which a block is sized in parallel, the goroutines
+// of the smaller blocks cost more than they save.
+const minParallelTxNum = 64
+
+// SetTxEstimationWorkers sets the number of goroutines sizing the l2 transactions of a large block for the
+// l1 commit estimations, the transactions are sized serially if it is at most 1.
+func SetTxEstimationWorkers(workers int) {
+	txEstimationWorkers = workers
+}
+
+// l2TxPayloadLengths returns the payload lengths of the l2 transactions of the block, in transaction order.
+func (w *WrappedBlock) l2TxPayloadLengths() ([]uint64, error) {
+	return w.l2TxPayloadLengthsWith(txEstimationWorkers)
+}
+
+// l2TxPayloadLengthsWith sizes the l2 transactions with up to the given number of goroutines, the error is
+// the one of the first failing transaction, so the result does not depend on the scheduling.
+func (w *WrappedBlock) l2TxPayloadLengthsWith(workers int) ([]uint64, error) {
+	l2Txs := make([]*types.TransactionData, 0, len(w.Transactions))
+	for _, txData := range w.Transactions {
+		if txData.Type != types.L1MessageTxType {
+			l2Txs = append(l2Txs, txData)
+		}
+	}
+	lengths := make([]uint64, len(l2Txs))
+
+	if workers <= 1 || len(l2Txs) < minParallelTxNum {
+		for i, txData := range l2Txs {
+			length, err := w.getTxPayloadLength(txData)
+			if err != nil {
+				return nil, err
+			}
+			lengths[i] = length
+		}
+		return lengths, nil
+	}
+
+	errs := make([]error, len(l2Txs))
+	if workers > len(l2Txs) {
+		workers = len(l2Txs)
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := int(next.Add(1) - 1); j < len(l2Txs); j = int(next.Add(1) - 1) {
+				lengths[j], errs[j] = w.getTxPayloadLength(l2Txs[j])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
 	}
+	return lengths, nil
+}
 
-	if length, exists := w.txPayloadLengthCache[txData.TxHash]; exists {
-		return length
+func (w *WrappedBlock) getTxPayloadLength(txData *types.TransactionData) (uint64, error) {
+	w.txPayloadLengthMu.Lock()
+	length, exists := w.txPayloadLengthCache[txData.TxHash]
+	w.txPayloadLengthMu.Unlock()
+	if exists {
+		return length, nil
 	}
 
-	rlpTxData, err := convertTxDataToRLPEncoding(txData)
+	rlpTxData, err := convertTxDataToRLPEncoding(txData, w.typedTxFields(txData))
 	if err != nil {
-		log.Crit("convertTxDataToRLPEncoding failed, which should not happen", "hash", txData.TxHash, "err", err)
-		return 0
+		return 0, fmt.Errorf("failed to encode tx %s: %w", txData.TxHash, err)
 	}
 	txPayloadLength := uint64(len(rlpTxData))
+
+	w.txPayloadLengthMu.Lock()
+	defer w.txPayloadLengthMu.Unlock()
+	if w.txPayloadLengthCache == nil {
+		w.txPayloadLengthCache = make(map[string]uint64)
+	}
 	w.txPayloadLengthCache[txData.TxHash] = txPayloadLength
-	return txPayloadLength
+	return txPayloadLength, nil
 }
 
-func convertTxDataToRLPEncoding(txData *types.TransactionData) ([]byte, error) {
-	data, err := hexutil.Decode(txData.Data)
+// convertTxDataToRLPEncoding rebuilds the l2 transaction of the trace by its type and returns its EIP-2718 encoding.
+func convertTxDataToRLPEncoding(txData *types.TransactionData, fields *TypedTxFields) ([]byte, error) {
+	tx, err := newTxFromTxData(txData, fields)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode txData.Data: %s, err: %w", txData.Data, err)
+		return nil, err
 	}
-
-	tx := types.NewTx(&types.LegacyTx{
-		Nonce:    txData.Nonce,
-		To:       txData.To,
-		Value:    txData.Value.ToInt(),
-		Gas:      txData.Gas,
-		GasPrice: txData.GasPrice.ToInt(),
-		Data:     data,
-		V:        txData.V.ToInt(),
-		R:        txData.R.ToInt(),
-		S:        txData.S.ToInt(),
-	})
-
 	rlpTxData, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal binary of the tx: %+v, err: %w", tx, err)
@@ -191,3 +734,76 @@ func convertTxDataToRLPEncoding(txData *types.TransactionData) ([]byte, error) {
 
 	return rlpTxData, nil
 }
+
+// newTxFromTxData rebuilds the l2 transaction of the trace by its type with its typed fields. Without typed fields,
+// a typed transaction is rebuilt with an empty access list and the gas price as fee caps, which only holds for some
+// transactions, so the rebuilt transaction must hash to the signed one or ErrTxNotReproducible is returned.
+func newTxFromTxData(txData *types.TransactionData, fields *TypedTxFields) (*types.Transaction, error) {
+	// the decoded data is copied by NewTx, so the buffer goes back to the pool right after
+	decoder := GetHexDecoder()
+	defer decoder.Release()
+	data, err := decoder.Decode(txData.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode txData.Data: %s, err: %w", txData.Data, err)
+	}
+	if fields == nil {
+		fields = &TypedTxFields{}
+	}
+
+	var txInner types.TxData
+	switch txData.Type {
+	case types.LegacyTxType:
+		txInner = &types.LegacyTx{
+			Nonce:    txData.Nonce,
+			To:       txData.To,
+			Value:    txData.Value.ToInt(),
+			Gas:      txData.Gas,
+			GasPrice: txData.GasPrice.ToInt(),
+			Data:     data,
+			V:        txData.V.ToInt(),
+			R:        txData.R.ToInt(),
+			S:        txData.S.ToInt(),
+		}
+	case types.AccessListTxType:
+		txInner = &types.AccessListTx{
+			ChainID:    txData.ChainId.ToInt(),
+			Nonce:      txData.Nonce,
+			To:         txData.To,
+			Value:      txData.Value.ToInt(),
+			Gas:        txData.Gas,
+			GasPrice:   txData.GasPrice.ToInt(),
+			Data:       data,
+			AccessList: fields.AccessList,
+			V:          txData.V.ToInt(),
+			R:          txData.R.ToInt(),
+			S:          txData.S.ToInt(),
+		}
+	case types.DynamicFeeTxType:
+		gasTipCap, gasFeeCap := txData.GasPrice, txData.GasPrice
+		if fields.GasTipCap != nil && fields.GasFeeCap != nil {
+			gasTipCap, gasFeeCap = fields.GasTipCap, fields.GasFeeCap
+		}
+		txInner = &types.DynamicFeeTx{
+			ChainID:    txData.ChainId.ToInt(),
+			Nonce:      txData.Nonce,
+			To:         txData.To,
+			Value:      txData.Value.ToInt(),
+			Gas:        txData.Gas,
+			GasTipCap:  gasTipCap.ToInt(),
+			GasFeeCap:  gasFeeCap.ToInt(),
+			Data:       data,
+			AccessList: fields.AccessList,
+			V:          txData.V.ToInt(),
+			R:          txData.R.ToInt(),
+			S:          txData.S.ToInt(),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tx type: %d", txData.Type)
+	}
+
+	tx := types.NewTx(txInner)
+	if tx.Hash() != common.HexToHash(txData.TxHash) {
+		return nil, fmt.Errorf("%w: tx %s rebuilt as %s", ErrTxNotReproducible, txData.TxHash, tx.Hash().Hex())
+	}
+	return tx, nil
+}
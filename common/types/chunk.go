@@ -1,20 +1,19 @@
 package types
 
 import (
-	"encoding/binary"
-	"encoding/hex"
-	"errors"
-	"fmt"
-	"strings"
+	"bytes"
+	"io"
 
 	"github.com/scroll-tech/go-ethereum/common"
-	"github.com/scroll-tech/go-ethereum/core/types"
-	"github.com/scroll-tech/go-ethereum/crypto"
 )
 
-// Chunk contains blocks to be encoded
+// Chunk contains blocks to be encoded, it is the one implementation of the chunk encoding
+// shared by the proposers, the relayer and the provers.
 type Chunk struct {
 	Blocks []*WrappedBlock `json:"blocks"`
+	// BaseFeeForkBlock is the first block whose BlockContext carries the base fee, it comes from the chain config
+	// of the service building the chunk. The base fee is encoded as zero if nil.
+	BaseFeeForkBlock *uint64 `json:"-"`
 }
 
 // NumL1Messages returns the number of L1 messages in this chunk.
@@ -30,102 +29,53 @@ func (c *Chunk) NumL1Messages(totalL1MessagePoppedBefore uint64) uint64 {
 	return numL1Messages
 }
 
-// Encode encodes the Chunk into RollupV2 Chunk Encoding.
+// Encode encodes the Chunk into RollupV2 Chunk Encoding:
+//
+//	numBlocks (1 byte) || blockContext (60 bytes) * numBlocks || (txLen (4 bytes) || rlpTx) * numL2Txs
+//
+// where the L2 transactions of all the blocks follow the block contexts in block order.
 func (c *Chunk) Encode(totalL1MessagePoppedBefore uint64) ([]byte, error) {
-	numBlocks := len(c.Blocks)
-
-	if numBlocks > 255 {
-		return nil, errors.New("number of blocks exceeds 1 byte")
-	}
-	if numBlocks == 0 {
-		return nil, errors.New("number of blocks is 0")
-	}
-
-	var chunkBytes []byte
-	chunkBytes = append(chunkBytes, byte(numBlocks))
-
-	var l2TxDataBytes []byte
-
-	for _, block := range c.Blocks {
-		blockBytes, err := block.Encode(totalL1MessagePoppedBefore)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encode block: %v", err)
-		}
-		totalL1MessagePoppedBefore += block.NumL1Messages(totalL1MessagePoppedBefore)
-
-		if len(blockBytes) != 60 {
-			return nil, fmt.Errorf("block encoding is not 60 bytes long %x", len(blockBytes))
-		}
-
-		chunkBytes = append(chunkBytes, blockBytes...)
-
-		// Append rlp-encoded l2Txs
-		for _, txData := range block.Transactions {
-			if txData.Type == types.L1MessageTxType {
-				continue
-			}
-			rlpTxData, err := convertTxDataToRLPEncoding(txData)
-			if err != nil {
-				return nil, err
-			}
-			var txLen [4]byte
-			binary.BigEndian.PutUint32(txLen[:], uint32(len(rlpTxData)))
-			l2TxDataBytes = append(l2TxDataBytes, txLen[:]...)
-			l2TxDataBytes = append(l2TxDataBytes, rlpTxData...)
-		}
+	var chunkBytes bytes.Buffer
+	if _, err := c.EncodeTo(&chunkBytes, totalL1MessagePoppedBefore); err != nil {
+		return nil, err
 	}
-
-	chunkBytes = append(chunkBytes, l2TxDataBytes...)
-
-	return chunkBytes, nil
+	return chunkBytes.Bytes(), nil
 }
 
-// Hash hashes the Chunk into RollupV2 Chunk Hash
+// Hash hashes the Chunk into RollupV2 Chunk Hash, the hashed data is streamed into the hasher.
 func (c *Chunk) Hash(totalL1MessagePoppedBefore uint64) (common.Hash, error) {
-	chunkBytes, err := c.Encode(totalL1MessagePoppedBefore)
-	if err != nil {
+	// the chunk is encoded first, so only a chunk that can be committed is hashed
+	if _, err := c.EncodeTo(io.Discard, totalL1MessagePoppedBefore); err != nil {
 		return common.Hash{}, err
 	}
-	numBlocks := chunkBytes[0]
-
-	// concatenate block contexts
-	var dataBytes []byte
-	for i := 0; i < int(numBlocks); i++ {
-		// only the first 58 bytes of each BlockContext are needed for the hashing process
-		dataBytes = append(dataBytes, chunkBytes[1+60*i:60*i+59]...)
+	hasher := NewKeccakWriter()
+	if err := c.writeHashData(hasher, totalL1MessagePoppedBefore); err != nil {
+		return common.Hash{}, err
 	}
+	return hasher.Hash(), nil
+}
 
-	// concatenate l1 and l2 tx hashes
-	for _, block := range c.Blocks {
-		var l1TxHashes []byte
-		var l2TxHashes []byte
-		for _, txData := range block.Transactions {
-			txHash := strings.TrimPrefix(txData.TxHash, "0x")
-			hashBytes, err := hex.DecodeString(txHash)
-			if err != nil {
-				return common.Hash{}, err
-			}
-			if txData.Type == types.L1MessageTxType {
-				l1TxHashes = append(l1TxHashes, hashBytes...)
-			} else {
-				l2TxHashes = append(l2TxHashes, hashBytes...)
-			}
-		}
-		dataBytes = append(dataBytes, l1TxHashes...)
-		dataBytes = append(dataBytes, l2TxHashes...)
+// L1CommitCalldataGas calculates the exact calldata gas of the chunk encoding in l1 commit calldata,
+// the zero bytes are charged less, so the base fee bytes cost more from the base fee fork on.
+func (c *Chunk) L1CommitCalldataGas(totalL1MessagePoppedBefore uint64) (uint64, error) {
+	chunkBytes, err := c.Encode(totalL1MessagePoppedBefore)
+	if err != nil {
+		return 0, err
 	}
-
-	hash := crypto.Keccak256Hash(dataBytes)
-	return hash, nil
+	return getCalldataGas(chunkBytes), nil
 }
 
 // EstimateL1CommitGas calculates the total L1 commit gas for this chunk approximately
-func (c *Chunk) EstimateL1CommitGas() uint64 {
+func (c *Chunk) EstimateL1CommitGas() (uint64, error) {
 	var totalTxNum uint64
 	var totalL1CommitGas uint64
 	for _, block := range c.Blocks {
 		totalTxNum += uint64(len(block.Transactions))
-		totalL1CommitGas += block.EstimateL1CommitGas()
+		blockL1CommitGas, err := block.EstimateL1CommitGas()
+		if err != nil {
+			return 0, err
+		}
+		totalL1CommitGas += blockL1CommitGas
 	}
 
 	numBlocks := uint64(len(c.Blocks))
@@ -134,5 +84,5 @@ func (c *Chunk) EstimateL1CommitGas() uint64 {
 	totalL1CommitGas += CalldataNonZeroByteGas * numBlocks * 60 // numBlocks of BlockContext in chunk
 
 	totalL1CommitGas += GetKeccak256Gas(58*numBlocks + 32*totalTxNum) // chunk hash
-	return totalL1CommitGas
+	return totalL1CommitGas, nil
 }
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+// ChunkFixture is a regression fixture of the chunk encoding, the blocks of a chunk in the canonical
+// json schema along with their expected encodings, hash and l1 commit costs.
+type ChunkFixture struct {
+	TotalL1MessagePoppedBefore uint64          `json:"total_l1_message_popped_before"`
+	BaseFeeForkBlock           *uint64         `json:"base_fee_fork_block,omitempty"`
+	Blocks                     []*WrappedBlock `json:"blocks"`
+	BlockContexts              []hexutil.Bytes `json:"block_contexts"`
+	Encoding                   hexutil.Bytes   `json:"encoding"`
+	Hash                       common.Hash     `json:"hash"`
+	L1CommitCalldataSize       uint64          `json:"l1_commit_calldata_size"`
+	EstimatedL1CommitGas       uint64          `json:"estimated_l1_commit_gas"`
+}
+
+// NewChunkFixture computes the expected encodings of the chunk of the given blocks.
+func NewChunkFixture(blocks []*WrappedBlock, totalL1MessagePoppedBefore uint64, baseFeeForkBlock *uint64) (*ChunkFixture, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("chunk fixture without blocks")
+	}
+
+	fixture := &ChunkFixture{
+		TotalL1MessagePoppedBefore: totalL1MessagePoppedBefore,
+		BaseFeeForkBlock:           baseFeeForkBlock,
+		Blocks:                     blocks,
+	}
+	poppedBefore := totalL1MessagePoppedBefore
+	for _, block := range blocks {
+		blockContext, err := block.Encode(poppedBefore, baseFeeForkBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode block %v: %w", block.Header.Number, err)
+		}
+		fixture.BlockContexts = append(fixture.BlockContexts, blockContext)
+		poppedBefore += block.NumL1Messages(poppedBefore)
+	}
+
+	chunk := &Chunk{Blocks: blocks, BaseFeeForkBlock: baseFeeForkBlock}
+	var err error
+	if fixture.Encoding, err = chunk.Encode(totalL1MessagePoppedBefore); err != nil {
+		return nil, fmt.Errorf("failed to encode chunk: %w", err)
+	}
+	if fixture.Hash, err = chunk.Hash(totalL1MessagePoppedBefore); err != nil {
+		return nil, fmt.Errorf("failed to hash chunk: %w", err)
+	}
+	fixture.L1CommitCalldataSize = uint64(len(fixture.Encoding))
+	if fixture.EstimatedL1CommitGas, err = chunk.EstimateL1CommitGas(); err != nil {
+		return nil, fmt.Errorf("failed to estimate chunk l1 commit gas: %w", err)
+	}
+	return fixture, nil
+}
+
+// Verify encodes the blocks of the fixture again and compares the results with the expected ones.
+func (f *ChunkFixture) Verify() error {
+	actual, err := NewChunkFixture(f.Blocks, f.TotalL1MessagePoppedBefore, f.BaseFeeForkBlock)
+	if err != nil {
+		return err
+	}
+	if len(actual.BlockContexts) != len(f.BlockContexts) {
+		return fmt.Errorf("block context number mismatch, expected %v, actual %v", len(f.BlockContexts), len(actual.BlockContexts))
+	}
+	for i := range f.BlockContexts {
+		if !bytes.Equal(actual.BlockContexts[i], f.BlockContexts[i]) {
+			return fmt.Errorf("block context %v mismatch, expected %v, actual %v", i, f.BlockContexts[i], actual.BlockContexts[i])
+		}
+	}
+	switch {
+	case !bytes.Equal(actual.Encoding, f.Encoding):
+		return errors.New("chunk encoding mismatch")
+	case actual.Hash != f.Hash:
+		return fmt.Errorf("chunk hash mismatch, expected %v, actual %v", f.Hash.Hex(), actual.Hash.Hex())
+	case actual.L1CommitCalldataSize != f.L1CommitCalldataSize:
+		return fmt.Errorf("chunk l1 commit calldata size mismatch, expected %v, actual %v", f.L1CommitCalldataSize, actual.L1CommitCalldataSize)
+	case actual.EstimatedL1CommitGas != f.EstimatedL1CommitGas:
+		return fmt.Errorf("chunk estimated l1 commit gas mismatch, expected %v, actual %v", f.EstimatedL1CommitGas, actual.EstimatedL1CommitGas)
+	}
+	return nil
+}
@@ -1,11 +1,20 @@
 package types
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"io"
+	"math/big"
 	"os"
+	"sync"
 	"testing"
 
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,7 +47,9 @@ func TestChunkEncode(t *testing.T) {
 	wrappedBlock := &WrappedBlock{}
 	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
 	assert.Equal(t, uint64(0), wrappedBlock.NumL1Messages(0))
-	assert.Equal(t, uint64(298), wrappedBlock.EstimateL1CommitCalldataSize())
+	estimatedSize, err := wrappedBlock.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(298), estimatedSize)
 	assert.Equal(t, uint64(2), wrappedBlock.NumL2Transactions())
 	chunk = &Chunk{
 		Blocks: []*WrappedBlock{
@@ -46,7 +57,9 @@ func TestChunkEncode(t *testing.T) {
 		},
 	}
 	assert.Equal(t, uint64(0), chunk.NumL1Messages(0))
-	assert.Equal(t, uint64(6042), chunk.EstimateL1CommitGas())
+	estimatedGas, err := chunk.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6042), estimatedGas)
 	bytes, err = chunk.Encode(0)
 	hexString := hex.EncodeToString(bytes)
 	assert.NoError(t, err)
@@ -60,7 +73,9 @@ func TestChunkEncode(t *testing.T) {
 	wrappedBlock2 := &WrappedBlock{}
 	assert.NoError(t, json.Unmarshal(templateBlockTrace2, wrappedBlock2))
 	assert.Equal(t, uint64(11), wrappedBlock2.NumL1Messages(0)) // 0..=9 skipped, 10 included
-	assert.Equal(t, uint64(96), wrappedBlock2.EstimateL1CommitCalldataSize())
+	estimatedSize, err = wrappedBlock2.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(96), estimatedSize)
 	assert.Equal(t, uint64(1), wrappedBlock2.NumL2Transactions())
 	chunk = &Chunk{
 		Blocks: []*WrappedBlock{
@@ -68,7 +83,9 @@ func TestChunkEncode(t *testing.T) {
 		},
 	}
 	assert.Equal(t, uint64(11), chunk.NumL1Messages(0))
-	assert.Equal(t, uint64(5329), chunk.EstimateL1CommitGas())
+	estimatedGas, err = chunk.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5329), estimatedGas)
 	bytes, err = chunk.Encode(0)
 	hexString = hex.EncodeToString(bytes)
 	assert.NoError(t, err)
@@ -84,7 +101,9 @@ func TestChunkEncode(t *testing.T) {
 		},
 	}
 	assert.Equal(t, uint64(11), chunk.NumL1Messages(0))
-	assert.Equal(t, uint64(10612), chunk.EstimateL1CommitGas())
+	estimatedGas, err = chunk.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10612), estimatedGas)
 	bytes, err = chunk.Encode(0)
 	hexString = hex.EncodeToString(bytes)
 	assert.NoError(t, err)
@@ -129,7 +148,7 @@ func TestChunkHash(t *testing.T) {
 	}
 	hash, err = chunk.Hash(0)
 	assert.NoError(t, err)
-	assert.Equal(t, "0xaa9e494f72bc6965857856f0fae6916f27b2a6591c714a573b2fab46df03b8ae", hash.Hex())
+	assert.Equal(t, "0x3bb4212bed64f02e280ed5f405946e3ff81ad00a3ae90a36239674d13f3cf4dc", hash.Hex())
 
 	// Test case 4: successfully hashing a chunk on two blocks each with L1 and L2 txs
 	templateBlockTrace2, err := os.ReadFile("../testdata/blockTrace_04.json")
@@ -147,6 +166,78 @@ func TestChunkHash(t *testing.T) {
 	assert.Equal(t, "0x2eb7dd63bf8fc29a0f8c10d16c2ae6f9da446907c79d50f5c164d30dc8526b60", hash.Hex())
 }
 
+func TestChunkL1CommitCalldataSize(t *testing.T) {
+	for _, file := range []string{"blockTrace_02.json", "blockTrace_03.json", "blockTrace_04.json"} {
+		templateBlockTrace, err := os.ReadFile("../testdata/" + file)
+		assert.NoError(t, err)
+
+		wrappedBlock := &WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		blockSize, err := wrappedBlock.L1CommitCalldataSize()
+		assert.NoError(t, err)
+		estimatedSize, err := wrappedBlock.EstimateL1CommitCalldataSize()
+		assert.NoError(t, err)
+		assert.Equal(t, estimatedSize, blockSize)
+
+		// the chunk encoding is the numBlocks byte followed by the blocks
+		chunk := &Chunk{Blocks: []*WrappedBlock{wrappedBlock, wrappedBlock}}
+		chunkSize, err := chunk.EncodeTo(io.Discard, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1+2*blockSize), chunkSize)
+	}
+
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	wrappedBlock.Transactions[0].Data = "not-a-hex"
+	chunk := &Chunk{Blocks: []*WrappedBlock{wrappedBlock}}
+	_, err = chunk.EncodeTo(io.Discard, 0)
+	assert.Error(t, err)
+	_, err = wrappedBlock.L1CommitCalldataSize()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), wrappedBlock.Transactions[0].TxHash)
+
+	// the estimations surface the decoding errors as well
+	_, err = wrappedBlock.EstimateL1CommitCalldataSize()
+	assert.ErrorIs(t, err, hexutil.ErrMissingPrefix)
+	_, err = wrappedBlock.EstimateL1CommitGas()
+	assert.ErrorIs(t, err, hexutil.ErrMissingPrefix)
+	assert.Contains(t, err.Error(), wrappedBlock.Transactions[0].TxHash)
+	_, err = chunk.EstimateL1CommitGas()
+	assert.ErrorIs(t, err, hexutil.ErrMissingPrefix)
+}
+
+func TestBlockL1CommitCosts(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	costs, err := wrappedBlock.L1CommitCosts()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, costs)
+
+	var estimatedSize, estimatedGas uint64
+	for _, cost := range costs {
+		assert.Equal(t, cost.EstimatedSize, cost.ActualSize)
+		// the gas estimate treats every byte as non-zero
+		assert.GreaterOrEqual(t, cost.EstimatedGas, cost.ActualGas)
+		estimatedSize += cost.EstimatedSize
+		estimatedGas += cost.EstimatedGas
+	}
+	blockEstimatedSize, err := wrappedBlock.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, blockEstimatedSize, estimatedSize+60)
+	blockEstimatedGas, err := wrappedBlock.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, blockEstimatedGas, estimatedGas+CalldataNonZeroByteGas*60)
+
+	wrappedBlock.Transactions[0].Data = "not-a-hex"
+	_, err = wrappedBlock.L1CommitCosts()
+	assert.Error(t, err)
+}
+
 func TestErrorPaths(t *testing.T) {
 	// test 1: Header.Number is not a uint64
 	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
@@ -156,7 +247,7 @@ func TestErrorPaths(t *testing.T) {
 
 	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
 	wrappedBlock.Header.Number = wrappedBlock.Header.Number.Lsh(wrappedBlock.Header.Number, 64)
-	bytes, err := wrappedBlock.Encode(0)
+	bytes, err := wrappedBlock.Encode(0, nil)
 	assert.Nil(t, bytes)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "block number is not uint64")
@@ -166,7 +257,7 @@ func TestErrorPaths(t *testing.T) {
 		wrappedBlock.Transactions = append(wrappedBlock.Transactions, wrappedBlock.Transactions[0])
 	}
 
-	bytes, err = wrappedBlock.Encode(0)
+	bytes, err = wrappedBlock.Encode(0, nil)
 	assert.Nil(t, bytes)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "number of transactions exceeds max uint16")
@@ -192,8 +283,7 @@ func TestErrorPaths(t *testing.T) {
 	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
 	wrappedBlock.Transactions[0].TxHash = "not-a-hex"
 	_, err = chunk.Hash(0)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "invalid byte")
+	assert.ErrorIs(t, err, ErrTxNotReproducible)
 
 	templateBlockTrace2, err := os.ReadFile("../testdata/blockTrace_04.json")
 	assert.NoError(t, err)
@@ -207,7 +297,7 @@ func TestErrorPaths(t *testing.T) {
 		wrappedBlock2.Transactions = append(wrappedBlock2.Transactions, txCopy)
 	}
 
-	bytes, err = wrappedBlock2.Encode(0)
+	bytes, err = wrappedBlock2.Encode(0, nil)
 	assert.Nil(t, bytes)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "number of L1 messages exceeds max uint16")
@@ -224,3 +314,648 @@ func TestErrorPaths(t *testing.T) {
 	assert.Contains(t, err.Error(), "number of L1 messages exceeds max uint16")
 
 }
+
+// TestWrappedBlockConcurrentEstimation is meant to be run with the race detector.
+func TestWrappedBlockConcurrentEstimation(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_03.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	chunk := &Chunk{Blocks: []*WrappedBlock{wrappedBlock}}
+
+	expectedCalldataSize, err := wrappedBlock.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	expectedGas, err := chunk.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	wrappedBlock.txPayloadLengthCache = nil
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			calldataSize, err := wrappedBlock.EstimateL1CommitCalldataSize()
+			assert.NoError(t, err)
+			assert.Equal(t, expectedCalldataSize, calldataSize)
+			gas, err := chunk.EstimateL1CommitGas()
+			assert.NoError(t, err)
+			assert.Equal(t, expectedGas, gas)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWrappedBlockParallelEstimation(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_03.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	// a large block of l2 txs of distinct payload lengths and an l1 message
+	chainID := wrappedBlock.Transactions[0].ChainId.ToInt()
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	signer := gethTypes.LatestSignerForChainID(chainID)
+	wrappedBlock.Transactions = []*gethTypes.TransactionData{{Type: gethTypes.L1MessageTxType, TxHash: common.Hash{}.Hex()}}
+	wrappedBlock.TypedTxFields = make(map[common.Hash]*TypedTxFields)
+	for i := 0; i < 4*minParallelTxNum; i++ {
+		tx, err := gethTypes.SignNewTx(key, signer, &gethTypes.DynamicFeeTx{ChainID: chainID, Nonce: uint64(i), Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Data: bytes.Repeat([]byte{0xab}, i)})
+		assert.NoError(t, err)
+		wrappedBlock.Transactions = append(wrappedBlock.Transactions, gethTypes.NewTransactionData(tx, 1, &params.ChainConfig{ChainID: chainID}))
+		wrappedBlock.TypedTxFields[tx.Hash()] = NewTypedTxFields(tx)
+	}
+
+	expectedLengths, err := wrappedBlock.l2TxPayloadLengthsWith(1)
+	assert.NoError(t, err)
+	assert.Len(t, expectedLengths, 4*minParallelTxNum)
+	expectedCalldataSize, err := wrappedBlock.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	expectedGas, err := wrappedBlock.EstimateL1CommitGas()
+	assert.NoError(t, err)
+
+	SetTxEstimationWorkers(8)
+	defer SetTxEstimationWorkers(0)
+	for _, workers := range []int{2, 8, 1024} {
+		wrappedBlock.txPayloadLengthCache = nil
+		lengths, err := wrappedBlock.l2TxPayloadLengthsWith(workers)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedLengths, lengths)
+	}
+	wrappedBlock.txPayloadLengthCache = nil
+	calldataSize, err := wrappedBlock.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCalldataSize, calldataSize)
+	wrappedBlock.txPayloadLengthCache = nil
+	gas, err := wrappedBlock.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedGas, gas)
+
+	// the error is the one of the first failing tx whatever the number of workers
+	wrappedBlock.txPayloadLengthCache = nil
+	wrappedBlock.Transactions[10].Data = "0xzz"
+	wrappedBlock.Transactions[20].Type = 3
+	for _, workers := range []int{1, 8} {
+		_, err := wrappedBlock.l2TxPayloadLengthsWith(workers)
+		assert.ErrorContains(t, err, "failed to decode txData.Data")
+	}
+}
+
+func TestWrappedBlockJSON(t *testing.T) {
+	// legacy json without withdraw trie root
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	legacyBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, legacyBlock))
+	assert.False(t, legacyBlock.HasWithdrawRoot())
+
+	// legacy json with withdraw trie root
+	templateBlockTrace, err = os.ReadFile("../testdata/blockTrace_04.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	assert.True(t, wrappedBlock.HasWithdrawRoot())
+
+	// the canonical json round trips with the fields in order
+	encoded, err := json.Marshal(wrappedBlock)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\{"schema_version":1,"header":\{.*\},"transactions":\[.*\],"withdraw_trie_root":"0x[0-9a-f]{64}","row_consumption":\[.*\]\}$`, string(encoded))
+	decoded := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(encoded, decoded))
+	assert.Equal(t, wrappedBlock.Header.Hash(), decoded.Header.Hash())
+	assert.Equal(t, wrappedBlock.WithdrawRoot, decoded.WithdrawRoot)
+	assert.Equal(t, wrappedBlock.RowConsumption, decoded.RowConsumption)
+	assert.Equal(t, len(wrappedBlock.Transactions), len(decoded.Transactions))
+	reencoded, err := json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, encoded, reencoded)
+
+	// the zero withdraw trie root is encoded, the unknown row consumption is not
+	legacyBlock.RowConsumption = nil
+	encoded, err = json.Marshal(legacyBlock)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"withdraw_trie_root":"0x0000000000000000000000000000000000000000000000000000000000000000"`)
+	assert.NotContains(t, string(encoded), "row_consumption")
+	assert.NoError(t, json.Unmarshal(encoded, decoded))
+	assert.True(t, decoded.HasWithdrawRoot())
+
+	// the canonical json must contain the hash-critical fields
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(encoded, &fields))
+	delete(fields, "withdraw_trie_root")
+	encoded, err = json.Marshal(fields)
+	assert.NoError(t, err)
+	assert.ErrorContains(t, json.Unmarshal(encoded, decoded), "withdraw trie root is missing")
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"schema_version":2}`), decoded), "unsupported wrapped block schema version")
+	_, err = json.Marshal(&WrappedBlock{})
+	assert.Error(t, err)
+}
+
+func TestWrappedBlockJSONMalformed(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_04.json")
+	assert.NoError(t, err)
+
+	// malformed applies the change to the json of the block trace and decodes it
+	malformed := func(change func(fields map[string]interface{}, txs []interface{})) error {
+		var fields map[string]interface{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, &fields))
+		txs, _ := fields["transactions"].([]interface{})
+		change(fields, txs)
+		encoded, err := json.Marshal(fields)
+		assert.NoError(t, err)
+		return json.Unmarshal(encoded, &WrappedBlock{})
+	}
+	tx := func(txs []interface{}, i int) map[string]interface{} {
+		return txs[i].(map[string]interface{})
+	}
+
+	assert.NoError(t, malformed(func(map[string]interface{}, []interface{}) {}))
+
+	cases := []struct {
+		name     string
+		change   func(fields map[string]interface{}, txs []interface{})
+		contains string
+	}{
+		{"missing header", func(f map[string]interface{}, _ []interface{}) { delete(f, "header") }, "header is missing"},
+		{"null transactions", func(f map[string]interface{}, _ []interface{}) { f["transactions"] = nil }, "transactions are missing"},
+		{"null transaction", func(_ map[string]interface{}, txs []interface{}) { txs[1] = nil }, "transactions[1]: transaction is null"},
+		{"malformed header", func(f map[string]interface{}, _ []interface{}) {
+			f["header"].(map[string]interface{})["gasLimit"] = "0xzz"
+		}, "header: "},
+		{"malformed gas price", func(_ map[string]interface{}, txs []interface{}) { tx(txs, 1)["gasPrice"] = "0xzz" }, "transactions[1]: gasPrice: "},
+		{"malformed value", func(_ map[string]interface{}, txs []interface{}) { tx(txs, 0)["value"] = "12" }, "transactions[0]: value: "},
+		{"missing signature", func(_ map[string]interface{}, txs []interface{}) { delete(tx(txs, 1), "r") }, "transactions[1]: r is missing"},
+		{"unknown tx type", func(_ map[string]interface{}, txs []interface{}) { tx(txs, 1)["type"] = 3 }, "transactions[1]: type: unsupported tx type 3"},
+		{"malformed tx hash", func(_ map[string]interface{}, txs []interface{}) { tx(txs, 0)["txHash"] = "0x1234" }, "transactions[0]: txHash: "},
+		{"malformed data", func(_ map[string]interface{}, txs []interface{}) { tx(txs, 1)["data"] = "0xgg" }, "transactions[1]: data: "},
+		{"typed field of legacy tx", func(_ map[string]interface{}, txs []interface{}) { tx(txs, 1)["gasTipCap"] = "0x1" }, "transactions[1]: gasTipCap, gasFeeCap: not fields of tx type 0"},
+		{"malformed access list", func(_ map[string]interface{}, txs []interface{}) { tx(txs, 1)["accessList"] = "0x1" }, "transactions[1]: accessList: "},
+	}
+	for _, c := range cases {
+		err := malformed(c.change)
+		assert.ErrorIs(t, err, ErrWrappedBlockMalformed, c.name)
+		assert.ErrorContains(t, err, c.contains, c.name)
+	}
+}
+
+func TestDecodeBlockContext(t *testing.T) {
+	// blockTrace_04 includes skipped and included l1 messages
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_04.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	blockBytes, err := wrappedBlock.Encode(0, nil)
+	assert.NoError(t, err)
+	blockContext, err := DecodeBlockContext(blockBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, wrappedBlock.Header.Number.Uint64(), blockContext.Number)
+	assert.Equal(t, wrappedBlock.Header.Time, blockContext.Timestamp)
+	assert.Equal(t, 0, blockContext.BaseFee.Sign())
+	assert.Equal(t, wrappedBlock.Header.GasLimit, blockContext.GasLimit)
+	assert.Equal(t, uint16(wrappedBlock.NumL1Messages(0)), blockContext.NumL1Messages)
+	assert.Equal(t, uint16(wrappedBlock.NumL1Messages(0)+wrappedBlock.NumL2Transactions()), blockContext.NumTransactions)
+
+	// the block contexts of a chunk encoding
+	chunk := &Chunk{Blocks: []*WrappedBlock{wrappedBlock, wrappedBlock}}
+	chunkBytes, err := chunk.Encode(0)
+	assert.NoError(t, err)
+	secondContext, err := DecodeBlockContext(chunkBytes[61:121])
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0), secondContext.NumL1Messages)
+	assert.Equal(t, uint16(wrappedBlock.NumL2Transactions()), secondContext.NumTransactions)
+
+	_, err = DecodeBlockContext(blockBytes[:59])
+	assert.Error(t, err)
+}
+
+func TestChunkEmptyBlocks(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	emptyBlock := &WrappedBlock{Header: wrappedBlock.Header, WithdrawRoot: wrappedBlock.WithdrawRoot}
+
+	// an empty block costs only its BlockContext
+	assert.Equal(t, uint64(0), emptyBlock.NumL1Messages(5))
+	assert.Equal(t, uint64(0), emptyBlock.NumL2Transactions())
+	estimatedSize, err := emptyBlock.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(60), estimatedSize)
+	exactSize, err := emptyBlock.L1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(60), exactSize)
+	blockGas, err := emptyBlock.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(CalldataNonZeroByteGas*60), blockGas)
+	costs, err := emptyBlock.L1CommitCosts()
+	assert.NoError(t, err)
+	assert.Empty(t, costs)
+
+	blockBytes, err := emptyBlock.Encode(5, nil)
+	assert.NoError(t, err)
+	assert.Len(t, blockBytes, 60)
+	assert.Equal(t, []byte{0, 0, 0, 0}, blockBytes[56:60])
+
+	// a chunk of empty blocks
+	chunk := &Chunk{Blocks: []*WrappedBlock{emptyBlock, emptyBlock}}
+	chunkBytes, err := chunk.Encode(5)
+	assert.NoError(t, err)
+	assert.Len(t, chunkBytes, 121)
+	assert.Equal(t, byte(2), chunkBytes[0])
+	chunkSize, err := chunk.EncodeTo(io.Discard, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(chunkBytes)), chunkSize)
+	assert.Equal(t, uint64(0), chunk.NumL1Messages(5))
+
+	chunkGas, err := chunk.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, 2*blockGas+2*100+CalldataNonZeroByteGas+CalldataNonZeroByteGas*2*60+GetKeccak256Gas(2*58), chunkGas)
+
+	hash, err := chunk.Hash(5)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(chunkBytes[1:59], chunkBytes[61:119]), hash)
+
+	// an empty block between blocks with l1 messages does not pop any message
+	templateBlockTrace, err = os.ReadFile("../testdata/blockTrace_04.json")
+	assert.NoError(t, err)
+	l1MessageBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, l1MessageBlock))
+	mixedChunk := &Chunk{Blocks: []*WrappedBlock{l1MessageBlock, emptyBlock}}
+	assert.Equal(t, l1MessageBlock.NumL1Messages(0), mixedChunk.NumL1Messages(0))
+	mixedBytes, err := mixedChunk.Encode(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0}, mixedBytes[1+60+56:1+60+60])
+}
+
+func TestTypedTxL1CommitSize(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	signer := gethTypes.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	to := common.HexToAddress("0x1234")
+
+	txs := []gethTypes.TxData{
+		&gethTypes.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1e9), Data: []byte{1, 2, 3}},
+		&gethTypes.AccessListTx{ChainID: params.TestChainConfig.ChainID, Nonce: 2, To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: big.NewInt(1e9), Data: []byte{1, 2, 3}},
+		&gethTypes.DynamicFeeTx{ChainID: params.TestChainConfig.ChainID, Nonce: 3, To: &to, Value: big.NewInt(1), Gas: 21000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(1e9), Data: []byte{1, 2, 3}},
+	}
+	block := &WrappedBlock{Header: &gethTypes.Header{Number: big.NewInt(1)}}
+	var expectedSize uint64 = 60
+	for _, txInner := range txs {
+		tx, err := gethTypes.SignNewTx(key, signer, txInner)
+		assert.NoError(t, err)
+		expected, err := tx.MarshalBinary()
+		assert.NoError(t, err)
+
+		txData := gethTypes.NewTransactionData(tx, 1, params.TestChainConfig)
+		rlpTxData, err := convertTxDataToRLPEncoding(txData, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, rlpTxData, "tx type %d", tx.Type())
+
+		block.Transactions = append(block.Transactions, txData)
+		expectedSize += 4 + uint64(len(expected))
+	}
+
+	size, err := block.L1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSize, size)
+	estimatedSize, err := block.EstimateL1CommitCalldataSize()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSize, estimatedSize)
+
+	block.Transactions[0].Type = 5
+	_, err = convertTxDataToRLPEncoding(block.Transactions[0], nil)
+	assert.Error(t, err)
+}
+
+func TestTypedTxFieldsRoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	chainID := params.TestChainConfig.ChainID
+	signer := gethTypes.LatestSignerForChainID(chainID)
+	to := common.HexToAddress("0x1234")
+	accessList := gethTypes.AccessList{{Address: to, StorageKeys: []common.Hash{common.HexToHash("0x1"), common.HexToHash("0x2")}}}
+
+	var signedTxs []*gethTypes.Transaction
+	for _, txInner := range []gethTypes.TxData{
+		&gethTypes.AccessListTx{ChainID: chainID, Nonce: 1, To: &to, Value: big.NewInt(1), Gas: 50000, GasPrice: big.NewInt(1e9), AccessList: accessList},
+		&gethTypes.DynamicFeeTx{ChainID: chainID, Nonce: 2, To: &to, Value: big.NewInt(1), Gas: 50000, GasTipCap: big.NewInt(1e8), GasFeeCap: big.NewInt(2e9), AccessList: accessList, Data: []byte{1, 2, 3}},
+	} {
+		tx, err := gethTypes.SignNewTx(key, signer, txInner)
+		assert.NoError(t, err)
+		signedTxs = append(signedTxs, tx)
+	}
+
+	block := &WrappedBlock{Header: &gethTypes.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0)}, TypedTxFields: make(map[common.Hash]*TypedTxFields)}
+	var expectedPayload []byte
+	for _, tx := range signedTxs {
+		// the effective gas price is the only fee of the trace
+		txData := gethTypes.NewTransactionData(tx, 1, params.TestChainConfig)
+		txData.GasPrice = (*hexutil.Big)(big.NewInt(1e9))
+		block.Transactions = append(block.Transactions, txData)
+		block.TypedTxFields[tx.Hash()] = NewTypedTxFields(tx)
+		rawTx, err := tx.MarshalBinary()
+		assert.NoError(t, err)
+		expectedPayload = append(expectedPayload, rawTx...)
+	}
+
+	// the typed fields survive the json of the block and the one of its transactions
+	encoded, err := json.Marshal(block)
+	assert.NoError(t, err)
+	decoded := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(encoded, decoded))
+	encodedTxs, err := block.MarshalTransactions()
+	assert.NoError(t, err)
+	decodedTxs := &WrappedBlock{Header: block.Header}
+	assert.NoError(t, decodedTxs.UnmarshalTransactions(encodedTxs))
+
+	for _, b := range []*WrappedBlock{decoded, decodedTxs} {
+		payload, err := b.TxPayload()
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPayload, payload)
+		for i, tx := range signedTxs {
+			rebuilt, err := newTxFromTxData(b.Transactions[i], b.typedTxFields(b.Transactions[i]))
+			assert.NoError(t, err)
+			assert.Equal(t, tx.Hash(), rebuilt.Hash())
+		}
+	}
+
+	// without the typed fields the transactions can't be rebuilt as signed, the encoding fails instead
+	block.TypedTxFields = nil
+	_, err = block.TxPayload()
+	assert.ErrorIs(t, err, ErrTxNotReproducible)
+	_, err = (&Chunk{Blocks: []*WrappedBlock{block}}).Encode(0)
+	assert.ErrorIs(t, err, ErrTxNotReproducible)
+	_, err = block.EstimateL1CommitCalldataSize()
+	assert.ErrorIs(t, err, ErrTxNotReproducible)
+}
+
+func TestBlockContextBaseFee(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	wrappedBlock.Header.BaseFee = big.NewInt(1e9)
+	number := wrappedBlock.Header.Number.Uint64()
+
+	// no base fee fork
+	blockBytes, err := wrappedBlock.Encode(0, nil)
+	assert.NoError(t, err)
+	blockContext, err := DecodeBlockContext(blockBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), blockContext.BaseFee.Int64())
+
+	// before the base fee fork
+	forkBlock := number + 1
+	blockBytes, err = wrappedBlock.Encode(0, &forkBlock)
+	assert.NoError(t, err)
+	blockContext, err = DecodeBlockContext(blockBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), blockContext.BaseFee.Int64())
+
+	// from the base fee fork on
+	forkBlock = number
+	blockBytes, err = wrappedBlock.Encode(0, &forkBlock)
+	assert.NoError(t, err)
+	blockContext, err = DecodeBlockContext(blockBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, wrappedBlock.Header.BaseFee, blockContext.BaseFee)
+	assert.Equal(t, wrappedBlock.Header.GasLimit, blockContext.GasLimit)
+
+	wrappedBlock.Header.BaseFee = new(big.Int).Lsh(big.NewInt(1), 256)
+	_, err = wrappedBlock.Encode(0, &forkBlock)
+	assert.Error(t, err)
+}
+
+func TestChunkL1CommitCalldataGasBaseFee(t *testing.T) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	wrappedBlock.Header.BaseFee = big.NewInt(0x0102)
+	chunk := &Chunk{Blocks: []*WrappedBlock{wrappedBlock}}
+
+	estimatedGas, err := chunk.EstimateL1CommitGas()
+	assert.NoError(t, err)
+
+	// the base fee is encoded as zero bytes before the base fee fork
+	assert.False(t, wrappedBlock.IsBaseFeeEncoded(chunk.BaseFeeForkBlock))
+	gasBeforeFork, err := chunk.L1CommitCalldataGas(0)
+	assert.NoError(t, err)
+	assert.Greater(t, estimatedGas, gasBeforeFork)
+	hashBeforeFork, err := chunk.Hash(0)
+	assert.NoError(t, err)
+
+	forkBlock := wrappedBlock.Header.Number.Uint64()
+	chunk.BaseFeeForkBlock = &forkBlock
+	assert.True(t, wrappedBlock.IsBaseFeeEncoded(chunk.BaseFeeForkBlock))
+	gasAfterFork, err := chunk.L1CommitCalldataGas(0)
+	assert.NoError(t, err)
+	assert.Equal(t, gasBeforeFork+2*(CalldataNonZeroByteGas-CalldataZeroByteGas), gasAfterFork)
+	assert.Greater(t, estimatedGas, gasAfterFork)
+
+	chunkBytes, err := chunk.Encode(0)
+	assert.NoError(t, err)
+	assert.Equal(t, getCalldataGas(chunkBytes), gasAfterFork)
+
+	// the chunk hash follows the fork of the chunk, not of another chunk of the same blocks
+	hashAfterFork, err := chunk.Hash(0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hashBeforeFork, hashAfterFork)
+	otherHash, err := (&Chunk{Blocks: chunk.Blocks}).Hash(0)
+	assert.NoError(t, err)
+	assert.Equal(t, hashBeforeFork, otherHash)
+}
+
+func TestChunkFixture(t *testing.T) {
+	var blocks []*WrappedBlock
+	for _, name := range []string{"blockTrace_02.json", "blockTrace_03.json"} {
+		templateBlockTrace, err := os.ReadFile("../testdata/" + name)
+		assert.NoError(t, err)
+		wrappedBlock := &WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		blocks = append(blocks, wrappedBlock)
+	}
+
+	_, err := NewChunkFixture(nil, 0, nil)
+	assert.Error(t, err)
+
+	fixture, err := NewChunkFixture(blocks, 0, nil)
+	assert.NoError(t, err)
+	assert.Len(t, fixture.BlockContexts, 2)
+	assert.NoError(t, fixture.Verify())
+
+	// the fixture verifies after a json round trip
+	data, err := json.Marshal(fixture)
+	assert.NoError(t, err)
+	loaded := &ChunkFixture{}
+	assert.NoError(t, json.Unmarshal(data, loaded))
+	assert.NoError(t, loaded.Verify())
+
+	// a changed expectation fails the verification
+	loaded.Hash = common.Hash{}
+	assert.ErrorContains(t, loaded.Verify(), "chunk hash mismatch")
+	loaded.Hash = fixture.Hash
+	loaded.L1CommitCalldataSize++
+	assert.ErrorContains(t, loaded.Verify(), "calldata size mismatch")
+}
+
+func TestBatchBlob(t *testing.T) {
+	var chunks []*Chunk
+	for _, name := range []string{"blockTrace_02.json", "blockTrace_03.json"} {
+		templateBlockTrace, err := os.ReadFile("../testdata/" + name)
+		assert.NoError(t, err)
+		wrappedBlock := &WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		chunks = append(chunks, &Chunk{Blocks: []*WrappedBlock{wrappedBlock}})
+	}
+
+	_, err := NewBatchBlob(nil)
+	assert.Error(t, err)
+	_, err = NewBatchBlob(make([]*Chunk, MaxBlobChunks+1))
+	assert.Error(t, err)
+
+	batchBlob, err := NewBatchBlob(chunks)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0x01), batchBlob.VersionedHash[0])
+	for i := 0; i < BlobFieldElements; i++ {
+		assert.Equal(t, byte(0), batchBlob.Blob[32*i])
+	}
+
+	payloads, err := DecodeBatchBlob(batchBlob.Blob)
+	assert.NoError(t, err)
+	assert.Len(t, payloads, len(chunks))
+	for i, chunk := range chunks {
+		payload, err := chunk.TxPayload()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, payload)
+		assert.Equal(t, payload, payloads[i])
+	}
+
+	sidecar, err := batchBlob.Sidecar()
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Hash{batchBlob.VersionedHash}, sidecar.BlobHashes())
+
+	// the data exceeding a blob is rejected
+	_, err = NewBlob(make([]byte, MaxBlobDataSize+1))
+	assert.Error(t, err)
+	data := make([]byte, MaxBlobDataSize)
+	data[MaxBlobDataSize-1] = 1
+	blob, err := NewBlob(data)
+	assert.NoError(t, err)
+	decoded, err := DecodeBlob(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decoded)
+}
+
+func TestCompressBatchPayload(t *testing.T) {
+	var chunks []*Chunk
+	for _, name := range []string{"blockTrace_02.json", "blockTrace_03.json"} {
+		templateBlockTrace, err := os.ReadFile("../testdata/" + name)
+		assert.NoError(t, err)
+		wrappedBlock := &WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		chunks = append(chunks, &Chunk{Blocks: []*WrappedBlock{wrappedBlock}})
+	}
+
+	payload, err := NewBatchPayload(chunks)
+	assert.NoError(t, err)
+	compressed := CompressBatchPayload(payload)
+	decompressed, err := DecompressBatchPayload(compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decompressed)
+	_, err = DecompressBatchPayload([]byte{1, 2, 3})
+	assert.Error(t, err)
+
+	// the compressed sizes of the blocks approximate the compressed size of the chunk
+	chunk := &Chunk{Blocks: []*WrappedBlock{chunks[0].Blocks[0], chunks[1].Blocks[0]}}
+	chunkSize, err := chunk.EstimateCompressedSize()
+	assert.NoError(t, err)
+	var blocksSize uint64
+	for _, block := range chunk.Blocks {
+		blockSize, err := block.EstimateCompressedSize()
+		assert.NoError(t, err)
+		blocksSize += blockSize
+	}
+	assert.InEpsilon(t, blocksSize, chunkSize, 0.05)
+}
+
+func TestWrappedBlockValidate(t *testing.T) {
+	loadBlock := func(file string) *WrappedBlock {
+		templateBlockTrace, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		wrappedBlock := &WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		return wrappedBlock
+	}
+
+	block2 := loadBlock("../testdata/blockTrace_02.json")
+	block3 := loadBlock("../testdata/blockTrace_03.json")
+	assert.NoError(t, block2.Validate(nil, 0, true))
+	assert.NoError(t, block3.Validate(block2.Header, 0, true))
+
+	// header invariants
+	assert.ErrorIs(t, (&WrappedBlock{}).Validate(nil, 0, false), ErrBlockHeaderMissing)
+	block := loadBlock("../testdata/blockTrace_02.json")
+	block.Header.Number = new(big.Int).Lsh(block.Header.Number, 64)
+	assert.ErrorIs(t, block.Validate(nil, 0, false), ErrBlockNumberNotUint64)
+	assert.ErrorIs(t, block3.Validate(block3.Header, 0, true), ErrBlockNotChildOfParent)
+	block = loadBlock("../testdata/blockTrace_03.json")
+	block.Header.Time = block2.Header.Time - 1
+	assert.ErrorIs(t, block.Validate(block2.Header, 0, true), ErrBlockTimestampNotMonotonic)
+	block.Header.Time = block2.Header.Time
+	assert.NoError(t, block.Validate(block2.Header, 0, true))
+
+	// L1 messages, the skipped ones included
+	block4 := loadBlock("../testdata/blockTrace_04.json")
+	assert.NoError(t, block4.Validate(nil, 0, true))
+	assert.NoError(t, block4.Validate(nil, 10, true))
+	assert.ErrorIs(t, block4.Validate(nil, 11, true), ErrL1MessagesNotSorted)
+	block6 := loadBlock("../testdata/blockTrace_06.json")
+	assert.NoError(t, block6.Validate(nil, 0, true))
+	block6.Transactions[1], block6.Transactions[2] = block6.Transactions[2], block6.Transactions[1]
+	assert.ErrorIs(t, block6.Validate(nil, 0, true), ErrL1MessagesNotSorted)
+	block4.Transactions[0], block4.Transactions[1] = block4.Transactions[1], block4.Transactions[0]
+	assert.ErrorIs(t, block4.Validate(nil, 0, true), ErrL1MessagesNotContiguous)
+
+	// row consumption
+	block = loadBlock("../testdata/blockTrace_02.json")
+	block.RowConsumption = nil
+	assert.NoError(t, block.Validate(nil, 0, false))
+	err := block.Validate(nil, 0, true)
+	assert.ErrorIs(t, err, ErrRowConsumptionMissing)
+	assert.Contains(t, err.Error(), block.Header.Hash().Hex())
+}
+
+func TestChunkRowConsumption(t *testing.T) {
+	block1 := &WrappedBlock{RowConsumption: &gethTypes.RowConsumption{{Name: "evm", RowNumber: 10}, {Name: "keccak", RowNumber: 30}}}
+	block2 := &WrappedBlock{RowConsumption: &gethTypes.RowConsumption{{Name: "evm", RowNumber: 25}, {Name: "mpt", RowNumber: 5}}}
+	chunk := &Chunk{Blocks: []*WrappedBlock{block1, block2}}
+
+	crc, err := chunk.RowConsumption()
+	assert.NoError(t, err)
+	assert.Equal(t, ChunkRowConsumption{"evm": 35, "keccak": 30, "mpt": 5}, crc)
+	subCircuit, max := crc.MaxSubCircuit()
+	assert.Equal(t, "evm", subCircuit)
+	assert.Equal(t, uint64(35), max)
+	crcMax, err := chunk.CrcMax()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(35), crcMax)
+
+	// the clone is not changed by the later blocks
+	cloned := crc.Clone()
+	assert.NoError(t, crc.Add(block1.RowConsumption))
+	assert.Equal(t, uint64(35), cloned.Max())
+	assert.Equal(t, uint64(60), crc.Max())
+
+	// a tie is broken by the sub-circuit name
+	subCircuit, _ = ChunkRowConsumption{"mpt": 7, "evm": 7}.MaxSubCircuit()
+	assert.Equal(t, "evm", subCircuit)
+
+	// the empty chunk consumes no rows, a block without row consumption can't be aggregated
+	crcMax, err = (&Chunk{}).CrcMax()
+	assert.NoError(t, err)
+	assert.Zero(t, crcMax)
+	_, err = NewChunkRowConsumption([]*WrappedBlock{block1, {}})
+	assert.ErrorIs(t, err, ErrRowConsumptionUnknown)
+}
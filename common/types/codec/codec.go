@@ -0,0 +1,91 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+
+	"scroll-tech/common/types"
+)
+
+// Version is the version of the batch encoding, committed as the version byte of the batch header.
+type Version uint8
+
+const (
+	// CodecV0 commits the block contexts and the L2 transactions of the chunks in calldata.
+	CodecV0 Version = 0
+	// CodecV1 commits the block contexts in calldata and the L2 transactions in a blob,
+	// the batch header commits the versioned hash of the blob.
+	CodecV1 Version = 1
+	// CodecV2 is CodecV1 with the blob payload zstd compressed.
+	CodecV2 Version = 2
+)
+
+// BatchHeader is the batch header of a codec, the types.BatchHeader of CodecV0.
+type BatchHeader interface {
+	Version() uint8
+	BatchIndex() uint64
+	L1MessagePopped() uint64
+	TotalL1MessagePopped() uint64
+	DataHash() common.Hash
+	ParentBatchHash() common.Hash
+	SkippedL1MessageBitmap() []byte
+	Encode() []byte
+	Hash() common.Hash
+}
+
+// BlobBatchHeader is the batch header of a codec committing a blob, from CodecV1 on.
+type BlobBatchHeader interface {
+	BatchHeader
+	BlobVersionedHash() common.Hash
+}
+
+// Codec encodes the blocks, chunks and batches of a batch version and estimates their l1 commit costs,
+// so the relayer and the coordinator handle a batch by its version instead of a hard-coded format.
+type Codec interface {
+	// Version returns the batch version of the codec.
+	Version() Version
+	// EncodeBlock encodes the block context of the block, baseFeeForkBlock is the first block carrying its base fee.
+	EncodeBlock(block *types.WrappedBlock, totalL1MessagePoppedBefore uint64, baseFeeForkBlock *uint64) ([]byte, error)
+	// EncodeChunk encodes the chunk committed in the commitBatch calldata.
+	EncodeChunk(chunk *types.Chunk, totalL1MessagePoppedBefore uint64) ([]byte, error)
+	// ChunkHash returns the hash of the chunk the batch data hash is computed over.
+	ChunkHash(chunk *types.Chunk, totalL1MessagePoppedBefore uint64) (common.Hash, error)
+	// NewBatchHeader creates the header of the batch of the chunks, its Encode is the batch encoding.
+	NewBatchHeader(batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*types.Chunk) (BatchHeader, error)
+	// NewBatchBlob creates the blob committed with the batch, nil if the codec commits no blob.
+	NewBatchBlob(chunks []*types.Chunk) (*types.BatchBlob, error)
+	// EstimateChunkL1CommitCalldataSize estimates the size of the chunk in the commitBatch calldata.
+	EstimateChunkL1CommitCalldataSize(chunk *types.Chunk) (uint64, error)
+	// EstimateChunkL1CommitGas estimates the gas of committing the chunk, the blob gas left out.
+	EstimateChunkL1CommitGas(chunk *types.Chunk) (uint64, error)
+}
+
+// New returns the codec of the batch version.
+func New(version Version) (Codec, error) {
+	switch version {
+	case CodecV0:
+		return &codecV0{}, nil
+	case CodecV1:
+		return &codecV1{version: CodecV1}, nil
+	case CodecV2:
+		return &codecV1{version: CodecV2, compressed: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported codec version %v", version)
+	}
+}
+
+// DecodeBatchHeader decodes the batch header by the codec of its version byte.
+func DecodeBatchHeader(data []byte) (BatchHeader, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("insufficient data for BatchHeader")
+	}
+	switch Version(data[0]) {
+	case CodecV0:
+		return types.DecodeBatchHeader(data)
+	case CodecV1, CodecV2:
+		return decodeBatchHeaderV1(data)
+	default:
+		return nil, fmt.Errorf("unsupported codec version %v", data[0])
+	}
+}
@@ -0,0 +1,148 @@
+package codec
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scroll-tech/common/types"
+)
+
+func readChunk(t *testing.T, names ...string) *types.Chunk {
+	chunk := &types.Chunk{}
+	for _, name := range names {
+		templateBlockTrace, err := os.ReadFile("../../testdata/" + name)
+		require.NoError(t, err)
+		wrappedBlock := &types.WrappedBlock{}
+		require.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		chunk.Blocks = append(chunk.Blocks, wrappedBlock)
+	}
+	return chunk
+}
+
+func TestCodecs(t *testing.T) {
+	chunk := readChunk(t, "blockTrace_02.json", "blockTrace_03.json")
+	chunkWithL1Messages := readChunk(t, "blockTrace_04.json")
+	chunks := []*types.Chunk{chunk, chunkWithL1Messages}
+	parentBatchHash := common.HexToHash("0x1")
+
+	_, err := New(Version(3))
+	assert.Error(t, err)
+
+	v0, err := New(CodecV0)
+	require.NoError(t, err)
+	v0Header, err := v0.NewBatchHeader(1, 0, parentBatchHash, chunks)
+	require.NoError(t, err)
+	v0Blob, err := v0.NewBatchBlob(chunks)
+	assert.NoError(t, err)
+	assert.Nil(t, v0Blob)
+
+	for _, version := range []Version{CodecV0, CodecV1, CodecV2} {
+		c, err := New(version)
+		require.NoError(t, err)
+		assert.Equal(t, version, c.Version())
+
+		// the block contexts are shared by the codecs
+		blockBytes, err := c.EncodeBlock(chunk.Blocks[0], 0, nil)
+		assert.NoError(t, err)
+		v0BlockBytes, err := v0.EncodeBlock(chunk.Blocks[0], 0, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, v0BlockBytes, blockBytes)
+
+		chunkBytes, err := c.EncodeChunk(chunk, 0)
+		assert.NoError(t, err)
+		calldataSize, err := c.EstimateChunkL1CommitCalldataSize(chunk)
+		assert.NoError(t, err)
+		commitGas, err := c.EstimateChunkL1CommitGas(chunk)
+		assert.NoError(t, err)
+		assert.NotZero(t, commitGas)
+
+		header, err := c.NewBatchHeader(1, 0, parentBatchHash, chunks)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(version), header.Version())
+		assert.Equal(t, v0Header.L1MessagePopped(), header.L1MessagePopped())
+		assert.Equal(t, v0Header.SkippedL1MessageBitmap(), header.SkippedL1MessageBitmap())
+		assert.Equal(t, parentBatchHash, header.ParentBatchHash())
+
+		decoded, err := DecodeBatchHeader(header.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, header.Encode(), decoded.Encode())
+		assert.Equal(t, header.Hash(), decoded.Hash())
+
+		if version == CodecV0 {
+			assert.Equal(t, uint64(len(chunkBytes)), calldataSize+1)
+			assert.Equal(t, v0Header.DataHash(), header.DataHash())
+			continue
+		}
+
+		// the blob codecs hash the block contexts and the L1 messages only, the L2 transactions are in the blob
+		var chunkHashes []common.Hash
+		for _, ch := range chunks {
+			var hashData []byte
+			for _, block := range ch.Blocks {
+				blockBytes, err := block.Encode(0, nil)
+				require.NoError(t, err)
+				hashData = append(hashData, blockBytes[:58]...)
+			}
+			for _, block := range ch.Blocks {
+				for _, tx := range block.Transactions {
+					if tx.Type == gethTypes.L1MessageTxType {
+						hashData = append(hashData, common.HexToHash(tx.TxHash).Bytes()...)
+					}
+				}
+			}
+			chunkHash, err := c.ChunkHash(ch, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, crypto.Keccak256Hash(hashData), chunkHash)
+			v0ChunkHash, err := v0.ChunkHash(ch, 0)
+			assert.NoError(t, err)
+			assert.NotEqual(t, v0ChunkHash, chunkHash)
+			chunkHashes = append(chunkHashes, chunkHash)
+		}
+		assert.Equal(t, types.BatchDataHash(chunkHashes), header.DataHash())
+
+		// the contract hashes the committed block contexts and the L1 messages into the same data hash,
+		// blockTrace_05 includes the L1 messages 37 to 41 with their actual hashes
+		contractChunks := []*types.Chunk{chunk, readChunk(t, "blockTrace_05.json")}
+		contractHeader, err := c.NewBatchHeader(1, 0, parentBatchHash, contractChunks)
+		require.NoError(t, err)
+		encodedChunks := make([][]byte, len(contractChunks))
+		for i, ch := range contractChunks {
+			encodedChunks[i], err = c.EncodeChunk(ch, 0)
+			require.NoError(t, err)
+		}
+		batch := &types.Batch{Index: 1, ParentBatchHash: parentBatchHash, Chunks: contractChunks}
+		blobDataHash, err := batch.BlobDataHash(encodedChunks)
+		assert.NoError(t, err)
+		assert.Equal(t, contractHeader.DataHash(), blobDataHash)
+		_, err = batch.DataHash(encodedChunks)
+		assert.Error(t, err)
+		assert.NotEqual(t, v0Header.DataHash(), header.DataHash())
+
+		// the blob codecs commit the block contexts only in calldata
+		assert.Equal(t, v0BlockBytes, chunkBytes[1:61])
+		assert.Len(t, chunkBytes, 1+60*len(chunk.Blocks))
+		assert.Equal(t, uint64(len(chunkBytes)), calldataSize)
+		v0CommitGas, err := v0.EstimateChunkL1CommitGas(chunk)
+		assert.NoError(t, err)
+		assert.Less(t, commitGas, v0CommitGas)
+
+		blob, err := c.NewBatchBlob(chunks)
+		require.NoError(t, err)
+		assert.Equal(t, blob.VersionedHash, header.(*batchHeaderV1).BlobVersionedHash())
+		assert.Equal(t, blob.VersionedHash, decoded.(*batchHeaderV1).BlobVersionedHash())
+		assert.Len(t, header.Encode(), BatchHeaderV1FixedSize+len(header.SkippedL1MessageBitmap()))
+		assert.NotEqual(t, v0Header.Hash(), header.Hash())
+	}
+
+	_, err = DecodeBatchHeader([]byte{3})
+	assert.Error(t, err)
+	_, err = DecodeBatchHeader([]byte{1, 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+
+	"scroll-tech/common/types"
+)
+
+// codecV0 is the calldata encoding implemented by the types package.
+type codecV0 struct{}
+
+func (c *codecV0) Version() Version {
+	return CodecV0
+}
+
+func (c *codecV0) EncodeBlock(block *types.WrappedBlock, totalL1MessagePoppedBefore uint64, baseFeeForkBlock *uint64) ([]byte, error) {
+	return block.Encode(totalL1MessagePoppedBefore, baseFeeForkBlock)
+}
+
+func (c *codecV0) EncodeChunk(chunk *types.Chunk, totalL1MessagePoppedBefore uint64) ([]byte, error) {
+	return chunk.Encode(totalL1MessagePoppedBefore)
+}
+
+func (c *codecV0) ChunkHash(chunk *types.Chunk, totalL1MessagePoppedBefore uint64) (common.Hash, error) {
+	return chunk.Hash(totalL1MessagePoppedBefore)
+}
+
+func (c *codecV0) NewBatchHeader(batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*types.Chunk) (BatchHeader, error) {
+	return types.NewBatchHeader(uint8(CodecV0), batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
+}
+
+func (c *codecV0) NewBatchBlob([]*types.Chunk) (*types.BatchBlob, error) {
+	return nil, nil
+}
+
+func (c *codecV0) EstimateChunkL1CommitCalldataSize(chunk *types.Chunk) (uint64, error) {
+	var size uint64
+	for _, block := range chunk.Blocks {
+		blockSize, err := block.EstimateL1CommitCalldataSize()
+		if err != nil {
+			return 0, err
+		}
+		size += blockSize
+	}
+	return size, nil
+}
+
+func (c *codecV0) EstimateChunkL1CommitGas(chunk *types.Chunk) (uint64, error) {
+	return chunk.EstimateL1CommitGas()
+}
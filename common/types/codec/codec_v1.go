@@ -0,0 +1,169 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+
+	"scroll-tech/common/types"
+)
+
+// BatchHeaderV1FixedSize is the size of the CodecV1 batch header encoding without the skipped L1 message bitmap.
+const BatchHeaderV1FixedSize = 121
+
+// codecV1 commits the L2 transactions of the chunks in a blob, the compressed one of CodecV2 included.
+type codecV1 struct {
+	version    Version
+	compressed bool
+}
+
+func (c *codecV1) Version() Version {
+	return c.version
+}
+
+func (c *codecV1) EncodeBlock(block *types.WrappedBlock, totalL1MessagePoppedBefore uint64, baseFeeForkBlock *uint64) ([]byte, error) {
+	return block.Encode(totalL1MessagePoppedBefore, baseFeeForkBlock)
+}
+
+// EncodeChunk encodes the chunk into
+//
+//	numBlocks (1 byte) || blockContext (60 bytes) * numBlocks
+//
+// the L2 transactions are committed in the blob.
+func (c *codecV1) EncodeChunk(chunk *types.Chunk, totalL1MessagePoppedBefore uint64) ([]byte, error) {
+	chunkBytes, err := chunk.Encode(totalL1MessagePoppedBefore)
+	if err != nil {
+		return nil, err
+	}
+	return chunkBytes[:1+60*len(chunk.Blocks)], nil
+}
+
+// ChunkHash hashes the chunk as
+//
+//	keccak256(blockContext[0:58] * numBlocks || l1MessageTxHash * numL1Messages)
+//
+// the L2 transactions are committed by the blob versioned hash instead.
+func (c *codecV1) ChunkHash(chunk *types.Chunk, totalL1MessagePoppedBefore uint64) (common.Hash, error) {
+	chunkBytes, err := c.EncodeChunk(chunk, totalL1MessagePoppedBefore)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hasher := types.NewKeccakWriter()
+	for i := range chunk.Blocks {
+		blockContext := chunkBytes[1+60*i : 1+60*(i+1)]
+		if _, err := hasher.Write(blockContext[:58]); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	for _, block := range chunk.Blocks {
+		for _, txData := range block.Transactions {
+			if txData.Type != gethTypes.L1MessageTxType {
+				continue
+			}
+			hashBytes, err := hex.DecodeString(strings.TrimPrefix(txData.TxHash, "0x"))
+			if err != nil {
+				return common.Hash{}, err
+			}
+			if _, err := hasher.Write(hashBytes); err != nil {
+				return common.Hash{}, err
+			}
+		}
+	}
+	return hasher.Hash(), nil
+}
+
+func (c *codecV1) NewBatchHeader(batchIndex, totalL1MessagePoppedBefore uint64, parentBatchHash common.Hash, chunks []*types.Chunk) (BatchHeader, error) {
+	blob, err := c.NewBatchBlob(chunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch blob: %w", err)
+	}
+	header, err := types.NewBatchHeaderWithChunkHash(uint8(c.version), batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks, c.ChunkHash)
+	if err != nil {
+		return nil, err
+	}
+	return &batchHeaderV1{BatchHeader: header, blobVersionedHash: blob.VersionedHash}, nil
+}
+
+func (c *codecV1) NewBatchBlob(chunks []*types.Chunk) (*types.BatchBlob, error) {
+	if c.compressed {
+		return types.NewCompressedBatchBlob(chunks)
+	}
+	return types.NewBatchBlob(chunks)
+}
+
+func (c *codecV1) EstimateChunkL1CommitCalldataSize(chunk *types.Chunk) (uint64, error) {
+	return 1 + 60*uint64(len(chunk.Blocks)), nil
+}
+
+// EstimateChunkL1CommitGas estimates the commit gas of the chunk by the CodecV0 estimate without the calldata
+// of the L2 transactions, which are hashed all the same.
+func (c *codecV1) EstimateChunkL1CommitGas(chunk *types.Chunk) (uint64, error) {
+	totalL1CommitGas, err := chunk.EstimateL1CommitGas()
+	if err != nil {
+		return 0, err
+	}
+	for _, block := range chunk.Blocks {
+		blockSize, err := block.EstimateL1CommitCalldataSize()
+		if err != nil {
+			return 0, err
+		}
+		totalL1CommitGas -= types.CalldataNonZeroByteGas * (blockSize - 60) // the calldata besides the BlockContext
+	}
+	return totalL1CommitGas, nil
+}
+
+// batchHeaderV1 is the CodecV0 batch header committing the versioned hash of the blob of the batch,
+// its data hash is computed over the CodecV1 chunk hashes.
+type batchHeaderV1 struct {
+	*types.BatchHeader
+	blobVersionedHash common.Hash
+}
+
+// BlobVersionedHash returns the versioned hash of the blob of the batch.
+func (b *batchHeaderV1) BlobVersionedHash() common.Hash {
+	return b.blobVersionedHash
+}
+
+// Encode encodes the batch header into
+//
+//	version (1 byte) || batchIndex (8 bytes) || l1MessagePopped (8 bytes) || totalL1MessagePopped (8 bytes) ||
+//	dataHash (32 bytes) || blobVersionedHash (32 bytes) || parentBatchHash (32 bytes) || skippedL1MessageBitmap
+func (b *batchHeaderV1) Encode() []byte {
+	bitmap := b.SkippedL1MessageBitmap()
+	batchBytes := make([]byte, BatchHeaderV1FixedSize+len(bitmap))
+	batchBytes[0] = b.Version()
+	binary.BigEndian.PutUint64(batchBytes[1:], b.BatchIndex())
+	binary.BigEndian.PutUint64(batchBytes[9:], b.L1MessagePopped())
+	binary.BigEndian.PutUint64(batchBytes[17:], b.TotalL1MessagePopped())
+	dataHash, parentBatchHash := b.DataHash(), b.ParentBatchHash()
+	copy(batchBytes[25:], dataHash[:])
+	copy(batchBytes[57:], b.blobVersionedHash[:])
+	copy(batchBytes[89:], parentBatchHash[:])
+	copy(batchBytes[BatchHeaderV1FixedSize:], bitmap)
+	return batchBytes
+}
+
+// Hash calculates the hash of the batch header.
+func (b *batchHeaderV1) Hash() common.Hash {
+	return crypto.Keccak256Hash(b.Encode())
+}
+
+func decodeBatchHeaderV1(data []byte) (*batchHeaderV1, error) {
+	if len(data) < BatchHeaderV1FixedSize {
+		return nil, fmt.Errorf("insufficient data for BatchHeader")
+	}
+	// the CodecV0 layout is the CodecV1 one without the blob versioned hash
+	v0Data := make([]byte, 0, len(data)-32)
+	v0Data = append(v0Data, data[:57]...)
+	v0Data = append(v0Data, data[89:]...)
+	header, err := types.DecodeBatchHeader(v0Data)
+	if err != nil {
+		return nil, err
+	}
+	return &batchHeaderV1{BatchHeader: header, blobVersionedHash: common.BytesToHash(data[57:89])}, nil
+}
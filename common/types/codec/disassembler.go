@@ -0,0 +1,229 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
+	"github.com/scroll-tech/go-ethereum/rlp"
+
+	"scroll-tech/common/types"
+)
+
+// ErrUnknownCommitMethod is returned when the calldata calls neither commitBatch nor commitBatchWithBlobProof.
+var ErrUnknownCommitMethod = errors.New("unknown commit method")
+
+var (
+	// commitBatchSelector is the selector of commitBatch(uint8,bytes,bytes[],bytes).
+	commitBatchSelector = crypto.Keccak256([]byte("commitBatch(uint8,bytes,bytes[],bytes)"))[:4]
+	// commitBatchWithBlobProofSelector is the selector of commitBatchWithBlobProof(uint8,bytes,bytes[],bytes,bytes).
+	commitBatchWithBlobProofSelector = crypto.Keccak256([]byte("commitBatchWithBlobProof(uint8,bytes,bytes[],bytes,bytes)"))[:4]
+)
+
+var commitBatchArguments, commitBatchWithBlobProofArguments abi.Arguments
+
+func init() {
+	uint8Type, _ := abi.NewType("uint8", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	bytesArrayType, _ := abi.NewType("bytes[]", "", nil)
+	commitBatchArguments = abi.Arguments{{Type: uint8Type}, {Type: bytesType}, {Type: bytesArrayType}, {Type: bytesType}}
+	commitBatchWithBlobProofArguments = append(abi.Arguments{}, commitBatchArguments...)
+	commitBatchWithBlobProofArguments = append(commitBatchWithBlobProofArguments, abi.Argument{Type: bytesType})
+}
+
+// DisassembledBatch is a batch reconstructed from the calldata of its commit transaction and its blob.
+type DisassembledBatch struct {
+	Version                Version
+	ParentBatchHeader      BatchHeader
+	SkippedL1MessageBitmap []byte
+	// BlobDataProof is the blob data proof of commitBatchWithBlobProof, nil for commitBatch.
+	BlobDataProof []byte
+	Chunks        []*DisassembledChunk
+}
+
+// DisassembledChunk is a chunk of a disassembled batch.
+type DisassembledChunk struct {
+	Blocks []*DisassembledBlock
+}
+
+// DisassembledBlock is a block of a disassembled chunk. The L1 messages are not posted with the batch,
+// only counted by the block context.
+type DisassembledBlock struct {
+	Context        *types.BlockContext
+	L2Transactions []*gethTypes.Transaction
+}
+
+// DisassembleCommitBatch reconstructs the batch posted by a commitBatch or commitBatchWithBlobProof transaction
+// from its calldata, the selector included. The blob carries the L2 transactions from CodecV1 on and is ignored
+// by CodecV0, whose L2 transactions are posted in the calldata.
+func DisassembleCommitBatch(calldata []byte, blob *kzg4844.Blob) (*DisassembledBatch, error) {
+	if len(calldata) < 4 {
+		return nil, fmt.Errorf("%w: calldata is %v bytes long", ErrUnknownCommitMethod, len(calldata))
+	}
+	arguments := commitBatchArguments
+	switch {
+	case bytes.Equal(calldata[:4], commitBatchSelector):
+	case bytes.Equal(calldata[:4], commitBatchWithBlobProofSelector):
+		arguments = commitBatchWithBlobProofArguments
+	default:
+		return nil, fmt.Errorf("%w: selector %x", ErrUnknownCommitMethod, calldata[:4])
+	}
+
+	values, err := arguments.Unpack(calldata[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack commit calldata: %w", err)
+	}
+	batch := &DisassembledBatch{
+		Version:                Version(values[0].(uint8)),
+		SkippedL1MessageBitmap: values[3].([]byte),
+	}
+	if len(values) > 4 {
+		batch.BlobDataProof = values[4].([]byte)
+	}
+	if _, err = New(batch.Version); err != nil {
+		return nil, err
+	}
+	if batch.ParentBatchHeader, err = DecodeBatchHeader(values[1].([]byte)); err != nil {
+		return nil, fmt.Errorf("failed to decode parent batch header: %w", err)
+	}
+
+	encodedChunks := values[2].([][]byte)
+	if len(encodedChunks) == 0 {
+		return nil, errors.New("batch has no chunks")
+	}
+	if batch.Version == CodecV0 {
+		for i, encodedChunk := range encodedChunks {
+			chunk, err := disassembleChunk(encodedChunk, true)
+			if err != nil {
+				return nil, fmt.Errorf("failed to disassemble chunk %v: %w", i, err)
+			}
+			batch.Chunks = append(batch.Chunks, chunk)
+		}
+		return batch, nil
+	}
+
+	if blob == nil {
+		return nil, fmt.Errorf("codec version %v commits the l2 transactions in a blob, but there is no blob", batch.Version)
+	}
+	var txPayloads [][]byte
+	if batch.Version == CodecV1 {
+		txPayloads, err = types.DecodeBatchBlob(blob)
+	} else {
+		txPayloads, err = types.DecodeCompressedBatchBlob(blob)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode batch blob: %w", err)
+	}
+	if len(txPayloads) != len(encodedChunks) {
+		return nil, fmt.Errorf("the blob has %v chunks, the calldata has %v", len(txPayloads), len(encodedChunks))
+	}
+	for i, encodedChunk := range encodedChunks {
+		chunk, err := disassembleChunk(encodedChunk, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to disassemble chunk %v: %w", i, err)
+		}
+		if err = chunk.readL2Transactions(txPayloads[i], false); err != nil {
+			return nil, fmt.Errorf("failed to decode the l2 transactions of chunk %v: %w", i, err)
+		}
+		batch.Chunks = append(batch.Chunks, chunk)
+	}
+	return batch, nil
+}
+
+// disassembleChunk decodes the block contexts of the chunk encoding, followed by the length-prefixed
+// L2 transactions if withTxs.
+func disassembleChunk(data []byte, withTxs bool) (*DisassembledChunk, error) {
+	if len(data) == 0 {
+		return nil, errors.New("chunk is empty")
+	}
+	numBlocks := int(data[0])
+	if numBlocks == 0 {
+		return nil, errors.New("number of blocks is 0")
+	}
+	if len(data) < 1+60*numBlocks {
+		return nil, fmt.Errorf("chunk of %v blocks is %v bytes long", numBlocks, len(data))
+	}
+
+	chunk := &DisassembledChunk{}
+	for i := 0; i < numBlocks; i++ {
+		blockContext, err := types.DecodeBlockContext(data[1+60*i : 1+60*(i+1)])
+		if err != nil {
+			return nil, err
+		}
+		chunk.Blocks = append(chunk.Blocks, &DisassembledBlock{Context: blockContext})
+	}
+
+	txData := data[1+60*numBlocks:]
+	if !withTxs {
+		if len(txData) != 0 {
+			return nil, fmt.Errorf("%v unexpected bytes after the block contexts", len(txData))
+		}
+		return chunk, nil
+	}
+	if err := chunk.readL2Transactions(txData, true); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// readL2Transactions decodes the L2 transactions of the blocks from the data, each one prefixed by its
+// 4-byte length if withLen. The number of L2 transactions of a block is given by its context.
+func (c *DisassembledChunk) readL2Transactions(data []byte, withLen bool) error {
+	for _, block := range c.Blocks {
+		numL2Txs := int(block.Context.NumTransactions) - int(block.Context.NumL1Messages)
+		if numL2Txs < 0 {
+			return fmt.Errorf("block %v has %v transactions but %v l1 messages", block.Context.Number, block.Context.NumTransactions, block.Context.NumL1Messages)
+		}
+		for i := 0; i < numL2Txs; i++ {
+			var rawTx []byte
+			var err error
+			if withLen {
+				if len(data) < 4 {
+					return fmt.Errorf("missing length of tx %v of block %v", i, block.Context.Number)
+				}
+				txLen := binary.BigEndian.Uint32(data[0:4])
+				if uint64(len(data)-4) < uint64(txLen) {
+					return fmt.Errorf("tx %v of block %v exceeds the chunk", i, block.Context.Number)
+				}
+				rawTx, data = data[4:4+txLen], data[4+txLen:]
+			} else if rawTx, data, err = splitRawTx(data); err != nil {
+				return fmt.Errorf("failed to split tx %v of block %v: %w", i, block.Context.Number, err)
+			}
+
+			tx := new(gethTypes.Transaction)
+			if err = tx.UnmarshalBinary(rawTx); err != nil {
+				return fmt.Errorf("failed to decode tx %v of block %v: %w", i, block.Context.Number, err)
+			}
+			block.L2Transactions = append(block.L2Transactions, tx)
+		}
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%v unexpected bytes after the l2 transactions", len(data))
+	}
+	return nil
+}
+
+// splitRawTx splits the first transaction off the concatenated binary encodings of transactions, a typed
+// transaction being its type byte followed by an rlp list and a legacy one an rlp list.
+func splitRawTx(data []byte) ([]byte, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errors.New("no data left")
+	}
+	offset := 0
+	if data[0] < 0x80 {
+		offset = 1
+	}
+	kind, _, rest, err := rlp.Split(data[offset:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if kind != rlp.List {
+		return nil, nil, errors.New("transaction is not an rlp list")
+	}
+	return data[:len(data)-len(rest)], rest, nil
+}
@@ -0,0 +1,114 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"scroll-tech/common/types"
+)
+
+func TestDisassembleCommitBatch(t *testing.T) {
+	chunk := readChunk(t, "blockTrace_02.json", "blockTrace_03.json")
+	chunkWithL1Messages := readChunk(t, "blockTrace_04.json")
+	chunks := []*types.Chunk{chunk, chunkWithL1Messages}
+
+	v0, err := New(CodecV0)
+	require.NoError(t, err)
+	parentBatchHeader, err := v0.NewBatchHeader(0, 0, common.HexToHash("0x1"), []*types.Chunk{chunk})
+	require.NoError(t, err)
+	bitmap := []byte{0x01}
+	blobDataProof := []byte{0x02, 0x03}
+
+	for _, version := range []Version{CodecV0, CodecV1, CodecV2} {
+		c, err := New(version)
+		require.NoError(t, err)
+
+		var encodedChunks [][]byte
+		var totalL1MessagePoppedBefore uint64
+		for _, chunk := range chunks {
+			encodedChunk, err := c.EncodeChunk(chunk, totalL1MessagePoppedBefore)
+			require.NoError(t, err)
+			encodedChunks = append(encodedChunks, encodedChunk)
+			totalL1MessagePoppedBefore += chunk.NumL1Messages(totalL1MessagePoppedBefore)
+		}
+		batchBlob, err := c.NewBatchBlob(chunks)
+		require.NoError(t, err)
+
+		args, err := commitBatchArguments.Pack(uint8(version), parentBatchHeader.Encode(), encodedChunks, bitmap)
+		require.NoError(t, err)
+		argsWithProof, err := commitBatchWithBlobProofArguments.Pack(uint8(version), parentBatchHeader.Encode(), encodedChunks, bitmap, blobDataProof)
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			calldata      []byte
+			blobDataProof []byte
+		}{
+			{commitCalldata(commitBatchSelector, args), nil},
+			{commitCalldata(commitBatchWithBlobProofSelector, argsWithProof), blobDataProof},
+		} {
+			var batch *DisassembledBatch
+			if batchBlob == nil {
+				batch, err = DisassembleCommitBatch(tc.calldata, nil)
+			} else {
+				batch, err = DisassembleCommitBatch(tc.calldata, batchBlob.Blob)
+			}
+			require.NoError(t, err)
+			assert.Equal(t, version, batch.Version)
+			assert.Equal(t, parentBatchHeader.Hash(), batch.ParentBatchHeader.Hash())
+			assert.Equal(t, bitmap, batch.SkippedL1MessageBitmap)
+			assert.Equal(t, tc.blobDataProof, batch.BlobDataProof)
+
+			// the posted block contexts and l2 transactions are the ones of the chunks
+			require.Len(t, batch.Chunks, len(chunks))
+			totalL1MessagePoppedBefore = 0
+			for i, chunk := range chunks {
+				require.Len(t, batch.Chunks[i].Blocks, len(chunk.Blocks))
+				for j, block := range chunk.Blocks {
+					blockBytes, err := block.Encode(totalL1MessagePoppedBefore, nil)
+					require.NoError(t, err)
+					totalL1MessagePoppedBefore += block.NumL1Messages(totalL1MessagePoppedBefore)
+					blockContext, err := types.DecodeBlockContext(blockBytes)
+					require.NoError(t, err)
+					assert.Equal(t, blockContext, batch.Chunks[i].Blocks[j].Context)
+
+					expected, err := block.TxPayload()
+					require.NoError(t, err)
+					var actual []byte
+					for _, tx := range batch.Chunks[i].Blocks[j].L2Transactions {
+						rawTx, err := tx.MarshalBinary()
+						require.NoError(t, err)
+						actual = append(actual, rawTx...)
+					}
+					assert.Equal(t, expected, actual)
+					assert.Equal(t, int(block.NumL2Transactions()), len(batch.Chunks[i].Blocks[j].L2Transactions))
+				}
+			}
+		}
+
+		if version != CodecV0 {
+			_, err = DisassembleCommitBatch(commitCalldata(commitBatchSelector, args), nil)
+			assert.Error(t, err)
+		}
+	}
+
+	_, err = DisassembleCommitBatch([]byte{0x01, 0x02}, nil)
+	assert.True(t, errors.Is(err, ErrUnknownCommitMethod))
+	_, err = DisassembleCommitBatch([]byte{0x01, 0x02, 0x03, 0x04}, nil)
+	assert.True(t, errors.Is(err, ErrUnknownCommitMethod))
+
+	// a truncated chunk is rejected
+	encodedChunk, err := v0.EncodeChunk(chunk, 0)
+	require.NoError(t, err)
+	args, err := commitBatchArguments.Pack(uint8(CodecV0), parentBatchHeader.Encode(), [][]byte{encodedChunk[:len(encodedChunk)-1]}, bitmap)
+	require.NoError(t, err)
+	_, err = DisassembleCommitBatch(commitCalldata(commitBatchSelector, args), nil)
+	assert.Error(t, err)
+}
+
+func commitCalldata(selector, args []byte) []byte {
+	return append(append([]byte{}, selector...), args...)
+}
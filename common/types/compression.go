@@ -0,0 +1,75 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxDecompressedBatchPayloadSize bounds the memory of decompressing a batch payload.
+const maxDecompressedBatchPayloadSize = 16 * 1024 * 1024
+
+var (
+	// the encoder and the decoder are safe for concurrent EncodeAll and DecodeAll calls.
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func init() {
+	var err error
+	// the committed data is hashed already, the frames leave out the checksum
+	zstdEncoder, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression), zstd.WithEncoderCRC(false))
+	if err != nil {
+		panic(fmt.Sprintf("failed to create zstd encoder: %v", err))
+	}
+	zstdDecoder, err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedBatchPayloadSize))
+	if err != nil {
+		panic(fmt.Sprintf("failed to create zstd decoder: %v", err))
+	}
+}
+
+// CompressBatchPayload zstd compresses the batch payload, see NewBatchPayload.
+func CompressBatchPayload(payload []byte) []byte {
+	return zstdEncoder.EncodeAll(payload, nil)
+}
+
+// DecompressBatchPayload decompresses the batch payload compressed by CompressBatchPayload.
+func DecompressBatchPayload(data []byte) ([]byte, error) {
+	payload, err := zstdDecoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress batch payload: %w", err)
+	}
+	return payload, nil
+}
+
+// newBatchPayloadReader returns a reader of the batch payload compressed at the start of data. The bytes
+// following the compressed frame, e.g. the zero padding of a blob, are only read past the end of the payload.
+func newBatchPayloadReader(data []byte) (*zstd.Decoder, error) {
+	reader, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxDecompressedBatchPayloadSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+	}
+	return reader, nil
+}
+
+// EstimateCompressedSize returns the size of the compressed tx payload of the block on its own.
+// The sum over the blocks of a chunk approximates the compressed size of the chunk, the proposer
+// checks it block by block without compressing every candidate chunk again.
+func (w *WrappedBlock) EstimateCompressedSize() (uint64, error) {
+	payload, err := w.TxPayload()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(CompressBatchPayload(payload))), nil
+}
+
+// EstimateCompressedSize returns the size of the compressed tx payload of the chunk, the share of
+// the chunk in the compressed batch payload up to the metadata and the cross chunk matches.
+func (c *Chunk) EstimateCompressedSize() (uint64, error) {
+	payload, err := c.TxPayload()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(CompressBatchPayload(payload))), nil
+}
@@ -0,0 +1,278 @@
+// Package coordinatorpb implements the protobuf encoding of the coordinator messages described in
+// coordinator.proto. The task data and the proofs are large strings, encoding them as protobuf
+// bytes avoids the escaping cost of json on both the coordinator and the provers.
+package coordinatorpb
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"scroll-tech/common/types/message"
+)
+
+// ContentType is the media type of the protobuf encoded messages.
+const ContentType = "application/x-protobuf"
+
+// AcceptHeader is sent by the clients supporting the protobuf encoding, json is kept as the fallback
+// for the coordinators which do not support it.
+const AcceptHeader = ContentType + ", application/json;q=0.9"
+
+// Accepts returns whether the Accept header value allows the protobuf encoding.
+func Accepts(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(mediaRange, ";")
+		if strings.TrimSpace(mediaType) != ContentType {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
+
+// IsContentType returns whether the Content-Type header value is the protobuf media type.
+func IsContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.TrimSpace(mediaType) == ContentType
+}
+
+// GetTaskData is the task assigned to a prover.
+type GetTaskData struct {
+	UUID        string
+	TaskID      string
+	TaskType    int
+	TaskData    string
+	Resources   *message.TaskResources
+	ServerTime  int64
+	DeadlineSec int64
+}
+
+// GetTaskResponse is the response of the get task api.
+type GetTaskResponse struct {
+	ErrCode int
+	ErrMsg  string
+	Data    *GetTaskData
+}
+
+// SubmitProofRequest is the request of the submit proof api.
+type SubmitProofRequest struct {
+	UUID        string
+	TaskID      string
+	TaskType    int
+	Status      int
+	Proof       string
+	FailureType int
+	FailureMsg  string
+}
+
+// Marshal encodes the response.
+func (r *GetTaskResponse) Marshal() []byte {
+	var data []byte
+	if r.Data != nil {
+		data = r.Data.marshal()
+	}
+	b := make([]byte, 0, len(data)+len(r.ErrMsg)+32)
+	b = appendInt(b, 1, int64(r.ErrCode))
+	b = appendString(b, 2, r.ErrMsg)
+	if r.Data != nil {
+		b = appendMessage(b, 3, data)
+	}
+	return b
+}
+
+// Unmarshal decodes the response.
+func (r *GetTaskResponse) Unmarshal(b []byte) error {
+	*r = GetTaskResponse{}
+	return consumeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			r.ErrCode = int(f.varint)
+		case 2:
+			r.ErrMsg = string(f.bytes)
+		case 3:
+			r.Data = &GetTaskData{}
+			return r.Data.unmarshal(f.bytes)
+		}
+		return nil
+	})
+}
+
+func (d *GetTaskData) marshal() []byte {
+	b := make([]byte, 0, len(d.TaskData)+len(d.UUID)+len(d.TaskID)+64)
+	b = appendString(b, 1, d.UUID)
+	b = appendString(b, 2, d.TaskID)
+	b = appendInt(b, 3, int64(d.TaskType))
+	b = appendString(b, 4, d.TaskData)
+	if d.Resources != nil {
+		b = appendMessage(b, 5, marshalTaskResources(d.Resources))
+	}
+	b = appendInt(b, 6, d.ServerTime)
+	b = appendInt(b, 7, d.DeadlineSec)
+	return b
+}
+
+func (d *GetTaskData) unmarshal(b []byte) error {
+	return consumeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			d.UUID = string(f.bytes)
+		case 2:
+			d.TaskID = string(f.bytes)
+		case 3:
+			d.TaskType = int(f.varint)
+		case 4:
+			d.TaskData = string(f.bytes)
+		case 5:
+			d.Resources = &message.TaskResources{}
+			return unmarshalTaskResources(d.Resources, f.bytes)
+		case 6:
+			d.ServerTime = int64(f.varint)
+		case 7:
+			d.DeadlineSec = int64(f.varint)
+		}
+		return nil
+	})
+}
+
+func marshalTaskResources(r *message.TaskResources) []byte {
+	var b []byte
+	b = appendUint(b, 1, r.ExpectedMemoryMB)
+	b = appendUint(b, 2, r.EstimatedDurationSec)
+	if r.Artifacts != nil {
+		var artifacts []byte
+		artifacts = appendString(artifacts, 1, r.Artifacts.Version)
+		artifacts = appendString(artifacts, 2, r.Artifacts.URL)
+		artifacts = appendString(artifacts, 3, r.Artifacts.Checksum)
+		b = appendMessage(b, 3, artifacts)
+	}
+	return b
+}
+
+func unmarshalTaskResources(r *message.TaskResources, b []byte) error {
+	return consumeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			r.ExpectedMemoryMB = f.varint
+		case 2:
+			r.EstimatedDurationSec = f.varint
+		case 3:
+			r.Artifacts = &message.CircuitArtifacts{}
+			return consumeFields(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					r.Artifacts.Version = string(f.bytes)
+				case 2:
+					r.Artifacts.URL = string(f.bytes)
+				case 3:
+					r.Artifacts.Checksum = string(f.bytes)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// Marshal encodes the request.
+func (r *SubmitProofRequest) Marshal() []byte {
+	b := make([]byte, 0, len(r.Proof)+len(r.UUID)+len(r.TaskID)+len(r.FailureMsg)+64)
+	b = appendString(b, 1, r.UUID)
+	b = appendString(b, 2, r.TaskID)
+	b = appendInt(b, 3, int64(r.TaskType))
+	b = appendInt(b, 4, int64(r.Status))
+	b = appendString(b, 5, r.Proof)
+	b = appendInt(b, 6, int64(r.FailureType))
+	b = appendString(b, 7, r.FailureMsg)
+	return b
+}
+
+// Unmarshal decodes the request.
+func (r *SubmitProofRequest) Unmarshal(b []byte) error {
+	*r = SubmitProofRequest{}
+	return consumeFields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			r.UUID = string(f.bytes)
+		case 2:
+			r.TaskID = string(f.bytes)
+		case 3:
+			r.TaskType = int(f.varint)
+		case 4:
+			r.Status = int(f.varint)
+		case 5:
+			r.Proof = string(f.bytes)
+		case 6:
+			r.FailureType = int(f.varint)
+		case 7:
+			r.FailureMsg = string(f.bytes)
+		}
+		return nil
+	})
+}
+
+// the zero values are omitted as in proto3.
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendInt(b []byte, num protowire.Number, v int64) []byte {
+	return appendUint(b, num, uint64(v))
+}
+
+func appendUint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+type field struct {
+	num    protowire.Number
+	varint uint64
+	bytes  []byte
+}
+
+// consumeFields calls fn for each varint and length-delimited field, the fields of
+// the other wire types are skipped so newer messages can still be decoded.
+func consumeFields(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := field{num: num}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			f.num = 0
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if f.num == 0 {
+			continue
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+package coordinatorpb
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+)
+
+func TestAccepts(t *testing.T) {
+	assert.True(t, Accepts(AcceptHeader))
+	assert.True(t, Accepts("application/json, application/x-protobuf"))
+	assert.False(t, Accepts("application/json"))
+	assert.False(t, Accepts("application/x-protobuf; q=0"))
+	assert.False(t, Accepts(""))
+
+	assert.True(t, IsContentType("application/x-protobuf"))
+	assert.True(t, IsContentType("application/x-protobuf; charset=utf-8"))
+	assert.False(t, IsContentType("application/json"))
+}
+
+func TestGetTaskResponse(t *testing.T) {
+	resp := testGetTaskResponse(1024)
+
+	var decoded GetTaskResponse
+	assert.NoError(t, decoded.Unmarshal(resp.Marshal()))
+	assert.Equal(t, resp, &decoded)
+
+	// the zero values are omitted
+	empty := GetTaskResponse{ErrCode: 4001}
+	assert.NoError(t, decoded.Unmarshal(empty.Marshal()))
+	assert.Equal(t, empty, decoded)
+
+	// the unknown fields are skipped
+	b := resp.Marshal()
+	b = protowire.AppendTag(b, 100, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, 1)
+	b = protowire.AppendTag(b, 101, protowire.BytesType)
+	b = protowire.AppendString(b, "unknown")
+	assert.NoError(t, decoded.Unmarshal(b))
+	assert.Equal(t, resp, &decoded)
+
+	assert.Error(t, decoded.Unmarshal(resp.Marshal()[:100]))
+}
+
+func TestSubmitProofRequest(t *testing.T) {
+	req := testSubmitProofRequest(1024)
+
+	var decoded SubmitProofRequest
+	assert.NoError(t, decoded.Unmarshal(req.Marshal()))
+	assert.Equal(t, req, &decoded)
+}
+
+// TestProtoDescriptor checks the hand written encoding against the messages of coordinator.proto,
+// each message must be decoded by the reference protobuf implementation and the other way around.
+func TestProtoDescriptor(t *testing.T) {
+	file := loadProtoFile(t)
+
+	getTaskResponses := map[string]*GetTaskResponse{
+		"Full":  testGetTaskResponse(1),
+		"Empty": {},
+		"Error": {ErrCode: 4001, ErrMsg: "no task"},
+		"NoResources": {Data: &GetTaskData{
+			UUID: "8c5b5f96-8e0e-4f3e-9b5a-5d6d7f8e9a0b", TaskID: "0x01", TaskType: int(message.ProofTypeBatch), TaskData: "{}",
+		}},
+		"NoArtifacts": {Data: &GetTaskData{
+			TaskID: "0x01", Resources: &message.TaskResources{ExpectedMemoryMB: 1},
+		}},
+		"EmptyMessages":  {Data: &GetTaskData{Resources: &message.TaskResources{Artifacts: &message.CircuitArtifacts{}}}},
+		"NegativeValues": {ErrCode: -1, Data: &GetTaskData{TaskType: -2, ServerTime: -3, DeadlineSec: -4}},
+	}
+	for name, resp := range getTaskResponses {
+		t.Run("GetTaskResponse"+name, func(t *testing.T) {
+			expected := getTaskResponseMessage(t, file, resp)
+
+			decoded := newProtoMessage(file, "GetTaskResponse")
+			assert.NoError(t, proto.Unmarshal(resp.Marshal(), decoded))
+			assert.True(t, proto.Equal(expected, decoded), "expected %v, got %v", expected, decoded)
+
+			b, err := proto.Marshal(expected)
+			assert.NoError(t, err)
+			var got GetTaskResponse
+			assert.NoError(t, got.Unmarshal(b))
+			assert.Equal(t, resp, &got)
+		})
+	}
+
+	fullRequest := testSubmitProofRequest(1)
+	fullRequest.Status = int(message.StatusProofError)
+	fullRequest.FailureType = int(types.ProverTaskFailureTypeTimeout)
+	fullRequest.FailureMsg = "timeout"
+	submitProofRequests := map[string]*SubmitProofRequest{
+		"Full":           fullRequest,
+		"Empty":          {},
+		"NegativeValues": {TaskType: -1, Status: -2, FailureType: -3},
+	}
+	for name, req := range submitProofRequests {
+		t.Run("SubmitProofRequest"+name, func(t *testing.T) {
+			expected := submitProofRequestMessage(t, file, req)
+
+			decoded := newProtoMessage(file, "SubmitProofRequest")
+			assert.NoError(t, proto.Unmarshal(req.Marshal(), decoded))
+			assert.True(t, proto.Equal(expected, decoded), "expected %v, got %v", expected, decoded)
+
+			b, err := proto.Marshal(expected)
+			assert.NoError(t, err)
+			var got SubmitProofRequest
+			assert.NoError(t, got.Unmarshal(b))
+			assert.Equal(t, req, &got)
+		})
+	}
+}
+
+var (
+	protoSyntaxRe  = regexp.MustCompile(`^syntax\s*=\s*"(\w+)";$`)
+	protoPackageRe = regexp.MustCompile(`^package\s+(\w+);$`)
+	protoMessageRe = regexp.MustCompile(`^message\s+(\w+)\s*\{$`)
+	protoFieldRe   = regexp.MustCompile(`^(\w+)\s+(\w+)\s*=\s*(\d+);$`)
+)
+
+// loadProtoFile builds the descriptor of coordinator.proto, it only understands the subset of the
+// language used by the file and fails on anything else so the test can't silently skip a field.
+func loadProtoFile(t *testing.T) protoreflect.FileDescriptor {
+	content, err := os.ReadFile("coordinator.proto")
+	assert.NoError(t, err)
+
+	scalarTypes := map[string]descriptorpb.FieldDescriptorProto_Type{
+		"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		"uint64": descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	}
+	fd := &descriptorpb.FileDescriptorProto{Name: proto.String("coordinator.proto")}
+	var msg *descriptorpb.DescriptorProto
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if m := protoSyntaxRe.FindStringSubmatch(line); m != nil && msg == nil {
+			fd.Syntax = proto.String(m[1])
+		} else if m := protoPackageRe.FindStringSubmatch(line); m != nil && msg == nil {
+			fd.Package = proto.String(m[1])
+		} else if m := protoMessageRe.FindStringSubmatch(line); m != nil && msg == nil {
+			msg = &descriptorpb.DescriptorProto{Name: proto.String(m[1])}
+			fd.MessageType = append(fd.MessageType, msg)
+		} else if m := protoFieldRe.FindStringSubmatch(line); m != nil && msg != nil {
+			number, err := strconv.ParseInt(m[3], 10, 32)
+			assert.NoError(t, err)
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:   proto.String(m[2]),
+				Number: proto.Int32(int32(number)),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			}
+			if typ, ok := scalarTypes[m[1]]; ok {
+				field.Type = typ.Enum()
+			} else {
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+				field.TypeName = proto.String("." + fd.GetPackage() + "." + m[1])
+			}
+			msg.Field = append(msg.Field, field)
+		} else if line == "}" && msg != nil {
+			msg = nil
+		} else {
+			t.Fatalf("unsupported line %d of coordinator.proto: %q", i+1, line)
+		}
+	}
+
+	file, err := protodesc.NewFile(fd, nil)
+	assert.NoError(t, err)
+	return file
+}
+
+func newProtoMessage(file protoreflect.FileDescriptor, name protoreflect.Name) *dynamicpb.Message {
+	return dynamicpb.NewMessage(file.Messages().ByName(name))
+}
+
+// setProtoFields sets the fields of the message by name, every field of the message must be given
+// so a field added to coordinator.proto fails the test until the encoding is updated.
+func setProtoFields(t *testing.T, m *dynamicpb.Message, values map[protoreflect.Name]interface{}) {
+	fields := m.Descriptor().Fields()
+	assert.Equal(t, fields.Len(), len(values), "fields of %s", m.Descriptor().Name())
+	for name, value := range values {
+		field := fields.ByName(name)
+		if !assert.NotNil(t, field, "field %s of %s", name, m.Descriptor().Name()) {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			m.Set(field, protoreflect.ValueOfString(v))
+		case int64:
+			m.Set(field, protoreflect.ValueOfInt64(v))
+		case uint64:
+			m.Set(field, protoreflect.ValueOfUint64(v))
+		case *dynamicpb.Message:
+			if v != nil {
+				m.Set(field, protoreflect.ValueOfMessage(v))
+			}
+		default:
+			t.Fatalf("unsupported value %T of field %s", value, name)
+		}
+	}
+}
+
+func getTaskResponseMessage(t *testing.T, file protoreflect.FileDescriptor, resp *GetTaskResponse) *dynamicpb.Message {
+	var data *dynamicpb.Message
+	if resp.Data != nil {
+		var resources *dynamicpb.Message
+		if resp.Data.Resources != nil {
+			var artifacts *dynamicpb.Message
+			if a := resp.Data.Resources.Artifacts; a != nil {
+				artifacts = newProtoMessage(file, "CircuitArtifacts")
+				setProtoFields(t, artifacts, map[protoreflect.Name]interface{}{
+					"version":  a.Version,
+					"url":      a.URL,
+					"checksum": a.Checksum,
+				})
+			}
+			resources = newProtoMessage(file, "TaskResources")
+			setProtoFields(t, resources, map[protoreflect.Name]interface{}{
+				"expected_memory_mb":     resp.Data.Resources.ExpectedMemoryMB,
+				"estimated_duration_sec": resp.Data.Resources.EstimatedDurationSec,
+				"artifacts":              artifacts,
+			})
+		}
+		data = newProtoMessage(file, "GetTaskData")
+		setProtoFields(t, data, map[protoreflect.Name]interface{}{
+			"uuid":         resp.Data.UUID,
+			"task_id":      resp.Data.TaskID,
+			"task_type":    int64(resp.Data.TaskType),
+			"task_data":    resp.Data.TaskData,
+			"resources":    resources,
+			"server_time":  resp.Data.ServerTime,
+			"deadline_sec": resp.Data.DeadlineSec,
+		})
+	}
+	m := newProtoMessage(file, "GetTaskResponse")
+	setProtoFields(t, m, map[protoreflect.Name]interface{}{
+		"errcode": int64(resp.ErrCode),
+		"errmsg":  resp.ErrMsg,
+		"data":    data,
+	})
+	return m
+}
+
+func submitProofRequestMessage(t *testing.T, file protoreflect.FileDescriptor, req *SubmitProofRequest) *dynamicpb.Message {
+	m := newProtoMessage(file, "SubmitProofRequest")
+	setProtoFields(t, m, map[protoreflect.Name]interface{}{
+		"uuid":         req.UUID,
+		"task_id":      req.TaskID,
+		"task_type":    int64(req.TaskType),
+		"status":       int64(req.Status),
+		"proof":        req.Proof,
+		"failure_type": int64(req.FailureType),
+		"failure_msg":  req.FailureMsg,
+	})
+	return m
+}
+
+// testGetTaskResponse mimics the task data, a large json document with many strings to escape.
+func testGetTaskResponse(txNum int) *GetTaskResponse {
+	tx := `{"type":2,"nonce":1,"txHash":"0x6b2e1cbfd2e6a4a9a0b3c3f3a0a2d7b0e5f4c3b2a1908f7e6d5c4b3a2918f7e6","gas":21000,"data":"0x","to":"0x0000000000000000000000000000000000000001"}`
+	taskData := `{"block_hashes":[],"txs":[` + strings.TrimSuffix(strings.Repeat(tx+",", txNum), ",") + `]}`
+	return &GetTaskResponse{
+		Data: &GetTaskData{
+			UUID:     "8c5b5f96-8e0e-4f3e-9b5a-5d6d7f8e9a0b",
+			TaskID:   "0x6b2e1cbfd2e6a4a9a0b3c3f3a0a2d7b0e5f4c3b2a1908f7e6d5c4b3a2918f7e6",
+			TaskType: int(message.ProofTypeChunk),
+			TaskData: taskData,
+			Resources: &message.TaskResources{
+				ExpectedMemoryMB:     1024,
+				EstimatedDurationSec: 600,
+				Artifacts:            &message.CircuitArtifacts{Version: "v0.10.3", URL: "https://artifacts", Checksum: "0xabcd"},
+			},
+			ServerTime:  1700000000000,
+			DeadlineSec: 300,
+		},
+	}
+}
+
+func testSubmitProofRequest(size int) *SubmitProofRequest {
+	proof := `{"proof":"` + strings.Repeat("aGVsbG8gcHJvb2Y=", size) + `","instances":"AAEC","vk":"AwQF"}`
+	return &SubmitProofRequest{
+		UUID:     "8c5b5f96-8e0e-4f3e-9b5a-5d6d7f8e9a0b",
+		TaskID:   "0x6b2e1cbfd2e6a4a9a0b3c3f3a0a2d7b0e5f4c3b2a1908f7e6d5c4b3a2918f7e6",
+		TaskType: int(message.ProofTypeChunk),
+		Status:   int(message.StatusOk),
+		Proof:    proof,
+	}
+}
+
+// getTaskResponseJSON is the json encoding of the get task response used by the coordinator.
+type getTaskResponseJSON struct {
+	ErrCode int              `json:"errcode"`
+	ErrMsg  string           `json:"errmsg"`
+	Data    *getTaskDataJSON `json:"data"`
+}
+
+type getTaskDataJSON struct {
+	UUID        string                 `json:"uuid"`
+	TaskID      string                 `json:"task_id"`
+	TaskType    int                    `json:"task_type"`
+	TaskData    string                 `json:"task_data"`
+	Resources   *message.TaskResources `json:"resources,omitempty"`
+	ServerTime  int64                  `json:"server_time,omitempty"`
+	DeadlineSec int64                  `json:"deadline_sec,omitempty"`
+}
+
+func BenchmarkGetTaskResponseJSON(b *testing.B) {
+	resp := testGetTaskResponse(10000)
+	jsonResp := getTaskResponseJSON{ErrCode: resp.ErrCode, ErrMsg: resp.ErrMsg}
+	jsonResp.Data = (*getTaskDataJSON)(resp.Data)
+	b.SetBytes(int64(len(resp.Data.TaskData)))
+	b.ResetTimer()
+	var decoded getTaskResponseJSON
+	for i := 0; i < b.N; i++ {
+		encoded, _ := json.Marshal(&jsonResp)
+		_ = json.Unmarshal(encoded, &decoded)
+	}
+}
+
+func BenchmarkGetTaskResponseProtobuf(b *testing.B) {
+	resp := testGetTaskResponse(10000)
+	var decoded GetTaskResponse
+	b.SetBytes(int64(len(resp.Data.TaskData)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = decoded.Unmarshal(resp.Marshal())
+	}
+}
+
+func BenchmarkSubmitProofRequestJSON(b *testing.B) {
+	req := testSubmitProofRequest(10000)
+	var decoded SubmitProofRequest
+	b.SetBytes(int64(len(req.Proof)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoded, _ := json.Marshal(req)
+		_ = json.Unmarshal(encoded, &decoded)
+	}
+}
+
+func BenchmarkSubmitProofRequestProtobuf(b *testing.B) {
+	req := testSubmitProofRequest(10000)
+	var decoded SubmitProofRequest
+	b.SetBytes(int64(len(req.Proof)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = decoded.Unmarshal(req.Marshal())
+	}
+}
@@ -2,6 +2,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
@@ -80,6 +81,8 @@ const (
 	ProverProofValid
 	// ProverProofInvalid indicates prover has submitted invalid proof
 	ProverProofInvalid
+	// ProverTaskDeclined indicates prover has declined the task, it isn't treated as a failure
+	ProverTaskDeclined
 )
 
 func (s ProverProveStatus) String() string {
@@ -90,6 +93,8 @@ func (s ProverProveStatus) String() string {
 		return "ProverProofValid"
 	case ProverProofInvalid:
 		return "ProverProofInvalid"
+	case ProverTaskDeclined:
+		return "ProverTaskDeclined"
 	default:
 		return fmt.Sprintf("Bad Value: %d", int32(s))
 	}
@@ -109,6 +114,8 @@ const (
 	ProverTaskFailureTypeVerifiedFailed
 	// ProverTaskFailureTypeServerError collect occur error
 	ProverTaskFailureTypeServerError
+	// ProverTaskFailureTypeExpired prover task failure of being stuck in assigned status beyond the max age
+	ProverTaskFailureTypeExpired
 )
 
 func (r ProverTaskFailureType) String() string {
@@ -123,6 +130,8 @@ func (r ProverTaskFailureType) String() string {
 		return "prover task failure verified failed"
 	case ProverTaskFailureTypeServerError:
 		return "prover task failure server exception"
+	case ProverTaskFailureTypeExpired:
+		return "prover task failure expired"
 	default:
 		return fmt.Sprintf("illegal prover task failure type (%d)", int32(r))
 	}
@@ -163,6 +172,66 @@ func (ps ProvingStatus) String() string {
 	}
 }
 
+// provingStatusTransitions lists the statuses a proving task may move to from each status.
+var provingStatusTransitions = map[ProvingStatus][]ProvingStatus{
+	ProvingTaskUnassigned:       {ProvingTaskAssigned, ProvingTaskVerified, ProvingTaskFailed},
+	ProvingTaskAssigned:         {ProvingTaskUnassigned, ProvingTaskVerified, ProvingTaskFailed},
+	ProvingTaskProvedDEPRECATED: {ProvingTaskVerified, ProvingTaskFailed},
+	ProvingTaskFailed:           {ProvingTaskUnassigned, ProvingTaskAssigned},
+	ProvingTaskVerified:         {},
+}
+
+// IsValid reports whether ps is a defined proving status.
+func (ps ProvingStatus) IsValid() bool {
+	_, ok := provingStatusTransitions[ps]
+	return ok
+}
+
+// CanTransitionTo reports whether a proving task in status ps may be updated to next,
+// keeping the same status is always allowed.
+func (ps ProvingStatus) CanTransitionTo(next ProvingStatus) bool {
+	if !ps.IsValid() || !next.IsValid() {
+		return false
+	}
+	if ps == next {
+		return true
+	}
+	for _, status := range provingStatusTransitions[ps] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON encodes the proving status as its name.
+func (ps ProvingStatus) MarshalJSON() ([]byte, error) {
+	if !ps.IsValid() {
+		return nil, fmt.Errorf("invalid proving status %d", int32(ps))
+	}
+	return json.Marshal(ps.String())
+}
+
+// UnmarshalJSON decodes the proving status from its name or its integer value.
+func (ps *ProvingStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var value int
+		if err = json.Unmarshal(data, &value); err != nil || !ProvingStatus(value).IsValid() {
+			return fmt.Errorf("invalid proving status %s", data)
+		}
+		*ps = ProvingStatus(value)
+		return nil
+	}
+	for status := range provingStatusTransitions {
+		if status.String() == name {
+			*ps = status
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid proving status %s", data)
+}
+
 // ChunkProofsStatus describes the proving status of chunks that belong to a batch.
 type ChunkProofsStatus int
 
@@ -188,6 +257,50 @@ func (s ChunkProofsStatus) String() string {
 	}
 }
 
+// ChunkProposalStatus describes whether a block can be put into a chunk by the chunk proposer.
+type ChunkProposalStatus int
+
+const (
+	// ChunkProposalStatusUndefined means that the block has not been marked by the chunk proposer
+	ChunkProposalStatusUndefined ChunkProposalStatus = iota
+
+	// ChunkProposalStatusQuarantined means that the block failed too many times and is proposed in a single-block chunk
+	ChunkProposalStatusQuarantined
+)
+
+func (s ChunkProposalStatus) String() string {
+	switch s {
+	case ChunkProposalStatusUndefined:
+		return "ChunkProposalStatusUndefined"
+	case ChunkProposalStatusQuarantined:
+		return "ChunkProposalStatusQuarantined"
+	default:
+		return fmt.Sprintf("Undefined ChunkProposalStatus (%d)", int32(s))
+	}
+}
+
+// TaskLane describes the priority lane of a chunk or batch proving task
+type TaskLane int
+
+const (
+	// TaskLaneNormal is the default lane of the proving tasks
+	TaskLaneNormal TaskLane = iota
+
+	// TaskLaneHigh is the lane of the proving tasks which are requeued after a failure, they are assigned first
+	TaskLaneHigh
+)
+
+func (l TaskLane) String() string {
+	switch l {
+	case TaskLaneNormal:
+		return "normal"
+	case TaskLaneHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("Undefined TaskLane (%d)", int32(l))
+	}
+}
+
 // RollupStatus block_batch rollup_status (pending, committing, committed, commit_failed, finalizing, finalized, finalize_skipped, finalize_failed)
 type RollupStatus int
 
@@ -231,6 +344,69 @@ func (s RollupStatus) String() string {
 	}
 }
 
+// rollupStatusTransitions lists the statuses a batch may move to from each rollup status.
+// Commit and finalize events observed on layer 1 may skip the intermediate statuses.
+var rollupStatusTransitions = map[RollupStatus][]RollupStatus{
+	RollupPending:        {RollupCommitting, RollupCommitted, RollupFinalized},
+	RollupCommitting:     {RollupCommitted, RollupCommitFailed, RollupFinalized},
+	RollupCommitFailed:   {RollupCommitting, RollupCommitted},
+	RollupCommitted:      {RollupFinalizing, RollupFinalized},
+	RollupFinalizing:     {RollupFinalized, RollupFinalizeFailed},
+	RollupFinalizeFailed: {RollupFinalizing, RollupFinalized},
+	RollupFinalized:      {},
+}
+
+// IsValid reports whether s is a defined rollup status.
+func (s RollupStatus) IsValid() bool {
+	_, ok := rollupStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a batch in rollup status s may be updated to next,
+// keeping the same status is always allowed.
+func (s RollupStatus) CanTransitionTo(next RollupStatus) bool {
+	if !s.IsValid() || !next.IsValid() {
+		return false
+	}
+	if s == next {
+		return true
+	}
+	for _, status := range rollupStatusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON encodes the rollup status as its name.
+func (s RollupStatus) MarshalJSON() ([]byte, error) {
+	if !s.IsValid() {
+		return nil, fmt.Errorf("invalid rollup status %d", int32(s))
+	}
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes the rollup status from its name or its integer value.
+func (s *RollupStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var value int
+		if err = json.Unmarshal(data, &value); err != nil || !RollupStatus(value).IsValid() {
+			return fmt.Errorf("invalid rollup status %s", data)
+		}
+		*s = RollupStatus(value)
+		return nil
+	}
+	for status := range rollupStatusTransitions {
+		if status.String() == name {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid rollup status %s", data)
+}
+
 // SenderType defines the various types of senders sending the transactions.
 type SenderType int
 
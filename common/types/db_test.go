@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,6 +28,11 @@ func TestProverProveStatus(t *testing.T) {
 			ProverProofInvalid,
 			"ProverProofInvalid",
 		},
+		{
+			"ProverTaskDeclined",
+			ProverTaskDeclined,
+			"ProverTaskDeclined",
+		},
 		{
 			"Bad Value",
 			ProverProveStatus(999), // Invalid value.
@@ -312,6 +318,11 @@ func TestProverTaskFailureType(t *testing.T) {
 			ProverTaskFailureTypeServerError,
 			"prover task failure server exception",
 		},
+		{
+			"ProverTaskFailureTypeExpired",
+			ProverTaskFailureTypeExpired,
+			"prover task failure expired",
+		},
 		{
 			"Invalid Value",
 			ProverTaskFailureType(999),
@@ -325,3 +336,115 @@ func TestProverTaskFailureType(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskLane(t *testing.T) {
+	tests := []struct {
+		name string
+		l    TaskLane
+		want string
+	}{
+		{
+			"TaskLaneNormal",
+			TaskLaneNormal,
+			"normal",
+		},
+		{
+			"TaskLaneHigh",
+			TaskLaneHigh,
+			"high",
+		},
+		{
+			"Invalid Value",
+			TaskLane(999),
+			"Undefined TaskLane (999)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.l.String())
+		})
+	}
+}
+
+func TestChunkProposalStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		s    ChunkProposalStatus
+		want string
+	}{
+		{
+			"ChunkProposalStatusUndefined",
+			ChunkProposalStatusUndefined,
+			"ChunkProposalStatusUndefined",
+		},
+		{
+			"ChunkProposalStatusQuarantined",
+			ChunkProposalStatusQuarantined,
+			"ChunkProposalStatusQuarantined",
+		},
+		{
+			"Invalid Value",
+			ChunkProposalStatus(999),
+			"Undefined ChunkProposalStatus (999)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.s.String())
+		})
+	}
+}
+
+func TestRollupStatusTransition(t *testing.T) {
+	assert.True(t, RollupPending.CanTransitionTo(RollupCommitting))
+	assert.True(t, RollupCommitting.CanTransitionTo(RollupCommitted))
+	assert.True(t, RollupCommitFailed.CanTransitionTo(RollupCommitting))
+	assert.True(t, RollupCommitted.CanTransitionTo(RollupFinalizing))
+	assert.True(t, RollupFinalizing.CanTransitionTo(RollupFinalizeFailed))
+	assert.True(t, RollupFinalized.CanTransitionTo(RollupFinalized))
+
+	assert.False(t, RollupFinalized.CanTransitionTo(RollupPending))
+	assert.False(t, RollupCommitted.CanTransitionTo(RollupCommitting))
+	assert.False(t, RollupPending.CanTransitionTo(RollupFinalizing))
+	assert.False(t, RollupUndefined.CanTransitionTo(RollupPending))
+	assert.False(t, RollupPending.CanTransitionTo(RollupStatus(999)))
+}
+
+func TestProvingStatusTransition(t *testing.T) {
+	assert.True(t, ProvingTaskUnassigned.CanTransitionTo(ProvingTaskAssigned))
+	assert.True(t, ProvingTaskAssigned.CanTransitionTo(ProvingTaskUnassigned))
+	assert.True(t, ProvingTaskAssigned.CanTransitionTo(ProvingTaskVerified))
+	assert.True(t, ProvingTaskFailed.CanTransitionTo(ProvingTaskUnassigned))
+
+	assert.False(t, ProvingTaskVerified.CanTransitionTo(ProvingTaskUnassigned))
+	assert.False(t, ProvingStatusUndefined.CanTransitionTo(ProvingTaskAssigned))
+	assert.False(t, ProvingTaskAssigned.CanTransitionTo(ProvingStatus(999)))
+}
+
+func TestStatusJSON(t *testing.T) {
+	data, err := json.Marshal(RollupCommitted)
+	assert.NoError(t, err)
+	assert.Equal(t, `"RollupCommitted"`, string(data))
+
+	data, err = json.Marshal(ProvingTaskVerified)
+	assert.NoError(t, err)
+	assert.Equal(t, `"verified"`, string(data))
+
+	_, err = json.Marshal(RollupUndefined)
+	assert.Error(t, err)
+
+	var rollupStatus RollupStatus
+	assert.NoError(t, json.Unmarshal([]byte(`"RollupFinalized"`), &rollupStatus))
+	assert.Equal(t, RollupFinalized, rollupStatus)
+	assert.NoError(t, json.Unmarshal([]byte(`3`), &rollupStatus))
+	assert.Equal(t, RollupCommitted, rollupStatus)
+	assert.Error(t, json.Unmarshal([]byte(`"finalized"`), &rollupStatus))
+	assert.Error(t, json.Unmarshal([]byte(`0`), &rollupStatus))
+
+	var provingStatus ProvingStatus
+	assert.NoError(t, json.Unmarshal([]byte(`"assigned"`), &provingStatus))
+	assert.Equal(t, ProvingTaskAssigned, provingStatus)
+	assert.Error(t, json.Unmarshal([]byte(`99`), &provingStatus))
+}
@@ -26,4 +26,41 @@ const (
 	ErrCoordinatorHandleZkProofFailure = 20003
 	// ErrCoordinatorEmptyProofData get empty proof data
 	ErrCoordinatorEmptyProofData = 20004
+	// ErrCoordinatorDrainMode coordinator is in drain mode and assigns no new task
+	ErrCoordinatorDrainMode = 20005
+	// ErrCoordinatorAdminUnauthorized admin api request is unauthorized
+	ErrCoordinatorAdminUnauthorized = 20006
+	// ErrCoordinatorArtifactNotFound the requested circuit artifact is not found
+	ErrCoordinatorArtifactNotFound = 20007
+	// ErrCoordinatorDeclineTaskFailure is handle decline task error
+	ErrCoordinatorDeclineTaskFailure = 20008
+	// ErrCoordinatorReportProgressFailure is handle report task progress error
+	ErrCoordinatorReportProgressFailure = 20009
+	// ErrCoordinatorProverCoolingDown the prover recently declined the task type and is assigned no task of it
+	ErrCoordinatorProverCoolingDown = 20010
+
+	// ErrRollupAPIParameterInvalidNo is invalid params
+	ErrRollupAPIParameterInvalidNo = 30001
+	// ErrRollupAPIChunkWhatIfFailure is estimating chunk what-if error
+	ErrRollupAPIChunkWhatIfFailure = 30002
+	// ErrRollupAPIEstimatorErrorFailure is querying estimator error stats error
+	ErrRollupAPIEstimatorErrorFailure = 30003
+	// ErrRollupAPIUnauthorized rollup api request is unauthorized
+	ErrRollupAPIUnauthorized = 30004
+	// ErrRollupAPIChunkPreviewFailure is previewing the open chunk error
+	ErrRollupAPIChunkPreviewFailure = 30005
+	// ErrRollupAPIBatchCommitDataFailure is fetching the committed batch data error
+	ErrRollupAPIBatchCommitDataFailure = 30006
+	// ErrRollupAPIOperationPauseFailure is reading or setting the operation pause switches error
+	ErrRollupAPIOperationPauseFailure = 30007
+	// ErrRollupAPIBatchStateFailure is querying the batch state at a layer 1 block error
+	ErrRollupAPIBatchStateFailure = 30008
+	// ErrRollupAPIKeyFailure is issuing, reading or updating the api keys error
+	ErrRollupAPIKeyFailure = 30009
+	// ErrRollupAPIQuotaExceeded the daily quota of the api key is exceeded
+	ErrRollupAPIQuotaExceeded = 30010
+	// ErrRollupAPICommitDiagnosisFailure is querying the diagnoses of the reverted commit txs error
+	ErrRollupAPICommitDiagnosisFailure = 30011
+	// ErrRollupAPIBatchTimelineFailure is querying the lifecycle timeline of the batches error
+	ErrRollupAPIBatchTimelineFailure = 30012
 )
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+// maxPooledHexBufferSize caps the buffers kept in the pool, so a single large
+// transaction does not pin its buffer in memory for the lifetime of the process.
+const maxPooledHexBufferSize = 128 * 1024
+
+var hexDecoderPool = sync.Pool{
+	New: func() interface{} { return new(HexDecoder) },
+}
+
+// HexDecoder validates and decodes 0x-prefixed hex strings, such as the Data field of
+// TransactionData, into a reusable buffer. Unlike hexutil.Decode it does not allocate
+// once its buffer has grown to the decoded size. A HexDecoder is not safe for concurrent
+// use, GetHexDecoder returns one from a pool.
+type HexDecoder struct {
+	buf []byte
+}
+
+// GetHexDecoder returns a decoder from the pool, Release puts it back.
+func GetHexDecoder() *HexDecoder {
+	return hexDecoderPool.Get().(*HexDecoder)
+}
+
+// Release puts the decoder back into the pool, the bytes it returned must no longer be used.
+func (d *HexDecoder) Release() {
+	if cap(d.buf) > maxPooledHexBufferSize {
+		d.buf = nil
+	}
+	hexDecoderPool.Put(d)
+}
+
+// Decode decodes the input with the validation rules and errors of hexutil.Decode. The returned
+// bytes are only valid until the next call of Decode or Release.
+func (d *HexDecoder) Decode(input string) ([]byte, error) {
+	if len(input) == 0 {
+		return nil, hexutil.ErrEmptyString
+	}
+	if len(input) < 2 || input[0] != '0' || (input[1] != 'x' && input[1] != 'X') {
+		return nil, hexutil.ErrMissingPrefix
+	}
+	input = input[2:]
+
+	// an odd length input is decoded as if it had a leading zero, like hexutil.Decode does
+	n := (len(input) + 1) / 2
+	if d.buf == nil || cap(d.buf) < n {
+		d.buf = make([]byte, n)
+	}
+	d.buf = d.buf[:n]
+	dst := d.buf
+	if len(input)%2 == 1 {
+		lo := hexValues[input[0]]
+		if lo == invalidHexValue {
+			return nil, hexutil.ErrSyntax
+		}
+		dst[0] = lo
+		dst, input = dst[1:], input[1:]
+	}
+	for i := range dst {
+		hi, lo := hexValues[input[2*i]], hexValues[input[2*i+1]]
+		if hi == invalidHexValue || lo == invalidHexValue {
+			return nil, hexutil.ErrSyntax
+		}
+		dst[i] = hi<<4 | lo
+	}
+	return d.buf, nil
+}
+
+// hexValues maps the hex characters to their values and the other bytes to invalidHexValue.
+var hexValues = func() (table [256]byte) {
+	for i := range table {
+		table[i] = invalidHexValue
+	}
+	for c := byte('0'); c <= '9'; c++ {
+		table[c] = c - '0'
+	}
+	for c := byte('a'); c <= 'f'; c++ {
+		table[c] = c - 'a' + 10
+		table[c-'a'+'A'] = c - 'a' + 10
+	}
+	return table
+}()
+
+const invalidHexValue = 0xff
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexDecoder(t *testing.T) {
+	decoder := GetHexDecoder()
+	defer decoder.Release()
+
+	inputs := []string{"", "0", "0x", "0X", "0x00", "0xdeadBEEF", "deadbeef", "0xabc", "0xab0g", "0xg", "0xgab", "0x" + strings.Repeat("01", 1024)}
+	for _, input := range inputs {
+		expected, expectedErr := hexutil.Decode(input)
+		decoded, err := decoder.Decode(input)
+		assert.Equal(t, expectedErr, err, input)
+		if expectedErr == nil {
+			assert.Equal(t, expected, decoded, input)
+		}
+	}
+
+	// the buffer is reused once it has grown
+	_, err := decoder.Decode("0x" + strings.Repeat("ff", 64))
+	assert.NoError(t, err)
+	input := "0x" + strings.Repeat("ff", 32)
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = decoder.Decode(input)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func benchmarkTxData(b *testing.B) string {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_03.json")
+	assert.NoError(b, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(b, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	var data string
+	for _, txData := range wrappedBlock.Transactions {
+		if len(txData.Data) > len(data) {
+			data = txData.Data
+		}
+	}
+	return data
+}
+
+func BenchmarkHexutilDecode(b *testing.B) {
+	data := benchmarkTxData(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = hexutil.Decode(data)
+	}
+}
+
+func BenchmarkHexDecoder(b *testing.B) {
+	data := benchmarkTxData(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decoder := GetHexDecoder()
+		_, _ = decoder.Decode(data)
+		decoder.Release()
+	}
+}
+
+// BenchmarkEstimateL1CommitCalldataSize measures the estimation hot path without the payload length cache.
+func BenchmarkEstimateL1CommitCalldataSize(b *testing.B) {
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_03.json")
+	assert.NoError(b, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(b, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wrappedBlock.txPayloadLengthCache = nil
+		_, _ = wrappedBlock.EstimateL1CommitCalldataSize()
+	}
+}
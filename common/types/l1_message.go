@@ -0,0 +1,66 @@
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// L1Message is a message appended to the L1MessageQueue contract, it is included on layer 2 as an L1MessageTx.
+type L1Message struct {
+	QueueIndex uint64         `json:"queue_index"`
+	Gas        uint64         `json:"gas"`
+	To         common.Address `json:"to"`
+	Value      *big.Int       `json:"value"`
+	Data       []byte         `json:"data"`
+	Sender     common.Address `json:"sender"`
+}
+
+// NewL1MessageFromTx returns the L1Message of an L1MessageTx transaction.
+func NewL1MessageFromTx(tx *types.Transaction) (*L1Message, error) {
+	msg := tx.AsL1MessageTx()
+	if msg == nil {
+		return nil, errors.New("not an L1MessageTx")
+	}
+	if msg.To == nil {
+		return nil, errors.New("L1MessageTx without target")
+	}
+	return &L1Message{
+		QueueIndex: msg.QueueIndex,
+		Gas:        msg.Gas,
+		To:         *msg.To,
+		Value:      msg.Value,
+		Data:       msg.Data,
+		Sender:     msg.Sender,
+	}, nil
+}
+
+// L1MessageTx converts the message into the L1MessageTx executed on layer 2.
+func (m *L1Message) L1MessageTx() *types.L1MessageTx {
+	to := m.To
+	value := m.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	return &types.L1MessageTx{
+		QueueIndex: m.QueueIndex,
+		Gas:        m.Gas,
+		To:         &to,
+		Value:      value,
+		Data:       m.Data,
+		Sender:     m.Sender,
+	}
+}
+
+// Encode returns the EIP-2718 encoding of the message, 0x7E || rlp([queueIndex, gasLimit, to, value, data, sender]).
+func (m *L1Message) Encode() ([]byte, error) {
+	return types.NewTx(m.L1MessageTx()).MarshalBinary()
+}
+
+// Hash returns the hash of the message, which equals the hash computed by
+// L1MessageQueue.computeTransactionHash and the hash of the L1MessageTx.
+func (m *L1Message) Hash() common.Hash {
+	return types.NewTx(m.L1MessageTx()).Hash()
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestL1MessageHash(t *testing.T) {
+	sender := common.HexToAddress("0xb2a70fab1a45b1b9be443b6567849a1702bc1232")
+	target := common.HexToAddress("0xcb18150e4efefb6786130e289a5f61a82a5b86d7")
+
+	for _, msg := range []*L1Message{
+		{QueueIndex: 0, Gas: 0, To: target, Value: big.NewInt(0), Data: nil, Sender: sender},
+		{QueueIndex: 127, Gas: 128, To: target, Value: big.NewInt(22334455), Data: []byte{0x80}, Sender: sender},
+		{QueueIndex: 22334455, Gas: 1000000, To: target, Value: new(big.Int).Lsh(big.NewInt(1), 255), Data: make([]byte, 100), Sender: sender},
+	} {
+		// the encoding of L1MessageQueue.computeTransactionHash
+		payload, err := rlp.EncodeToBytes([]interface{}{msg.QueueIndex, msg.Gas, msg.To, msg.Value, msg.Data, msg.Sender})
+		assert.NoError(t, err)
+		expected := append([]byte{types.L1MessageTxType}, payload...)
+
+		encoded, err := msg.Encode()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, encoded)
+		assert.Equal(t, crypto.Keccak256Hash(expected), msg.Hash())
+	}
+}
+
+func TestL1MessageFromTx(t *testing.T) {
+	msg := &L1Message{
+		QueueIndex: 10,
+		Gas:        200000,
+		To:         common.HexToAddress("0xcb18150e4efefb6786130e289a5f61a82a5b86d7"),
+		Value:      big.NewInt(1),
+		Data:       []byte{0x01, 0x02},
+		Sender:     common.HexToAddress("0xb2a70fab1a45b1b9be443b6567849a1702bc1232"),
+	}
+	tx := types.NewTx(msg.L1MessageTx())
+	assert.Equal(t, tx.Hash(), msg.Hash())
+
+	decoded, err := NewL1MessageFromTx(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decoded)
+
+	_, err = NewL1MessageFromTx(types.NewTx(&types.LegacyTx{}))
+	assert.Error(t, err)
+}
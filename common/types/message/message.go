@@ -25,6 +25,55 @@ const (
 	ProofFailureNoPanic
 )
 
+// TaskDeclineReason the machine-readable reason of a prover declining a task
+type TaskDeclineReason int
+
+const (
+	// TaskDeclineReasonUndefined the undefined decline reason
+	TaskDeclineReasonUndefined TaskDeclineReason = iota
+	// TaskDeclineReasonUnsupportedCircuit the prover doesn't support the circuit of the task
+	TaskDeclineReasonUnsupportedCircuit
+	// TaskDeclineReasonInsufficientMemory the prover doesn't have enough memory to prove the task
+	TaskDeclineReasonInsufficientMemory
+	// TaskDeclineReasonArtifactMissing the prover doesn't have the circuit artifacts of the task
+	TaskDeclineReasonArtifactMissing
+)
+
+func (r TaskDeclineReason) String() string {
+	switch r {
+	case TaskDeclineReasonUnsupportedCircuit:
+		return "unsupported circuit"
+	case TaskDeclineReasonInsufficientMemory:
+		return "insufficient memory"
+	case TaskDeclineReasonArtifactMissing:
+		return "artifact missing"
+	default:
+		return fmt.Sprintf("undefined decline reason: %d", r)
+	}
+}
+
+// TaskProgressStage the stage of the task being proved, reported by the prover along with its progress
+type TaskProgressStage string
+
+const (
+	// TaskProgressStageFetchingWitness the prover fetches and reduces the witness of the task
+	TaskProgressStageFetchingWitness TaskProgressStage = "fetching_witness"
+	// TaskProgressStageProving the prover generates the proof
+	TaskProgressStageProving TaskProgressStage = "proving"
+	// TaskProgressStageSubmitting the prover submits the proof
+	TaskProgressStageSubmitting TaskProgressStage = "submitting"
+)
+
+// IsValid returns whether the stage is a known progress stage
+func (s TaskProgressStage) IsValid() bool {
+	switch s {
+	case TaskProgressStageFetchingWitness, TaskProgressStageProving, TaskProgressStageSubmitting:
+		return true
+	default:
+		return false
+	}
+}
+
 // RespStatus represents status code from prover to scroll
 type RespStatus uint32
 
@@ -216,6 +265,22 @@ type TaskMsg struct {
 	Type            ProofType        `json:"type,omitempty"`
 	BatchTaskDetail *BatchTaskDetail `json:"batch_task_detail,omitempty"`
 	ChunkTaskDetail *ChunkTaskDetail `json:"chunk_task_detail,omitempty"`
+	Resources       *TaskResources   `json:"resources,omitempty"`
+}
+
+// TaskResources describes the resources needed to prove a task, so that prover orchestration can schedule it.
+type TaskResources struct {
+	ExpectedMemoryMB     uint64            `json:"expected_memory_mb,omitempty"`
+	EstimatedDurationSec uint64            `json:"estimated_duration_sec,omitempty"`
+	Artifacts            *CircuitArtifacts `json:"artifacts,omitempty"`
+}
+
+// CircuitArtifacts describes the circuit artifacts required to prove a task.
+type CircuitArtifacts struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	// Checksum is the hex encoded sha256 checksum of the artifacts.
+	Checksum string `json:"checksum"`
 }
 
 // ChunkTaskDetail is a type containing ChunkTask detail.
@@ -271,6 +336,19 @@ type ChunkProof struct {
 	// cross-reference between cooridinator computation and prover compution
 	ChunkInfo  *ChunkInfo `json:"chunk_info,omitempty"`
 	GitVersion string     `json:"git_version,omitempty"`
+	// WitnessSource is recorded by the prover, the coordinator does not verify it
+	WitnessSource *WitnessSource `json:"witness_source,omitempty" rlp:"optional"`
+}
+
+// WitnessSource identifies the L2 node state the witness of a chunk proof was generated from, so the proof
+// can be reproduced after node upgrades and a divergence attributed to the node.
+type WitnessSource struct {
+	// NodeVersions are the distinct versions of the L2 nodes which served the block traces.
+	NodeVersions     []string    `json:"node_versions"`
+	ChainID          uint64      `json:"chain_id"`
+	PrevStateRoot    common.Hash `json:"prev_state_root"`
+	PostStateRoot    common.Hash `json:"post_state_root"`
+	WitnessReduction string      `json:"witness_reduction"`
 }
 
 // BatchProof includes the proof info that are required for batch verification and rollup.
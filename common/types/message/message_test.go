@@ -123,6 +123,21 @@ func TestProveTypeString(t *testing.T) {
 	assert.Equal(t, "illegal proof type: 3", illegalProof.String())
 }
 
+func TestTaskDeclineReasonString(t *testing.T) {
+	assert.Equal(t, "unsupported circuit", TaskDeclineReasonUnsupportedCircuit.String())
+	assert.Equal(t, "insufficient memory", TaskDeclineReasonInsufficientMemory.String())
+	assert.Equal(t, "artifact missing", TaskDeclineReasonArtifactMissing.String())
+	assert.Equal(t, "undefined decline reason: 0", TaskDeclineReasonUndefined.String())
+}
+
+func TestTaskProgressStageIsValid(t *testing.T) {
+	assert.True(t, TaskProgressStageFetchingWitness.IsValid())
+	assert.True(t, TaskProgressStageProving.IsValid())
+	assert.True(t, TaskProgressStageSubmitting.IsValid())
+	assert.False(t, TaskProgressStage("").IsValid())
+	assert.False(t, TaskProgressStage("aggregating").IsValid())
+}
+
 func TestProofMsgPublicKey(t *testing.T) {
 	privkey, err := crypto.GenerateKey()
 	assert.NoError(t, err)
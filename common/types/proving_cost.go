@@ -0,0 +1,110 @@
+package types
+
+import (
+	"errors"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// ErrProvingThroughputUnknown is returned by EstimateProvingTime when the cost model has no prover throughput.
+var ErrProvingThroughputUnknown = errors.New("proving cost model has no cycles per second")
+
+// ProvingCostModel converts the row consumption and the transaction mix of blocks into the cycles a prover
+// spends proving them. The circuits of a chunk are sized to its largest sub-circuit, so the rows are charged
+// on the max sub-circuit row count, the transactions are charged per type for the work outside of the rows,
+// e.g. the signature verification of the l2 transactions.
+type ProvingCostModel struct {
+	// BaseCycles is the fixed cost of proving a chunk, whatever its content.
+	BaseCycles uint64 `json:"base_cycles"`
+	// CyclesPerRow is the cost of a row of the largest sub-circuit.
+	CyclesPerRow uint64 `json:"cycles_per_row"`
+	// CyclesPerL2Tx is the cost of an l2 transaction.
+	CyclesPerL2Tx uint64 `json:"cycles_per_l2_tx"`
+	// CyclesPerL1Message is the cost of an l1 message.
+	CyclesPerL1Message uint64 `json:"cycles_per_l1_message"`
+	// CyclesPerSecond is the proving throughput of a prover.
+	CyclesPerSecond uint64 `json:"cycles_per_second"`
+}
+
+// DefaultProvingCostModel is the cost model used when none is given, to be calibrated with the proof times
+// reported by the provers.
+var DefaultProvingCostModel = &ProvingCostModel{
+	BaseCycles:         200_000_000,
+	CyclesPerRow:       2_000,
+	CyclesPerL2Tx:      500_000,
+	CyclesPerL1Message: 50_000,
+	CyclesPerSecond:    100_000_000,
+}
+
+// EstimateProvingCycles estimates the cycles of proving the block alone, the base cost of a chunk excluded.
+// The block must have its row consumption, the default cost model is used if model is nil.
+func (w *WrappedBlock) EstimateProvingCycles(model *ProvingCostModel) (uint64, error) {
+	crc := ChunkRowConsumption{}
+	if err := crc.Add(w.RowConsumption); err != nil {
+		return 0, err
+	}
+	return orDefaultProvingCostModel(model).cycles(crc, w.Transactions), nil
+}
+
+// EstimateProvingTime estimates the time of proving the block alone, the base cost of a chunk excluded.
+func (w *WrappedBlock) EstimateProvingTime(model *ProvingCostModel) (time.Duration, error) {
+	model = orDefaultProvingCostModel(model)
+	cycles, err := w.EstimateProvingCycles(model)
+	if err != nil {
+		return 0, err
+	}
+	return model.duration(cycles)
+}
+
+// EstimateProvingCycles estimates the cycles of proving the chunk. Every block must have its row
+// consumption, the default cost model is used if model is nil.
+func (c *Chunk) EstimateProvingCycles(model *ProvingCostModel) (uint64, error) {
+	model = orDefaultProvingCostModel(model)
+	crc, err := c.RowConsumption()
+	if err != nil {
+		return 0, err
+	}
+	var txs []*types.TransactionData
+	for _, block := range c.Blocks {
+		txs = append(txs, block.Transactions...)
+	}
+	return model.BaseCycles + model.cycles(crc, txs), nil
+}
+
+// EstimateProvingTime estimates the time of proving the chunk.
+func (c *Chunk) EstimateProvingTime(model *ProvingCostModel) (time.Duration, error) {
+	model = orDefaultProvingCostModel(model)
+	cycles, err := c.EstimateProvingCycles(model)
+	if err != nil {
+		return 0, err
+	}
+	return model.duration(cycles)
+}
+
+func orDefaultProvingCostModel(model *ProvingCostModel) *ProvingCostModel {
+	if model == nil {
+		return DefaultProvingCostModel
+	}
+	return model
+}
+
+// cycles returns the cycles of the rows and the transactions, without the base cost.
+func (m *ProvingCostModel) cycles(crc ChunkRowConsumption, txs []*types.TransactionData) uint64 {
+	cycles := m.CyclesPerRow * crc.Max()
+	for _, txData := range txs {
+		if txData.Type == types.L1MessageTxType {
+			cycles += m.CyclesPerL1Message
+		} else {
+			cycles += m.CyclesPerL2Tx
+		}
+	}
+	return cycles
+}
+
+func (m *ProvingCostModel) duration(cycles uint64) (time.Duration, error) {
+	if m.CyclesPerSecond == 0 {
+		return 0, ErrProvingThroughputUnknown
+	}
+	return time.Duration(float64(cycles) / float64(m.CyclesPerSecond) * float64(time.Second)), nil
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateProvingCost(t *testing.T) {
+	model := &ProvingCostModel{BaseCycles: 1000, CyclesPerRow: 10, CyclesPerL2Tx: 100, CyclesPerL1Message: 1, CyclesPerSecond: 100}
+	block1 := &WrappedBlock{
+		RowConsumption: &gethTypes.RowConsumption{{Name: "evm", RowNumber: 10}, {Name: "keccak", RowNumber: 30}},
+		Transactions:   []*gethTypes.TransactionData{{Type: gethTypes.LegacyTxType}, {Type: gethTypes.L1MessageTxType}},
+	}
+	block2 := &WrappedBlock{
+		RowConsumption: &gethTypes.RowConsumption{{Name: "evm", RowNumber: 25}},
+		Transactions:   []*gethTypes.TransactionData{{Type: gethTypes.DynamicFeeTxType}},
+	}
+
+	cycles, err := block1.EstimateProvingCycles(model)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10*30+100+1), cycles)
+	provingTime, err := block1.EstimateProvingTime(model)
+	assert.NoError(t, err)
+	assert.Equal(t, 4010*time.Millisecond, provingTime)
+
+	// the rows of a chunk are charged on its largest sub-circuit summed over the blocks
+	chunk := &Chunk{Blocks: []*WrappedBlock{block1, block2}}
+	cycles, err = chunk.EstimateProvingCycles(model)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000+10*35+2*100+1), cycles)
+	provingTime, err = chunk.EstimateProvingTime(model)
+	assert.NoError(t, err)
+	assert.Equal(t, 15510*time.Millisecond, provingTime)
+
+	// the default model
+	cycles, err = chunk.EstimateProvingCycles(nil)
+	assert.NoError(t, err)
+	defaultCycles, err := chunk.EstimateProvingCycles(DefaultProvingCostModel)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCycles, cycles)
+
+	_, err = chunk.EstimateProvingTime(&ProvingCostModel{CyclesPerRow: 1})
+	assert.ErrorIs(t, err, ErrProvingThroughputUnknown)
+	_, err = (&WrappedBlock{}).EstimateProvingCycles(model)
+	assert.ErrorIs(t, err, ErrRowConsumptionUnknown)
+	_, err = (&Chunk{Blocks: []*WrappedBlock{block1, {}}}).EstimateProvingTime(model)
+	assert.ErrorIs(t, err, ErrRowConsumptionUnknown)
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// ErrRowConsumptionUnknown is returned when the row consumption of a block is aggregated but unknown.
+var ErrRowConsumptionUnknown = errors.New("rowConsumption is <nil>")
+
+// ChunkRowConsumption is the row consumption of a chunk, map(sub-circuit name => sub-circuit row count)
+// summed over the blocks of the chunk, the circuit capacity a chunk is limited by.
+type ChunkRowConsumption map[string]uint64
+
+// NewChunkRowConsumption aggregates the row consumption of the blocks, each block must have its row consumption.
+func NewChunkRowConsumption(blocks []*WrappedBlock) (ChunkRowConsumption, error) {
+	crc := ChunkRowConsumption{}
+	for _, block := range blocks {
+		if err := crc.Add(block.RowConsumption); err != nil {
+			return nil, err
+		}
+	}
+	return crc, nil
+}
+
+// Add accumulates the row consumption of a block per sub-circuit.
+func (crc ChunkRowConsumption) Add(rowConsumption *types.RowConsumption) error {
+	if rowConsumption == nil {
+		return ErrRowConsumptionUnknown
+	}
+	for _, subCircuit := range *rowConsumption {
+		crc[subCircuit.Name] += subCircuit.RowNumber
+	}
+	return nil
+}
+
+// Max returns the maximum row consumption among all sub-circuits.
+func (crc ChunkRowConsumption) Max() uint64 {
+	_, max := crc.MaxSubCircuit()
+	return max
+}
+
+// MaxSubCircuit returns the sub-circuit with the maximum row consumption and its row count,
+// the first sub-circuit by name on a tie.
+func (crc ChunkRowConsumption) MaxSubCircuit() (string, uint64) {
+	var name string
+	var max uint64
+	for subCircuit, value := range crc {
+		if value > max || (value == max && subCircuit < name) {
+			name, max = subCircuit, value
+		}
+	}
+	return name, max
+}
+
+// Clone returns a copy of the chunk row consumption.
+func (crc ChunkRowConsumption) Clone() ChunkRowConsumption {
+	cloned := make(ChunkRowConsumption, len(crc))
+	for subCircuit, value := range crc {
+		cloned[subCircuit] = value
+	}
+	return cloned
+}
+
+// RowConsumption aggregates the row consumption of the blocks of the chunk.
+func (c *Chunk) RowConsumption() (ChunkRowConsumption, error) {
+	return NewChunkRowConsumption(c.Blocks)
+}
+
+// CrcMax returns the maximum row consumption among the sub-circuits of the chunk.
+func (c *Chunk) CrcMax() (uint64, error) {
+	crc, err := c.RowConsumption()
+	if err != nil {
+		return 0, err
+	}
+	return crc.Max(), nil
+}
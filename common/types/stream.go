@@ -0,0 +1,185 @@
+package types
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// KeccakWriter computes the keccak256 hash of the bytes written to it, so an encoding streamed into it
+// is hashed without being held in memory.
+type KeccakWriter struct {
+	state crypto.KeccakState
+}
+
+// NewKeccakWriter creates a new KeccakWriter.
+func NewKeccakWriter() *KeccakWriter {
+	return &KeccakWriter{state: crypto.NewKeccakState()}
+}
+
+// Write adds p to the hashed bytes, it never returns an error.
+func (k *KeccakWriter) Write(p []byte) (int, error) {
+	return k.state.Write(p)
+}
+
+// Hash returns the hash of the bytes written so far, more bytes can be written afterwards.
+func (k *KeccakWriter) Hash() common.Hash {
+	return common.BytesToHash(k.state.Sum(nil))
+}
+
+// streamWriter counts the bytes written to w and keeps the first write error, the following writes are skipped.
+type streamWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (s *streamWriter) write(p []byte) {
+	if s.err != nil {
+		return
+	}
+	n, err := s.w.Write(p)
+	s.n += int64(n)
+	s.err = err
+}
+
+// EncodeTo streams the RollupV2 Chunk Encoding of Encode into w and returns the number of bytes written.
+// The l2 transactions are rlp-encoded one at a time, so the memory used doesn't grow with the chunk.
+func (c *Chunk) EncodeTo(w io.Writer, totalL1MessagePoppedBefore uint64) (int64, error) {
+	numBlocks := len(c.Blocks)
+	if numBlocks > 255 {
+		return 0, errors.New("number of blocks exceeds 1 byte")
+	}
+	if numBlocks == 0 {
+		return 0, errors.New("number of blocks is 0")
+	}
+
+	sw := &streamWriter{w: w}
+	sw.write([]byte{byte(numBlocks)})
+	for _, block := range c.Blocks {
+		blockBytes, err := block.Encode(totalL1MessagePoppedBefore, c.BaseFeeForkBlock)
+		if err != nil {
+			return sw.n, fmt.Errorf("failed to encode block: %v", err)
+		}
+		totalL1MessagePoppedBefore += block.NumL1Messages(totalL1MessagePoppedBefore)
+
+		if len(blockBytes) != 60 {
+			return sw.n, fmt.Errorf("block encoding is not 60 bytes long %x", len(blockBytes))
+		}
+		sw.write(blockBytes)
+	}
+
+	for _, block := range c.Blocks {
+		if err := block.writeL2Txs(sw, true); err != nil {
+			return sw.n, err
+		}
+	}
+	return sw.n, sw.err
+}
+
+// WriteTxPayloadTo streams the tx payload of TxPayload into w and returns the number of bytes written.
+func (c *Chunk) WriteTxPayloadTo(w io.Writer) (int64, error) {
+	sw := &streamWriter{w: w}
+	for _, block := range c.Blocks {
+		if err := block.writeL2Txs(sw, false); err != nil {
+			return sw.n, err
+		}
+	}
+	return sw.n, sw.err
+}
+
+// writeL2Txs writes the rlp-encoded l2 transactions of the block, each one prefixed by its 4-byte length if withLen.
+func (w *WrappedBlock) writeL2Txs(sw *streamWriter, withLen bool) error {
+	for _, txData := range w.Transactions {
+		if txData.Type == types.L1MessageTxType {
+			continue
+		}
+		rlpTxData, err := convertTxDataToRLPEncoding(txData, w.typedTxFields(txData))
+		if err != nil {
+			return err
+		}
+		if withLen {
+			var txLen [4]byte
+			binary.BigEndian.PutUint32(txLen[:], uint32(len(rlpTxData)))
+			sw.write(txLen[:])
+		}
+		sw.write(rlpTxData)
+		if sw.err != nil {
+			return sw.err
+		}
+	}
+	return nil
+}
+
+// writeHashData streams the data hashed by Hash into w: the first 58 bytes of every BlockContext, then the
+// l1 and l2 transaction hashes of each block.
+func (c *Chunk) writeHashData(w io.Writer, totalL1MessagePoppedBefore uint64) error {
+	sw := &streamWriter{w: w}
+	for _, block := range c.Blocks {
+		blockBytes, err := block.Encode(totalL1MessagePoppedBefore, c.BaseFeeForkBlock)
+		if err != nil {
+			return fmt.Errorf("failed to encode block: %v", err)
+		}
+		totalL1MessagePoppedBefore += block.NumL1Messages(totalL1MessagePoppedBefore)
+		sw.write(blockBytes[:58])
+	}
+
+	for _, block := range c.Blocks {
+		for _, l1Messages := range []bool{true, false} {
+			for _, txData := range block.Transactions {
+				if (txData.Type == types.L1MessageTxType) != l1Messages {
+					continue
+				}
+				hashBytes, err := hex.DecodeString(strings.TrimPrefix(txData.TxHash, "0x"))
+				if err != nil {
+					return err
+				}
+				sw.write(hashBytes)
+			}
+		}
+	}
+	return sw.err
+}
+
+// WriteBatchPayload streams the batch payload of NewBatchPayload into w and returns the number of bytes written.
+// The tx payload sizes of the metadata are counted by a first pass over the chunks, so the transactions are
+// rlp-encoded twice instead of holding the payload in memory.
+func WriteBatchPayload(w io.Writer, chunks []*Chunk) (int64, error) {
+	if len(chunks) == 0 {
+		return 0, errors.New("number of chunks is 0")
+	}
+	if len(chunks) > MaxBlobChunks {
+		return 0, fmt.Errorf("number of chunks %v exceeds the blob limit %v", len(chunks), MaxBlobChunks)
+	}
+
+	metadata := make([]byte, blobMetadataSize)
+	binary.BigEndian.PutUint16(metadata[0:2], uint16(len(chunks)))
+	for i, chunk := range chunks {
+		size, err := chunk.WriteTxPayloadTo(io.Discard)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the tx payload of chunk %v: %w", i, err)
+		}
+		binary.BigEndian.PutUint32(metadata[2+4*i:6+4*i], uint32(size))
+	}
+
+	sw := &streamWriter{w: w}
+	sw.write(metadata)
+	if sw.err != nil {
+		return sw.n, sw.err
+	}
+	for i, chunk := range chunks {
+		n, err := chunk.WriteTxPayloadTo(w)
+		sw.n += n
+		if err != nil {
+			return sw.n, fmt.Errorf("failed to write the tx payload of chunk %v: %w", i, err)
+		}
+	}
+	return sw.n, nil
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// limitedWriter fails the writes past its limit.
+type limitedWriter struct {
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("writer limit reached")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestKeccakWriter(t *testing.T) {
+	hasher := NewKeccakWriter()
+	assert.Equal(t, crypto.Keccak256Hash(), hasher.Hash())
+
+	_, err := hasher.Write([]byte("scroll"))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash([]byte("scroll")), hasher.Hash())
+	_, err = hasher.Write([]byte("-tech"))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash([]byte("scroll-tech")), hasher.Hash())
+}
+
+func TestStreamEncoding(t *testing.T) {
+	var chunks []*Chunk
+	for _, name := range []string{"blockTrace_02.json", "blockTrace_03.json", "blockTrace_04.json"} {
+		templateBlockTrace, err := os.ReadFile("../testdata/" + name)
+		assert.NoError(t, err)
+		wrappedBlock := &WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+		chunks = append(chunks, &Chunk{Blocks: []*WrappedBlock{wrappedBlock}})
+	}
+
+	for _, chunk := range chunks {
+		chunkBytes, err := chunk.Encode(0)
+		assert.NoError(t, err)
+
+		hasher := NewKeccakWriter()
+		n, err := chunk.EncodeTo(hasher, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(chunkBytes)), n)
+		assert.Equal(t, crypto.Keccak256Hash(chunkBytes), hasher.Hash())
+
+		// the first write error is returned
+		_, err = chunk.EncodeTo(&limitedWriter{limit: 10}, 0)
+		assert.EqualError(t, err, "writer limit reached")
+	}
+
+	var payload bytes.Buffer
+	n, err := WriteBatchPayload(&payload, chunks)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(payload.Len()), n)
+	data := payload.Bytes()
+	assert.Equal(t, uint16(len(chunks)), binary.BigEndian.Uint16(data[0:2]))
+	offset := blobMetadataSize
+	for i, chunk := range chunks {
+		var chunkPayload []byte
+		for _, block := range chunk.Blocks {
+			blockPayload, err := block.TxPayload()
+			assert.NoError(t, err)
+			chunkPayload = append(chunkPayload, blockPayload...)
+		}
+		assert.Equal(t, uint32(len(chunkPayload)), binary.BigEndian.Uint32(data[2+4*i:6+4*i]))
+		assert.Equal(t, chunkPayload, data[offset:offset+len(chunkPayload)])
+		offset += len(chunkPayload)
+	}
+	assert.Equal(t, len(data), offset)
+
+	_, err = WriteBatchPayload(&limitedWriter{limit: blobMetadataSize + 1}, chunks)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "writer limit reached")
+	_, err = WriteBatchPayload(&payload, nil)
+	assert.EqualError(t, err, "number of chunks is 0")
+}
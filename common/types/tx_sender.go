@@ -0,0 +1,83 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+// ErrTxSenderNotRecoverable is returned by RecoverTxSender when the transaction rebuilt from the trace is not
+// the signed one, e.g. a typed transaction with an access list or a tip cap below its fee cap, whose typed fields
+// the trace doesn't carry, so its signature can't be checked against the payload actually signed.
+var ErrTxSenderNotRecoverable = errors.New("tx sender is not recoverable from the trace")
+
+// RecoverTxSender recovers the sender of the transaction of the trace from its V, R, S by the signer of its type
+// on the chain of the given id. The sender of an L1 message, which isn't signed, is its From field.
+func RecoverTxSender(txData *types.TransactionData, chainID *big.Int) (common.Address, error) {
+	if txData.Type == types.L1MessageTxType {
+		return txData.From, nil
+	}
+	tx, err := newTxFromTxData(txData, nil)
+	if errors.Is(err, ErrTxNotReproducible) {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrTxSenderNotRecoverable, err)
+	}
+	if err != nil {
+		return common.Address{}, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover the sender of tx %s: %w", txData.TxHash, err)
+	}
+	return from, nil
+}
+
+// RecoverTxSenders recovers the senders of the transactions with up to GOMAXPROCS goroutines, the sender of
+// the i-th transaction is the i-th one.
+func RecoverTxSenders(txs []*types.TransactionData, chainID *big.Int) ([]common.Address, error) {
+	return recoverTxSenders(txs, chainID, runtime.GOMAXPROCS(0))
+}
+
+// recoverTxSenders recovers the senders of the transactions with up to the given number of goroutines, the error
+// is the one of the first failing transaction, so the result does not depend on the scheduling.
+func recoverTxSenders(txs []*types.TransactionData, chainID *big.Int, workers int) ([]common.Address, error) {
+	senders := make([]common.Address, len(txs))
+	errs := make([]error, len(txs))
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+
+	if workers <= 1 {
+		for i, txData := range txs {
+			if senders[i], errs[i] = RecoverTxSender(txData, chainID); errs[i] != nil {
+				return nil, errs[i]
+			}
+		}
+		return senders, nil
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(txs); i = int(next.Add(1) - 1) {
+				senders[i], errs[i] = RecoverTxSender(txs[i], chainID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return senders, nil
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverTxSender(t *testing.T) {
+	// the legacy txs of blockTrace_02 are signed on chain 0xcf55
+	templateBlockTrace, err := os.ReadFile("../testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	wrappedBlock := &WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	chainID := big.NewInt(0xcf55)
+	for _, txData := range wrappedBlock.Transactions {
+		from, err := RecoverTxSender(txData, chainID)
+		assert.NoError(t, err)
+		assert.Equal(t, txData.From, from)
+	}
+	_, err = RecoverTxSender(wrappedBlock.Transactions[0], big.NewInt(1))
+	assert.Error(t, err)
+
+	// the l1 messages are not signed
+	templateBlockTrace, err = os.ReadFile("../testdata/blockTrace_05.json")
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+	from, err := RecoverTxSender(wrappedBlock.Transactions[0], chainID)
+	assert.NoError(t, err)
+	assert.Equal(t, wrappedBlock.Transactions[0].From, from)
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	signer := types.LatestSignerForChainID(chainID)
+	to := common.HexToAddress("0x1234")
+	chainConfig := &params.ChainConfig{ChainID: chainID}
+
+	// a dynamic fee tx rebuilt as signed
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(5), Gas: 21000, To: &to, Value: big.NewInt(1)})
+	assert.NoError(t, err)
+	from, err = RecoverTxSender(types.NewTransactionData(tx, 1, chainConfig), chainID)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), from)
+
+	// the tip cap of a dynamic fee tx is not in the trace
+	tx, err = types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: chainID, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(5), Gas: 21000, To: &to, Value: big.NewInt(1)})
+	assert.NoError(t, err)
+	_, err = RecoverTxSender(types.NewTransactionData(tx, 1, chainConfig), chainID)
+	assert.True(t, errors.Is(err, ErrTxSenderNotRecoverable))
+}
+
+func TestRecoverTxSendersParallel(t *testing.T) {
+	chainID := big.NewInt(0xcf55)
+	signer := types.LatestSignerForChainID(chainID)
+	chainConfig := &params.ChainConfig{ChainID: chainID}
+	to := common.HexToAddress("0x1234")
+
+	var txs []*types.TransactionData
+	var expected []common.Address
+	for i := 0; i < 64; i++ {
+		key, err := crypto.GenerateKey()
+		assert.NoError(t, err)
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(1), Gas: 21000, To: &to, Value: big.NewInt(1)})
+		assert.NoError(t, err)
+		txs = append(txs, types.NewTransactionData(tx, 1, chainConfig))
+		expected = append(expected, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	for _, workers := range []int{1, 4, 128} {
+		senders, err := recoverTxSenders(txs, chainID, workers)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, senders)
+	}
+	senders, err := RecoverTxSenders(nil, chainID)
+	assert.NoError(t, err)
+	assert.Empty(t, senders)
+
+	// the error is the one of the first failing tx whatever the number of workers
+	txs[10].Type, txs[20].Type = 3, 4
+	for _, workers := range []int{1, 4, 128} {
+		_, err := recoverTxSenders(txs, chainID, workers)
+		assert.EqualError(t, err, "unsupported tx type: 3")
+	}
+}
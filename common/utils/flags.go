@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"time"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -15,6 +17,18 @@ var (
 		&MetricsEnabled,
 		&MetricsAddr,
 		&MetricsPort,
+		&MetricsPprofEnabled,
+		&MetricsStatsDAddr,
+		&MetricsStatsDPrefix,
+		&MetricsStatsDInterval,
+		&MetricsStatsDTags,
+		&DebugEnabled,
+		&DebugAddr,
+		&DebugPort,
+		&DebugToken,
+		&LabelNetwork,
+		&LabelChainID,
+		&LabelRole,
 		&ServicePortFlag,
 	}
 	// RollupRelayerFlags contains flags only used in rollup-relayer
@@ -71,6 +85,88 @@ var (
 		Category: "METRICS",
 		Value:    6060,
 	}
+	// MetricsPprofEnabled keeps serving pprof unauthenticated on the metrics server
+	MetricsPprofEnabled = cli.BoolFlag{
+		Name:     "metrics.pprof",
+		Usage:    "Serve pprof on the metrics server without authentication, deprecated: pprof is served by the debug server",
+		Category: "METRICS",
+		Value:    false,
+	}
+	// MetricsStatsDAddr is the address of the StatsD or DogStatsD agent the metrics are pushed to
+	MetricsStatsDAddr = cli.StringFlag{
+		Name:     "metrics.statsd.addr",
+		Usage:    "StatsD or DogStatsD agent address the metrics are pushed to over udp, e.g. 127.0.0.1:8125, disabled if empty",
+		Category: "METRICS",
+	}
+	// MetricsStatsDPrefix is the prefix of the metric names pushed to the StatsD agent
+	MetricsStatsDPrefix = cli.StringFlag{
+		Name:     "metrics.statsd.prefix",
+		Usage:    "Prefix of the metric names pushed to the StatsD agent, e.g. scroll.",
+		Category: "METRICS",
+	}
+	// MetricsStatsDInterval is the interval of pushing the metrics to the StatsD agent
+	MetricsStatsDInterval = cli.DurationFlag{
+		Name:     "metrics.statsd.interval",
+		Usage:    "Interval of pushing the metrics to the StatsD agent",
+		Category: "METRICS",
+		Value:    10 * time.Second,
+	}
+	// MetricsStatsDTags sends the metric labels as DogStatsD tags
+	MetricsStatsDTags = cli.BoolFlag{
+		Name:     "metrics.statsd.tags",
+		Usage:    "Send the metric labels as DogStatsD tags, the label values are appended to the metric names otherwise",
+		Category: "METRICS",
+		Value:    true,
+	}
+	// DebugEnabled enable the authenticated debug server
+	DebugEnabled = cli.BoolFlag{
+		Name:     "debug",
+		Usage:    "Enable the debug server serving pprof, goroutine dumps, gc stats and the pipeline state",
+		Category: "DEBUG",
+		Value:    false,
+	}
+	// DebugAddr is listening address of the debug server
+	DebugAddr = cli.StringFlag{
+		Name:     "debug.addr",
+		Usage:    "Debug server listening address",
+		Category: "DEBUG",
+		Value:    "127.0.0.1",
+	}
+	// DebugPort is listening port of the debug server
+	DebugPort = cli.IntFlag{
+		Name:     "debug.port",
+		Usage:    "Debug server listening port",
+		Category: "DEBUG",
+		Value:    6061,
+	}
+	// DebugToken is the bearer token of the debug server requests
+	DebugToken = cli.StringFlag{
+		Name:     "debug.token",
+		Usage:    "Bearer token of the debug server requests",
+		Category: "DEBUG",
+		EnvVars:  []string{"SCROLL_DEBUG_TOKEN"},
+	}
+	// LabelNetwork is the network name label of the metrics and logs
+	LabelNetwork = cli.StringFlag{
+		Name:     "label.network",
+		Usage:    "Network name added to all the metrics and logs, e.g. mainnet or sepolia",
+		Category: "LABELS",
+		EnvVars:  []string{"SCROLL_NETWORK"},
+	}
+	// LabelChainID is the chain id label of the metrics and logs
+	LabelChainID = cli.StringFlag{
+		Name:     "label.chain-id",
+		Usage:    "Chain id added to all the metrics and logs",
+		Category: "LABELS",
+		EnvVars:  []string{"SCROLL_CHAIN_ID"},
+	}
+	// LabelRole is the instance role label of the metrics and logs
+	LabelRole = cli.StringFlag{
+		Name:     "label.role",
+		Usage:    "Instance role added to all the metrics and logs, the binary name if empty while another label is set",
+		Category: "LABELS",
+		EnvVars:  []string{"SCROLL_ROLE"},
+	}
 	// ImportGenesisFlag import genesis batch during startup
 	ImportGenesisFlag = cli.BoolFlag{
 		Name:  "import-genesis",
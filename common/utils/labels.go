@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+// Labels are the chain and environment labels added to all the metrics and structured logs of a service,
+// so the services of mainnet, sepolia and the devnets can share the dashboards.
+type Labels struct {
+	Network string
+	ChainID string
+	Role    string
+}
+
+var (
+	// labels are set once by LogSetup before the services start.
+	labels Labels
+
+	metricsHandler     http.Handler
+	metricsHandlerOnce sync.Once
+)
+
+// LabelsFromContext reads the labels from the label flags, the role defaults to the binary name
+// when another label is set. No label is added if none is set.
+func LabelsFromContext(ctx *cli.Context) Labels {
+	l := Labels{
+		Network: ctx.String(LabelNetwork.Name),
+		ChainID: ctx.String(LabelChainID.Name),
+		Role:    ctx.String(LabelRole.Name),
+	}
+	if l.Role == "" && (l.Network != "" || l.ChainID != "") {
+		l.Role = ctx.App.HelpName
+	}
+	return l
+}
+
+// pairs returns the label names and values, the empty labels are left out.
+func (l Labels) pairs() [][2]string {
+	var pairs [][2]string
+	for _, pair := range [][2]string{{"network", l.Network}, {"chain_id", l.ChainID}, {"role", l.Role}} {
+		if pair[1] != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// labeledLogHandler adds the labels to the context of every log record.
+func labeledLogHandler(l Labels, h log.Handler) log.Handler {
+	pairs := l.pairs()
+	if len(pairs) == 0 {
+		return h
+	}
+	ctx := make([]interface{}, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		ctx = append(ctx, pair[0], pair[1])
+	}
+	return log.FuncHandler(func(r *log.Record) error {
+		r.Ctx = append(r.Ctx, ctx...)
+		return h.Log(r)
+	})
+}
+
+// MetricsHandler serves the metrics of the default registry with the labels, the metrics registered
+// before the labels are known, e.g. in package initialization, are labeled as well.
+func MetricsHandler() http.Handler {
+	metricsHandlerOnce.Do(func() {
+		metricsHandler = promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(MetricsGatherer(), promhttp.HandlerOpts{}))
+	})
+	return metricsHandler
+}
+
+// MetricsGatherer gathers the metrics of the default registry with the labels, for the exporters pushing
+// the metrics instead of serving them.
+func MetricsGatherer() prometheus.Gatherer {
+	return &labeledGatherer{Gatherer: prometheus.DefaultGatherer, labels: labels}
+}
+
+// labeledGatherer adds the labels to the gathered metrics, the labels a metric already has are kept.
+type labeledGatherer struct {
+	prometheus.Gatherer
+	labels Labels
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *labeledGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.Gatherer.Gather()
+	pairs := g.labels.pairs()
+	if len(pairs) == 0 {
+		return families, err
+	}
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			metric.Label = addLabels(metric.Label, pairs)
+		}
+	}
+	return families, err
+}
+
+func addLabels(labelPairs []*dto.LabelPair, pairs [][2]string) []*dto.LabelPair {
+	existing := make(map[string]struct{}, len(labelPairs))
+	for _, labelPair := range labelPairs {
+		existing[labelPair.GetName()] = struct{}{}
+	}
+	for _, pair := range pairs {
+		if _, ok := existing[pair[0]]; ok {
+			continue
+		}
+		name, value := pair[0], pair[1]
+		labelPairs = append(labelPairs, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	sort.Slice(labelPairs, func(i, j int) bool { return labelPairs[i].GetName() < labelPairs[j].GetName() })
+	return labelPairs
+}
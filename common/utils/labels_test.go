@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	l := Labels{Network: "sepolia", Role: "rollup-relayer"}
+	assert.Equal(t, [][2]string{{"network", "sepolia"}, {"role", "rollup-relayer"}}, l.pairs())
+
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "test"}, []string{"role"})
+	reg.MustRegister(counter)
+	counter.WithLabelValues("custom").Inc()
+
+	// the labels a metric already has are kept
+	families, err := (&labeledGatherer{Gatherer: reg, labels: l}).Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	labelPairs := families[0].Metric[0].Label
+	require.Len(t, labelPairs, 2)
+	assert.Equal(t, "network", labelPairs[0].GetName())
+	assert.Equal(t, "sepolia", labelPairs[0].GetValue())
+	assert.Equal(t, "role", labelPairs[1].GetName())
+	assert.Equal(t, "custom", labelPairs[1].GetValue())
+
+	var buf bytes.Buffer
+	logger := log.New()
+	logger.SetHandler(labeledLogHandler(l, log.StreamHandler(&buf, log.JSONFormat())))
+	logger.Info("labeled", "key", "value")
+	assert.True(t, strings.Contains(buf.String(), `"network":"sepolia"`))
+	assert.True(t, strings.Contains(buf.String(), `"role":"rollup-relayer"`))
+	assert.True(t, strings.Contains(buf.String(), `"key":"value"`))
+}
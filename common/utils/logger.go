@@ -37,6 +37,7 @@ func LogSetup(ctx *cli.Context) error {
 	glogger := log.NewGlogHandler(ostream)
 	// Set log level
 	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
-	log.Root().SetHandler(glogger)
+	labels = LabelsFromContext(ctx)
+	log.Root().SetHandler(labeledLogHandler(labels, glogger))
 	return nil
 }
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNTPTimeConversion(t *testing.T) {
+	now := time.Unix(1700000000, 123456789)
+	assert.WithinDuration(t, now, fromNTPTime(toNTPTime(now)), time.Microsecond)
+}
+
+func TestNTPClockOffset(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	skew := 3 * time.Second
+	go func() {
+		buf := make([]byte, 48)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil || n < 48 {
+			return
+		}
+		resp := make([]byte, 48)
+		resp[0] = 0x24
+		copy(resp[24:32], buf[40:48])
+		now := time.Now().Add(skew)
+		binary.BigEndian.PutUint64(resp[32:], toNTPTime(now))
+		binary.BigEndian.PutUint64(resp[40:], toNTPTime(now))
+		_, _ = conn.WriteTo(resp, addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	offset, err := NTPClockOffset(ctx, conn.LocalAddr().String())
+	assert.NoError(t, err)
+	assert.InDelta(t, skew.Seconds(), offset.Seconds(), 0.5)
+}
@@ -0,0 +1,89 @@
+package withdrawtrie
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+var (
+	// ErrMessageNonceMismatch is returned when the appended messages don't follow the messages of the trie.
+	ErrMessageNonceMismatch = errors.New("message nonce mismatch")
+	// ErrWithdrawRootMismatch is returned when the root of the trie diverges from the expected withdraw root.
+	ErrWithdrawRootMismatch = errors.New("withdraw root mismatch")
+)
+
+// relayMessageSelector is the selector of relayMessage(address,address,uint256,uint256,bytes).
+var relayMessageSelector = crypto.Keccak256([]byte("relayMessage(address,address,uint256,uint256,bytes)"))[:4]
+
+var relayMessageArguments abi.Arguments
+
+func init() {
+	addressType, _ := abi.NewType("address", "", nil)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	relayMessageArguments = abi.Arguments{{Type: addressType}, {Type: addressType}, {Type: uint256Type}, {Type: uint256Type}, {Type: bytesType}}
+}
+
+// SentMessage is a SentMessage event of the L2ScrollMessenger, a leaf of the withdraw trie.
+type SentMessage struct {
+	Sender       common.Address
+	Target       common.Address
+	Value        *big.Int
+	MessageNonce *big.Int
+	Message      []byte
+}
+
+// Hash returns the message hash of the message, see ComputeMessageHash.
+func (m *SentMessage) Hash() common.Hash {
+	return ComputeMessageHash(m.Sender, m.Target, m.Value, m.MessageNonce, m.Message)
+}
+
+// ComputeMessageHash computes the hash of a cross domain message, the keccak256 of its relayMessage calldata.
+func ComputeMessageHash(sender, target common.Address, value, messageNonce *big.Int, message []byte) common.Hash {
+	data, _ := relayMessageArguments.Pack(sender, target, value, messageNonce, message)
+	return crypto.Keccak256Hash(relayMessageSelector, data)
+}
+
+// AppendSentMessages appends the messages of the SentMessage events, in the order of their nonces,
+// the first message must have the next message nonce of the trie.
+func (w *WithdrawTrie) AppendSentMessages(messages []*SentMessage) error {
+	for i, message := range messages {
+		if message.MessageNonce == nil || !message.MessageNonce.IsUint64() || message.MessageNonce.Uint64() != w.NextMessageNonce {
+			return fmt.Errorf("%w: message %v, expected nonce: %v, got: %v", ErrMessageNonceMismatch, i, w.NextMessageNonce, message.MessageNonce)
+		}
+		w.AppendMessage(message.Hash())
+	}
+	return nil
+}
+
+// VerifyRoot checks the root of the trie against the withdraw root, the root of the trie is the withdraw root
+// of the L2 block of the last appended message and of the blocks up to the next message.
+func (w *WithdrawTrie) VerifyRoot(withdrawRoot common.Hash) error {
+	if root := w.MessageRoot(); root != withdrawRoot {
+		return fmt.Errorf("%w: next message nonce %v, trie root: %v, withdraw root: %v", ErrWithdrawRootMismatch, w.NextMessageNonce, root.Hex(), withdrawRoot.Hex())
+	}
+	return nil
+}
+
+// VerifyMerkleProof checks the merkle proof of the message against the withdraw root, the same as the
+// verifyMerkleProof of the WithdrawTrieVerifier contract relayMessageWithProof calls on L1.
+func VerifyMerkleProof(withdrawRoot, messageHash common.Hash, nonce uint64, proofBytes []byte) bool {
+	if len(proofBytes)%32 != 0 {
+		return false
+	}
+	root := messageHash
+	for _, item := range decodeBytesToMerkleProof(proofBytes) {
+		if nonce%2 == 0 {
+			root = keccak2(root, item)
+		} else {
+			root = keccak2(item, root)
+		}
+		nonce >>= 1
+	}
+	return root == withdrawRoot
+}
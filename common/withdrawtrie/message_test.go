@@ -0,0 +1,75 @@
+package withdrawtrie
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeMessageHash(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	target := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	message := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	// relayMessage(sender, target, value, nonce, message) abi encoded by hand
+	var data []byte
+	data = append(data, crypto.Keccak256([]byte("relayMessage(address,address,uint256,uint256,bytes)"))[:4]...)
+	data = append(data, common.LeftPadBytes(sender[:], 32)...)
+	data = append(data, common.LeftPadBytes(target[:], 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(100).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(7).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(5*32).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(message))).Bytes(), 32)...)
+	data = append(data, common.RightPadBytes(message, 32)...)
+
+	assert.Equal(t, crypto.Keccak256Hash(data), ComputeMessageHash(sender, target, big.NewInt(100), big.NewInt(7), message))
+}
+
+func TestAppendSentMessages(t *testing.T) {
+	var messages []*SentMessage
+	var hashes []common.Hash
+	for i := 0; i < 5; i++ {
+		message := &SentMessage{
+			Sender:       common.BigToAddress(big.NewInt(int64(i + 1))),
+			Target:       common.BigToAddress(big.NewInt(int64(i + 2))),
+			Value:        big.NewInt(int64(i)),
+			MessageNonce: big.NewInt(int64(i)),
+			Message:      []byte{byte(i)},
+		}
+		messages = append(messages, message)
+		hashes = append(hashes, message.Hash())
+	}
+
+	withdrawTrie := NewWithdrawTrie()
+	assert.NoError(t, withdrawTrie.VerifyRoot(common.Hash{}))
+	assert.NoError(t, withdrawTrie.AppendSentMessages(messages[:2]))
+	assert.NoError(t, withdrawTrie.VerifyRoot(computeMerkleRoot(hashes[:2])))
+	assert.NoError(t, withdrawTrie.AppendSentMessages(messages[2:]))
+	assert.NoError(t, withdrawTrie.VerifyRoot(computeMerkleRoot(hashes)))
+	assert.ErrorIs(t, withdrawTrie.VerifyRoot(computeMerkleRoot(hashes[:4])), ErrWithdrawRootMismatch)
+
+	// the messages must follow the messages of the trie
+	assert.ErrorIs(t, withdrawTrie.AppendSentMessages(messages[4:]), ErrMessageNonceMismatch)
+	assert.ErrorIs(t, NewWithdrawTrie().AppendSentMessages(messages[1:]), ErrMessageNonceMismatch)
+	assert.Equal(t, uint64(5), withdrawTrie.NextMessageNonce)
+}
+
+func TestVerifyMerkleProof(t *testing.T) {
+	var hashes []common.Hash
+	for i := 0; i < 11; i++ {
+		hashes = append(hashes, common.BigToHash(big.NewInt(int64(i+1))))
+	}
+	withdrawTrie := NewWithdrawTrie()
+	proofs := withdrawTrie.AppendMessages(hashes)
+	root := withdrawTrie.MessageRoot()
+
+	for i, proof := range proofs {
+		assert.True(t, VerifyMerkleProof(root, hashes[i], uint64(i), proof), "message %v", i)
+		assert.False(t, VerifyMerkleProof(root, hashes[i], uint64(i+1), proof), "message %v", i)
+		assert.False(t, VerifyMerkleProof(root, hashes[(i+1)%len(hashes)], uint64(i), proof), "message %v", i)
+	}
+	assert.False(t, VerifyMerkleProof(root, hashes[0], 0, proofs[0][1:]))
+}
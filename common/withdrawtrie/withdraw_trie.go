@@ -1,7 +1,11 @@
-package utils
+// Package withdrawtrie rebuilds the withdraw trie of the L2MessageQueue predeploy, the append only merkle tree
+// of the L2 to L1 message hashes whose root is the WithdrawRoot of the L2 blocks, from the SentMessage events of
+// the L2ScrollMessenger. It computes the merkle proofs of the withdrawals and detects a diverging withdraw root.
+package withdrawtrie
 
 import (
 	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
 )
 
 // MaxHeight is the maximum possible height of withdrawal trie
@@ -25,7 +29,7 @@ func NewWithdrawTrie() *WithdrawTrie {
 
 	zeroes[0] = common.Hash{}
 	for i := 1; i < MaxHeight; i++ {
-		zeroes[i] = Keccak2(zeroes[i-1], zeroes[i-1])
+		zeroes[i] = keccak2(zeroes[i-1], zeroes[i-1])
 	}
 
 	return &WithdrawTrie{
@@ -86,7 +90,7 @@ func (w *WithdrawTrie) AppendMessages(hashes []common.Hash) [][]byte {
 			cache[h][maxIndex^1] = w.zeroes[h]
 		}
 		for i := minIndex; i <= maxIndex; i += 2 {
-			cache[h+1][i>>1] = Keccak2(cache[h][i], cache[h][i^1])
+			cache[h+1][i>>1] = keccak2(cache[h][i], cache[h][i^1])
 		}
 		minIndex >>= 1
 		maxIndex >>= 1
@@ -114,6 +118,27 @@ func (w *WithdrawTrie) AppendMessages(hashes []common.Hash) [][]byte {
 	return proofs
 }
 
+// AppendMessage appends a new message as the rightest leaf node without computing its proof,
+// it is cheaper than AppendMessages to replay the finalized messages.
+func (w *WithdrawTrie) AppendMessage(hash common.Hash) {
+	proof := updateBranchWithNewMessage(w.zeroes, w.branches, w.NextMessageNonce, hash)
+	w.NextMessageNonce++
+	w.height = len(proof)
+}
+
+// Snapshot returns the height and the encoded branches of the trie, which restore it with Restore.
+func (w *WithdrawTrie) Snapshot() (int, []byte) {
+	return w.height, encodeMerkleProofToBytes(w.branches)
+}
+
+// Restore restores the trie from a snapshot taken when the next message nonce was nextMessageNonce.
+func (w *WithdrawTrie) Restore(nextMessageNonce uint64, height int, branches []byte) {
+	w.branches = make([]common.Hash, MaxHeight)
+	copy(w.branches, decodeBytesToMerkleProof(branches))
+	w.height = height
+	w.NextMessageNonce = nextMessageNonce
+}
+
 // MessageRoot return the current root hash of withdraw trie.
 func (w *WithdrawTrie) MessageRoot() common.Hash {
 	if w.height == -1 {
@@ -151,10 +176,10 @@ func updateBranchWithNewMessage(zeroes []common.Hash, branches []common.Hash, in
 			branches[height] = root
 			merkleProof = append(merkleProof, zeroes[height])
 			// it's a left child, the right child must be null
-			root = Keccak2(root, zeroes[height])
+			root = keccak2(root, zeroes[height])
 		} else {
 			// it's a right child, use previously computed hash
-			root = Keccak2(branches[height], root)
+			root = keccak2(branches[height], root)
 			merkleProof = append(merkleProof, branches[height])
 		}
 		index >>= 1
@@ -172,11 +197,11 @@ func recoverBranchFromProof(proof []common.Hash, index uint64, msgHash common.Ha
 		if index%2 == 0 {
 			branches[height] = root
 			// it's a left child, the right child must be null
-			root = Keccak2(root, proof[height])
+			root = keccak2(root, proof[height])
 		} else {
 			// it's a right child, use previously computed hash
 			branches[height] = proof[height]
-			root = Keccak2(proof[height], root)
+			root = keccak2(proof[height], root)
 		}
 		index >>= 1
 	}
@@ -186,3 +211,8 @@ func recoverBranchFromProof(proof []common.Hash, index uint64, msgHash common.Ha
 	}
 	return branches
 }
+
+// keccak2 computes the keccak256 of the concatenation of two bytes32
+func keccak2(a common.Hash, b common.Hash) common.Hash {
+	return crypto.Keccak256Hash(a[:], b[:])
+}
@@ -1,4 +1,4 @@
-package utils
+package withdrawtrie
 
 import (
 	"math/big"
@@ -13,7 +13,7 @@ func TestUpdateBranchWithNewMessage(t *testing.T) {
 	branches := make([]common.Hash, 64)
 	zeroes[0] = common.Hash{}
 	for i := 1; i < 64; i++ {
-		zeroes[i] = Keccak2(zeroes[i-1], zeroes[i-1])
+		zeroes[i] = keccak2(zeroes[i-1], zeroes[i-1])
 	}
 
 	updateBranchWithNewMessage(zeroes, branches, 0, common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000001"))
@@ -66,7 +66,7 @@ func TestRecoverBranchFromProof(t *testing.T) {
 	branches := make([]common.Hash, 64)
 	zeroes[0] = common.Hash{}
 	for i := 1; i < 64; i++ {
-		zeroes[i] = Keccak2(zeroes[i-1], zeroes[i-1])
+		zeroes[i] = keccak2(zeroes[i-1], zeroes[i-1])
 	}
 
 	proof := updateBranchWithNewMessage(zeroes, branches, 0, common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000001"))
@@ -172,13 +172,64 @@ func TestWithdrawTrieMultiple(t *testing.T) {
 	}
 }
 
+func TestWithdrawTrieSnapshot(t *testing.T) {
+	var hashes []common.Hash
+	for i := 0; i < 128; i++ {
+		hashes = append(hashes, common.BigToHash(big.NewInt(int64(i+1))))
+	}
+
+	for snapshotAt := 0; snapshotAt < 100; snapshotAt++ {
+		// replay the messages before the snapshot one by one
+		withdrawTrie := NewWithdrawTrie()
+		for i := 0; i < snapshotAt; i++ {
+			withdrawTrie.AppendMessage(hashes[i])
+		}
+		assert.Equal(t, computeMerkleRoot(hashes[:snapshotAt]).String(), withdrawTrie.MessageRoot().String())
+
+		height, branches := withdrawTrie.Snapshot()
+		restored := NewWithdrawTrie()
+		restored.Restore(withdrawTrie.NextMessageNonce, height, branches)
+		assert.Equal(t, withdrawTrie.MessageRoot().String(), restored.MessageRoot().String())
+
+		proofBytes := restored.AppendMessages(hashes[snapshotAt:100])
+		assert.Equal(t, uint64(100), restored.NextMessageNonce)
+		expectedRoot := computeMerkleRoot(hashes[:100])
+		assert.Equal(t, expectedRoot.String(), restored.MessageRoot().String())
+		for i := snapshotAt; i < 100; i++ {
+			proof := decodeBytesToMerkleProof(proofBytes[i-snapshotAt])
+			assert.Equal(t, expectedRoot.String(), verifyMerkleProof(uint64(i), hashes[i], proof).String())
+		}
+	}
+}
+
+func TestWithdrawTrieZeroes(t *testing.T) {
+	// zeroHashes of the AppendOnlyMerkleTree contract
+	withdrawTrie := NewWithdrawTrie()
+	assert.Equal(t, common.Hash{}, withdrawTrie.zeroes[0])
+	assert.Equal(t, common.HexToHash("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5"), withdrawTrie.zeroes[1])
+	assert.Equal(t, common.HexToHash("0xb4c11951957c6f8f642c4af61cd6b24640fec6dc7fc607ee8206a99e92410d30"), withdrawTrie.zeroes[2])
+	assert.Equal(t, common.HexToHash("0x21ddb9a356815c3fac1026b6dec5df3124afbadb485c9ba5a3e3398a04b7ba85"), withdrawTrie.zeroes[3])
+}
+
+func TestWithdrawTrieMessageRoot(t *testing.T) {
+	var hashes []common.Hash
+	withdrawTrie := NewWithdrawTrie()
+	assert.Equal(t, common.Hash{}, withdrawTrie.MessageRoot())
+	for i := 0; i < 128; i++ {
+		hash := common.BigToHash(big.NewInt(int64(i + 1)))
+		hashes = append(hashes, hash)
+		withdrawTrie.AppendMessage(hash)
+		assert.Equal(t, computeMerkleRoot(hashes).String(), withdrawTrie.MessageRoot().String())
+	}
+}
+
 func verifyMerkleProof(index uint64, leaf common.Hash, proof []common.Hash) common.Hash {
 	root := leaf
 	for _, h := range proof {
 		if index%2 == 0 {
-			root = Keccak2(root, h)
+			root = keccak2(root, h)
 		} else {
-			root = Keccak2(h, root)
+			root = keccak2(h, root)
 		}
 		index >>= 1
 	}
@@ -198,13 +249,13 @@ func computeMerkleRoot(hashes []common.Hash) common.Hash {
 		var newHashes []common.Hash
 		for i := 0; i < len(hashes); i += 2 {
 			if i+1 < len(hashes) {
-				newHashes = append(newHashes, Keccak2(hashes[i], hashes[i+1]))
+				newHashes = append(newHashes, keccak2(hashes[i], hashes[i+1]))
 			} else {
-				newHashes = append(newHashes, Keccak2(hashes[i], zeroHash))
+				newHashes = append(newHashes, keccak2(hashes[i], zeroHash))
 			}
 		}
 		hashes = newHashes
-		zeroHash = Keccak2(zeroHash, zeroHash)
+		zeroHash = keccak2(zeroHash, zeroHash)
 	}
 	return hashes[0]
 }
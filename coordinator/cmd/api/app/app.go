@@ -11,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 	"gorm.io/gorm"
@@ -20,6 +21,8 @@ import (
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
 
+	"scroll-tech/database/migrate"
+
 	"scroll-tech/coordinator/internal/config"
 	"scroll-tech/coordinator/internal/controller/api"
 	"scroll-tech/coordinator/internal/route"
@@ -54,6 +57,13 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
 	defer func() {
 		if err = database.CloseDB(db); err != nil {
 			log.Error("can not close db connection", "error", err)
@@ -63,6 +73,8 @@ func action(ctx *cli.Context) error {
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
 
+	checkClockDrift(ctx.Context, cfg, registry)
+
 	apiSrv := apiServer(ctx, cfg, db, registry)
 
 	log.Info(
@@ -109,6 +121,34 @@ func apiServer(ctx *cli.Context, cfg *config.Config, db *gorm.DB, reg prometheus
 	return srv
 }
 
+// checkClockDrift warns if the drift between the local clock and the ntp server exceeds the threshold,
+// the task deadlines are server relative but the timeouts are still checked on the coordinator clock.
+func checkClockDrift(ctx context.Context, cfg *config.Config, reg prometheus.Registerer) {
+	if cfg.ClockCheck == nil || cfg.ClockCheck.NTPServer == "" {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	offset, err := utils.NTPClockOffset(checkCtx, cfg.ClockCheck.NTPServer)
+	if err != nil {
+		log.Warn("failed to check local clock drift", "ntp server", cfg.ClockCheck.NTPServer, "error", err)
+		return
+	}
+
+	promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "coordinator_local_clock_drift_seconds",
+		Help: "The drift between the local clock and the ntp server measured at startup.",
+	}).Set(offset.Seconds())
+
+	maxDrift := time.Duration(cfg.ClockCheck.MaxClockDriftMs) * time.Millisecond
+	if maxDrift > 0 && (offset > maxDrift || offset < -maxDrift) {
+		log.Warn("local clock drift exceeds the threshold, please check the ntp service", "drift", offset, "threshold", maxDrift)
+		return
+	}
+	log.Info("local clock drift checked", "drift", offset)
+}
+
 // Run coordinator.
 func Run() {
 	// RunApp the coordinator.
@@ -15,6 +15,8 @@ import (
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
 
+	"scroll-tech/database/migrate"
+
 	"scroll-tech/coordinator/internal/config"
 	"scroll-tech/coordinator/internal/controller/cron"
 )
@@ -48,6 +50,13 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
 
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
@@ -2,10 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"scroll-tech/common/database"
+	"scroll-tech/common/types/message"
 )
 
 // ProverManager loads sequencer configuration items.
@@ -23,6 +25,56 @@ type ProverManager struct {
 	ChunkCollectionTimeSec int `json:"chunk_collection_time_sec"`
 	// Max number of workers in verifier worker pool
 	MaxVerifierWorkers int `json:"max_verifier_workers"`
+	// DeclineCooldownSec is the duration (in seconds) a prover isn't assigned the task type it declined, default 300.
+	DeclineCooldownSec int `json:"decline_cooldown_sec,omitempty"`
+	// ProgressStaleSec is the duration (in seconds) without progress after which an assigned task is reported as stuck, default 600.
+	ProgressStaleSec int `json:"progress_stale_sec,omitempty"`
+	// HeartbeatStaleSec is the duration (in seconds) without get task request after which a prover isn't counted in the fleet capacity, default 300.
+	HeartbeatStaleSec int `json:"heartbeat_stale_sec,omitempty"`
+	// ChunkTaskResources is the resource descriptor attached to the dispatched chunk tasks.
+	ChunkTaskResources *message.TaskResources `json:"chunk_task_resources,omitempty"`
+	// BatchTaskResources is the resource descriptor attached to the dispatched batch tasks.
+	BatchTaskResources *message.TaskResources `json:"batch_task_resources,omitempty"`
+	// HardForks are the circuit upgrades of the layer 2 chain in ascending block number, the same for the coordinators
+	// of all the circuits, empty if the chain has a single circuit.
+	HardForks []*HardFork `json:"hard_forks,omitempty"`
+	// HardForkName is the hard fork of the circuit of the verifier, the coordinator dispatches the tasks of its blocks only.
+	HardForkName string `json:"hard_fork_name,omitempty"`
+}
+
+// HardFork is a circuit upgrade, the blocks from BlockNumber until the next hard fork are proven by its circuit.
+type HardFork struct {
+	Name        string `json:"name"`
+	BlockNumber uint64 `json:"block_number"`
+}
+
+// validateHardForks checks the hard forks are named uniquely and in ascending block number,
+// and the hard fork of the coordinator is one of them.
+func (p *ProverManager) validateHardForks() error {
+	if len(p.HardForks) == 0 {
+		if p.HardForkName != "" {
+			return fmt.Errorf("hard fork %v of the coordinator is not in the hard forks", p.HardForkName)
+		}
+		return nil
+	}
+
+	names := make(map[string]bool, len(p.HardForks))
+	for i, fork := range p.HardForks {
+		if fork == nil || fork.Name == "" {
+			return fmt.Errorf("hard fork %v has no name", i)
+		}
+		if names[fork.Name] {
+			return fmt.Errorf("hard fork %v is defined twice", fork.Name)
+		}
+		names[fork.Name] = true
+		if i > 0 && fork.BlockNumber <= p.HardForks[i-1].BlockNumber {
+			return fmt.Errorf("hard fork %v at block %v is not after hard fork %v at block %v", fork.Name, fork.BlockNumber, p.HardForks[i-1].Name, p.HardForks[i-1].BlockNumber)
+		}
+	}
+	if !names[p.HardForkName] {
+		return fmt.Errorf("hard fork %q of the coordinator is not in the hard forks", p.HardForkName)
+	}
+	return nil
 }
 
 // L2 loads l2geth configuration items.
@@ -38,12 +90,50 @@ type Auth struct {
 	LoginExpireDurationSec     int    `json:"login_expire_duration_sec"`
 }
 
+// Admin provides the admin api of coordinator
+type Admin struct {
+	// Secret is the bearer token of the admin api, the admin api is disabled if empty.
+	Secret string `json:"secret"`
+}
+
+// Artifacts provides the circuit artifacts distribution of coordinator
+type Artifacts struct {
+	// Dir is the directory of artifacts, organized as <dir>/<version>/<name>.
+	Dir string `json:"dir"`
+	// Mirrors are the base urls which serve the same layout, returned to provers as alternative sources.
+	Mirrors []string `json:"mirrors,omitempty"`
+}
+
+// ClockCheck provides the clock skew check of coordinator
+type ClockCheck struct {
+	// NTPServer is the address of the ntp server used to check the local clock drift at startup, e.g. "pool.ntp.org:123".
+	NTPServer string `json:"ntp_server,omitempty"`
+	// MaxClockDriftMs is the max tolerated drift between the clocks of coordinator, ntp server and provers.
+	MaxClockDriftMs int64 `json:"max_clock_drift_ms"`
+}
+
+// StaleTaskGC provides the garbage collector of the tasks stuck in assigned status
+type StaleTaskGC struct {
+	// CheckIntervalSec is the interval between two collections.
+	CheckIntervalSec int `json:"check_interval_sec"`
+	// ProverTaskMaxAgeSec is the max duration a prover task stays assigned before it's expired.
+	ProverTaskMaxAgeSec int `json:"prover_task_max_age_sec"`
+	// ChunkMaxAgeSec is the max duration a chunk stays assigned without any active prover task before it's failed.
+	ChunkMaxAgeSec int `json:"chunk_max_age_sec"`
+	// BatchMaxAgeSec is the max duration a batch stays assigned without any active prover task before it's failed.
+	BatchMaxAgeSec int `json:"batch_max_age_sec"`
+}
+
 // Config load configuration items.
 type Config struct {
 	ProverManager *ProverManager   `json:"prover_manager"`
 	DB            *database.Config `json:"db"`
 	L2            *L2              `json:"l2"`
 	Auth          *Auth            `json:"auth"`
+	Admin         *Admin           `json:"admin,omitempty"`
+	Artifacts     *Artifacts       `json:"artifacts,omitempty"`
+	ClockCheck    *ClockCheck      `json:"clock_check,omitempty"`
+	StaleTaskGC   *StaleTaskGC     `json:"stale_task_gc,omitempty"`
 }
 
 // VerifierConfig load zk verifier config.
@@ -66,5 +156,11 @@ func NewConfig(file string) (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.ProverManager != nil {
+		if err = cfg.ProverManager.validateHardForks(); err != nil {
+			return nil, fmt.Errorf("invalid hard forks: %w", err)
+		}
+	}
+
 	return cfg, nil
 }
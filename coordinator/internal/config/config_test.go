@@ -88,4 +88,15 @@ func TestConfig(t *testing.T) {
 		_, err = NewConfig(tmpFile.Name())
 		assert.Error(t, err)
 	})
+	t.Run("Hard Forks", func(t *testing.T) {
+		forks := []*HardFork{{Name: "bernoulli", BlockNumber: 0}, {Name: "curie", BlockNumber: 100}}
+		assert.NoError(t, (&ProverManager{}).validateHardForks())
+		assert.NoError(t, (&ProverManager{HardForks: forks, HardForkName: "curie"}).validateHardForks())
+		assert.Error(t, (&ProverManager{HardForkName: "curie"}).validateHardForks())
+		assert.Error(t, (&ProverManager{HardForks: forks}).validateHardForks())
+		assert.Error(t, (&ProverManager{HardForks: forks, HardForkName: "darwin"}).validateHardForks())
+		assert.Error(t, (&ProverManager{HardForks: []*HardFork{forks[1], forks[0]}, HardForkName: "curie"}).validateHardForks())
+		assert.Error(t, (&ProverManager{HardForks: []*HardFork{forks[0], forks[0]}, HardForkName: "bernoulli"}).validateHardForks())
+		assert.Error(t, (&ProverManager{HardForks: []*HardFork{{BlockNumber: 1}}}).validateHardForks())
+	})
 }
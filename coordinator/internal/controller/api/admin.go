@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/logic/taskprogress"
+	"scroll-tech/coordinator/internal/orm"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+// maxTaskProgressLimit is the max number of assigned tasks returned by the task progress api
+const maxTaskProgressLimit = 1000
+
+// AdminController the admin api controller
+type AdminController struct {
+	getTask           *GetTaskController
+	taskProgressLogic *taskprogress.TaskProgressLogic
+	chunkOrm          *orm.Chunk
+	batchOrm          *orm.Batch
+}
+
+// NewAdminController create an admin controller
+func NewAdminController(getTask *GetTaskController, taskProgressLogic *taskprogress.TaskProgressLogic, db *gorm.DB) *AdminController {
+	return &AdminController{
+		getTask:           getTask,
+		taskProgressLogic: taskProgressLogic,
+		chunkOrm:          orm.NewChunk(db),
+		batchOrm:          orm.NewBatch(db),
+	}
+}
+
+// Drain stops assigning new tasks, proofs of the in-flight tasks are still accepted
+func (a *AdminController) Drain(ctx *gin.Context) {
+	if err := a.getTask.SetDrainMode(ctx, true); err != nil {
+		types.RenderFailure(ctx, types.InternalServerError, err)
+		return
+	}
+	types.RenderSuccess(ctx, a.status(ctx))
+}
+
+// Resume leaves the drain mode and restarts assigning new tasks
+func (a *AdminController) Resume(ctx *gin.Context) {
+	if err := a.getTask.SetDrainMode(ctx, false); err != nil {
+		types.RenderFailure(ctx, types.InternalServerError, err)
+		return
+	}
+	types.RenderSuccess(ctx, a.status(ctx))
+}
+
+// Status returns the admin status of coordinator
+func (a *AdminController) Status(ctx *gin.Context) {
+	types.RenderSuccess(ctx, a.status(ctx))
+}
+
+// SetTaskLane manually moves a chunk or batch proving task to the given priority lane
+func (a *AdminController) SetTaskLane(ctx *gin.Context) {
+	var param coordinatorType.SetTaskLaneParameter
+	if err := ctx.ShouldBind(&param); err != nil {
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, fmt.Errorf("parameter invalid, err:%w", err))
+		return
+	}
+
+	var lane types.TaskLane
+	switch param.Lane {
+	case types.TaskLaneNormal.String():
+		lane = types.TaskLaneNormal
+	case types.TaskLaneHigh.String():
+		lane = types.TaskLaneHigh
+	default:
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, fmt.Errorf("invalid lane: %s", param.Lane))
+		return
+	}
+
+	var err error
+	switch message.ProofType(param.TaskType) {
+	case message.ProofTypeChunk:
+		err = a.chunkOrm.UpdatePriorityByHash(ctx, param.TaskID, lane)
+	case message.ProofTypeBatch:
+		err = a.batchOrm.UpdatePriorityByHash(ctx, param.TaskID, lane)
+	default:
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, fmt.Errorf("invalid task type: %d", param.TaskType))
+		return
+	}
+	if err != nil {
+		types.RenderFailure(ctx, types.InternalServerError, err)
+		return
+	}
+	types.RenderSuccess(ctx, nil)
+}
+
+// TaskProgress returns the progress, the estimated remaining time and the stuck flag of the assigned tasks
+func (a *AdminController) TaskProgress(ctx *gin.Context) {
+	var param coordinatorType.TaskProgressParameter
+	if err := ctx.ShouldBindQuery(&param); err != nil {
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, fmt.Errorf("parameter invalid, err:%w", err))
+		return
+	}
+	if param.Limit <= 0 || param.Limit > maxTaskProgressLimit {
+		param.Limit = maxTaskProgressLimit
+	}
+
+	progresses, err := a.taskProgressLogic.Progresses(ctx, param.Limit)
+	if err != nil {
+		types.RenderFailure(ctx, types.InternalServerError, err)
+		return
+	}
+	types.RenderSuccess(ctx, progresses)
+}
+
+func (a *AdminController) status(ctx context.Context) *coordinatorType.AdminStatusSchema {
+	return &coordinatorType.AdminStatusSchema{
+		Draining: a.getTask.IsDraining(ctx),
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/coordinator/internal/config"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+// ArtifactController serves the versioned circuit artifacts (proving keys and params) to provers
+type ArtifactController struct {
+	dir     string
+	mirrors []string
+
+	// checksums caches the sha256 of the artifacts, keyed by path, size and modification time
+	checksums sync.Map
+}
+
+// NewArtifactController create an artifact controller
+func NewArtifactController(cfg *config.Artifacts) *ArtifactController {
+	return &ArtifactController{
+		dir:     cfg.Dir,
+		mirrors: cfg.Mirrors,
+	}
+}
+
+// List returns all the artifacts with their checksums and mirror urls
+func (a *ArtifactController) List(ctx *gin.Context) {
+	versions, err := os.ReadDir(a.dir)
+	if err != nil {
+		types.RenderFailure(ctx, types.InternalServerError, fmt.Errorf("read artifacts dir failed: %w", err))
+		return
+	}
+
+	result := &coordinatorType.ListArtifactsSchema{Artifacts: []*coordinatorType.ArtifactSchema{}}
+	for _, version := range versions {
+		if !version.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(a.dir, version.Name()))
+		if err != nil {
+			types.RenderFailure(ctx, types.InternalServerError, fmt.Errorf("read artifacts version dir failed: %w", err))
+			return
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			artifact, err := a.artifact(version.Name(), file.Name())
+			if err != nil {
+				types.RenderFailure(ctx, types.InternalServerError, err)
+				return
+			}
+			result.Artifacts = append(result.Artifacts, artifact)
+		}
+	}
+	types.RenderSuccess(ctx, result)
+}
+
+// Get serves the artifact file, range requests are supported so that provers can resume the download
+func (a *ArtifactController) Get(ctx *gin.Context) {
+	version, name := ctx.Param("version"), ctx.Param("name")
+	path, err := a.path(version, name)
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, err)
+		return
+	}
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		types.RenderFailure(ctx, types.ErrCoordinatorArtifactNotFound, fmt.Errorf("artifact %s/%s not found", version, name))
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		types.RenderFailure(ctx, types.ErrCoordinatorArtifactNotFound, fmt.Errorf("artifact %s/%s not found", version, name))
+		return
+	}
+
+	checksum, err := a.checksum(path, info)
+	if err != nil {
+		types.RenderFailure(ctx, types.InternalServerError, err)
+		return
+	}
+
+	ctx.Header("ETag", `"`+checksum+`"`)
+	ctx.Header("X-Checksum-Sha256", checksum)
+	http.ServeContent(ctx.Writer, ctx.Request, name, info.ModTime(), f)
+}
+
+func (a *ArtifactController) artifact(version, name string) (*coordinatorType.ArtifactSchema, error) {
+	path, err := a.path(version, name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat artifact %s/%s failed: %w", version, name, err)
+	}
+	checksum, err := a.checksum(path, info)
+	if err != nil {
+		return nil, err
+	}
+
+	var mirrors []string
+	for _, mirror := range a.mirrors {
+		mirrorURL, err := url.JoinPath(mirror, version, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid artifact mirror %s: %w", mirror, err)
+		}
+		mirrors = append(mirrors, mirrorURL)
+	}
+
+	return &coordinatorType.ArtifactSchema{
+		Version:  version,
+		Name:     name,
+		Size:     info.Size(),
+		Checksum: checksum,
+		Mirrors:  mirrors,
+	}, nil
+}
+
+func (a *ArtifactController) path(version, name string) (string, error) {
+	for _, s := range []string{version, name} {
+		if s == "" || s == "." || s == ".." || strings.ContainsAny(s, `/\`) {
+			return "", errors.New("invalid artifact version or name")
+		}
+	}
+	return filepath.Join(a.dir, version, name), nil
+}
+
+type checksumKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (a *ArtifactController) checksum(path string, info os.FileInfo) (string, error) {
+	key := checksumKey{path: path, size: info.Size(), modTime: info.ModTime()}
+	if checksum, ok := a.checksums.Load(key); ok {
+		return checksum.(string), nil
+	}
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("open artifact %s failed: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("read artifact %s failed: %w", path, err)
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+	a.checksums.Store(key, checksum)
+	return checksum, nil
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestArtifactController(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("0123456789")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "v0.1.0"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "v0.1.0", "params20"), content, 0600))
+	sum := sha256.Sum256(content)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	a := NewArtifactController(&config.Artifacts{Dir: dir, Mirrors: []string{"https://mirror.example.com/artifacts"}})
+	router.GET("/artifacts", a.List)
+	router.GET("/artifacts/:version/:name", a.Get)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/artifacts", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), hex.EncodeToString(sum[:]))
+	assert.Contains(t, w.Body.String(), "https://mirror.example.com/artifacts/v0.1.0/params20")
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/artifacts/v0.1.0/params20", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "2345", w.Body.String())
+	assert.Equal(t, hex.EncodeToString(sum[:]), w.Header().Get("X-Checksum-Sha256"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/artifacts/v0.1.0/missing", nil))
+	assert.Contains(t, w.Body.String(), "not found")
+}
@@ -1,12 +1,17 @@
 package api
 
 import (
+	"context"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/observability"
+
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/declinetask"
+	"scroll-tech/coordinator/internal/logic/taskprogress"
 	"scroll-tech/coordinator/internal/logic/verifier"
 )
 
@@ -15,8 +20,16 @@ var (
 	GetTask *GetTaskController
 	// SubmitProof the submit proof controller
 	SubmitProof *SubmitProofController
+	// DeclineTask the decline task controller
+	DeclineTask *DeclineTaskController
+	// TaskProgress the task progress controller
+	TaskProgress *TaskProgressController
 	// Auth the auth controller
 	Auth *AuthController
+	// Admin the admin controller
+	Admin *AdminController
+	// Artifact the circuit artifacts controller
+	Artifact *ArtifactController
 
 	initControllerOnce sync.Once
 )
@@ -30,7 +43,20 @@ func InitController(cfg *config.Config, db *gorm.DB, reg prometheus.Registerer)
 		}
 
 		Auth = NewAuthController(db)
-		GetTask = NewGetTaskController(cfg, db, vf, reg)
+		declineTaskLogic := declinetask.NewDeclineTaskLogic(cfg.ProverManager, db, reg)
+		GetTask = NewGetTaskController(cfg, db, vf, declineTaskLogic, reg)
+		DeclineTask = NewDeclineTaskController(declineTaskLogic)
 		SubmitProof = NewSubmitProofController(cfg, db, vf, reg)
+		taskProgressLogic := taskprogress.NewTaskProgressLogic(cfg.ProverManager, db, reg)
+		TaskProgress = NewTaskProgressController(taskProgressLogic)
+		Admin = NewAdminController(GetTask, taskProgressLogic, db)
+		observability.RegisterStateDumper("coordinator", func() interface{} {
+			ctx, cancel := context.WithTimeout(context.Background(), observability.StateDumpTimeout)
+			defer cancel()
+			return Admin.status(ctx)
+		})
+		if cfg.Artifacts != nil && cfg.Artifacts.Dir != "" {
+			Artifact = NewArtifactController(cfg.Artifacts)
+		}
 	})
 }
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/coordinator/internal/logic/declinetask"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+// DeclineTaskController the decline task api controller
+type DeclineTaskController struct {
+	declineTaskLogic *declinetask.DeclineTaskLogic
+}
+
+// NewDeclineTaskController create the decline task api controller instance
+func NewDeclineTaskController(declineTaskLogic *declinetask.DeclineTaskLogic) *DeclineTaskController {
+	return &DeclineTaskController{
+		declineTaskLogic: declineTaskLogic,
+	}
+}
+
+// DeclineTask prover declines the assigned task with a machine-readable reason
+func (dtc *DeclineTaskController) DeclineTask(ctx *gin.Context) {
+	var param coordinatorType.DeclineTaskParameter
+	if err := ctx.ShouldBind(&param); err != nil {
+		nerr := fmt.Errorf("parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
+		return
+	}
+
+	if err := dtc.declineTaskLogic.Decline(ctx, &param); err != nil {
+		if errors.Is(err, declinetask.ErrInvalidDeclineReason) {
+			types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, err)
+			return
+		}
+		nerr := fmt.Errorf("decline task failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorDeclineTaskFailure, nerr)
+		return
+	}
+	types.RenderSuccess(ctx, nil)
+}
@@ -1,34 +1,98 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/coordinatorpb"
 	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/capacity"
+	"scroll-tech/coordinator/internal/logic/declinetask"
 	"scroll-tech/coordinator/internal/logic/provertask"
 	"scroll-tech/coordinator/internal/logic/verifier"
+	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
 
+// drainRefreshInterval bounds how long a drain mode set through another coordinator instance takes to apply.
+const drainRefreshInterval = 5 * time.Second
+
 // GetTaskController the get prover task api controller
 type GetTaskController struct {
+	cfg         *config.Config
 	proverTasks map[message.ProofType]provertask.ProverTask
+
+	declineTaskLogic *declinetask.DeclineTaskLogic
+	proverCapacity   *capacity.ProverCapacity
+
+	// draining indicates the coordinator stops assigning new tasks, proofs of the in-flight tasks are
+	// still accepted. It is persisted as the pause switch of the assign task operation, so it is
+	// shared by the coordinator instances and survives restarts, and cached for drainRefreshInterval.
+	operationPauseOrm *orm.OperationPause
+	drainMu           sync.Mutex
+	draining          bool
+	drainRefreshedAt  time.Time
+
+	drainModeGauge               prometheus.Gauge
+	drainModeRefreshFailureTotal prometheus.Counter
+
+	proverClockSkew            prometheus.Histogram
+	proverClockSkewExceedTotal prometheus.Counter
+
+	getTaskEncodingTotal *prometheus.CounterVec
 }
 
 // NewGetTaskController create a get prover task controller
-func NewGetTaskController(cfg *config.Config, db *gorm.DB, vf *verifier.Verifier, reg prometheus.Registerer) *GetTaskController {
-	chunkProverTask := provertask.NewChunkProverTask(cfg, db, vf.ChunkVK, reg)
-	batchProverTask := provertask.NewBatchProverTask(cfg, db, vf.BatchVK, reg)
+func NewGetTaskController(cfg *config.Config, db *gorm.DB, vf *verifier.Verifier, declineTaskLogic *declinetask.DeclineTaskLogic, reg prometheus.Registerer) *GetTaskController {
+	proverCapacity := capacity.NewProverCapacity(cfg.ProverManager, reg)
+	chunkProverTask := provertask.NewChunkProverTask(cfg, db, vf.ChunkVK, proverCapacity, reg)
+	batchProverTask := provertask.NewBatchProverTask(cfg, db, vf.BatchVK, proverCapacity, reg)
 
 	ptc := &GetTaskController{
+		cfg:         cfg,
 		proverTasks: make(map[message.ProofType]provertask.ProverTask),
+
+		declineTaskLogic: declineTaskLogic,
+		proverCapacity:   proverCapacity,
+
+		operationPauseOrm: orm.NewOperationPause(db),
+		drainModeGauge: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "coordinator_drain_mode",
+			Help: "Whether the coordinator is in drain mode, 1 means draining.",
+		}),
+		drainModeRefreshFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_drain_mode_refresh_failure_total",
+			Help: "Total number of failures to load the drain mode from the database.",
+		}),
+		proverClockSkew: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Name:    "coordinator_prover_clock_skew_seconds",
+			Help:    "The absolute clock skew between the provers and coordinator estimated from get task requests.",
+			Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300},
+		}),
+		proverClockSkewExceedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_prover_clock_skew_exceed_total",
+			Help: "Total number of get task requests whose prover clock skew exceeds the max clock drift.",
+		}),
+		getTaskEncodingTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_get_task_encoding_total",
+			Help: "Total number of tasks assigned by the encoding of the response, json or protobuf.",
+		}, []string{"encoding"}),
 	}
 
 	ptc.proverTasks[message.ProofTypeChunk] = chunkProverTask
@@ -39,6 +103,11 @@ func NewGetTaskController(cfg *config.Config, db *gorm.DB, vf *verifier.Verifier
 
 // GetTasks get assigned chunk/batch task
 func (ptc *GetTaskController) GetTasks(ctx *gin.Context) {
+	if ptc.IsDraining(ctx) {
+		types.RenderFailure(ctx, types.ErrCoordinatorDrainMode, errors.New("coordinator is in drain mode"))
+		return
+	}
+
 	var getTaskParameter coordinatorType.GetTaskParameter
 	if err := ctx.ShouldBind(&getTaskParameter); err != nil {
 		nerr := fmt.Errorf("prover task parameter invalid, err:%w", err)
@@ -46,6 +115,11 @@ func (ptc *GetTaskController) GetTasks(ctx *gin.Context) {
 		return
 	}
 
+	ptc.checkProverClockSkew(ctx, &getTaskParameter)
+	if publicKey, ok := ctx.Get(coordinatorType.PublicKey); ok {
+		ptc.proverCapacity.Heartbeat(publicKey.(string), getTaskParameter.Parallelism, getTaskParameter.ActiveTasks)
+	}
+
 	proofType := ptc.proofType(&getTaskParameter)
 	proverTask, isExist := ptc.proverTasks[proofType]
 	if !isExist {
@@ -54,6 +128,21 @@ func (ptc *GetTaskController) GetTasks(ctx *gin.Context) {
 		return
 	}
 
+	// the prover recently declined the task type, route the tasks to the other provers
+	if publicKey, ok := ctx.Get(coordinatorType.PublicKey); ok {
+		coolingDown, err := ptc.declineTaskLogic.IsCoolingDown(ctx, publicKey.(string), proofType)
+		if err != nil {
+			nerr := fmt.Errorf("check prover decline cooldown err:%w", err)
+			types.RenderFailure(ctx, types.ErrCoordinatorGetTaskFailure, nerr)
+			return
+		}
+		if coolingDown {
+			nerr := fmt.Errorf("prover declined %s recently", proofType)
+			types.RenderFailure(ctx, types.ErrCoordinatorProverCoolingDown, nerr)
+			return
+		}
+	}
+
 	result, err := proverTask.Assign(ctx, &getTaskParameter)
 	if err != nil {
 		nerr := fmt.Errorf("return prover task err:%w", err)
@@ -67,9 +156,101 @@ func (ptc *GetTaskController) GetTasks(ctx *gin.Context) {
 		return
 	}
 
+	result.ServerTime = time.Now().UnixMilli()
+	switch proofType {
+	case message.ProofTypeChunk:
+		result.DeadlineSec = int64(ptc.cfg.ProverManager.ChunkCollectionTimeSec)
+	case message.ProofTypeBatch:
+		result.DeadlineSec = int64(ptc.cfg.ProverManager.BatchCollectionTimeSec)
+	}
+
+	// the provers supporting protobuf say so in the Accept header, the others keep receiving json
+	if coordinatorpb.Accepts(ctx.GetHeader("Accept")) {
+		ptc.getTaskEncodingTotal.WithLabelValues("protobuf").Inc()
+		resp := coordinatorpb.GetTaskResponse{
+			ErrCode: types.Success,
+			Data: &coordinatorpb.GetTaskData{
+				UUID:        result.UUID,
+				TaskID:      result.TaskID,
+				TaskType:    result.TaskType,
+				TaskData:    result.TaskData,
+				Resources:   result.Resources,
+				ServerTime:  result.ServerTime,
+				DeadlineSec: result.DeadlineSec,
+			},
+		}
+		ctx.Data(http.StatusOK, coordinatorpb.ContentType, resp.Marshal())
+		return
+	}
+	ptc.getTaskEncodingTotal.WithLabelValues("json").Inc()
 	types.RenderSuccess(ctx, result)
 }
 
+// checkProverClockSkew estimates the clock skew of the prover, the get task requests work as the heartbeats of provers.
+func (ptc *GetTaskController) checkProverClockSkew(ctx *gin.Context, para *coordinatorType.GetTaskParameter) {
+	if para.ProverTime == 0 {
+		return
+	}
+
+	skew := time.Duration(math.Abs(float64(utils.NowUTC().UnixMilli()-para.ProverTime))) * time.Millisecond
+	ptc.proverClockSkew.Observe(skew.Seconds())
+
+	if ptc.cfg.ClockCheck == nil || ptc.cfg.ClockCheck.MaxClockDriftMs <= 0 {
+		return
+	}
+	if skew > time.Duration(ptc.cfg.ClockCheck.MaxClockDriftMs)*time.Millisecond {
+		ptc.proverClockSkewExceedTotal.Inc()
+		publicKey, _ := ctx.Get(coordinatorType.PublicKey)
+		proverName, _ := ctx.Get(coordinatorType.ProverName)
+		log.Warn("prover clock skew exceeds the max clock drift", "public key", publicKey, "prover name", proverName, "skew", skew)
+	}
+}
+
+// SetDrainMode enables or disables the drain mode of all coordinator instances
+func (ptc *GetTaskController) SetDrainMode(ctx context.Context, draining bool) error {
+	if err := ptc.operationPauseOrm.SetOperationPause(ctx, orm.OperationAssignTask, draining, ""); err != nil {
+		return err
+	}
+
+	ptc.drainMu.Lock()
+	defer ptc.drainMu.Unlock()
+	ptc.drainRefreshedAt = time.Now()
+	ptc.applyDrainMode(draining)
+	return nil
+}
+
+// IsDraining returns whether the coordinator is in drain mode, the last known mode is kept if it fails to load
+func (ptc *GetTaskController) IsDraining(ctx context.Context) bool {
+	ptc.drainMu.Lock()
+	defer ptc.drainMu.Unlock()
+
+	if time.Since(ptc.drainRefreshedAt) >= drainRefreshInterval {
+		draining, err := ptc.operationPauseOrm.IsOperationPaused(ctx, orm.OperationAssignTask)
+		if err != nil {
+			ptc.drainModeRefreshFailureTotal.Inc()
+			log.Error("failed to load the drain mode", "err", err)
+			return ptc.draining
+		}
+		ptc.drainRefreshedAt = time.Now()
+		ptc.applyDrainMode(draining)
+	}
+	return ptc.draining
+}
+
+func (ptc *GetTaskController) applyDrainMode(draining bool) {
+	if ptc.draining == draining {
+		return
+	}
+	ptc.draining = draining
+	if draining {
+		ptc.drainModeGauge.Set(1)
+		log.Info("coordinator enters drain mode, no new task will be assigned")
+	} else {
+		ptc.drainModeGauge.Set(0)
+		log.Info("coordinator leaves drain mode")
+	}
+}
+
 func (ptc *GetTaskController) proofType(para *coordinatorType.GetTaskParameter) message.ProofType {
 	proofType := message.ProofType(para.TaskType)
 
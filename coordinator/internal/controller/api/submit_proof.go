@@ -2,13 +2,18 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
 
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/coordinatorpb"
 	"scroll-tech/common/types/message"
 
 	"scroll-tech/coordinator/internal/config"
@@ -17,6 +22,9 @@ import (
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
 
+// proofFormFile is the name of the proof file of the multipart submit proof form.
+const proofFormFile = "proof"
+
 // SubmitProofController the submit proof api controller
 type SubmitProofController struct {
 	submitProofReceiverLogic *submitproof.ProofReceiverLogic
@@ -32,7 +40,7 @@ func NewSubmitProofController(cfg *config.Config, db *gorm.DB, vf *verifier.Veri
 // SubmitProof prover submit the proof to coordinator
 func (spc *SubmitProofController) SubmitProof(ctx *gin.Context) {
 	var spp coordinatorType.SubmitProofParameter
-	if err := ctx.ShouldBind(&spp); err != nil {
+	if err := bindSubmitProofParameter(ctx, &spp); err != nil {
 		nerr := fmt.Errorf("parameter invalid, err:%w", err)
 		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
 		return
@@ -74,3 +82,63 @@ func (spc *SubmitProofController) SubmitProof(ctx *gin.Context) {
 	}
 	types.RenderSuccess(ctx, nil)
 }
+
+// bindSubmitProofParameter binds the protobuf encoded parameter sent by the provers which negotiated
+// protobuf in get task, the multipart form uploaded by the polling provers, and the json or form
+// encoded parameter otherwise.
+func bindSubmitProofParameter(ctx *gin.Context, spp *coordinatorType.SubmitProofParameter) error {
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		// the form binding maps the values only, the multipart binding fails on the proof file
+		if err := ctx.ShouldBindWith(spp, binding.Form); err != nil {
+			return err
+		}
+		return bindProofFile(ctx, spp)
+	}
+	if !coordinatorpb.IsContentType(ctx.ContentType()) {
+		return ctx.ShouldBind(spp)
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		return err
+	}
+	var req coordinatorpb.SubmitProofRequest
+	if err = req.Unmarshal(body); err != nil {
+		return err
+	}
+	*spp = coordinatorType.SubmitProofParameter{
+		UUID:        req.UUID,
+		TaskID:      req.TaskID,
+		TaskType:    req.TaskType,
+		Status:      req.Status,
+		Proof:       req.Proof,
+		FailureType: req.FailureType,
+		FailureMsg:  req.FailureMsg,
+	}
+	return binding.Validator.ValidateStruct(spp)
+}
+
+// bindProofFile reads the proof uploaded as the proof file of the multipart form, a form without
+// the file keeps the proof of the form field.
+func bindProofFile(ctx *gin.Context, spp *coordinatorType.SubmitProofParameter) error {
+	fileHeader, err := ctx.FormFile(proofFormFile)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	proof, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	spp.Proof = string(proof)
+	return nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+func TestBindMultipartSubmitProofParameter(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	assert.NoError(t, writer.WriteField("uuid", "uuid"))
+	assert.NoError(t, writer.WriteField("task_id", "task"))
+	assert.NoError(t, writer.WriteField("task_type", "1"))
+	assert.NoError(t, writer.WriteField("status", "0"))
+	file, err := writer.CreateFormFile(proofFormFile, "proof.json")
+	assert.NoError(t, err)
+	_, err = file.Write([]byte(`{"proof":"AAEC"}`))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/coordinator/v1/poll/submit_proof", &body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var spp coordinatorType.SubmitProofParameter
+	assert.NoError(t, bindSubmitProofParameter(ctx, &spp))
+	assert.Equal(t, coordinatorType.SubmitProofParameter{UUID: "uuid", TaskID: "task", TaskType: 1, Proof: `{"proof":"AAEC"}`}, spp)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/coordinator/internal/logic/taskprogress"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+// TaskProgressController the task progress api controller
+type TaskProgressController struct {
+	taskProgressLogic *taskprogress.TaskProgressLogic
+}
+
+// NewTaskProgressController create the task progress api controller instance
+func NewTaskProgressController(taskProgressLogic *taskprogress.TaskProgressLogic) *TaskProgressController {
+	return &TaskProgressController{
+		taskProgressLogic: taskProgressLogic,
+	}
+}
+
+// ReportProgress prover reports the progress of the assigned task
+func (tpc *TaskProgressController) ReportProgress(ctx *gin.Context) {
+	var param coordinatorType.ReportProgressParameter
+	if err := ctx.ShouldBind(&param); err != nil {
+		nerr := fmt.Errorf("parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, nerr)
+		return
+	}
+
+	if err := tpc.taskProgressLogic.Report(ctx, &param); err != nil {
+		if errors.Is(err, taskprogress.ErrInvalidProgress) {
+			types.RenderFailure(ctx, types.ErrCoordinatorParameterInvalidNo, err)
+			return
+		}
+		nerr := fmt.Errorf("report progress failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrCoordinatorReportProgressFailure, nerr)
+		return
+	}
+	types.RenderSuccess(ctx, nil)
+}
@@ -14,6 +14,7 @@ import (
 	"scroll-tech/common/types/message"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/hardfork"
 	"scroll-tech/coordinator/internal/orm"
 )
 
@@ -29,12 +30,18 @@ type Collector struct {
 	chunkOrm      *orm.Chunk
 	batchOrm      *orm.Batch
 	challenge     *orm.Challenge
+	cutover       *hardfork.Cutover
 
 	timeoutBatchCheckerRunTotal     prometheus.Counter
 	batchProverTaskTimeoutTotal     prometheus.Counter
 	timeoutChunkCheckerRunTotal     prometheus.Counter
 	chunkProverTaskTimeoutTotal     prometheus.Counter
 	checkBatchAllChunkReadyRunTotal prometheus.Counter
+	taskLaneDepth                   *prometheus.GaugeVec
+	gcStaleTaskRunTotal             prometheus.Counter
+	gcStaleTaskExpiredTotal         *prometheus.CounterVec
+	gcStaleTaskFailureTotal         *prometheus.CounterVec
+	hardForkStraddlingTasks         prometheus.Gauge
 }
 
 // NewCollector create a collector to cron collect the data to send to prover
@@ -48,6 +55,7 @@ func NewCollector(ctx context.Context, db *gorm.DB, cfg *config.Config, reg prom
 		chunkOrm:        orm.NewChunk(db),
 		batchOrm:        orm.NewBatch(db),
 		challenge:       orm.NewChallenge(db),
+		cutover:         hardfork.NewCutover(cfg.ProverManager, db),
 
 		timeoutBatchCheckerRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_batch_timeout_checker_run_total",
@@ -69,12 +77,39 @@ func NewCollector(ctx context.Context, db *gorm.DB, cfg *config.Config, reg prom
 			Name: "coordinator_check_batch_all_chunk_ready_run_total",
 			Help: "Total number of check batch all chunks ready total",
 		}),
+		taskLaneDepth: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "coordinator_task_lane_depth",
+			Help: "The number of unproved tasks in each priority lane.",
+		}, []string{"task_type", "lane"}),
+		gcStaleTaskRunTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_gc_stale_task_run_total",
+			Help: "Total number of stale task gc run.",
+		}),
+		gcStaleTaskExpiredTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_gc_stale_task_expired_total",
+			Help: "Total number of prover tasks, chunks and batches expired by the stale task gc.",
+		}, []string{"kind"}),
+		gcStaleTaskFailureTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_gc_stale_task_failure_total",
+			Help: "Total number of stale task gc failures.",
+		}, []string{"kind"}),
+		hardForkStraddlingTasks: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "coordinator_hard_fork_straddling_tasks",
+			Help: "The number of chunks and batches straddling a hard fork, which no circuit proves.",
+		}),
 	}
 
 	go c.timeoutBatchProofTask()
 	go c.timeoutChunkProofTask()
 	go c.checkBatchAllChunkReady()
 	go c.cleanupChallenge()
+	go c.updateTaskLaneDepth()
+	if cfg.StaleTaskGC != nil {
+		go c.gcStaleTask()
+	}
+	if len(cfg.ProverManager.HardForks) > 0 {
+		go c.checkHardForkCutover()
+	}
 
 	log.Info("Start coordinator cron successfully.")
 
@@ -183,6 +218,12 @@ func (c *Collector) check(assignedProverTasks []orm.ProverTask, timeout promethe
 					log.Error("update proving status failed failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
 					return err
 				}
+
+				// the requeued task enters the high priority lane, so a flaky proof doesn't delay the finality.
+				if err := c.chunkOrm.UpdatePriorityByHash(c.ctx, assignedProverTask.TaskID, types.TaskLaneHigh, tx); err != nil {
+					log.Error("update priority failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
+					return err
+				}
 			case message.ProofTypeBatch:
 				if err := c.batchOrm.DecreaseActiveAttemptsByHash(c.ctx, assignedProverTask.TaskID, tx); err != nil {
 					log.Error("decrease batch active attempts failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
@@ -193,6 +234,12 @@ func (c *Collector) check(assignedProverTasks []orm.ProverTask, timeout promethe
 					log.Error("update proving status failed failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
 					return err
 				}
+
+				// the requeued task enters the high priority lane, so a flaky proof doesn't delay the finality.
+				if err := c.batchOrm.UpdatePriorityByHash(c.ctx, assignedProverTask.TaskID, types.TaskLaneHigh, tx); err != nil {
+					log.Error("update priority failure", "uuid", assignedProverTask.UUID, "hash", assignedProverTask.TaskID, "pubKey", assignedProverTask.ProverPublicKey, "err", err)
+					return err
+				}
 			}
 
 			return nil
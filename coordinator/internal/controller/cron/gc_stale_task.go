@@ -0,0 +1,64 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/utils"
+)
+
+// gcStaleTask expires the prover tasks, chunks and batches stuck in assigned status beyond their max ages,
+// e.g. when the timeout checker keeps failing on them, so they don't pin the attempts forever.
+func (c *Collector) gcStaleTask() {
+	defer func() {
+		if err := recover(); err != nil {
+			nerr := fmt.Errorf("gc stale task panic error: %v", err)
+			log.Warn(nerr.Error())
+		}
+	}()
+
+	cfg := c.cfg.StaleTaskGC
+	ticker := time.NewTicker(time.Duration(cfg.CheckIntervalSec) * time.Second)
+	for {
+		select {
+		case <-ticker.C:
+			c.gcStaleTaskRunTotal.Inc()
+			now := utils.NowUTC()
+
+			if cfg.ProverTaskMaxAgeSec > 0 {
+				expired, err := c.proverTaskOrm.ExpireAssignedProverTasks(c.ctx, now.Add(-time.Duration(cfg.ProverTaskMaxAgeSec)*time.Second))
+				c.recordStaleTaskGC("prover_task", expired, err)
+			}
+			if cfg.ChunkMaxAgeSec > 0 {
+				expired, err := c.chunkOrm.ExpireAssignedChunks(c.ctx, now.Add(-time.Duration(cfg.ChunkMaxAgeSec)*time.Second))
+				c.recordStaleTaskGC("chunk", expired, err)
+			}
+			if cfg.BatchMaxAgeSec > 0 {
+				expired, err := c.batchOrm.ExpireAssignedBatches(c.ctx, now.Add(-time.Duration(cfg.BatchMaxAgeSec)*time.Second))
+				c.recordStaleTaskGC("batch", expired, err)
+			}
+		case <-c.ctx.Done():
+			if c.ctx.Err() != nil {
+				log.Error("manager context canceled with error", "error", c.ctx.Err())
+			}
+			return
+		case <-c.stopTimeoutChan:
+			log.Info("the coordinator run loop exit")
+			return
+		}
+	}
+}
+
+func (c *Collector) recordStaleTaskGC(kind string, expired int64, err error) {
+	if err != nil {
+		c.gcStaleTaskFailureTotal.WithLabelValues(kind).Inc()
+		log.Error("gc stale task failure", "kind", kind, "error", err)
+		return
+	}
+	if expired > 0 {
+		c.gcStaleTaskExpiredTotal.WithLabelValues(kind).Add(float64(expired))
+		log.Warn("expired stale tasks stuck in assigned status", "kind", kind, "count", expired)
+	}
+}
@@ -0,0 +1,49 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+)
+
+// checkHardForkCutover reports the chunks and batches straddling a hard fork, the coordinators of the circuits
+// dispatch neither of them, so they have to be split at the hard fork.
+func (c *Collector) checkHardForkCutover() {
+	defer func() {
+		if err := recover(); err != nil {
+			nerr := fmt.Errorf("check hard fork cutover panic error: %v", err)
+			log.Warn(nerr.Error())
+		}
+	}()
+
+	ticker := time.NewTicker(time.Minute)
+	for {
+		select {
+		case <-ticker.C:
+			err := c.cutover.Check(c.ctx)
+			if err == nil {
+				c.hardForkStraddlingTasks.Set(0)
+				continue
+			}
+			var joined interface{ Unwrap() []error }
+			if !errors.As(err, &joined) {
+				log.Error("failed to check hard fork cutover", "error", err)
+				continue
+			}
+			c.hardForkStraddlingTasks.Set(float64(len(joined.Unwrap())))
+			for _, straddleErr := range joined.Unwrap() {
+				log.Error("hard fork cutover broken", "error", straddleErr)
+			}
+		case <-c.ctx.Done():
+			if c.ctx.Err() != nil {
+				log.Error("manager context canceled with error", "error", c.ctx.Err())
+			}
+			return
+		case <-c.stopTimeoutChan:
+			log.Info("the coordinator run loop exit")
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+)
+
+func (c *Collector) updateTaskLaneDepth() {
+	defer func() {
+		if err := recover(); err != nil {
+			nerr := fmt.Errorf("update task lane depth panic error: %v", err)
+			log.Warn(nerr.Error())
+		}
+	}()
+
+	ticker := time.NewTicker(time.Second * 30)
+	for {
+		select {
+		case <-ticker.C:
+			chunkCounts, err := c.chunkOrm.GetUnprovedCountByLane(c.ctx)
+			if err != nil {
+				log.Error("get chunk lane depth failure", "error", err)
+			} else {
+				c.setTaskLaneDepth(message.ProofTypeChunk, chunkCounts)
+			}
+
+			batchCounts, err := c.batchOrm.GetUnprovedCountByLane(c.ctx)
+			if err != nil {
+				log.Error("get batch lane depth failure", "error", err)
+			} else {
+				c.setTaskLaneDepth(message.ProofTypeBatch, batchCounts)
+			}
+		case <-c.ctx.Done():
+			if c.ctx.Err() != nil {
+				log.Error("manager context canceled with error", "error", c.ctx.Err())
+			}
+			return
+		case <-c.stopTimeoutChan:
+			log.Info("the coordinator run loop exit")
+			return
+		}
+	}
+}
+
+func (c *Collector) setTaskLaneDepth(proofType message.ProofType, counts map[types.TaskLane]int64) {
+	for _, lane := range []types.TaskLane{types.TaskLaneNormal, types.TaskLaneHigh} {
+		c.taskLaneDepth.WithLabelValues(proofType.String(), lane.String()).Set(float64(counts[lane]))
+	}
+}
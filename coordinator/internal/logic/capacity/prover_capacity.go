@@ -0,0 +1,138 @@
+package capacity
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+// defaultHeartbeatStaleTime is the default duration without heartbeat after which a prover leaves the fleet
+const defaultHeartbeatStaleTime = 5 * time.Minute
+
+// defaultParallelism is the parallelism of the provers not declaring one, a single-GPU prover proves one task at a time.
+const defaultParallelism = 1
+
+type proverState struct {
+	parallelism int
+	activeTasks int
+	lastSeen    time.Time
+}
+
+// ProverCapacity tracks the parallelism and the active tasks declared by the provers in their heartbeats,
+// the get task requests, so no prover is assigned more tasks than it can prove at the same time.
+type ProverCapacity struct {
+	staleTime time.Duration
+
+	mu      sync.Mutex
+	provers map[string]*proverState
+
+	atCapacityTotal prometheus.Counter
+}
+
+// NewProverCapacity create a prover capacity tracker
+func NewProverCapacity(cfg *config.ProverManager, reg prometheus.Registerer) *ProverCapacity {
+	staleTime := defaultHeartbeatStaleTime
+	if cfg.HeartbeatStaleSec > 0 {
+		staleTime = time.Duration(cfg.HeartbeatStaleSec) * time.Second
+	}
+
+	c := &ProverCapacity{
+		staleTime: staleTime,
+		provers:   make(map[string]*proverState),
+		atCapacityTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "coordinator_prover_at_capacity_total",
+			Help: "Total number of get task requests rejected because the prover is at capacity.",
+		}),
+	}
+	promauto.With(reg).NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "coordinator_prover_fleet_capacity",
+		Help: "The number of tasks the live provers can prove at the same time.",
+	}, func() float64 {
+		capacity, _ := c.fleet()
+		return float64(capacity)
+	})
+	promauto.With(reg).NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "coordinator_prover_fleet_active_tasks",
+		Help: "The number of tasks being proved by the live provers.",
+	}, func() float64 {
+		_, active := c.fleet()
+		return float64(active)
+	})
+	promauto.With(reg).NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "coordinator_prover_fleet_utilization",
+		Help: "The ratio of the active tasks to the capacity of the live provers.",
+	}, func() float64 {
+		capacity, active := c.fleet()
+		if capacity == 0 {
+			return 0
+		}
+		return float64(active) / float64(capacity)
+	})
+	return c
+}
+
+// Heartbeat records the parallelism and the active tasks declared by the prover.
+func (c *ProverCapacity) Heartbeat(publicKey string, parallelism, activeTasks int) {
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+	if activeTasks < 0 {
+		activeTasks = 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.provers[publicKey] = &proverState{parallelism: parallelism, activeTasks: activeTasks, lastSeen: time.Now()}
+}
+
+// HasCapacity reports whether the prover can be assigned another task, the active tasks of the prover
+// are the most of the assigned tasks and the declared ones, it returns the active tasks and the parallelism.
+func (c *ProverCapacity) HasCapacity(publicKey string, assignedTasks int) (bool, int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	parallelism, activeTasks := defaultParallelism, assignedTasks
+	if state, ok := c.provers[publicKey]; ok {
+		parallelism = state.parallelism
+		if state.activeTasks > activeTasks {
+			activeTasks = state.activeTasks
+		}
+		// keep the active tasks until the next heartbeat
+		state.activeTasks = activeTasks
+	}
+	if activeTasks >= parallelism {
+		c.atCapacityTotal.Inc()
+		return false, activeTasks, parallelism
+	}
+	return true, activeTasks, parallelism
+}
+
+// Assigned records a task assigned to the prover.
+func (c *ProverCapacity) Assigned(publicKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if state, ok := c.provers[publicKey]; ok {
+		state.activeTasks++
+	}
+}
+
+// fleet returns the capacity and the active tasks of the live provers, the stale provers are removed.
+func (c *ProverCapacity) fleet() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var capacity, active int
+	for publicKey, state := range c.provers {
+		if time.Since(state.lastSeen) > c.staleTime {
+			delete(c.provers, publicKey)
+			continue
+		}
+		capacity += state.parallelism
+		active += state.activeTasks
+	}
+	return capacity, active
+}
@@ -0,0 +1,55 @@
+package capacity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+func TestProverCapacity(t *testing.T) {
+	c := NewProverCapacity(&config.ProverManager{}, prometheus.NewRegistry())
+
+	// a prover without heartbeat proves one task at a time
+	ok, activeTasks, parallelism := c.HasCapacity("single", 0)
+	assert.True(t, ok)
+	assert.Equal(t, 0, activeTasks)
+	assert.Equal(t, 1, parallelism)
+	ok, _, _ = c.HasCapacity("single", 1)
+	assert.False(t, ok)
+
+	// a busy single-GPU prover isn't assigned a second task
+	c.Heartbeat("single", 0, 1)
+	ok, activeTasks, parallelism = c.HasCapacity("single", 0)
+	assert.False(t, ok)
+	assert.Equal(t, 1, activeTasks)
+	assert.Equal(t, 1, parallelism)
+
+	// a multi-GPU prover is assigned tasks up to its parallelism
+	c.Heartbeat("multi", 2, 0)
+	ok, _, _ = c.HasCapacity("multi", 0)
+	assert.True(t, ok)
+	c.Assigned("multi")
+	ok, activeTasks, _ = c.HasCapacity("multi", 0)
+	assert.True(t, ok)
+	assert.Equal(t, 1, activeTasks)
+	c.Assigned("multi")
+	ok, activeTasks, parallelism = c.HasCapacity("multi", 2)
+	assert.False(t, ok)
+	assert.Equal(t, 2, activeTasks)
+	assert.Equal(t, 2, parallelism)
+
+	capacity, active := c.fleet()
+	assert.Equal(t, 3, capacity)
+	assert.Equal(t, 3, active)
+
+	// the stale provers leave the fleet
+	c.provers["single"].lastSeen = time.Now().Add(-2 * defaultHeartbeatStaleTime)
+	capacity, active = c.fleet()
+	assert.Equal(t, 2, capacity)
+	assert.Equal(t, 2, active)
+	assert.NotContains(t, c.provers, "single")
+}
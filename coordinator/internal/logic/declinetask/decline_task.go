@@ -0,0 +1,123 @@
+package declinetask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+// defaultDeclineCooldown is the default duration a prover isn't assigned the declined task type
+const defaultDeclineCooldown = 5 * time.Minute
+
+var (
+	// ErrProverTaskNotAssigned the declined prover task is not in assigned status
+	ErrProverTaskNotAssigned = errors.New("prover task is not assigned")
+	// ErrInvalidDeclineReason the decline reason is undefined
+	ErrInvalidDeclineReason = errors.New("invalid decline reason")
+)
+
+// DeclineTaskLogic handles the tasks declined by provers. A declined task isn't treated as a failure,
+// its attempts are restored and the prover isn't assigned the same task type until the cooldown ends.
+// The cooldown is derived from the declined prover tasks in the database, so it holds across the
+// coordinator instances and restarts.
+type DeclineTaskLogic struct {
+	db            *gorm.DB
+	chunkOrm      *orm.Chunk
+	batchOrm      *orm.Batch
+	proverTaskOrm *orm.ProverTask
+
+	cooldown time.Duration
+
+	declinedTotal *prometheus.CounterVec
+}
+
+// NewDeclineTaskLogic create a decline task logic
+func NewDeclineTaskLogic(cfg *config.ProverManager, db *gorm.DB, reg prometheus.Registerer) *DeclineTaskLogic {
+	cooldown := defaultDeclineCooldown
+	if cfg.DeclineCooldownSec > 0 {
+		cooldown = time.Duration(cfg.DeclineCooldownSec) * time.Second
+	}
+
+	return &DeclineTaskLogic{
+		db:            db,
+		chunkOrm:      orm.NewChunk(db),
+		batchOrm:      orm.NewBatch(db),
+		proverTaskOrm: orm.NewProverTask(db),
+		cooldown:      cooldown,
+		declinedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_prover_task_declined_total",
+			Help: "Total number of tasks declined by provers.",
+		}, []string{"task_type", "reason"}),
+	}
+}
+
+// Decline releases the task declined by the prover
+func (l *DeclineTaskLogic) Decline(ctx *gin.Context, param *coordinatorType.DeclineTaskParameter) error {
+	reason := message.TaskDeclineReason(param.Reason)
+	switch reason {
+	case message.TaskDeclineReasonUnsupportedCircuit, message.TaskDeclineReasonInsufficientMemory, message.TaskDeclineReasonArtifactMissing:
+	default:
+		return fmt.Errorf("%w: %d", ErrInvalidDeclineReason, param.Reason)
+	}
+
+	publicKey, publicKeyExist := ctx.Get(coordinatorType.PublicKey)
+	if !publicKeyExist {
+		return errors.New("get public key from context failed")
+	}
+	proverName, _ := ctx.Get(coordinatorType.ProverName)
+
+	proverTask, err := l.proverTaskOrm.GetProverTaskByUUIDAndPublicKey(ctx, param.UUID, publicKey.(string))
+	if err != nil {
+		return err
+	}
+	if proverTask.TaskID != param.TaskID || proverTask.TaskType != int16(param.TaskType) {
+		return fmt.Errorf("prover task mismatch, uuid: %s, task id: %s", param.UUID, param.TaskID)
+	}
+	if types.ProverProveStatus(proverTask.ProvingStatus) != types.ProverAssigned {
+		return fmt.Errorf("%w, uuid: %s, status: %s", ErrProverTaskNotAssigned, param.UUID, types.ProverProveStatus(proverTask.ProvingStatus))
+	}
+
+	proofType := message.ProofType(proverTask.TaskType)
+	err = l.db.Transaction(func(tx *gorm.DB) error {
+		if err := l.proverTaskOrm.UpdateProverTaskProvingStatusAndFailureType(ctx, proverTask.UUID, types.ProverTaskDeclined, types.ProverTaskFailureTypeUndefined, tx); err != nil {
+			return err
+		}
+		switch proofType {
+		case message.ProofTypeChunk:
+			return l.chunkOrm.DecreaseAttemptsByHash(ctx, proverTask.TaskID, tx)
+		case message.ProofTypeBatch:
+			return l.batchOrm.DecreaseAttemptsByHash(ctx, proverTask.TaskID, tx)
+		default:
+			return fmt.Errorf("invalid task type: %d", proverTask.TaskType)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	l.declinedTotal.WithLabelValues(proofType.String(), reason.String()).Inc()
+
+	log.Info("prover declined task", "uuid", param.UUID, "task id", param.TaskID, "task type", proofType,
+		"public key", publicKey, "prover name", proverName, "reason", reason, "message", param.Message)
+	return nil
+}
+
+// IsCoolingDown returns whether the prover declined a task of the type within the cooldown
+func (l *DeclineTaskLogic) IsCoolingDown(ctx context.Context, publicKey string, proofType message.ProofType) (bool, error) {
+	return l.proverTaskOrm.IsProverDeclinedSince(ctx, publicKey, proofType, utils.NowUTC().Add(-l.cooldown))
+}
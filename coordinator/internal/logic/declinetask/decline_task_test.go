@@ -0,0 +1,145 @@
+package declinetask
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/docker"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+	"scroll-tech/database/migrate"
+)
+
+var (
+	base *docker.App
+
+	db *gorm.DB
+)
+
+func TestMain(m *testing.M) {
+	t := &testing.T{}
+	base = docker.NewDockerApp()
+	base.RunDBImage(t)
+	var err error
+	db, err = database.InitDB(
+		&database.Config{
+			DSN:        base.DBConfig.DSN,
+			DriverName: base.DBConfig.DriverName,
+			MaxOpenNum: base.DBConfig.MaxOpenNum,
+			MaxIdleNum: base.DBConfig.MaxIdleNum,
+		},
+	)
+	assert.NoError(t, err)
+	defer func() {
+		sqlDB, err := db.DB()
+		assert.NoError(t, err)
+		sqlDB.Close()
+		base.Free()
+	}()
+	m.Run()
+}
+
+func newProverContext(publicKey string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/coordinator/v1/decline_task", nil)
+	ctx.Set(coordinatorType.PublicKey, publicKey)
+	ctx.Set(coordinatorType.ProverName, "prover-"+publicKey)
+	return ctx
+}
+
+func TestDeclineTask(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	templateBlockTrace, err := os.ReadFile("../../../../common/testdata/blockTrace_02.json")
+	assert.NoError(t, err)
+	wrappedBlock := &types.WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(templateBlockTrace, wrappedBlock))
+
+	chunkOrm := orm.NewChunk(db)
+	dbChunk, err := chunkOrm.InsertChunk(context.Background(), &types.Chunk{Blocks: []*types.WrappedBlock{wrappedBlock}})
+	assert.NoError(t, err)
+	rowsAffected, err := chunkOrm.UpdateChunkAttempts(context.Background(), dbChunk.Index, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+
+	proverTaskOrm := orm.NewProverTask(db)
+	assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &orm.ProverTask{
+		TaskType:        int16(message.ProofTypeChunk),
+		TaskID:          dbChunk.Hash,
+		ProverName:      "prover-0",
+		ProverPublicKey: "0",
+		ProvingStatus:   int16(types.ProverAssigned),
+		AssignedAt:      utils.NowUTC(),
+	}))
+	proverTasks, err := proverTaskOrm.GetProverTasksByHashes(context.Background(), message.ProofTypeChunk, []string{dbChunk.Hash})
+	assert.NoError(t, err)
+	assert.Len(t, proverTasks, 1)
+	param := &coordinatorType.DeclineTaskParameter{
+		UUID:     proverTasks[0].UUID.String(),
+		TaskID:   dbChunk.Hash,
+		TaskType: int(message.ProofTypeChunk),
+		Reason:   int(message.TaskDeclineReasonInsufficientMemory),
+	}
+
+	logic := NewDeclineTaskLogic(&config.ProverManager{DeclineCooldownSec: 60}, db, nil)
+	coolingDown, err := logic.IsCoolingDown(context.Background(), "0", message.ProofTypeChunk)
+	assert.NoError(t, err)
+	assert.False(t, coolingDown)
+
+	// an undefined reason is rejected before the task is touched
+	invalidParam := *param
+	invalidParam.Reason = 100
+	assert.ErrorIs(t, logic.Decline(newProverContext("0"), &invalidParam), ErrInvalidDeclineReason)
+
+	// another prover can't decline the task
+	assert.Error(t, logic.Decline(newProverContext("1"), param))
+
+	assert.NoError(t, logic.Decline(newProverContext("0"), param))
+	proverTasks, err = proverTaskOrm.GetProverTasksByHashes(context.Background(), message.ProofTypeChunk, []string{dbChunk.Hash})
+	assert.NoError(t, err)
+	assert.Equal(t, int16(types.ProverTaskDeclined), proverTasks[0].ProvingStatus)
+	var declinedChunk orm.Chunk
+	assert.NoError(t, db.Where("hash = ?", dbChunk.Hash).First(&declinedChunk).Error)
+	assert.Equal(t, int16(0), declinedChunk.ActiveAttempts)
+	assert.Equal(t, int16(0), declinedChunk.TotalAttempts)
+
+	// the declined task isn't assigned anymore
+	assert.ErrorIs(t, logic.Decline(newProverContext("0"), param), ErrProverTaskNotAssigned)
+
+	// the cooldown applies to the prover and the task type only, and is shared by the logic instances
+	otherLogic := NewDeclineTaskLogic(&config.ProverManager{DeclineCooldownSec: 60}, db, nil)
+	coolingDown, err = otherLogic.IsCoolingDown(context.Background(), "0", message.ProofTypeChunk)
+	assert.NoError(t, err)
+	assert.True(t, coolingDown)
+	coolingDown, err = otherLogic.IsCoolingDown(context.Background(), "0", message.ProofTypeBatch)
+	assert.NoError(t, err)
+	assert.False(t, coolingDown)
+	coolingDown, err = otherLogic.IsCoolingDown(context.Background(), "1", message.ProofTypeChunk)
+	assert.NoError(t, err)
+	assert.False(t, coolingDown)
+
+	// the cooldown ends
+	assert.NoError(t, db.Model(&orm.ProverTask{}).Where("uuid = ?", proverTasks[0].UUID).
+		UpdateColumn("updated_at", utils.NowUTC().Add(-2*time.Minute)).Error)
+	coolingDown, err = otherLogic.IsCoolingDown(context.Background(), "0", message.ProofTypeChunk)
+	assert.NoError(t, err)
+	assert.False(t, coolingDown)
+}
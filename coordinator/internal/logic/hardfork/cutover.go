@@ -0,0 +1,109 @@
+package hardfork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+)
+
+// ErrTaskStraddlesHardFork a chunk or a batch includes the blocks of two hard forks, neither circuit proves it.
+var ErrTaskStraddlesHardFork = errors.New("task straddles hard fork")
+
+// Cutover dispatches the tasks of the hard fork of the coordinator only, so the tasks before a hard fork go to the
+// provers of the old circuit and the ones after it to the provers of the new circuit, through the coordinator of
+// each circuit sharing the database.
+//
+// The chunks are ranged by their block numbers, the batches by their chunk indexes starting from the first chunk
+// of the hard fork. A chunk or a batch straddling a hard fork is in no range, Check reports it.
+type Cutover struct {
+	hardForks []*config.HardFork
+	// the blocks [fromBlock, toBlock) of the hard fork of the coordinator, toBlock is 0 for the latest hard fork
+	fromBlock uint64
+	toBlock   uint64
+
+	chunkOrm *orm.Chunk
+	batchOrm *orm.Batch
+}
+
+// NewCutover creates the cutover of the hard fork of the coordinator, all the tasks are in range without hard forks.
+func NewCutover(cfg *config.ProverManager, db *gorm.DB) *Cutover {
+	c := &Cutover{hardForks: cfg.HardForks, chunkOrm: orm.NewChunk(db), batchOrm: orm.NewBatch(db)}
+	for i, fork := range cfg.HardForks {
+		if fork.Name != cfg.HardForkName {
+			continue
+		}
+		c.fromBlock = fork.BlockNumber
+		if i+1 < len(cfg.HardForks) {
+			c.toBlock = cfg.HardForks[i+1].BlockNumber
+		}
+	}
+	return c
+}
+
+// ChunkRange returns the block numbers of the chunks of the hard fork.
+func (c *Cutover) ChunkRange() orm.TaskRange {
+	return orm.TaskRange{From: c.fromBlock, To: c.toBlock}
+}
+
+// BatchRange returns the chunk indexes of the batches of the hard fork, ok is false if the hard fork has no chunk yet.
+func (c *Cutover) BatchRange(ctx context.Context) (chunkRange orm.TaskRange, ok bool, err error) {
+	if c.fromBlock > 0 {
+		var found bool
+		chunkRange.From, found, err = c.chunkOrm.GetFirstChunkIndexFromBlock(ctx, c.fromBlock)
+		if err != nil {
+			return orm.TaskRange{}, false, err
+		}
+		if !found {
+			return orm.TaskRange{}, false, nil
+		}
+	}
+	if c.toBlock > 0 {
+		// no chunk of the next hard fork yet, so no batch of this hard fork includes one
+		chunkRange.To, _, err = c.chunkOrm.GetFirstChunkIndexFromBlock(ctx, c.toBlock)
+		if err != nil {
+			return orm.TaskRange{}, false, err
+		}
+	}
+	return chunkRange, true, nil
+}
+
+// Check returns an error wrapping ErrTaskStraddlesHardFork if a chunk or a batch straddles any of the hard forks.
+func (c *Cutover) Check(ctx context.Context) error {
+	var errs []error
+	for _, fork := range c.hardForks {
+		if fork.BlockNumber == 0 {
+			continue
+		}
+
+		chunks, err := c.chunkOrm.GetChunksStraddlingBlock(ctx, fork.BlockNumber)
+		if err != nil {
+			return err
+		}
+		for _, chunk := range chunks {
+			errs = append(errs, fmt.Errorf("%w: chunk %v of blocks %v to %v straddles hard fork %v at block %v, split it at the hard fork",
+				ErrTaskStraddlesHardFork, chunk.Index, chunk.StartBlockNumber, chunk.EndBlockNumber, fork.Name, fork.BlockNumber))
+		}
+
+		firstChunkIndex, found, err := c.chunkOrm.GetFirstChunkIndexFromBlock(ctx, fork.BlockNumber)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		batches, err := c.batchOrm.GetBatchesStraddlingChunk(ctx, firstChunkIndex)
+		if err != nil {
+			return err
+		}
+		for _, batch := range batches {
+			errs = append(errs, fmt.Errorf("%w: batch %v of chunks %v to %v straddles hard fork %v at chunk %v, split it at the hard fork",
+				ErrTaskStraddlesHardFork, batch.Index, batch.StartChunkIndex, batch.EndChunkIndex, fork.Name, firstChunkIndex))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -18,6 +18,8 @@ import (
 	"scroll-tech/common/utils"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/capacity"
+	"scroll-tech/coordinator/internal/logic/hardfork"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -31,15 +33,17 @@ type BatchProverTask struct {
 }
 
 // NewBatchProverTask new a batch collector
-func NewBatchProverTask(cfg *config.Config, db *gorm.DB, vk string, reg prometheus.Registerer) *BatchProverTask {
+func NewBatchProverTask(cfg *config.Config, db *gorm.DB, vk string, proverCapacity *capacity.ProverCapacity, reg prometheus.Registerer) *BatchProverTask {
 	bp := &BatchProverTask{
 		BaseProverTask: BaseProverTask{
-			vk:            vk,
-			db:            db,
-			cfg:           cfg,
-			chunkOrm:      orm.NewChunk(db),
-			batchOrm:      orm.NewBatch(db),
-			proverTaskOrm: orm.NewProverTask(db),
+			vk:             vk,
+			proverCapacity: proverCapacity,
+			cutover:        hardfork.NewCutover(cfg.ProverManager, db),
+			db:             db,
+			cfg:            cfg,
+			chunkOrm:       orm.NewChunk(db),
+			batchOrm:       orm.NewBatch(db),
+			proverTaskOrm:  orm.NewProverTask(db),
 		},
 		batchAttemptsExceedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "coordinator_batch_attempts_exceed_total",
@@ -62,11 +66,20 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 
 	maxActiveAttempts := bp.cfg.ProverManager.ProversPerSession
 	maxTotalAttempts := bp.cfg.ProverManager.SessionAttempts
+	chunkRange, ok, err := bp.cutover.BatchRange(ctx)
+	if err != nil {
+		log.Error("failed to get the chunk range of the hard fork", "height", getTaskParameter.ProverHeight, "err", err)
+		return nil, ErrCoordinatorInternalFailure
+	}
+	if !ok {
+		log.Debug("no chunk of the hard fork yet", "hard fork", bp.cfg.ProverManager.HardForkName)
+		return nil, nil
+	}
 	var batchTask *orm.Batch
 	for i := 0; i < 5; i++ {
 		var getTaskError error
 		var tmpBatchTask *orm.Batch
-		tmpBatchTask, getTaskError = bp.batchOrm.GetAssignedBatch(ctx, maxActiveAttempts, maxTotalAttempts)
+		tmpBatchTask, getTaskError = bp.batchOrm.GetAssignedBatch(ctx, chunkRange, maxActiveAttempts, maxTotalAttempts)
 		if getTaskError != nil {
 			log.Error("failed to get assigned batch proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 			return nil, ErrCoordinatorInternalFailure
@@ -75,7 +88,7 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 		// Why here need get again? In order to support a task can assign to multiple prover, need also assign `ProvingTaskAssigned`
 		// batch to prover. But use `proving_status in (1, 2)` will not use the postgres index. So need split the sql.
 		if tmpBatchTask == nil {
-			tmpBatchTask, getTaskError = bp.batchOrm.GetUnassignedBatch(ctx, maxActiveAttempts, maxTotalAttempts)
+			tmpBatchTask, getTaskError = bp.batchOrm.GetUnassignedBatch(ctx, chunkRange, maxActiveAttempts, maxTotalAttempts)
 			if getTaskError != nil {
 				log.Error("failed to get unassigned batch proving tasks", "height", getTaskParameter.ProverHeight, "err", getTaskError)
 				return nil, ErrCoordinatorInternalFailure
@@ -136,6 +149,7 @@ func (bp *BatchProverTask) Assign(ctx *gin.Context, getTaskParameter *coordinato
 	}
 
 	bp.batchTaskGetTaskTotal.Inc()
+	bp.proverCapacity.Assigned(taskCtx.PublicKey)
 
 	return taskMsg, nil
 }
@@ -179,10 +193,11 @@ func (bp *BatchProverTask) formatProverTask(ctx context.Context, task *orm.Prove
 	}
 
 	taskMsg := &coordinatorType.GetTaskSchema{
-		UUID:     task.UUID.String(),
-		TaskID:   task.TaskID,
-		TaskType: int(message.ProofTypeBatch),
-		TaskData: string(chunkProofsBytes),
+		UUID:      task.UUID.String(),
+		TaskID:    task.TaskID,
+		TaskType:  int(message.ProofTypeBatch),
+		TaskData:  string(chunkProofsBytes),
+		Resources: bp.cfg.ProverManager.BatchTaskResources,
 	}
 	return taskMsg, nil
 }
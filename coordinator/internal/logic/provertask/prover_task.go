@@ -9,6 +9,8 @@ import (
 	"scroll-tech/common/version"
 
 	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/logic/capacity"
+	"scroll-tech/coordinator/internal/logic/hardfork"
 	"scroll-tech/coordinator/internal/orm"
 	coordinatorType "scroll-tech/coordinator/internal/types"
 )
@@ -24,6 +26,10 @@ type BaseProverTask struct {
 	db  *gorm.DB
 	vk  string
 
+	proverCapacity *capacity.ProverCapacity
+	// dispatches the tasks of the hard fork of the coordinator only
+	cutover *hardfork.Cutover
+
 	batchOrm      *orm.Batch
 	chunkOrm      *orm.Chunk
 	blockOrm      *orm.L2Block
@@ -68,13 +74,13 @@ func (b *BaseProverTask) checkParameter(ctx *gin.Context, getTaskParameter *coor
 		return nil, fmt.Errorf("incompatible vk. please check your params files or config files")
 	}
 
-	isAssigned, err := b.proverTaskOrm.IsProverAssigned(ctx, publicKey.(string))
+	assignedTasks, err := b.proverTaskOrm.CountProverAssignedTasks(ctx, publicKey.(string))
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if prover is assigned a task: %w", err)
+		return nil, fmt.Errorf("failed to count the tasks assigned to prover: %w", err)
 	}
 
-	if isAssigned {
-		return nil, fmt.Errorf("prover with publicKey %s is already assigned a task", publicKey)
+	if ok, activeTasks, parallelism := b.proverCapacity.HasCapacity(ptc.PublicKey, int(assignedTasks)); !ok {
+		return nil, fmt.Errorf("prover with publicKey %s is at capacity, active tasks: %d, parallelism: %d", publicKey, activeTasks, parallelism)
 	}
 	return &ptc, nil
 }
@@ -47,6 +47,7 @@ type ProofReceiverLogic struct {
 	chunkOrm      *orm.Chunk
 	batchOrm      *orm.Batch
 	proverTaskOrm *orm.ProverTask
+	l2BlockOrm    *orm.L2Block
 
 	db  *gorm.DB
 	cfg *config.ProverManager
@@ -71,6 +72,7 @@ func NewSubmitProofReceiverLogic(cfg *config.ProverManager, db *gorm.DB, vf *ver
 		chunkOrm:      orm.NewChunk(db),
 		batchOrm:      orm.NewBatch(db),
 		proverTaskOrm: orm.NewProverTask(db),
+		l2BlockOrm:    orm.NewL2Block(db),
 
 		cfg: cfg,
 		db:  db,
@@ -179,6 +181,12 @@ func (m *ProofReceiverLogic) HandleZkProof(ctx *gin.Context, proofMsg *message.P
 
 		log.Info("proof verified by coordinator failed", "proof id", proofMsg.ID, "prover name", proverTask.ProverName,
 			"prover pk", pk, "prove type", proofMsg.Type, "proof time", proofTimeSec, "error", verifyErr)
+		if proofMsg.ChunkProof != nil && proofMsg.ChunkProof.WitnessSource != nil {
+			source := proofMsg.ChunkProof.WitnessSource
+			log.Info("witness source of the failed chunk proof", "proof id", proofMsg.ID, "node versions", source.NodeVersions,
+				"chain id", source.ChainID, "prev state root", source.PrevStateRoot, "post state root", source.PostStateRoot,
+				"witness reduction", source.WitnessReduction)
+		}
 
 		if verifyErr != nil {
 			return ErrValidatorFailureVerifiedFailed
@@ -322,6 +330,13 @@ func (m *ProofReceiverLogic) updateProofStatus(ctx context.Context, proverTask *
 				log.Error("failed to update chunk proving_status as failed", "hash", proverTask.TaskID, "error", err)
 				return err
 			}
+			// the prover failures are counted against the blocks of the chunk, not the coordinator's own errors
+			if status == types.ProverProofInvalid && failureType != types.ProverTaskFailureTypeServerError {
+				if err := m.l2BlockOrm.IncreaseChunkProposalFailuresByChunkHash(ctx, proverTask.TaskID, tx); err != nil {
+					log.Error("failed to record the chunk prover failure for the blocks", "hash", proverTask.TaskID, "error", err)
+					return err
+				}
+			}
 		case message.ProofTypeBatch:
 			if err := m.batchOrm.DecreaseActiveAttemptsByHash(ctx, proverTask.TaskID, tx); err != nil {
 				log.Error("failed to update batch proving_status as failed", "hash", proverTask.TaskID, "error", err)
@@ -0,0 +1,140 @@
+package taskprogress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	"scroll-tech/coordinator/internal/config"
+	"scroll-tech/coordinator/internal/orm"
+	coordinatorType "scroll-tech/coordinator/internal/types"
+)
+
+// defaultProgressStaleTime is the default duration without progress after which an assigned task is reported as stuck
+const defaultProgressStaleTime = 10 * time.Minute
+
+var (
+	// ErrInvalidProgress the reported stage or percent is invalid
+	ErrInvalidProgress = errors.New("invalid progress")
+	// ErrProverTaskNotAssigned the prover task of the progress is not in assigned status
+	ErrProverTaskNotAssigned = errors.New("prover task is not assigned")
+)
+
+// TaskProgressLogic stores the progress reported by the provers and estimates the remaining time of the assigned tasks.
+type TaskProgressLogic struct {
+	proverTaskOrm *orm.ProverTask
+
+	staleTime time.Duration
+
+	reportedTotal *prometheus.CounterVec
+}
+
+// NewTaskProgressLogic create a task progress logic
+func NewTaskProgressLogic(cfg *config.ProverManager, db *gorm.DB, reg prometheus.Registerer) *TaskProgressLogic {
+	staleTime := defaultProgressStaleTime
+	if cfg.ProgressStaleSec > 0 {
+		staleTime = time.Duration(cfg.ProgressStaleSec) * time.Second
+	}
+
+	return &TaskProgressLogic{
+		proverTaskOrm: orm.NewProverTask(db),
+		staleTime:     staleTime,
+		reportedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "coordinator_prover_task_progress_reported_total",
+			Help: "Total number of task progresses reported by provers.",
+		}, []string{"task_type", "stage"}),
+	}
+}
+
+// Report stores the latest progress of the task assigned to the prover
+func (l *TaskProgressLogic) Report(ctx *gin.Context, param *coordinatorType.ReportProgressParameter) error {
+	stage := message.TaskProgressStage(param.Stage)
+	if !stage.IsValid() {
+		return fmt.Errorf("%w: stage %q", ErrInvalidProgress, param.Stage)
+	}
+	if param.Percent < 0 || param.Percent > 100 {
+		return fmt.Errorf("%w: percent %d", ErrInvalidProgress, param.Percent)
+	}
+
+	publicKey, publicKeyExist := ctx.Get(coordinatorType.PublicKey)
+	if !publicKeyExist {
+		return errors.New("get public key from context failed")
+	}
+
+	proverTask, err := l.proverTaskOrm.GetProverTaskByUUIDAndPublicKey(ctx, param.UUID, publicKey.(string))
+	if err != nil {
+		return err
+	}
+	if proverTask.TaskID != param.TaskID || proverTask.TaskType != int16(param.TaskType) {
+		return fmt.Errorf("prover task mismatch, uuid: %s, task id: %s", param.UUID, param.TaskID)
+	}
+
+	updated, err := l.proverTaskOrm.UpdateProverTaskProgress(ctx, proverTask.UUID, stage, int16(param.Percent))
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return fmt.Errorf("%w, uuid: %s", ErrProverTaskNotAssigned, param.UUID)
+	}
+
+	l.reportedTotal.WithLabelValues(message.ProofType(proverTask.TaskType).String(), string(stage)).Inc()
+	return nil
+}
+
+// Progresses returns the progress of the assigned tasks, the earliest assigned first
+func (l *TaskProgressLogic) Progresses(ctx context.Context, limit int) ([]*coordinatorType.TaskProgressSchema, error) {
+	proverTasks, err := l.proverTaskOrm.GetAssignedProverTasks(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.NowUTC()
+	progresses := make([]*coordinatorType.TaskProgressSchema, 0, len(proverTasks))
+	for i := range proverTasks {
+		progresses = append(progresses, taskProgress(&proverTasks[i], now, l.staleTime))
+	}
+	return progresses, nil
+}
+
+// taskProgress estimates the remaining time of the task from its elapsed time and percent, the task is stuck
+// if neither its assignment nor its latest progress is more recent than the stale time.
+func taskProgress(proverTask *orm.ProverTask, now time.Time, staleTime time.Duration) *coordinatorType.TaskProgressSchema {
+	elapsed := now.Sub(proverTask.AssignedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	progress := &coordinatorType.TaskProgressSchema{
+		UUID:            proverTask.UUID.String(),
+		TaskID:          proverTask.TaskID,
+		TaskType:        int(proverTask.TaskType),
+		ProverName:      proverTask.ProverName,
+		ProverPublicKey: proverTask.ProverPublicKey,
+		Stage:           proverTask.ProgressStage,
+		Percent:         int(proverTask.ProgressPercent),
+		AssignedAt:      proverTask.AssignedAt,
+		UpdatedAt:       proverTask.ProgressUpdatedAt,
+		ElapsedSec:      uint64(elapsed.Seconds()),
+	}
+
+	if percent := uint64(proverTask.ProgressPercent); percent > 0 && percent <= 100 {
+		remaining := progress.ElapsedSec * (100 - percent) / percent
+		progress.EstimatedRemainingSec = &remaining
+	}
+
+	lastSeen := proverTask.AssignedAt
+	if proverTask.ProgressUpdatedAt != nil && proverTask.ProgressUpdatedAt.After(lastSeen) {
+		lastSeen = *proverTask.ProgressUpdatedAt
+	}
+	progress.Stuck = now.Sub(lastSeen) > staleTime
+	return progress
+}
@@ -0,0 +1,52 @@
+package taskprogress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/coordinator/internal/orm"
+)
+
+func TestTaskProgress(t *testing.T) {
+	now := time.Now()
+	staleTime := 10 * time.Minute
+
+	// no progress reported yet
+	proverTask := &orm.ProverTask{
+		TaskID:     "hash",
+		TaskType:   int16(message.ProofTypeChunk),
+		AssignedAt: now.Add(-5 * time.Minute),
+	}
+	progress := taskProgress(proverTask, now, staleTime)
+	assert.Equal(t, uint64(300), progress.ElapsedSec)
+	assert.Nil(t, progress.EstimatedRemainingSec)
+	assert.False(t, progress.Stuck)
+
+	// a quarter done in 5 minutes, 15 minutes to go
+	updatedAt := now.Add(-time.Minute)
+	proverTask.ProgressStage = string(message.TaskProgressStageProving)
+	proverTask.ProgressPercent = 25
+	proverTask.ProgressUpdatedAt = &updatedAt
+	progress = taskProgress(proverTask, now, staleTime)
+	assert.Equal(t, string(message.TaskProgressStageProving), progress.Stage)
+	assert.Equal(t, 25, progress.Percent)
+	assert.Equal(t, uint64(900), *progress.EstimatedRemainingSec)
+	assert.False(t, progress.Stuck)
+
+	// no progress since the last report for longer than the stale time
+	proverTask.AssignedAt = now.Add(-time.Hour)
+	updatedAt = now.Add(-11 * time.Minute)
+	progress = taskProgress(proverTask, now, staleTime)
+	assert.True(t, progress.Stuck)
+
+	// no progress reported since the assignment for longer than the stale time
+	proverTask.ProgressPercent = 0
+	proverTask.ProgressUpdatedAt = nil
+	progress = taskProgress(proverTask, now, staleTime)
+	assert.Nil(t, progress.EstimatedRemainingSec)
+	assert.True(t, progress.Stuck)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/coordinator/internal/config"
+)
+
+// AdminMiddleware checks the bearer token of the admin api
+func AdminMiddleware(conf *config.Config) gin.HandlerFunc {
+	expected := []byte("Bearer " + conf.Admin.Secret)
+	return func(c *gin.Context) {
+		token := []byte(c.GetHeader("Authorization"))
+		if subtle.ConstantTimeCompare(token, expected) != 1 {
+			types.RenderFailure(c, types.ErrCoordinatorAdminUnauthorized, errors.New("admin api unauthorized"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// PollMiddleware closes the connection after each response of the polling api, it serves the
+// provers which can not hold long-lived connections.
+func PollMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Connection", "close")
+		c.Next()
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"gorm.io/gorm"
 
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/utils"
 )
@@ -43,6 +44,7 @@ type Batch struct {
 	ProofTimeSec      int32      `json:"proof_time_sec" gorm:"column:proof_time_sec;default:NULL"`
 	TotalAttempts     int16      `json:"total_attempts" gorm:"column:total_attempts;default:0"`
 	ActiveAttempts    int16      `json:"active_attempts" gorm:"column:active_attempts;default:0"`
+	Priority          int16      `json:"priority" gorm:"column:priority;default:0"`
 
 	// rollup
 	RollupStatus   int16      `json:"rollup_status" gorm:"column:rollup_status;default:1"`
@@ -73,12 +75,14 @@ func (*Batch) TableName() string {
 
 // GetUnassignedBatch retrieves unassigned batch based on the specified limit.
 // The returned batch are sorted in ascending order by their index.
-func (o *Batch) GetUnassignedBatch(ctx context.Context, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
+func (o *Batch) GetUnassignedBatch(ctx context.Context, chunkRange TaskRange, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Where("proving_status = ?", int(types.ProvingTaskUnassigned))
 	db = db.Where("total_attempts < ?", maxTotalAttempts)
 	db = db.Where("active_attempts < ?", maxActiveAttempts)
 	db = db.Where("chunk_proofs_status = ?", int(types.ChunkProofsStatusReady))
+	db = chunkRange.apply(db, "start_chunk_index", "end_chunk_index")
+	db = db.Order("priority DESC")
 
 	var batch Batch
 	err := db.First(&batch).Error
@@ -94,12 +98,14 @@ func (o *Batch) GetUnassignedBatch(ctx context.Context, maxActiveAttempts, maxTo
 
 // GetAssignedBatch retrieves assigned batch based on the specified limit.
 // The returned batch are sorted in ascending order by their index.
-func (o *Batch) GetAssignedBatch(ctx context.Context, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
+func (o *Batch) GetAssignedBatch(ctx context.Context, chunkRange TaskRange, maxActiveAttempts, maxTotalAttempts uint8) (*Batch, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
 	db = db.Where("total_attempts < ?", maxTotalAttempts)
 	db = db.Where("active_attempts < ?", maxActiveAttempts)
 	db = db.Where("chunk_proofs_status = ?", int(types.ChunkProofsStatusReady))
+	db = chunkRange.apply(db, "start_chunk_index", "end_chunk_index")
+	db = db.Order("priority DESC")
 
 	var batch Batch
 	err := db.First(&batch).Error
@@ -133,6 +139,21 @@ func (o *Batch) GetUnassignedAndChunksUnreadyBatches(ctx context.Context, offset
 	return batches, nil
 }
 
+// GetBatchesStraddlingChunk retrieves the batches starting before and ending at or after the chunk index.
+func (o *Batch) GetBatchesStraddlingChunk(ctx context.Context, chunkIndex uint64) ([]*Batch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("start_chunk_index < ?", chunkIndex)
+	db = db.Where("end_chunk_index >= ?", chunkIndex)
+	db = db.Order("index ASC")
+
+	var batches []*Batch
+	if err := db.Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("Batch.GetBatchesStraddlingChunk error: %w, chunk index: %v", err, chunkIndex)
+	}
+	return batches, nil
+}
+
 // GetAssignedBatches retrieves all batches whose proving_status is either types.ProvingTaskAssigned.
 func (o *Batch) GetAssignedBatches(ctx context.Context) ([]*Batch, error) {
 	db := o.db.WithContext(ctx)
@@ -210,8 +231,8 @@ func (o *Batch) InsertBatch(ctx context.Context, startChunkIndex, endChunkIndex
 		batchIndex = parentBatch.Index + 1
 		parentBatchHash = common.HexToHash(parentBatch.Hash)
 
-		var parentBatchHeader *types.BatchHeader
-		parentBatchHeader, err = types.DecodeBatchHeader(parentBatch.BatchHeader)
+		var parentBatchHeader codec.BatchHeader
+		parentBatchHeader, err = codec.DecodeBatchHeader(parentBatch.BatchHeader)
 		if err != nil {
 			log.Error("failed to decode parent batch header", "index", parentBatch.Index, "hash", parentBatch.Hash, "err", err)
 			return nil, err
@@ -221,7 +242,12 @@ func (o *Batch) InsertBatch(ctx context.Context, startChunkIndex, endChunkIndex
 		version = parentBatchHeader.Version()
 	}
 
-	batchHeader, err := types.NewBatchHeader(version, batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
+	batchCodec, err := codec.New(codec.Version(version))
+	if err != nil {
+		log.Error("failed to get batch codec", "index", batchIndex, "version", version, "err", err)
+		return nil, err
+	}
+	batchHeader, err := batchCodec.NewBatchHeader(batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
 	if err != nil {
 		log.Error("failed to create batch header",
 			"index", batchIndex, "total l1 message popped before", totalL1MessagePoppedBefore,
@@ -361,3 +387,91 @@ func (o *Batch) DecreaseActiveAttemptsByHash(ctx context.Context, batchHash stri
 	}
 	return nil
 }
+
+// UpdatePriorityByHash updates the priority lane of a batch given its hash.
+func (o *Batch) UpdatePriorityByHash(ctx context.Context, hash string, lane types.TaskLane, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash = ?", hash)
+
+	result := db.Update("priority", int(lane))
+	if result.Error != nil {
+		return fmt.Errorf("Batch.UpdatePriorityByHash error: %w, batch hash: %v", result.Error, hash)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Batch.UpdatePriorityByHash error: batch not found, batch hash: %v", hash)
+	}
+	return nil
+}
+
+// GetUnprovedCountByLane returns the number of the unassigned and assigned batches in each priority lane.
+func (o *Batch) GetUnprovedCountByLane(ctx context.Context) (map[types.TaskLane]int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Select("priority, COUNT(*) AS count")
+	db = db.Where("proving_status IN ?", []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskAssigned)})
+	db = db.Group("priority")
+
+	var results []struct {
+		Priority int16
+		Count    int64
+	}
+	if err := db.Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("Batch.GetUnprovedCountByLane error: %w", err)
+	}
+
+	counts := make(map[types.TaskLane]int64)
+	for _, result := range results {
+		counts[types.TaskLane(result.Priority)] = result.Count
+	}
+	return counts, nil
+}
+
+// DecreaseAttemptsByHash decrements both the active_attempts and total_attempts of a batch given its hash,
+// it's used when a prover declines the task, which isn't counted as an attempt.
+func (o *Batch) DecreaseAttemptsByHash(ctx context.Context, hash string, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash = ?", hash)
+	db = db.Where("proving_status != ?", int(types.ProvingTaskVerified))
+	db = db.Where("active_attempts > ?", 0)
+	db = db.Where("total_attempts > ?", 0)
+	result := db.Updates(map[string]interface{}{
+		"active_attempts": gorm.Expr("active_attempts - 1"),
+		"total_attempts":  gorm.Expr("total_attempts - 1"),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("Batch.DecreaseAttemptsByHash error: %w, batch hash: %v", result.Error, hash)
+	}
+	if result.RowsAffected == 0 {
+		log.Warn("No rows were affected in DecreaseAttemptsByHash", "batch hash", hash)
+	}
+	return nil
+}
+
+// ExpireAssignedBatches marks the batches stuck in assigned status since before the given time, and without any
+// assigned prover task, as failed. It returns the number of expired batches.
+func (o *Batch) ExpireAssignedBatches(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
+	db = db.Where("updated_at < ?", updatedBefore)
+	db = db.Where("NOT EXISTS (SELECT 1 FROM prover_task WHERE prover_task.task_id = batch.hash AND prover_task.task_type = ? AND prover_task.proving_status = ? AND prover_task.deleted_at IS NULL)",
+		int(message.ProofTypeBatch), int(types.ProverAssigned))
+	result := db.Updates(map[string]interface{}{
+		"proving_status":  int(types.ProvingTaskFailed),
+		"active_attempts": 0,
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("Batch.ExpireAssignedBatches error: %w, updated before: %v", result.Error, updatedBefore)
+	}
+	return result.RowsAffected, nil
+}
@@ -42,6 +42,7 @@ type Chunk struct {
 	ProofTimeSec     int32      `json:"proof_time_sec" gorm:"column:proof_time_sec;default:NULL"`
 	TotalAttempts    int16      `json:"total_attempts" gorm:"column:total_attempts;default:0"`
 	ActiveAttempts   int16      `json:"active_attempts" gorm:"column:active_attempts;default:0"`
+	Priority         int16      `json:"priority" gorm:"column:priority;default:0"`
 
 	// batch
 	BatchHash string `json:"batch_hash" gorm:"column:batch_hash;default:NULL"`
@@ -66,15 +67,34 @@ func (*Chunk) TableName() string {
 	return "chunk"
 }
 
+// TaskRange is the half-open range [From, To) of the tasks of a hard fork, To is 0 if the range is unbounded.
+// It ranges the block numbers of the chunks and the chunk indexes of the batches.
+type TaskRange struct {
+	From uint64
+	To   uint64
+}
+
+func (r TaskRange) apply(db *gorm.DB, startColumn, endColumn string) *gorm.DB {
+	if r.From > 0 {
+		db = db.Where(startColumn+" >= ?", r.From)
+	}
+	if r.To > 0 {
+		db = db.Where(endColumn+" < ?", r.To)
+	}
+	return db
+}
+
 // GetUnassignedChunk retrieves unassigned chunk based on the specified limit.
 // The returned chunks are sorted in ascending order by their index.
-func (o *Chunk) GetUnassignedChunk(ctx context.Context, height int, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
+func (o *Chunk) GetUnassignedChunk(ctx context.Context, height int, blockRange TaskRange, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Model(&Chunk{})
 	db = db.Where("proving_status = ?", int(types.ProvingTaskUnassigned))
 	db = db.Where("total_attempts < ?", maxTotalAttempts)
 	db = db.Where("active_attempts < ?", maxActiveAttempts)
 	db = db.Where("end_block_number <= ?", height)
+	db = blockRange.apply(db, "start_block_number", "end_block_number")
+	db = db.Order("priority DESC")
 
 	var chunk Chunk
 	err := db.First(&chunk).Error
@@ -90,13 +110,15 @@ func (o *Chunk) GetUnassignedChunk(ctx context.Context, height int, maxActiveAtt
 
 // GetAssignedChunk retrieves assigned chunk based on the specified limit.
 // The returned chunks are sorted in ascending order by their index.
-func (o *Chunk) GetAssignedChunk(ctx context.Context, height int, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
+func (o *Chunk) GetAssignedChunk(ctx context.Context, height int, blockRange TaskRange, maxActiveAttempts, maxTotalAttempts uint8) (*Chunk, error) {
 	db := o.db.WithContext(ctx)
 	db = db.Model(&Chunk{})
 	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
 	db = db.Where("total_attempts < ?", maxTotalAttempts)
 	db = db.Where("active_attempts < ?", maxActiveAttempts)
 	db = db.Where("end_block_number <= ?", height)
+	db = blockRange.apply(db, "start_block_number", "end_block_number")
+	db = db.Order("priority DESC")
 
 	var chunk Chunk
 	err := db.First(&chunk).Error
@@ -110,6 +132,40 @@ func (o *Chunk) GetAssignedChunk(ctx context.Context, height int, maxActiveAttem
 	return &chunk, nil
 }
 
+// GetFirstChunkIndexFromBlock retrieves the index of the first chunk starting at or after the block number,
+// found is false if there is no such chunk yet.
+func (o *Chunk) GetFirstChunkIndexFromBlock(ctx context.Context, blockNumber uint64) (index uint64, found bool, err error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("start_block_number >= ?", blockNumber)
+	db = db.Order("index ASC")
+
+	var chunk Chunk
+	err = db.Select("index").First(&chunk).Error
+	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("Chunk.GetFirstChunkIndexFromBlock error: %w, block number: %v", err, blockNumber)
+	}
+	return chunk.Index, true, nil
+}
+
+// GetChunksStraddlingBlock retrieves the chunks starting before and ending at or after the block number.
+func (o *Chunk) GetChunksStraddlingBlock(ctx context.Context, blockNumber uint64) ([]*Chunk, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("start_block_number < ?", blockNumber)
+	db = db.Where("end_block_number >= ?", blockNumber)
+	db = db.Order("index ASC")
+
+	var chunks []*Chunk
+	if err := db.Find(&chunks).Error; err != nil {
+		return nil, fmt.Errorf("Chunk.GetChunksStraddlingBlock error: %w, block number: %v", err, blockNumber)
+	}
+	return chunks, nil
+}
+
 // GetChunksByBatchHash retrieves the chunks associated with a specific batch hash.
 // The returned chunks are sorted in ascending order by their associated chunk index.
 func (o *Chunk) GetChunksByBatchHash(ctx context.Context, batchHash string) ([]*Chunk, error) {
@@ -257,8 +313,16 @@ func (o *Chunk) InsertChunk(ctx context.Context, chunk *types.Chunk, dbTX ...*go
 	for _, block := range chunk.Blocks {
 		totalL2TxGas += block.Header.GasUsed
 		totalL2TxNum += block.NumL2Transactions()
-		totalL1CommitCalldataSize += block.EstimateL1CommitCalldataSize()
-		totalL1CommitGas += block.EstimateL1CommitGas()
+		blockL1CommitCalldataSize, err := block.EstimateL1CommitCalldataSize()
+		if err != nil {
+			return nil, fmt.Errorf("Chunk.InsertChunk error: %w", err)
+		}
+		totalL1CommitCalldataSize += blockL1CommitCalldataSize
+		blockL1CommitGas, err := block.EstimateL1CommitGas()
+		if err != nil {
+			return nil, fmt.Errorf("Chunk.InsertChunk error: %w", err)
+		}
+		totalL1CommitGas += blockL1CommitGas
 	}
 
 	numBlocks := len(chunk.Blocks)
@@ -396,3 +460,91 @@ func (o *Chunk) DecreaseActiveAttemptsByHash(ctx context.Context, chunkHash stri
 	}
 	return nil
 }
+
+// UpdatePriorityByHash updates the priority lane of a chunk given its hash.
+func (o *Chunk) UpdatePriorityByHash(ctx context.Context, hash string, lane types.TaskLane, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("hash = ?", hash)
+
+	result := db.Update("priority", int(lane))
+	if result.Error != nil {
+		return fmt.Errorf("Chunk.UpdatePriorityByHash error: %w, chunk hash: %v", result.Error, hash)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Chunk.UpdatePriorityByHash error: chunk not found, chunk hash: %v", hash)
+	}
+	return nil
+}
+
+// GetUnprovedCountByLane returns the number of the unassigned and assigned chunks in each priority lane.
+func (o *Chunk) GetUnprovedCountByLane(ctx context.Context) (map[types.TaskLane]int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Select("priority, COUNT(*) AS count")
+	db = db.Where("proving_status IN ?", []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskAssigned)})
+	db = db.Group("priority")
+
+	var results []struct {
+		Priority int16
+		Count    int64
+	}
+	if err := db.Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("Chunk.GetUnprovedCountByLane error: %w", err)
+	}
+
+	counts := make(map[types.TaskLane]int64)
+	for _, result := range results {
+		counts[types.TaskLane(result.Priority)] = result.Count
+	}
+	return counts, nil
+}
+
+// DecreaseAttemptsByHash decrements both the active_attempts and total_attempts of a chunk given its hash,
+// it's used when a prover declines the task, which isn't counted as an attempt.
+func (o *Chunk) DecreaseAttemptsByHash(ctx context.Context, hash string, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("hash = ?", hash)
+	db = db.Where("proving_status != ?", int(types.ProvingTaskVerified))
+	db = db.Where("active_attempts > ?", 0)
+	db = db.Where("total_attempts > ?", 0)
+	result := db.Updates(map[string]interface{}{
+		"active_attempts": gorm.Expr("active_attempts - 1"),
+		"total_attempts":  gorm.Expr("total_attempts - 1"),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("Chunk.DecreaseAttemptsByHash error: %w, chunk hash: %v", result.Error, hash)
+	}
+	if result.RowsAffected == 0 {
+		log.Warn("No rows were affected in DecreaseAttemptsByHash", "chunk hash", hash)
+	}
+	return nil
+}
+
+// ExpireAssignedChunks marks the chunks stuck in assigned status since before the given time, and without any
+// assigned prover task, as failed. It returns the number of expired chunks.
+func (o *Chunk) ExpireAssignedChunks(ctx context.Context, updatedBefore time.Time) (int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("proving_status = ?", int(types.ProvingTaskAssigned))
+	db = db.Where("updated_at < ?", updatedBefore)
+	db = db.Where("NOT EXISTS (SELECT 1 FROM prover_task WHERE prover_task.task_id = chunk.hash AND prover_task.task_type = ? AND prover_task.proving_status = ? AND prover_task.deleted_at IS NULL)",
+		int(message.ProofTypeChunk), int(types.ProverAssigned))
+	result := db.Updates(map[string]interface{}{
+		"proving_status":  int(types.ProvingTaskFailed),
+		"active_attempts": 0,
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("Chunk.ExpireAssignedChunks error: %w, updated before: %v", result.Error, updatedBefore)
+	}
+	return result.RowsAffected, nil
+}
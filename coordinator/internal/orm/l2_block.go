@@ -14,6 +14,9 @@ import (
 	"scroll-tech/common/types"
 )
 
+// chunkProverFailureReason is the failure reason recorded for the blocks of a chunk failing proving.
+const chunkProverFailureReason = "prover_failure"
+
 // L2Block represents a l2 block in the database.
 type L2Block struct {
 	db *gorm.DB `gorm:"column:-"`
@@ -32,7 +35,9 @@ type L2Block struct {
 	RowConsumption string `json:"row_consumption" gorm:"row_consumption"`
 
 	// chunk
-	ChunkHash string `json:"chunk_hash" gorm:"chunk_hash;default:NULL"`
+	ChunkHash                   string `json:"chunk_hash" gorm:"chunk_hash;default:NULL"`
+	ChunkProposalFailures       uint64 `json:"chunk_proposal_failures" gorm:"chunk_proposal_failures;default:0"`
+	ChunkProposalFailureReasons string `json:"chunk_proposal_failure_reasons" gorm:"chunk_proposal_failure_reasons;default:''"`
 
 	// metadata
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
@@ -68,7 +73,7 @@ func (o *L2Block) GetL2BlocksByChunkHash(ctx context.Context, chunkHash string)
 	for _, v := range l2Blocks {
 		var wrappedBlock types.WrappedBlock
 
-		if err := json.Unmarshal([]byte(v.Transactions), &wrappedBlock.Transactions); err != nil {
+		if err := wrappedBlock.UnmarshalTransactions([]byte(v.Transactions)); err != nil {
 			return nil, fmt.Errorf("L2Block.GetL2BlocksByChunkHash error: %w, chunk hash: %v", err, chunkHash)
 		}
 
@@ -99,7 +104,7 @@ func (o *L2Block) InsertL2Blocks(ctx context.Context, blocks []*types.WrappedBlo
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)
 		}
 
-		txs, err := json.Marshal(block.Transactions)
+		txs, err := block.MarshalTransactions()
 		if err != nil {
 			log.Error("failed to marshal transactions", "hash", block.Header.Hash().String(), "err", err)
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)
@@ -150,3 +155,26 @@ func (o *L2Block) UpdateChunkHashInRange(ctx context.Context, startNumber uint64
 	}
 	return nil
 }
+
+// IncreaseChunkProposalFailuresByChunkHash counts a failed proof of a chunk as a failure of each of its blocks,
+// the chunk proposer quarantines the blocks failing too many times into single-block chunks when they are chunked again.
+func (o *L2Block) IncreaseChunkProposalFailuresByChunkHash(ctx context.Context, chunkHash string, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Where("chunk_hash = ?", chunkHash)
+
+	err := db.Updates(map[string]interface{}{
+		"chunk_proposal_failures": gorm.Expr("chunk_proposal_failures + 1"),
+		"chunk_proposal_failure_reasons": gorm.Expr("CASE WHEN ? = ANY(string_to_array(chunk_proposal_failure_reasons, ',')) THEN chunk_proposal_failure_reasons "+
+			"WHEN chunk_proposal_failure_reasons = '' THEN ? ELSE chunk_proposal_failure_reasons || ',' || ? END",
+			chunkProverFailureReason, chunkProverFailureReason, chunkProverFailureReason),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("L2Block.IncreaseChunkProposalFailuresByChunkHash error: %w, chunk hash: %v", err, chunkHash)
+	}
+	return nil
+}
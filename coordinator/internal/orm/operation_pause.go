@@ -0,0 +1,77 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OperationAssignTask assigns new proving tasks to the provers, the coordinator is in drain mode while it is paused.
+const OperationAssignTask = "assign_task"
+
+// OperationPause is the persisted pause switch of an operation, the switches are shared with the rollup services.
+type OperationPause struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	Operation string `json:"operation" gorm:"column:operation;primaryKey"`
+	Paused    bool   `json:"paused" gorm:"column:paused"`
+	Reason    string `json:"reason" gorm:"column:reason"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewOperationPause creates a new OperationPause database instance.
+func NewOperationPause(db *gorm.DB) *OperationPause {
+	return &OperationPause{db: db}
+}
+
+// TableName returns the table name for the OperationPause model.
+func (*OperationPause) TableName() string {
+	return "operation_pause"
+}
+
+// IsOperationPaused returns whether the operation is paused, an operation never set is not paused.
+func (o *OperationPause) IsOperationPaused(ctx context.Context, operation string) (bool, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&OperationPause{})
+	db = db.Where("operation = ?", operation)
+
+	var operationPause OperationPause
+	if err := db.First(&operationPause).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("OperationPause.IsOperationPaused error: %w, operation: %v", err, operation)
+	}
+	return operationPause.Paused, nil
+}
+
+// SetOperationPause pauses or resumes an operation.
+func (o *OperationPause) SetOperationPause(ctx context.Context, operation string, paused bool, reason string, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&OperationPause{})
+	db = db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "operation"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"paused":     paused,
+			"reason":     reason,
+			"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	})
+	operationPause := OperationPause{Operation: operation, Paused: paused, Reason: reason}
+	if err := db.Create(&operationPause).Error; err != nil {
+		return fmt.Errorf("OperationPause.SetOperationPause error: %w, operation: %v, paused: %v", err, operation, paused)
+	}
+	return nil
+}
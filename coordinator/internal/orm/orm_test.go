@@ -2,8 +2,11 @@ package orm
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -124,3 +127,223 @@ func TestProverTaskOrmUint256(t *testing.T) {
 	assert.Equal(t, resultRewardUint256, rewardUint256)
 	assert.Equal(t, resultRewardUint256.String(), "115792089237316195423570985008687907853269984665640564039457584007913129639935")
 }
+
+func TestExpireAssignedProverTasks(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	staleTask := ProverTask{
+		TaskType:        int16(message.ProofTypeChunk),
+		TaskID:          "stale-hash",
+		ProverName:      "prover-0",
+		ProverPublicKey: "0",
+		ProvingStatus:   int16(types.ProverAssigned),
+		AssignedAt:      utils.NowUTC().Add(-time.Hour),
+	}
+	assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &staleTask))
+
+	activeTask := ProverTask{
+		TaskType:        int16(message.ProofTypeChunk),
+		TaskID:          "active-hash",
+		ProverName:      "prover-1",
+		ProverPublicKey: "1",
+		ProvingStatus:   int16(types.ProverAssigned),
+		AssignedAt:      utils.NowUTC(),
+	}
+	assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &activeTask))
+
+	expired, err := proverTaskOrm.ExpireAssignedProverTasks(context.Background(), utils.NowUTC().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	proverTasks, err := proverTaskOrm.GetProverTasksByHashes(context.Background(), message.ProofTypeChunk, []string{"stale-hash", "active-hash"})
+	assert.NoError(t, err)
+	assert.Len(t, proverTasks, 2)
+	for _, proverTask := range proverTasks {
+		if proverTask.TaskID == "stale-hash" {
+			assert.Equal(t, int16(types.ProverProofInvalid), proverTask.ProvingStatus)
+			assert.Equal(t, int16(types.ProverTaskFailureTypeExpired), proverTask.FailureType)
+		} else {
+			assert.Equal(t, int16(types.ProverAssigned), proverTask.ProvingStatus)
+		}
+	}
+}
+
+func TestProverTaskProgress(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	assignedTask := ProverTask{
+		TaskType:        int16(message.ProofTypeChunk),
+		TaskID:          "assigned-hash",
+		ProverName:      "prover-0",
+		ProverPublicKey: "0",
+		ProvingStatus:   int16(types.ProverAssigned),
+		AssignedAt:      utils.NowUTC(),
+	}
+	assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &assignedTask))
+
+	provedTask := ProverTask{
+		TaskType:        int16(message.ProofTypeChunk),
+		TaskID:          "proved-hash",
+		ProverName:      "prover-1",
+		ProverPublicKey: "1",
+		ProvingStatus:   int16(types.ProverProofValid),
+		AssignedAt:      utils.NowUTC(),
+	}
+	assert.NoError(t, proverTaskOrm.InsertProverTask(context.Background(), &provedTask))
+
+	updated, err := proverTaskOrm.UpdateProverTaskProgress(context.Background(), assignedTask.UUID, message.TaskProgressStageProving, 40)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	// the progress of a task no longer assigned is not updated
+	updated, err = proverTaskOrm.UpdateProverTaskProgress(context.Background(), provedTask.UUID, message.TaskProgressStageProving, 40)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	proverTasks, err := proverTaskOrm.GetAssignedProverTasks(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, proverTasks, 1)
+	assert.Equal(t, "assigned-hash", proverTasks[0].TaskID)
+	assert.Equal(t, string(message.TaskProgressStageProving), proverTasks[0].ProgressStage)
+	assert.Equal(t, int16(40), proverTasks[0].ProgressPercent)
+	assert.NotNil(t, proverTasks[0].ProgressUpdatedAt)
+
+	count, err := proverTaskOrm.CountProverAssignedTasks(context.Background(), "0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = proverTaskOrm.CountProverAssignedTasks(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestHardForkTaskRange(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	readBlock := func(file string) *types.WrappedBlock {
+		templateBlockTrace, readErr := os.ReadFile("../../../common/testdata/" + file)
+		assert.NoError(t, readErr)
+		block := &types.WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, block))
+		return block
+	}
+	block2, block3 := readBlock("blockTrace_02.json"), readBlock("blockTrace_03.json")
+
+	// chunk 1 straddles the hard fork at block 3
+	ctx := context.Background()
+	chunkOrm := NewChunk(db)
+	var chunks []*Chunk
+	for _, blocks := range [][]*types.WrappedBlock{{block2}, {block2, block3}, {block3}} {
+		chunk, insertErr := chunkOrm.InsertChunk(ctx, &types.Chunk{Blocks: blocks})
+		assert.NoError(t, insertErr)
+		chunks = append(chunks, chunk)
+	}
+
+	index, found, err := chunkOrm.GetFirstChunkIndexFromBlock(ctx, 3)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(2), index)
+	_, found, err = chunkOrm.GetFirstChunkIndexFromBlock(ctx, 4)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	straddling, err := chunkOrm.GetChunksStraddlingBlock(ctx, 3)
+	assert.NoError(t, err)
+	assert.Len(t, straddling, 1)
+	assert.Equal(t, chunks[1].Hash, straddling[0].Hash)
+
+	chunk, err := chunkOrm.GetUnassignedChunk(ctx, 100, TaskRange{To: 3}, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, chunks[0].Hash, chunk.Hash)
+	chunk, err = chunkOrm.GetUnassignedChunk(ctx, 100, TaskRange{From: 3}, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, chunks[2].Hash, chunk.Hash)
+
+	// batch 0 straddles the hard fork at chunk 1
+	batchOrm := NewBatch(db)
+	batch0, err := batchOrm.InsertBatch(ctx, 0, 1, chunks[0].Hash, chunks[1].Hash, []*types.Chunk{{Blocks: []*types.WrappedBlock{block2}}, {Blocks: []*types.WrappedBlock{block2, block3}}})
+	assert.NoError(t, err)
+	batch1, err := batchOrm.InsertBatch(ctx, 2, 2, chunks[2].Hash, chunks[2].Hash, []*types.Chunk{{Blocks: []*types.WrappedBlock{block3}}})
+	assert.NoError(t, err)
+	for _, batch := range []*Batch{batch0, batch1} {
+		assert.NoError(t, batchOrm.UpdateChunkProofsStatusByBatchHash(ctx, batch.Hash, types.ChunkProofsStatusReady))
+	}
+
+	straddlingBatches, err := batchOrm.GetBatchesStraddlingChunk(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, straddlingBatches, 1)
+	assert.Equal(t, batch0.Hash, straddlingBatches[0].Hash)
+	straddlingBatches, err = batchOrm.GetBatchesStraddlingChunk(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, straddlingBatches)
+
+	batch, err := batchOrm.GetUnassignedBatch(ctx, TaskRange{To: 2}, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, batch0.Hash, batch.Hash)
+	batch, err = batchOrm.GetUnassignedBatch(ctx, TaskRange{From: 2}, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, batch1.Hash, batch.Hash)
+}
+
+func TestL2BlockChunkProposalFailures(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	var blocks []*types.WrappedBlock
+	for _, file := range []string{"blockTrace_02.json", "blockTrace_03.json"} {
+		templateBlockTrace, readErr := os.ReadFile("../../../common/testdata/" + file)
+		assert.NoError(t, readErr)
+		block := &types.WrappedBlock{}
+		assert.NoError(t, json.Unmarshal(templateBlockTrace, block))
+		blocks = append(blocks, block)
+	}
+
+	ctx := context.Background()
+	l2BlockOrm := NewL2Block(db)
+	assert.NoError(t, l2BlockOrm.InsertL2Blocks(ctx, blocks))
+	assert.NoError(t, l2BlockOrm.UpdateChunkHashInRange(ctx, 2, 2, "chunk-hash"))
+
+	// every failed proof is counted, the reason is recorded once
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, l2BlockOrm.IncreaseChunkProposalFailuresByChunkHash(ctx, "chunk-hash"))
+	}
+	assert.NoError(t, l2BlockOrm.IncreaseChunkProposalFailuresByChunkHash(ctx, "unknown-hash"))
+
+	var l2Blocks []L2Block
+	assert.NoError(t, db.Order("number ASC").Find(&l2Blocks).Error)
+	assert.Len(t, l2Blocks, 2)
+	assert.Equal(t, uint64(2), l2Blocks[0].ChunkProposalFailures)
+	assert.Equal(t, "prover_failure", l2Blocks[0].ChunkProposalFailureReasons)
+	assert.Equal(t, uint64(0), l2Blocks[1].ChunkProposalFailures)
+	assert.Equal(t, "", l2Blocks[1].ChunkProposalFailureReasons)
+}
+
+func TestOperationPause(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	ctx := context.Background()
+	operationPauseOrm := NewOperationPause(db)
+
+	// an operation never set is not paused
+	paused, err := operationPauseOrm.IsOperationPaused(ctx, OperationAssignTask)
+	assert.NoError(t, err)
+	assert.False(t, paused)
+
+	assert.NoError(t, operationPauseOrm.SetOperationPause(ctx, OperationAssignTask, true, "upgrade"))
+	paused, err = operationPauseOrm.IsOperationPaused(ctx, OperationAssignTask)
+	assert.NoError(t, err)
+	assert.True(t, paused)
+
+	assert.NoError(t, operationPauseOrm.SetOperationPause(ctx, OperationAssignTask, false, ""))
+	paused, err = operationPauseOrm.IsOperationPaused(ctx, OperationAssignTask)
+	assert.NoError(t, err)
+	assert.False(t, paused)
+}
@@ -38,6 +38,11 @@ type ProverTask struct {
 	Proof         []byte          `json:"proof" gorm:"column:proof;default:NULL"`
 	AssignedAt    time.Time       `json:"assigned_at" gorm:"assigned_at"`
 
+	// progress, the latest one reported by the prover
+	ProgressStage     string     `json:"progress_stage" gorm:"column:progress_stage;default:NULL"`
+	ProgressPercent   int16      `json:"progress_percent" gorm:"column:progress_percent;default:0"`
+	ProgressUpdatedAt *time.Time `json:"progress_updated_at" gorm:"column:progress_updated_at;default:NULL"`
+
 	// metadata
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
@@ -68,6 +73,33 @@ func (o *ProverTask) IsProverAssigned(ctx context.Context, publicKey string) (bo
 	return true, nil
 }
 
+// CountProverAssignedTasks returns the number of tasks assigned to the prover with the given public key.
+func (o *ProverTask) CountProverAssignedTasks(ctx context.Context, publicKey string) (int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("prover_public_key = ? AND proving_status = ?", publicKey, types.ProverAssigned)
+
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("ProverTask.CountProverAssignedTasks error: %w, public key: %v", err, publicKey)
+	}
+	return count, nil
+}
+
+// IsProverDeclinedSince checks if the prover with the given public key declined a task of the type after the given time.
+func (o *ProverTask) IsProverDeclinedSince(ctx context.Context, publicKey string, taskType message.ProofType, since time.Time) (bool, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("prover_public_key = ? AND task_type = ?", publicKey, int(taskType))
+	db = db.Where("proving_status = ? AND updated_at > ?", int(types.ProverTaskDeclined), since)
+
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("ProverTask.IsProverDeclinedSince error: %w, public key: %v, task type: %v", err, publicKey, taskType)
+	}
+	return count > 0, nil
+}
+
 // GetProverTasks get prover tasks
 func (o *ProverTask) GetProverTasks(ctx context.Context, fields map[string]interface{}, orderByList []string, offset, limit int) ([]ProverTask, error) {
 	db := o.db.WithContext(ctx)
@@ -244,6 +276,38 @@ func (o *ProverTask) UpdateProverTaskProof(ctx context.Context, uuid uuid.UUID,
 	return nil
 }
 
+// UpdateProverTaskProgress updates the progress of an assigned prover task, it returns whether the task is updated.
+func (o *ProverTask) UpdateProverTaskProgress(ctx context.Context, uuid uuid.UUID, stage message.TaskProgressStage, percent int16) (bool, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("uuid = ?", uuid)
+	db = db.Where("proving_status = ?", int(types.ProverAssigned))
+	result := db.Updates(map[string]interface{}{
+		"progress_stage":      string(stage),
+		"progress_percent":    percent,
+		"progress_updated_at": utils.NowUTC(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("ProverTask.UpdateProverTaskProgress error: %w, uuid: %v", result.Error, uuid)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetAssignedProverTasks returns the assigned prover tasks, the earliest assigned first.
+func (o *ProverTask) GetAssignedProverTasks(ctx context.Context, limit int) ([]ProverTask, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("proving_status = ?", int(types.ProverAssigned))
+	db = db.Order("assigned_at ASC")
+	db = db.Limit(limit)
+
+	var proverTasks []ProverTask
+	if err := db.Find(&proverTasks).Error; err != nil {
+		return nil, fmt.Errorf("ProverTask.GetAssignedProverTasks error: %w", err)
+	}
+	return proverTasks, nil
+}
+
 // UpdateProverTaskProvingStatusAndFailureType updates the proving_status of a specific ProverTask record.
 func (o *ProverTask) UpdateProverTaskProvingStatusAndFailureType(ctx context.Context, uuid uuid.UUID, status types.ProverProveStatus, failureType types.ProverTaskFailureType, dbTX ...*gorm.DB) error {
 	db := o.db
@@ -279,3 +343,20 @@ func (o *ProverTask) UpdateProverTaskFailureType(ctx context.Context, uuid uuid.
 	}
 	return nil
 }
+
+// ExpireAssignedProverTasks marks the prover tasks assigned before the given time as invalid with the expired
+// failure type. It returns the number of expired prover tasks.
+func (o *ProverTask) ExpireAssignedProverTasks(ctx context.Context, assignedBefore time.Time) (int64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&ProverTask{})
+	db = db.Where("proving_status = ?", int(types.ProverAssigned))
+	db = db.Where("assigned_at < ?", assignedBefore)
+	result := db.Updates(map[string]interface{}{
+		"proving_status": int(types.ProverProofInvalid),
+		"failure_type":   int(types.ProverTaskFailureTypeExpired),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("ProverTask.ExpireAssignedProverTasks error: %w, assigned before: %v", result.Error, assignedBefore)
+	}
+	return result.RowsAffected, nil
+}
@@ -20,6 +20,10 @@ func Route(router *gin.Engine, cfg *config.Config, reg prometheus.Registerer) {
 	r := router.Group("coordinator")
 
 	v1(r, cfg)
+
+	if cfg.Admin != nil && cfg.Admin.Secret != "" {
+		admin(r, cfg)
+	}
 }
 
 func v1(router *gin.RouterGroup, conf *config.Config) {
@@ -31,10 +35,36 @@ func v1(router *gin.RouterGroup, conf *config.Config) {
 	loginMiddleware := middleware.LoginMiddleware(conf)
 	r.POST("/login", challengeMiddleware.MiddlewareFunc(), loginMiddleware.LoginHandler)
 
+	if conf.Artifacts != nil && conf.Artifacts.Dir != "" {
+		r.GET("/artifacts", api.Artifact.List)
+		r.GET("/artifacts/:version/:name", api.Artifact.Get)
+	}
+
 	// need jwt token api
 	r.Use(loginMiddleware.MiddlewareFunc())
 	{
 		r.POST("/get_task", api.GetTask.GetTasks)
 		r.POST("/submit_proof", api.SubmitProof.SubmitProof)
+		r.POST("/decline_task", api.DeclineTask.DeclineTask)
+		r.POST("/report_progress", api.TaskProgress.ReportProgress)
+
+		// the polling fallback of the provers which can not hold long-lived connections,
+		// the proofs are uploaded as multipart files
+		poll := r.Group("/poll", middleware.PollMiddleware())
+		poll.POST("/claim_task", api.GetTask.GetTasks)
+		poll.POST("/submit_proof", api.SubmitProof.SubmitProof)
+	}
+}
+
+func admin(router *gin.RouterGroup, conf *config.Config) {
+	r := router.Group("/admin")
+
+	r.Use(middleware.AdminMiddleware(conf))
+	{
+		r.GET("/status", api.Admin.Status)
+		r.POST("/drain", api.Admin.Drain)
+		r.POST("/resume", api.Admin.Resume)
+		r.POST("/lane", api.Admin.SetTaskLane)
+		r.GET("/task_progress", api.Admin.TaskProgress)
 	}
 }
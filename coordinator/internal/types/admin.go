@@ -0,0 +1,18 @@
+package types
+
+// AdminStatusSchema is the response of the admin api
+type AdminStatusSchema struct {
+	Draining bool `json:"draining"`
+}
+
+// SetTaskLaneParameter is the parameter of the set task lane api
+type SetTaskLaneParameter struct {
+	TaskType int    `form:"task_type" json:"task_type" binding:"required"`
+	TaskID   string `form:"task_id" json:"task_id" binding:"required"`
+	Lane     string `form:"lane" json:"lane" binding:"required"`
+}
+
+// TaskProgressParameter is the parameter of the task progress api
+type TaskProgressParameter struct {
+	Limit int `form:"limit" json:"limit"`
+}
@@ -0,0 +1,15 @@
+package types
+
+// ArtifactSchema describes a versioned circuit artifact
+type ArtifactSchema struct {
+	Version  string   `json:"version"`
+	Name     string   `json:"name"`
+	Size     int64    `json:"size"`
+	Checksum string   `json:"checksum"`
+	Mirrors  []string `json:"mirrors,omitempty"`
+}
+
+// ListArtifactsSchema is the response of the list artifacts api
+type ListArtifactsSchema struct {
+	Artifacts []*ArtifactSchema `json:"artifacts"`
+}
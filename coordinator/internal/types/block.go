@@ -10,7 +10,7 @@ type WrappedBlock struct {
 	Header *types.Header `json:"header"`
 	// Transactions is only used for recover types.Transactions, the from of types.TransactionData field is missing.
 	Transactions []*types.TransactionData `json:"transactions"`
-	WithdrawRoot common.Hash              `json:"withdraw_trie_root,omitempty"`
+	WithdrawRoot common.Hash              `json:"withdraw_trie_root"`
 }
 
 // BatchInfo contains the BlockBatch's main info
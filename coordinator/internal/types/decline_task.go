@@ -0,0 +1,10 @@
+package types
+
+// DeclineTaskParameter the DeclineTask api request parameter
+type DeclineTaskParameter struct {
+	UUID     string `form:"uuid" json:"uuid" binding:"required"`
+	TaskID   string `form:"task_id" json:"task_id" binding:"required"`
+	TaskType int    `form:"task_type" json:"task_type" binding:"required"`
+	Reason   int    `form:"reason" json:"reason" binding:"required"`
+	Message  string `form:"message" json:"message"`
+}
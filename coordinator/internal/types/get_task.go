@@ -1,10 +1,19 @@
 package types
 
+import "scroll-tech/common/types/message"
+
 // GetTaskParameter for ProverTasks request parameter
 type GetTaskParameter struct {
 	ProverHeight int    `form:"prover_height" json:"prover_height"`
 	TaskType     int    `form:"task_type" json:"task_type"`
 	VK           string `form:"vk" json:"vk"`
+	// ProverTime is the unix time (in milliseconds) of the prover clock when sending the request,
+	// used to estimate the clock skew of the prover.
+	ProverTime int64 `form:"prover_time" json:"prover_time,omitempty"`
+	// Parallelism is the number of tasks the prover can prove at the same time, default 1.
+	Parallelism int `form:"parallelism" json:"parallelism,omitempty"`
+	// ActiveTasks is the number of tasks the prover is proving.
+	ActiveTasks int `form:"active_tasks" json:"active_tasks,omitempty"`
 }
 
 // GetTaskSchema the schema data return to prover for get prover task
@@ -13,4 +22,10 @@ type GetTaskSchema struct {
 	TaskID   string `json:"task_id"`
 	TaskType int    `json:"task_type"`
 	TaskData string `json:"task_data"`
+	// Resources describes the resources needed to prove the task.
+	Resources *message.TaskResources `json:"resources,omitempty"`
+	// ServerTime is the unix time (in milliseconds) of the coordinator clock when assigning the task.
+	ServerTime int64 `json:"server_time,omitempty"`
+	// DeadlineSec is the deadline of the task relative to the assignment, so it doesn't depend on synchronized clocks.
+	DeadlineSec int64 `json:"deadline_sec,omitempty"`
 }
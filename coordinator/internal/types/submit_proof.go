@@ -1,6 +1,7 @@
 package types
 
-// SubmitProofParameter the SubmitProof api request parameter
+// SubmitProofParameter the SubmitProof api request parameter. The submissions aren't signed, the prover
+// identity is verified once by the login signature and the submissions are authenticated by the jwt token.
 type SubmitProofParameter struct {
 	// TODO when prover have upgrade, need change this field to required
 	UUID        string `form:"uuid" json:"uuid"`
@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// ReportProgressParameter the ReportProgress api request parameter
+type ReportProgressParameter struct {
+	UUID     string `form:"uuid" json:"uuid" binding:"required"`
+	TaskID   string `form:"task_id" json:"task_id" binding:"required"`
+	TaskType int    `form:"task_type" json:"task_type" binding:"required"`
+	Stage    string `form:"stage" json:"stage" binding:"required"`
+	Percent  int    `form:"percent" json:"percent"`
+}
+
+// TaskProgressSchema is the progress of an assigned prover task returned by the admin api
+type TaskProgressSchema struct {
+	UUID            string     `json:"uuid"`
+	TaskID          string     `json:"task_id"`
+	TaskType        int        `json:"task_type"`
+	ProverName      string     `json:"prover_name"`
+	ProverPublicKey string     `json:"prover_public_key"`
+	Stage           string     `json:"stage,omitempty"`
+	Percent         int        `json:"percent"`
+	AssignedAt      time.Time  `json:"assigned_at"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+	ElapsedSec      uint64     `json:"elapsed_sec"`
+	// EstimatedRemainingSec is extrapolated from the elapsed time and the percent, absent until the first percent is reported.
+	EstimatedRemainingSec *uint64 `json:"estimated_remaining_sec,omitempty"`
+	// Stuck is set if the prover has not reported progress for the stale duration.
+	Stuck bool `json:"stuck"`
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 
@@ -30,6 +31,7 @@ import (
 	"scroll-tech/coordinator/internal/controller/cron"
 	"scroll-tech/coordinator/internal/orm"
 	"scroll-tech/coordinator/internal/route"
+	coordinatorType "scroll-tech/coordinator/internal/types"
 )
 
 var (
@@ -51,6 +53,8 @@ var (
 	tokenTimeout int
 )
 
+const adminSecret = "admin_secret"
+
 func TestMain(m *testing.M) {
 	base = docker.NewDockerApp()
 	m.Run()
@@ -87,6 +91,7 @@ func setupCoordinator(t *testing.T, proversPerSession uint8, coordinatorURL stri
 			ChallengeExpireDurationSec: tokenTimeout,
 			LoginExpireDurationSec:     tokenTimeout,
 		},
+		Admin: &config.Admin{Secret: adminSecret},
 	}
 
 	proofCollector := cron.NewCollector(context.Background(), db, conf, nil)
@@ -161,6 +166,7 @@ func TestApis(t *testing.T) {
 	t.Run("TestInvalidProof", testInvalidProof)
 	t.Run("TestProofGeneratedFailed", testProofGeneratedFailed)
 	t.Run("TestTimeoutProof", testTimeoutProof)
+	t.Run("TestDrainMode", testDrainMode)
 
 	// Teardown
 	t.Cleanup(func() {
@@ -534,3 +540,80 @@ func testTimeoutProof(t *testing.T) {
 	assert.Equal(t, 2, int(batchMaxAttempts))
 	assert.Equal(t, 0, int(batchActiveAttempts))
 }
+
+func adminRequest(t *testing.T, coordinatorURL, method, path, secret string) (int, *coordinatorType.AdminStatusSchema) {
+	type response struct {
+		ErrCode int                               `json:"errcode"`
+		ErrMsg  string                            `json:"errmsg"`
+		Data    coordinatorType.AdminStatusSchema `json:"data"`
+	}
+
+	var result response
+	resp, err := resty.New().R().
+		SetHeader("Authorization", "Bearer "+secret).
+		SetResult(&result).
+		Execute(method, "http://"+coordinatorURL+"/coordinator/admin"+path)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	return result.ErrCode, &result.Data
+}
+
+func testDrainMode(t *testing.T) {
+	coordinatorURL := randomURL()
+	collector, httpHandler := setupCoordinator(t, 1, coordinatorURL)
+	defer func() {
+		collector.Stop()
+		assert.NoError(t, httpHandler.Shutdown(context.Background()))
+	}()
+
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+	dbChunk, err := chunkOrm.InsertChunk(context.Background(), chunk)
+	assert.NoError(t, err)
+	err = l2BlockOrm.UpdateChunkHashInRange(context.Background(), 0, 100, dbChunk.Hash)
+	assert.NoError(t, err)
+
+	// the admin api refuses the requests without the admin secret
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/status"}, {http.MethodPost, "/drain"}, {http.MethodPost, "/resume"},
+	} {
+		errCode, _ := adminRequest(t, coordinatorURL, route.method, route.path, "wrong_secret")
+		assert.Equal(t, types.ErrCoordinatorAdminUnauthorized, errCode, route.path)
+	}
+
+	errCode, status := adminRequest(t, coordinatorURL, http.MethodGet, "/status", adminSecret)
+	assert.Equal(t, types.Success, errCode)
+	assert.False(t, status.Draining)
+
+	errCode, status = adminRequest(t, coordinatorURL, http.MethodPost, "/drain", adminSecret)
+	assert.Equal(t, types.Success, errCode)
+	assert.True(t, status.Draining)
+
+	// the drain mode is persisted so it is shared by the coordinator instances and survives restarts
+	paused, err := orm.NewOperationPause(db).IsOperationPaused(context.Background(), orm.OperationAssignTask)
+	assert.NoError(t, err)
+	assert.True(t, paused)
+
+	errCode, status = adminRequest(t, coordinatorURL, http.MethodGet, "/status", adminSecret)
+	assert.Equal(t, types.Success, errCode)
+	assert.True(t, status.Draining)
+
+	// no task is assigned while draining
+	chunkProver := newMockProver(t, "prover_drain_test", coordinatorURL, message.ProofTypeChunk)
+	errCode, _ = chunkProver.tryGetProverTask(t, message.ProofTypeChunk)
+	assert.Equal(t, types.ErrCoordinatorDrainMode, errCode)
+	chunkProofStatus, err := chunkOrm.GetProvingStatusByHash(context.Background(), dbChunk.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ProvingTaskUnassigned, chunkProofStatus)
+
+	errCode, status = adminRequest(t, coordinatorURL, http.MethodPost, "/resume", adminSecret)
+	assert.Equal(t, types.Success, errCode)
+	assert.False(t, status.Draining)
+
+	paused, err = orm.NewOperationPause(db).IsOperationPaused(context.Background(), orm.OperationAssignTask)
+	assert.NoError(t, err)
+	assert.False(t, paused)
+
+	proverTask := chunkProver.getProverTask(t, message.ProofTypeChunk)
+	assert.Equal(t, dbChunk.Hash, proverTask.TaskID)
+}
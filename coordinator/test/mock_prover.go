@@ -134,6 +134,17 @@ func (r *mockProver) healthCheckFailure(t *testing.T) bool {
 }
 
 func (r *mockProver) getProverTask(t *testing.T, proofType message.ProofType) *types.GetTaskSchema {
+	errCode, proverTask := r.tryGetProverTask(t, proofType)
+	assert.Equal(t, ctypes.Success, errCode)
+
+	assert.NotEmpty(t, proverTask.TaskID)
+	assert.NotEmpty(t, proverTask.TaskType)
+	assert.NotEmpty(t, proverTask.TaskData)
+	return proverTask
+}
+
+// tryGetProverTask requests a task from coordinator and returns the error code of the response with the task
+func (r *mockProver) tryGetProverTask(t *testing.T, proofType message.ProofType) (int, *types.GetTaskSchema) {
 	// get task from coordinator
 	token := r.connectToCoordinator(t)
 	assert.NotEmpty(t, token)
@@ -154,12 +165,7 @@ func (r *mockProver) getProverTask(t *testing.T, proofType message.ProofType) *t
 		Post("http://" + r.coordinatorURL + "/coordinator/v1/get_task")
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode())
-	assert.Equal(t, ctypes.Success, result.ErrCode)
-
-	assert.NotEmpty(t, result.Data.TaskID)
-	assert.NotEmpty(t, result.Data.TaskType)
-	assert.NotEmpty(t, result.Data.TaskData)
-	return &result.Data
+	return result.ErrCode, &result.Data
 }
 
 func (r *mockProver) submitProof(t *testing.T, proverTaskSchema *types.GetTaskSchema, proofStatus proofStatus, errCode int) {
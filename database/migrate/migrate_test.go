@@ -41,6 +41,7 @@ func TestMigrate(t *testing.T) {
 	t.Run("testResetDB", testResetDB)
 	t.Run("testMigrate", testMigrate)
 	t.Run("testRollback", testRollback)
+	t.Run("testCheckVersion", testCheckVersion)
 
 	t.Cleanup(func() {
 		base.Free()
@@ -63,7 +64,7 @@ func testResetDB(t *testing.T) {
 	cur, err := Current(pgDB.DB)
 	assert.NoError(t, err)
 	// total number of tables.
-	assert.Equal(t, 15, int(cur))
+	assert.Equal(t, 27, int(cur))
 }
 
 func testMigrate(t *testing.T) {
@@ -84,3 +85,17 @@ func testRollback(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, cur+1 == version)
 }
+
+func testCheckVersion(t *testing.T) {
+	latest, err := Latest()
+	assert.NoError(t, err)
+	assert.Equal(t, 27, int(latest))
+
+	assert.NoError(t, Migrate(pgDB.DB))
+	assert.NoError(t, CheckVersion(pgDB.DB))
+
+	assert.NoError(t, Rollback(pgDB.DB, nil))
+	err = CheckVersion(pgDB.DB)
+	assert.ErrorIs(t, err, ErrIncompatibleVersion)
+	assert.Contains(t, err.Error(), "00027_l2_block_chunk_proposal_failures.sql")
+}
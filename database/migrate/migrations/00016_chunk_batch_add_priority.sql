@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE chunk
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE batch
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN chunk.priority IS 'normal, high';
+COMMENT ON COLUMN batch.priority IS 'normal, high';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE IF EXISTS chunk
+    DROP COLUMN IF EXISTS priority;
+
+ALTER TABLE IF EXISTS batch
+    DROP COLUMN IF EXISTS priority;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE estimator_error
+(
+    id                  SERIAL       PRIMARY KEY,
+
+    tx_type             SMALLINT     NOT NULL,
+    target              VARCHAR      NOT NULL, -- empty for contract creation
+    sample_count        BIGINT       NOT NULL DEFAULT 0,
+    estimated_size_sum  BIGINT       NOT NULL DEFAULT 0,
+    actual_size_sum     BIGINT       NOT NULL DEFAULT 0,
+    estimated_gas_sum   BIGINT       NOT NULL DEFAULT 0,
+    actual_gas_sum      BIGINT       NOT NULL DEFAULT 0,
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at          TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX unique_idx_estimator_error_on_tx_type_target ON estimator_error(tx_type, target);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS estimator_error;
+-- +goose StatementEnd
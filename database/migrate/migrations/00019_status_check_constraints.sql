@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE chunk
+    ADD CONSTRAINT check_chunk_proving_status CHECK (proving_status BETWEEN 1 AND 5);
+
+ALTER TABLE batch
+    ADD CONSTRAINT check_batch_chunk_proofs_status CHECK (chunk_proofs_status BETWEEN 1 AND 2),
+    ADD CONSTRAINT check_batch_proving_status CHECK (proving_status BETWEEN 1 AND 5),
+    ADD CONSTRAINT check_batch_rollup_status CHECK (rollup_status BETWEEN 1 AND 7);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE IF EXISTS chunk
+    DROP CONSTRAINT IF EXISTS check_chunk_proving_status;
+
+ALTER TABLE IF EXISTS batch
+    DROP CONSTRAINT IF EXISTS check_batch_chunk_proofs_status,
+    DROP CONSTRAINT IF EXISTS check_batch_proving_status,
+    DROP CONSTRAINT IF EXISTS check_batch_rollup_status;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE operation_pause
+(
+    operation   VARCHAR      PRIMARY KEY, -- propose, commit, finalize or gas_oracle
+    paused      BOOLEAN      NOT NULL DEFAULT FALSE,
+    reason      VARCHAR      NOT NULL DEFAULT '',
+
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS operation_pause;
+-- +goose StatementEnd
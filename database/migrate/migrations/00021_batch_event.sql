@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE batch_event
+(
+    id                  SERIAL       PRIMARY KEY,
+
+    batch_index         BIGINT       NOT NULL,
+    batch_hash          VARCHAR      NOT NULL,
+    rollup_status       SMALLINT     NOT NULL, -- the status the event moves the batch to, committed or finalized
+    l1_block_number     BIGINT       NOT NULL,
+    l1_tx_hash          VARCHAR      NOT NULL,
+
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at          TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX unique_idx_batch_event_on_rollup_status_batch_hash ON batch_event(rollup_status, batch_hash);
+CREATE INDEX idx_batch_event_on_rollup_status_l1_block_number_batch_index ON batch_event(rollup_status, l1_block_number, batch_index);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS batch_event;
+-- +goose StatementEnd
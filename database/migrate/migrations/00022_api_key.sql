@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE api_key
+(
+    id          BIGSERIAL    PRIMARY KEY,
+    key_hash    VARCHAR      NOT NULL, -- sha256 of the key, the key itself is never stored
+    owner       VARCHAR      NOT NULL,
+    tier        VARCHAR      NOT NULL,
+    issued_ip   VARCHAR      NOT NULL DEFAULT '',
+
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_key_key_hash_uindex
+ON api_key(key_hash) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_api_key_issued_ip_created_at
+ON api_key(issued_ip, created_at) WHERE deleted_at IS NULL;
+
+CREATE TABLE api_key_usage
+(
+    api_key_id    BIGINT       NOT NULL,
+    day           DATE         NOT NULL,
+    request_count BIGINT       NOT NULL DEFAULT 0,
+
+    created_at    TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at    TIMESTAMP(0) DEFAULT NULL,
+
+    PRIMARY KEY (api_key_id, day)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_key_usage;
+DROP TABLE IF EXISTS api_key;
+-- +goose StatementEnd
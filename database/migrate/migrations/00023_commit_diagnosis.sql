@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE commit_diagnosis
+(
+    id                          SERIAL       PRIMARY KEY,
+
+    batch_index                 BIGINT       NOT NULL,
+    batch_hash                  VARCHAR      NOT NULL,
+    commit_tx_hash              VARCHAR      NOT NULL,
+    revert_reason               VARCHAR      NOT NULL DEFAULT '', -- the decoded revert of the replayed commit tx
+    local_parent_batch_hash     VARCHAR      NOT NULL DEFAULT '',
+    onchain_parent_batch_hash   VARCHAR      NOT NULL DEFAULT '',
+    onchain_batch_hash          VARCHAR      NOT NULL DEFAULT '', -- the batch committed at the index on layer 1, if any
+    cause                       VARCHAR      NOT NULL,
+    detail                      VARCHAR      NOT NULL DEFAULT '',
+
+    created_at                  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at                  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at                  TIMESTAMP(0) DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX unique_idx_commit_diagnosis_on_commit_tx_hash ON commit_diagnosis(commit_tx_hash);
+CREATE INDEX idx_commit_diagnosis_on_batch_hash ON commit_diagnosis(batch_hash);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS commit_diagnosis;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE prover_task
+    ADD COLUMN progress_stage VARCHAR DEFAULT NULL,
+    ADD COLUMN progress_percent SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN progress_updated_at TIMESTAMP(0) DEFAULT NULL;
+
+COMMENT ON COLUMN prover_task.progress_stage IS 'fetching_witness, proving, submitting';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE IF EXISTS prover_task
+    DROP COLUMN IF EXISTS progress_stage,
+    DROP COLUMN IF EXISTS progress_percent,
+    DROP COLUMN IF EXISTS progress_updated_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE l2_block
+    ADD COLUMN chunk_proposal_failure_reasons VARCHAR NOT NULL DEFAULT '', -- the distinct chunk limits the block broke on its own, comma separated
+    ADD COLUMN chunk_proposal_status SMALLINT NOT NULL DEFAULT 0;          -- 1 once the block is quarantined into a single-block chunk
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE IF EXISTS l2_block
+    DROP COLUMN IF EXISTS chunk_proposal_failure_reasons,
+    DROP COLUMN IF EXISTS chunk_proposal_status;
+
+-- +goose StatementEnd
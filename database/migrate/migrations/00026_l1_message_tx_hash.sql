@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE l1_message
+    ADD COLUMN tx_hash VARCHAR DEFAULT NULL; -- the hash of the L1MessageTx executed on layer 2, NULL for the messages watched before this column
+
+create index if not exists l1_message_tx_hash_index
+on l1_message (tx_hash) where deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+drop index if exists l1_message_tx_hash_index;
+
+ALTER TABLE IF EXISTS l1_message
+    DROP COLUMN IF EXISTS tx_hash;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE l2_block
+    ADD COLUMN chunk_proposal_failures INTEGER NOT NULL DEFAULT 0; -- the times the block broke the chunk limits on its own or failed proving in a chunk
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE IF EXISTS l2_block
+    DROP COLUMN IF EXISTS chunk_proposal_failures;
+
+-- +goose StatementEnd
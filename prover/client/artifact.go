@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"scroll-tech/common/types/message"
+)
+
+// ErrArtifactChecksumMismatch the checksum of the artifact is not equal to the expected one
+var ErrArtifactChecksumMismatch = errors.New("artifact checksum mismatch")
+
+// VerifyArtifactChecksum checks that the sha256 checksum of the file equals the hex encoded checksum.
+func VerifyArtifactChecksum(path string, checksum string) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to read artifact: %w", err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	expected := strings.ToLower(strings.TrimPrefix(checksum, "0x"))
+	if actual != expected {
+		return fmt.Errorf("%w, path: %s, expected: %s, actual: %s", ErrArtifactChecksumMismatch, path, expected, actual)
+	}
+	return nil
+}
+
+// DownloadArtifact downloads the circuit artifacts to the path and verifies the checksum,
+// the existing file is reused if its checksum matches.
+func DownloadArtifact(ctx context.Context, artifacts *message.CircuitArtifacts, path string) error {
+	if artifacts == nil || artifacts.URL == "" {
+		return errors.New("artifact url is empty")
+	}
+
+	if err := VerifyArtifactChecksum(path, artifacts.Checksum); err == nil {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifacts.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create artifact request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download artifact: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download artifact, status code: %v", resp.StatusCode)
+	}
+
+	tmpPath := path + ".download"
+	f, err := os.Create(filepath.Clean(tmpPath))
+	if err != nil {
+		return fmt.Errorf("failed to create artifact file: %w", err)
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write artifact file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to close artifact file: %w", err)
+	}
+
+	if err = VerifyArtifactChecksum(tmpPath, artifacts.Checksum); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types/message"
+)
+
+func TestVerifyArtifactChecksum(t *testing.T) {
+	content := []byte("circuit artifact")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	path := filepath.Join(t.TempDir(), "artifact")
+	assert.NoError(t, os.WriteFile(path, content, 0600))
+
+	assert.NoError(t, VerifyArtifactChecksum(path, checksum))
+	assert.NoError(t, VerifyArtifactChecksum(path, "0x"+checksum))
+	assert.ErrorIs(t, VerifyArtifactChecksum(path, hex.EncodeToString(make([]byte, 32))), ErrArtifactChecksumMismatch)
+	assert.Error(t, VerifyArtifactChecksum(filepath.Join(t.TempDir(), "missing"), checksum))
+}
+
+func TestDownloadArtifact(t *testing.T) {
+	content := []byte("circuit artifact")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "artifact")
+	artifacts := &message.CircuitArtifacts{Version: "v0.1.0", URL: srv.URL, Checksum: checksum}
+	assert.NoError(t, DownloadArtifact(context.Background(), artifacts, path))
+	assert.NoError(t, VerifyArtifactChecksum(path, checksum))
+
+	badPath := filepath.Join(t.TempDir(), "bad")
+	artifacts.Checksum = hex.EncodeToString(make([]byte, 32))
+	assert.ErrorIs(t, DownloadArtifact(context.Background(), artifacts, badPath), ErrArtifactChecksumMismatch)
+	_, err := os.Stat(badPath)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -3,8 +3,14 @@ package client
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -13,27 +19,52 @@ import (
 	"scroll-tech/prover/config"
 
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/coordinatorpb"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/version"
 )
 
 // CoordinatorClient is a client used for interacting with the Coordinator service.
+//
+// The client sends its requests to the first reachable of the configured coordinators. Once the current
+// coordinator is unreachable, it fails over to the next one, logs in to it and resends the request there.
+// The coordinators share the task assignments, so the tasks in flight are kept by the prover and their
+// proofs are submitted to whichever coordinator is current when they are done.
 type CoordinatorClient struct {
 	client *resty.Client
 
+	// baseURLs are the urls of the coordinators in failover order, endpoint is the index of the current one.
+	baseURLs []string
+	endpoint atomic.Int64
+
 	proverName string
 	priv       *ecdsa.PrivateKey
 
+	// polling is set if the client uses the polling transport of the coordinator.
+	polling bool
+
+	// protobufSupported is set once the coordinator answered a task in protobuf,
+	// the proofs are submitted in protobuf from then on.
+	protobufSupported atomic.Bool
+
 	mu sync.Mutex
 }
 
 // NewCoordinatorClient constructs a new CoordinatorClient.
 func NewCoordinatorClient(cfg *config.CoordinatorConfig, proverName string, priv *ecdsa.PrivateKey) (*CoordinatorClient, error) {
+	var polling bool
+	switch cfg.Transport {
+	case "", config.TransportHTTP:
+	case config.TransportPolling:
+		polling = true
+	default:
+		return nil, fmt.Errorf("unknown coordinator transport: %v", cfg.Transport)
+	}
+
 	client := resty.New().
 		SetTimeout(time.Duration(cfg.ConnectionTimeoutSec) * time.Second).
 		SetRetryCount(cfg.RetryCount).
 		SetRetryWaitTime(time.Duration(cfg.RetryWaitTimeSec) * time.Second).
-		SetBaseURL(cfg.BaseURL).
 		AddRetryAfterErrorCondition().
 		AddRetryCondition(func(response *resty.Response, err error) bool {
 			if err != nil {
@@ -42,35 +73,56 @@ func NewCoordinatorClient(cfg *config.CoordinatorConfig, proverName string, priv
 			}
 			return response.IsError()
 		})
+	if polling {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DisableKeepAlives = true
+		client.SetTransport(transport)
+	}
 
 	log.Info("successfully initialized prover client",
 		"base url", cfg.BaseURL,
+		"backup urls", cfg.BackupURLs,
+		"transport", cfg.Transport,
 		"connection timeout (second)", cfg.ConnectionTimeoutSec,
 		"retry count", cfg.RetryCount,
 		"retry wait time (second)", cfg.RetryWaitTimeSec)
 
 	return &CoordinatorClient{
 		client:     client,
+		baseURLs:   append([]string{cfg.BaseURL}, cfg.BackupURLs...),
 		proverName: proverName,
 		priv:       priv,
+		polling:    polling,
 	}, nil
 }
 
-// Login completes the entire login process in one function call.
+// Login completes the entire login process in one function call, failing over to the next coordinator
+// while the current one is unreachable.
 func (c *CoordinatorClient) Login(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	err := c.login(ctx)
+	for i := 1; i < len(c.baseURLs) && errors.Is(err, ErrCoordinatorConnect); i++ {
+		log.Warn("coordinator unreachable, failing over", "from", c.baseURL(), "err", err)
+		c.switchEndpoint()
+		err = c.login(ctx)
+	}
+	return err
+}
+
+// login logs in to the current coordinator, the caller must hold mu.
+func (c *CoordinatorClient) login(ctx context.Context) error {
 	var challengeResult ChallengeResponse
 
 	// Get random string
 	challengeResp, err := c.client.R().
 		SetHeader("Content-Type", "application/json").
 		SetResult(&challengeResult).
-		Get("/coordinator/v1/challenge")
+		Get(c.url("/coordinator/v1/challenge"))
 
-	if err != nil {
-		return fmt.Errorf("get random string failed: %w", err)
+	if isUnreachable(challengeResp, err) {
+		return fmt.Errorf("get random string failed: %w, err: %v", ErrCoordinatorConnect, err)
 	}
 
 	if challengeResp.StatusCode() != 200 {
@@ -113,10 +165,10 @@ func (c *CoordinatorClient) Login(ctx context.Context) error {
 		SetHeader("Content-Type", "application/json").
 		SetBody(loginReq).
 		SetResult(&loginResult).
-		Post("/coordinator/v1/login")
+		Post(c.url("/coordinator/v1/login"))
 
-	if err != nil {
-		return fmt.Errorf("login failed: %w", err)
+	if isUnreachable(loginResp, err) {
+		return fmt.Errorf("login failed: %w, err: %v", ErrCoordinatorConnect, err)
 	}
 
 	if loginResp.StatusCode() != 200 {
@@ -135,13 +187,12 @@ func (c *CoordinatorClient) Login(ctx context.Context) error {
 
 // GetTask sends a request to the coordinator to get prover task.
 func (c *CoordinatorClient) GetTask(ctx context.Context, req *GetTaskRequest) (*GetTaskResponse, error) {
-	var result GetTaskResponse
-
-	resp, err := c.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(req).
-		SetResult(&result).
-		Post("/coordinator/v1/get_task")
+	resp, err := c.post(ctx, c.path("get_task", "claim_task"), func() *resty.Request {
+		return c.client.R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader("Accept", coordinatorpb.AcceptHeader).
+			SetBody(req)
+	})
 
 	if err != nil {
 		return nil, fmt.Errorf("request for GetTask failed: %w", err)
@@ -151,6 +202,11 @@ func (c *CoordinatorClient) GetTask(ctx context.Context, req *GetTaskRequest) (*
 		return nil, fmt.Errorf("failed to get task, status code: %v", resp.StatusCode())
 	}
 
+	result, err := c.decodeGetTaskResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode GetTask response: %w", err)
+	}
+
 	if result.ErrCode == types.ErrJWTTokenExpired {
 		log.Info("JWT expired, attempting to re-login")
 		if err := c.Login(ctx); err != nil {
@@ -163,18 +219,73 @@ func (c *CoordinatorClient) GetTask(ctx context.Context, req *GetTaskRequest) (*
 		return nil, fmt.Errorf("error code: %v, error message: %v", result.ErrCode, result.ErrMsg)
 	}
 
-	return &result, nil
+	return result, nil
+}
+
+// decodeGetTaskResponse decodes the response in the encoding chosen by the coordinator, the coordinators
+// not supporting protobuf and the failures are answered in json.
+func (c *CoordinatorClient) decodeGetTaskResponse(resp *resty.Response) (*GetTaskResponse, error) {
+	if !coordinatorpb.IsContentType(resp.Header().Get("Content-Type")) {
+		var result GetTaskResponse
+		if err := json.Unmarshal(resp.Body(), &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	var pbResult coordinatorpb.GetTaskResponse
+	if err := pbResult.Unmarshal(resp.Body()); err != nil {
+		return nil, err
+	}
+	if !c.protobufSupported.Swap(true) {
+		log.Info("coordinator supports protobuf encoding, switching proof submission to protobuf")
+	}
+
+	result := &GetTaskResponse{ErrCode: pbResult.ErrCode, ErrMsg: pbResult.ErrMsg}
+	if data := pbResult.Data; data != nil {
+		result.Data = &GetTaskData{
+			UUID:        data.UUID,
+			TaskID:      data.TaskID,
+			TaskType:    data.TaskType,
+			TaskData:    data.TaskData,
+			Resources:   data.Resources,
+			ServerTime:  data.ServerTime,
+			DeadlineSec: data.DeadlineSec,
+		}
+	}
+	return result, nil
 }
 
 // SubmitProof sends a request to the coordinator to submit proof.
 func (c *CoordinatorClient) SubmitProof(ctx context.Context, req *SubmitProofRequest) error {
 	var result SubmitProofResponse
 
-	resp, err := c.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(req).
-		SetResult(&result).
-		Post("/coordinator/v1/submit_proof")
+	resp, err := c.post(ctx, c.path("submit_proof", "submit_proof"), func() *resty.Request {
+		request := c.client.R().SetResult(&result)
+		if c.polling {
+			return request.SetMultipartFormData(map[string]string{
+				"uuid":         req.UUID,
+				"task_id":      req.TaskID,
+				"task_type":    strconv.Itoa(req.TaskType),
+				"status":       strconv.Itoa(req.Status),
+				"failure_type": strconv.Itoa(req.FailureType),
+				"failure_msg":  req.FailureMsg,
+			}).SetMultipartField("proof", "proof.json", "application/json", strings.NewReader(req.Proof))
+		}
+		if c.protobufSupported.Load() {
+			pbReq := coordinatorpb.SubmitProofRequest{
+				UUID:        req.UUID,
+				TaskID:      req.TaskID,
+				TaskType:    req.TaskType,
+				Status:      req.Status,
+				Proof:       req.Proof,
+				FailureType: req.FailureType,
+				FailureMsg:  req.FailureMsg,
+			}
+			return request.SetHeader("Content-Type", coordinatorpb.ContentType).SetBody(pbReq.Marshal())
+		}
+		return request.SetHeader("Content-Type", "application/json").SetBody(req)
+	})
 
 	if err != nil {
 		log.Error("submit proof request failed", "error", err)
@@ -202,3 +313,137 @@ func (c *CoordinatorClient) SubmitProof(ctx context.Context, req *SubmitProofReq
 
 	return nil
 }
+
+// DeclineTask sends a request to the coordinator to decline the assigned task with a machine-readable reason.
+func (c *CoordinatorClient) DeclineTask(ctx context.Context, req *DeclineTaskRequest) error {
+	var result DeclineTaskResponse
+
+	resp, err := c.post(ctx, "/coordinator/v1/decline_task", func() *resty.Request {
+		return c.client.R().
+			SetHeader("Content-Type", "application/json").
+			SetBody(req).
+			SetResult(&result)
+	})
+
+	if err != nil {
+		return fmt.Errorf("decline task request failed: %w", ErrCoordinatorConnect)
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("failed to decline task, status code not 200: %w", ErrCoordinatorConnect)
+	}
+
+	if result.ErrCode == types.ErrJWTTokenExpired {
+		log.Info("JWT expired, attempting to re-login")
+		if err := c.Login(ctx); err != nil {
+			return fmt.Errorf("JWT expired, re-login failed: %w", ErrCoordinatorConnect)
+		}
+		log.Info("re-login success")
+		return c.DeclineTask(ctx, req)
+	}
+
+	if result.ErrCode != types.Success {
+		return fmt.Errorf("error code: %v, error message: %v", result.ErrCode, result.ErrMsg)
+	}
+
+	return nil
+}
+
+// post sends the request built by newRequest to the current coordinator. While the coordinator is unreachable,
+// it fails over to the next one and, once logged in to it, sends the request again, each coordinator is tried once.
+func (c *CoordinatorClient) post(ctx context.Context, path string, newRequest func() *resty.Request) (*resty.Response, error) {
+	endpoint := c.endpoint.Load()
+	resp, err := newRequest().Post(c.url(path))
+	for i := 1; i < len(c.baseURLs) && isUnreachable(resp, err); i++ {
+		var loginErr error
+		if endpoint, loginErr = c.failover(ctx, endpoint, err); loginErr != nil {
+			log.Warn("failed to login after failing over", "url", c.baseURLs[endpoint], "err", loginErr)
+			continue
+		}
+		resp, err = newRequest().Post(c.url(path))
+	}
+	return resp, err
+}
+
+// failover switches from the unreachable coordinator of the given endpoint to the next one and logs in to it,
+// unless a concurrent request has already failed over, and returns the new endpoint.
+func (c *CoordinatorClient) failover(ctx context.Context, from int64, cause error) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if endpoint := c.endpoint.Load(); endpoint != from {
+		return endpoint, nil
+	}
+	log.Warn("coordinator unreachable, failing over", "from", c.baseURL(), "err", cause)
+	endpoint := c.switchEndpoint()
+	return endpoint, c.login(ctx)
+}
+
+// switchEndpoint makes the next coordinator the current one, the caller must hold mu. The encoding is
+// negotiated again with the new coordinator.
+func (c *CoordinatorClient) switchEndpoint() int64 {
+	endpoint := (c.endpoint.Load() + 1) % int64(len(c.baseURLs))
+	c.endpoint.Store(endpoint)
+	c.protobufSupported.Store(false)
+	log.Info("switched coordinator", "url", c.baseURLs[endpoint])
+	return endpoint
+}
+
+// baseURL returns the url of the current coordinator.
+func (c *CoordinatorClient) baseURL() string {
+	return c.baseURLs[c.endpoint.Load()]
+}
+
+// url returns the url of the coordinator api on the current coordinator.
+func (c *CoordinatorClient) url(path string) string {
+	return strings.TrimSuffix(c.baseURL(), "/") + path
+}
+
+// isUnreachable reports whether the coordinator could not serve the request, the requests failing on it
+// may succeed on another coordinator.
+func isUnreachable(resp *resty.Response, err error) bool {
+	return err != nil || resp.StatusCode() >= http.StatusInternalServerError
+}
+
+// path returns the path of the coordinator api, the polling transport uses the polling api.
+func (c *CoordinatorClient) path(name, pollingName string) string {
+	if c.polling {
+		return "/coordinator/v1/poll/" + pollingName
+	}
+	return "/coordinator/v1/" + name
+}
+
+// ReportProgress sends a request to the coordinator to report the progress of the assigned task.
+func (c *CoordinatorClient) ReportProgress(ctx context.Context, req *ReportProgressRequest) error {
+	var result ReportProgressResponse
+
+	resp, err := c.post(ctx, "/coordinator/v1/report_progress", func() *resty.Request {
+		return c.client.R().
+			SetHeader("Content-Type", "application/json").
+			SetBody(req).
+			SetResult(&result)
+	})
+
+	if err != nil {
+		return fmt.Errorf("report progress request failed: %w", ErrCoordinatorConnect)
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("failed to report progress, status code not 200: %w", ErrCoordinatorConnect)
+	}
+
+	if result.ErrCode == types.ErrJWTTokenExpired {
+		log.Info("JWT expired, attempting to re-login")
+		if err := c.Login(ctx); err != nil {
+			return fmt.Errorf("JWT expired, re-login failed: %w", ErrCoordinatorConnect)
+		}
+		log.Info("re-login success")
+		return c.ReportProgress(ctx, req)
+	}
+
+	if result.ErrCode != types.Success {
+		return fmt.Errorf("error code: %v, error message: %v", result.ErrCode, result.ErrMsg)
+	}
+
+	return nil
+}
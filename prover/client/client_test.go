@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/coordinatorpb"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/prover/config"
+)
+
+func TestEncodingNegotiation(t *testing.T) {
+	for _, supportsProtobuf := range []bool{false, true} {
+		var submitted SubmitProofRequest
+		var submittedContentType string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/coordinator/v1/get_task":
+				data := &GetTaskData{UUID: "uuid", TaskID: "task", TaskType: int(message.ProofTypeChunk), TaskData: `{"block_hashes":[]}`, DeadlineSec: 300}
+				if supportsProtobuf && coordinatorpb.Accepts(r.Header.Get("Accept")) {
+					resp := coordinatorpb.GetTaskResponse{Data: (*coordinatorpb.GetTaskData)(data)}
+					w.Header().Set("Content-Type", coordinatorpb.ContentType)
+					_, _ = w.Write(resp.Marshal())
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(&GetTaskResponse{Data: data})
+			case "/coordinator/v1/submit_proof":
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				submittedContentType = r.Header.Get("Content-Type")
+				if coordinatorpb.IsContentType(submittedContentType) {
+					var req coordinatorpb.SubmitProofRequest
+					assert.NoError(t, req.Unmarshal(body))
+					submitted = SubmitProofRequest(req)
+				} else {
+					assert.NoError(t, json.Unmarshal(body, &submitted))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(&SubmitProofResponse{ErrCode: types.Success})
+			}
+		}))
+
+		client, err := NewCoordinatorClient(&config.CoordinatorConfig{BaseURL: srv.URL, ConnectionTimeoutSec: 5}, "prover", nil)
+		assert.NoError(t, err)
+
+		resp, err := client.GetTask(context.Background(), &GetTaskRequest{TaskType: message.ProofTypeChunk})
+		assert.NoError(t, err)
+		assert.Equal(t, "task", resp.Data.TaskID)
+		assert.Equal(t, `{"block_hashes":[]}`, resp.Data.TaskData)
+		assert.Equal(t, int64(300), resp.Data.DeadlineSec)
+
+		req := &SubmitProofRequest{UUID: "uuid", TaskID: "task", TaskType: int(message.ProofTypeChunk), Status: int(message.StatusOk), Proof: `{"proof":"AAEC"}`}
+		assert.NoError(t, client.SubmitProof(context.Background(), req))
+		assert.Equal(t, *req, submitted)
+		assert.Equal(t, supportsProtobuf, coordinatorpb.IsContentType(submittedContentType))
+
+		srv.Close()
+	}
+}
+
+func TestPollingTransport(t *testing.T) {
+	var submitted SubmitProofRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, r.Close)
+		switch r.URL.Path {
+		case "/coordinator/v1/poll/claim_task":
+			data := &GetTaskData{UUID: "uuid", TaskID: "task", TaskType: int(message.ProofTypeBatch), TaskData: "{}"}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(&GetTaskResponse{Data: data})
+		case "/coordinator/v1/poll/submit_proof":
+			assert.NoError(t, r.ParseMultipartForm(1<<20))
+			file, _, err := r.FormFile("proof")
+			assert.NoError(t, err)
+			proof, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			taskType, err := strconv.Atoi(r.FormValue("task_type"))
+			assert.NoError(t, err)
+			status, err := strconv.Atoi(r.FormValue("status"))
+			assert.NoError(t, err)
+			submitted = SubmitProofRequest{UUID: r.FormValue("uuid"), TaskID: r.FormValue("task_id"), TaskType: taskType, Status: status, Proof: string(proof)}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(&SubmitProofResponse{ErrCode: types.Success})
+		default:
+			t.Errorf("unexpected path %v", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	_, err := NewCoordinatorClient(&config.CoordinatorConfig{BaseURL: srv.URL, Transport: "grpc"}, "prover", nil)
+	assert.Error(t, err)
+
+	client, err := NewCoordinatorClient(&config.CoordinatorConfig{BaseURL: srv.URL, ConnectionTimeoutSec: 5, Transport: config.TransportPolling}, "prover", nil)
+	assert.NoError(t, err)
+
+	resp, err := client.GetTask(context.Background(), &GetTaskRequest{TaskType: message.ProofTypeBatch})
+	assert.NoError(t, err)
+	assert.Equal(t, "task", resp.Data.TaskID)
+
+	req := &SubmitProofRequest{UUID: "uuid", TaskID: "task", TaskType: int(message.ProofTypeBatch), Status: int(message.StatusOk), Proof: `{"proof":"AAEC"}`}
+	assert.NoError(t, client.SubmitProof(context.Background(), req))
+	assert.Equal(t, *req, submitted)
+}
+
+func TestCoordinatorFailover(t *testing.T) {
+	var primaryDown atomic.Bool
+	newCoordinator := func(name string, logins *atomic.Int32, submitted *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if name == "primary" && primaryDown.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/coordinator/v1/challenge":
+				_, _ = w.Write([]byte(`{"errcode":0,"data":{"token":"challenge"}}`))
+			case "/coordinator/v1/login":
+				logins.Add(1)
+				_, _ = w.Write([]byte(`{"errcode":0,"data":{"token":"` + name + `"}}`))
+			case "/coordinator/v1/get_task":
+				assert.Equal(t, "Bearer "+name, r.Header.Get("Authorization"))
+				_ = json.NewEncoder(w).Encode(&GetTaskResponse{Data: &GetTaskData{UUID: "uuid", TaskID: name, TaskType: int(message.ProofTypeChunk)}})
+			case "/coordinator/v1/submit_proof":
+				assert.Equal(t, "Bearer "+name, r.Header.Get("Authorization"))
+				submitted.Add(1)
+				_ = json.NewEncoder(w).Encode(&SubmitProofResponse{ErrCode: types.Success})
+			default:
+				t.Errorf("unexpected path %v", r.URL.Path)
+			}
+		}))
+	}
+	var primaryLogins, backupLogins, primarySubmitted, backupSubmitted atomic.Int32
+	primary := newCoordinator("primary", &primaryLogins, &primarySubmitted)
+	defer primary.Close()
+	backup := newCoordinator("backup", &backupLogins, &backupSubmitted)
+	defer backup.Close()
+
+	priv, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	client, err := NewCoordinatorClient(&config.CoordinatorConfig{BaseURL: primary.URL, BackupURLs: []string{backup.URL}, ConnectionTimeoutSec: 5}, "prover", priv)
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Login(context.Background()))
+	resp, err := client.GetTask(context.Background(), &GetTaskRequest{TaskType: message.ProofTypeChunk})
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", resp.Data.TaskID)
+
+	// the task in flight is submitted to the backup coordinator once logged in to it
+	primaryDown.Store(true)
+	req := &SubmitProofRequest{UUID: "uuid", TaskID: resp.Data.TaskID, TaskType: int(message.ProofTypeChunk), Status: int(message.StatusOk), Proof: "{}"}
+	assert.NoError(t, client.SubmitProof(context.Background(), req))
+	assert.Equal(t, int32(1), backupLogins.Load())
+	assert.Equal(t, int32(1), backupSubmitted.Load())
+	assert.Equal(t, int32(0), primarySubmitted.Load())
+
+	// the backup coordinator stays the current one
+	primaryDown.Store(false)
+	resp, err = client.GetTask(context.Background(), &GetTaskRequest{TaskType: message.ProofTypeChunk})
+	assert.NoError(t, err)
+	assert.Equal(t, "backup", resp.Data.TaskID)
+	assert.Equal(t, int32(1), backupLogins.Load())
+
+	// the client fails over at login as well, and back to the primary coordinator
+	backup.Close()
+	assert.NoError(t, client.Login(context.Background()))
+	assert.Equal(t, int32(2), primaryLogins.Load())
+	resp, err = client.GetTask(context.Background(), &GetTaskRequest{TaskType: message.ProofTypeChunk})
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", resp.Data.TaskID)
+
+	// every coordinator unreachable
+	primaryDown.Store(true)
+	err = client.SubmitProof(context.Background(), req)
+	assert.ErrorIs(t, err, ErrCoordinatorConnect)
+}
@@ -44,18 +44,27 @@ type GetTaskRequest struct {
 	TaskType     message.ProofType `json:"task_type"`
 	ProverHeight uint64            `json:"prover_height,omitempty"`
 	VK           string            `json:"vk"`
+	ProverTime   int64             `json:"prover_time,omitempty"`
+	Parallelism  int               `json:"parallelism,omitempty"`
+	ActiveTasks  int               `json:"active_tasks,omitempty"`
 }
 
 // GetTaskResponse defines the response structure for GetTask API
 type GetTaskResponse struct {
-	ErrCode int    `json:"errcode"`
-	ErrMsg  string `json:"errmsg"`
-	Data    *struct {
-		UUID     string `json:"uuid"`
-		TaskID   string `json:"task_id"`
-		TaskType int    `json:"task_type"`
-		TaskData string `json:"task_data"`
-	} `json:"data"`
+	ErrCode int          `json:"errcode"`
+	ErrMsg  string       `json:"errmsg"`
+	Data    *GetTaskData `json:"data"`
+}
+
+// GetTaskData defines the task assigned by the GetTask API
+type GetTaskData struct {
+	UUID        string                 `json:"uuid"`
+	TaskID      string                 `json:"task_id"`
+	TaskType    int                    `json:"task_type"`
+	TaskData    string                 `json:"task_data"`
+	Resources   *message.TaskResources `json:"resources,omitempty"`
+	ServerTime  int64                  `json:"server_time,omitempty"`
+	DeadlineSec int64                  `json:"deadline_sec,omitempty"`
 }
 
 // SubmitProofRequest defines the request structure for the SubmitProof API.
@@ -74,3 +83,33 @@ type SubmitProofResponse struct {
 	ErrCode int    `json:"errcode"`
 	ErrMsg  string `json:"errmsg"`
 }
+
+// DeclineTaskRequest defines the request structure for the DeclineTask API.
+type DeclineTaskRequest struct {
+	UUID     string                    `json:"uuid"`
+	TaskID   string                    `json:"task_id"`
+	TaskType int                       `json:"task_type"`
+	Reason   message.TaskDeclineReason `json:"reason"`
+	Message  string                    `json:"message,omitempty"`
+}
+
+// DeclineTaskResponse defines the response structure for the DeclineTask API.
+type DeclineTaskResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// ReportProgressRequest defines the request structure for the ReportProgress API.
+type ReportProgressRequest struct {
+	UUID     string                    `json:"uuid"`
+	TaskID   string                    `json:"task_id"`
+	TaskType int                       `json:"task_type"`
+	Stage    message.TaskProgressStage `json:"stage"`
+	Percent  int                       `json:"percent"`
+}
+
+// ReportProgressResponse defines the response structure for the ReportProgress API.
+type ReportProgressResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
@@ -11,6 +11,7 @@ import (
 
 	"scroll-tech/prover"
 
+	"scroll-tech/common/observability"
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
 
@@ -51,6 +52,9 @@ func action(ctx *cli.Context) error {
 	// Start prover.
 	r.Start()
 
+	observability.RegisterStateDumper("prover", r.DebugState)
+	observability.DebugServer(ctx)
+
 	defer r.Stop()
 	log.Info(
 		"prover start successfully",
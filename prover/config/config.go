@@ -28,16 +28,34 @@ type ProverCoreConfig struct {
 	AssetsPath string            `json:"assets_path"`
 	ProofType  message.ProofType `json:"proof_type,omitempty"` // 1: chunk prover (default type), 2: batch prover
 	DumpDir    string            `json:"dump_dir,omitempty"`
+	// WitnessReduction is the witness reduction mode of chunk tasks: "full" (default) or "state_diff",
+	// only enable "state_diff" when the circuit supports the minimized witnesses.
+	WitnessReduction string `json:"witness_reduction,omitempty"`
+	// MaxMemoryMB is the memory available for proving, tasks expecting more memory are declined. 0 means unlimited.
+	MaxMemoryMB uint64 `json:"max_memory_mb,omitempty"`
 }
 
 // CoordinatorConfig represents the configuration for the Coordinator client.
 type CoordinatorConfig struct {
-	BaseURL              string `json:"base_url"`
-	RetryCount           int    `json:"retry_count"`
-	RetryWaitTimeSec     int    `json:"retry_wait_time_sec"`
-	ConnectionTimeoutSec int    `json:"connection_timeout_sec"`
+	BaseURL string `json:"base_url"`
+	// BackupURLs are the coordinators the client fails over to, in order, once the current one is unreachable.
+	BackupURLs           []string `json:"backup_urls,omitempty"`
+	RetryCount           int      `json:"retry_count"`
+	RetryWaitTimeSec     int      `json:"retry_wait_time_sec"`
+	ConnectionTimeoutSec int      `json:"connection_timeout_sec"`
+	// Transport is "http" (the default) or "polling", the polling transport opens a connection per request
+	// and uploads the proofs as multipart files, for the environments which can not hold long-lived connections.
+	Transport string `json:"transport,omitempty"`
+	// ProgressIntervalSec is the interval (in seconds) of the task progress reports, 0 disables the reports.
+	ProgressIntervalSec int `json:"progress_interval_sec,omitempty"`
 }
 
+// The transports of the coordinator client.
+const (
+	TransportHTTP    = "http"
+	TransportPolling = "polling"
+)
+
 // L2GethConfig represents the configuration for the l2geth client.
 type L2GethConfig struct {
 	Endpoint      string          `json:"endpoint"`
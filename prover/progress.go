@@ -0,0 +1,127 @@
+package prover
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/prover/client"
+	"scroll-tech/prover/store"
+
+	"scroll-tech/common/types/message"
+)
+
+// progressReporter reports the progress of the task being proved to the coordinator periodically and
+// on every stage change. A nil reporter reports nothing, so the reports are disabled by a zero interval.
+type progressReporter struct {
+	r    *Prover
+	task *store.ProvingTask
+
+	mu           sync.Mutex
+	stage        message.TaskProgressStage
+	stageStarted time.Time
+
+	stageChan chan struct{}
+	stopChan  chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+// startProgressReporter starts reporting the progress of the task, it returns nil if the reports are disabled.
+func (r *Prover) startProgressReporter(task *store.ProvingTask) *progressReporter {
+	if r.cfg.Coordinator.ProgressIntervalSec <= 0 {
+		return nil
+	}
+	p := &progressReporter{
+		r:         r,
+		task:      task,
+		stageChan: make(chan struct{}, 1),
+		stopChan:  make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go p.loop(time.Duration(r.cfg.Coordinator.ProgressIntervalSec) * time.Second)
+	return p
+}
+
+// setStage moves the task to the stage and reports it right away.
+func (p *progressReporter) setStage(stage message.TaskProgressStage) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.stage, p.stageStarted = stage, time.Now()
+	p.mu.Unlock()
+
+	select {
+	case p.stageChan <- struct{}{}:
+	default:
+	}
+}
+
+// stop stops the reports and waits for the pending one.
+func (p *progressReporter) stop() {
+	if p == nil {
+		return
+	}
+	p.stopOnce.Do(func() { close(p.stopChan) })
+	<-p.done
+}
+
+func (p *progressReporter) loop(interval time.Duration) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stageChan:
+		case <-ticker.C:
+		case <-p.stopChan:
+			return
+		case <-p.r.ctx.Done():
+			return
+		}
+		p.report()
+	}
+}
+
+func (p *progressReporter) report() {
+	p.mu.Lock()
+	stage, stageStarted := p.stage, p.stageStarted
+	p.mu.Unlock()
+	if stage == "" {
+		return
+	}
+
+	req := &client.ReportProgressRequest{
+		UUID:     p.task.Task.UUID,
+		TaskID:   p.task.Task.ID,
+		TaskType: int(p.task.Task.Type),
+		Stage:    stage,
+		Percent:  progressPercent(stage, time.Since(stageStarted), p.task.Task.Resources),
+	}
+	if err := p.r.coordinatorClient.ReportProgress(p.r.ctx, req); err != nil {
+		log.Warn("failed to report task progress", "task-type", req.TaskType, "task-id", req.TaskID, "stage", stage, "err", err)
+	}
+}
+
+// progressPercent estimates the progress of the task, the proving stage takes the bulk of the task and
+// advances with the estimated duration of the task given by the coordinator.
+func progressPercent(stage message.TaskProgressStage, elapsed time.Duration, resources *message.TaskResources) int {
+	switch stage {
+	case message.TaskProgressStageProving:
+		if resources == nil || resources.EstimatedDurationSec == 0 {
+			return 10
+		}
+		percent := 10 + int(85*elapsed.Seconds()/float64(resources.EstimatedDurationSec))
+		if percent > 95 {
+			return 95
+		}
+		return percent
+	case message.TaskProgressStageSubmitting:
+		return 95
+	default:
+		return 0
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"sync/atomic"
 	"time"
@@ -21,6 +23,7 @@ import (
 	"scroll-tech/prover/core"
 	"scroll-tech/prover/store"
 	putils "scroll-tech/prover/utils"
+	"scroll-tech/prover/witness"
 
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/utils"
@@ -29,8 +32,13 @@ import (
 var (
 	// retry connecting to coordinator
 	retryWait = time.Second * 10
+	// maxClockSkew is the max tolerated clock skew against the coordinator
+	maxClockSkew = time.Second * 5
 )
 
+// parallelism is the number of tasks the prover proves at the same time, the tasks are proved one by one.
+const parallelism = 1
+
 // Prover contains websocket conn to coordinator, and task stack.
 type Prover struct {
 	ctx               context.Context
@@ -39,6 +47,7 @@ type Prover struct {
 	stack             *store.Stack
 	l2GethClient      *ethclient.Client // only applicable for a chunk_prover
 	proverCore        *core.ProverCore
+	witnessReducer    witness.Reducer
 
 	isClosed int64
 	stopChan chan struct{}
@@ -82,6 +91,11 @@ func NewProver(ctx context.Context, cfg *config.Config) (*Prover, error) {
 	}
 	log.Info("init prover_core successfully!")
 
+	witnessReducer, err := witness.NewReducer(cfg.Core.WitnessReduction)
+	if err != nil {
+		return nil, err
+	}
+
 	coordinatorClient, err := client.NewCoordinatorClient(cfg.Coordinator, cfg.ProverName, priv)
 	if err != nil {
 		return nil, err
@@ -94,6 +108,7 @@ func NewProver(ctx context.Context, cfg *config.Config) (*Prover, error) {
 		l2GethClient:      l2GethClient,
 		stack:             stackDb,
 		proverCore:        newProverCore,
+		witnessReducer:    witnessReducer,
 		stopChan:          make(chan struct{}),
 		priv:              priv,
 	}, nil
@@ -109,6 +124,28 @@ func (r *Prover) PublicKey() string {
 	return common.Bytes2Hex(crypto.CompressPubkey(&r.priv.PublicKey))
 }
 
+// DebugState returns the state of the prover for the debug server, the task is the one being proved.
+func (r *Prover) DebugState() interface{} {
+	state := map[string]interface{}{
+		"name":       r.cfg.ProverName,
+		"type":       r.Type(),
+		"public_key": r.PublicKey(),
+	}
+	task, err := r.stack.Peek()
+	switch {
+	case err == nil:
+		state["task"] = map[string]interface{}{
+			"id":       task.Task.ID,
+			"type":     task.Task.Type,
+			"times":    task.Times,
+			"deadline": task.Deadline,
+		}
+	case !errors.Is(err, store.ErrEmpty):
+		state["task_error"] = err.Error()
+	}
+	return state
+}
+
 // Start runs Prover.
 func (r *Prover) Start() {
 	log.Info("start to login to coordinator")
@@ -160,12 +197,20 @@ func (r *Prover) proveAndSubmit() error {
 			return fmt.Errorf("failed to update times on stack: %v", err)
 		}
 
+		if task.Deadline != 0 && time.Now().Unix() > task.Deadline {
+			log.Warn("task deadline has passed, the proof may be rejected", "task-type", task.Task.Type, "task-id", task.Task.ID, "deadline", time.Unix(task.Deadline, 0))
+		}
+
+		progress := r.startProgressReporter(task)
+		defer progress.stop()
+
 		log.Info("start to prove task", "task-type", task.Task.Type, "task-id", task.Task.ID)
-		proofMsg, err = r.prove(task)
+		proofMsg, err = r.prove(task, progress)
 		if err != nil { // handling error from prove
 			log.Error("failed to prove task", "task_type", task.Task.Type, "task-id", task.Task.ID, "err", err)
 			return r.submitErr(task, message.ProofFailureNoPanic, err)
 		}
+		progress.setStage(message.TaskProgressStageSubmitting)
 		return r.submitProof(proofMsg, task.Task.UUID)
 	}
 
@@ -182,6 +227,8 @@ func (r *Prover) fetchTaskFromCoordinator() (*store.ProvingTask, error) {
 		// we may not be able to get the vk at the first time, so we should pass vk to the coordinator every time we getTask
 		// instead of passing vk when we login
 		VK: r.proverCore.VK,
+		// a new task is fetched once the stack is empty, so no task is active
+		Parallelism: parallelism,
 	}
 
 	if req.TaskType == message.ProofTypeChunk {
@@ -198,16 +245,27 @@ func (r *Prover) fetchTaskFromCoordinator() (*store.ProvingTask, error) {
 	}
 
 	// send the request
+	sent := time.Now()
+	req.ProverTime = sent.UnixMilli()
 	resp, err := r.coordinatorClient.GetTask(r.ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task, req: %v, err: %v", req, err)
 	}
+	received := time.Now()
+
+	if resp.Data.ServerTime != 0 {
+		skew := putils.EstimateClockSkew(sent, received, resp.Data.ServerTime)
+		if skew > maxClockSkew || skew < -maxClockSkew {
+			log.Warn("local clock skew against coordinator exceeds the threshold, please check the ntp service", "skew", skew, "threshold", maxClockSkew)
+		}
+	}
 
 	// create a new TaskMsg
 	taskMsg := message.TaskMsg{
-		UUID: resp.Data.UUID,
-		ID:   resp.Data.TaskID,
-		Type: message.ProofType(resp.Data.TaskType),
+		UUID:      resp.Data.UUID,
+		ID:        resp.Data.TaskID,
+		Type:      message.ProofType(resp.Data.TaskType),
+		Resources: resp.Data.Resources,
 	}
 
 	// depending on the task type, unmarshal the task data into the appropriate field
@@ -226,11 +284,29 @@ func (r *Prover) fetchTaskFromCoordinator() (*store.ProvingTask, error) {
 		return nil, fmt.Errorf("unknown task type: %v", taskMsg.Type)
 	}
 
+	if reason, msg := r.checkTask(&taskMsg); reason != message.TaskDeclineReasonUndefined {
+		declineReq := &client.DeclineTaskRequest{
+			UUID:     taskMsg.UUID,
+			TaskID:   taskMsg.ID,
+			TaskType: int(taskMsg.Type),
+			Reason:   reason,
+			Message:  msg,
+		}
+		if err = r.coordinatorClient.DeclineTask(r.ctx, declineReq); err != nil {
+			return nil, fmt.Errorf("failed to decline task, task id: %v, reason: %v, err: %v", taskMsg.ID, reason, err)
+		}
+		return nil, fmt.Errorf("declined task, task id: %v, reason: %v, message: %v", taskMsg.ID, reason, msg)
+	}
+
 	// convert the response task to a ProvingTask
 	provingTask := &store.ProvingTask{
 		Task:  &taskMsg,
 		Times: 0,
 	}
+	// the deadline is relative to the assignment, so it doesn't depend on the clock of coordinator
+	if resp.Data.DeadlineSec > 0 {
+		provingTask.Deadline = received.Add(time.Duration(resp.Data.DeadlineSec) * time.Second).Unix()
+	}
 
 	// marshal the task to a json string for logging
 	taskJSON, err := json.Marshal(provingTask)
@@ -243,8 +319,27 @@ func (r *Prover) fetchTaskFromCoordinator() (*store.ProvingTask, error) {
 	return provingTask, nil
 }
 
+// checkTask checks whether the prover is able to prove the task, returns the decline reason if not.
+func (r *Prover) checkTask(task *message.TaskMsg) (message.TaskDeclineReason, string) {
+	if task.Type != r.Type() {
+		return message.TaskDeclineReasonUnsupportedCircuit, fmt.Sprintf("prover type is %v", r.Type())
+	}
+	if task.Resources == nil {
+		return message.TaskDeclineReasonUndefined, ""
+	}
+	if r.cfg.Core.MaxMemoryMB != 0 && task.Resources.ExpectedMemoryMB > r.cfg.Core.MaxMemoryMB {
+		return message.TaskDeclineReasonInsufficientMemory, fmt.Sprintf("expected %d MB, available %d MB", task.Resources.ExpectedMemoryMB, r.cfg.Core.MaxMemoryMB)
+	}
+	if artifacts := task.Resources.Artifacts; artifacts != nil && artifacts.URL == "" && r.cfg.Core.AssetsPath != "" {
+		if _, err := os.Stat(filepath.Join(r.cfg.Core.AssetsPath, artifacts.Version)); err != nil {
+			return message.TaskDeclineReasonArtifactMissing, fmt.Sprintf("artifacts %s not found", artifacts.Version)
+		}
+	}
+	return message.TaskDeclineReasonUndefined, ""
+}
+
 // prove function tries to prove a task. It returns an error if the proof fails.
-func (r *Prover) prove(task *store.ProvingTask) (*message.ProofDetail, error) {
+func (r *Prover) prove(task *store.ProvingTask, progress *progressReporter) (*message.ProofDetail, error) {
 	detail := &message.ProofDetail{
 		ID:     task.Task.ID,
 		Type:   task.Task.Type,
@@ -253,7 +348,7 @@ func (r *Prover) prove(task *store.ProvingTask) (*message.ProofDetail, error) {
 
 	switch r.Type() {
 	case message.ProofTypeChunk:
-		proof, err := r.proveChunk(task)
+		proof, err := r.proveChunk(task, progress)
 		if err != nil {
 			detail.Status = message.StatusProofError
 			detail.Error = err.Error()
@@ -264,7 +359,7 @@ func (r *Prover) prove(task *store.ProvingTask) (*message.ProofDetail, error) {
 		return detail, nil
 
 	case message.ProofTypeBatch:
-		proof, err := r.proveBatch(task)
+		proof, err := r.proveBatch(task, progress)
 		if err != nil {
 			detail.Status = message.StatusProofError
 			detail.Error = err.Error()
@@ -280,21 +375,34 @@ func (r *Prover) prove(task *store.ProvingTask) (*message.ProofDetail, error) {
 	}
 }
 
-func (r *Prover) proveChunk(task *store.ProvingTask) (*message.ChunkProof, error) {
+func (r *Prover) proveChunk(task *store.ProvingTask, progress *progressReporter) (*message.ChunkProof, error) {
 	if task.Task.ChunkTaskDetail == nil {
 		return nil, fmt.Errorf("ChunkTaskDetail is empty")
 	}
+	progress.setStage(message.TaskProgressStageFetchingWitness)
 	traces, err := r.getSortedTracesByHashes(task.Task.ChunkTaskDetail.BlockHashes)
 	if err != nil {
 		return nil, fmt.Errorf("get traces from eth node failed, block hashes: %v, err: %v", task.Task.ChunkTaskDetail.BlockHashes, err)
 	}
-	return r.proverCore.ProveChunk(task.Task.ID, traces)
+	source := witness.NewSource(traces, r.cfg.Core.WitnessReduction)
+	traces, err = r.witnessReducer.Reduce(traces)
+	if err != nil {
+		return nil, fmt.Errorf("reduce witness failed, block hashes: %v, err: %v", task.Task.ChunkTaskDetail.BlockHashes, err)
+	}
+	progress.setStage(message.TaskProgressStageProving)
+	proof, err := r.proverCore.ProveChunk(task.Task.ID, traces)
+	if err != nil {
+		return nil, err
+	}
+	proof.WitnessSource = source
+	return proof, nil
 }
 
-func (r *Prover) proveBatch(task *store.ProvingTask) (*message.BatchProof, error) {
+func (r *Prover) proveBatch(task *store.ProvingTask, progress *progressReporter) (*message.BatchProof, error) {
 	if task.Task.BatchTaskDetail == nil {
 		return nil, fmt.Errorf("BatchTaskDetail is empty")
 	}
+	progress.setStage(message.TaskProgressStageProving)
 	return r.proverCore.ProveBatch(task.Task.ID, task.Task.BatchTaskDetail.ChunkInfos, task.Task.BatchTaskDetail.ChunkProofs)
 }
 
@@ -27,6 +27,8 @@ type ProvingTask struct {
 	Task *message.TaskMsg `json:"task"`
 	// Times is how many times prover proved.
 	Times int `json:"times"`
+	// Deadline is the unix time of the task deadline on the local clock, 0 means no deadline.
+	Deadline int64 `json:"deadline,omitempty"`
 }
 
 var bucket = []byte("stack")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/rpc"
@@ -54,3 +55,11 @@ func GetLatestConfirmedBlockNumber(ctx context.Context, client ethClient, confir
 		return 0, fmt.Errorf("unknown confirmation type: %v", confirm)
 	}
 }
+
+// EstimateClockSkew estimates the skew of the local clock against the server clock (unix milliseconds),
+// assuming the server handled the request at the midpoint of the round trip. A positive skew means the
+// local clock is behind the server.
+func EstimateClockSkew(sent, received time.Time, serverTimeMs int64) time.Duration {
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	return time.UnixMilli(serverTimeMs).Sub(midpoint)
+}
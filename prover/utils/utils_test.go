@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateClockSkew(t *testing.T) {
+	sent := time.UnixMilli(1700000000000)
+	received := sent.Add(200 * time.Millisecond)
+
+	assert.Equal(t, time.Duration(0), EstimateClockSkew(sent, received, sent.Add(100*time.Millisecond).UnixMilli()))
+	assert.Equal(t, 3*time.Second, EstimateClockSkew(sent, received, sent.Add(3100*time.Millisecond).UnixMilli()))
+	assert.Equal(t, -2*time.Second, EstimateClockSkew(sent, received, sent.Add(-1900*time.Millisecond).UnixMilli()))
+}
@@ -0,0 +1,174 @@
+package witness
+
+import (
+	"fmt"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+)
+
+const (
+	// ModeFull passes the full block traces to the prover core.
+	ModeFull = "full"
+	// ModeStateDiff reduces the block traces to the touched accounts and storage slots with their proofs.
+	ModeStateDiff = "state_diff"
+)
+
+// Reducer converts the block traces into the witnesses sent to the prover core.
+type Reducer interface {
+	Reduce(traces []*types.BlockTrace) ([]*types.BlockTrace, error)
+}
+
+// NewReducer returns the Reducer of the given mode, an empty mode means ModeFull.
+func NewReducer(mode string) (Reducer, error) {
+	switch mode {
+	case "", ModeFull:
+		return &fullReducer{}, nil
+	case ModeStateDiff:
+		return &stateDiffReducer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown witness reduction mode: %s", mode)
+	}
+}
+
+type fullReducer struct{}
+
+// Reduce returns the traces as they are.
+func (r *fullReducer) Reduce(traces []*types.BlockTrace) ([]*types.BlockTrace, error) {
+	return traces, nil
+}
+
+// stateDiffReducer only keeps the proofs of the accounts and storage slots touched by the block,
+// and drops the per-opcode execution steps, the circuit re-executes the transactions on the partial state.
+type stateDiffReducer struct{}
+
+// Reduce returns the minimized copies of the traces, the input traces are not modified.
+func (r *stateDiffReducer) Reduce(traces []*types.BlockTrace) ([]*types.BlockTrace, error) {
+	reduced := make([]*types.BlockTrace, 0, len(traces))
+	for _, trace := range traces {
+		if trace == nil || trace.Header == nil {
+			return nil, fmt.Errorf("invalid block trace: missing header")
+		}
+		if trace.StorageTrace == nil {
+			return nil, fmt.Errorf("invalid block trace: missing storage trace, block number: %v", trace.Header.Number)
+		}
+		reduced = append(reduced, reduceTrace(trace))
+	}
+	return reduced, nil
+}
+
+type touchedSet struct {
+	accounts map[common.Address]struct{}
+	storage  map[common.Address]map[common.Hash]struct{}
+}
+
+func (s *touchedSet) addAccount(account *types.AccountWrapper) {
+	if account == nil {
+		return
+	}
+	s.accounts[account.Address] = struct{}{}
+	if account.Storage == nil || account.Storage.Key == "" {
+		return
+	}
+	if s.storage[account.Address] == nil {
+		s.storage[account.Address] = make(map[common.Hash]struct{})
+	}
+	s.storage[account.Address][common.HexToHash(account.Storage.Key)] = struct{}{}
+}
+
+func collectTouched(trace *types.BlockTrace) *touchedSet {
+	touched := &touchedSet{
+		accounts: make(map[common.Address]struct{}),
+		storage:  make(map[common.Address]map[common.Hash]struct{}),
+	}
+
+	touched.addAccount(trace.Coinbase)
+	for _, tx := range trace.Transactions {
+		touched.accounts[tx.From] = struct{}{}
+		if tx.To != nil {
+			touched.accounts[*tx.To] = struct{}{}
+		}
+	}
+	for _, result := range trace.ExecutionResults {
+		touched.addAccount(result.From)
+		touched.addAccount(result.To)
+		touched.addAccount(result.AccountCreated)
+		for _, account := range result.AccountsAfter {
+			touched.addAccount(account)
+		}
+		for _, structLog := range result.StructLogs {
+			if structLog.ExtraData == nil {
+				continue
+			}
+			for _, account := range structLog.ExtraData.StateList {
+				touched.addAccount(account)
+			}
+			for _, account := range structLog.ExtraData.Caller {
+				touched.addAccount(account)
+			}
+		}
+	}
+	return touched
+}
+
+func reduceTrace(trace *types.BlockTrace) *types.BlockTrace {
+	touched := collectTouched(trace)
+
+	storageTrace := &types.StorageTrace{
+		RootBefore:     trace.StorageTrace.RootBefore,
+		RootAfter:      trace.StorageTrace.RootAfter,
+		Proofs:         make(map[string][]hexutil.Bytes),
+		StorageProofs:  make(map[string]map[string][]hexutil.Bytes),
+		DeletionProofs: trace.StorageTrace.DeletionProofs,
+	}
+	for addr, proof := range trace.StorageTrace.Proofs {
+		if _, ok := touched.accounts[common.HexToAddress(addr)]; ok {
+			storageTrace.Proofs[addr] = proof
+		}
+	}
+	for addr, proofs := range trace.StorageTrace.StorageProofs {
+		slots, ok := touched.storage[common.HexToAddress(addr)]
+		if !ok {
+			continue
+		}
+		for key, proof := range proofs {
+			if _, ok := slots[common.HexToHash(key)]; !ok {
+				continue
+			}
+			if storageTrace.StorageProofs[addr] == nil {
+				storageTrace.StorageProofs[addr] = make(map[string][]hexutil.Bytes)
+			}
+			storageTrace.StorageProofs[addr][key] = proof
+		}
+	}
+
+	executionResults := make([]*types.ExecutionResult, 0, len(trace.ExecutionResults))
+	for _, result := range trace.ExecutionResults {
+		executionResults = append(executionResults, &types.ExecutionResult{
+			L1DataFee:        result.L1DataFee,
+			Gas:              result.Gas,
+			Failed:           result.Failed,
+			ReturnValue:      result.ReturnValue,
+			From:             result.From,
+			To:               result.To,
+			AccountCreated:   result.AccountCreated,
+			AccountsAfter:    result.AccountsAfter,
+			PoseidonCodeHash: result.PoseidonCodeHash,
+			ByteCode:         result.ByteCode,
+			StructLogs:       []*types.StructLogRes{},
+		})
+	}
+
+	return &types.BlockTrace{
+		ChainID:           trace.ChainID,
+		Version:           trace.Version,
+		Coinbase:          trace.Coinbase,
+		Header:            trace.Header,
+		Transactions:      trace.Transactions,
+		StorageTrace:      storageTrace,
+		ExecutionResults:  executionResults,
+		WithdrawTrieRoot:  trace.WithdrawTrieRoot,
+		StartL1QueueIndex: trace.StartL1QueueIndex,
+	}
+}
@@ -0,0 +1,78 @@
+package witness
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReducer(t *testing.T) {
+	for _, mode := range []string{"", ModeFull, ModeStateDiff} {
+		r, err := NewReducer(mode)
+		assert.NoError(t, err)
+		assert.NotNil(t, r)
+	}
+	_, err := NewReducer("unknown")
+	assert.Error(t, err)
+}
+
+func TestStateDiffReducer(t *testing.T) {
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	untouched := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	slot := common.HexToHash("0x01")
+	otherSlot := common.HexToHash("0x02")
+
+	trace := &types.BlockTrace{
+		Header:       &types.Header{Number: big.NewInt(1)},
+		Transactions: []*types.TransactionData{{From: from, To: &to}},
+		StorageTrace: &types.StorageTrace{
+			Proofs: map[string][]hexutil.Bytes{
+				from.Hex():      {{0x1}},
+				to.Hex():        {{0x2}},
+				untouched.Hex(): {{0x3}},
+			},
+			StorageProofs: map[string]map[string][]hexutil.Bytes{
+				to.Hex(): {
+					slot.Hex():      {{0x4}},
+					otherSlot.Hex(): {{0x5}},
+				},
+			},
+		},
+		TxStorageTraces: []*types.StorageTrace{{}},
+		ExecutionResults: []*types.ExecutionResult{{
+			Gas: 21000,
+			AccountsAfter: []*types.AccountWrapper{
+				{Address: to, Storage: &types.StorageWrapper{Key: slot.Hex()}},
+			},
+			StructLogs: []*types.StructLogRes{{Op: "SSTORE"}},
+			CallTrace:  json.RawMessage(`{}`),
+		}},
+	}
+
+	r, err := NewReducer(ModeStateDiff)
+	assert.NoError(t, err)
+	reduced, err := r.Reduce([]*types.BlockTrace{trace})
+	assert.NoError(t, err)
+	assert.Len(t, reduced, 1)
+
+	assert.Len(t, reduced[0].StorageTrace.Proofs, 2)
+	assert.NotContains(t, reduced[0].StorageTrace.Proofs, untouched.Hex())
+	assert.Len(t, reduced[0].StorageTrace.StorageProofs[to.Hex()], 1)
+	assert.Contains(t, reduced[0].StorageTrace.StorageProofs[to.Hex()], slot.Hex())
+	assert.Nil(t, reduced[0].TxStorageTraces)
+	assert.Empty(t, reduced[0].ExecutionResults[0].StructLogs)
+	assert.Equal(t, uint64(21000), reduced[0].ExecutionResults[0].Gas)
+
+	// the input trace is not modified
+	assert.Len(t, trace.StorageTrace.Proofs, 3)
+	assert.Len(t, trace.ExecutionResults[0].StructLogs, 1)
+
+	_, err = r.Reduce([]*types.BlockTrace{{Header: &types.Header{Number: big.NewInt(1)}}})
+	assert.Error(t, err)
+}
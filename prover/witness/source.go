@@ -0,0 +1,38 @@
+package witness
+
+import (
+	"github.com/scroll-tech/go-ethereum/core/types"
+
+	"scroll-tech/common/types/message"
+)
+
+// NewSource returns the identifiers of the L2 node state the traces were fetched from, the traces
+// must be sorted by block number and mode is the reduction mode applied to them.
+func NewSource(traces []*types.BlockTrace, mode string) *message.WitnessSource {
+	if mode == "" {
+		mode = ModeFull
+	}
+	source := &message.WitnessSource{WitnessReduction: mode}
+	if len(traces) == 0 {
+		return source
+	}
+
+	seen := make(map[string]struct{})
+	for _, trace := range traces {
+		if _, ok := seen[trace.Version]; ok {
+			continue
+		}
+		seen[trace.Version] = struct{}{}
+		source.NodeVersions = append(source.NodeVersions, trace.Version)
+	}
+
+	first, last := traces[0], traces[len(traces)-1]
+	source.ChainID = first.ChainID
+	if first.StorageTrace != nil {
+		source.PrevStateRoot = first.StorageTrace.RootBefore
+	}
+	if last.Header != nil {
+		source.PostStateRoot = last.Header.Root
+	}
+	return source
+}
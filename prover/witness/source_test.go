@@ -0,0 +1,28 @@
+package witness
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSource(t *testing.T) {
+	traces := []*types.BlockTrace{
+		{ChainID: 534352, Version: "l2geth/v5.1.0", Header: &types.Header{Root: common.HexToHash("0x02")}, StorageTrace: &types.StorageTrace{RootBefore: common.HexToHash("0x01")}},
+		{ChainID: 534352, Version: "l2geth/v5.1.0", Header: &types.Header{Root: common.HexToHash("0x03")}},
+		{ChainID: 534352, Version: "l2geth/v5.1.1", Header: &types.Header{Root: common.HexToHash("0x04")}},
+	}
+
+	source := NewSource(traces, "")
+	assert.Equal(t, []string{"l2geth/v5.1.0", "l2geth/v5.1.1"}, source.NodeVersions)
+	assert.Equal(t, uint64(534352), source.ChainID)
+	assert.Equal(t, common.HexToHash("0x01"), source.PrevStateRoot)
+	assert.Equal(t, common.HexToHash("0x04"), source.PostStateRoot)
+	assert.Equal(t, ModeFull, source.WitnessReduction)
+
+	source = NewSource(nil, ModeStateDiff)
+	assert.Empty(t, source.NodeVersions)
+	assert.Equal(t, ModeStateDiff, source.WitnessReduction)
+}
@@ -99,6 +99,18 @@ func TestPackSetL1BaseFee(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestPackSetL1BaseFeeAndBlobBaseFee(t *testing.T) {
+	assert := assert.New(t)
+
+	l1GasOracleABI, err := L1GasPriceOracleMetaData.GetAbi()
+	assert.NoError(err)
+
+	baseFee := big.NewInt(2333)
+	blobBaseFee := big.NewInt(1)
+	_, err = l1GasOracleABI.Pack("setL1BaseFeeAndBlobBaseFee", baseFee, blobBaseFee)
+	assert.NoError(err)
+}
+
 func TestPackSetL2BaseFee(t *testing.T) {
 	assert := assert.New(t)
 
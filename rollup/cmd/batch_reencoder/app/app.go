@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/utils"
+	"scroll-tech/common/version"
+
+	"scroll-tech/database/migrate"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+var app *cli.App
+
+var (
+	startBatchFlag = cli.Uint64Flag{
+		Name:     "start-batch",
+		Usage:    "Index of the first batch to re-encode",
+		Required: true,
+	}
+	endBatchFlag = cli.Uint64Flag{
+		Name:     "end-batch",
+		Usage:    "Index of the last batch to re-encode",
+		Required: true,
+	}
+	codecVersionFlag = cli.UintFlag{
+		Name:     "codec-version",
+		Usage:    "Codec version the batches are re-encoded under",
+		Required: true,
+	}
+	outputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File the json lines report is written to, stdout if empty",
+	}
+)
+
+func init() {
+	// Set up batch-reencoder app info.
+	app = cli.NewApp()
+	app.Action = action
+	app.Name = "batch-reencoder"
+	app.Usage = "Re-encodes stored batches under a new codec version in shadow mode and reports the differences"
+	app.Version = version.Version
+	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &startBatchFlag, &endBatchFlag, &codecVersionFlag, &outputFlag)
+	app.Before = func(ctx *cli.Context) error {
+		return utils.LogSetup(ctx)
+	}
+}
+
+func action(ctx *cli.Context) error {
+	// Load config file.
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
+	}
+	types.SetTxEstimationWorkers(cfg.L2Config.ChunkProposerConfig.TxEstimationWorkers)
+
+	startIndex, endIndex := ctx.Uint64(startBatchFlag.Name), ctx.Uint64(endBatchFlag.Name)
+	if endIndex < startIndex {
+		return fmt.Errorf("end batch %v is less than start batch %v", endIndex, startIndex)
+	}
+	codecVersion := ctx.Uint(codecVersionFlag.Name)
+	if codecVersion > 255 {
+		return fmt.Errorf("invalid codec version %v", codecVersion)
+	}
+
+	// Init db connection
+	db, err := database.InitDB(cfg.DBConfig)
+	if err != nil {
+		log.Crit("failed to init db connection", "err", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
+	defer func() {
+		if err = database.CloseDB(db); err != nil {
+			log.Crit("failed to close db connection", "error", err)
+		}
+	}()
+
+	var out io.Writer = os.Stdout
+	if output := ctx.String(outputFlag.Name); output != "" {
+		f, createErr := os.Create(output)
+		if createErr != nil {
+			return fmt.Errorf("failed to create output file %v: %w", output, createErr)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	reencoder := watcher.NewBatchReencoder(context.Background(), cfg.L2Config.ChunkProposerConfig, cfg.L2Config.BatchProposerConfig, cfg.L2Config.BaseFeeForkBlock, db)
+	results, err := reencoder.Reencode(startIndex, endIndex, uint8(codecVersion))
+	if err != nil {
+		return err
+	}
+
+	var mismatches int
+	encoder := json.NewEncoder(out)
+	for _, result := range results {
+		if len(result.Differences) > 0 || len(result.LimitViolations) > 0 {
+			mismatches++
+		}
+		if err = encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+
+	log.Info("re-encoded batches", "start batch", startIndex, "end batch", endIndex, "codec version", codecVersion,
+		"batches", len(results), "mismatches", mismatches)
+	return nil
+}
+
+// Run batch reencoder cmd instance.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,7 @@
+package main
+
+import "scroll-tech/rollup/cmd/batch_reencoder/app"
+
+func main() {
+	app.Run()
+}
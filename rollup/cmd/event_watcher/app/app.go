@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/urfave/cli/v2"
 
@@ -17,8 +16,11 @@ import (
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
 
+	"scroll-tech/database/migrate"
+
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/watcher"
+	butils "scroll-tech/rollup/internal/utils"
 )
 
 var app *cli.App
@@ -53,6 +55,13 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
 	defer func() {
 		cancel()
 		if err = database.CloseDB(db); err != nil {
@@ -62,7 +71,11 @@ func action(ctx *cli.Context) error {
 
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
-	l1client, err := ethclient.Dial(cfg.L1Config.Endpoint)
+	if err = database.CountCallsWithoutDeadline(db, registry); err != nil {
+		log.Crit("failed to register db deadline check", "err", err)
+	}
+	rpcGuard := butils.NewRPCGuard(cfg.RPCBreakerConfig, registry)
+	l1client, err := rpcGuard.Dial("l1", cfg.L1Config.Endpoint)
 	if err != nil {
 		log.Crit("failed to connect l1 geth", "config file", cfgFile, "error", err)
 	}
@@ -70,8 +83,35 @@ func action(ctx *cli.Context) error {
 	l1watcher := watcher.NewL1WatcherClient(ctx.Context, l1client, cfg.L1Config.StartHeight, cfg.L1Config.Confirmations,
 		cfg.L1Config.L1MessageQueueAddress, cfg.L1Config.ScrollChainContractAddress, db, registry)
 
-	go utils.Loop(subCtx, 10*time.Second, func() {
-		if loopErr := l1watcher.FetchContractEvent(); loopErr != nil {
+	if cfg.L1Config.QuorumConfig != nil {
+		callers := []butils.ContractCaller{l1client}
+		for i, endpoint := range cfg.L1Config.QuorumConfig.Endpoints {
+			client, dialErr := rpcGuard.Dial(fmt.Sprintf("l1_quorum_%d", i), endpoint)
+			if dialErr != nil {
+				log.Crit("failed to connect l1 quorum endpoint", "endpoint", endpoint, "error", dialErr)
+			}
+			callers = append(callers, client)
+		}
+		quorumCaller, quorumErr := butils.NewQuorumCaller(callers, cfg.L1Config.QuorumConfig.Threshold)
+		if quorumErr != nil {
+			log.Crit("failed to create l1 quorum caller", "error", quorumErr)
+		}
+		l1watcher.SetQuorumCaller(quorumCaller)
+	}
+
+	if cfg.L1Config.ArchiveEndpoint != "" {
+		archiveClient, archiveErr := rpcGuard.Dial("l1_archive", cfg.L1Config.ArchiveEndpoint)
+		if archiveErr != nil {
+			log.Crit("failed to connect l1 archive endpoint", "endpoint", cfg.L1Config.ArchiveEndpoint, "error", archiveErr)
+		}
+		l1watcher.SetArchiveClient(archiveClient)
+	}
+
+	observability.RegisterStateDumper("l1_watcher", l1watcher.DebugState)
+
+	watchdog := butils.NewWatchdog(subCtx, cfg.WatchdogConfig, registry)
+	go watchdog.LoopWithContext(subCtx, "fetch_l1_events", 10*time.Second, func(ctx context.Context) {
+		if loopErr := l1watcher.FetchContractEvent(ctx); loopErr != nil {
 			log.Error("Failed to fetch bridge contract", "err", loopErr)
 		}
 	})
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/utils"
+	"scroll-tech/common/version"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+var app *cli.App
+
+var (
+	startBlockFlag = cli.Uint64Flag{
+		Name:     "start-block",
+		Usage:    "Number of the first block to fetch",
+		Required: true,
+	}
+	endBlockFlag = cli.Uint64Flag{
+		Name:     "end-block",
+		Usage:    "Number of the last block to fetch",
+		Required: true,
+	}
+	blocksPerChunkFlag = cli.Uint64Flag{
+		Name:  "blocks-per-chunk",
+		Usage: "Number of blocks of every chunk fixture",
+		Value: 10,
+	}
+	totalL1MessagePoppedBeforeFlag = cli.Uint64Flag{
+		Name:  "total-l1-message-popped-before",
+		Usage: "Total l1 messages popped before the first block, the queue index of the first l1 message in the range if unset",
+	}
+	outputDirFlag = cli.StringFlag{
+		Name:  "output-dir",
+		Usage: "Directory the fixtures are written to",
+		Value: ".",
+	}
+)
+
+func init() {
+	// Set up fixtures app info.
+	app = cli.NewApp()
+	app.Name = "fixtures"
+	app.Usage = "Builds the chunk encoding regression fixtures"
+	app.Version = version.Version
+	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Commands = []*cli.Command{
+		{
+			Name:   "fetch",
+			Usage:  "Fetches a block range from the layer 2 node and writes the chunk fixtures of it",
+			Flags:  []cli.Flag{&startBlockFlag, &endBlockFlag, &blocksPerChunkFlag, &totalL1MessagePoppedBeforeFlag, &outputDirFlag},
+			Action: fetch,
+		},
+	}
+	app.Before = func(ctx *cli.Context) error {
+		return utils.LogSetup(ctx)
+	}
+}
+
+func fetch(ctx *cli.Context) error {
+	// Load config file.
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
+	}
+	types.SetTxEstimationWorkers(cfg.L2Config.ChunkProposerConfig.TxEstimationWorkers)
+
+	startBlock, endBlock := ctx.Uint64(startBlockFlag.Name), ctx.Uint64(endBlockFlag.Name)
+	if endBlock < startBlock {
+		return fmt.Errorf("end block %v is less than start block %v", endBlock, startBlock)
+	}
+	blocksPerChunk := ctx.Uint64(blocksPerChunkFlag.Name)
+	if blocksPerChunk == 0 {
+		return fmt.Errorf("invalid blocks per chunk %v", blocksPerChunk)
+	}
+	outputDir := ctx.String(outputDirFlag.Name)
+	if err = os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output dir %v: %w", outputDir, err)
+	}
+
+	l2client, err := ethclient.Dial(cfg.L2Config.Endpoint)
+	if err != nil {
+		log.Crit("failed to connect l2 geth", "config file", cfgFile, "error", err)
+	}
+
+	// The canonical json of the wrapped blocks keeps the fields the encodings depend on only,
+	// so the fixtures don't carry the node specific fields of the live blocks.
+	var blocks []*types.WrappedBlock
+	for number := startBlock; number <= endBlock; number++ {
+		block, fetchErr := watcher.FetchWrappedBlock(context.Background(), l2client, number,
+			cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot)
+		if fetchErr != nil {
+			return fetchErr
+		}
+		blocks = append(blocks, block)
+	}
+
+	totalL1MessagePoppedBefore := ctx.Uint64(totalL1MessagePoppedBeforeFlag.Name)
+	if !ctx.IsSet(totalL1MessagePoppedBeforeFlag.Name) {
+		totalL1MessagePoppedBefore = firstQueueIndex(blocks)
+	}
+
+	for begin := uint64(0); begin < uint64(len(blocks)); begin += blocksPerChunk {
+		end := begin + blocksPerChunk
+		if end > uint64(len(blocks)) {
+			end = uint64(len(blocks))
+		}
+		chunkBlocks := blocks[begin:end]
+
+		fixture, fixtureErr := types.NewChunkFixture(chunkBlocks, totalL1MessagePoppedBefore, cfg.L2Config.BaseFeeForkBlock)
+		if fixtureErr != nil {
+			return fixtureErr
+		}
+		data, marshalErr := json.MarshalIndent(fixture, "", "  ")
+		if marshalErr != nil {
+			return marshalErr
+		}
+		first, last := chunkBlocks[0].Header.Number.Uint64(), chunkBlocks[len(chunkBlocks)-1].Header.Number.Uint64()
+		path := filepath.Join(outputDir, fmt.Sprintf("chunk_%v_%v.json", first, last))
+		if err = os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write fixture %v: %w", path, err)
+		}
+		log.Info("wrote chunk fixture", "path", path, "start block", first, "end block", last, "hash", fixture.Hash.Hex())
+
+		totalL1MessagePoppedBefore += (&types.Chunk{Blocks: chunkBlocks}).NumL1Messages(totalL1MessagePoppedBefore)
+	}
+	return nil
+}
+
+// firstQueueIndex returns the queue index of the first l1 message in the blocks, 0 if none.
+func firstQueueIndex(blocks []*types.WrappedBlock) uint64 {
+	for _, block := range blocks {
+		for _, txData := range block.Transactions {
+			if txData.Type == gethTypes.L1MessageTxType {
+				return txData.Nonce
+			}
+		}
+	}
+	return 0
+}
+
+// Run fixtures cmd instance.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
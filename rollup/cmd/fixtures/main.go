@@ -0,0 +1,7 @@
+package main
+
+import "scroll-tech/rollup/cmd/fixtures/app"
+
+func main() {
+	app.Run()
+}
@@ -2,13 +2,13 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/urfave/cli/v2"
@@ -18,14 +18,34 @@ import (
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
 
+	"scroll-tech/database/migrate"
+
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/relayer"
 	"scroll-tech/rollup/internal/controller/watcher"
+	"scroll-tech/rollup/internal/orm"
 	butils "scroll-tech/rollup/internal/utils"
 )
 
 var app *cli.App
 
+var (
+	fromBlockFlag = cli.Uint64Flag{
+		Name:     "from-block",
+		Usage:    "Number of the first l1 block to replay",
+		Required: true,
+	}
+	toBlockFlag = cli.Uint64Flag{
+		Name:     "to-block",
+		Usage:    "Number of the last l1 block to replay",
+		Required: true,
+	}
+	outputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File the json report is written to, printed if unset",
+	}
+)
+
 func init() {
 	// Set up gas-oracle app info.
 	app = cli.NewApp()
@@ -35,7 +55,14 @@ func init() {
 	app.Description = "Scroll Gas Oracle."
 	app.Version = version.Version
 	app.Flags = append(app.Flags, utils.CommonFlags...)
-	app.Commands = []*cli.Command{}
+	app.Commands = []*cli.Command{
+		{
+			Name:   "simulate",
+			Usage:  "Replays the l1 blocks stored in the db through the l1 gas oracle config and reports the updates and the fee error",
+			Flags:  []cli.Flag{&fromBlockFlag, &toBlockFlag, &outputFlag},
+			Action: simulate,
+		},
+	}
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
 	}
@@ -56,6 +83,13 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
 	defer func() {
 		cancel()
 		if err = database.CloseDB(db); err != nil {
@@ -65,14 +99,18 @@ func action(ctx *cli.Context) error {
 
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
+	if err = database.CountCallsWithoutDeadline(db, registry); err != nil {
+		log.Crit("failed to register db deadline check", "err", err)
+	}
 
-	l1client, err := ethclient.Dial(cfg.L1Config.Endpoint)
+	rpcGuard := butils.NewRPCGuard(cfg.RPCBreakerConfig, registry)
+	l1client, err := rpcGuard.Dial("l1", cfg.L1Config.Endpoint)
 	if err != nil {
 		log.Crit("failed to connect l1 geth", "config file", cfgFile, "error", err)
 	}
 
 	// Init l2geth connection
-	l2client, err := ethclient.Dial(cfg.L2Config.Endpoint)
+	l2client, err := rpcGuard.Dial("l2", cfg.L2Config.Endpoint)
 	if err != nil {
 		log.Crit("failed to connect l2 geth", "config file", cfgFile, "error", err)
 	}
@@ -83,12 +121,17 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to create new l1 relayer", "config file", cfgFile, "error", err)
 	}
-	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, false /* initGenesis */, relayer.ServiceTypeL2GasOracle, registry)
+	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, cfg.L2Config.BaseFeeForkBlock, false /* initGenesis */, relayer.ServiceTypeL2GasOracle, registry)
 	if err != nil {
 		log.Crit("failed to create new l2 relayer", "config file", cfgFile, "error", err)
 	}
+	observability.RegisterStateDumper("l1_watcher", l1watcher.DebugState)
+	observability.RegisterStateDumper("l1_relayer", l1relayer.DebugState)
+
+	watchdog := butils.NewWatchdog(subCtx, cfg.WatchdogConfig, registry)
+
 	// Start l1 watcher process
-	go utils.LoopWithContext(subCtx, 10*time.Second, func(ctx context.Context) {
+	go watchdog.LoopWithContext(subCtx, "fetch_l1_block_headers", 10*time.Second, func(ctx context.Context) {
 		// Fetch the latest block number to decrease the delay when fetching gas prices
 		// Use latest block number - 1 to prevent frequent reorg
 		number, loopErr := butils.GetLatestConfirmedBlockNumber(ctx, l1client, rpc.LatestBlockNumber)
@@ -97,15 +140,16 @@ func action(ctx *cli.Context) error {
 			return
 		}
 
-		if loopErr = l1watcher.FetchBlockHeader(number - 1); loopErr != nil {
+		if loopErr = l1watcher.FetchBlockHeader(ctx, number-1); loopErr != nil {
 			log.Error("Failed to fetch L1 block header", "lastest", number-1, "err", loopErr)
 			return
 		}
 	})
 
 	// Start l1relayer process
-	go utils.Loop(subCtx, 10*time.Second, l1relayer.ProcessGasPriceOracle)
-	go utils.Loop(subCtx, 2*time.Second, l2relayer.ProcessGasPriceOracle)
+	pauser := butils.NewPauser(db, registry)
+	go watchdog.LoopWithContext(subCtx, "l1_gas_oracle", 10*time.Second, pauser.Guard(orm.OperationGasOracle, l1relayer.ProcessGasPriceOracle))
+	go watchdog.LoopWithContext(subCtx, "l2_gas_oracle", 2*time.Second, pauser.Guard(orm.OperationGasOracle, l2relayer.ProcessGasPriceOracle))
 
 	// Finish start all message relayer functions
 	log.Info("Start gas-oracle successfully")
@@ -120,6 +164,55 @@ func action(ctx *cli.Context) error {
 	return nil
 }
 
+func simulate(ctx *cli.Context) error {
+	// Load config file.
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
+	}
+
+	fromBlock, toBlock := ctx.Uint64(fromBlockFlag.Name), ctx.Uint64(toBlockFlag.Name)
+	if toBlock < fromBlock {
+		return fmt.Errorf("to block %v is less than from block %v", toBlock, fromBlock)
+	}
+
+	db, err := database.InitDB(cfg.DBConfig)
+	if err != nil {
+		log.Crit("failed to init db connection", "err", err)
+	}
+	defer func() {
+		if err = database.CloseDB(db); err != nil {
+			log.Error("failed to close db connection", "error", err)
+		}
+	}()
+
+	blocks, err := orm.NewL1Block(db).GetL1Blocks(ctx.Context, map[string]interface{}{"number >= ?": fromBlock, "number <= ?": toBlock})
+	if err != nil {
+		return fmt.Errorf("failed to get l1 blocks: %w", err)
+	}
+	report, err := relayer.SimulateGasOracle(cfg.L1Config.RelayerConfig.GasOracleConfig, blocks)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output := ctx.String(outputFlag.Name); output != "" {
+		if err = os.WriteFile(output, data, 0644); err != nil {
+			return fmt.Errorf("failed to write report %v: %w", output, err)
+		}
+	} else {
+		fmt.Println(string(data))
+	}
+	log.Info("simulated l1 gas oracle", "from block", report.FromBlock, "to block", report.ToBlock, "blocks", report.Blocks,
+		"updates", len(report.Updates), "l1 base fee mean absolute error", report.L1BaseFeeError.MeanAbsoluteError,
+		"l1 base fee max undercharge", report.L1BaseFeeError.MaxUndercharge)
+	return nil
+}
+
 // Run message_relayer cmd instance.
 func Run() {
 	if err := app.Run(os.Args); err != nil {
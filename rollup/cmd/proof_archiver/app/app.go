@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/utils"
+	"scroll-tech/common/version"
+
+	"scroll-tech/database/migrate"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+var app *cli.App
+
+var (
+	startBatchFlag = cli.Uint64Flag{
+		Name:     "start-batch",
+		Usage:    "Index of the first batch to archive",
+		Required: true,
+	}
+	endBatchFlag = cli.Uint64Flag{
+		Name:     "end-batch",
+		Usage:    "Index of the last batch to archive",
+		Required: true,
+	}
+	outputFlag = cli.StringFlag{
+		Name:     "output",
+		Usage:    "File the tar.gz archive is written to",
+		Required: true,
+	}
+)
+
+func init() {
+	// Set up proof-archiver app info.
+	app = cli.NewApp()
+	app.Action = action
+	app.Name = "proof-archiver"
+	app.Usage = "Exports the proofs and public inputs of a batch range as an archive with a manifest and checksums"
+	app.Version = version.Version
+	app.Flags = append(app.Flags, utils.CommonFlags...)
+	app.Flags = append(app.Flags, &startBatchFlag, &endBatchFlag, &outputFlag)
+	app.Before = func(ctx *cli.Context) error {
+		return utils.LogSetup(ctx)
+	}
+}
+
+func action(ctx *cli.Context) error {
+	// Load config file.
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	cfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
+	}
+
+	// Init db connection
+	db, err := database.InitDB(cfg.DBConfig)
+	if err != nil {
+		log.Crit("failed to init db connection", "err", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
+	defer func() {
+		if err = database.CloseDB(db); err != nil {
+			log.Crit("failed to close db connection", "error", err)
+		}
+	}()
+
+	// the archive is written to a temporary file first, so a failed export leaves no partial archive
+	output := filepath.Clean(ctx.String(outputFlag.Name))
+	f, err := os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create output file %v: %w", output, err)
+	}
+	defer os.Remove(f.Name())
+
+	startIndex, endIndex := ctx.Uint64(startBatchFlag.Name), ctx.Uint64(endBatchFlag.Name)
+	manifest, err := watcher.NewProofArchiver(context.Background(), db).Archive(f, startIndex, endIndex)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), output); err != nil {
+		return err
+	}
+
+	log.Info("archived proofs", "start batch", startIndex, "end batch", endIndex, "batches", len(manifest.Batches),
+		"files", len(manifest.Files), "output", output)
+	return nil
+}
+
+// Run proof archiver cmd instance.
+func Run() {
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,7 @@
+package main
+
+import "scroll-tech/rollup/cmd/proof_archiver/app"
+
+func main() {
+	app.Run()
+}
@@ -2,24 +2,35 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"scroll-tech/common/database"
 	"scroll-tech/common/observability"
+	"scroll-tech/common/types"
 	"scroll-tech/common/utils"
 	"scroll-tech/common/version"
 
+	"scroll-tech/database/migrate"
+
 	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/api"
 	"scroll-tech/rollup/internal/controller/relayer"
 	"scroll-tech/rollup/internal/controller/watcher"
+	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/route"
 	butils "scroll-tech/rollup/internal/utils"
 )
 
@@ -49,6 +60,7 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
+	types.SetTxEstimationWorkers(cfg.L2Config.ChunkProposerConfig.TxEstimationWorkers)
 
 	subCtx, cancel := context.WithCancel(ctx.Context)
 	// Init db connection
@@ -56,6 +68,13 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to init db connection", "err", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Crit("failed to get db connection", "err", err)
+	}
+	if err = migrate.CheckVersion(sqlDB); err != nil {
+		log.Crit("refuse to run against the db", "err", err)
+	}
 	defer func() {
 		cancel()
 		if err = database.CloseDB(db); err != nil {
@@ -65,48 +84,135 @@ func action(ctx *cli.Context) error {
 
 	registry := prometheus.DefaultRegisterer
 	observability.Server(ctx, db)
+	if err = database.CountCallsWithoutDeadline(db, registry); err != nil {
+		log.Crit("failed to register db deadline check", "err", err)
+	}
 
 	// Init l2geth connection
-	l2client, err := ethclient.Dial(cfg.L2Config.Endpoint)
+	rpcGuard := butils.NewRPCGuard(cfg.RPCBreakerConfig, registry)
+	l2client, err := rpcGuard.Dial("l2", cfg.L2Config.Endpoint)
 	if err != nil {
 		log.Crit("failed to connect l2 geth", "config file", cfgFile, "error", err)
 	}
 
 	initGenesis := ctx.Bool(utils.ImportGenesisFlag.Name)
-	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, initGenesis, relayer.ServiceTypeL2RollupRelayer, registry)
+	l2relayer, err := relayer.NewLayer2Relayer(ctx.Context, l2client, db, cfg.L2Config.RelayerConfig, cfg.L2Config.BaseFeeForkBlock, initGenesis, relayer.ServiceTypeL2RollupRelayer, registry)
 	if err != nil {
 		log.Crit("failed to create l2 relayer", "config file", cfgFile, "error", err)
 	}
 
-	chunkProposer := watcher.NewChunkProposer(subCtx, cfg.L2Config.ChunkProposerConfig, db, registry)
+	chunkProposer := watcher.NewChunkProposer(subCtx, cfg.L2Config.ChunkProposerConfig, cfg.L2Config.BaseFeeForkBlock, db, registry)
 	if err != nil {
 		log.Crit("failed to create chunkProposer", "config file", cfgFile, "error", err)
 	}
+	if cfg.L2Config.ChunkProposerConfig.ReproposeChunksOnStartup {
+		if err = chunkProposer.ReproposeChunksExceedingRowConsumption(); err != nil {
+			log.Crit("failed to repropose chunks exceeding the row consumption limit", "error", err)
+		}
+	}
 
-	batchProposer := watcher.NewBatchProposer(subCtx, cfg.L2Config.BatchProposerConfig, db, registry)
+	batchProposer := watcher.NewBatchProposer(cfg.L2Config.BatchProposerConfig, cfg.L2Config.BaseFeeForkBlock, db, registry)
 	if err != nil {
 		log.Crit("failed to create batchProposer", "config file", cfgFile, "error", err)
 	}
 
-	l2watcher := watcher.NewL2WatcherClient(subCtx, l2client, cfg.L2Config.Confirmations, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, db, registry)
+	// Init l1geth connection, only used by the optional components
+	var l1client *ethclient.Client
+	if cfg.L2Config.BlockTimestampConfig != nil || cfg.L2Config.BatchReportConfig != nil || cfg.L2Config.RelayerConfig.DiagnoseCommitFailures ||
+		cfg.L2Config.CommitmentCheckConfig != nil || cfg.L2Config.RelayerConfig.StartupCheckConfig != nil {
+		l1client, err = rpcGuard.Dial("l1", cfg.L2Config.RelayerConfig.SenderConfig.Endpoint)
+		if err != nil {
+			log.Crit("failed to connect l1 geth", "config file", cfgFile, "error", err)
+		}
+	}
+
+	if cfg.L2Config.RelayerConfig.DiagnoseCommitFailures {
+		l2relayer.SetCommitDiagnosis(l1client)
+	}
+
+	if checkCfg := cfg.L2Config.RelayerConfig.StartupCheckConfig; checkCfg != nil {
+		if checkErr := l2relayer.CheckBatchIntegrity(l1client); checkErr != nil {
+			if !errors.Is(checkErr, relayer.ErrBatchIntegrity) || checkCfg.Action == config.StartupCheckActionExit {
+				log.Crit("startup check of the local batches failed", "error", checkErr)
+			}
+			if repairErr := l2relayer.EnterRepairMode(checkErr.Error()); repairErr != nil {
+				log.Crit("failed to enter repair mode", "error", repairErr)
+			}
+		}
+	}
+
+	l2watcher := watcher.NewL2WatcherClient(l2client, cfg.L2Config.Confirmations, cfg.L2Config.L2MessageQueueAddress, cfg.L2Config.WithdrawTrieRootSlot, db, registry)
+	if timestampCfg := cfg.L2Config.BlockTimestampConfig; timestampCfg != nil {
+		l2watcher.SetBlockTimestampCheck(timestampCfg, l1client)
+	}
+
+	observability.RegisterStateDumper("chunk_proposer", chunkProposer.DebugState)
+	observability.RegisterStateDumper("batch_proposer", batchProposer.DebugState)
+	observability.RegisterStateDumper("l2_relayer", l2relayer.DebugState)
+	observability.RegisterStateDumper("l2_watcher", l2watcher.DebugState)
+
+	watchdog := butils.NewWatchdog(subCtx, cfg.WatchdogConfig, registry)
 
 	// Watcher loop to fetch missing blocks
-	go utils.LoopWithContext(subCtx, 2*time.Second, func(ctx context.Context) {
+	go watchdog.LoopWithContext(subCtx, "fetch_l2_blocks", 2*time.Second, func(ctx context.Context) {
 		number, loopErr := butils.GetLatestConfirmedBlockNumber(ctx, l2client, cfg.L2Config.Confirmations)
 		if loopErr != nil {
 			log.Error("failed to get block number", "err", loopErr)
 			return
 		}
-		l2watcher.TryFetchRunningMissingBlocks(number)
+		l2watcher.TryFetchRunningMissingBlocks(ctx, number)
 	})
 
-	go utils.Loop(subCtx, 2*time.Second, chunkProposer.TryProposeChunk)
+	pauser := butils.NewPauser(db, registry)
 
-	go utils.Loop(subCtx, 10*time.Second, batchProposer.TryProposeBatch)
+	go watchdog.LoopWithContext(subCtx, "propose_chunk", 2*time.Second, pauser.Guard(orm.OperationPropose, chunkProposer.TryProposeChunk))
 
-	go utils.Loop(subCtx, 2*time.Second, l2relayer.ProcessPendingBatches)
+	go watchdog.LoopWithContext(subCtx, "propose_batch", 10*time.Second, pauser.Guard(orm.OperationPropose, batchProposer.TryProposeBatch))
 
-	go utils.Loop(subCtx, 15*time.Second, l2relayer.ProcessCommittedBatches)
+	go watchdog.LoopWithContext(subCtx, "commit_batches", 2*time.Second, pauser.Guard(orm.OperationCommit, l2relayer.ProcessPendingBatches))
+
+	go watchdog.LoopWithContext(subCtx, "finalize_batches", 15*time.Second, pauser.Guard(orm.OperationFinalize, l2relayer.ProcessCommittedBatches))
+
+	if feeCfg := cfg.L2Config.RelayerConfig.FeeRecipientConfig; feeCfg != nil {
+		go watchdog.LoopWithContext(subCtx, "sweep_fees", time.Duration(feeCfg.SweepIntervalSec)*time.Second, l2relayer.ProcessFeeSweep)
+	}
+
+	if auditorCfg := cfg.L2Config.BatchAuditorConfig; auditorCfg != nil {
+		batchAuditor := watcher.NewBatchAuditor(auditorCfg, cfg.L2Config.BaseFeeForkBlock, db, registry)
+		go watchdog.LoopWithContext(subCtx, "audit_batches", time.Duration(auditorCfg.AuditIntervalSec)*time.Second, batchAuditor.TryAuditBatches)
+	}
+
+	if monitorCfg := cfg.L2Config.L1MessageQueueMonitorConfig; monitorCfg != nil {
+		l1MessageQueueMonitor := watcher.NewL1MessageQueueMonitor(monitorCfg, db, registry)
+		go watchdog.LoopWithContext(subCtx, "check_l1_message_queue", time.Duration(monitorCfg.CheckIntervalSec)*time.Second, l1MessageQueueMonitor.TryCheckQueueLag)
+	}
+
+	if reportCfg := cfg.L2Config.BatchReportConfig; reportCfg != nil {
+		batchReporter, reporterErr := watcher.NewBatchReporter(reportCfg, l1client, db, registry)
+		if reporterErr != nil {
+			log.Crit("failed to create batch reporter", "config file", cfgFile, "error", reporterErr)
+		}
+		go watchdog.LoopWithContext(subCtx, "report_batches", time.Duration(reportCfg.ReportIntervalSec)*time.Second, batchReporter.TryReportBatches)
+	}
+
+	if checkCfg := cfg.L2Config.CommitmentCheckConfig; checkCfg != nil {
+		commitmentChecker := watcher.NewCommitmentChecker(checkCfg, cfg.L2Config.RelayerConfig.RollupContractAddress, l1client, db, registry)
+		go watchdog.LoopWithContext(subCtx, "check_commitments", time.Duration(checkCfg.CheckIntervalSec)*time.Second, commitmentChecker.TryCheckCommitments)
+	}
+
+	if blockTagCfg := cfg.L2Config.BlockTagConfig; blockTagCfg != nil {
+		l2AdminClient, dialErr := rpc.Dial(blockTagCfg.Endpoint)
+		if dialErr != nil {
+			log.Crit("failed to connect l2 geth admin rpc", "config file", cfgFile, "error", dialErr)
+		}
+		blockTagReporter := watcher.NewBlockTagReporter(blockTagCfg, l2client, l2AdminClient, db, registry)
+		go watchdog.LoopWithContext(subCtx, "report_block_tags", time.Duration(blockTagCfg.ReportIntervalSec)*time.Second, blockTagReporter.TryReportBlockTags)
+	}
+
+	var apiSrv *http.Server
+	if cfg.APIConfig != nil && cfg.APIConfig.Enabled {
+		apiSrv = apiServer(ctx, cfg, chunkProposer, batchProposer, db, registry)
+	}
 
 	// Finish start all rollup relayer functions.
 	log.Info("Start rollup-relayer successfully")
@@ -118,9 +224,40 @@ func action(ctx *cli.Context) error {
 	// Wait until the interrupt signal is received from an OS signal.
 	<-interrupt
 
+	if apiSrv != nil {
+		closeCtx, cancelExit := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelExit()
+		if err = apiSrv.Shutdown(closeCtx); err != nil {
+			log.Warn("shutdown rollup api server failure", "error", err)
+		}
+	}
+
 	return nil
 }
 
+func apiServer(ctx *cli.Context, cfg *config.Config, chunkProposer *watcher.ChunkProposer, batchProposer *watcher.BatchProposer, db *gorm.DB, reg prometheus.Registerer) *http.Server {
+	router := gin.New()
+	// the client ip of the api key issuance is read from X-Forwarded-For only behind the trusted proxies
+	if err := router.SetTrustedProxies(cfg.APIConfig.TrustedProxies); err != nil {
+		log.Crit("invalid rollup api trusted proxies", "error", err)
+	}
+	api.InitController(cfg, chunkProposer, batchProposer, db)
+	route.Route(router, cfg, reg)
+	port := ctx.Int(utils.ServicePortFlag.Name)
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           router,
+		ReadHeaderTimeout: time.Minute,
+	}
+
+	go func() {
+		if runServerErr := srv.ListenAndServe(); runServerErr != nil && !errors.Is(runServerErr, http.ErrServerClosed) {
+			log.Crit("run rollup api server failure", "error", runServerErr)
+		}
+	}()
+	return srv
+}
+
 // Run rollup relayer cmd instance.
 func Run() {
 	if err := app.Run(os.Args); err != nil {
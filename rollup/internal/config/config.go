@@ -3,23 +3,164 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 
+	"github.com/scroll-tech/go-ethereum/common"
+
 	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
 )
 
+// APIConfig loads the rollup api configuration items.
+type APIConfig struct {
+	// Enabled starts the rollup api server on the service port.
+	Enabled bool `json:"enabled"`
+	// PreviewSecret is the bearer token required by the chunk preview endpoint, the endpoint is disabled if empty.
+	PreviewSecret string `json:"preview_secret,omitempty"`
+	// AdminSecret is the bearer token required by the admin endpoints, the endpoints are disabled if empty.
+	AdminSecret string `json:"admin_secret,omitempty"`
+	// APIKeyConfig requires a self-service api key on the public endpoints, they are open if nil.
+	APIKeyConfig *APIKeyConfig `json:"api_key_config,omitempty"`
+	// TrustedProxies lists the ips and cidrs of the reverse proxies whose X-Forwarded-For header is trusted,
+	// the client ip is the remote address of the connection if empty.
+	TrustedProxies []string `json:"trusted_proxies,omitempty"`
+}
+
+// APIKeyConfig loads the self-service api key configuration of the public endpoints of the rollup relayer api.
+// The bridge-history-api endpoints are not covered by the keys.
+type APIKeyConfig struct {
+	// Tiers maps the quota tier names to their daily request quota.
+	Tiers map[string]uint64 `json:"tiers"`
+	// DefaultTier is the tier of the self-service issued keys.
+	DefaultTier string `json:"default_tier"`
+	// MaxKeysPerIPPerDay caps the number of keys issued to a client ip within a day.
+	MaxKeysPerIPPerDay uint64 `json:"max_keys_per_ip_per_day"`
+}
+
+// RPCBreakerConfig loads the circuit breaker and retry budget configuration of the rpc clients.
+type RPCBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit of an endpoint.
+	FailureThreshold int `json:"failure_threshold"`
+	// OpenTimeoutSec is the duration the circuit stays open before a half-open probe is let through.
+	OpenTimeoutSec int `json:"open_timeout_sec"`
+	// RetryRatio is the number of retries earned by each request, the budget is shared by all the endpoints.
+	RetryRatio float64 `json:"retry_ratio"`
+	// MaxRetryTokens caps the number of retries that can be accumulated in the budget.
+	MaxRetryTokens float64 `json:"max_retry_tokens"`
+}
+
+// Watchdog actions applied to a stuck loop.
+const (
+	// WatchdogActionRestart cancels the context of the stuck round and starts the next round once it returns,
+	// the process exits if the round of the loop takes no context or doesn't return once cancelled.
+	WatchdogActionRestart = "restart"
+	// WatchdogActionExit exits the process with a distinct code, so the orchestrator restarts it.
+	WatchdogActionExit = "exit"
+)
+
+// WatchdogConfig loads the supervision configuration of the internal loops.
+type WatchdogConfig struct {
+	// DeadlineSec is the time a round of a loop may take before the loop is considered stuck.
+	DeadlineSec uint64 `json:"deadline_sec"`
+	// LoopDeadlineSec overrides DeadlineSec for the named loops.
+	LoopDeadlineSec map[string]uint64 `json:"loop_deadline_sec,omitempty"`
+	// CheckIntervalSec is the interval between two liveness checks.
+	CheckIntervalSec uint64 `json:"check_interval_sec"`
+	// Action is applied to a stuck loop, either "restart" or "exit".
+	Action string `json:"action"`
+}
+
 // Config load configuration items.
 type Config struct {
-	L1Config *L1Config        `json:"l1_config"`
-	L2Config *L2Config        `json:"l2_config"`
-	DBConfig *database.Config `json:"db_config"`
+	L1Config  *L1Config        `json:"l1_config"`
+	L2Config  *L2Config        `json:"l2_config"`
+	DBConfig  *database.Config `json:"db_config"`
+	APIConfig *APIConfig       `json:"api_config,omitempty"`
+	// RPCBreakerConfig guards the http rpc clients, they are not guarded if nil.
+	RPCBreakerConfig *RPCBreakerConfig `json:"rpc_breaker_config,omitempty"`
+	// WatchdogConfig supervises the internal loops, they are not supervised if nil.
+	WatchdogConfig *WatchdogConfig `json:"watchdog_config,omitempty"`
 }
 
 func (c *Config) validate() error {
 	if maxChunkPerBatch := c.L2Config.BatchProposerConfig.MaxChunkNumPerBatch; maxChunkPerBatch <= 0 {
 		return fmt.Errorf("Invalid max_chunk_num_per_batch configuration: %v", maxChunkPerBatch)
 	}
+	for i, fork := range c.L2Config.ChunkProposerConfig.Forks {
+		if i > 0 && fork.Block <= c.L2Config.ChunkProposerConfig.Forks[i-1].Block {
+			return fmt.Errorf("Invalid chunk proposer forks configuration: fork %v at block %v is not after fork %v", fork.Name, fork.Block, c.L2Config.ChunkProposerConfig.Forks[i-1].Name)
+		}
+	}
+	if chunkCfg := c.L2Config.ChunkProposerConfig; chunkCfg.MinBlockNumPerChunk > 0 &&
+		(chunkCfg.MinBlockNumPerChunk > chunkCfg.MaxBlockNumPerChunk || chunkCfg.MinBlockNumTimeoutSec < chunkCfg.ChunkTimeoutSec) {
+		return fmt.Errorf("Invalid chunk proposer min block number configuration: min_block_num_per_chunk %v, max_block_num_per_chunk %v, min_block_num_timeout_sec %v, chunk_timeout_sec %v",
+			chunkCfg.MinBlockNumPerChunk, chunkCfg.MaxBlockNumPerChunk, chunkCfg.MinBlockNumTimeoutSec, chunkCfg.ChunkTimeoutSec)
+	}
+	if batchCfg := c.L2Config.BatchProposerConfig; batchCfg.MinChunkNumPerBatch > 0 &&
+		(batchCfg.MinChunkNumPerBatch > batchCfg.MaxChunkNumPerBatch || batchCfg.MinChunkNumTimeoutSec < batchCfg.BatchTimeoutSec) {
+		return fmt.Errorf("Invalid batch proposer min chunk number configuration: min_chunk_num_per_batch %v, max_chunk_num_per_batch %v, min_chunk_num_timeout_sec %v, batch_timeout_sec %v",
+			batchCfg.MinChunkNumPerBatch, batchCfg.MaxChunkNumPerBatch, batchCfg.MinChunkNumTimeoutSec, batchCfg.BatchTimeoutSec)
+	}
+	if batchCfg := c.L2Config.BatchProposerConfig; batchCfg.CodecVersion != uint8(codec.CodecV0) {
+		if _, err := codec.New(codec.Version(batchCfg.CodecVersion)); err != nil {
+			return fmt.Errorf("Invalid batch proposer codec_version configuration: %w", err)
+		}
+		if batchCfg.MaxChunkNumPerBatch > types.MaxBlobChunks {
+			return fmt.Errorf("Invalid max_chunk_num_per_batch configuration: %v exceeds the %v chunks of a blob of codec version %v", batchCfg.MaxChunkNumPerBatch, types.MaxBlobChunks, batchCfg.CodecVersion)
+		}
+		if txType := c.L2Config.RelayerConfig.SenderConfig.TxType; txType != "DynamicFeeTx" {
+			return fmt.Errorf("Invalid tx_type configuration: codec version %v commits blob-carrying transactions, which need DynamicFeeTx, got: %v", batchCfg.CodecVersion, txType)
+		}
+	}
+	if auditorCfg := c.L2Config.BatchAuditorConfig; auditorCfg != nil && auditorCfg.AuditIntervalSec == 0 {
+		return fmt.Errorf("Invalid audit_interval_sec configuration: %v", auditorCfg.AuditIntervalSec)
+	}
+	if monitorCfg := c.L2Config.L1MessageQueueMonitorConfig; monitorCfg != nil && monitorCfg.CheckIntervalSec == 0 {
+		return fmt.Errorf("Invalid check_interval_sec configuration: %v", monitorCfg.CheckIntervalSec)
+	}
+	if delayCfg := c.L2Config.RelayerConfig.CommitDelayConfig; delayCfg != nil && delayCfg.MaxDelaySec < delayCfg.MinDelaySec {
+		return fmt.Errorf("Invalid commit delay configuration: max_delay_sec %v is less than min_delay_sec %v", delayCfg.MaxDelaySec, delayCfg.MinDelaySec)
+	}
+	if checkCfg := c.L2Config.CommitmentCheckConfig; checkCfg != nil && (checkCfg.CheckIntervalSec == 0 || checkCfg.NumBatches == 0) {
+		return fmt.Errorf("Invalid commitment check configuration: check_interval_sec %v, num_batches %v", checkCfg.CheckIntervalSec, checkCfg.NumBatches)
+	}
+	if blockTagCfg := c.L2Config.BlockTagConfig; blockTagCfg != nil && (blockTagCfg.ReportIntervalSec == 0 || blockTagCfg.Method == "") {
+		return fmt.Errorf("Invalid block tag configuration: report_interval_sec %v, method %q", blockTagCfg.ReportIntervalSec, blockTagCfg.Method)
+	}
+	if checkCfg := c.L2Config.RelayerConfig.StartupCheckConfig; checkCfg != nil &&
+		checkCfg.Action != StartupCheckActionExit && checkCfg.Action != StartupCheckActionPause {
+		return fmt.Errorf("Invalid startup check action configuration: %v", checkCfg.Action)
+	}
+	if feeCfg := c.L2Config.RelayerConfig.FeeRecipientConfig; feeCfg != nil &&
+		(feeCfg.Address == (common.Address{}) || feeCfg.ReserveBalance == nil || feeCfg.ReserveBalance.Sign() <= 0 || feeCfg.SweepIntervalSec == 0) {
+		return fmt.Errorf("Invalid fee recipient configuration: address %v, reserve_balance %v, sweep_interval_sec %v", feeCfg.Address, feeCfg.ReserveBalance, feeCfg.SweepIntervalSec)
+	}
+	if watchdogCfg := c.WatchdogConfig; watchdogCfg != nil {
+		if watchdogCfg.DeadlineSec == 0 || watchdogCfg.CheckIntervalSec == 0 {
+			return fmt.Errorf("Invalid watchdog configuration: deadline_sec %v, check_interval_sec %v", watchdogCfg.DeadlineSec, watchdogCfg.CheckIntervalSec)
+		}
+		if watchdogCfg.Action != WatchdogActionRestart && watchdogCfg.Action != WatchdogActionExit {
+			return fmt.Errorf("Invalid watchdog action configuration: %v", watchdogCfg.Action)
+		}
+	}
+	if c.APIConfig != nil {
+		for _, proxy := range c.APIConfig.TrustedProxies {
+			if net.ParseIP(proxy) == nil {
+				if _, _, err := net.ParseCIDR(proxy); err != nil {
+					return fmt.Errorf("Invalid api trusted proxy configuration: %v", proxy)
+				}
+			}
+		}
+	}
+	if c.APIConfig != nil && c.APIConfig.APIKeyConfig != nil {
+		apiKeyCfg := c.APIConfig.APIKeyConfig
+		if _, ok := apiKeyCfg.Tiers[apiKeyCfg.DefaultTier]; !ok {
+			return fmt.Errorf("Invalid api key configuration: default_tier %v is not one of the tiers", apiKeyCfg.DefaultTier)
+		}
+	}
 	return nil
 }
 
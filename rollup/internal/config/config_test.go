@@ -3,10 +3,12 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,4 +57,142 @@ func TestConfig(t *testing.T) {
 		_, err = NewConfig(tmpFile.Name())
 		assert.Error(t, err)
 	})
+	t.Run("Unordered Chunk Forks", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.L2Config.ChunkProposerConfig.Forks = []*ChunkForkConfig{
+			{Name: "curie", Block: 200, MaxTxNumPerChunk: 100},
+			{Name: "bernoulli", Block: 100, MaxTxNumPerChunk: 100},
+		}
+		assert.Error(t, cfg.validate())
+
+		cfg.L2Config.ChunkProposerConfig.Forks[0], cfg.L2Config.ChunkProposerConfig.Forks[1] =
+			cfg.L2Config.ChunkProposerConfig.Forks[1], cfg.L2Config.ChunkProposerConfig.Forks[0]
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid Watchdog Action", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.WatchdogConfig = &WatchdogConfig{DeadlineSec: 600, CheckIntervalSec: 30, Action: "kill"}
+		assert.Error(t, cfg.validate())
+
+		cfg.WatchdogConfig.Action = WatchdogActionExit
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid Commit Delay Config", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.L2Config.RelayerConfig.CommitDelayConfig = &CommitDelayConfig{MinDelaySec: 30, MaxDelaySec: 10}
+		assert.Error(t, cfg.validate())
+
+		cfg.L2Config.RelayerConfig.CommitDelayConfig.MaxDelaySec = 30
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid Block Tag Config", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.L2Config.BlockTagConfig = &BlockTagConfig{Endpoint: "http://localhost:8551", ReportIntervalSec: 10}
+		assert.Error(t, cfg.validate())
+
+		cfg.L2Config.BlockTagConfig.Method = "scroll_setBlockTags"
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid Commitment Check Config", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.L2Config.CommitmentCheckConfig = &CommitmentCheckConfig{CheckIntervalSec: 60}
+		assert.Error(t, cfg.validate())
+
+		cfg.L2Config.CommitmentCheckConfig.NumBatches = 100
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid Startup Check Action", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.L2Config.RelayerConfig.StartupCheckConfig = &StartupCheckConfig{Action: "repair"}
+		assert.Error(t, cfg.validate())
+
+		cfg.L2Config.RelayerConfig.StartupCheckConfig.Action = StartupCheckActionPause
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid Fee Recipient Config", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.L2Config.RelayerConfig.FeeRecipientConfig = &FeeRecipientConfig{
+			Address:          common.HexToAddress("0x1234"),
+			SweepIntervalSec: 3600,
+		}
+		assert.Error(t, cfg.validate())
+
+		cfg.L2Config.RelayerConfig.FeeRecipientConfig.ReserveBalance = big.NewInt(1e18)
+		assert.NoError(t, cfg.validate())
+
+		cfg.L2Config.RelayerConfig.FeeRecipientConfig.Address = common.Address{}
+		assert.Error(t, cfg.validate())
+	})
+	t.Run("Invalid Proposer Min Number Config", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		chunkCfg := cfg.L2Config.ChunkProposerConfig
+		chunkCfg.MinBlockNumPerChunk = chunkCfg.MaxBlockNumPerChunk + 1
+		chunkCfg.MinBlockNumTimeoutSec = chunkCfg.ChunkTimeoutSec
+		assert.Error(t, cfg.validate())
+
+		chunkCfg.MinBlockNumPerChunk = chunkCfg.MaxBlockNumPerChunk
+		assert.NoError(t, cfg.validate())
+
+		batchCfg := cfg.L2Config.BatchProposerConfig
+		batchCfg.MinChunkNumPerBatch = batchCfg.MaxChunkNumPerBatch
+		assert.Error(t, cfg.validate())
+
+		batchCfg.MinChunkNumTimeoutSec = batchCfg.BatchTimeoutSec + 60
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid Batch Codec Version", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		batchCfg := cfg.L2Config.BatchProposerConfig
+		batchCfg.CodecVersion = 3
+		assert.Error(t, cfg.validate())
+
+		batchCfg.CodecVersion = 1
+		batchCfg.MaxChunkNumPerBatch = 16
+		assert.Error(t, cfg.validate())
+
+		batchCfg.MaxChunkNumPerBatch = 15
+		cfg.L2Config.RelayerConfig.SenderConfig.TxType = "LegacyTx"
+		assert.Error(t, cfg.validate())
+
+		cfg.L2Config.RelayerConfig.SenderConfig.TxType = "DynamicFeeTx"
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid API Key Default Tier", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.APIConfig = &APIConfig{APIKeyConfig: &APIKeyConfig{Tiers: map[string]uint64{"free": 1000}, DefaultTier: "pro"}}
+		assert.Error(t, cfg.validate())
+
+		cfg.APIConfig.APIKeyConfig.DefaultTier = "free"
+		assert.NoError(t, cfg.validate())
+	})
+	t.Run("Invalid API Trusted Proxy", func(t *testing.T) {
+		cfg, err := NewConfig("../../conf/config.json")
+		assert.NoError(t, err)
+
+		cfg.APIConfig = &APIConfig{TrustedProxies: []string{"10.0.0.1", "proxy.local"}}
+		assert.Error(t, cfg.validate())
+
+		cfg.APIConfig.TrustedProxies = []string{"10.0.0.1", "172.16.0.0/12", "::1"}
+		assert.NoError(t, cfg.validate())
+	})
 }
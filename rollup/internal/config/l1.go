@@ -19,4 +19,16 @@ type L1Config struct {
 	ScrollChainContractAddress common.Address `json:"scroll_chain_address"`
 	// The relayer config
 	RelayerConfig *RelayerConfig `json:"relayer_config"`
+	// l1 eth node url keeping the full history, the logs of the blocks pruned by the main endpoint are read from it.
+	ArchiveEndpoint string `json:"archive_endpoint,omitempty"`
+	// QuorumConfig enables cross-checking safety-critical reads against multiple l1 endpoints.
+	QuorumConfig *QuorumConfig `json:"quorum_config,omitempty"`
+}
+
+// QuorumConfig is the config of multi-endpoint quorum reads.
+type QuorumConfig struct {
+	// Extra l1 eth node urls, queried together with the main endpoint.
+	Endpoints []string `json:"endpoints"`
+	// The number of endpoints that must agree, 0 means a simple majority.
+	Threshold int `json:"threshold"`
 }
@@ -22,6 +22,20 @@ type L2Config struct {
 	ChunkProposerConfig *ChunkProposerConfig `json:"chunk_proposer_config"`
 	// The batch_proposer config
 	BatchProposerConfig *BatchProposerConfig `json:"batch_proposer_config"`
+	// The batch_auditor config, the batch auditor is disabled if nil
+	BatchAuditorConfig *BatchAuditorConfig `json:"batch_auditor_config,omitempty"`
+	// The l1_message_queue_monitor config, the monitor is disabled if nil
+	L1MessageQueueMonitorConfig *L1MessageQueueMonitorConfig `json:"l1_message_queue_monitor_config,omitempty"`
+	// The batch_report config, the per-batch cost report is disabled if nil
+	BatchReportConfig *BatchReportConfig `json:"batch_report_config,omitempty"`
+	// The block_timestamp config, the timestamps of the fetched blocks are not checked if nil
+	BlockTimestampConfig *BlockTimestampConfig `json:"block_timestamp_config,omitempty"`
+	// The block_tag config, the batch boundaries are not reported to the layer 2 node if nil
+	BlockTagConfig *BlockTagConfig `json:"block_tag_config,omitempty"`
+	// The commitment_check config, the commitments of the committed batches are not checked on layer 1 if nil
+	CommitmentCheckConfig *CommitmentCheckConfig `json:"commitment_check_config,omitempty"`
+	// BaseFeeForkBlock is the first l2 block whose BlockContext carries the base fee, the base fee is encoded as zero if nil
+	BaseFeeForkBlock *uint64 `json:"base_fee_fork_block,omitempty"`
 }
 
 // ChunkProposerConfig loads chunk_proposer configuration items.
@@ -33,6 +47,52 @@ type ChunkProposerConfig struct {
 	ChunkTimeoutSec                 uint64  `json:"chunk_timeout_sec"`
 	MaxRowConsumptionPerChunk       uint64  `json:"max_row_consumption_per_chunk"`
 	GasCostIncreaseMultiplier       float64 `json:"gas_cost_increase_multiplier"`
+	// ExactL1CommitCalldataSize enables computing the exact commit calldata size instead of the fast estimate.
+	// The fast estimate sizes each transaction by its cached RLP payload length and leaves out the numBlocks byte,
+	// the exact mode RLP-encodes every transaction again and counts the whole chunk encoding.
+	ExactL1CommitCalldataSize bool `json:"exact_l1_commit_calldata_size,omitempty"`
+	// QuarantineBlockAfterFailures is the number of failures after which a block is proposed alone in a single-block
+	// chunk, counting every time the block breaks a chunk limit on its own and every failed proof of a chunk including it.
+	// The failures are persisted per block. 0 disables the quarantine.
+	QuarantineBlockAfterFailures uint64 `json:"quarantine_block_after_failures,omitempty"`
+	// RecordEstimatorError stores the estimated and actual commit cost of proposed transactions,
+	// grouped by transaction type and target contract.
+	RecordEstimatorError bool `json:"record_estimator_error,omitempty"`
+	// MaxL1MessageNumPerChunk is the max number of l1 messages included in a chunk before the first fork, 0 means unlimited.
+	MaxL1MessageNumPerChunk uint64 `json:"max_l1_message_num_per_chunk,omitempty"`
+	// Forks override the protocol chunk limits from their fork block on, sorted by fork block.
+	Forks []*ChunkForkConfig `json:"forks,omitempty"`
+	// MinBlockNumPerChunk is the min number of blocks of a chunk proposed at the chunk timeout, a timed out
+	// chunk below it waits for more blocks until MinBlockNumTimeoutSec. 0 disables it.
+	MinBlockNumPerChunk uint64 `json:"min_block_num_per_chunk,omitempty"`
+	// MinBlockNumTimeoutSec is the age of the first block after which a chunk below MinBlockNumPerChunk is proposed anyway.
+	MinBlockNumTimeoutSec uint64 `json:"min_block_num_timeout_sec,omitempty"`
+	// MaxCompressedSizePerChunk is the max size of the zstd compressed tx payload of a chunk committed in a
+	// compressed batch, checked on the sum of the estimated compressed sizes of the blocks. 0 disables it.
+	MaxCompressedSizePerChunk uint64 `json:"max_compressed_size_per_chunk,omitempty"`
+	// RowConsumptionRequiredFromBlock is the first l2 block whose row consumption is required, the chunks of
+	// the blocks before it are not limited by the circuit capacity. 0 requires the row consumption of every block.
+	RowConsumptionRequiredFromBlock uint64 `json:"row_consumption_required_from_block,omitempty"`
+	// TxEstimationWorkers is the number of goroutines sizing the l2 transactions of a large block for the l1
+	// commit estimations, the transactions are sized serially if it is at most 1.
+	TxEstimationWorkers int `json:"tx_estimation_workers,omitempty"`
+	// MaxL2GasPerChunk is the max sum of the gas used by the blocks of a chunk, checked as the "max_l2_gas"
+	// packing constraint. 0 means unlimited.
+	MaxL2GasPerChunk uint64 `json:"max_l2_gas_per_chunk,omitempty"`
+	// ReproposeChunksOnStartup re-evaluates the chunks not yet in a batch against MaxRowConsumptionPerChunk on
+	// startup, the first chunk breaking it and the chunks after it are deleted and proposed again. So is the first
+	// failed chunk including a block to quarantine.
+	ReproposeChunksOnStartup bool `json:"repropose_chunks_on_startup,omitempty"`
+}
+
+// ChunkForkConfig loads the protocol chunk limits of a hard fork.
+type ChunkForkConfig struct {
+	// Name is the fork name, only used in logs.
+	Name string `json:"name"`
+	// Block is the first l2 block number of the fork.
+	Block                   uint64 `json:"block"`
+	MaxTxNumPerChunk        uint64 `json:"max_tx_num_per_chunk"`
+	MaxL1MessageNumPerChunk uint64 `json:"max_l1_message_num_per_chunk,omitempty"`
 }
 
 // BatchProposerConfig loads batch_proposer configuration items.
@@ -42,4 +102,81 @@ type BatchProposerConfig struct {
 	MaxL1CommitCalldataSizePerBatch uint32  `json:"max_l1_commit_calldata_size_per_batch"`
 	BatchTimeoutSec                 uint64  `json:"batch_timeout_sec"`
 	GasCostIncreaseMultiplier       float64 `json:"gas_cost_increase_multiplier"`
+	// MaxL1CommitTxCalldataSize is the max size of the abi encoded commitBatch calldata, batches whose commit
+	// transaction would exceed the layer 1 transaction size limit are not proposed. The limit is disabled if 0.
+	MaxL1CommitTxCalldataSize uint64 `json:"max_l1_commit_tx_calldata_size,omitempty"`
+	// MinChunkNumPerBatch is the min number of chunks of a batch proposed at the batch timeout, a timed out
+	// batch below it waits for more chunks until MinChunkNumTimeoutSec. 0 disables it.
+	MinChunkNumPerBatch uint64 `json:"min_chunk_num_per_batch,omitempty"`
+	// MinChunkNumTimeoutSec is the age of the first block after which a batch below MinChunkNumPerBatch is proposed anyway.
+	MinChunkNumTimeoutSec uint64 `json:"min_chunk_num_timeout_sec,omitempty"`
+	// MaxL1MessagePoppedPerBatch is the max number of l1 messages popped by the chunks of a batch, which bounds
+	// the skipped l1 message bitmap of the commit calldata. 0 means unlimited.
+	MaxL1MessagePoppedPerBatch uint64 `json:"max_l1_message_popped_per_batch,omitempty"`
+	// CodecVersion is the codec version of the proposed batches, the batches committing their L2 transactions
+	// in a blob from CodecV1 on. The version of the batches never decreases, a lower version keeps the one of the
+	// latest batch.
+	CodecVersion uint8 `json:"codec_version,omitempty"`
+}
+
+// BatchAuditorConfig loads batch_auditor configuration items.
+type BatchAuditorConfig struct {
+	// AuditIntervalSec is the interval between two audit rounds.
+	AuditIntervalSec uint64 `json:"audit_interval_sec"`
+	// NumBatches is the number of latest batches to audit in one round.
+	NumBatches uint64 `json:"num_batches"`
+}
+
+// L1MessageQueueMonitorConfig loads l1_message_queue_monitor configuration items.
+type L1MessageQueueMonitorConfig struct {
+	// CheckIntervalSec is the interval between two checks.
+	CheckIntervalSec uint64 `json:"check_interval_sec"`
+	// MaxQueueLag is the alerting threshold of the number of l1 messages not yet included on layer 2, 0 disables it.
+	MaxQueueLag uint64 `json:"max_queue_lag"`
+	// MaxStalledSec is the alerting threshold of the duration in which pending l1 messages are not included, 0 disables it.
+	MaxStalledSec uint64 `json:"max_stalled_sec"`
+}
+
+// BatchReportConfig loads batch_report configuration items.
+type BatchReportConfig struct {
+	// ReportIntervalSec is the interval between two report rounds.
+	ReportIntervalSec uint64 `json:"report_interval_sec"`
+	// ExportDir is the directory the csv reports are written to, it may be a mounted object store bucket.
+	ExportDir string `json:"export_dir"`
+	// MaxBatchesPerReport is the max number of finalized batches summarized in one report file.
+	MaxBatchesPerReport uint64 `json:"max_batches_per_report"`
+}
+
+// BlockTimestampConfig loads block_timestamp configuration items.
+type BlockTimestampConfig struct {
+	// MaxL1DriftSec is the max number of seconds a block timestamp may be ahead of the latest layer 1 block.
+	MaxL1DriftSec uint64 `json:"max_l1_drift_sec"`
+	// RejectAnomalies stops storing blocks with anomalous timestamps instead of only flagging them.
+	RejectAnomalies bool `json:"reject_anomalies,omitempty"`
+}
+
+// BlockTagConfig loads block_tag configuration items.
+type BlockTagConfig struct {
+	// Endpoint is the admin rpc endpoint of the layer 2 node.
+	Endpoint string `json:"endpoint"`
+	// Method is the admin rpc method setting the safe and finalized blocks of the layer 2 node.
+	Method string `json:"method"`
+	// ReportIntervalSec is the interval between two report rounds.
+	ReportIntervalSec uint64 `json:"report_interval_sec"`
+	// MaxRetries is the number of retries of a failed report within a round.
+	MaxRetries uint64 `json:"max_retries"`
+}
+
+// CommitmentCheckConfig loads commitment_check configuration items.
+type CommitmentCheckConfig struct {
+	// CheckIntervalSec is the interval between two check rounds.
+	CheckIntervalSec uint64 `json:"check_interval_sec"`
+	// NumBatches is the number of latest committed and not yet finalized batches checked in one round.
+	NumBatches uint64 `json:"num_batches"`
+	// Resubmit marks the batches whose commitment is missing on layer 1 as commit failed, so they are committed again.
+	Resubmit bool `json:"resubmit,omitempty"`
+	// MissingRounds is the number of consecutive rounds the commitment of a batch must be missing before it is
+	// committed again, so a commitment dropped by a short layer 1 reorg and included again is not committed twice.
+	// Defaults to 3.
+	MissingRounds uint64 `json:"missing_rounds,omitempty"`
 }
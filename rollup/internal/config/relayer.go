@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/crypto"
@@ -26,8 +27,17 @@ type SenderConfig struct {
 	EscalateMultipleDen uint64 `json:"escalate_multiple_den"`
 	// The maximum gas price can be used to send transaction.
 	MaxGasPrice uint64 `json:"max_gas_price"`
+	// The maximum blob gas price of a blob-carrying transaction, unlimited if 0.
+	MaxBlobGasPrice uint64 `json:"max_blob_gas_price,omitempty"`
 	// The transaction type to use: LegacyTx, AccessListTx, DynamicFeeTx
 	TxType string `json:"tx_type"`
+	// DisableAccessList stops attaching EIP-2930 access lists, which are otherwise used when they reduce gas.
+	DisableAccessList bool `json:"disable_access_list,omitempty"`
+	// PrivateEndpoint is a protected rpc endpoint, e.g. a private relay, the signed transactions are
+	// broadcast through it instead of Endpoint, which is still used to read the chain.
+	PrivateEndpoint string `json:"private_endpoint,omitempty"`
+	// PrivateFallbackToPublic broadcasts a transaction through Endpoint if the private endpoint rejects it.
+	PrivateFallbackToPublic bool `json:"private_fallback_to_public,omitempty"`
 }
 
 // ChainMonitor this config is used to get batch status from chain_monitor API.
@@ -53,6 +63,15 @@ type RelayerConfig struct {
 	ChainMonitor *ChainMonitor `json:"chain_monitor"`
 	// L1CommitGasLimitMultiplier multiplier for fallback gas limit in commitBatch txs
 	L1CommitGasLimitMultiplier float64 `json:"l1_commit_gas_limit_multiplier,omitempty"`
+	// CommitDelayConfig delays the commit transactions of the sealed batches, they are committed right away if nil.
+	CommitDelayConfig *CommitDelayConfig `json:"commit_delay_config,omitempty"`
+	// DiagnoseCommitFailures replays the reverted commit txs and stores the most likely cause of the failure.
+	DiagnoseCommitFailures bool `json:"diagnose_commit_failures,omitempty"`
+	// StartupCheckConfig compares the local batches with the rollup contract at startup, they are not compared if nil.
+	StartupCheckConfig *StartupCheckConfig `json:"startup_check_config,omitempty"`
+	// FeeRecipientConfig sweeps the surplus balance of the commit and finalize senders to the operator fee
+	// recipient, nothing is swept if nil.
+	FeeRecipientConfig *FeeRecipientConfig `json:"fee_recipient_config,omitempty"`
 	// The private key of the relayer
 	GasOracleSenderPrivateKey *ecdsa.PrivateKey `json:"-"`
 	CommitSenderPrivateKey    *ecdsa.PrivateKey `json:"-"`
@@ -64,12 +83,66 @@ type RelayerConfig struct {
 	FinalizeBatchWithoutProofTimeoutSec uint64 `json:"finalize_batch_without_proof_timeout_sec"`
 }
 
+// Startup check actions applied when the local batches don't match the rollup contract.
+const (
+	// StartupCheckActionExit refuses to start the rollup relayer.
+	StartupCheckActionExit = "exit"
+	// StartupCheckActionPause starts the rollup relayer in repair mode, committing and finalizing batches are paused.
+	StartupCheckActionPause = "pause"
+)
+
+// StartupCheckConfig loads startup_check configuration items.
+type StartupCheckConfig struct {
+	// Action is applied when the local batches don't match the rollup contract, either "exit" or "pause".
+	Action string `json:"action"`
+}
+
+// FeeRecipientConfig loads fee_recipient configuration items.
+type FeeRecipientConfig struct {
+	// Address is the operator fee recipient, the refunds and any other balance the senders accrue above
+	// the reserve balance are sent to it.
+	Address common.Address `json:"address"`
+	// ReserveBalance is the balance in wei every sender keeps to pay for its transactions, the sweep
+	// transaction included.
+	ReserveBalance *big.Int `json:"reserve_balance"`
+	// SweepIntervalSec is the interval between two sweeps.
+	SweepIntervalSec uint64 `json:"sweep_interval_sec"`
+}
+
+// CommitDelayConfig loads commit_delay configuration items.
+type CommitDelayConfig struct {
+	// MinDelaySec is the min delay between the sealing of a batch and the broadcast of its commit transaction.
+	MinDelaySec uint64 `json:"min_delay_sec"`
+	// MaxDelaySec is the max delay, the delay of each batch is drawn uniformly between the min and the max,
+	// it is fixed if both are equal.
+	MaxDelaySec uint64 `json:"max_delay_sec"`
+}
+
 // GasOracleConfig The config for updating gas price oracle.
 type GasOracleConfig struct {
 	// MinGasPrice store the minimum gas price to set.
 	MinGasPrice uint64 `json:"min_gas_price"`
 	// GasPriceDiff store the percentage of gas price difference.
 	GasPriceDiff uint64 `json:"gas_price_diff"`
+
+	// EnableBlobBaseFee pushes the blob base fee alongside the l1 base fee (post EIP-4844).
+	EnableBlobBaseFee bool `json:"enable_blob_base_fee,omitempty"`
+	// MinBlobGasPrice store the minimum blob gas price to set.
+	MinBlobGasPrice uint64 `json:"min_blob_gas_price,omitempty"`
+	// BlobGasPriceDiff store the percentage of blob gas price difference.
+	BlobGasPriceDiff uint64 `json:"blob_gas_price_diff,omitempty"`
+	// L1BaseFeeSmoothingFactor is the weight of the newest l1 base fee in its moving average, 0 disables smoothing.
+	L1BaseFeeSmoothingFactor float64 `json:"l1_base_fee_smoothing_factor,omitempty"`
+	// BlobBaseFeeSmoothingFactor is the weight of the newest blob base fee in its moving average, 0 disables smoothing.
+	BlobBaseFeeSmoothingFactor float64 `json:"blob_base_fee_smoothing_factor,omitempty"`
+	// SimulateBeforeSend simulates the oracle update call and skips sending if it would revert.
+	SimulateBeforeSend bool `json:"simulate_before_send,omitempty"`
+	// L1FeeMedianWindow is the number of the latest l1 blocks whose median fees are smoothed instead of the fees
+	// of the latest block, so a single anomalous block doesn't move the pushed fees. 0 or 1 disables it.
+	L1FeeMedianWindow int `json:"l1_fee_median_window,omitempty"`
+	// MaxGasPriceDelta clamps the change of the pushed fees relative to the last pushed ones, with the precision
+	// of gas_price_diff, a larger move is spread over several updates. 0 disables it.
+	MaxGasPriceDelta uint64 `json:"max_gas_price_delta,omitempty"`
 }
 
 // relayerConfigAlias RelayerConfig alias name
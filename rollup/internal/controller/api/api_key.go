@@ -0,0 +1,217 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+// APIKeyHeader is the request header carrying the api key.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyUsageDays is the number of days returned by the usage endpoint.
+const apiKeyUsageDays = 7
+
+// APIKeyController the self-service api key controller
+type APIKeyController struct {
+	cfg            *config.APIKeyConfig
+	apiKeyOrm      *orm.APIKey
+	apiKeyUsageOrm *orm.APIKeyUsage
+}
+
+// NewAPIKeyController create an api key controller, cfg must not be nil
+func NewAPIKeyController(cfg *config.APIKeyConfig, db *gorm.DB) *APIKeyController {
+	return &APIKeyController{
+		cfg:            cfg,
+		apiKeyOrm:      orm.NewAPIKey(db),
+		apiKeyUsageOrm: orm.NewAPIKeyUsage(db),
+	}
+}
+
+// Issue issues a new api key of the default tier, the number of keys issued to a client ip per day is capped
+func (c *APIKeyController) Issue(ctx *gin.Context) {
+	var req rollupTypes.APIKeyIssueRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		nerr := fmt.Errorf("api key issue request invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	key, err := newAPIKey()
+	if err != nil {
+		nerr := fmt.Errorf("generate api key failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIKeyFailure, nerr)
+		return
+	}
+
+	// the client ip is only read from the forwarding headers set by the trusted proxies
+	clientIP := ctx.ClientIP()
+	apiKey, err := c.apiKeyOrm.InsertAPIKeyWithinLimit(ctx, hashAPIKey(key), req.Owner, c.cfg.DefaultTier, clientIP, time.Now().Add(-24*time.Hour), c.cfg.MaxKeysPerIPPerDay)
+	if err != nil {
+		nerr := fmt.Errorf("insert api key failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIKeyFailure, nerr)
+		return
+	}
+	if apiKey == nil {
+		nerr := fmt.Errorf("too many api keys issued to %v, at most %v keys are issued per day", clientIP, c.cfg.MaxKeysPerIPPerDay)
+		types.RenderFailure(ctx, types.ErrRollupAPIQuotaExceeded, nerr)
+		return
+	}
+
+	types.RenderSuccess(ctx, &rollupTypes.APIKeyIssueSchema{
+		ID:         apiKey.ID,
+		Key:        key,
+		Tier:       apiKey.Tier,
+		DailyQuota: c.dailyQuota(apiKey.Tier),
+	})
+}
+
+// Usage returns the quota and the recent daily usage of the api key of the request, it is not counted as a request
+func (c *APIKeyController) Usage(ctx *gin.Context) {
+	apiKey, ok := c.lookup(ctx)
+	if !ok {
+		return
+	}
+
+	today := utcDay(time.Now())
+	usages, err := c.apiKeyUsageOrm.GetUsages(ctx, apiKey.ID, today.AddDate(0, 0, 1-apiKeyUsageDays))
+	if err != nil {
+		nerr := fmt.Errorf("get api key usages failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIKeyFailure, nerr)
+		return
+	}
+
+	schema := &rollupTypes.APIKeyUsageSchema{
+		ID:         apiKey.ID,
+		Tier:       apiKey.Tier,
+		DailyQuota: c.dailyQuota(apiKey.Tier),
+		Days:       make([]*rollupTypes.APIKeyDailyUsageSchema, 0, len(usages)),
+	}
+	for _, usage := range usages {
+		schema.Days = append(schema.Days, &rollupTypes.APIKeyDailyUsageSchema{
+			Day:          usage.Day.UTC().Format("2006-01-02"),
+			RequestCount: usage.RequestCount,
+		})
+	}
+	types.RenderSuccess(ctx, schema)
+}
+
+// SetTier moves an api key to another quota tier
+func (c *APIKeyController) SetTier(ctx *gin.Context) {
+	var para rollupTypes.APIKeyTierParameter
+	if err := ctx.ShouldBindUri(&para); err != nil {
+		nerr := fmt.Errorf("api key tier parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	var req rollupTypes.APIKeyTierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		nerr := fmt.Errorf("api key tier request invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	if _, ok := c.cfg.Tiers[req.Tier]; !ok {
+		nerr := fmt.Errorf("unknown tier %v", req.Tier)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	found, err := c.apiKeyOrm.UpdateAPIKeyTier(ctx, para.ID, req.Tier)
+	if err != nil {
+		nerr := fmt.Errorf("update api key tier failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIKeyFailure, nerr)
+		return
+	}
+	if !found {
+		nerr := fmt.Errorf("api key %v not found", para.ID)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+	types.RenderSuccess(ctx, nil)
+}
+
+// Authorize is the middleware of the public endpoints, it counts the request against the daily quota of the api key
+func (c *APIKeyController) Authorize(ctx *gin.Context) {
+	apiKey, ok := c.lookup(ctx)
+	if !ok {
+		ctx.Abort()
+		return
+	}
+
+	count, err := c.apiKeyUsageOrm.IncreaseUsage(ctx, apiKey.ID, utcDay(time.Now()))
+	if err != nil {
+		nerr := fmt.Errorf("increase api key usage failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIKeyFailure, nerr)
+		ctx.Abort()
+		return
+	}
+
+	if quota := c.dailyQuota(apiKey.Tier); count > quota {
+		nerr := fmt.Errorf("daily quota of %v requests exceeded, tier: %v", quota, apiKey.Tier)
+		types.RenderFailure(ctx, types.ErrRollupAPIQuotaExceeded, nerr)
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
+// lookup returns the api key of the request, the failure is rendered if the key is missing or unknown.
+func (c *APIKeyController) lookup(ctx *gin.Context) (*orm.APIKey, bool) {
+	key := ctx.GetHeader(APIKeyHeader)
+	if key == "" {
+		types.RenderFailure(ctx, types.ErrRollupAPIUnauthorized, fmt.Errorf("missing %v header", APIKeyHeader))
+		return nil, false
+	}
+
+	apiKey, err := c.apiKeyOrm.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		nerr := fmt.Errorf("get api key failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIKeyFailure, nerr)
+		return nil, false
+	}
+	if apiKey == nil {
+		types.RenderFailure(ctx, types.ErrRollupAPIUnauthorized, errors.New("unknown api key"))
+		return nil, false
+	}
+	return apiKey, true
+}
+
+// dailyQuota returns the daily quota of the tier, the keys of a tier removed from the configuration
+// fall back to the default tier.
+func (c *APIKeyController) dailyQuota(tier string) uint64 {
+	if quota, ok := c.cfg.Tiers[tier]; ok {
+		return quota
+	}
+	return c.cfg.Tiers[c.cfg.DefaultTier]
+}
+
+func newAPIKey() (string, error) {
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key[:]), nil
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func utcDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/rlp"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+const (
+	batchTimelineDayLayout = "2006-01-02"
+	// maxBatchTimelineDays bounds the days of a /batch/timeline query.
+	maxBatchTimelineDays = 31
+)
+
+// BatchController the batch api controller
+type BatchController struct {
+	batchOrm              *orm.Batch
+	pendingTransactionOrm *orm.PendingTransaction
+	batchEventOrm         *orm.BatchEvent
+}
+
+// NewBatchController create a batch api controller
+func NewBatchController(db *gorm.DB) *BatchController {
+	return &BatchController{
+		batchOrm:              orm.NewBatch(db),
+		pendingTransactionOrm: orm.NewPendingTransaction(db),
+		batchEventOrm:         orm.NewBatchEvent(db),
+	}
+}
+
+// CommitData returns the exact data committed to layer 1 for a batch, so external verifiers
+// can re-derive the layer 2 state without running the relayer
+func (c *BatchController) CommitData(ctx *gin.Context) {
+	var para rollupTypes.BatchCommitDataParameter
+	if err := ctx.ShouldBindUri(&para); err != nil {
+		nerr := fmt.Errorf("batch commit data parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	batch, err := c.batchOrm.GetBatchByIndex(ctx, para.Index)
+	if err != nil {
+		nerr := fmt.Errorf("get batch failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIBatchCommitDataFailure, nerr)
+		return
+	}
+	if batch.CommitTxHash == "" {
+		nerr := fmt.Errorf("batch %v is not committed yet", para.Index)
+		types.RenderFailure(ctx, types.ErrRollupAPIBatchCommitDataFailure, nerr)
+		return
+	}
+
+	pendingTx, err := c.pendingTransactionOrm.GetPendingTransactionByTxHash(ctx, common.HexToHash(batch.CommitTxHash))
+	if err != nil {
+		nerr := fmt.Errorf("get commit transaction failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIBatchCommitDataFailure, nerr)
+		return
+	}
+
+	tx := new(gethTypes.Transaction)
+	if err = tx.DecodeRLP(rlp.NewStream(bytes.NewReader(pendingTx.RLPEncoding), 0)); err != nil {
+		nerr := fmt.Errorf("decode commit transaction failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIBatchCommitDataFailure, nerr)
+		return
+	}
+
+	types.RenderSuccess(ctx, &rollupTypes.BatchCommitDataSchema{
+		Index:               batch.Index,
+		Hash:                batch.Hash,
+		CommitTxHash:        batch.CommitTxHash,
+		Data:                tx.Data(),
+		ContentHash:         crypto.Keccak256Hash(tx.Data()),
+		BlobVersionedHashes: tx.BlobHashes(),
+	})
+}
+
+// StateAt returns the last committed and the last finalized batch as of a past layer 1 block,
+// answered from the recorded layer 1 rollup events
+func (c *BatchController) StateAt(ctx *gin.Context) {
+	var para rollupTypes.BatchStateAtParameter
+	if err := ctx.ShouldBindQuery(&para); err != nil {
+		nerr := fmt.Errorf("batch state parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	state := rollupTypes.BatchStateAtSchema{L1BlockNumber: para.L1BlockNumber}
+	committedIndex, found, err := c.batchEventOrm.GetLatestBatchIndexAt(ctx, types.RollupCommitted, para.L1BlockNumber)
+	if err != nil {
+		nerr := fmt.Errorf("get last committed batch failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIBatchStateFailure, nerr)
+		return
+	}
+	if found {
+		state.LastCommittedBatchIndex = &committedIndex
+	}
+
+	finalizedIndex, found, err := c.batchEventOrm.GetLatestBatchIndexAt(ctx, types.RollupFinalized, para.L1BlockNumber)
+	if err != nil {
+		nerr := fmt.Errorf("get last finalized batch failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIBatchStateFailure, nerr)
+		return
+	}
+	if found {
+		state.LastFinalizedBatchIndex = &finalizedIndex
+	}
+
+	types.RenderSuccess(ctx, &state)
+}
+
+// Timeline returns the lifecycle of the batches proposed in a range of days grouped by the day of
+// their proposal, with the average duration of every stage per day, to track where the time to
+// finality goes
+func (c *BatchController) Timeline(ctx *gin.Context) {
+	var para rollupTypes.BatchTimelineParameter
+	if err := ctx.ShouldBindQuery(&para); err != nil {
+		nerr := fmt.Errorf("batch timeline parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+	if para.EndDay == "" {
+		para.EndDay = para.StartDay
+	}
+	startDay, err := time.Parse(batchTimelineDayLayout, para.StartDay)
+	if err != nil {
+		nerr := fmt.Errorf("batch timeline parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+	endDay, err := time.Parse(batchTimelineDayLayout, para.EndDay)
+	if err != nil {
+		nerr := fmt.Errorf("batch timeline parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+	dayNum := int(endDay.Sub(startDay)/(24*time.Hour)) + 1
+	if dayNum <= 0 || dayNum > maxBatchTimelineDays {
+		nerr := fmt.Errorf("batch timeline parameter invalid, err:end day must be within %v days from start day", maxBatchTimelineDays)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	batches, err := c.batchOrm.GetBatchTimelines(ctx, startDay, endDay.AddDate(0, 0, 1))
+	if err != nil {
+		nerr := fmt.Errorf("get batch timelines failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIBatchTimelineFailure, nerr)
+		return
+	}
+
+	types.RenderSuccess(ctx, newBatchTimelineDays(startDay, dayNum, batches))
+}
+
+// newBatchTimelineDays groups the batch timelines by the day of their proposal, the days without
+// any batch proposed are kept so the timeline has no holes.
+func newBatchTimelineDays(startDay time.Time, dayNum int, batches []*orm.Batch) []*rollupTypes.BatchTimelineDaySchema {
+	type stageSum struct {
+		sum   int64
+		count int
+	}
+	days := make([]*rollupTypes.BatchTimelineDaySchema, dayNum)
+	sums := make([][4]stageSum, dayNum)
+	for i := range days {
+		days[i] = &rollupTypes.BatchTimelineDaySchema{
+			Day:     startDay.AddDate(0, 0, i).Format(batchTimelineDayLayout),
+			Batches: []*rollupTypes.BatchTimelineSchema{},
+		}
+	}
+
+	for _, batch := range batches {
+		i := int(batch.CreatedAt.Sub(startDay) / (24 * time.Hour))
+		if i < 0 || i >= dayNum {
+			continue
+		}
+		timeline := newBatchTimeline(batch)
+		days[i].Batches = append(days[i].Batches, timeline)
+		days[i].BatchCount++
+		if timeline.FinalizedAt != nil {
+			days[i].FinalizedBatchCount++
+		}
+		for stage, duration := range []*int64{timeline.CommitDurationSec, timeline.ProveDurationSec, timeline.FinalizeDurationSec, timeline.TotalDurationSec} {
+			if duration != nil {
+				sums[i][stage].sum += *duration
+				sums[i][stage].count++
+			}
+		}
+	}
+
+	for i, day := range days {
+		for stage, avg := range []*float64{&day.AvgCommitDurationSec, &day.AvgProveDurationSec, &day.AvgFinalizeDurationSec, &day.AvgTotalDurationSec} {
+			if sums[i][stage].count > 0 {
+				*avg = float64(sums[i][stage].sum) / float64(sums[i][stage].count)
+			}
+		}
+	}
+	return days
+}
+
+// newBatchTimeline computes the stage durations of a batch, a stage whose end is not reached
+// yet has no duration.
+func newBatchTimeline(batch *orm.Batch) *rollupTypes.BatchTimelineSchema {
+	unix := func(t *time.Time) *int64 {
+		if t == nil {
+			return nil
+		}
+		sec := t.Unix()
+		return &sec
+	}
+	between := func(from, to *int64) *int64 {
+		if from == nil || to == nil {
+			return nil
+		}
+		sec := *to - *from
+		if sec < 0 {
+			sec = 0
+		}
+		return &sec
+	}
+
+	proposedAt := batch.CreatedAt.Unix()
+	timeline := &rollupTypes.BatchTimelineSchema{
+		Index:       batch.Index,
+		Hash:        batch.Hash,
+		ProposedAt:  proposedAt,
+		CommittedAt: unix(batch.CommittedAt),
+		ProvedAt:    unix(batch.ProvedAt),
+		FinalizedAt: unix(batch.FinalizedAt),
+	}
+	timeline.CommitDurationSec = between(&proposedAt, timeline.CommittedAt)
+	timeline.ProveDurationSec = between(timeline.CommittedAt, timeline.ProvedAt)
+	// the batches are finalized once both committed and proved
+	finalizeStart := timeline.CommittedAt
+	if timeline.ProvedAt != nil && finalizeStart != nil && *timeline.ProvedAt > *finalizeStart {
+		finalizeStart = timeline.ProvedAt
+	}
+	timeline.FinalizeDurationSec = between(finalizeStart, timeline.FinalizedAt)
+	timeline.TotalDurationSec = between(&proposedAt, timeline.FinalizedAt)
+	return timeline
+}
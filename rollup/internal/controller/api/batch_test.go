@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/orm"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+// commitBatchCalldata packs the commitBatch calldata of a batch of one chunk the way the relayer does.
+func commitBatchCalldata(t *testing.T) (calldata []byte, parentBatchHeader []byte, encodedChunks [][]byte) {
+	templateBlockTrace, err := os.ReadFile("../../../../common/testdata/blockTrace_02.json")
+	require.NoError(t, err)
+	block := &types.WrappedBlock{}
+	require.NoError(t, json.Unmarshal(templateBlockTrace, block))
+	chunks := []*types.Chunk{{Blocks: []*types.WrappedBlock{block}}}
+
+	batchCodec, err := codec.New(codec.CodecV0)
+	require.NoError(t, err)
+	parentBatch, err := batchCodec.NewBatchHeader(0, 0, common.Hash{}, chunks)
+	require.NoError(t, err)
+	currentBatch, err := batchCodec.NewBatchHeader(1, 0, parentBatch.Hash(), chunks)
+	require.NoError(t, err)
+	encodedChunk, err := batchCodec.EncodeChunk(chunks[0], 0)
+	require.NoError(t, err)
+
+	encodedChunks = [][]byte{encodedChunk}
+	calldata, err = bridgeAbi.ScrollChainABI.Pack("commitBatch", currentBatch.Version(), parentBatch.Encode(), encodedChunks, currentBatch.SkippedL1MessageBitmap())
+	require.NoError(t, err)
+	return calldata, parentBatch.Encode(), encodedChunks
+}
+
+func TestBatchControllerCommitData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calldata, parentBatchHeader, encodedChunks := commitBatchCalldata(t)
+	scrollChainAddress := common.HexToAddress("0x1234")
+	commitTx := gethTypes.NewTx(&gethTypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       1000000,
+		To:        &scrollChainAddress,
+		Data:      calldata,
+	})
+	commitTxRLP := new(bytes.Buffer)
+	require.NoError(t, commitTx.EncodeRLP(commitTxRLP))
+
+	tests := []struct {
+		name             string
+		batch            *orm.Batch
+		expectCode       int
+		expectTxFetched  bool
+		expectCommitData bool
+	}{
+		{
+			name:       "UnknownBatch",
+			expectCode: types.ErrRollupAPIBatchCommitDataFailure,
+		},
+		{
+			name:       "NotCommittedBatch",
+			batch:      &orm.Batch{Index: 1, Hash: "0x01", RollupStatus: int16(types.RollupPending)},
+			expectCode: types.ErrRollupAPIBatchCommitDataFailure,
+		},
+		{
+			name:             "CommittedBatch",
+			batch:            &orm.Batch{Index: 1, Hash: "0x01", RollupStatus: int16(types.RollupCommitted), CommitTxHash: commitTx.Hash().String()},
+			expectCode:       types.Success,
+			expectTxFetched:  true,
+			expectCommitData: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var txFetched bool
+			patches := gomonkey.ApplyMethodFunc(&orm.Batch{}, "GetBatchByIndex", func(ctx context.Context, index uint64) (*orm.Batch, error) {
+				if tt.batch == nil || tt.batch.Index != index {
+					return nil, fmt.Errorf("Batch.GetBatchByIndex error: %w, index: %v", gorm.ErrRecordNotFound, index)
+				}
+				return tt.batch, nil
+			})
+			defer patches.Reset()
+			patches.ApplyMethodFunc(&orm.PendingTransaction{}, "GetPendingTransactionByTxHash", func(ctx context.Context, hash common.Hash) (*orm.PendingTransaction, error) {
+				txFetched = true
+				assert.Equal(t, commitTx.Hash(), hash)
+				return &orm.PendingTransaction{Hash: hash.String(), RLPEncoding: commitTxRLP.Bytes()}, nil
+			})
+
+			router := gin.New()
+			router.GET("/batch/:index/commit_data", NewBatchController(nil).CommitData)
+			req := httptest.NewRequest(http.MethodGet, "/batch/1/commit_data", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp struct {
+				ErrCode int                               `json:"errcode"`
+				ErrMsg  string                            `json:"errmsg"`
+				Data    rollupTypes.BatchCommitDataSchema `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectCode, resp.ErrCode, resp.ErrMsg)
+			assert.Equal(t, tt.expectTxFetched, txFetched)
+			if !tt.expectCommitData {
+				return
+			}
+
+			assert.Equal(t, tt.batch.Index, resp.Data.Index)
+			assert.Equal(t, tt.batch.Hash, resp.Data.Hash)
+			assert.Equal(t, tt.batch.CommitTxHash, resp.Data.CommitTxHash)
+			assert.Equal(t, calldata, []byte(resp.Data.Data))
+			assert.Equal(t, crypto.Keccak256Hash(calldata), resp.Data.ContentHash)
+			assert.Empty(t, resp.Data.BlobVersionedHashes)
+
+			// the returned data is the commitBatch call the relayer sent
+			method, err := bridgeAbi.ScrollChainABI.MethodById(resp.Data.Data[:4])
+			require.NoError(t, err)
+			assert.Equal(t, "commitBatch", method.Name)
+			args, err := method.Inputs.Unpack(resp.Data.Data[4:])
+			require.NoError(t, err)
+			require.Len(t, args, 4)
+			assert.Equal(t, uint8(codec.CodecV0), args[0])
+			assert.Equal(t, parentBatchHeader, args[1])
+			assert.Equal(t, encodedChunks, args[2])
+		})
+	}
+}
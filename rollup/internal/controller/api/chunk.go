@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/controller/watcher"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+// chunkEstimator is the part of the chunk proposer serving the chunk estimations.
+type chunkEstimator interface {
+	WhatIf(ctx context.Context, startBlockNumber, endBlockNumber uint64, candidates []*types.WrappedBlock) (*watcher.ChunkEstimation, *watcher.ChunkEstimation, error)
+	Preview(ctx context.Context) (*watcher.ChunkPreview, error)
+}
+
+// ChunkController the chunk api controller
+type ChunkController struct {
+	chunkProposer chunkEstimator
+}
+
+// NewChunkController create a chunk api controller
+func NewChunkController(chunkProposer chunkEstimator) *ChunkController {
+	return &ChunkController{
+		chunkProposer: chunkProposer,
+	}
+}
+
+// WhatIf returns the calldata size, commit gas and row consumption of the current open chunk
+// and of the chunk after adding the candidate blocks
+func (c *ChunkController) WhatIf(ctx *gin.Context) {
+	var para rollupTypes.ChunkWhatIfParameter
+	if err := ctx.ShouldBind(&para); err != nil {
+		nerr := fmt.Errorf("chunk what-if parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	startBlockNumber := *para.StartBlockNumber
+	if len(para.Blocks) == 0 && para.EndBlockNumber < startBlockNumber {
+		nerr := fmt.Errorf("end block number %v is less than start block number %v", para.EndBlockNumber, startBlockNumber)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	current, proposed, err := c.chunkProposer.WhatIf(ctx, startBlockNumber, para.EndBlockNumber, para.Blocks)
+	if errors.Is(err, watcher.ErrChunkWhatIfTooManyBlocks) {
+		nerr := fmt.Errorf("chunk what-if parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+	if err != nil {
+		nerr := fmt.Errorf("estimate chunk what-if failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIChunkWhatIfFailure, nerr)
+		return
+	}
+
+	types.RenderSuccess(ctx, &rollupTypes.ChunkWhatIfSchema{
+		Current:  current,
+		Proposed: proposed,
+	})
+}
+
+// Preview returns the current open chunk of the proposer and the remaining budget of each chunk limit
+func (c *ChunkController) Preview(ctx *gin.Context) {
+	preview, err := c.chunkProposer.Preview(ctx)
+	if err != nil {
+		nerr := fmt.Errorf("preview open chunk failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIChunkPreviewFailure, nerr)
+		return
+	}
+
+	types.RenderSuccess(ctx, preview)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+// mockChunkEstimator records the what-if requests and answers them with the configured error.
+type mockChunkEstimator struct {
+	err error
+
+	startBlockNumber uint64
+	endBlockNumber   uint64
+	called           bool
+}
+
+func (m *mockChunkEstimator) WhatIf(ctx context.Context, startBlockNumber, endBlockNumber uint64, candidates []*types.WrappedBlock) (*watcher.ChunkEstimation, *watcher.ChunkEstimation, error) {
+	m.called = true
+	m.startBlockNumber, m.endBlockNumber = startBlockNumber, endBlockNumber
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+	return &watcher.ChunkEstimation{TotalTxNum: 1}, &watcher.ChunkEstimation{TotalTxNum: 2}, nil
+}
+
+func (m *mockChunkEstimator) Preview(ctx context.Context) (*watcher.ChunkPreview, error) {
+	return &watcher.ChunkPreview{}, m.err
+}
+
+func TestChunkControllerWhatIf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		body         string
+		err          error
+		expectCode   int
+		expectCalled bool
+		expectStart  uint64
+		expectEnd    uint64
+	}{
+		{
+			name:         "GenesisStartBlock",
+			body:         `{"start_block_number":0,"end_block_number":1}`,
+			expectCode:   types.Success,
+			expectCalled: true,
+			expectStart:  0,
+			expectEnd:    1,
+		},
+		{
+			name:         "BlockRange",
+			body:         `{"start_block_number":5,"end_block_number":7}`,
+			expectCode:   types.Success,
+			expectCalled: true,
+			expectStart:  5,
+			expectEnd:    7,
+		},
+		{
+			name:       "MissingStartBlock",
+			body:       `{"end_block_number":7}`,
+			expectCode: types.ErrRollupAPIParameterInvalidNo,
+		},
+		{
+			name:       "EndBeforeStart",
+			body:       `{"start_block_number":7,"end_block_number":5}`,
+			expectCode: types.ErrRollupAPIParameterInvalidNo,
+		},
+		{
+			name:         "TooManyBlocks",
+			body:         `{"start_block_number":5,"end_block_number":1000000}`,
+			err:          fmt.Errorf("%w, open blocks: 0, candidate blocks: 999996, max: 100", watcher.ErrChunkWhatIfTooManyBlocks),
+			expectCode:   types.ErrRollupAPIParameterInvalidNo,
+			expectCalled: true,
+			expectStart:  5,
+			expectEnd:    1000000,
+		},
+		{
+			name:         "EstimationFailure",
+			body:         `{"start_block_number":5,"end_block_number":7}`,
+			err:          errors.New("start block number 5 is already chunked"),
+			expectCode:   types.ErrRollupAPIChunkWhatIfFailure,
+			expectCalled: true,
+			expectStart:  5,
+			expectEnd:    7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimator := &mockChunkEstimator{err: tt.err}
+			router := gin.New()
+			router.POST("/chunk/what_if", NewChunkController(estimator).WhatIf)
+
+			req := httptest.NewRequest(http.MethodPost, "/chunk/what_if", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			var resp types.Response
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectCode, resp.ErrCode, resp.ErrMsg)
+			assert.Equal(t, tt.expectCalled, estimator.called)
+			if tt.expectCalled {
+				assert.Equal(t, tt.expectStart, estimator.startBlockNumber)
+				assert.Equal(t, tt.expectEnd, estimator.endBlockNumber)
+			}
+			if tt.expectCode == types.Success {
+				assert.NotNil(t, resp.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+const defaultCommitDiagnosisLimit = 20
+
+// CommitDiagnosisController the reverted commit tx diagnosis api controller
+type CommitDiagnosisController struct {
+	commitDiagnosisOrm *orm.CommitDiagnosis
+}
+
+// NewCommitDiagnosisController create a commit diagnosis api controller
+func NewCommitDiagnosisController(db *gorm.DB) *CommitDiagnosisController {
+	return &CommitDiagnosisController{
+		commitDiagnosisOrm: orm.NewCommitDiagnosis(db),
+	}
+}
+
+// List returns the latest diagnoses of the reverted commit txs, only the ones of a batch if the batch hash is given
+func (c *CommitDiagnosisController) List(ctx *gin.Context) {
+	var para rollupTypes.CommitDiagnosisParameter
+	if err := ctx.ShouldBindQuery(&para); err != nil {
+		nerr := fmt.Errorf("commit diagnosis parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+	if para.Limit <= 0 {
+		para.Limit = defaultCommitDiagnosisLimit
+	}
+
+	diagnoses, err := c.commitDiagnosisOrm.GetCommitDiagnoses(ctx, para.BatchHash, para.Limit)
+	if err != nil {
+		nerr := fmt.Errorf("get commit diagnoses failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPICommitDiagnosisFailure, nerr)
+		return
+	}
+
+	schemas := make([]*rollupTypes.CommitDiagnosisSchema, 0, len(diagnoses))
+	for _, d := range diagnoses {
+		schemas = append(schemas, &rollupTypes.CommitDiagnosisSchema{
+			BatchIndex:             d.BatchIndex,
+			BatchHash:              d.BatchHash,
+			CommitTxHash:           d.CommitTxHash,
+			RevertReason:           d.RevertReason,
+			LocalParentBatchHash:   d.LocalParentBatchHash,
+			OnchainParentBatchHash: d.OnchainParentBatchHash,
+			OnchainBatchHash:       d.OnchainBatchHash,
+			Cause:                  d.Cause,
+			Detail:                 d.Detail,
+			DiagnosedAt:            d.CreatedAt.Unix(),
+		})
+	}
+	types.RenderSuccess(ctx, schemas)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+var (
+	// Chunk the chunk api controller
+	Chunk *ChunkController
+	// EstimatorError the estimator error api controller
+	EstimatorError *EstimatorErrorController
+	// Batch the batch api controller
+	Batch *BatchController
+	// Pause the operation pause api controller
+	Pause *PauseController
+	// Proposer the chunk and batch proposer api controller
+	Proposer *ProposerController
+	// CommitDiagnosis the reverted commit tx diagnosis api controller
+	CommitDiagnosis *CommitDiagnosisController
+	// APIKey the self-service api key controller, nil if the api keys are not configured
+	APIKey *APIKeyController
+
+	initControllerOnce sync.Once
+)
+
+// InitController inits Controller with the running components
+func InitController(cfg *config.Config, chunkProposer *watcher.ChunkProposer, batchProposer *watcher.BatchProposer, db *gorm.DB) {
+	initControllerOnce.Do(func() {
+		Chunk = NewChunkController(chunkProposer)
+		EstimatorError = NewEstimatorErrorController(db)
+		Batch = NewBatchController(db)
+		Pause = NewPauseController(db)
+		Proposer = NewProposerController(chunkProposer, batchProposer)
+		CommitDiagnosis = NewCommitDiagnosisController(db)
+		if cfg.APIConfig != nil && cfg.APIConfig.APIKeyConfig != nil {
+			APIKey = NewAPIKeyController(cfg.APIConfig.APIKeyConfig, db)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+const defaultEstimatorErrorLimit = 20
+
+// EstimatorErrorController the estimator error api controller
+type EstimatorErrorController struct {
+	estimatorErrorOrm *orm.EstimatorError
+}
+
+// NewEstimatorErrorController create an estimator error api controller
+func NewEstimatorErrorController(db *gorm.DB) *EstimatorErrorController {
+	return &EstimatorErrorController{
+		estimatorErrorOrm: orm.NewEstimatorError(db),
+	}
+}
+
+// List returns the transaction types and targets with the largest commit gas estimation error
+func (c *EstimatorErrorController) List(ctx *gin.Context) {
+	var para rollupTypes.EstimatorErrorParameter
+	if err := ctx.ShouldBindQuery(&para); err != nil {
+		nerr := fmt.Errorf("estimator error parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+	if para.Limit <= 0 {
+		para.Limit = defaultEstimatorErrorLimit
+	}
+
+	estimatorErrors, err := c.estimatorErrorOrm.GetWorstEstimatorErrors(ctx, para.Limit)
+	if err != nil {
+		nerr := fmt.Errorf("get estimator errors failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIEstimatorErrorFailure, nerr)
+		return
+	}
+
+	schemas := make([]*rollupTypes.EstimatorErrorSchema, 0, len(estimatorErrors))
+	for _, e := range estimatorErrors {
+		schema := &rollupTypes.EstimatorErrorSchema{
+			TxType:           e.TxType,
+			Target:           e.Target,
+			SampleCount:      e.SampleCount,
+			EstimatedSizeSum: e.EstimatedSizeSum,
+			ActualSizeSum:    e.ActualSizeSum,
+			EstimatedGasSum:  e.EstimatedGasSum,
+			ActualGasSum:     e.ActualGasSum,
+		}
+		if e.ActualGasSum > 0 {
+			schema.GasErrorRatio = (float64(e.EstimatedGasSum) - float64(e.ActualGasSum)) / float64(e.ActualGasSum)
+		}
+		schemas = append(schemas, schema)
+	}
+	types.RenderSuccess(ctx, schemas)
+}
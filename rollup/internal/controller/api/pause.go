@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+// PauseController the operation pause api controller
+type PauseController struct {
+	operationPauseOrm *orm.OperationPause
+}
+
+// NewPauseController create an operation pause api controller
+func NewPauseController(db *gorm.DB) *PauseController {
+	return &PauseController{
+		operationPauseOrm: orm.NewOperationPause(db),
+	}
+}
+
+// List returns the pause switches of all the operations
+func (c *PauseController) List(ctx *gin.Context) {
+	operationPauses, err := c.operationPauseOrm.GetOperationPauses(ctx)
+	if err != nil {
+		nerr := fmt.Errorf("get operation pauses failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIOperationPauseFailure, nerr)
+		return
+	}
+
+	stored := make(map[string]*orm.OperationPause, len(operationPauses))
+	for _, operationPause := range operationPauses {
+		stored[operationPause.Operation] = operationPause
+	}
+
+	schemas := make([]*rollupTypes.OperationPauseSchema, 0, len(orm.Operations))
+	for _, operation := range orm.Operations {
+		schema := &rollupTypes.OperationPauseSchema{Operation: operation}
+		if operationPause, ok := stored[operation]; ok {
+			schema.Paused = operationPause.Paused
+			schema.Reason = operationPause.Reason
+			schema.UpdatedAt = operationPause.UpdatedAt.Unix()
+		}
+		schemas = append(schemas, schema)
+	}
+	types.RenderSuccess(ctx, schemas)
+}
+
+// Set pauses or resumes an operation, the running services apply the switch within a few seconds
+func (c *PauseController) Set(ctx *gin.Context) {
+	var para rollupTypes.OperationPauseParameter
+	if err := ctx.ShouldBindUri(&para); err != nil {
+		nerr := fmt.Errorf("operation pause parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	var req rollupTypes.OperationPauseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		nerr := fmt.Errorf("operation pause request invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	if !isPausableOperation(para.Operation) {
+		nerr := fmt.Errorf("unknown operation %v, expected one of %v", para.Operation, orm.Operations)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	if err := c.operationPauseOrm.SetOperationPause(ctx, para.Operation, req.Paused, req.Reason); err != nil {
+		nerr := fmt.Errorf("set operation pause failure, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIOperationPauseFailure, nerr)
+		return
+	}
+
+	types.RenderSuccess(ctx, &rollupTypes.OperationPauseSchema{
+		Operation: para.Operation,
+		Paused:    req.Paused,
+		Reason:    req.Reason,
+	})
+}
+
+func isPausableOperation(operation string) bool {
+	for _, o := range orm.Operations {
+		if o == operation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/controller/watcher"
+	rollupTypes "scroll-tech/rollup/internal/types"
+)
+
+// ProposerController the chunk and batch proposer api controller
+type ProposerController struct {
+	chunkProposer *watcher.ChunkProposer
+	batchProposer *watcher.BatchProposer
+}
+
+// NewProposerController create a proposer api controller
+func NewProposerController(chunkProposer *watcher.ChunkProposer, batchProposer *watcher.BatchProposer) *ProposerController {
+	return &ProposerController{
+		chunkProposer: chunkProposer,
+		batchProposer: batchProposer,
+	}
+}
+
+// Minimum returns the min number of blocks per chunk and of chunks per batch
+func (c *ProposerController) Minimum(ctx *gin.Context) {
+	types.RenderSuccess(ctx, c.minimum())
+}
+
+// SetMinimum changes the min number of blocks per chunk or of chunks per batch until the next restart
+func (c *ProposerController) SetMinimum(ctx *gin.Context) {
+	var para rollupTypes.ProposerMinimumParameter
+	if err := ctx.ShouldBindUri(&para); err != nil {
+		nerr := fmt.Errorf("proposer minimum parameter invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	var req watcher.ProposalMinimum
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		nerr := fmt.Errorf("proposer minimum request invalid, err:%w", err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	var err error
+	if para.Proposer == "chunk" {
+		err = c.chunkProposer.SetMinBlockNum(req)
+	} else {
+		err = c.batchProposer.SetMinChunkNum(req)
+	}
+	if err != nil {
+		nerr := fmt.Errorf("set %v proposer minimum failure, err:%w", para.Proposer, err)
+		types.RenderFailure(ctx, types.ErrRollupAPIParameterInvalidNo, nerr)
+		return
+	}
+
+	types.RenderSuccess(ctx, c.minimum())
+}
+
+func (c *ProposerController) minimum() *rollupTypes.ProposerMinimumSchema {
+	return &rollupTypes.ProposerMinimumSchema{
+		Chunk: c.chunkProposer.MinBlockNum(),
+		Batch: c.batchProposer.MinChunkNum(),
+	}
+}
@@ -0,0 +1,250 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// The most likely causes of a reverted commit transaction.
+const (
+	// CommitFailureBatchAlreadyCommitted the batch was committed by another transaction.
+	CommitFailureBatchAlreadyCommitted = "batch_already_committed"
+	// CommitFailureConflictingBatch another batch is committed at the index of the batch.
+	CommitFailureConflictingBatch = "conflicting_batch"
+	// CommitFailureParentBatchMismatch the parent batch committed on layer 1 is not the local parent batch.
+	CommitFailureParentBatchMismatch = "parent_batch_mismatch"
+	// CommitFailureNotSequencer the commit sender is not a sequencer of the rollup contract.
+	CommitFailureNotSequencer = "not_sequencer"
+	// CommitFailureContractPaused the rollup contract is paused.
+	CommitFailureContractPaused = "contract_paused"
+	// CommitFailureInvalidBatchData the contract rejected the encoded chunks or the batch header.
+	CommitFailureInvalidBatchData = "invalid_batch_data"
+	// CommitFailureOutOfGas the transaction used all its gas and the replay does not revert.
+	CommitFailureOutOfGas = "out_of_gas"
+	// CommitFailureReverted the transaction reverted for a reason without a known cause.
+	CommitFailureReverted = "reverted"
+	// CommitFailureUnknown the replay does not revert and the on-chain state does not explain the failure.
+	CommitFailureUnknown = "unknown"
+)
+
+// commitRevertCauses maps the revert reasons of ScrollChain.commitBatch to their cause.
+var commitRevertCauses = map[string]string{
+	"incorrect parent batch hash":    CommitFailureParentBatchMismatch,
+	"batch already committed":        CommitFailureBatchAlreadyCommitted,
+	"caller not sequencer":           CommitFailureNotSequencer,
+	"Pausable: paused":               CommitFailureContractPaused,
+	"invalid version":                CommitFailureInvalidBatchData,
+	"batch is empty":                 CommitFailureInvalidBatchData,
+	"num txs less than num L1 msgs":  CommitFailureInvalidBatchData,
+	"too many txs in one chunk":      CommitFailureInvalidBatchData,
+	"incomplete l2 transaction data": CommitFailureInvalidBatchData,
+	"cannot skip last L1 message":    CommitFailureInvalidBatchData,
+	"invalid chunk length":           CommitFailureInvalidBatchData,
+	"no block in chunk":              CommitFailureInvalidBatchData,
+	"batch header length too small":  CommitFailureInvalidBatchData,
+	"wrong bitmap length":            CommitFailureInvalidBatchData,
+}
+
+var (
+	revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector  = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// L1ChainReader is the subset of the layer 1 client used to replay and diagnose the reverted commit transactions.
+type L1ChainReader interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (*gethTypes.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*gethTypes.Receipt, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+type commitDiagnosis struct {
+	l1Reader           L1ChainReader
+	commitDiagnosisOrm *orm.CommitDiagnosis
+}
+
+// SetCommitDiagnosis enables the diagnosis of the reverted commit transactions, the diagnoses
+// are stored and served by the admin api.
+func (r *Layer2Relayer) SetCommitDiagnosis(l1Reader L1ChainReader) {
+	r.commitDiagnosis.Store(&commitDiagnosis{
+		l1Reader:           l1Reader,
+		commitDiagnosisOrm: orm.NewCommitDiagnosis(r.db),
+	})
+}
+
+// diagnoseCommitFailure replays the reverted commit transaction of the batch, compares the batches
+// committed on layer 1 with the local ones and stores the most likely cause of the failure.
+func (r *Layer2Relayer) diagnoseCommitFailure(d *commitDiagnosis, batchHash string, txHash common.Hash) {
+	batches, err := r.batchOrm.GetBatches(r.ctx, map[string]interface{}{"hash": batchHash}, nil, 1)
+	if err != nil || len(batches) == 0 {
+		log.Error("failed to get the batch of the reverted commit tx", "batch hash", batchHash, "tx hash", txHash, "err", err)
+		return
+	}
+	batch := batches[0]
+
+	diagnosis := &orm.CommitDiagnosis{
+		BatchIndex:   batch.Index,
+		BatchHash:    batch.Hash,
+		CommitTxHash: txHash.String(),
+	}
+	if batch.Index > 0 {
+		parentBatch, err := r.batchOrm.GetBatchByIndex(r.ctx, batch.Index-1)
+		if err != nil {
+			log.Error("failed to get the parent batch of the reverted commit tx", "index", batch.Index-1, "err", err)
+			return
+		}
+		diagnosis.LocalParentBatchHash = parentBatch.Hash
+
+		onchainParentBatchHash, err := r.committedBatchHash(d.l1Reader, batch.Index-1)
+		if err != nil {
+			log.Error("failed to get the parent batch committed on layer 1", "index", batch.Index-1, "err", err)
+			return
+		}
+		diagnosis.OnchainParentBatchHash = onchainParentBatchHash.String()
+	}
+	onchainBatchHash, err := r.committedBatchHash(d.l1Reader, batch.Index)
+	if err != nil {
+		log.Error("failed to get the batch committed on layer 1", "index", batch.Index, "err", err)
+		return
+	}
+	if onchainBatchHash != (common.Hash{}) {
+		diagnosis.OnchainBatchHash = onchainBatchHash.String()
+	}
+
+	outOfGas, err := r.replayCommitTx(d.l1Reader, txHash, diagnosis)
+	if err != nil {
+		log.Error("failed to replay the reverted commit tx", "tx hash", txHash, "err", err)
+		return
+	}
+
+	diagnosis.Cause, diagnosis.Detail = commitFailureCause(diagnosis, outOfGas)
+	r.metrics.rollupL2CommitFailureDiagnosisTotal.WithLabelValues(diagnosis.Cause).Inc()
+	log.Warn("diagnosed the reverted commit tx",
+		"index", diagnosis.BatchIndex,
+		"batch hash", diagnosis.BatchHash,
+		"tx hash", diagnosis.CommitTxHash,
+		"revert reason", diagnosis.RevertReason,
+		"cause", diagnosis.Cause,
+		"detail", diagnosis.Detail)
+
+	if err := d.commitDiagnosisOrm.InsertCommitDiagnosis(r.ctx, diagnosis); err != nil {
+		log.Error("failed to store the commit tx diagnosis", "tx hash", txHash, "err", err)
+	}
+}
+
+// committedBatchHash returns the batch hash committed at the index on layer 1, zero if there is none.
+func (r *Layer2Relayer) committedBatchHash(l1Caller ethereum.ContractCaller, index uint64) (common.Hash, error) {
+	calldata, err := r.l1RollupABI.Pack("committedBatches", new(big.Int).SetUint64(index))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	output, err := l1Caller.CallContract(r.ctx, ethereum.CallMsg{To: &r.cfg.RollupContractAddress, Data: calldata}, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(output), nil
+}
+
+// replayCommitTx calls the commit transaction again against the state of the parent block of its inclusion block
+// and records the decoded revert, outOfGas reports whether the transaction used all its gas.
+func (r *Layer2Relayer) replayCommitTx(l1Reader L1ChainReader, txHash common.Hash, diagnosis *orm.CommitDiagnosis) (outOfGas bool, err error) {
+	tx, _, err := l1Reader.TransactionByHash(r.ctx, txHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to get transaction, err: %w", err)
+	}
+	receipt, err := l1Reader.TransactionReceipt(r.ctx, txHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to get transaction receipt, err: %w", err)
+	}
+	from, err := gethTypes.Sender(gethTypes.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return false, fmt.Errorf("failed to recover transaction sender, err: %w", err)
+	}
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	blockNumber := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	if _, callErr := l1Reader.CallContract(r.ctx, msg, blockNumber); callErr != nil {
+		diagnosis.RevertReason = decodeRevert(callErr)
+	}
+	return receipt.GasUsed >= tx.Gas(), nil
+}
+
+// decodeRevert decodes the revert data of a failed call by its error selector, the error message
+// is returned if the node does not return the revert data.
+func decodeRevert(err error) string {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err.Error()
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil || len(data) < 4 {
+		return err.Error()
+	}
+
+	switch selector := data[:4]; {
+	case bytes.Equal(selector, revertSelector):
+		if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+			return reason
+		}
+	case bytes.Equal(selector, panicSelector):
+		return fmt.Sprintf("panic: 0x%x", data[4:])
+	default:
+		for name, contractErr := range bridgeAbi.ScrollChainABI.Errors {
+			if bytes.Equal(selector, contractErr.ID[:4]) {
+				return name
+			}
+		}
+	}
+	return fmt.Sprintf("unknown error selector 0x%x", data[:4])
+}
+
+// commitFailureCause returns the most likely cause of the failure, the batches committed on layer 1
+// explain the failure better than the revert reason, which is computed against the state before the block.
+func commitFailureCause(diagnosis *orm.CommitDiagnosis, outOfGas bool) (string, string) {
+	if diagnosis.OnchainBatchHash != "" {
+		if diagnosis.OnchainBatchHash == diagnosis.BatchHash {
+			return CommitFailureBatchAlreadyCommitted, "the batch is committed on layer 1 by another transaction"
+		}
+		return CommitFailureConflictingBatch, fmt.Sprintf("batch %v is committed on layer 1 at index %v", diagnosis.OnchainBatchHash, diagnosis.BatchIndex)
+	}
+	if diagnosis.BatchIndex > 0 && diagnosis.OnchainParentBatchHash != diagnosis.LocalParentBatchHash {
+		if diagnosis.OnchainParentBatchHash == (common.Hash{}).String() {
+			return CommitFailureParentBatchMismatch, "the parent batch is not committed on layer 1"
+		}
+		return CommitFailureParentBatchMismatch, fmt.Sprintf("the parent batch committed on layer 1 is %v, the local parent batch is %v", diagnosis.OnchainParentBatchHash, diagnosis.LocalParentBatchHash)
+	}
+	if cause, ok := commitRevertCauses[diagnosis.RevertReason]; ok {
+		return cause, fmt.Sprintf("the commit tx reverts with %q", diagnosis.RevertReason)
+	}
+	if diagnosis.RevertReason != "" {
+		return CommitFailureReverted, fmt.Sprintf("the commit tx reverts with %q", diagnosis.RevertReason)
+	}
+	if outOfGas {
+		return CommitFailureOutOfGas, "the commit tx used all its gas and its replay does not revert"
+	}
+	return CommitFailureUnknown, "the replay of the commit tx does not revert"
+}
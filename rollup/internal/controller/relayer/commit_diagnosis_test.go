@@ -0,0 +1,55 @@
+package relayer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// revertError is the error of a reverted call returned by the rpc client.
+type revertError struct {
+	data string
+}
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorData() interface{} { return e.data }
+
+func testCommitFailureDiagnosis(t *testing.T) {
+	// Error(string) with the reason "incorrect parent batch hash"
+	reason := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000001b" +
+		"696e636f727265637420706172656e7420626174636820686173680000000000"
+	assert.Equal(t, "incorrect parent batch hash", decodeRevert(&revertError{data: reason}))
+	assert.Equal(t, "panic: 0x11", decodeRevert(&revertError{data: hexutil.Encode(append(panicSelector, 0x11))}))
+	assert.Equal(t, "unknown error selector 0x01020304", decodeRevert(&revertError{data: "0x01020304"}))
+	assert.Equal(t, "out of gas", decodeRevert(errors.New("out of gas")))
+
+	zeroHash := common.Hash{}.String()
+	tests := []struct {
+		name      string
+		diagnosis *orm.CommitDiagnosis
+		outOfGas  bool
+		cause     string
+	}{
+		{"AlreadyCommitted", &orm.CommitDiagnosis{BatchIndex: 2, BatchHash: "0x2", OnchainBatchHash: "0x2", LocalParentBatchHash: "0x1", OnchainParentBatchHash: "0x1"}, false, CommitFailureBatchAlreadyCommitted},
+		{"ConflictingBatch", &orm.CommitDiagnosis{BatchIndex: 2, BatchHash: "0x2", OnchainBatchHash: "0x3", LocalParentBatchHash: "0x1", OnchainParentBatchHash: "0x1"}, false, CommitFailureConflictingBatch},
+		{"ParentNotCommitted", &orm.CommitDiagnosis{BatchIndex: 2, BatchHash: "0x2", LocalParentBatchHash: "0x1", OnchainParentBatchHash: zeroHash}, false, CommitFailureParentBatchMismatch},
+		{"RevertReason", &orm.CommitDiagnosis{BatchIndex: 2, BatchHash: "0x2", LocalParentBatchHash: "0x1", OnchainParentBatchHash: "0x1", RevertReason: "caller not sequencer"}, false, CommitFailureNotSequencer},
+		{"UnknownRevertReason", &orm.CommitDiagnosis{BatchIndex: 2, BatchHash: "0x2", LocalParentBatchHash: "0x1", OnchainParentBatchHash: "0x1", RevertReason: "panic: 0x11"}, false, CommitFailureReverted},
+		{"OutOfGas", &orm.CommitDiagnosis{BatchIndex: 2, BatchHash: "0x2", LocalParentBatchHash: "0x1", OnchainParentBatchHash: "0x1"}, true, CommitFailureOutOfGas},
+		{"Unknown", &orm.CommitDiagnosis{BatchIndex: 0, BatchHash: "0x0"}, false, CommitFailureUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cause, detail := commitFailureCause(tt.diagnosis, tt.outOfGas)
+			assert.Equal(t, tt.cause, cause)
+			assert.NotEmpty(t, detail)
+		})
+	}
+}
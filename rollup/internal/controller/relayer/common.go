@@ -1,13 +1,26 @@
 package relayer
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 const (
 	gasPriceDiffPrecision = 1000000
 
 	defaultGasPriceDiff = 50000 // 5%
+
+	// sentTxRecordTimeout bounds the db update recording a sent tx.
+	sentTxRecordTimeout = 10 * time.Second
 )
 
+// sentTxRecordContext returns the context of the db update recording a sent tx, derived from the relayer
+// context instead of the round context: a round cancelled after the send must not leave the tx unrecorded.
+func sentTxRecordContext(relayerCtx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(relayerCtx, sentTxRecordTimeout)
+}
+
 var (
 	// ErrExecutionRevertedMessageExpired error of Message expired
 	ErrExecutionRevertedMessageExpired = errors.New("execution reverted: Message expired")
@@ -0,0 +1,96 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/rollup/internal/controller/sender"
+)
+
+// feeSweepContextIDPrefix prefixes the context id of the fee sweep txs, they are sent by the commit
+// and finalize senders but confirm no batch.
+const feeSweepContextIDPrefix = "fee-sweep-"
+
+// feeSweep is a fee sweep tx not confirmed yet.
+type feeSweep struct {
+	contextID string
+	value     *big.Int
+}
+
+// ProcessFeeSweep sends the balance of the commit and finalize senders above the reserve balance to the
+// operator fee recipient, so the refunds and any other surplus don't pile up in the hot wallets. A sender
+// doesn't sweep again before its previous sweep is confirmed.
+func (r *Layer2Relayer) ProcessFeeSweep(ctx context.Context) {
+	feeCfg := r.cfg.FeeRecipientConfig
+	if feeCfg == nil {
+		return
+	}
+	for _, s := range []struct {
+		name   string
+		sender *sender.Sender
+	}{
+		{"commit_sender", r.commitSender},
+		{"finalize_sender", r.finalizeSender},
+	} {
+		if err := r.sweepFees(ctx, s.name, s.sender); err != nil {
+			r.metrics.rollupL2FeeSweepTotal.WithLabelValues(s.name, "failed").Inc()
+			log.Error("failed to sweep fees to the fee recipient", "sender", s.name, "recipient", feeCfg.Address, "err", err)
+		}
+	}
+}
+
+func (r *Layer2Relayer) sweepFees(ctx context.Context, name string, s *sender.Sender) error {
+	if pending, ok := r.feeSweeps.Load(name); ok {
+		log.Debug("fee sweep pending", "sender", name, "context id", pending.(*feeSweep).contextID)
+		return nil
+	}
+
+	feeCfg := r.cfg.FeeRecipientConfig
+	balance, err := s.GetBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get balance of %v: %w", s.GetAddress(), err)
+	}
+	value := new(big.Int).Sub(balance, feeCfg.ReserveBalance)
+	if value.Sign() <= 0 {
+		return nil
+	}
+
+	contextID := fmt.Sprintf("%s%s-%d", feeSweepContextIDPrefix, name, time.Now().Unix())
+	txHash, err := s.SendTransaction(ctx, contextID, &feeCfg.Address, value, nil, 0)
+	if err != nil {
+		return fmt.Errorf("failed to send fee sweep tx: %w", err)
+	}
+	r.feeSweeps.Store(name, &feeSweep{contextID: contextID, value: value})
+	r.metrics.rollupL2FeeSweepTotal.WithLabelValues(name, "sent").Inc()
+	log.Info("sent fee sweep tx", "sender", name, "from", s.GetAddress(), "recipient", feeCfg.Address, "value", value, "tx hash", txHash.String())
+	return nil
+}
+
+// handleFeeSweepConfirmation accounts the value of a confirmed fee sweep tx, the senders sweep again after it.
+func (r *Layer2Relayer) handleFeeSweepConfirmation(cfm *sender.Confirmation) {
+	name := strings.TrimPrefix(cfm.ContextID, feeSweepContextIDPrefix)
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		name = name[:i]
+	}
+	pending, ok := r.feeSweeps.Load(name)
+	if !ok || pending.(*feeSweep).contextID != cfm.ContextID {
+		log.Warn("confirmed unknown fee sweep tx", "confirmation", cfm)
+		return
+	}
+	r.feeSweeps.Delete(name)
+
+	if !cfm.IsSuccessful {
+		r.metrics.rollupL2FeeSweepTotal.WithLabelValues(name, "confirmed_failed").Inc()
+		log.Warn("fee sweep transaction confirmed but failed in layer1", "confirmation", cfm)
+		return
+	}
+	value, _ := new(big.Float).SetInt(pending.(*feeSweep).value).Float64()
+	r.metrics.rollupL2FeeSweepTotal.WithLabelValues(name, "confirmed").Inc()
+	r.metrics.rollupL2FeeSweptWeiTotal.WithLabelValues(name).Add(value)
+	log.Info("fee sweep transaction confirmed", "sender", name, "value", pending.(*feeSweep).value, "tx hash", cfm.TxHash.String())
+}
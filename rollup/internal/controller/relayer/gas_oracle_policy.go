@@ -0,0 +1,162 @@
+package relayer
+
+import (
+	"fmt"
+	"sort"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// gasOraclePolicy decides the fees the gas oracle pushes to the layer 2 gas price oracle: the median l1 fees of the
+// latest blocks are smoothed by exponential moving averages, clamped around the last pushed ones and pushed when
+// they differ enough from them.
+type gasOraclePolicy struct {
+	minGasPrice  uint64
+	gasPriceDiff uint64
+
+	enableBlobBaseFee bool
+	minBlobGasPrice   uint64
+	blobGasPriceDiff  uint64
+
+	l1BaseFeeSmoothingFactor   float64
+	blobBaseFeeSmoothingFactor float64
+
+	medianWindow     int
+	maxGasPriceDelta uint64
+
+	// the fees of the latest l1 blocks, at most medianWindow of them
+	baseFeeSamples     []uint64
+	blobBaseFeeSamples []uint64
+
+	// the last pushed fees, 0 before the first update
+	lastGasPrice     uint64
+	lastBlobGasPrice uint64
+
+	// exponential moving averages of the fees, keyed by the last l1 block folded in.
+	smoothedBlockNumber uint64
+	smoothedBaseFee     float64
+	smoothedBlobBaseFee float64
+}
+
+// newGasOraclePolicy creates the policy of the gas oracle config, the default one if cfg is nil.
+func newGasOraclePolicy(cfg *config.GasOracleConfig) (*gasOraclePolicy, error) {
+	if cfg == nil {
+		return &gasOraclePolicy{gasPriceDiff: defaultGasPriceDiff, blobGasPriceDiff: defaultGasPriceDiff}, nil
+	}
+
+	if cfg.L1BaseFeeSmoothingFactor < 0 || cfg.L1BaseFeeSmoothingFactor > 1 {
+		return nil, fmt.Errorf("invalid l1 base fee smoothing factor %v, expected in [0, 1]", cfg.L1BaseFeeSmoothingFactor)
+	}
+	if cfg.BlobBaseFeeSmoothingFactor < 0 || cfg.BlobBaseFeeSmoothingFactor > 1 {
+		return nil, fmt.Errorf("invalid blob base fee smoothing factor %v, expected in [0, 1]", cfg.BlobBaseFeeSmoothingFactor)
+	}
+	if cfg.L1FeeMedianWindow < 0 {
+		return nil, fmt.Errorf("invalid l1 fee median window %v, expected non-negative", cfg.L1FeeMedianWindow)
+	}
+
+	blobGasPriceDiff := uint64(defaultGasPriceDiff)
+	if cfg.BlobGasPriceDiff > 0 {
+		blobGasPriceDiff = cfg.BlobGasPriceDiff
+	}
+	return &gasOraclePolicy{
+		minGasPrice:  cfg.MinGasPrice,
+		gasPriceDiff: cfg.GasPriceDiff,
+
+		enableBlobBaseFee: cfg.EnableBlobBaseFee,
+		minBlobGasPrice:   cfg.MinBlobGasPrice,
+		blobGasPriceDiff:  blobGasPriceDiff,
+
+		l1BaseFeeSmoothingFactor:   cfg.L1BaseFeeSmoothingFactor,
+		blobBaseFeeSmoothingFactor: cfg.BlobBaseFeeSmoothingFactor,
+
+		medianWindow:     cfg.L1FeeMedianWindow,
+		maxGasPriceDelta: cfg.MaxGasPriceDelta,
+	}, nil
+}
+
+// observe folds the fees of the l1 block into the moving averages, once per block, and returns the smoothed
+// and clamped fees and whether they should be pushed.
+func (p *gasOraclePolicy) observe(blockNumber, baseFee, blobBaseFee uint64) (uint64, uint64, bool) {
+	if blockNumber != p.smoothedBlockNumber || p.smoothedBaseFee == 0 {
+		p.baseFeeSamples = appendFeeSample(p.baseFeeSamples, baseFee, p.medianWindow)
+		p.blobBaseFeeSamples = appendFeeSample(p.blobBaseFeeSamples, blobBaseFee, p.medianWindow)
+		p.smoothedBaseFee = smoothFee(p.smoothedBaseFee, medianFee(p.baseFeeSamples), p.l1BaseFeeSmoothingFactor)
+		p.smoothedBlobBaseFee = smoothFee(p.smoothedBlobBaseFee, medianFee(p.blobBaseFeeSamples), p.blobBaseFeeSmoothingFactor)
+		p.smoothedBlockNumber = blockNumber
+	}
+	l1BaseFee := clampFee(p.lastGasPrice, uint64(p.smoothedBaseFee), p.maxGasPriceDelta)
+	smoothedBlobBaseFee := clampFee(p.lastBlobGasPrice, uint64(p.smoothedBlobBaseFee), p.maxGasPriceDelta)
+
+	shouldUpdate := exceedGasPriceDiff(p.lastGasPrice, l1BaseFee, p.minGasPrice, p.gasPriceDiff)
+	if p.enableBlobBaseFee {
+		shouldUpdate = shouldUpdate || exceedGasPriceDiff(p.lastBlobGasPrice, smoothedBlobBaseFee, p.minBlobGasPrice, p.blobGasPriceDiff)
+	}
+	return l1BaseFee, smoothedBlobBaseFee, shouldUpdate
+}
+
+// pushed records the fees pushed to the layer 2 gas price oracle.
+func (p *gasOraclePolicy) pushed(l1BaseFee, blobBaseFee uint64) {
+	p.lastGasPrice = l1BaseFee
+	if p.enableBlobBaseFee {
+		p.lastBlobGasPrice = blobBaseFee
+	}
+}
+
+// appendFeeSample appends the fee to the samples and keeps the latest window ones, at least the latest one.
+func appendFeeSample(samples []uint64, fee uint64, window int) []uint64 {
+	samples = append(samples, fee)
+	if window < 1 {
+		window = 1
+	}
+	if len(samples) > window {
+		samples = samples[len(samples)-window:]
+	}
+	return samples
+}
+
+// medianFee returns the median of the fee samples, the lower one of the two middle samples of an even number.
+func medianFee(samples []uint64) uint64 {
+	sorted := append([]uint64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)-1)/2]
+}
+
+// clampFee limits the move of the fee from the last pushed one to maxDelta of it, with the precision of the
+// gas price diff, a maxDelta of 0 or an undefined last fee doesn't limit it.
+func clampFee(last, fee, maxDelta uint64) uint64 {
+	if last == 0 || maxDelta == 0 {
+		return fee
+	}
+	delta := last * maxDelta / gasPriceDiffPrecision
+	if delta == 0 {
+		delta = 1
+	}
+	if fee > last+delta {
+		return last + delta
+	}
+	if last > delta && fee < last-delta {
+		return last - delta
+	}
+	return fee
+}
+
+// smoothFee folds a new fee sample into an exponential moving average,
+// a factor of 0 (or 1) disables smoothing and returns the sample itself.
+func smoothFee(prev float64, sample uint64, factor float64) float64 {
+	if factor <= 0 || factor >= 1 || prev == 0 {
+		return float64(sample)
+	}
+	return factor*float64(sample) + (1-factor)*prev
+}
+
+// exceedGasPriceDiff returns true if the last price is undefined or (current >= min && exceed diff).
+func exceedGasPriceDiff(last, current, min, diff uint64) bool {
+	if last == 0 {
+		return true
+	}
+	expectedDelta := last * diff / gasPriceDiffPrecision
+	if expectedDelta == 0 {
+		expectedDelta = 1
+	}
+	return current >= min && (current >= last+expectedDelta || current <= last-expectedDelta)
+}
@@ -0,0 +1,104 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// GasOracleUpdate is a fee update the gas oracle pushes to the layer 2 gas price oracle.
+type GasOracleUpdate struct {
+	L1BlockNumber uint64 `json:"l1_block_number"`
+	L1BaseFee     uint64 `json:"l1_base_fee"`
+	BlobBaseFee   uint64 `json:"blob_base_fee,omitempty"`
+}
+
+// GasOracleFeeError is the error of the fee the users are charged by, the last pushed one, against the actual
+// fee of the l1 blocks, relative to the actual fee.
+type GasOracleFeeError struct {
+	MeanAbsoluteError float64 `json:"mean_absolute_error"`
+	// MaxUndercharge and MaxOvercharge are the largest relative errors below and above the actual fee.
+	MaxUndercharge float64 `json:"max_undercharge"`
+	MaxOvercharge  float64 `json:"max_overcharge"`
+	// UnderchargedBlocks is the number of l1 blocks the users are charged below the actual fee.
+	UnderchargedBlocks uint64 `json:"undercharged_blocks"`
+
+	sum    float64
+	blocks uint64
+}
+
+func (e *GasOracleFeeError) add(charged, actual uint64) {
+	if actual == 0 {
+		return
+	}
+	relative := (float64(charged) - float64(actual)) / float64(actual)
+	if relative < 0 {
+		e.UnderchargedBlocks++
+		e.MaxUndercharge = math.Max(e.MaxUndercharge, -relative)
+	} else {
+		e.MaxOvercharge = math.Max(e.MaxOvercharge, relative)
+	}
+	e.sum += math.Abs(relative)
+	e.blocks++
+	e.MeanAbsoluteError = e.sum / float64(e.blocks)
+}
+
+// GasOracleSimulation is the report of replaying l1 blocks through the gas oracle.
+type GasOracleSimulation struct {
+	FromBlock uint64             `json:"from_block"`
+	ToBlock   uint64             `json:"to_block"`
+	Blocks    uint64             `json:"blocks"`
+	Updates   []*GasOracleUpdate `json:"updates"`
+
+	L1BaseFeeError   GasOracleFeeError  `json:"l1_base_fee_error"`
+	BlobBaseFeeError *GasOracleFeeError `json:"blob_base_fee_error,omitempty"`
+}
+
+// SimulateGasOracle replays the l1 blocks, in ascending order, through the smoothing and the update policy of the
+// gas oracle config and reports the updates it would have pushed and the resulting fee error, so the parameters are
+// tuned against the historical fees before they are deployed.
+//
+// Every block is observed once and an update takes effect right away, the delay of the oracle transactions is
+// left out. The blocks before the first update are charged nothing and not counted in the fee error.
+func SimulateGasOracle(cfg *config.GasOracleConfig, blocks []orm.L1Block) (*GasOracleSimulation, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("no l1 blocks to simulate")
+	}
+	policy, err := newGasOraclePolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GasOracleSimulation{FromBlock: blocks[0].Number, ToBlock: blocks[len(blocks)-1].Number}
+	if policy.enableBlobBaseFee {
+		report.BlobBaseFeeError = &GasOracleFeeError{}
+	}
+	for i, block := range blocks {
+		if i > 0 && block.Number <= blocks[i-1].Number {
+			return nil, fmt.Errorf("l1 blocks not in ascending order, block %v after block %v", block.Number, blocks[i-1].Number)
+		}
+		report.Blocks++
+
+		l1BaseFee, blobBaseFee, shouldUpdate := policy.observe(block.Number, block.BaseFee, block.BlobBaseFee)
+		if shouldUpdate {
+			policy.pushed(l1BaseFee, blobBaseFee)
+			update := &GasOracleUpdate{L1BlockNumber: block.Number, L1BaseFee: l1BaseFee}
+			if policy.enableBlobBaseFee {
+				update.BlobBaseFee = blobBaseFee
+			}
+			report.Updates = append(report.Updates, update)
+		}
+
+		if len(report.Updates) == 0 {
+			continue
+		}
+		report.L1BaseFeeError.add(policy.lastGasPrice, block.BaseFee)
+		if policy.enableBlobBaseFee {
+			report.BlobBaseFeeError.add(policy.lastBlobGasPrice, block.BlobBaseFee)
+		}
+	}
+	return report, nil
+}
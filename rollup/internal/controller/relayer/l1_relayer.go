@@ -11,6 +11,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/observability"
 	"scroll-tech/common/types"
 
 	bridgeAbi "scroll-tech/rollup/abi"
@@ -33,9 +34,10 @@ type Layer1Relayer struct {
 	gasOracleSender *sender.Sender
 	l1GasOracleABI  *abi.ABI
 
-	lastGasPrice uint64
-	minGasPrice  uint64
-	gasPriceDiff uint64
+	// decides the fees pushed to the layer 2 gas price oracle
+	policy *gasOraclePolicy
+
+	simulateBeforeSend bool
 
 	l1BlockOrm *orm.L1Block
 	metrics    *l1RelayerMetrics
@@ -62,14 +64,9 @@ func NewLayer1Relayer(ctx context.Context, db *gorm.DB, cfg *config.RelayerConfi
 		return nil, fmt.Errorf("invalid service type for l1_relayer: %v", serviceType)
 	}
 
-	var minGasPrice uint64
-	var gasPriceDiff uint64
-	if cfg.GasOracleConfig != nil {
-		minGasPrice = cfg.GasOracleConfig.MinGasPrice
-		gasPriceDiff = cfg.GasOracleConfig.GasPriceDiff
-	} else {
-		minGasPrice = 0
-		gasPriceDiff = defaultGasPriceDiff
+	policy, err := newGasOraclePolicy(cfg.GasOracleConfig)
+	if err != nil {
+		return nil, err
 	}
 
 	l1Relayer := &Layer1Relayer{
@@ -80,8 +77,9 @@ func NewLayer1Relayer(ctx context.Context, db *gorm.DB, cfg *config.RelayerConfi
 		gasOracleSender: gasOracleSender,
 		l1GasOracleABI:  bridgeAbi.L1GasPriceOracleABI,
 
-		minGasPrice:  minGasPrice,
-		gasPriceDiff: gasPriceDiff,
+		policy: policy,
+
+		simulateBeforeSend: cfg.GasOracleConfig != nil && cfg.GasOracleConfig.SimulateBeforeSend,
 	}
 
 	l1Relayer.metrics = initL1RelayerMetrics(reg)
@@ -96,16 +94,44 @@ func NewLayer1Relayer(ctx context.Context, db *gorm.DB, cfg *config.RelayerConfi
 	return l1Relayer, nil
 }
 
+// DebugState returns the state of the l1 relayer for the debug server, the block is the latest
+// stored l1 block whose fees the gas oracle pushes.
+func (r *Layer1Relayer) DebugState() interface{} {
+	ctx, cancel := context.WithTimeout(r.ctx, observability.StateDumpTimeout)
+	defer cancel()
+
+	state := map[string]interface{}{"simulate_before_send": r.simulateBeforeSend}
+	height, err := r.l1BlockOrm.GetLatestL1BlockHeight(ctx)
+	if err != nil {
+		state["latest_block_error"] = err.Error()
+		return state
+	}
+	blocks, err := r.l1BlockOrm.GetL1Blocks(ctx, map[string]interface{}{"number": height})
+	if err != nil {
+		state["latest_block_error"] = err.Error()
+		return state
+	}
+	if len(blocks) == 1 {
+		state["latest_block"] = map[string]interface{}{
+			"number":        blocks[0].Number,
+			"base_fee":      blocks[0].BaseFee,
+			"blob_base_fee": blocks[0].BlobBaseFee,
+			"oracle_status": types.GasOracleStatus(blocks[0].GasOracleStatus).String(),
+		}
+	}
+	return state
+}
+
 // ProcessGasPriceOracle imports gas price to layer2
-func (r *Layer1Relayer) ProcessGasPriceOracle() {
+func (r *Layer1Relayer) ProcessGasPriceOracle(ctx context.Context) {
 	r.metrics.rollupL1RelayerGasPriceOraclerRunTotal.Inc()
-	latestBlockHeight, err := r.l1BlockOrm.GetLatestL1BlockHeight(r.ctx)
+	latestBlockHeight, err := r.l1BlockOrm.GetLatestL1BlockHeight(ctx)
 	if err != nil {
 		log.Warn("Failed to fetch latest L1 block height from db", "err", err)
 		return
 	}
 
-	blocks, err := r.l1BlockOrm.GetL1Blocks(r.ctx, map[string]interface{}{
+	blocks, err := r.l1BlockOrm.GetL1Blocks(ctx, map[string]interface{}{
 		"number": latestBlockHeight,
 	})
 	if err != nil {
@@ -118,36 +144,54 @@ func (r *Layer1Relayer) ProcessGasPriceOracle() {
 	}
 	block := blocks[0]
 
-	if types.GasOracleStatus(block.GasOracleStatus) == types.GasOraclePending {
-		expectedDelta := r.lastGasPrice * r.gasPriceDiff / gasPriceDiffPrecision
-		if r.lastGasPrice > 0 && expectedDelta == 0 {
-			expectedDelta = 1
-		}
-		// last is undefine or (block.BaseFee >= minGasPrice && exceed diff)
-		if r.lastGasPrice == 0 || (block.BaseFee >= r.minGasPrice && (block.BaseFee >= r.lastGasPrice+expectedDelta || block.BaseFee <= r.lastGasPrice-expectedDelta)) {
-			baseFee := big.NewInt(int64(block.BaseFee))
-			data, err := r.l1GasOracleABI.Pack("setL1BaseFee", baseFee)
-			if err != nil {
-				log.Error("Failed to pack setL1BaseFee", "block.Hash", block.Hash, "block.Height", block.Number, "block.BaseFee", block.BaseFee, "err", err)
-				return
-			}
-
-			hash, err := r.gasOracleSender.SendTransaction(block.Hash, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
-			if err != nil {
-				log.Error("Failed to send setL1BaseFee tx to layer2 ", "block.Hash", block.Hash, "block.Height", block.Number, "err", err)
-				return
-			}
-
-			err = r.l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(r.ctx, block.Hash, types.GasOracleImporting, hash.String())
-			if err != nil {
-				log.Error("UpdateGasOracleStatusAndOracleTxHash failed", "block.Hash", block.Hash, "block.Height", block.Number, "err", err)
-				return
-			}
-			r.lastGasPrice = block.BaseFee
-			r.metrics.rollupL1RelayerLastGasPrice.Set(float64(r.lastGasPrice))
-			log.Info("Update l1 base fee", "txHash", hash.String(), "baseFee", baseFee)
+	if types.GasOracleStatus(block.GasOracleStatus) != types.GasOraclePending {
+		return
+	}
+
+	l1BaseFee, blobBaseFee, shouldUpdate := r.policy.observe(block.Number, block.BaseFee, block.BlobBaseFee)
+	if !shouldUpdate {
+		return
+	}
+
+	baseFee := new(big.Int).SetUint64(l1BaseFee)
+	var data []byte
+	if r.policy.enableBlobBaseFee {
+		data, err = r.l1GasOracleABI.Pack("setL1BaseFeeAndBlobBaseFee", baseFee, new(big.Int).SetUint64(blobBaseFee))
+	} else {
+		data, err = r.l1GasOracleABI.Pack("setL1BaseFee", baseFee)
+	}
+	if err != nil {
+		log.Error("Failed to pack gas oracle update", "block.Hash", block.Hash, "block.Height", block.Number, "baseFee", l1BaseFee, "blobBaseFee", blobBaseFee, "err", err)
+		return
+	}
+
+	if r.simulateBeforeSend {
+		if err = r.gasOracleSender.SimulateTransaction(ctx, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data); err != nil {
+			r.metrics.rollupL1RelayerGasOracleSimulationFailureTotal.Inc()
+			log.Error("Gas oracle update would revert in layer2, skip sending", "block.Hash", block.Hash, "block.Height", block.Number, "err", err)
+			return
 		}
 	}
+
+	hash, err := r.gasOracleSender.SendTransaction(ctx, block.Hash, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
+	if err != nil {
+		log.Error("Failed to send gas oracle update tx to layer2 ", "block.Hash", block.Hash, "block.Height", block.Number, "err", err)
+		return
+	}
+
+	recordCtx, cancel := sentTxRecordContext(r.ctx)
+	defer cancel()
+	err = r.l1BlockOrm.UpdateL1GasOracleStatusAndOracleTxHash(recordCtx, block.Hash, types.GasOracleImporting, hash.String())
+	if err != nil {
+		log.Error("UpdateGasOracleStatusAndOracleTxHash failed", "block.Hash", block.Hash, "block.Height", block.Number, "err", err)
+		return
+	}
+	r.policy.pushed(l1BaseFee, blobBaseFee)
+	r.metrics.rollupL1RelayerLastGasPrice.Set(float64(l1BaseFee))
+	if r.policy.enableBlobBaseFee {
+		r.metrics.rollupL1RelayerLastBlobGasPrice.Set(float64(blobBaseFee))
+	}
+	log.Info("Update l1 base fee", "txHash", hash.String(), "baseFee", l1BaseFee, "blobBaseFee", blobBaseFee)
 }
 
 func (r *Layer1Relayer) handleConfirmation(cfm *sender.Confirmation) {
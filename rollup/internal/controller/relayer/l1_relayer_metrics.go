@@ -8,10 +8,12 @@ import (
 )
 
 type l1RelayerMetrics struct {
-	rollupL1RelayerGasPriceOraclerRunTotal      prometheus.Counter
-	rollupL1RelayerLastGasPrice                 prometheus.Gauge
-	rollupL1UpdateGasOracleConfirmedTotal       prometheus.Counter
-	rollupL1UpdateGasOracleConfirmedFailedTotal prometheus.Counter
+	rollupL1RelayerGasPriceOraclerRunTotal         prometheus.Counter
+	rollupL1RelayerLastGasPrice                    prometheus.Gauge
+	rollupL1RelayerLastBlobGasPrice                prometheus.Gauge
+	rollupL1RelayerGasOracleSimulationFailureTotal prometheus.Counter
+	rollupL1UpdateGasOracleConfirmedTotal          prometheus.Counter
+	rollupL1UpdateGasOracleConfirmedFailedTotal    prometheus.Counter
 }
 
 var (
@@ -30,6 +32,14 @@ func initL1RelayerMetrics(reg prometheus.Registerer) *l1RelayerMetrics {
 				Name: "rollup_layer1_gas_price_latest_gas_price",
 				Help: "The latest gas price of rollup relayer l1",
 			}),
+			rollupL1RelayerLastBlobGasPrice: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_layer1_gas_price_latest_blob_gas_price",
+				Help: "The latest blob gas price of rollup relayer l1",
+			}),
+			rollupL1RelayerGasOracleSimulationFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer1_gas_oracle_simulation_failure_total",
+				Help: "The total number of layer1 gas oracle updates skipped because the simulation failed",
+			}),
 			rollupL1UpdateGasOracleConfirmedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 				Name: "rollup_layer1_update_gas_oracle_confirmed_total",
 				Help: "The total number of updating layer1 gas oracle confirmed",
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +19,7 @@ import (
 
 	"scroll-tech/database/migrate"
 
+	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/sender"
 	"scroll-tech/rollup/internal/orm"
 )
@@ -99,7 +101,7 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 			return 0, targetErr
 		})
 		defer patchGuard.Reset()
-		l1Relayer.ProcessGasPriceOracle()
+		l1Relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard := gomonkey.ApplyMethodFunc(l1BlockOrm, "GetLatestL1BlockHeight", func(ctx context.Context) (uint64, error) {
@@ -112,7 +114,7 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1Blocks", func(ctx context.Context, fields map[string]interface{}) ([]orm.L1Block, error) {
 			return nil, targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		l1Relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	convey.Convey("Block not exist", t, func() {
@@ -123,7 +125,7 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 			}
 			return tmpInfo, nil
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		l1Relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1Blocks", func(ctx context.Context, fields map[string]interface{}) ([]orm.L1Block, error) {
@@ -142,7 +144,7 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(l1Relayer.l1GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
 			return nil, targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		l1Relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard.ApplyMethodFunc(l1Relayer.l1GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
@@ -151,13 +153,13 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 
 	convey.Convey("send transaction failure", t, func() {
 		targetErr := errors.New("send transaction failure")
-		patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SendTransaction", func(string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
+		patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SendTransaction", func(context.Context, string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
 			return common.Hash{}, targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		l1Relayer.ProcessGasPriceOracle(context.Background())
 	})
 
-	patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SendTransaction", func(string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
+	patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SendTransaction", func(context.Context, string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
 		return common.Hash{}, nil
 	})
 
@@ -166,12 +168,165 @@ func testL1RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(context.Context, string, types.GasOracleStatus, string) error {
 			return targetErr
 		})
-		l1Relayer.ProcessGasPriceOracle()
+		l1Relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard.ApplyMethodFunc(l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(context.Context, string, types.GasOracleStatus, string) error {
 		return nil
 	})
 
-	l1Relayer.ProcessGasPriceOracle()
+	l1Relayer.ProcessGasPriceOracle(context.Background())
+
+	l1Relayer.policy.enableBlobBaseFee = true
+	l1Relayer.simulateBeforeSend = true
+
+	// a new l1 block whose fees must be pushed
+	patchGuard.ApplyMethodFunc(l1BlockOrm, "GetL1Blocks", func(ctx context.Context, fields map[string]interface{}) ([]orm.L1Block, error) {
+		tmpInfo := []orm.L1Block{
+			{
+				Hash:            "gas-oracle-3",
+				Number:          1,
+				BaseFee:         1000000000,
+				BlobBaseFee:     2000000000,
+				GasOracleStatus: int16(types.GasOraclePending),
+			},
+		}
+		return tmpInfo, nil
+	})
+	var sendCalls, updateStatusCalls, simulateCalls int
+	patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SendTransaction", func(context.Context, string, *common.Address, *big.Int, []byte, uint64) (hash common.Hash, err error) {
+		sendCalls++
+		return common.Hash{}, nil
+	})
+	patchGuard.ApplyMethodFunc(l1BlockOrm, "UpdateL1GasOracleStatusAndOracleTxHash", func(context.Context, string, types.GasOracleStatus, string) error {
+		updateStatusCalls++
+		return nil
+	})
+
+	convey.Convey("simulate transaction failure", t, func() {
+		targetErr := errors.New("execution reverted")
+		patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SimulateTransaction", func(context.Context, *common.Address, *big.Int, []byte) error {
+			simulateCalls++
+			return targetErr
+		})
+		lastGasPrice, lastBlobGasPrice := l1Relayer.policy.lastGasPrice, l1Relayer.policy.lastBlobGasPrice
+		lastGasPriceMetric := testutil.ToFloat64(l1Relayer.metrics.rollupL1RelayerLastGasPrice)
+		simulationFailures := testutil.ToFloat64(l1Relayer.metrics.rollupL1RelayerGasOracleSimulationFailureTotal)
+
+		l1Relayer.ProcessGasPriceOracle(context.Background())
+
+		// the update is neither sent nor recorded, the block stays pending and the pushed prices are unchanged
+		assert.Equal(t, 1, simulateCalls)
+		assert.Equal(t, 0, sendCalls)
+		assert.Equal(t, 0, updateStatusCalls)
+		assert.Equal(t, lastGasPrice, l1Relayer.policy.lastGasPrice)
+		assert.Equal(t, lastBlobGasPrice, l1Relayer.policy.lastBlobGasPrice)
+		assert.Equal(t, lastGasPriceMetric, testutil.ToFloat64(l1Relayer.metrics.rollupL1RelayerLastGasPrice))
+		assert.Equal(t, simulationFailures+1, testutil.ToFloat64(l1Relayer.metrics.rollupL1RelayerGasOracleSimulationFailureTotal))
+	})
+
+	patchGuard.ApplyMethodFunc(l1Relayer.gasOracleSender, "SimulateTransaction", func(context.Context, *common.Address, *big.Int, []byte) error {
+		simulateCalls++
+		return nil
+	})
+
+	// the same block is pushed once the simulation succeeds
+	l1Relayer.ProcessGasPriceOracle(context.Background())
+	assert.Equal(t, 2, simulateCalls)
+	assert.Equal(t, 1, sendCalls)
+	assert.Equal(t, 1, updateStatusCalls)
+	assert.Equal(t, uint64(1000000000), l1Relayer.policy.lastGasPrice)
+	assert.Equal(t, uint64(2000000000), l1Relayer.policy.lastBlobGasPrice)
+}
+
+func testSimulateGasOracle(t *testing.T) {
+	_, err := SimulateGasOracle(nil, nil)
+	assert.Error(t, err)
+
+	_, err = SimulateGasOracle(&config.GasOracleConfig{L1BaseFeeSmoothingFactor: 2}, []orm.L1Block{{Number: 1, BaseFee: 100}})
+	assert.Error(t, err)
+
+	_, err = SimulateGasOracle(nil, []orm.L1Block{{Number: 2, BaseFee: 100}, {Number: 1, BaseFee: 100}})
+	assert.Error(t, err)
+
+	blocks := []orm.L1Block{
+		{Number: 1, BaseFee: 100, BlobBaseFee: 10},
+		{Number: 2, BaseFee: 105, BlobBaseFee: 10},
+		{Number: 3, BaseFee: 150, BlobBaseFee: 10},
+		{Number: 4, BaseFee: 120, BlobBaseFee: 20},
+	}
+
+	// the l1 base fee is pushed when it moves by 10% of the last pushed one
+	report, err := SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000}, blocks)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), report.FromBlock)
+	assert.Equal(t, uint64(4), report.ToBlock)
+	assert.Equal(t, uint64(4), report.Blocks)
+	assert.Equal(t, []*GasOracleUpdate{{L1BlockNumber: 1, L1BaseFee: 100}, {L1BlockNumber: 3, L1BaseFee: 150}, {L1BlockNumber: 4, L1BaseFee: 120}}, report.Updates)
+	assert.InDelta(t, 0.05/1.05/4, report.L1BaseFeeError.MeanAbsoluteError, 1e-9)
+	assert.InDelta(t, 0.05/1.05, report.L1BaseFeeError.MaxUndercharge, 1e-9)
+	assert.Equal(t, uint64(1), report.L1BaseFeeError.UnderchargedBlocks)
+	assert.Nil(t, report.BlobBaseFeeError)
+
+	// the smoothed fee lags behind the spike and undercharges the blocks after it
+	report, err = SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000, L1BaseFeeSmoothingFactor: 0.5}, blocks)
+	assert.NoError(t, err)
+	assert.Equal(t, []*GasOracleUpdate{{L1BlockNumber: 1, L1BaseFee: 100}, {L1BlockNumber: 3, L1BaseFee: 126}}, report.Updates)
+	assert.Equal(t, uint64(2), report.L1BaseFeeError.UnderchargedBlocks)
+	assert.InDelta(t, 0.16, report.L1BaseFeeError.MaxUndercharge, 1e-9)
+
+	report, err = SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000, EnableBlobBaseFee: true}, blocks)
+	assert.NoError(t, err)
+	assert.Len(t, report.Updates, 3)
+	assert.Equal(t, uint64(20), report.Updates[2].BlobBaseFee)
+	assert.NotNil(t, report.BlobBaseFeeError)
+	assert.Zero(t, report.BlobBaseFeeError.MeanAbsoluteError)
+}
+
+func testSimulateGasOracleSpikeProtection(t *testing.T) {
+	_, err := SimulateGasOracle(&config.GasOracleConfig{L1FeeMedianWindow: -1}, []orm.L1Block{{Number: 1, BaseFee: 100}})
+	assert.Error(t, err)
+
+	// a single anomalous block
+	spike := []orm.L1Block{
+		{Number: 1, BaseFee: 100},
+		{Number: 2, BaseFee: 100},
+		{Number: 3, BaseFee: 100},
+		{Number: 4, BaseFee: 1000},
+		{Number: 5, BaseFee: 100},
+		{Number: 6, BaseFee: 100},
+	}
+	report, err := SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000}, spike)
+	assert.NoError(t, err)
+	assert.Equal(t, []*GasOracleUpdate{{L1BlockNumber: 1, L1BaseFee: 100}, {L1BlockNumber: 4, L1BaseFee: 1000}, {L1BlockNumber: 5, L1BaseFee: 100}}, report.Updates)
+
+	// the median of the latest blocks ignores it
+	report, err = SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000, L1FeeMedianWindow: 3}, spike)
+	assert.NoError(t, err)
+	assert.Equal(t, []*GasOracleUpdate{{L1BlockNumber: 1, L1BaseFee: 100}}, report.Updates)
+
+	// the clamped update moves by at most 50% of the last pushed fee
+	report, err = SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000, MaxGasPriceDelta: 500000}, spike)
+	assert.NoError(t, err)
+	assert.Equal(t, []*GasOracleUpdate{{L1BlockNumber: 1, L1BaseFee: 100}, {L1BlockNumber: 4, L1BaseFee: 150}, {L1BlockNumber: 5, L1BaseFee: 100}}, report.Updates)
+
+	// a lasting move is followed, one block late by the median and over several updates by the clamp
+	rise := []orm.L1Block{
+		{Number: 1, BaseFee: 100, BlobBaseFee: 10},
+		{Number: 2, BaseFee: 400, BlobBaseFee: 40},
+		{Number: 3, BaseFee: 400, BlobBaseFee: 40},
+		{Number: 4, BaseFee: 400, BlobBaseFee: 40},
+	}
+	report, err = SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000, L1FeeMedianWindow: 3}, rise)
+	assert.NoError(t, err)
+	assert.Equal(t, []*GasOracleUpdate{{L1BlockNumber: 1, L1BaseFee: 100}, {L1BlockNumber: 3, L1BaseFee: 400}}, report.Updates)
+
+	report, err = SimulateGasOracle(&config.GasOracleConfig{GasPriceDiff: 100000, MaxGasPriceDelta: 500000, EnableBlobBaseFee: true}, rise)
+	assert.NoError(t, err)
+	assert.Equal(t, []*GasOracleUpdate{
+		{L1BlockNumber: 1, L1BaseFee: 100, BlobBaseFee: 10},
+		{L1BlockNumber: 2, L1BaseFee: 150, BlobBaseFee: 15},
+		{L1BlockNumber: 3, L1BaseFee: 225, BlobBaseFee: 22},
+		{L1BlockNumber: 4, L1BaseFee: 337, BlobBaseFee: 33},
+	}, report.Updates)
 }
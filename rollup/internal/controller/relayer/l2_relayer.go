@@ -2,9 +2,13 @@ package relayer
 
 import (
 	"context"
+	crand "crypto/rand"
 	"fmt"
 	"math/big"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -17,7 +21,9 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/observability"
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
 	"scroll-tech/common/utils"
 
 	bridgeAbi "scroll-tech/rollup/abi"
@@ -58,11 +64,23 @@ type Layer2Relayer struct {
 	// Used to get batch status from chain_monitor api.
 	chainMonitorClient *resty.Client
 
+	// commitDiagnosis replays the reverted commit txs, they are not diagnosed if nil.
+	commitDiagnosis atomic.Pointer[commitDiagnosis]
+
+	// commitDueAt is the time the commit delay of a pending batch is over, by batch hash.
+	commitDueAt map[string]time.Time
+
+	// feeSweeps are the fee sweep txs not confirmed yet, by sender name.
+	feeSweeps sync.Map
+
+	// baseFeeForkBlock is the first block whose BlockContext carries the base fee, nil before the fork is scheduled.
+	baseFeeForkBlock *uint64
+
 	metrics *l2RelayerMetrics
 }
 
 // NewLayer2Relayer will return a new instance of Layer2RelayerClient
-func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.DB, cfg *config.RelayerConfig, initGenesis bool, serviceType ServiceType, reg prometheus.Registerer) (*Layer2Relayer, error) {
+func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.DB, cfg *config.RelayerConfig, baseFeeForkBlock *uint64, initGenesis bool, serviceType ServiceType, reg prometheus.Registerer) (*Layer2Relayer, error) {
 	var gasOracleSender, commitSender, finalizeSender *sender.Sender
 	var err error
 
@@ -131,7 +149,8 @@ func NewLayer2Relayer(ctx context.Context, l2Client *ethclient.Client, db *gorm.
 		minGasPrice:  minGasPrice,
 		gasPriceDiff: gasPriceDiff,
 
-		cfg: cfg,
+		cfg:              cfg,
+		baseFeeForkBlock: baseFeeForkBlock,
 	}
 
 	// chain_monitor client
@@ -183,6 +202,7 @@ func (r *Layer2Relayer) initializeGenesis() error {
 			WithdrawRoot:   common.Hash{},
 			RowConsumption: &gethTypes.RowConsumption{},
 		}},
+		BaseFeeForkBlock: r.baseFeeForkBlock,
 	}
 
 	err = r.db.Transaction(func(dbTX *gorm.DB) error {
@@ -242,7 +262,7 @@ func (r *Layer2Relayer) commitGenesisBatch(batchHash string, batchHeader []byte,
 	}
 
 	// submit genesis batch to L1 rollup contract
-	txHash, err := r.commitSender.SendTransaction(batchHash, &r.cfg.RollupContractAddress, big.NewInt(0), calldata, 0)
+	txHash, err := r.commitSender.SendTransaction(r.ctx, batchHash, &r.cfg.RollupContractAddress, big.NewInt(0), calldata, 0)
 	if err != nil {
 		return fmt.Errorf("failed to send import genesis batch tx to L1, error: %v", err)
 	}
@@ -278,16 +298,16 @@ func (r *Layer2Relayer) commitGenesisBatch(batchHash string, batchHeader []byte,
 }
 
 // ProcessGasPriceOracle imports gas price to layer1
-func (r *Layer2Relayer) ProcessGasPriceOracle() {
+func (r *Layer2Relayer) ProcessGasPriceOracle(ctx context.Context) {
 	r.metrics.rollupL2RelayerGasPriceOraclerRunTotal.Inc()
-	batch, err := r.batchOrm.GetLatestBatch(r.ctx)
+	batch, err := r.batchOrm.GetLatestBatch(ctx)
 	if batch == nil || err != nil {
 		log.Error("Failed to GetLatestBatch", "batch", batch, "err", err)
 		return
 	}
 
 	if types.GasOracleStatus(batch.OracleStatus) == types.GasOraclePending {
-		suggestGasPrice, err := r.l2Client.SuggestGasPrice(r.ctx)
+		suggestGasPrice, err := r.l2Client.SuggestGasPrice(ctx)
 		if err != nil {
 			log.Error("Failed to fetch SuggestGasPrice from l2geth", "err", err)
 			return
@@ -306,13 +326,15 @@ func (r *Layer2Relayer) ProcessGasPriceOracle() {
 				return
 			}
 
-			hash, err := r.gasOracleSender.SendTransaction(batch.Hash, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
+			hash, err := r.gasOracleSender.SendTransaction(ctx, batch.Hash, &r.cfg.GasPriceOracleContractAddress, big.NewInt(0), data, 0)
 			if err != nil {
 				log.Error("Failed to send setL2BaseFee tx to layer2 ", "batch.Hash", batch.Hash, "err", err)
 				return
 			}
 
-			err = r.batchOrm.UpdateL2GasOracleStatusAndOracleTxHash(r.ctx, batch.Hash, types.GasOracleImporting, hash.String())
+			recordCtx, cancel := sentTxRecordContext(r.ctx)
+			err = r.batchOrm.UpdateL2GasOracleStatusAndOracleTxHash(recordCtx, batch.Hash, types.GasOracleImporting, hash.String())
+			cancel()
 			if err != nil {
 				log.Error("UpdateGasOracleStatusAndOracleTxHash failed", "batch.Hash", batch.Hash, "err", err)
 				return
@@ -324,25 +346,72 @@ func (r *Layer2Relayer) ProcessGasPriceOracle() {
 	}
 }
 
+// debugStatePendingBatches is the max number of pending or commit failed batches in the debug state.
+const debugStatePendingBatches = 10
+
+// DebugState returns the state of the l2 relayer for the debug server, the pending batches are the
+// first pending or commit failed ones.
+func (r *Layer2Relayer) DebugState() interface{} {
+	ctx, cancel := context.WithTimeout(r.ctx, observability.StateDumpTimeout)
+	defer cancel()
+
+	state := map[string]interface{}{"commit_diagnosis": r.commitDiagnosis.Load() != nil}
+	if batch, err := r.batchOrm.GetLatestBatch(ctx); err != nil {
+		state["latest_batch_error"] = err.Error()
+	} else if batch != nil {
+		state["latest_batch"] = map[string]interface{}{
+			"index":          batch.Index,
+			"hash":           batch.Hash,
+			"rollup_status":  types.RollupStatus(batch.RollupStatus).String(),
+			"proving_status": types.ProvingStatus(batch.ProvingStatus).String(),
+		}
+	}
+	batches, err := r.batchOrm.GetFailedAndPendingBatches(ctx, debugStatePendingBatches)
+	if err != nil {
+		state["pending_batches_error"] = err.Error()
+		return state
+	}
+	pendingBatches := make([]map[string]interface{}, 0, len(batches))
+	for _, batch := range batches {
+		pendingBatches = append(pendingBatches, map[string]interface{}{
+			"index":         batch.Index,
+			"hash":          batch.Hash,
+			"rollup_status": types.RollupStatus(batch.RollupStatus).String(),
+		})
+	}
+	state["pending_batches"] = pendingBatches
+	return state
+}
+
 // ProcessPendingBatches processes the pending batches by sending commitBatch transactions to layer 1.
-func (r *Layer2Relayer) ProcessPendingBatches() {
+func (r *Layer2Relayer) ProcessPendingBatches(ctx context.Context) {
 	// get pending batches from database in ascending order by their index.
-	batches, err := r.batchOrm.GetFailedAndPendingBatches(r.ctx, 5)
+	batches, err := r.batchOrm.GetFailedAndPendingBatches(ctx, 5)
 	if err != nil {
 		log.Error("Failed to fetch pending L2 batches", "err", err)
 		return
 	}
 	for _, batch := range batches {
+		// batches are committed in order, so a delayed batch holds back the following ones
+		if !r.commitDue(batch) {
+			return
+		}
+
 		r.metrics.rollupL2RelayerProcessPendingBatchTotal.Inc()
 		// get current header and parent header.
-		currentBatchHeader, err := types.DecodeBatchHeader(batch.BatchHeader)
+		currentBatchHeader, err := codec.DecodeBatchHeader(batch.BatchHeader)
 		if err != nil {
 			log.Error("Failed to decode batch header", "index", batch.Index, "error", err)
 			return
 		}
+		batchCodec, err := codec.New(codec.Version(currentBatchHeader.Version()))
+		if err != nil {
+			log.Error("Failed to get batch codec", "index", batch.Index, "version", currentBatchHeader.Version(), "error", err)
+			return
+		}
 		parentBatch := &orm.Batch{}
 		if batch.Index > 0 {
-			parentBatch, err = r.batchOrm.GetBatchByIndex(r.ctx, batch.Index-1)
+			parentBatch, err = r.batchOrm.GetBatchByIndex(ctx, batch.Index-1)
 			if err != nil {
 				log.Error("Failed to get parent batch header", "index", batch.Index-1, "error", err)
 				return
@@ -358,7 +427,7 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 		// get the chunks for the batch
 		startChunkIndex := batch.StartChunkIndex
 		endChunkIndex := batch.EndChunkIndex
-		dbChunks, err := r.chunkOrm.GetChunksInRange(r.ctx, startChunkIndex, endChunkIndex)
+		dbChunks, err := r.chunkOrm.GetChunksInRange(ctx, startChunkIndex, endChunkIndex)
 		if err != nil {
 			log.Error("Failed to fetch chunks",
 				"start index", startChunkIndex,
@@ -366,10 +435,11 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 			return
 		}
 
+		chunks := make([]*types.Chunk, len(dbChunks))
 		encodedChunks := make([][]byte, len(dbChunks))
 		for i, c := range dbChunks {
 			var wrappedBlocks []*types.WrappedBlock
-			wrappedBlocks, err = r.l2BlockOrm.GetL2BlocksInRange(r.ctx, c.StartBlockNumber, c.EndBlockNumber)
+			wrappedBlocks, err = r.l2BlockOrm.GetL2BlocksInRange(ctx, c.StartBlockNumber, c.EndBlockNumber)
 			if err != nil {
 				log.Error("Failed to fetch wrapped blocks",
 					"start number", c.StartBlockNumber,
@@ -377,17 +447,26 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 				return
 			}
 			chunk := &types.Chunk{
-				Blocks: wrappedBlocks,
+				Blocks:           wrappedBlocks,
+				BaseFeeForkBlock: r.baseFeeForkBlock,
 			}
 			var chunkBytes []byte
-			chunkBytes, err = chunk.Encode(c.TotalL1MessagesPoppedBefore)
+			chunkBytes, err = batchCodec.EncodeChunk(chunk, c.TotalL1MessagesPoppedBefore)
 			if err != nil {
 				log.Error("Failed to encode chunk", "error", err)
 				return
 			}
+			chunks[i] = chunk
 			encodedChunks[i] = chunkBytes
 		}
 
+		// the contract reverts the commit of a batch whose data hash differs from the local one
+		if err = checkBatchDataHash(batch, currentBatchHeader, batchCodec, dbChunks, chunks, encodedChunks); err != nil {
+			r.metrics.rollupL2RelayerBatchDataHashMismatchTotal.Inc()
+			log.Error("Refuse to commit the batch", "index", batch.Index, "hash", batch.Hash, "error", err)
+			return
+		}
+
 		calldata, err := r.l1RollupABI.Pack("commitBatch", currentBatchHeader.Version(), parentBatch.BatchHeader, encodedChunks, currentBatchHeader.SkippedL1MessageBitmap())
 		if err != nil {
 			log.Error("Failed to pack commitBatch", "index", batch.Index, "error", err)
@@ -401,7 +480,20 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 			fallbackGasLimit = 0
 			log.Warn("Batch commit previously failed, using eth_estimateGas for the re-submission", "hash", batch.Hash)
 		}
-		txHash, err := r.commitSender.SendTransaction(batch.Hash, &r.cfg.RollupContractAddress, big.NewInt(0), calldata, fallbackGasLimit)
+		var txHash common.Hash
+		if batchCodec.Version() == codec.CodecV0 {
+			txHash, err = r.commitSender.SendTransaction(ctx, batch.Hash, &r.cfg.RollupContractAddress, big.NewInt(0), calldata, fallbackGasLimit)
+		} else {
+			// the L2 transactions are committed in the blob of the commit transaction
+			var sidecar *gethTypes.BlobTxSidecar
+			sidecar, err = newBatchBlobSidecar(batchCodec, currentBatchHeader, chunks)
+			if err != nil {
+				log.Error("Failed to create the blob of the batch", "index", batch.Index, "hash", batch.Hash, "error", err)
+				return
+			}
+			// the commit gas of a blob-carrying transaction can't be estimated without its blob
+			txHash, err = r.commitSender.SendBlobTransaction(ctx, batch.Hash, &r.cfg.RollupContractAddress, calldata, sidecar, uint64(float64(batch.TotalL1CommitGas)*r.cfg.L1CommitGasLimitMultiplier))
+		}
 		if err != nil {
 			log.Error(
 				"Failed to send commitBatch tx to layer1",
@@ -421,25 +513,99 @@ func (r *Layer2Relayer) ProcessPendingBatches() {
 			return
 		}
 
-		err = r.batchOrm.UpdateCommitTxHashAndRollupStatus(r.ctx, batch.Hash, txHash.String(), types.RollupCommitting)
+		recordCtx, cancel := sentTxRecordContext(r.ctx)
+		err = r.batchOrm.UpdateCommitTxHashAndRollupStatus(recordCtx, batch.Hash, txHash.String(), types.RollupCommitting)
+		cancel()
 		if err != nil {
 			log.Error("UpdateCommitTxHashAndRollupStatus failed", "hash", batch.Hash, "index", batch.Index, "err", err)
 			return
 		}
+		delete(r.commitDueAt, batch.Hash)
 		r.metrics.rollupL2RelayerProcessPendingBatchSuccessTotal.Inc()
 		log.Info("Sent the commitBatch tx to layer1", "batch index", batch.Index, "batch hash", batch.Hash, "tx hash", txHash.Hex())
 	}
 }
 
+// checkBatchDataHash computes the data hash of the encoded chunks about to be committed the way the rollup contract
+// does and checks it against the data hash of the batch header.
+func checkBatchDataHash(batch *orm.Batch, header codec.BatchHeader, batchCodec codec.Codec, dbChunks []*orm.Chunk, chunks []*types.Chunk, encodedChunks [][]byte) error {
+	if len(dbChunks) == 0 {
+		return fmt.Errorf("batch %v has no chunks", batch.Index)
+	}
+	localBatch := &types.Batch{
+		Index:                      batch.Index,
+		TotalL1MessagePoppedBefore: dbChunks[0].TotalL1MessagesPoppedBefore,
+		ParentBatchHash:            header.ParentBatchHash(),
+		Chunks:                     chunks,
+	}
+	computeDataHash := localBatch.DataHash
+	if batchCodec.Version() != codec.CodecV0 {
+		computeDataHash = localBatch.BlobDataHash
+	}
+	dataHash, err := computeDataHash(encodedChunks)
+	if err != nil {
+		return fmt.Errorf("failed to compute the data hash of batch %v: %w", batch.Index, err)
+	}
+	if dataHash != header.DataHash() {
+		return fmt.Errorf("data hash of batch %v mismatch, computed: %v, batch header: %v", batch.Index, dataHash.Hex(), header.DataHash().Hex())
+	}
+	return nil
+}
+
+// newBatchBlobSidecar creates the sidecar of the blob of the batch and checks it is the blob committed by the batch header.
+func newBatchBlobSidecar(batchCodec codec.Codec, header codec.BatchHeader, chunks []*types.Chunk) (*gethTypes.BlobTxSidecar, error) {
+	blob, err := batchCodec.NewBatchBlob(chunks)
+	if err != nil {
+		return nil, err
+	}
+	if blob == nil {
+		return nil, fmt.Errorf("codec version %v commits no blob", batchCodec.Version())
+	}
+	if blobHeader, ok := header.(codec.BlobBatchHeader); !ok || blobHeader.BlobVersionedHash() != blob.VersionedHash {
+		return nil, fmt.Errorf("blob versioned hash mismatch, computed: %v", blob.VersionedHash.Hex())
+	}
+	return blob.Sidecar()
+}
+
+// commitDue returns whether the commit delay of the batch is over. The delay of a batch is drawn once and
+// counted from its creation by the batch proposer, the resubmission of a failed commit is not delayed.
+func (r *Layer2Relayer) commitDue(batch *orm.Batch) bool {
+	delayCfg := r.cfg.CommitDelayConfig
+	if delayCfg == nil || types.RollupStatus(batch.RollupStatus) == types.RollupCommitFailed {
+		return true
+	}
+
+	dueAt, ok := r.commitDueAt[batch.Hash]
+	if !ok {
+		delay := delayCfg.MinDelaySec
+		if delayCfg.MaxDelaySec > delayCfg.MinDelaySec {
+			// a crypto random delay cannot be predicted by the observers of the previous commits
+			n, err := crand.Int(crand.Reader, new(big.Int).SetUint64(delayCfg.MaxDelaySec-delayCfg.MinDelaySec+1))
+			if err != nil {
+				log.Warn("failed to draw the commit delay, using the max delay", "hash", batch.Hash, "err", err)
+				n = new(big.Int).SetUint64(delayCfg.MaxDelaySec - delayCfg.MinDelaySec)
+			}
+			delay += n.Uint64()
+		}
+		dueAt = batch.CreatedAt.Add(time.Duration(delay) * time.Second)
+		if r.commitDueAt == nil {
+			r.commitDueAt = make(map[string]time.Time)
+		}
+		r.commitDueAt[batch.Hash] = dueAt
+		log.Info("delaying the commit of the batch", "index", batch.Index, "hash", batch.Hash, "delay sec", delay, "due at", dueAt)
+	}
+	return !time.Now().Before(dueAt)
+}
+
 // ProcessCommittedBatches submit proof to layer 1 rollup contract
-func (r *Layer2Relayer) ProcessCommittedBatches() {
+func (r *Layer2Relayer) ProcessCommittedBatches(ctx context.Context) {
 	// retrieves the earliest batch whose rollup status is 'committed'
 	fields := map[string]interface{}{
 		"rollup_status": types.RollupCommitted,
 	}
 	orderByList := []string{"index ASC"}
 	limit := 1
-	batches, err := r.batchOrm.GetBatches(r.ctx, fields, orderByList, limit)
+	batches, err := r.batchOrm.GetBatches(ctx, fields, orderByList, limit)
 	if err != nil {
 		log.Error("Failed to fetch committed L2 batches", "err", err)
 		return
@@ -461,7 +627,7 @@ func (r *Layer2Relayer) ProcessCommittedBatches() {
 		}
 
 		if r.cfg.EnableTestEnvBypassFeatures && utils.NowUTC().Sub(*batch.CommittedAt) > time.Duration(r.cfg.FinalizeBatchWithoutProofTimeoutSec)*time.Second {
-			if err := r.finalizeBatch(batch, false); err != nil {
+			if err := r.finalizeBatch(ctx, batch, false); err != nil {
 				log.Error("Failed to finalize timeout batch without proof", "index", batch.Index, "hash", batch.Hash, "err", err)
 			}
 		}
@@ -469,7 +635,7 @@ func (r *Layer2Relayer) ProcessCommittedBatches() {
 	case types.ProvingTaskVerified:
 		log.Info("Start to roll up zk proof", "hash", batch.Hash)
 		r.metrics.rollupL2RelayerProcessCommittedBatchesFinalizedTotal.Inc()
-		if err := r.finalizeBatch(batch, true); err != nil {
+		if err := r.finalizeBatch(ctx, batch, true); err != nil {
 			log.Error("Failed to finalize batch with proof", "index", batch.Index, "hash", batch.Hash, "err", err)
 		}
 
@@ -496,11 +662,11 @@ func (r *Layer2Relayer) ProcessCommittedBatches() {
 	}
 }
 
-func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
+func (r *Layer2Relayer) finalizeBatch(ctx context.Context, batch *orm.Batch, withProof bool) error {
 	// Check batch status before send `finalizeBatch` tx.
 	if r.cfg.ChainMonitor.Enabled {
 		var batchStatus bool
-		batchStatus, err := r.getBatchStatusByIndex(batch)
+		batchStatus, err := r.getBatchStatusByIndex(ctx, batch)
 		if err != nil {
 			r.metrics.rollupL2ChainMonitorLatestFailedCall.Inc()
 			log.Warn("failed to get batch status, please check chain_monitor api server", "batch_index", batch.Index, "err", err)
@@ -516,7 +682,7 @@ func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
 	var parentBatchStateRoot string
 	if batch.Index > 0 {
 		var parentBatch *orm.Batch
-		parentBatch, err := r.batchOrm.GetBatchByIndex(r.ctx, batch.Index-1)
+		parentBatch, err := r.batchOrm.GetBatchByIndex(ctx, batch.Index-1)
 		// handle unexpected db error
 		if err != nil {
 			log.Error("Failed to get batch", "index", batch.Index-1, "err", err)
@@ -527,7 +693,7 @@ func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
 
 	var txCalldata []byte
 	if withProof {
-		aggProof, err := r.batchOrm.GetVerifiedProofByHash(r.ctx, batch.Hash)
+		aggProof, err := r.batchOrm.GetVerifiedProofByHash(ctx, batch.Hash)
 		if err != nil {
 			log.Error("get verified proof by hash failed", "hash", batch.Hash, "err", err)
 			return err
@@ -566,7 +732,7 @@ func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
 	}
 
 	// add suffix `-finalize` to avoid duplication with commit tx in unit tests
-	txHash, err := r.finalizeSender.SendTransaction(batch.Hash, &r.cfg.RollupContractAddress, big.NewInt(0), txCalldata, 0)
+	txHash, err := r.finalizeSender.SendTransaction(ctx, batch.Hash, &r.cfg.RollupContractAddress, big.NewInt(0), txCalldata, 0)
 	finalizeTxHash := &txHash
 	if err != nil {
 		log.Error(
@@ -591,7 +757,9 @@ func (r *Layer2Relayer) finalizeBatch(batch *orm.Batch, withProof bool) error {
 	log.Info("finalizeBatch in layer1", "with proof", withProof, "index", batch.Index, "batch hash", batch.Hash, "tx hash", batch.Hash)
 
 	// record and sync with db, @todo handle db error
-	if err := r.batchOrm.UpdateFinalizeTxHashAndRollupStatus(r.ctx, batch.Hash, finalizeTxHash.String(), types.RollupFinalizing); err != nil {
+	recordCtx, cancel := sentTxRecordContext(r.ctx)
+	defer cancel()
+	if err := r.batchOrm.UpdateFinalizeTxHashAndRollupStatus(recordCtx, batch.Hash, finalizeTxHash.String(), types.RollupFinalizing); err != nil {
 		log.Error("UpdateFinalizeTxHashAndRollupStatus failed", "index", batch.Index, "batch hash", batch.Hash, "tx hash", finalizeTxHash.String(), "err", err)
 		return err
 	}
@@ -606,8 +774,8 @@ type batchStatusResponse struct {
 	Data    bool   `json:"data"`
 }
 
-func (r *Layer2Relayer) getBatchStatusByIndex(batch *orm.Batch) (bool, error) {
-	chunks, getChunkErr := r.chunkOrm.GetChunksInRange(r.ctx, batch.StartChunkIndex, batch.EndChunkIndex)
+func (r *Layer2Relayer) getBatchStatusByIndex(ctx context.Context, batch *orm.Batch) (bool, error) {
+	chunks, getChunkErr := r.chunkOrm.GetChunksInRange(ctx, batch.StartChunkIndex, batch.EndChunkIndex)
 	if getChunkErr != nil {
 		log.Error("Layer2Relayer.getBatchStatusByIndex get chunks range failed", "startChunkIndex", batch.StartChunkIndex, "endChunkIndex", batch.EndChunkIndex, "err", getChunkErr)
 		return false, getChunkErr
@@ -646,6 +814,11 @@ func (r *Layer2Relayer) getBatchStatusByIndex(batch *orm.Batch) (bool, error) {
 }
 
 func (r *Layer2Relayer) handleConfirmation(cfm *sender.Confirmation) {
+	if strings.HasPrefix(cfm.ContextID, feeSweepContextIDPrefix) {
+		r.handleFeeSweepConfirmation(cfm)
+		return
+	}
+
 	switch cfm.SenderType {
 	case types.SenderTypeCommitBatch:
 		var status types.RollupStatus
@@ -656,6 +829,9 @@ func (r *Layer2Relayer) handleConfirmation(cfm *sender.Confirmation) {
 			status = types.RollupCommitFailed
 			r.metrics.rollupL2BatchesCommittedConfirmedFailedTotal.Inc()
 			log.Warn("CommitBatchTxType transaction confirmed but failed in layer1", "confirmation", cfm)
+			if diagnosis := r.commitDiagnosis.Load(); diagnosis != nil {
+				go r.diagnoseCommitFailure(diagnosis, cfm.ContextID, cfm.TxHash)
+			}
 		}
 
 		err := r.batchOrm.UpdateCommitTxHashAndRollupStatus(r.ctx, cfm.ContextID, cfm.TxHash.String(), status)
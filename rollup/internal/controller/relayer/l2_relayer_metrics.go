@@ -23,6 +23,10 @@ type l2RelayerMetrics struct {
 	rollupL2UpdateGasOracleConfirmedFailedTotal                 prometheus.Counter
 	rollupL2ChainMonitorLatestFailedCall                        prometheus.Counter
 	rollupL2ChainMonitorLatestFailedBatchStatus                 prometheus.Counter
+	rollupL2CommitFailureDiagnosisTotal                         *prometheus.CounterVec
+	rollupL2RelayerBatchDataHashMismatchTotal                   prometheus.Counter
+	rollupL2FeeSweepTotal                                       *prometheus.CounterVec
+	rollupL2FeeSweptWeiTotal                                    *prometheus.CounterVec
 }
 
 var (
@@ -93,6 +97,22 @@ func initL2RelayerMetrics(reg prometheus.Registerer) *l2RelayerMetrics {
 				Name: "rollup_layer2_chain_monitor_latest_failed_batch_status",
 				Help: "The total number of failed batch status get from chain_monitor",
 			}),
+			rollupL2CommitFailureDiagnosisTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_layer2_commit_failure_diagnosis_total",
+				Help: "The total number of diagnosed reverted commit batch txs by most likely cause",
+			}, []string{"cause"}),
+			rollupL2RelayerBatchDataHashMismatchTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_layer2_batch_data_hash_mismatch_total",
+				Help: "The total number of pending batches not committed because their data hash differs from the computed one",
+			}),
+			rollupL2FeeSweepTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_layer2_fee_sweep_total",
+				Help: "The total number of fee sweep txs to the operator fee recipient by sender and status",
+			}, []string{"sender", "status"}),
+			rollupL2FeeSweptWeiTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_layer2_fee_swept_wei_total",
+				Help: "The total wei swept to the operator fee recipient by confirmed fee sweep txs, by sender",
+			}, []string{"sender"}),
 		}
 	})
 	return l2RelayerMetric
@@ -7,21 +7,25 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 
 	"scroll-tech/common/database"
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/utils"
 
 	"scroll-tech/database/migrate"
 
+	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/controller/sender"
 	"scroll-tech/rollup/internal/orm"
 )
@@ -38,7 +42,7 @@ func setupL2RelayerDB(t *testing.T) *gorm.DB {
 func testCreateNewRelayer(t *testing.T) {
 	db := setupL2RelayerDB(t)
 	defer database.CloseDB(db)
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, relayer)
 }
@@ -48,7 +52,7 @@ func testL2RelayerProcessPendingBatches(t *testing.T) {
 	defer database.CloseDB(db)
 
 	l2Cfg := cfg.L2Config
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 
 	l2BlockOrm := orm.NewL2Block(db)
@@ -69,7 +73,7 @@ func testL2RelayerProcessPendingBatches(t *testing.T) {
 	batch, err := batchOrm.InsertBatch(context.Background(), []*types.Chunk{chunk1, chunk2}, batchMeta)
 	assert.NoError(t, err)
 
-	relayer.ProcessPendingBatches()
+	relayer.ProcessPendingBatches(context.Background())
 
 	statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{batch.Hash})
 	assert.NoError(t, err)
@@ -77,12 +81,100 @@ func testL2RelayerProcessPendingBatches(t *testing.T) {
 	assert.Equal(t, types.RollupCommitting, statuses[0])
 }
 
+func testL2RelayerCheckBatchDataHash(t *testing.T) {
+	chunks := []*types.Chunk{chunk1, chunk2}
+	dbChunks := []*orm.Chunk{{TotalL1MessagesPoppedBefore: 0}, {TotalL1MessagesPoppedBefore: chunk1.NumL1Messages(0)}}
+	var encodedChunks [][]byte
+	for i, chunk := range chunks {
+		chunkBytes, err := chunk.Encode(dbChunks[i].TotalL1MessagesPoppedBefore)
+		assert.NoError(t, err)
+		encodedChunks = append(encodedChunks, chunkBytes)
+	}
+	batchHeader, err := types.NewBatchHeader(0, 1, 0, common.Hash{}, chunks)
+	assert.NoError(t, err)
+	v0, err := codec.New(codec.CodecV0)
+	assert.NoError(t, err)
+	batch := &orm.Batch{Index: 1}
+	assert.NoError(t, checkBatchDataHash(batch, batchHeader, v0, dbChunks, chunks, encodedChunks))
+
+	// the encoded payload of an l2 tx no longer hashes to the tx hash of its trace
+	tampered := append([]byte{}, encodedChunks[1]...)
+	tampered[len(tampered)-1] ^= 1
+	err = checkBatchDataHash(batch, batchHeader, v0, dbChunks, chunks, [][]byte{encodedChunks[0], tampered})
+	assert.ErrorContains(t, err, "data hash of batch 1 mismatch")
+
+	// the blob codecs commit the block contexts only
+	v1, err := codec.New(codec.CodecV1)
+	assert.NoError(t, err)
+	v1Header, err := v1.NewBatchHeader(1, 0, common.Hash{}, chunks)
+	assert.NoError(t, err)
+	var v1EncodedChunks [][]byte
+	for i, chunk := range chunks {
+		chunkBytes, err := v1.EncodeChunk(chunk, dbChunks[i].TotalL1MessagesPoppedBefore)
+		assert.NoError(t, err)
+		v1EncodedChunks = append(v1EncodedChunks, chunkBytes)
+	}
+	assert.NoError(t, checkBatchDataHash(batch, v1Header, v1, dbChunks, chunks, v1EncodedChunks))
+	err = checkBatchDataHash(batch, batchHeader, v1, dbChunks, chunks, v1EncodedChunks)
+	assert.ErrorContains(t, err, "data hash of batch 1 mismatch")
+}
+
+func testL2RelayerProcessPendingBlobBatches(t *testing.T) {
+	db := setupL2RelayerDB(t)
+	defer database.CloseDB(db)
+
+	l2Cfg := cfg.L2Config
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err = l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+	chunkOrm := orm.NewChunk(db)
+	dbChunk1, err := chunkOrm.InsertChunk(context.Background(), chunk1)
+	assert.NoError(t, err)
+	dbChunk2, err := chunkOrm.InsertChunk(context.Background(), chunk2)
+	assert.NoError(t, err)
+	batchMeta := &types.BatchMeta{
+		StartChunkIndex:  0,
+		StartChunkHash:   dbChunk1.Hash,
+		EndChunkIndex:    1,
+		EndChunkHash:     dbChunk2.Hash,
+		TotalL1CommitGas: 100000,
+		CodecVersion:     uint8(codec.CodecV1),
+	}
+	batchOrm := orm.NewBatch(db)
+	batch, err := batchOrm.InsertBatch(context.Background(), []*types.Chunk{chunk1, chunk2}, batchMeta)
+	assert.NoError(t, err)
+	header, err := codec.DecodeBatchHeader(batch.BatchHeader)
+	assert.NoError(t, err)
+
+	var sentSidecar *gethTypes.BlobTxSidecar
+	var sentGasLimit uint64
+	patchGuard := gomonkey.ApplyMethodFunc(relayer.commitSender, "SendBlobTransaction", func(ctx context.Context, contextID string, target *common.Address, data []byte, sidecar *gethTypes.BlobTxSidecar, gasLimit uint64) (common.Hash, error) {
+		sentSidecar, sentGasLimit = sidecar, gasLimit
+		return common.HexToHash("0x56789abcdef1234"), nil
+	})
+	defer patchGuard.Reset()
+
+	relayer.ProcessPendingBatches(context.Background())
+
+	// the blob committed by the batch header is sent along with the commit transaction
+	assert.NotNil(t, sentSidecar)
+	assert.Equal(t, []common.Hash{header.(codec.BlobBatchHeader).BlobVersionedHash()}, sentSidecar.BlobHashes())
+	assert.Equal(t, uint64(float64(batchMeta.TotalL1CommitGas)*l2Cfg.RelayerConfig.L1CommitGasLimitMultiplier), sentGasLimit)
+	statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{batch.Hash})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, types.RollupCommitting, statuses[0])
+}
+
 func testL2RelayerProcessCommittedBatches(t *testing.T) {
 	db := setupL2RelayerDB(t)
 	defer database.CloseDB(db)
 
 	l2Cfg := cfg.L2Config
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	batchMeta := &types.BatchMeta{
 		StartChunkIndex: 0,
@@ -100,7 +192,7 @@ func testL2RelayerProcessCommittedBatches(t *testing.T) {
 	err = batchOrm.UpdateProvingStatus(context.Background(), batch.Hash, types.ProvingTaskVerified)
 	assert.NoError(t, err)
 
-	relayer.ProcessCommittedBatches()
+	relayer.ProcessCommittedBatches(context.Background())
 
 	statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{batch.Hash})
 	assert.NoError(t, err)
@@ -114,7 +206,7 @@ func testL2RelayerProcessCommittedBatches(t *testing.T) {
 	err = batchOrm.UpdateProofByHash(context.Background(), batch.Hash, proof, 100)
 	assert.NoError(t, err)
 
-	relayer.ProcessCommittedBatches()
+	relayer.ProcessCommittedBatches(context.Background())
 	statuses, err = batchOrm.GetRollupStatusByHashList(context.Background(), []string{batch.Hash})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(statuses))
@@ -128,7 +220,7 @@ func testL2RelayerFinalizeTimeoutBatches(t *testing.T) {
 	l2Cfg := cfg.L2Config
 	l2Cfg.RelayerConfig.EnableTestEnvBypassFeatures = true
 	l2Cfg.RelayerConfig.FinalizeBatchWithoutProofTimeoutSec = 0
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, l2Cfg.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	batchMeta := &types.BatchMeta{
 		StartChunkIndex: 0,
@@ -145,7 +237,7 @@ func testL2RelayerFinalizeTimeoutBatches(t *testing.T) {
 
 	// Check the database for the updated status using TryTimes.
 	ok := utils.TryTimes(5, func() bool {
-		relayer.ProcessCommittedBatches()
+		relayer.ProcessCommittedBatches(context.Background())
 		statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{batch.Hash})
 		return err == nil && len(statuses) == 1 && statuses[0] == types.RollupFinalizing
 	})
@@ -160,7 +252,7 @@ func testL2RelayerCommitConfirm(t *testing.T) {
 	l2Cfg := cfg.L2Config
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 
 	// Simulate message confirmations.
@@ -214,7 +306,7 @@ func testL2RelayerFinalizeConfirm(t *testing.T) {
 	l2Cfg := cfg.L2Config
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 
 	// Simulate message confirmations.
@@ -287,7 +379,7 @@ func testL2RelayerGasOracleConfirm(t *testing.T) {
 	l2Cfg := cfg.L2Config
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, false, ServiceTypeL2GasOracle, nil)
+	l2Relayer, err := NewLayer2Relayer(ctx, l2Cli, db, l2Cfg.RelayerConfig, nil, false, ServiceTypeL2GasOracle, nil)
 	assert.NoError(t, err)
 
 	// Simulate message confirmations.
@@ -326,7 +418,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 	db := setupL2RelayerDB(t)
 	defer database.CloseDB(db)
 
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, false, ServiceTypeL2GasOracle, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, nil, false, ServiceTypeL2GasOracle, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, relayer)
 
@@ -337,7 +429,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 			return nil, targetErr
 		})
 		defer patchGuard.Reset()
-		relayer.ProcessGasPriceOracle()
+		relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard := gomonkey.ApplyMethodFunc(batchOrm, "GetLatestBatch", func(context.Context) (*orm.Batch, error) {
@@ -354,7 +446,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(relayer.l2Client, "SuggestGasPrice", func(ctx context.Context) (*big.Int, error) {
 			return nil, targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard.ApplyMethodFunc(relayer.l2Client, "SuggestGasPrice", func(ctx context.Context) (*big.Int, error) {
@@ -366,7 +458,7 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(relayer.l2GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
 			return nil, targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard.ApplyMethodFunc(relayer.l2GasOracleABI, "Pack", func(name string, args ...interface{}) ([]byte, error) {
@@ -375,13 +467,13 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 
 	convey.Convey("Failed to send setL2BaseFee tx to layer2", t, func() {
 		targetErr := errors.New("failed to send setL2BaseFee tx to layer2 error")
-		patchGuard.ApplyMethodFunc(relayer.gasOracleSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
+		patchGuard.ApplyMethodFunc(relayer.gasOracleSender, "SendTransaction", func(ctx context.Context, ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
 			return common.Hash{}, targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		relayer.ProcessGasPriceOracle(context.Background())
 	})
 
-	patchGuard.ApplyMethodFunc(relayer.gasOracleSender, "SendTransaction", func(ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
+	patchGuard.ApplyMethodFunc(relayer.gasOracleSender, "SendTransaction", func(ctx context.Context, ContextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (hash common.Hash, err error) {
 		return common.HexToHash("0x56789abcdef1234"), nil
 	})
 
@@ -390,13 +482,13 @@ func testLayer2RelayerProcessGasPriceOracle(t *testing.T) {
 		patchGuard.ApplyMethodFunc(batchOrm, "UpdateL2GasOracleStatusAndOracleTxHash", func(ctx context.Context, hash string, status types.GasOracleStatus, txHash string) error {
 			return targetErr
 		})
-		relayer.ProcessGasPriceOracle()
+		relayer.ProcessGasPriceOracle(context.Background())
 	})
 
 	patchGuard.ApplyMethodFunc(batchOrm, "UpdateL2GasOracleStatusAndOracleTxHash", func(ctx context.Context, hash string, status types.GasOracleStatus, txHash string) error {
 		return nil
 	})
-	relayer.ProcessGasPriceOracle()
+	relayer.ProcessGasPriceOracle(context.Background())
 }
 
 func mockChainMonitorServer(baseURL string) (*http.Server, error) {
@@ -439,11 +531,36 @@ func testGetBatchStatusByIndex(t *testing.T) {
 	assert.NoError(t, err)
 
 	cfg.L2Config.RelayerConfig.ChainMonitor.Enabled = true
-	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, false, ServiceTypeL2RollupRelayer, nil)
+	relayer, err := NewLayer2Relayer(context.Background(), l2Cli, db, cfg.L2Config.RelayerConfig, nil, false, ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, relayer)
 
-	status, err := relayer.getBatchStatusByIndex(batch)
+	status, err := relayer.getBatchStatusByIndex(context.Background(), batch)
 	assert.NoError(t, err)
 	assert.Equal(t, true, status)
 }
+
+func testL2RelayerCommitDelay(t *testing.T) {
+	relayer := &Layer2Relayer{cfg: &config.RelayerConfig{}}
+	batch := &orm.Batch{Hash: "0x01", RollupStatus: int16(types.RollupPending), CreatedAt: time.Now()}
+	assert.True(t, relayer.commitDue(batch))
+
+	relayer.cfg.CommitDelayConfig = &config.CommitDelayConfig{MinDelaySec: 60, MaxDelaySec: 120}
+	assert.False(t, relayer.commitDue(batch))
+	dueAt := relayer.commitDueAt[batch.Hash]
+	assert.False(t, dueAt.Before(batch.CreatedAt.Add(60*time.Second)))
+	assert.False(t, dueAt.After(batch.CreatedAt.Add(120*time.Second)))
+
+	// the delay is drawn once per batch
+	assert.False(t, relayer.commitDue(batch))
+	assert.Equal(t, dueAt, relayer.commitDueAt[batch.Hash])
+
+	// the resubmission of a failed commit is not delayed
+	failedBatch := &orm.Batch{Hash: "0x02", RollupStatus: int16(types.RollupCommitFailed), CreatedAt: time.Now()}
+	assert.True(t, relayer.commitDue(failedBatch))
+
+	relayer.cfg.CommitDelayConfig = &config.CommitDelayConfig{MinDelaySec: 60, MaxDelaySec: 60}
+	sealedBatch := &orm.Batch{Hash: "0x03", RollupStatus: int16(types.RollupPending), CreatedAt: time.Now().Add(-time.Minute)}
+	assert.True(t, relayer.commitDue(sealedBatch))
+	assert.Equal(t, sealedBatch.CreatedAt.Add(time.Minute), relayer.commitDueAt[sealedBatch.Hash])
+}
@@ -106,15 +106,22 @@ func TestFunctions(t *testing.T) {
 	t.Run("TestCreateNewL1Relayer", testCreateNewL1Relayer)
 	t.Run("TestL1RelayerGasOracleConfirm", testL1RelayerGasOracleConfirm)
 	t.Run("TestL1RelayerProcessGasPriceOracle", testL1RelayerProcessGasPriceOracle)
+	t.Run("TestSimulateGasOracle", testSimulateGasOracle)
+	t.Run("TestSimulateGasOracleSpikeProtection", testSimulateGasOracleSpikeProtection)
 
 	// Run l2 relayer test cases.
 	t.Run("TestCreateNewRelayer", testCreateNewRelayer)
 	t.Run("TestL2RelayerProcessPendingBatches", testL2RelayerProcessPendingBatches)
+	t.Run("TestL2RelayerCheckBatchDataHash", testL2RelayerCheckBatchDataHash)
+	t.Run("TestL2RelayerProcessPendingBlobBatches", testL2RelayerProcessPendingBlobBatches)
 	t.Run("TestL2RelayerProcessCommittedBatches", testL2RelayerProcessCommittedBatches)
 	t.Run("TestL2RelayerFinalizeTimeoutBatches", testL2RelayerFinalizeTimeoutBatches)
+	t.Run("TestL2RelayerCommitDelay", testL2RelayerCommitDelay)
 	t.Run("TestL2RelayerCommitConfirm", testL2RelayerCommitConfirm)
 	t.Run("TestL2RelayerFinalizeConfirm", testL2RelayerFinalizeConfirm)
 	t.Run("TestL2RelayerGasOracleConfirm", testL2RelayerGasOracleConfirm)
+	t.Run("TestCommitFailureDiagnosis", testCommitFailureDiagnosis)
+	t.Run("TestBatchIntegrityCheck", testBatchIntegrityCheck)
 	t.Run("TestLayer2RelayerProcessGasPriceOracle", testLayer2RelayerProcessGasPriceOracle)
 	// test getBatchStatusByIndex
 	t.Run("TestGetBatchStatusByIndex", testGetBatchStatusByIndex)
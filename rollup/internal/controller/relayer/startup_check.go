@@ -0,0 +1,159 @@
+package relayer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// ErrBatchIntegrity the local batches don't match the batches committed and finalized on layer 1,
+// e.g. after the database is restored from an old backup.
+var ErrBatchIntegrity = errors.New("local batches don't match layer 1")
+
+// committedRollupStatuses are the statuses of the batches whose commitment is stored by the rollup contract.
+var committedRollupStatuses = []types.RollupStatus{types.RollupCommitted, types.RollupFinalizing, types.RollupFinalized, types.RollupFinalizeFailed}
+
+// batchIntegrity is the state of the local batches and the rollup contract compared at startup.
+type batchIntegrity struct {
+	// latestCommitted is the latest local batch committed on layer 1, and onchainCommittedHash the hash committed at its index.
+	latestCommitted      *orm.Batch
+	onchainCommittedHash common.Hash
+	// next is the local batch after the latest committed one, nil if none, and onchainNextHash the hash committed at its index.
+	next            *orm.Batch
+	onchainNextHash common.Hash
+	// gap is a local batch not committed below the latest committed one, nil if none.
+	gap *orm.Batch
+
+	// latestFinalized is the latest local batch finalized, nil if none.
+	latestFinalized           *orm.Batch
+	onchainLastFinalizedIndex uint64
+}
+
+// CheckBatchIntegrity compares the local batches with the commitments and the last finalized batch index
+// of the rollup contract, committing or finalizing batches on top of a mismatch would revert or commit
+// the same batches twice. The returned error wraps ErrBatchIntegrity if the batches don't match.
+func (r *Layer2Relayer) CheckBatchIntegrity(l1Caller ethereum.ContractCaller) error {
+	var state batchIntegrity
+
+	committedBatches, err := r.batchOrm.GetBatches(r.ctx, map[string]interface{}{"rollup_status IN ?": committedRollupStatuses}, []string{"index DESC"}, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest committed batch: %w", err)
+	}
+	if len(committedBatches) > 0 {
+		state.latestCommitted = committedBatches[0]
+		if state.onchainCommittedHash, err = r.committedBatchHash(l1Caller, state.latestCommitted.Index); err != nil {
+			return fmt.Errorf("failed to get the batch committed on layer 1 at index %v: %w", state.latestCommitted.Index, err)
+		}
+
+		nextBatches, err := r.batchOrm.GetBatches(r.ctx, map[string]interface{}{"index = ?": state.latestCommitted.Index + 1}, nil, 1)
+		if err != nil {
+			return fmt.Errorf("failed to get the batch after the latest committed batch: %w", err)
+		}
+		if len(nextBatches) > 0 {
+			state.next = nextBatches[0]
+		}
+		if state.onchainNextHash, err = r.committedBatchHash(l1Caller, state.latestCommitted.Index+1); err != nil {
+			return fmt.Errorf("failed to get the batch committed on layer 1 at index %v: %w", state.latestCommitted.Index+1, err)
+		}
+
+		gaps, err := r.batchOrm.GetBatches(r.ctx, map[string]interface{}{
+			"index < ?":              state.latestCommitted.Index,
+			"rollup_status NOT IN ?": committedRollupStatuses,
+		}, []string{"index ASC"}, 1)
+		if err != nil {
+			return fmt.Errorf("failed to get the uncommitted batches below the latest committed batch: %w", err)
+		}
+		if len(gaps) > 0 {
+			state.gap = gaps[0]
+		}
+	}
+
+	finalizedBatches, err := r.batchOrm.GetBatches(r.ctx, map[string]interface{}{"rollup_status = ?": types.RollupFinalized}, []string{"index DESC"}, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest finalized batch: %w", err)
+	}
+	if len(finalizedBatches) > 0 {
+		state.latestFinalized = finalizedBatches[0]
+		if state.onchainLastFinalizedIndex, err = r.lastFinalizedBatchIndex(l1Caller); err != nil {
+			return fmt.Errorf("failed to get the last finalized batch index on layer 1: %w", err)
+		}
+	}
+
+	if err := state.check(); err != nil {
+		return err
+	}
+	log.Info("local batches match layer 1", "last finalized batch index on layer 1", state.onchainLastFinalizedIndex)
+	return nil
+}
+
+// EnterRepairMode pauses committing and finalizing batches until an operator resumes them through the admin api.
+func (r *Layer2Relayer) EnterRepairMode(reason string) error {
+	operationPauseOrm := orm.NewOperationPause(r.db)
+	for _, operation := range []string{orm.OperationCommit, orm.OperationFinalize} {
+		if err := operationPauseOrm.SetOperationPause(r.ctx, operation, true, reason); err != nil {
+			return fmt.Errorf("failed to pause %v: %w", operation, err)
+		}
+	}
+	log.Warn("rollup relayer entered repair mode, committing and finalizing batches are paused", "reason", reason)
+	return nil
+}
+
+func (s *batchIntegrity) check() error {
+	if s.latestCommitted != nil {
+		index := s.latestCommitted.Index
+		switch {
+		case s.onchainCommittedHash == (common.Hash{}):
+			return fmt.Errorf("%w: batch %v is committed locally and not on layer 1", ErrBatchIntegrity, index)
+		case s.onchainCommittedHash != common.HexToHash(s.latestCommitted.Hash):
+			return fmt.Errorf("%w: batch %v is %v locally and %v on layer 1", ErrBatchIntegrity, index, s.latestCommitted.Hash, s.onchainCommittedHash.Hex())
+		}
+
+		// a batch being committed may be committed on layer 1 already, its commit tx isn't confirmed yet
+		if s.onchainNextHash != (common.Hash{}) {
+			if s.next == nil || types.RollupStatus(s.next.RollupStatus) != types.RollupCommitting || s.onchainNextHash != common.HexToHash(s.next.Hash) {
+				return fmt.Errorf("%w: batch %v is committed on layer 1 and not locally, the database may be restored from an old backup", ErrBatchIntegrity, index+1)
+			}
+		}
+
+		if s.gap != nil {
+			return fmt.Errorf("%w: batch %v is %v below the latest committed batch %v", ErrBatchIntegrity, s.gap.Index, types.RollupStatus(s.gap.RollupStatus), index)
+		}
+	}
+
+	if s.latestFinalized != nil && s.latestFinalized.Index > s.onchainLastFinalizedIndex {
+		return fmt.Errorf("%w: batch %v is finalized locally, the last finalized batch on layer 1 is %v", ErrBatchIntegrity, s.latestFinalized.Index, s.onchainLastFinalizedIndex)
+	}
+	return nil
+}
+
+// lastFinalizedBatchIndex returns the index of the last batch finalized on layer 1.
+func (r *Layer2Relayer) lastFinalizedBatchIndex(l1Caller ethereum.ContractCaller) (uint64, error) {
+	calldata, err := r.l1RollupABI.Pack("lastFinalizedBatchIndex")
+	if err != nil {
+		return 0, err
+	}
+	output, err := l1Caller.CallContract(r.ctx, ethereum.CallMsg{To: &r.cfg.RollupContractAddress, Data: calldata}, nil)
+	if err != nil {
+		return 0, err
+	}
+	outputs, err := r.l1RollupABI.Unpack("lastFinalizedBatchIndex", output)
+	if err != nil {
+		return 0, err
+	}
+	index, ok := outputs[0].(*big.Int)
+	if !ok {
+		return 0, fmt.Errorf("unexpected last finalized batch index output: %v", outputs[0])
+	}
+	if !index.IsUint64() {
+		return 0, fmt.Errorf("last finalized batch index is not uint64: %v", index)
+	}
+	return index.Uint64(), nil
+}
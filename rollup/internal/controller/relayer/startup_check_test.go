@@ -0,0 +1,46 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+func testBatchIntegrityCheck(t *testing.T) {
+	hash1, hash2, hash3 := common.HexToHash("0x1"), common.HexToHash("0x2"), common.HexToHash("0x3")
+	committed := &orm.Batch{Index: 1, Hash: hash1.Hex(), RollupStatus: int16(types.RollupCommitted)}
+	committing := &orm.Batch{Index: 2, Hash: hash2.Hex(), RollupStatus: int16(types.RollupCommitting)}
+	pending := &orm.Batch{Index: 2, Hash: hash2.Hex(), RollupStatus: int16(types.RollupPending)}
+	finalized := &orm.Batch{Index: 1, Hash: hash1.Hex(), RollupStatus: int16(types.RollupFinalized)}
+
+	tests := []struct {
+		name  string
+		state batchIntegrity
+		match bool
+	}{
+		{"Empty", batchIntegrity{}, true},
+		{"Match", batchIntegrity{latestCommitted: committed, onchainCommittedHash: hash1, next: pending, latestFinalized: finalized, onchainLastFinalizedIndex: 1}, true},
+		{"CommitTxNotConfirmed", batchIntegrity{latestCommitted: committed, onchainCommittedHash: hash1, next: committing, onchainNextHash: hash2}, true},
+		{"NotCommittedOnchain", batchIntegrity{latestCommitted: committed}, false},
+		{"ConflictingBatch", batchIntegrity{latestCommitted: committed, onchainCommittedHash: hash3}, false},
+		{"OnchainAhead", batchIntegrity{latestCommitted: committed, onchainCommittedHash: hash1, next: pending, onchainNextHash: hash2}, false},
+		{"OnchainAheadWithoutLocalBatch", batchIntegrity{latestCommitted: committed, onchainCommittedHash: hash1, onchainNextHash: hash2}, false},
+		{"Gap", batchIntegrity{latestCommitted: committed, onchainCommittedHash: hash1, gap: &orm.Batch{Index: 0, RollupStatus: int16(types.RollupCommitFailed)}}, false},
+		{"FinalizedAhead", batchIntegrity{latestFinalized: finalized}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.state.check()
+			if tt.match {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrBatchIntegrity)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package sender
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -10,13 +12,21 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 )
 
-func (s *Sender) estimateLegacyGas(to *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (*FeeData, error) {
-	gasPrice, err := s.client.SuggestGasPrice(s.ctx)
+const (
+	// minBlobGasPrice is the min blob base fee of EIP-4844.
+	minBlobGasPrice = 1
+	// blobGasPriceUpdateFraction is the blob base fee update fraction of EIP-4844.
+	blobGasPriceUpdateFraction = 3338477
+)
+
+func (s *Sender) estimateLegacyGas(ctx context.Context, to *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (*FeeData, error) {
+	gasPrice, err := s.client.SuggestGasPrice(ctx)
 	if err != nil {
 		log.Error("estimateLegacyGas SuggestGasPrice failure", "error", err)
 		return nil, err
 	}
-	gasLimit, _, err := s.estimateGasLimit(to, data, gasPrice, nil, nil, value, false)
+	useAccessList := s.config.TxType == AccessListTxType && !s.config.DisableAccessList
+	gasLimit, accessList, err := s.estimateGasLimit(ctx, to, data, gasPrice, nil, nil, value, useAccessList)
 	if err != nil {
 		log.Error("estimateLegacyGas estimateGasLimit failure", "gas price", gasPrice, "from", s.auth.From.String(),
 			"nonce", s.auth.Nonce.Uint64(), "to address", to.String(), "fallback gas limit", fallbackGasLimit, "error", err)
@@ -27,21 +37,25 @@ func (s *Sender) estimateLegacyGas(to *common.Address, value *big.Int, data []by
 	} else {
 		gasLimit = gasLimit * 12 / 10 // 20% extra gas to avoid out of gas error
 	}
-	return &FeeData{
+	feeData := &FeeData{
 		gasPrice: gasPrice,
 		gasLimit: gasLimit,
-	}, nil
+	}
+	if accessList != nil {
+		feeData.accessList = *accessList
+	}
+	return feeData, nil
 }
 
-func (s *Sender) estimateDynamicGas(to *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64) (*FeeData, error) {
-	gasTipCap, err := s.client.SuggestGasTipCap(s.ctx)
+func (s *Sender) estimateDynamicGas(ctx context.Context, to *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64) (*FeeData, error) {
+	gasTipCap, err := s.client.SuggestGasTipCap(ctx)
 	if err != nil {
 		log.Error("estimateDynamicGas SuggestGasTipCap failure", "error", err)
 		return nil, err
 	}
 
 	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(new(big.Int).SetUint64(baseFee), big.NewInt(2)))
-	gasLimit, accessList, err := s.estimateGasLimit(to, data, nil, gasTipCap, gasFeeCap, value, true)
+	gasLimit, accessList, err := s.estimateGasLimit(ctx, to, data, nil, gasTipCap, gasFeeCap, value, !s.config.DisableAccessList)
 	if err != nil {
 		log.Error("estimateDynamicGas estimateGasLimit failure",
 			"from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "to address", to.String(),
@@ -64,7 +78,52 @@ func (s *Sender) estimateDynamicGas(to *common.Address, value *big.Int, data []b
 	return feeData, nil
 }
 
-func (s *Sender) estimateGasLimit(to *common.Address, data []byte, gasPrice, gasTipCap, gasFeeCap, value *big.Int, useAccessList bool) (uint64, *types.AccessList, error) {
+// estimateBlobGas returns the fees of a blob-carrying transaction, whose gas limit can't be estimated without its blobs.
+func (s *Sender) estimateBlobGas(ctx context.Context, gasLimit uint64, baseFee, blobBaseFee uint64) (*FeeData, error) {
+	if blobBaseFee == 0 {
+		return nil, errors.New("blob base fee unavailable, the chain does not support blob-carrying transactions")
+	}
+	gasTipCap, err := s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		log.Error("estimateBlobGas SuggestGasTipCap failure", "error", err)
+		return nil, err
+	}
+
+	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(new(big.Int).SetUint64(baseFee), big.NewInt(2)))
+	blobGasFeeCap := new(big.Int).Mul(new(big.Int).SetUint64(blobBaseFee), big.NewInt(2))
+	if s.config.MaxBlobGasPrice > 0 && blobGasFeeCap.Cmp(new(big.Int).SetUint64(s.config.MaxBlobGasPrice)) > 0 {
+		return nil, fmt.Errorf("blob gas fee cap %v exceeds the max blob gas price %v", blobGasFeeCap, s.config.MaxBlobGasPrice)
+	}
+	return &FeeData{
+		gasLimit:      gasLimit,
+		gasTipCap:     gasTipCap,
+		gasFeeCap:     gasFeeCap,
+		blobGasFeeCap: blobGasFeeCap,
+	}, nil
+}
+
+// calcBlobFee calculates the blob base fee from the excess blob gas of the header, see EIP-4844.
+func calcBlobFee(excessBlobGas uint64) *big.Int {
+	return fakeExponential(big.NewInt(minBlobGasPrice), new(big.Int).SetUint64(excessBlobGas), big.NewInt(blobGasPriceUpdateFraction))
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using Taylor expansion.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
+
+func (s *Sender) estimateGasLimit(ctx context.Context, to *common.Address, data []byte, gasPrice, gasTipCap, gasFeeCap, value *big.Int, useAccessList bool) (uint64, *types.AccessList, error) {
 	msg := ethereum.CallMsg{
 		From:      s.auth.From,
 		To:        to,
@@ -74,7 +133,7 @@ func (s *Sender) estimateGasLimit(to *common.Address, data []byte, gasPrice, gas
 		Value:     value,
 		Data:      data,
 	}
-	gasLimitWithoutAccessList, err := s.client.EstimateGas(s.ctx, msg)
+	gasLimitWithoutAccessList, err := s.client.EstimateGas(ctx, msg)
 	if err != nil {
 		log.Error("estimateGasLimit EstimateGas failure without access list", "error", err)
 		return 0, nil, err
@@ -87,7 +146,7 @@ func (s *Sender) estimateGasLimit(to *common.Address, data []byte, gasPrice, gas
 	// Explicitly set a gas limit to prevent the "insufficient funds for gas * price + value" error.
 	// Because if msg.Gas remains unset, CreateAccessList defaults to using RPCGasCap(), which can be excessively high.
 	msg.Gas = gasLimitWithoutAccessList * 3
-	accessList, gasLimitWithAccessList, errStr, rpcErr := s.gethClient.CreateAccessList(s.ctx, msg)
+	accessList, gasLimitWithAccessList, errStr, rpcErr := s.gethClient.CreateAccessList(ctx, msg)
 	if rpcErr != nil {
 		log.Error("CreateAccessList RPC error", "error", rpcErr)
 		return gasLimitWithoutAccessList, nil, rpcErr
@@ -104,8 +163,11 @@ func (s *Sender) estimateGasLimit(to *common.Address, data []byte, gasPrice, gas
 	log.Info("gas", "senderName", s.name, "senderService", s.service, "gasLimitWithAccessList", gasLimitWithAccessList, "gasLimitWithoutAccessList", gasLimitWithoutAccessList, "accessList", accessList)
 
 	if gasLimitWithAccessList < gasLimitWithoutAccessList {
+		s.metrics.accessListUsedTotal.WithLabelValues(s.service, s.name).Inc()
+		s.metrics.accessListGasSavedTotal.WithLabelValues(s.service, s.name).Add(float64(gasLimitWithoutAccessList - gasLimitWithAccessList))
 		return gasLimitWithAccessList, accessList, nil
 	}
+	s.metrics.accessListSkippedTotal.WithLabelValues(s.service, s.name).Inc()
 	return gasLimitWithoutAccessList, nil, nil
 }
 
@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/holiman/uint256"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scroll-tech/go-ethereum"
 	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
 	"github.com/scroll-tech/go-ethereum/common"
 	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
@@ -53,6 +55,9 @@ type FeeData struct {
 	gasTipCap *big.Int
 	gasPrice  *big.Int
 
+	// blobGasFeeCap is the max fee per blob gas of a blob-carrying transaction.
+	blobGasFeeCap *big.Int
+
 	accessList gethTypes.AccessList
 
 	gasLimit uint64
@@ -69,6 +74,9 @@ type Sender struct {
 	name       string
 	senderType types.SenderType
 
+	// privateClient broadcasts the transactions instead of client if a private endpoint is configured.
+	privateClient *ethclient.Client
+
 	auth *bind.TransactOpts
 
 	db                    *gorm.DB
@@ -109,6 +117,14 @@ func NewSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.Pri
 	}
 	auth.Nonce = big.NewInt(int64(nonce))
 
+	var privateClient *ethclient.Client
+	if config.PrivateEndpoint != "" {
+		privateClient, err = ethclient.Dial(config.PrivateEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial private endpoint, err: %w", err)
+		}
+	}
+
 	sender := &Sender{
 		ctx:                   ctx,
 		config:                config,
@@ -123,6 +139,7 @@ func NewSender(ctx context.Context, config *config.SenderConfig, priv *ecdsa.Pri
 		name:                  name,
 		service:               service,
 		senderType:            senderType,
+		privateClient:         privateClient,
 	}
 	sender.metrics = initSenderMetrics(reg)
 
@@ -136,6 +153,16 @@ func (s *Sender) GetChainID() *big.Int {
 	return s.chainID
 }
 
+// GetAddress returns the address the sender sends the transactions from.
+func (s *Sender) GetAddress() common.Address {
+	return s.auth.From
+}
+
+// GetBalance returns the balance of the sender at the latest block.
+func (s *Sender) GetBalance(ctx context.Context) (*big.Int, error) {
+	return s.client.BalanceAt(ctx, s.auth.From, nil)
+}
+
 // Stop stop the sender module.
 func (s *Sender) Stop() {
 	close(s.stopCh)
@@ -153,15 +180,55 @@ func (s *Sender) SendConfirmation(cfm *Confirmation) {
 	s.confirmCh <- cfm
 }
 
-func (s *Sender) getFeeData(target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64) (*FeeData, error) {
+func (s *Sender) getFeeData(ctx context.Context, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64, baseFee uint64) (*FeeData, error) {
 	if s.config.TxType == DynamicFeeTxType {
-		return s.estimateDynamicGas(target, value, data, fallbackGasLimit, baseFee)
+		return s.estimateDynamicGas(ctx, target, value, data, fallbackGasLimit, baseFee)
 	}
-	return s.estimateLegacyGas(target, value, data, fallbackGasLimit)
+	return s.estimateLegacyGas(ctx, target, value, data, fallbackGasLimit)
+}
+
+// broadcast sends the signed transaction through the private endpoint if configured, so it is not
+// exposed in the public mempool before being included.
+func (s *Sender) broadcast(ctx context.Context, tx *gethTypes.Transaction) error {
+	if s.privateClient == nil {
+		return s.client.SendTransaction(ctx, tx)
+	}
+
+	err := s.privateClient.SendTransaction(ctx, tx)
+	if err == nil {
+		s.metrics.privateSendTransactionTotal.WithLabelValues(s.service, s.name).Inc()
+		return nil
+	}
+	s.metrics.privateSendTransactionFailureTotal.WithLabelValues(s.service, s.name).Inc()
+	if !s.config.PrivateFallbackToPublic {
+		return err
+	}
+
+	log.Warn("failed to send tx through the private endpoint, falling back to the public endpoint", "tx hash", tx.Hash().String(), "err", err)
+	return s.client.SendTransaction(ctx, tx)
 }
 
 // SendTransaction send a signed L2tL1 transaction.
-func (s *Sender) SendTransaction(contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+func (s *Sender) SendTransaction(ctx context.Context, contextID string, target *common.Address, value *big.Int, data []byte, fallbackGasLimit uint64) (common.Hash, error) {
+	return s.sendTransaction(ctx, contextID, target, value, data, nil, fallbackGasLimit)
+}
+
+// SendBlobTransaction sends a signed blob-carrying transaction with the blobs of the sidecar. The execution of
+// a blob-carrying transaction can't be estimated without its blobs, so its gas limit is gasLimit.
+func (s *Sender) SendBlobTransaction(ctx context.Context, contextID string, target *common.Address, data []byte, sidecar *gethTypes.BlobTxSidecar, gasLimit uint64) (common.Hash, error) {
+	if s.config.TxType != DynamicFeeTxType {
+		return common.Hash{}, fmt.Errorf("blob-carrying transactions need the %v tx type, got: %v", DynamicFeeTxType, s.config.TxType)
+	}
+	if sidecar == nil || len(sidecar.Blobs) == 0 {
+		return common.Hash{}, errors.New("blob-carrying transaction without blobs")
+	}
+	if gasLimit == 0 {
+		return common.Hash{}, errors.New("blob-carrying transaction without gas limit")
+	}
+	return s.sendTransaction(ctx, contextID, target, big.NewInt(0), data, sidecar, gasLimit)
+}
+
+func (s *Sender) sendTransaction(ctx context.Context, contextID string, target *common.Address, value *big.Int, data []byte, sidecar *gethTypes.BlobTxSidecar, fallbackGasLimit uint64) (common.Hash, error) {
 	s.metrics.sendTransactionTotal.WithLabelValues(s.service, s.name).Inc()
 	var (
 		feeData *FeeData
@@ -169,32 +236,56 @@ func (s *Sender) SendTransaction(contextID string, target *common.Address, value
 		err     error
 	)
 
-	blockNumber, baseFee, err := s.getBlockNumberAndBaseFee(s.ctx)
+	blockNumber, baseFee, blobBaseFee, err := s.getBlockNumberAndBaseFee(ctx)
 	if err != nil {
 		log.Error("failed to get block number and base fee", "error", err)
 		return common.Hash{}, fmt.Errorf("failed to get block number and base fee, err: %w", err)
 	}
 
-	if feeData, err = s.getFeeData(target, value, data, fallbackGasLimit, baseFee); err != nil {
+	if sidecar != nil {
+		feeData, err = s.estimateBlobGas(ctx, fallbackGasLimit, baseFee, blobBaseFee)
+	} else {
+		feeData, err = s.getFeeData(ctx, target, value, data, fallbackGasLimit, baseFee)
+	}
+	if err != nil {
 		s.metrics.sendTransactionFailureGetFee.WithLabelValues(s.service, s.name).Inc()
 		log.Error("failed to get fee data", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "fallback gas limit", fallbackGasLimit, "err", err)
 		return common.Hash{}, fmt.Errorf("failed to get fee data, err: %w", err)
 	}
 
-	if tx, err = s.createAndSendTx(feeData, target, value, data, nil); err != nil {
+	if tx, err = s.createAndSendTx(ctx, feeData, target, value, data, sidecar, nil); err != nil {
 		s.metrics.sendTransactionFailureSendTx.WithLabelValues(s.service, s.name).Inc()
 		log.Error("failed to create and send tx (non-resubmit case)", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "err", err)
 		return common.Hash{}, fmt.Errorf("failed to create and send transaction, err: %w", err)
 	}
 
-	if err = s.pendingTransactionOrm.InsertPendingTransaction(s.ctx, contextID, s.getSenderMeta(), tx, blockNumber); err != nil {
+	// the tx is broadcast, record it even if the round calling the sender is cancelled meanwhile
+	recordCtx, cancel := context.WithTimeout(s.ctx, recordPendingTransactionTimeout)
+	defer cancel()
+	if err = s.pendingTransactionOrm.InsertPendingTransaction(recordCtx, contextID, s.getSenderMeta(), tx, blockNumber); err != nil {
 		log.Error("failed to insert transaction", "from", s.auth.From.String(), "nonce", s.auth.Nonce.Uint64(), "err", err)
 		return common.Hash{}, fmt.Errorf("failed to insert transaction, err: %w", err)
 	}
 	return tx.Hash(), nil
 }
 
-func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value *big.Int, data []byte, overrideNonce *uint64) (*gethTypes.Transaction, error) {
+// SimulateTransaction executes the call against the latest state without sending it,
+// so callers can detect reverting transactions before paying for them.
+func (s *Sender) SimulateTransaction(ctx context.Context, target *common.Address, value *big.Int, data []byte) error {
+	msg := ethereum.CallMsg{
+		From:  s.auth.From,
+		To:    target,
+		Value: value,
+		Data:  data,
+	}
+	if _, err := s.client.CallContract(ctx, msg, nil); err != nil {
+		log.Warn("failed to simulate transaction", "from", s.auth.From.String(), "to", target, "err", err)
+		return fmt.Errorf("failed to simulate transaction, err: %w", err)
+	}
+	return nil
+}
+
+func (s *Sender) createAndSendTx(ctx context.Context, feeData *FeeData, target *common.Address, value *big.Int, data []byte, sidecar *gethTypes.BlobTxSidecar, overrideNonce *uint64) (*gethTypes.Transaction, error) {
 	var (
 		nonce  = s.auth.Nonce.Uint64()
 		txData gethTypes.TxData
@@ -205,8 +296,29 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 		nonce = *overrideNonce
 	}
 
-	switch s.config.TxType {
-	case LegacyTxType:
+	switch {
+	case sidecar != nil:
+		if target == nil {
+			return nil, errors.New("blob-carrying transaction without target")
+		}
+		txData = &gethTypes.BlobTx{
+			Nonce:      nonce,
+			To:         *target,
+			Data:       common.CopyBytes(data),
+			Gas:        feeData.gasLimit,
+			AccessList: feeData.accessList,
+			Value:      uint256.MustFromBig(value),
+			ChainID:    uint256.MustFromBig(s.chainID),
+			GasTipCap:  uint256.MustFromBig(feeData.gasTipCap),
+			GasFeeCap:  uint256.MustFromBig(feeData.gasFeeCap),
+			BlobFeeCap: uint256.MustFromBig(feeData.blobGasFeeCap),
+			BlobHashes: sidecar.BlobHashes(),
+			Sidecar:    sidecar,
+			V:          new(uint256.Int),
+			R:          new(uint256.Int),
+			S:          new(uint256.Int),
+		}
+	case s.config.TxType == LegacyTxType:
 		// for ganache mock node
 		txData = &gethTypes.LegacyTx{
 			Nonce:    nonce,
@@ -219,7 +331,7 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 			R:        new(big.Int),
 			S:        new(big.Int),
 		}
-	case AccessListTxType:
+	case s.config.TxType == AccessListTxType:
 		txData = &gethTypes.AccessListTx{
 			ChainID:    s.chainID,
 			Nonce:      nonce,
@@ -257,12 +369,14 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 		return nil, err
 	}
 
-	if err = s.client.SendTransaction(s.ctx, tx); err != nil {
+	if err = s.broadcast(ctx, tx); err != nil {
 		log.Error("failed to send tx", "tx hash", tx.Hash().String(), "from", s.auth.From.String(), "nonce", tx.Nonce(), "err", err)
 		// Check if contain nonce, and reset nonce
 		// only reset nonce when it is not from resubmit
 		if strings.Contains(err.Error(), "nonce") && overrideNonce == nil {
-			s.resetNonce(context.Background())
+			ctx, cancel := context.WithTimeout(ctx, resetNonceTimeout)
+			s.resetNonce(ctx)
+			cancel()
 		}
 		return nil, err
 	}
@@ -275,6 +389,10 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 		s.metrics.currentGasFeeCap.WithLabelValues(s.service, s.name).Set(float64(feeData.gasFeeCap.Uint64()))
 	}
 
+	if feeData.blobGasFeeCap != nil {
+		s.metrics.currentBlobGasFeeCap.WithLabelValues(s.service, s.name).Set(float64(feeData.blobGasFeeCap.Uint64()))
+	}
+
 	if feeData.gasPrice != nil {
 		s.metrics.currentGasPrice.WithLabelValues(s.service, s.name).Set(float64(feeData.gasPrice.Uint64()))
 	}
@@ -288,6 +406,15 @@ func (s *Sender) createAndSendTx(feeData *FeeData, target *common.Address, value
 	return tx, nil
 }
 
+// resetNonceTimeout bounds the nonce query made after a failed send.
+const resetNonceTimeout = 10 * time.Second
+
+// recordPendingTransactionTimeout bounds the insert of a broadcast transaction, which is detached from the caller's context.
+const recordPendingTransactionTimeout = 10 * time.Second
+
+// checkPendingTransactionTimeout bounds a round of checkPendingTransaction, the sender context has no deadline.
+const checkPendingTransactionTimeout = time.Minute
+
 // resetNonce reset nonce if send signed tx failed.
 func (s *Sender) resetNonce(ctx context.Context) {
 	nonce, err := s.client.PendingNonceAt(ctx, s.auth.From)
@@ -298,11 +425,17 @@ func (s *Sender) resetNonce(ctx context.Context) {
 	s.auth.Nonce = big.NewInt(int64(nonce))
 }
 
-func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64) (*gethTypes.Transaction, error) {
+func (s *Sender) resubmitTransaction(ctx context.Context, tx *gethTypes.Transaction, baseFee, blobBaseFee uint64) (*gethTypes.Transaction, error) {
 	escalateMultipleNum := new(big.Int).SetUint64(s.config.EscalateMultipleNum)
 	escalateMultipleDen := new(big.Int).SetUint64(s.config.EscalateMultipleDen)
 	maxGasPrice := new(big.Int).SetUint64(s.config.MaxGasPrice)
 
+	sidecar := tx.BlobTxSidecar()
+	if sidecar != nil && s.config.EscalateMultipleNum < 2*s.config.EscalateMultipleDen {
+		// the blob pool replaces a blob-carrying transaction only if all of its fees are doubled
+		escalateMultipleNum, escalateMultipleDen = big.NewInt(2), big.NewInt(1)
+	}
+
 	txInfo := map[string]interface{}{
 		"tx_hash": tx.Hash().String(),
 		"tx_type": s.config.TxType,
@@ -312,6 +445,20 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64)
 
 	var feeData FeeData
 	feeData.gasLimit = tx.Gas()
+	// keep the access list, the original gas limit was estimated with it.
+	feeData.accessList = tx.AccessList()
+	if s.config.DisableAccessList && len(feeData.accessList) > 0 {
+		// access lists were disabled since the original submission, the gas limit is estimated again without it.
+		gasLimit, _, err := s.estimateGasLimit(ctx, tx.To(), tx.Data(), nil, nil, nil, tx.Value(), false)
+		if err != nil {
+			log.Error("failed to estimate gas limit without access list (resubmit case)", "from", s.auth.From.String(), "nonce", tx.Nonce(), "err", err)
+			return nil, err
+		}
+		feeData.gasLimit = gasLimit * 12 / 10 // 20% extra gas to avoid out of gas error
+		feeData.accessList = nil
+		txInfo["original_gas_limit"] = tx.Gas()
+		txInfo["adjusted_gas_limit"] = feeData.gasLimit
+	}
 	switch s.config.TxType {
 	case LegacyTxType, AccessListTxType: // `LegacyTxType`is for ganache mock node
 		originalGasPrice := tx.GasPrice()
@@ -375,11 +522,29 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64)
 		txInfo["adjusted_gas_fee_cap"] = gasFeeCap.Uint64()
 	}
 
+	if sidecar != nil {
+		originalBlobGasFeeCap := tx.BlobGasFeeCap()
+		blobGasFeeCap := new(big.Int).Mul(originalBlobGasFeeCap, escalateMultipleNum)
+		blobGasFeeCap = blobGasFeeCap.Div(blobGasFeeCap, escalateMultipleDen)
+		// adjust for rising blob base fee
+		currentBlobGasFeeCap := new(big.Int).Mul(new(big.Int).SetUint64(blobBaseFee), big.NewInt(2))
+		if blobGasFeeCap.Cmp(currentBlobGasFeeCap) < 0 {
+			blobGasFeeCap = currentBlobGasFeeCap
+		}
+		// but don't exceed maxBlobGasPrice
+		if s.config.MaxBlobGasPrice > 0 && blobGasFeeCap.Cmp(new(big.Int).SetUint64(s.config.MaxBlobGasPrice)) > 0 {
+			blobGasFeeCap = new(big.Int).SetUint64(s.config.MaxBlobGasPrice)
+		}
+		feeData.blobGasFeeCap = blobGasFeeCap
+		txInfo["original_blob_gas_fee_cap"] = originalBlobGasFeeCap.Uint64()
+		txInfo["adjusted_blob_gas_fee_cap"] = blobGasFeeCap.Uint64()
+	}
+
 	log.Info("Transaction gas adjustment details", "service", s.service, "name", s.name, "txInfo", txInfo)
 
 	nonce := tx.Nonce()
 	s.metrics.resubmitTransactionTotal.WithLabelValues(s.service, s.name).Inc()
-	tx, err := s.createAndSendTx(&feeData, tx.To(), tx.Value(), tx.Data(), &nonce)
+	tx, err := s.createAndSendTx(ctx, &feeData, tx.To(), tx.Value(), tx.Data(), sidecar, &nonce)
 	if err != nil {
 		log.Error("failed to create and send tx (resubmit case)", "from", s.auth.From.String(), "nonce", nonce, "err", err)
 		return nil, err
@@ -389,22 +554,22 @@ func (s *Sender) resubmitTransaction(tx *gethTypes.Transaction, baseFee uint64)
 
 // checkPendingTransaction checks the confirmation status of pending transactions against the latest confirmed block number.
 // If a transaction hasn't been confirmed after a certain number of blocks, it will be resubmitted with an increased gas price.
-func (s *Sender) checkPendingTransaction() {
+func (s *Sender) checkPendingTransaction(ctx context.Context) {
 	s.metrics.senderCheckPendingTransactionTotal.WithLabelValues(s.service, s.name).Inc()
 
-	blockNumber, baseFee, err := s.getBlockNumberAndBaseFee(s.ctx)
+	blockNumber, baseFee, blobBaseFee, err := s.getBlockNumberAndBaseFee(ctx)
 	if err != nil {
 		log.Error("failed to get block number and base fee", "error", err)
 		return
 	}
 
-	transactionsToCheck, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(s.ctx, s.senderType, 100)
+	transactionsToCheck, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(ctx, s.senderType, 100)
 	if err != nil {
 		log.Error("failed to load pending transactions", "sender meta", s.getSenderMeta(), "err", err)
 		return
 	}
 
-	confirmed, err := utils.GetLatestConfirmedBlockNumber(s.ctx, s.client, s.config.Confirmations)
+	confirmed, err := utils.GetLatestConfirmedBlockNumber(ctx, s.client, s.config.Confirmations)
 	if err != nil {
 		log.Error("failed to get latest confirmed block number", "confirmations", s.config.Confirmations, "err", err)
 		return
@@ -417,17 +582,17 @@ func (s *Sender) checkPendingTransaction() {
 			continue
 		}
 
-		receipt, err := s.client.TransactionReceipt(s.ctx, tx.Hash())
+		receipt, err := s.client.TransactionReceipt(ctx, tx.Hash())
 		if (err == nil) && (receipt != nil) { // tx confirmed.
 			if receipt.BlockNumber.Uint64() <= confirmed {
 				err := s.db.Transaction(func(dbTX *gorm.DB) error {
 					// Update the status of the transaction to TxStatusConfirmed.
-					if err := s.pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(s.ctx, tx.Hash(), types.TxStatusConfirmed, dbTX); err != nil {
+					if err := s.pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(ctx, tx.Hash(), types.TxStatusConfirmed, dbTX); err != nil {
 						log.Error("failed to update transaction status by tx hash", "hash", tx.Hash().String(), "sender meta", s.getSenderMeta(), "from", s.auth.From.String(), "nonce", tx.Nonce(), "err", err)
 						return err
 					}
 					// Update other transactions with the same nonce and sender address as failed.
-					if err := s.pendingTransactionOrm.UpdateOtherTransactionsAsFailedByNonce(s.ctx, txnToCheck.SenderAddress, tx.Nonce(), tx.Hash(), dbTX); err != nil {
+					if err := s.pendingTransactionOrm.UpdateOtherTransactionsAsFailedByNonce(ctx, txnToCheck.SenderAddress, tx.Nonce(), tx.Hash(), dbTX); err != nil {
 						log.Error("failed to update other transactions as failed by nonce", "senderAddress", txnToCheck.SenderAddress, "nonce", tx.Nonce(), "excludedTxHash", tx.Hash(), "err", err)
 						return err
 					}
@@ -450,7 +615,7 @@ func (s *Sender) checkPendingTransaction() {
 			s.config.EscalateBlocks+txnToCheck.SubmitBlockNumber <= blockNumber {
 			// It's possible that the pending transaction was marked as failed earlier in this loop (e.g., if one of its replacements has already been confirmed).
 			// Therefore, we fetch the current transaction status again for accuracy before proceeding.
-			status, err := s.pendingTransactionOrm.GetTxStatusByTxHash(s.ctx, tx.Hash())
+			status, err := s.pendingTransactionOrm.GetTxStatusByTxHash(ctx, tx.Hash())
 			if err != nil {
 				log.Error("failed to get transaction status by tx hash", "hash", tx.Hash().String(), "err", err)
 				return
@@ -470,17 +635,17 @@ func (s *Sender) checkPendingTransaction() {
 				"currentBlockNumber", blockNumber,
 				"escalateBlocks", s.config.EscalateBlocks)
 
-			if newTx, err := s.resubmitTransaction(tx, baseFee); err != nil {
+			if newTx, err := s.resubmitTransaction(ctx, tx, baseFee, blobBaseFee); err != nil {
 				s.metrics.resubmitTransactionFailedTotal.WithLabelValues(s.service, s.name).Inc()
 				log.Error("failed to resubmit transaction", "context ID", txnToCheck.ContextID, "sender meta", s.getSenderMeta(), "from", s.auth.From.String(), "nonce", tx.Nonce(), "err", err)
 			} else {
 				err := s.db.Transaction(func(dbTX *gorm.DB) error {
 					// Update the status of the original transaction as replaced, while still checking its confirmation status.
-					if err := s.pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(s.ctx, tx.Hash(), types.TxStatusReplaced, dbTX); err != nil {
+					if err := s.pendingTransactionOrm.UpdatePendingTransactionStatusByTxHash(ctx, tx.Hash(), types.TxStatusReplaced, dbTX); err != nil {
 						return fmt.Errorf("failed to update status of transaction with hash %s to TxStatusReplaced, err: %w", tx.Hash().String(), err)
 					}
 					// Record the new transaction that has replaced the original one.
-					if err := s.pendingTransactionOrm.InsertPendingTransaction(s.ctx, txnToCheck.ContextID, s.getSenderMeta(), newTx, blockNumber, dbTX); err != nil {
+					if err := s.pendingTransactionOrm.InsertPendingTransaction(ctx, txnToCheck.ContextID, s.getSenderMeta(), newTx, blockNumber, dbTX); err != nil {
 						return fmt.Errorf("failed to insert new pending transaction with context ID: %s, nonce: %d, hash: %v, previous block number: %v, current block number: %v, err: %w", txnToCheck.ContextID, newTx.Nonce(), newTx.Hash().String(), txnToCheck.SubmitBlockNumber, blockNumber, err)
 					}
 					return nil
@@ -502,7 +667,9 @@ func (s *Sender) loop(ctx context.Context) {
 	for {
 		select {
 		case <-checkTick.C:
-			s.checkPendingTransaction()
+			checkCtx, cancel := context.WithTimeout(ctx, checkPendingTransactionTimeout)
+			s.checkPendingTransaction(checkCtx)
+			cancel()
 		case <-ctx.Done():
 			return
 		case <-s.stopCh:
@@ -520,19 +687,24 @@ func (s *Sender) getSenderMeta() *orm.SenderMeta {
 	}
 }
 
-func (s *Sender) getBlockNumberAndBaseFee(ctx context.Context) (uint64, uint64, error) {
+// getBlockNumberAndBaseFee returns the latest block number, its base fee and its blob base fee,
+// the blob base fee is 0 before EIP-4844.
+func (s *Sender) getBlockNumberAndBaseFee(ctx context.Context) (uint64, uint64, uint64, error) {
 	header, err := s.client.HeaderByNumber(ctx, nil)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get header by number, err: %w", err)
+		return 0, 0, 0, fmt.Errorf("failed to get header by number, err: %w", err)
 	}
 
-	var baseFeePerGas uint64
+	var baseFeePerGas, blobBaseFeePerGas uint64
 	if s.config.TxType == DynamicFeeTxType {
 		if header.BaseFee != nil {
 			baseFeePerGas = header.BaseFee.Uint64()
 		} else {
-			return 0, 0, errors.New("dynamic fee tx type not supported: header.BaseFee is nil")
+			return 0, 0, 0, errors.New("dynamic fee tx type not supported: header.BaseFee is nil")
+		}
+		if header.ExcessBlobGas != nil {
+			blobBaseFeePerGas = calcBlobFee(*header.ExcessBlobGas).Uint64()
 		}
 	}
-	return header.Number.Uint64(), baseFeePerGas, nil
+	return header.Number.Uint64(), baseFeePerGas, blobBaseFeePerGas, nil
 }
@@ -16,8 +16,14 @@ type senderMetrics struct {
 	resubmitTransactionFailedTotal     *prometheus.CounterVec
 	currentGasFeeCap                   *prometheus.GaugeVec
 	currentGasTipCap                   *prometheus.GaugeVec
+	currentBlobGasFeeCap               *prometheus.GaugeVec
 	currentGasPrice                    *prometheus.GaugeVec
 	currentGasLimit                    *prometheus.GaugeVec
+	accessListUsedTotal                *prometheus.CounterVec
+	accessListSkippedTotal             *prometheus.CounterVec
+	accessListGasSavedTotal            *prometheus.CounterVec
+	privateSendTransactionTotal        *prometheus.CounterVec
+	privateSendTransactionFailureTotal *prometheus.CounterVec
 }
 
 var (
@@ -64,6 +70,30 @@ func initSenderMetrics(reg prometheus.Registerer) *senderMetrics {
 				Name: "rollup_sender_gas_limit",
 				Help: "The gas limit of current transaction.",
 			}, []string{"service", "name"}),
+			accessListUsedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_access_list_used_total",
+				Help: "The total number of transactions sent with an access list because it reduced gas.",
+			}, []string{"service", "name"}),
+			accessListSkippedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_access_list_skipped_total",
+				Help: "The total number of transactions sent without an access list because it did not reduce gas.",
+			}, []string{"service", "name"}),
+			accessListGasSavedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_access_list_gas_saved_total",
+				Help: "The total estimated gas saved by attaching access lists.",
+			}, []string{"service", "name"}),
+			privateSendTransactionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_private_send_transaction_total",
+				Help: "The total number of transactions sent through the private endpoint.",
+			}, []string{"service", "name"}),
+			privateSendTransactionFailureTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_sender_private_send_transaction_failure_total",
+				Help: "The total number of transactions rejected by the private endpoint.",
+			}, []string{"service", "name"}),
+			currentBlobGasFeeCap: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+				Name: "rollup_sender_blob_gas_fee_cap",
+				Help: "The blob gas fee cap of the latest blob-carrying transaction.",
+			}, []string{"service", "name"}),
 			senderCheckPendingTransactionTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 				Name: "rollup_sender_check_pending_transaction_total",
 				Help: "The total number of check pending transaction.",
@@ -10,10 +10,12 @@ import (
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
 	"github.com/scroll-tech/go-ethereum/common"
 	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/crypto/kzg4844"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/log"
 	"github.com/scroll-tech/go-ethereum/rpc"
@@ -99,6 +101,9 @@ func TestSender(t *testing.T) {
 	t.Run("test fallback gas limit", testFallbackGasLimit)
 	t.Run("test send and retrieve transaction", testSendAndRetrieveTransaction)
 	t.Run("test access list transaction gas limit", testAccessListTransactionGasLimit)
+	t.Run("test disable access list", testDisableAccessList)
+	t.Run("test resubmit transaction with access list disabled", testResubmitTransactionWithAccessListDisabled)
+	t.Run("test send blob transaction", testSendBlobTransaction)
 	t.Run("test resubmit zero gas price transaction", testResubmitZeroGasPriceTransaction)
 	t.Run("test resubmit non-zero gas price transaction", testResubmitNonZeroGasPriceTransaction)
 	t.Run("test resubmit under priced transaction", testResubmitUnderpricedTransaction)
@@ -143,7 +148,7 @@ func testSendAndRetrieveTransaction(t *testing.T) {
 		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeUnknown, db, nil)
 		assert.NoError(t, err)
 
-		hash, err := s.SendTransaction("0", &common.Address{}, big.NewInt(0), nil, 0)
+		hash, err := s.SendTransaction(context.Background(), "0", &common.Address{}, big.NewInt(0), nil, 0)
 		assert.NoError(t, err)
 		txs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
 		assert.NoError(t, err)
@@ -176,7 +181,7 @@ func testFallbackGasLimit(t *testing.T) {
 		assert.NoError(t, err)
 
 		// FallbackGasLimit = 0
-		txHash0, err := s.SendTransaction("0", &common.Address{}, big.NewInt(0), nil, 0)
+		txHash0, err := s.SendTransaction(context.Background(), "0", &common.Address{}, big.NewInt(0), nil, 0)
 		assert.NoError(t, err)
 		tx0, _, err := client.TransactionByHash(context.Background(), txHash0)
 		assert.NoError(t, err)
@@ -184,12 +189,12 @@ func testFallbackGasLimit(t *testing.T) {
 
 		// FallbackGasLimit = 100000
 		patchGuard := gomonkey.ApplyPrivateMethod(s, "estimateGasLimit",
-			func(contract *common.Address, data []byte, gasPrice, gasTipCap, gasFeeCap, value *big.Int) (uint64, *gethTypes.AccessList, error) {
+			func(_ *Sender, ctx context.Context, contract *common.Address, data []byte, gasPrice, gasTipCap, gasFeeCap, value *big.Int, useAccessList bool) (uint64, *gethTypes.AccessList, error) {
 				return 0, nil, errors.New("estimateGasLimit error")
 			},
 		)
 
-		txHash1, err := s.SendTransaction("1", &common.Address{}, big.NewInt(0), nil, 100000)
+		txHash1, err := s.SendTransaction(context.Background(), "1", &common.Address{}, big.NewInt(0), nil, 100000)
 		assert.NoError(t, err)
 		tx1, _, err := client.TransactionByHash(context.Background(), txHash1)
 		assert.NoError(t, err)
@@ -200,6 +205,55 @@ func testFallbackGasLimit(t *testing.T) {
 	}
 }
 
+func testSendBlobTransaction(t *testing.T) {
+	sidecar := &gethTypes.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{{}},
+		Commitments: []kzg4844.Commitment{{}},
+		Proofs:      []kzg4844.Proof{{}},
+	}
+	for _, txType := range txTypes {
+		sqlDB, err := db.DB()
+		assert.NoError(t, err)
+		assert.NoError(t, migrate.ResetDB(sqlDB))
+
+		cfgCopy := *cfg.L1Config.RelayerConfig.SenderConfig
+		cfgCopy.TxType = txType
+		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeUnknown, db, nil)
+		assert.NoError(t, err)
+
+		_, err = s.SendBlobTransaction(context.Background(), "test", &mockL1ContractsAddress, nil, sidecar, 100000)
+		if txType != DynamicFeeTxType {
+			assert.ErrorContains(t, err, "blob-carrying transactions need the DynamicFeeTx tx type")
+			s.Stop()
+			continue
+		}
+		// the test chain is before EIP-4844
+		assert.ErrorContains(t, err, "blob base fee unavailable")
+
+		_, err = s.SendBlobTransaction(context.Background(), "test", &mockL1ContractsAddress, nil, &gethTypes.BlobTxSidecar{}, 100000)
+		assert.ErrorContains(t, err, "without blobs")
+		_, err = s.SendBlobTransaction(context.Background(), "test", &mockL1ContractsAddress, nil, sidecar, 0)
+		assert.ErrorContains(t, err, "without gas limit")
+		s.Stop()
+	}
+}
+
+func TestCalcBlobFee(t *testing.T) {
+	// the vectors of EIP-4844
+	tests := []struct {
+		excessBlobGas uint64
+		blobFee       int64
+	}{
+		{0, 1},
+		{2314057, 1},
+		{2314058, 2},
+		{10 * 1024 * 1024, 23},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, big.NewInt(tt.blobFee), calcBlobFee(tt.excessBlobGas), "excess blob gas: %v", tt.excessBlobGas)
+	}
+}
+
 func testResubmitZeroGasPriceTransaction(t *testing.T) {
 	for _, txType := range txTypes {
 		sqlDB, err := db.DB()
@@ -216,11 +270,11 @@ func testResubmitZeroGasPriceTransaction(t *testing.T) {
 			gasFeeCap: big.NewInt(0),
 			gasLimit:  50000,
 		}
-		tx, err := s.createAndSendTx(feeData, &common.Address{}, big.NewInt(0), nil, nil)
+		tx, err := s.createAndSendTx(context.Background(), feeData, &common.Address{}, big.NewInt(0), nil, nil, nil)
 		assert.NoError(t, err)
 		assert.NotNil(t, tx)
 		// Increase at least 1 wei in gas price, gas tip cap and gas fee cap.
-		_, err = s.resubmitTransaction(tx, 0)
+		_, err = s.resubmitTransaction(context.Background(), tx, 0, 0)
 		assert.NoError(t, err)
 		s.Stop()
 	}
@@ -243,12 +297,12 @@ func testAccessListTransactionGasLimit(t *testing.T) {
 		data, err := l2GasOracleABI.Pack("setL2BaseFee", big.NewInt(2333))
 		assert.NoError(t, err)
 
-		gasLimit, accessList, err := s.estimateGasLimit(&mockL1ContractsAddress, data, big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(0), true)
+		gasLimit, accessList, err := s.estimateGasLimit(context.Background(), &mockL1ContractsAddress, data, big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(0), true)
 		assert.NoError(t, err)
 		assert.Equal(t, uint64(43472), gasLimit)
 		assert.NotNil(t, accessList)
 
-		gasLimit, accessList, err = s.estimateGasLimit(&mockL1ContractsAddress, data, big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(0), false)
+		gasLimit, accessList, err = s.estimateGasLimit(context.Background(), &mockL1ContractsAddress, data, big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(100000000000), big.NewInt(0), false)
 		assert.NoError(t, err)
 		assert.Equal(t, uint64(43949), gasLimit)
 		assert.Nil(t, accessList)
@@ -257,6 +311,93 @@ func testAccessListTransactionGasLimit(t *testing.T) {
 	}
 }
 
+func testDisableAccessList(t *testing.T) {
+	l1Client, err := base.L1Client()
+	assert.NoError(t, err)
+
+	l2GasOracleABI, err := bridgeAbi.L2GasPriceOracleMetaData.GetAbi()
+	assert.NoError(t, err)
+	data, err := l2GasOracleABI.Pack("setL2BaseFee", big.NewInt(2333))
+	assert.NoError(t, err)
+
+	for _, txType := range []string{"AccessListTx", "DynamicFeeTx"} {
+		for _, disableAccessList := range []bool{false, true} {
+			sqlDB, err := db.DB()
+			assert.NoError(t, err)
+			assert.NoError(t, migrate.ResetDB(sqlDB))
+
+			cfgCopy := *cfg.L1Config.RelayerConfig.SenderConfig
+			cfgCopy.TxType = txType
+			cfgCopy.DisableAccessList = disableAccessList
+			name := fmt.Sprintf("access_list_%s_%v", txType, disableAccessList)
+			s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", name, types.SenderTypeUnknown, db, nil)
+			assert.NoError(t, err)
+
+			hash, err := s.SendTransaction(context.Background(), "test", &mockL1ContractsAddress, big.NewInt(0), data, 0)
+			assert.NoError(t, err)
+			tx, _, err := l1Client.TransactionByHash(context.Background(), hash)
+			assert.NoError(t, err)
+
+			usedTotal := testutil.ToFloat64(s.metrics.accessListUsedTotal.WithLabelValues("test", name))
+			skippedTotal := testutil.ToFloat64(s.metrics.accessListSkippedTotal.WithLabelValues("test", name))
+			gasSavedTotal := testutil.ToFloat64(s.metrics.accessListGasSavedTotal.WithLabelValues("test", name))
+			if disableAccessList {
+				// the access list is not even created, the gas limit is estimated without it
+				assert.Empty(t, tx.AccessList())
+				assert.Equal(t, uint64(43949*12/10), tx.Gas())
+				assert.Equal(t, float64(0), usedTotal)
+				assert.Equal(t, float64(0), skippedTotal)
+				assert.Equal(t, float64(0), gasSavedTotal)
+			} else {
+				assert.Equal(t, uint64(43472*12/10), tx.Gas())
+				assert.Equal(t, float64(1), usedTotal)
+				assert.Equal(t, float64(0), skippedTotal)
+				assert.Equal(t, float64(43949-43472), gasSavedTotal)
+			}
+			s.Stop()
+		}
+	}
+}
+
+func testResubmitTransactionWithAccessListDisabled(t *testing.T) {
+	for _, txType := range []string{"AccessListTx", "DynamicFeeTx"} {
+		sqlDB, err := db.DB()
+		assert.NoError(t, err)
+		assert.NoError(t, migrate.ResetDB(sqlDB))
+
+		cfgCopy := *cfg.L1Config.RelayerConfig.SenderConfig
+		cfgCopy.EscalateMultipleNum = 110
+		cfgCopy.EscalateMultipleDen = 100
+		cfgCopy.TxType = txType
+		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeUnknown, db, nil)
+		assert.NoError(t, err)
+		feeData := &FeeData{
+			gasPrice:   big.NewInt(100000),
+			gasTipCap:  big.NewInt(100000),
+			gasFeeCap:  big.NewInt(100000),
+			gasLimit:   50000,
+			accessList: gethTypes.AccessList{{Address: common.HexToAddress("0x1"), StorageKeys: []common.Hash{{}}}},
+		}
+		tx, err := s.createAndSendTx(context.Background(), feeData, &common.Address{}, big.NewInt(0), nil, nil, nil)
+		assert.NoError(t, err)
+		assert.Len(t, tx.AccessList(), 1)
+
+		// the access list and the gas limit estimated with it are kept while access lists are enabled
+		tx, err = s.resubmitTransaction(context.Background(), tx, 0, 0)
+		assert.NoError(t, err)
+		assert.Len(t, tx.AccessList(), 1)
+		assert.Equal(t, uint64(50000), tx.Gas())
+
+		// access lists are disabled between the submissions, the gas limit is estimated again without it
+		cfgCopy.DisableAccessList = true
+		tx, err = s.resubmitTransaction(context.Background(), tx, 0, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, tx.AccessList())
+		assert.Equal(t, uint64(21000*12/10), tx.Gas())
+		s.Stop()
+	}
+}
+
 func testResubmitNonZeroGasPriceTransaction(t *testing.T) {
 	for _, txType := range txTypes {
 		sqlDB, err := db.DB()
@@ -276,10 +417,10 @@ func testResubmitNonZeroGasPriceTransaction(t *testing.T) {
 			gasFeeCap: big.NewInt(100000),
 			gasLimit:  50000,
 		}
-		tx, err := s.createAndSendTx(feeData, &common.Address{}, big.NewInt(0), nil, nil)
+		tx, err := s.createAndSendTx(context.Background(), feeData, &common.Address{}, big.NewInt(0), nil, nil, nil)
 		assert.NoError(t, err)
 		assert.NotNil(t, tx)
-		_, err = s.resubmitTransaction(tx, 0)
+		_, err = s.resubmitTransaction(context.Background(), tx, 0, 0)
 		assert.NoError(t, err)
 		s.Stop()
 	}
@@ -304,10 +445,10 @@ func testResubmitUnderpricedTransaction(t *testing.T) {
 			gasFeeCap: big.NewInt(100000),
 			gasLimit:  50000,
 		}
-		tx, err := s.createAndSendTx(feeData, &common.Address{}, big.NewInt(0), nil, nil)
+		tx, err := s.createAndSendTx(context.Background(), feeData, &common.Address{}, big.NewInt(0), nil, nil, nil)
 		assert.NoError(t, err)
 		assert.NotNil(t, tx)
-		_, err = s.resubmitTransaction(tx, 0)
+		_, err = s.resubmitTransaction(context.Background(), tx, 0, 0)
 		assert.Error(t, err, "replacement transaction underpriced")
 		s.Stop()
 	}
@@ -329,7 +470,7 @@ func testResubmitTransactionWithRisingBaseFee(t *testing.T) {
 	// bump the basefee by 10x
 	baseFeePerGas *= 10
 	// resubmit and check that the gas fee has been adjusted accordingly
-	newTx, err := s.resubmitTransaction(tx, baseFeePerGas)
+	newTx, err := s.resubmitTransaction(context.Background(), tx, baseFeePerGas, 0)
 	assert.NoError(t, err)
 
 	escalateMultipleNum := new(big.Int).SetUint64(s.config.EscalateMultipleNum)
@@ -361,7 +502,7 @@ func testCheckPendingTransactionTxConfirmed(t *testing.T) {
 		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeCommitBatch, db, nil)
 		assert.NoError(t, err)
 
-		_, err = s.SendTransaction("test", &common.Address{}, big.NewInt(0), nil, 0)
+		_, err = s.SendTransaction(context.Background(), "test", &common.Address{}, big.NewInt(0), nil, 0)
 		assert.NoError(t, err)
 
 		txs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
@@ -374,7 +515,7 @@ func testCheckPendingTransactionTxConfirmed(t *testing.T) {
 			return &gethTypes.Receipt{TxHash: hash, BlockNumber: big.NewInt(0), Status: gethTypes.ReceiptStatusSuccessful}, nil
 		})
 
-		s.checkPendingTransaction()
+		s.checkPendingTransaction(context.Background())
 		assert.NoError(t, err)
 
 		txs, err = s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
@@ -398,7 +539,7 @@ func testCheckPendingTransactionResubmitTxConfirmed(t *testing.T) {
 		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeFinalizeBatch, db, nil)
 		assert.NoError(t, err)
 
-		originTxHash, err := s.SendTransaction("test", &common.Address{}, big.NewInt(0), nil, 0)
+		originTxHash, err := s.SendTransaction(context.Background(), "test", &common.Address{}, big.NewInt(0), nil, 0)
 		assert.NoError(t, err)
 
 		txs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
@@ -415,7 +556,7 @@ func testCheckPendingTransactionResubmitTxConfirmed(t *testing.T) {
 		})
 
 		// Attempt to resubmit the transaction.
-		s.checkPendingTransaction()
+		s.checkPendingTransaction(context.Background())
 		assert.NoError(t, err)
 
 		status, err := s.pendingTransactionOrm.GetTxStatusByTxHash(context.Background(), originTxHash)
@@ -429,7 +570,7 @@ func testCheckPendingTransactionResubmitTxConfirmed(t *testing.T) {
 		assert.Equal(t, types.TxStatusPending, txs[1].Status)
 
 		// Check the pending transactions again after attempting to resubmit.
-		s.checkPendingTransaction()
+		s.checkPendingTransaction(context.Background())
 		assert.NoError(t, err)
 
 		txs, err = s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
@@ -453,7 +594,7 @@ func testCheckPendingTransactionReplacedTxConfirmed(t *testing.T) {
 		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeL1GasOracle, db, nil)
 		assert.NoError(t, err)
 
-		txHash, err := s.SendTransaction("test", &common.Address{}, big.NewInt(0), nil, 0)
+		txHash, err := s.SendTransaction(context.Background(), "test", &common.Address{}, big.NewInt(0), nil, 0)
 		assert.NoError(t, err)
 
 		txs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
@@ -480,7 +621,7 @@ func testCheckPendingTransactionReplacedTxConfirmed(t *testing.T) {
 		})
 
 		// Attempt to resubmit the transaction.
-		s.checkPendingTransaction()
+		s.checkPendingTransaction(context.Background())
 		assert.NoError(t, err)
 
 		status, err := s.pendingTransactionOrm.GetTxStatusByTxHash(context.Background(), txHash)
@@ -494,7 +635,7 @@ func testCheckPendingTransactionReplacedTxConfirmed(t *testing.T) {
 		assert.Equal(t, types.TxStatusPending, txs[1].Status)
 
 		// Check the pending transactions again after attempting to resubmit.
-		s.checkPendingTransaction()
+		s.checkPendingTransaction(context.Background())
 		assert.NoError(t, err)
 
 		txs, err = s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
@@ -518,7 +659,7 @@ func testCheckPendingTransactionTxMultipleTimesWithOnlyOneTxPending(t *testing.T
 		s, err := NewSender(context.Background(), &cfgCopy, privateKey, "test", "test", types.SenderTypeCommitBatch, db, nil)
 		assert.NoError(t, err)
 
-		_, err = s.SendTransaction("test", &common.Address{}, big.NewInt(0), nil, 0)
+		_, err = s.SendTransaction(context.Background(), "test", &common.Address{}, big.NewInt(0), nil, 0)
 		assert.NoError(t, err)
 
 		txs, err := s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 1)
@@ -532,7 +673,7 @@ func testCheckPendingTransactionTxMultipleTimesWithOnlyOneTxPending(t *testing.T
 		})
 
 		for i := 1; i <= 6; i++ {
-			s.checkPendingTransaction()
+			s.checkPendingTransaction(context.Background())
 			assert.NoError(t, err)
 
 			txs, err = s.pendingTransactionOrm.GetPendingOrReplacedTransactionsBySenderType(context.Background(), s.senderType, 100)
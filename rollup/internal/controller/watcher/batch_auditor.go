@@ -0,0 +1,228 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/crypto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// BatchAuditor re-verifies the hash linkage and data hashes of the latest batches against the stored
+// chunks and blocks, in order to detect corruption introduced by bugs or manual DB edits.
+type BatchAuditor struct {
+	db *gorm.DB
+
+	batchOrm   orm.BatchRepo
+	chunkOrm   orm.ChunkRepo
+	l2BlockOrm *orm.L2Block
+
+	numBatches       uint64
+	baseFeeForkBlock *uint64
+
+	batchAuditorCircleTotal      prometheus.Counter
+	batchAuditorFailureTotal     prometheus.Counter
+	batchAuditorCorruptedTotal   prometheus.Counter
+	batchAuditorAuditedBatchNum  prometheus.Gauge
+	batchAuditorLatestBatchIndex prometheus.Gauge
+}
+
+// NewBatchAuditor creates a new BatchAuditor instance, baseFeeForkBlock is the base fee fork height of the l2 chain.
+func NewBatchAuditor(cfg *config.BatchAuditorConfig, baseFeeForkBlock *uint64, db *gorm.DB, reg prometheus.Registerer) *BatchAuditor {
+	log.Debug("new batch auditor",
+		"auditIntervalSec", cfg.AuditIntervalSec,
+		"numBatches", cfg.NumBatches)
+
+	return &BatchAuditor{
+		db:               db,
+		batchOrm:         orm.NewBatch(db),
+		chunkOrm:         orm.NewChunk(db),
+		l2BlockOrm:       orm.NewL2Block(db),
+		numBatches:       cfg.NumBatches,
+		baseFeeForkBlock: baseFeeForkBlock,
+
+		batchAuditorCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_batch_auditor_circle_total",
+			Help: "Total number of batch auditor rounds.",
+		}),
+		batchAuditorFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_batch_auditor_failure_total",
+			Help: "Total number of batch auditor rounds failed to complete.",
+		}),
+		batchAuditorCorruptedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_batch_auditor_corrupted_batch_total",
+			Help: "Total number of corrupted batches found by the batch auditor.",
+		}),
+		batchAuditorAuditedBatchNum: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_batch_auditor_audited_batch_number",
+			Help: "The number of batches audited in the last round.",
+		}),
+		batchAuditorLatestBatchIndex: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_batch_auditor_latest_batch_index",
+			Help: "The latest batch index audited in the last round.",
+		}),
+	}
+}
+
+// TryAuditBatches audits the latest batches and reports every corrupted batch.
+func (a *BatchAuditor) TryAuditBatches(ctx context.Context) {
+	a.batchAuditorCircleTotal.Inc()
+
+	// fetch one more batch to check the parent linkage of the oldest audited batch
+	batches, err := a.batchOrm.GetBatches(ctx, map[string]interface{}{}, []string{"index DESC"}, int(a.numBatches+1))
+	if err != nil {
+		a.batchAuditorFailureTotal.Inc()
+		log.Error("batch auditor failed to get batches", "err", err)
+		return
+	}
+	if len(batches) == 0 {
+		return
+	}
+
+	// sort in ascending order by index
+	for i, j := 0, len(batches)-1; i < j; i, j = i+1, j-1 {
+		batches[i], batches[j] = batches[j], batches[i]
+	}
+
+	var audited uint64
+	for i, batch := range batches {
+		var parent *orm.Batch
+		if i > 0 {
+			parent = batches[i-1]
+		} else if batch.Index != 0 && uint64(len(batches)) > a.numBatches {
+			// the oldest batch is only used as parent
+			continue
+		}
+
+		if err := a.auditBatch(ctx, parent, batch); err != nil {
+			a.batchAuditorCorruptedTotal.Inc()
+			log.Error("batch auditor found corrupted batch", "index", batch.Index, "hash", batch.Hash, "err", err)
+		}
+		audited++
+	}
+
+	a.batchAuditorAuditedBatchNum.Set(float64(audited))
+	a.batchAuditorLatestBatchIndex.Set(float64(batches[len(batches)-1].Index))
+	log.Info("batch auditor finished",
+		"start index", batches[0].Index,
+		"end index", batches[len(batches)-1].Index,
+		"audited batches", audited)
+}
+
+// auditBatch verifies the batch against its parent and the stored chunks and blocks.
+// The parent is nil for the first audited batch if it is the genesis batch.
+func (a *BatchAuditor) auditBatch(ctx context.Context, parent, batch *orm.Batch) error {
+	batchHeader, err := codec.DecodeBatchHeader(batch.BatchHeader)
+	if err != nil {
+		return fmt.Errorf("failed to decode batch header: %w", err)
+	}
+
+	if batchHeader.BatchIndex() != batch.Index {
+		return fmt.Errorf("batch header index mismatch, header: %v, stored: %v", batchHeader.BatchIndex(), batch.Index)
+	}
+
+	if hash := crypto.Keccak256Hash(batch.BatchHeader); hash != common.HexToHash(batch.Hash) {
+		return fmt.Errorf("batch hash mismatch, computed: %v, stored: %v", hash.Hex(), batch.Hash)
+	}
+
+	if batchHeader.ParentBatchHash() != common.HexToHash(batch.ParentBatchHash) {
+		return fmt.Errorf("parent batch hash mismatch, header: %v, stored: %v", batchHeader.ParentBatchHash().Hex(), batch.ParentBatchHash)
+	}
+
+	if parent != nil {
+		if parent.Index+1 != batch.Index {
+			return fmt.Errorf("batch index gap, parent index: %v, index: %v", parent.Index, batch.Index)
+		}
+
+		if common.HexToHash(parent.Hash) != batchHeader.ParentBatchHash() {
+			return fmt.Errorf("parent batch hash linkage broken, parent hash: %v, parent hash in header: %v", parent.Hash, batchHeader.ParentBatchHash().Hex())
+		}
+
+		parentBatchHeader, err := codec.DecodeBatchHeader(parent.BatchHeader)
+		if err != nil {
+			return fmt.Errorf("failed to decode parent batch header: %w", err)
+		}
+
+		if parentBatchHeader.TotalL1MessagePopped()+batchHeader.L1MessagePopped() != batchHeader.TotalL1MessagePopped() {
+			return fmt.Errorf("total l1 message popped mismatch, parent total: %v, popped: %v, total: %v",
+				parentBatchHeader.TotalL1MessagePopped(), batchHeader.L1MessagePopped(), batchHeader.TotalL1MessagePopped())
+		}
+
+		if parent.EndChunkIndex+1 != batch.StartChunkIndex {
+			return fmt.Errorf("chunk index gap, parent end chunk index: %v, start chunk index: %v", parent.EndChunkIndex, batch.StartChunkIndex)
+		}
+	}
+
+	// the blocks of the genesis batch are not stored in the database
+	if batch.Index == 0 {
+		return nil
+	}
+
+	if batch.EndChunkIndex < batch.StartChunkIndex {
+		return fmt.Errorf("invalid chunk range, start chunk index: %v, end chunk index: %v", batch.StartChunkIndex, batch.EndChunkIndex)
+	}
+
+	dbChunks, err := a.chunkOrm.GetChunksInRange(ctx, batch.StartChunkIndex, batch.EndChunkIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get chunks: %w", err)
+	}
+
+	// a missing chunk would leave its blocks out of the data hash check
+	if uint64(len(dbChunks)) != batch.EndChunkIndex-batch.StartChunkIndex+1 {
+		return fmt.Errorf("chunk number mismatch, expected: %v, got: %v", batch.EndChunkIndex-batch.StartChunkIndex+1, len(dbChunks))
+	}
+
+	batchCodec, err := codec.New(codec.Version(batchHeader.Version()))
+	if err != nil {
+		return fmt.Errorf("failed to get batch codec: %w", err)
+	}
+
+	chunkHashes := make([]common.Hash, 0, len(dbChunks))
+	for i, dbChunk := range dbChunks {
+		if dbChunk.Index != batch.StartChunkIndex+uint64(i) {
+			return fmt.Errorf("chunk index gap, expected: %v, got: %v", batch.StartChunkIndex+uint64(i), dbChunk.Index)
+		}
+
+		if dbChunk.BatchHash != batch.Hash {
+			return fmt.Errorf("chunk %v belongs to batch %v", dbChunk.Index, dbChunk.BatchHash)
+		}
+
+		blocks, err := a.l2BlockOrm.GetL2BlocksInRange(ctx, dbChunk.StartBlockNumber, dbChunk.EndBlockNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get blocks of chunk %v: %w", dbChunk.Index, err)
+		}
+
+		chunk := &types.Chunk{Blocks: blocks, BaseFeeForkBlock: a.baseFeeForkBlock}
+		chunkHash, err := chunk.Hash(dbChunk.TotalL1MessagesPoppedBefore)
+		if err != nil {
+			return fmt.Errorf("failed to compute hash of chunk %v: %w", dbChunk.Index, err)
+		}
+
+		if chunkHash != common.HexToHash(dbChunk.Hash) {
+			return fmt.Errorf("chunk %v hash mismatch, computed: %v, stored: %v", dbChunk.Index, chunkHash.Hex(), dbChunk.Hash)
+		}
+
+		// the data hash is computed over the chunk hashes of the batch version
+		if batchCodec.Version() != codec.CodecV0 {
+			if chunkHash, err = batchCodec.ChunkHash(chunk, dbChunk.TotalL1MessagesPoppedBefore); err != nil {
+				return fmt.Errorf("failed to compute codec hash of chunk %v: %w", dbChunk.Index, err)
+			}
+		}
+		chunkHashes = append(chunkHashes, chunkHash)
+	}
+
+	if dataHash := types.BatchDataHash(chunkHashes); dataHash != batchHeader.DataHash() {
+		return fmt.Errorf("data hash mismatch, computed: %v, header: %v", dataHash.Hex(), batchHeader.DataHash().Hex())
+	}
+	return nil
+}
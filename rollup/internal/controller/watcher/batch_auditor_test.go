@@ -0,0 +1,131 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// gapChunkRepo drops the chunk of the given index from the chunk ranges it returns.
+type gapChunkRepo struct {
+	orm.ChunkRepo
+	missingIndex uint64
+}
+
+func (r *gapChunkRepo) GetChunksInRange(ctx context.Context, startIndex uint64, endIndex uint64) ([]*orm.Chunk, error) {
+	chunks, err := r.ChunkRepo.GetChunksInRange(ctx, startIndex, endIndex)
+	if err != nil {
+		return nil, err
+	}
+	var kept []*orm.Chunk
+	for _, chunk := range chunks {
+		if chunk.Index != r.missingIndex {
+			kept = append(kept, chunk)
+		}
+	}
+	return kept, nil
+}
+
+func testBatchAuditorAuditBatch(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             1,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background()) // chunk1 contains block1
+	cp.TryProposeChunk(context.Background()) // chunk2 contains block2
+
+	bp := NewBatchProposer(&config.BatchProposerConfig{
+		MaxChunkNumPerBatch:             1,
+		MaxL1CommitGasPerBatch:          50000000000,
+		MaxL1CommitCalldataSizePerBatch: 1000000,
+		BatchTimeoutSec:                 1000000000000,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+	bp.TryProposeBatch(context.Background()) // batch1 contains chunk1
+	bp.TryProposeBatch(context.Background()) // batch2 contains chunk2
+
+	batches, err := orm.NewBatch(db).GetBatches(context.Background(), map[string]interface{}{}, []string{"index ASC"}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 2)
+	parent, batch := batches[0], batches[1]
+
+	tests := []struct {
+		name      string
+		parent    func() *orm.Batch
+		batch     func() *orm.Batch
+		chunkRepo func() orm.ChunkRepo
+		expectErr string
+	}{
+		{
+			name: "Valid",
+		},
+		{
+			name: "ChunkGap",
+			chunkRepo: func() orm.ChunkRepo {
+				return &gapChunkRepo{ChunkRepo: orm.NewChunk(db), missingIndex: batch.StartChunkIndex}
+			},
+			expectErr: "chunk number mismatch",
+		},
+		{
+			name: "HashMismatch",
+			batch: func() *orm.Batch {
+				corrupted := *batch
+				corrupted.Hash = common.HexToHash("0x01").Hex()
+				return &corrupted
+			},
+			expectErr: "batch hash mismatch",
+		},
+		{
+			name: "WrongParent",
+			parent: func() *orm.Batch {
+				wrong := *parent
+				wrong.Hash = common.HexToHash("0x02").Hex()
+				return &wrong
+			},
+			expectErr: "parent batch hash linkage broken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditor := NewBatchAuditor(&config.BatchAuditorConfig{AuditIntervalSec: 1, NumBatches: 2}, nil, db, nil)
+			if tt.chunkRepo != nil {
+				auditor.chunkOrm = tt.chunkRepo()
+			}
+			auditedParent, auditedBatch := parent, batch
+			if tt.parent != nil {
+				auditedParent = tt.parent()
+			}
+			if tt.batch != nil {
+				auditedBatch = tt.batch()
+			}
+
+			err := auditor.auditBatch(context.Background(), auditedParent, auditedBatch)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectErr)
+		})
+	}
+}
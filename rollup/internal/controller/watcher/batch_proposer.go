@@ -3,6 +3,8 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,6 +12,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/observability"
 	"scroll-tech/common/types"
 
 	"scroll-tech/rollup/internal/config"
@@ -18,8 +21,7 @@ import (
 
 // BatchProposer proposes batches based on available unbatched chunks.
 type BatchProposer struct {
-	ctx context.Context
-	db  *gorm.DB
+	db *gorm.DB
 
 	batchOrm   *orm.Batch
 	chunkOrm   *orm.Chunk
@@ -28,8 +30,14 @@ type BatchProposer struct {
 	maxChunkNumPerBatch             uint64
 	maxL1CommitGasPerBatch          uint64
 	maxL1CommitCalldataSizePerBatch uint32
+	maxL1CommitTxCalldataSize       uint64
+	maxL1MessagePoppedPerBatch      uint64
 	batchTimeoutSec                 uint64
 	gasCostIncreaseMultiplier       float64
+	codecVersion                    uint8
+	baseFeeForkBlock                *uint64
+	// minChunkNum is changed at runtime through the admin api
+	minChunkNum atomic.Pointer[ProposalMinimum]
 
 	batchProposerCircleTotal           prometheus.Counter
 	proposeBatchFailureTotal           prometheus.Counter
@@ -40,19 +48,25 @@ type BatchProposer struct {
 	batchChunksNum                     prometheus.Gauge
 	batchFirstBlockTimeoutReached      prometheus.Counter
 	batchChunksProposeNotEnoughTotal   prometheus.Counter
+	batchBelowMinChunkNumTotal         prometheus.Counter
+	batchCommitTxCalldataSizeExceeded  prometheus.Counter
 }
 
-// NewBatchProposer creates a new BatchProposer instance.
-func NewBatchProposer(ctx context.Context, cfg *config.BatchProposerConfig, db *gorm.DB, reg prometheus.Registerer) *BatchProposer {
+// NewBatchProposer creates a new BatchProposer instance, baseFeeForkBlock is the base fee fork height of the l2 chain.
+func NewBatchProposer(cfg *config.BatchProposerConfig, baseFeeForkBlock *uint64, db *gorm.DB, reg prometheus.Registerer) *BatchProposer {
 	log.Debug("new batch proposer",
 		"maxChunkNumPerBatch", cfg.MaxChunkNumPerBatch,
 		"maxL1CommitGasPerBatch", cfg.MaxL1CommitGasPerBatch,
 		"maxL1CommitCalldataSizePerBatch", cfg.MaxL1CommitCalldataSizePerBatch,
+		"maxL1CommitTxCalldataSize", cfg.MaxL1CommitTxCalldataSize,
+		"maxL1MessagePoppedPerBatch", cfg.MaxL1MessagePoppedPerBatch,
 		"batchTimeoutSec", cfg.BatchTimeoutSec,
-		"gasCostIncreaseMultiplier", cfg.GasCostIncreaseMultiplier)
+		"gasCostIncreaseMultiplier", cfg.GasCostIncreaseMultiplier,
+		"codecVersion", cfg.CodecVersion,
+		"minChunkNumPerBatch", cfg.MinChunkNumPerBatch,
+		"minChunkNumTimeoutSec", cfg.MinChunkNumTimeoutSec)
 
-	return &BatchProposer{
-		ctx:                             ctx,
+	p := &BatchProposer{
 		db:                              db,
 		batchOrm:                        orm.NewBatch(db),
 		chunkOrm:                        orm.NewChunk(db),
@@ -60,8 +74,12 @@ func NewBatchProposer(ctx context.Context, cfg *config.BatchProposerConfig, db *
 		maxChunkNumPerBatch:             cfg.MaxChunkNumPerBatch,
 		maxL1CommitGasPerBatch:          cfg.MaxL1CommitGasPerBatch,
 		maxL1CommitCalldataSizePerBatch: cfg.MaxL1CommitCalldataSizePerBatch,
+		maxL1CommitTxCalldataSize:       cfg.MaxL1CommitTxCalldataSize,
+		maxL1MessagePoppedPerBatch:      cfg.MaxL1MessagePoppedPerBatch,
 		batchTimeoutSec:                 cfg.BatchTimeoutSec,
 		gasCostIncreaseMultiplier:       cfg.GasCostIncreaseMultiplier,
+		codecVersion:                    cfg.CodecVersion,
+		baseFeeForkBlock:                baseFeeForkBlock,
 
 		batchProposerCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "rollup_propose_batch_circle_total",
@@ -99,47 +117,106 @@ func NewBatchProposer(ctx context.Context, cfg *config.BatchProposerConfig, db *
 			Name: "rollup_propose_batch_chunks_propose_not_enough_total",
 			Help: "Total number of batch chunk propose not enough",
 		}),
+		batchBelowMinChunkNumTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_batch_below_min_chunk_num_total",
+			Help: "Total times of a timed out batch waiting for more chunks because it is below the min number of chunks",
+		}),
+		batchCommitTxCalldataSizeExceeded: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_batch_commit_tx_calldata_size_exceeded_total",
+			Help: "Total number of proposed batches shrunk because their exact commit tx calldata exceeds the limit",
+		}),
+	}
+	p.minChunkNum.Store(&ProposalMinimum{Num: cfg.MinChunkNumPerBatch, TimeoutSec: cfg.MinChunkNumTimeoutSec})
+	return p
+}
+
+// MinChunkNum returns the min number of chunks of a batch proposed at the batch timeout.
+func (p *BatchProposer) MinChunkNum() ProposalMinimum {
+	return *p.minChunkNum.Load()
+}
+
+// DebugState returns the state of the batch proposer for the debug server, the open batch starts
+// at the first unbatched chunk.
+func (p *BatchProposer) DebugState() interface{} {
+	ctx, cancel := context.WithTimeout(context.Background(), observability.StateDumpTimeout)
+	defer cancel()
+
+	state := map[string]interface{}{"min_chunk_num": p.MinChunkNum()}
+	if index, err := p.batchOrm.GetFirstUnbatchedChunkIndex(ctx); err != nil {
+		state["first_unbatched_chunk_index_error"] = err.Error()
+	} else {
+		state["first_unbatched_chunk_index"] = index
+	}
+	if chunk, err := p.chunkOrm.GetLatestChunk(ctx); err != nil {
+		state["latest_chunk_error"] = err.Error()
+	} else {
+		state["latest_chunk_index"] = chunk.Index
+	}
+	return state
+}
+
+// SetMinChunkNum changes the min number of chunks of a batch proposed at the batch timeout
+// until the next restart, it applies from the next proposal round on.
+func (p *BatchProposer) SetMinChunkNum(minimum ProposalMinimum) error {
+	if err := minimum.validate(p.maxChunkNumPerBatch, p.batchTimeoutSec); err != nil {
+		return err
 	}
+	p.minChunkNum.Store(&minimum)
+	log.Info("batch proposer min number of chunks changed", "num", minimum.Num, "timeoutSec", minimum.TimeoutSec)
+	return nil
 }
 
 // TryProposeBatch tries to propose a new batches.
-func (p *BatchProposer) TryProposeBatch() {
+func (p *BatchProposer) TryProposeBatch(ctx context.Context) {
 	p.batchProposerCircleTotal.Inc()
-	dbChunks, batchMeta, err := p.proposeBatchChunks()
+	dbChunks, batchMeta, err := p.proposeBatchChunks(ctx)
 	if err != nil {
 		p.proposeBatchFailureTotal.Inc()
 		log.Error("proposeBatchChunks failed", "err", err)
 		return
 	}
-	if err := p.updateBatchInfoInDB(dbChunks, batchMeta); err != nil {
+	if err := p.updateBatchInfoInDB(ctx, dbChunks, batchMeta); err != nil {
 		p.proposeBatchUpdateInfoFailureTotal.Inc()
 		log.Error("update batch info in db failed", "err", err)
 	}
 }
 
-func (p *BatchProposer) updateBatchInfoInDB(dbChunks []*orm.Chunk, batchMeta *types.BatchMeta) error {
+func (p *BatchProposer) updateBatchInfoInDB(ctx context.Context, dbChunks []*orm.Chunk, batchMeta *types.BatchMeta) error {
 	p.proposeBatchUpdateInfoTotal.Inc()
 	numChunks := len(dbChunks)
 	if numChunks <= 0 {
 		return nil
 	}
-	chunks, err := p.dbChunksToRollupChunks(dbChunks)
+	chunks, err := p.dbChunksToRollupChunks(ctx, dbChunks)
 	if err != nil {
 		return err
 	}
 
+	if p.maxL1CommitTxCalldataSize > 0 {
+		numChunks, err = p.fitCommitTxCalldataSize(ctx, dbChunks, chunks)
+		if err != nil {
+			return err
+		}
+		for _, dropped := range dbChunks[numChunks:] {
+			batchMeta.TotalL1CommitGas -= dropped.TotalL1CommitGas
+			batchMeta.TotalL1CommitCalldataSize -= dropped.TotalL1CommitCalldataSize
+		}
+		dbChunks, chunks = dbChunks[:numChunks], chunks[:numChunks]
+	}
+
 	batchMeta.StartChunkIndex = dbChunks[0].Index
 	batchMeta.StartChunkHash = dbChunks[0].Hash
 	batchMeta.EndChunkIndex = dbChunks[numChunks-1].Index
 	batchMeta.EndChunkHash = dbChunks[numChunks-1].Hash
-	err = p.db.Transaction(func(dbTX *gorm.DB) error {
-		batch, dbErr := p.batchOrm.InsertBatch(p.ctx, chunks, batchMeta, dbTX)
+	batchMeta.CodecVersion = p.codecVersion
+	err = p.db.WithContext(ctx).Transaction(func(dbTX *gorm.DB) error {
+		batch, dbErr := p.batchOrm.InsertBatch(ctx, chunks, batchMeta, dbTX)
 		if dbErr != nil {
 			log.Warn("BatchProposer.updateBatchInfoInDB insert batch failure",
 				"start chunk index", batchMeta.StartChunkIndex, "end chunk index", batchMeta.EndChunkIndex, "error", dbErr)
 			return dbErr
 		}
-		dbErr = p.chunkOrm.UpdateBatchHashInRange(p.ctx, batchMeta.StartChunkIndex, batchMeta.EndChunkIndex, batch.Hash, dbTX)
+		dbErr = p.chunkOrm.UpdateBatchHashInRange(ctx, batchMeta.StartChunkIndex, batchMeta.EndChunkIndex, batch.Hash, dbTX)
 		if dbErr != nil {
 			log.Warn("BatchProposer.UpdateBatchHashInRange update the chunk's batch hash failure", "hash", batch.Hash, "error", dbErr)
 			return dbErr
@@ -149,14 +226,14 @@ func (p *BatchProposer) updateBatchInfoInDB(dbChunks []*orm.Chunk, batchMeta *ty
 	return err
 }
 
-func (p *BatchProposer) proposeBatchChunks() ([]*orm.Chunk, *types.BatchMeta, error) {
-	unbatchedChunkIndex, err := p.batchOrm.GetFirstUnbatchedChunkIndex(p.ctx)
+func (p *BatchProposer) proposeBatchChunks(ctx context.Context) ([]*orm.Chunk, *types.BatchMeta, error) {
+	unbatchedChunkIndex, err := p.batchOrm.GetFirstUnbatchedChunkIndex(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// select at most p.maxChunkNumPerBatch chunks
-	dbChunks, err := p.chunkOrm.GetChunksGEIndex(p.ctx, unbatchedChunkIndex, int(p.maxChunkNumPerBatch))
+	dbChunks, err := p.chunkOrm.GetChunksGEIndex(ctx, unbatchedChunkIndex, int(p.maxChunkNumPerBatch))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -171,11 +248,17 @@ func (p *BatchProposer) proposeBatchChunks() ([]*orm.Chunk, *types.BatchMeta, er
 	var totalL1MessagePopped uint64
 	var batchMeta types.BatchMeta
 
-	parentBatch, err := p.batchOrm.GetLatestBatch(p.ctx)
+	parentBatch, err := p.batchOrm.GetLatestBatch(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	var parentBatchHeaderSize uint64
+	if parentBatch != nil {
+		parentBatchHeaderSize = uint64(len(parentBatch.BatchHeader))
+	}
+	chunkSizes := make([]uint64, 0, len(dbChunks))
+
 	// Add extra gas costs
 	totalL1CommitGas += 100000                       // constant to account for ops like _getAdmin, _implementation, _requireNotPaused, etc
 	totalL1CommitGas += 4 * 2100                     // 4 one-time cold sload for commitBatch
@@ -213,11 +296,35 @@ func (p *BatchProposer) proposeBatchChunks() ([]*orm.Chunk, *types.BatchMeta, er
 		totalL1CommitGas += types.GetKeccak256Gas(89 + 32*(totalL1MessagePopped+255)/256)
 		totalL1CommitGas += types.GetMemoryExpansionCost(uint64(totalL1CommitCalldataSize))
 		totalOverEstimateL1CommitGas := uint64(p.gasCostIncreaseMultiplier * float64(totalL1CommitGas))
+		chunkSizes = append(chunkSizes, uint64(chunk.TotalL1CommitCalldataSize))
+		commitTxCalldataSize := commitBatchCalldataSize(parentBatchHeaderSize, chunkSizes, types.SkippedL1MessageBitmapSize(totalL1MessagePopped))
+		commitTxCalldataSizeExceeded := p.maxL1CommitTxCalldataSize > 0 && commitTxCalldataSize > p.maxL1CommitTxCalldataSize
+		l1MessagePoppedExceeded := p.maxL1MessagePoppedPerBatch > 0 && totalL1MessagePopped > p.maxL1MessagePoppedPerBatch
 		if totalL1CommitCalldataSize > p.maxL1CommitCalldataSizePerBatch ||
-			totalOverEstimateL1CommitGas > p.maxL1CommitGasPerBatch {
+			totalOverEstimateL1CommitGas > p.maxL1CommitGasPerBatch ||
+			commitTxCalldataSizeExceeded ||
+			l1MessagePoppedExceeded {
 			// Check if the first chunk breaks hard limits.
 			// If so, it indicates there are bugs in chunk-proposer, manual fix is needed.
 			if i == 0 {
+				if l1MessagePoppedExceeded {
+					return nil, nil, fmt.Errorf(
+						"the first chunk exceeds l1 message popped limit; start block number: %v, end block number: %v, l1 message popped: %v, max l1 message popped limit: %v",
+						dbChunks[0].StartBlockNumber,
+						dbChunks[0].EndBlockNumber,
+						totalL1MessagePopped,
+						p.maxL1MessagePoppedPerBatch,
+					)
+				}
+				if commitTxCalldataSizeExceeded {
+					return nil, nil, fmt.Errorf(
+						"the first chunk exceeds l1 commit tx calldata size limit; start block number: %v, end block number: %v, commit tx calldata size: %v, max commit tx calldata size limit: %v",
+						dbChunks[0].StartBlockNumber,
+						dbChunks[0].EndBlockNumber,
+						commitTxCalldataSize,
+						p.maxL1CommitTxCalldataSize,
+					)
+				}
 				if totalOverEstimateL1CommitGas > p.maxL1CommitGasPerBatch {
 					return nil, nil, fmt.Errorf(
 						"the first chunk exceeds l1 commit gas limit; start block number: %v, end block number: %v, commit gas: %v, max commit gas limit: %v",
@@ -242,7 +349,11 @@ func (p *BatchProposer) proposeBatchChunks() ([]*orm.Chunk, *types.BatchMeta, er
 				"currentL1CommitCalldataSize", totalL1CommitCalldataSize,
 				"maxL1CommitCalldataSizePerBatch", p.maxL1CommitCalldataSizePerBatch,
 				"currentOverEstimateL1CommitGas", totalOverEstimateL1CommitGas,
-				"maxL1CommitGasPerBatch", p.maxL1CommitGasPerBatch)
+				"maxL1CommitGasPerBatch", p.maxL1CommitGasPerBatch,
+				"currentL1CommitTxCalldataSize", commitTxCalldataSize,
+				"maxL1CommitTxCalldataSize", p.maxL1CommitTxCalldataSize,
+				"currentL1MessagePopped", totalL1MessagePopped,
+				"maxL1MessagePoppedPerBatch", p.maxL1MessagePoppedPerBatch)
 
 			p.totalL1CommitGas.Set(float64(batchMeta.TotalL1CommitGas))
 			p.totalL1CommitCalldataSize.Set(float64(batchMeta.TotalL1CommitCalldataSize))
@@ -252,9 +363,19 @@ func (p *BatchProposer) proposeBatchChunks() ([]*orm.Chunk, *types.BatchMeta, er
 	}
 
 	currentTimeSec := uint64(time.Now().Unix())
-	if dbChunks[0].StartBlockTime+p.batchTimeoutSec < currentTimeSec ||
-		totalChunks == p.maxChunkNumPerBatch {
-		if dbChunks[0].StartBlockTime+p.batchTimeoutSec < currentTimeSec {
+	timedOut := dbChunks[0].StartBlockTime+p.batchTimeoutSec < currentTimeSec
+	if minimum := p.MinChunkNum(); timedOut && minimum.waits(totalChunks, dbChunks[0].StartBlockTime, currentTimeSec) {
+		log.Debug("timed out batch is below the min number of chunks",
+			"start chunk index", dbChunks[0].Index,
+			"chunk count", totalChunks,
+			"min chunk count", minimum.Num,
+		)
+		p.batchBelowMinChunkNumTotal.Inc()
+		return nil, nil, nil
+	}
+
+	if timedOut || totalChunks == p.maxChunkNumPerBatch {
+		if timedOut {
 			log.Warn("first block timeout",
 				"start block number", dbChunks[0].StartBlockNumber,
 				"start block timestamp", dbChunks[0].StartBlockTime,
@@ -280,17 +401,63 @@ func (p *BatchProposer) proposeBatchChunks() ([]*orm.Chunk, *types.BatchMeta, er
 	return nil, nil, nil
 }
 
-func (p *BatchProposer) dbChunksToRollupChunks(dbChunks []*orm.Chunk) ([]*types.Chunk, error) {
+// fitCommitTxCalldataSize returns the number of leading chunks whose exact commit tx calldata fits the limit,
+// the chunk sizes stored by the chunk proposer may be estimates, so the proposal is checked again here
+// instead of failing when the commit tx is broadcast.
+func (p *BatchProposer) fitCommitTxCalldataSize(ctx context.Context, dbChunks []*orm.Chunk, chunks []*types.Chunk) (int, error) {
+	parentBatch, err := p.batchOrm.GetLatestBatch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var parentBatchHeaderSize uint64
+	if parentBatch != nil {
+		parentBatchHeaderSize = uint64(len(parentBatch.BatchHeader))
+	}
+
+	chunkSizes := make([]uint64, len(chunks))
+	for i, chunk := range chunks {
+		chunkSize, err := chunk.EncodeTo(io.Discard, dbChunks[i].TotalL1MessagesPoppedBefore)
+		if err != nil {
+			return 0, fmt.Errorf("failed to compute the calldata size of chunk %v: %w", dbChunks[i].Index, err)
+		}
+		chunkSizes[i] = uint64(chunkSize)
+	}
+
+	var totalL1MessagePopped uint64
+	for _, dbChunk := range dbChunks {
+		totalL1MessagePopped += uint64(dbChunk.TotalL1MessagesPoppedInChunk)
+	}
+	for numChunks := len(chunks); numChunks > 0; numChunks-- {
+		bitmapSize := types.SkippedL1MessageBitmapSize(totalL1MessagePopped)
+		size := commitBatchCalldataSize(parentBatchHeaderSize, chunkSizes[:numChunks], bitmapSize)
+		if size <= p.maxL1CommitTxCalldataSize {
+			return numChunks, nil
+		}
+
+		p.batchCommitTxCalldataSizeExceeded.Inc()
+		log.Warn("proposed batch exceeds l1 commit tx calldata size limit, dropping its last chunk",
+			"start chunk index", dbChunks[0].Index, "end chunk index", dbChunks[numChunks-1].Index,
+			"commit tx calldata size", size, "max commit tx calldata size", p.maxL1CommitTxCalldataSize,
+			"parent batch header size", parentBatchHeaderSize, "chunk sizes", chunkSizes[:numChunks], "skipped l1 message bitmap size", bitmapSize)
+		totalL1MessagePopped -= uint64(dbChunks[numChunks-1].TotalL1MessagesPoppedInChunk)
+	}
+
+	return 0, fmt.Errorf("the first chunk exceeds l1 commit tx calldata size limit; chunk index: %v, start block number: %v, end block number: %v, chunk size: %v, parent batch header size: %v, max commit tx calldata size limit: %v",
+		dbChunks[0].Index, dbChunks[0].StartBlockNumber, dbChunks[0].EndBlockNumber, chunkSizes[0], parentBatchHeaderSize, p.maxL1CommitTxCalldataSize)
+}
+
+func (p *BatchProposer) dbChunksToRollupChunks(ctx context.Context, dbChunks []*orm.Chunk) ([]*types.Chunk, error) {
 	chunks := make([]*types.Chunk, len(dbChunks))
 	for i, c := range dbChunks {
-		wrappedBlocks, err := p.l2BlockOrm.GetL2BlocksInRange(p.ctx, c.StartBlockNumber, c.EndBlockNumber)
+		wrappedBlocks, err := p.l2BlockOrm.GetL2BlocksInRange(ctx, c.StartBlockNumber, c.EndBlockNumber)
 		if err != nil {
 			log.Error("Failed to fetch wrapped blocks",
 				"start number", c.StartBlockNumber, "end number", c.EndBlockNumber, "error", err)
 			return nil, err
 		}
 		chunks[i] = &types.Chunk{
-			Blocks: wrappedBlocks,
+			Blocks:           wrappedBlocks,
+			BaseFeeForkBlock: p.baseFeeForkBlock,
 		}
 	}
 	return chunks, nil
@@ -8,6 +8,7 @@ import (
 
 	"scroll-tech/common/database"
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
 
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/orm"
@@ -19,6 +20,7 @@ func testBatchProposerLimits(t *testing.T) {
 		maxChunkNum                uint64
 		maxL1CommitGas             uint64
 		maxL1CommitCalldataSize    uint32
+		maxL1CommitTxCalldataSize  uint64
 		batchTimeoutSec            uint64
 		expectedBatchesLen         int
 		expectedChunksInFirstBatch uint64 // only be checked when expectedBatchesLen > 0
@@ -83,6 +85,25 @@ func testBatchProposerLimits(t *testing.T) {
 			expectedBatchesLen:         1,
 			expectedChunksInFirstBatch: 1,
 		},
+		{
+			name:                       "MaxL1CommitTxCalldataSizeIsFirstChunk",
+			maxChunkNum:                10,
+			maxL1CommitGas:             50000000000,
+			maxL1CommitCalldataSize:    1000000,
+			maxL1CommitTxCalldataSize:  1000,
+			batchTimeoutSec:            1000000000000,
+			expectedBatchesLen:         1,
+			expectedChunksInFirstBatch: 1,
+		},
+		{
+			name:                      "MaxL1CommitTxCalldataSizeBelowFirstChunk",
+			maxChunkNum:               10,
+			maxL1CommitGas:            50000000000,
+			maxL1CommitCalldataSize:   1000000,
+			maxL1CommitTxCalldataSize: 500,
+			batchTimeoutSec:           0,
+			expectedBatchesLen:        0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -102,9 +123,9 @@ func testBatchProposerLimits(t *testing.T) {
 				MaxRowConsumptionPerChunk:       1000000,
 				ChunkTimeoutSec:                 300,
 				GasCostIncreaseMultiplier:       1.2,
-			}, db, nil)
-			cp.TryProposeChunk() // chunk1 contains block1
-			cp.TryProposeChunk() // chunk2 contains block2
+			}, nil, db, nil)
+			cp.TryProposeChunk(context.Background()) // chunk1 contains block1
+			cp.TryProposeChunk(context.Background()) // chunk2 contains block2
 
 			chunkOrm := orm.NewChunk(db)
 			chunks, err := chunkOrm.GetChunksInRange(context.Background(), 0, 1)
@@ -114,14 +135,15 @@ func testBatchProposerLimits(t *testing.T) {
 			assert.Equal(t, uint64(94586), chunks[1].TotalL1CommitGas)
 			assert.Equal(t, uint32(5735), chunks[1].TotalL1CommitCalldataSize)
 
-			bp := NewBatchProposer(context.Background(), &config.BatchProposerConfig{
+			bp := NewBatchProposer(&config.BatchProposerConfig{
 				MaxChunkNumPerBatch:             tt.maxChunkNum,
 				MaxL1CommitGasPerBatch:          tt.maxL1CommitGas,
 				MaxL1CommitCalldataSizePerBatch: tt.maxL1CommitCalldataSize,
+				MaxL1CommitTxCalldataSize:       tt.maxL1CommitTxCalldataSize,
 				BatchTimeoutSec:                 tt.batchTimeoutSec,
 				GasCostIncreaseMultiplier:       1.2,
-			}, db, nil)
-			bp.TryProposeBatch()
+			}, nil, db, nil)
+			bp.TryProposeBatch(context.Background())
 
 			batchOrm := orm.NewBatch(db)
 			batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{}, 0)
@@ -145,6 +167,65 @@ func testBatchProposerLimits(t *testing.T) {
 	}
 }
 
+func testBatchProposerMaxL1MessagePopped(t *testing.T) {
+	for _, tt := range []struct {
+		name                       string
+		maxL1MessagePopped         uint64
+		expectedBatchesLen         int
+		expectedChunksInFirstBatch uint64
+	}{
+		{name: "Unlimited", maxL1MessagePopped: 0, expectedBatchesLen: 1, expectedChunksInFirstBatch: 2},
+		{name: "SecondChunkExceeds", maxL1MessagePopped: 2, expectedBatchesLen: 1, expectedChunksInFirstBatch: 1},
+		{name: "FirstChunkExceeds", maxL1MessagePopped: 1, expectedBatchesLen: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupDB(t)
+			defer database.CloseDB(db)
+
+			l2BlockOrm := orm.NewL2Block(db)
+			err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+			assert.NoError(t, err)
+
+			cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+				MaxBlockNumPerChunk:             1,
+				MaxTxNumPerChunk:                10000,
+				MaxL1CommitGasPerChunk:          50000000000,
+				MaxL1CommitCalldataSizePerChunk: 1000000,
+				MaxRowConsumptionPerChunk:       1000000,
+				ChunkTimeoutSec:                 300,
+				GasCostIncreaseMultiplier:       1.2,
+			}, nil, db, nil)
+			cp.TryProposeChunk(context.Background()) // chunk1 contains block1
+			cp.TryProposeChunk(context.Background()) // chunk2 contains block2
+
+			// the test blocks pop no l1 message, the popped counts are only read by the batch limits
+			err = db.Model(&orm.Chunk{}).Where("index = ?", 0).Update("total_l1_messages_popped_in_chunk", 2).Error
+			assert.NoError(t, err)
+			err = db.Model(&orm.Chunk{}).Where("index = ?", 1).Update("total_l1_messages_popped_in_chunk", 1).Error
+			assert.NoError(t, err)
+
+			bp := NewBatchProposer(&config.BatchProposerConfig{
+				MaxChunkNumPerBatch:             10,
+				MaxL1CommitGasPerBatch:          50000000000,
+				MaxL1CommitCalldataSizePerBatch: 1000000,
+				BatchTimeoutSec:                 0,
+				GasCostIncreaseMultiplier:       1.2,
+				MaxL1MessagePoppedPerBatch:      tt.maxL1MessagePopped,
+			}, nil, db, nil)
+			bp.TryProposeBatch(context.Background())
+
+			batchOrm := orm.NewBatch(db)
+			batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{}, 0)
+			assert.NoError(t, err)
+			assert.Len(t, batches, tt.expectedBatchesLen)
+			if tt.expectedBatchesLen > 0 {
+				assert.Equal(t, uint64(0), batches[0].StartChunkIndex)
+				assert.Equal(t, tt.expectedChunksInFirstBatch-1, batches[0].EndChunkIndex)
+			}
+		})
+	}
+}
+
 func testBatchCommitGasAndCalldataSizeEstimation(t *testing.T) {
 	db := setupDB(t)
 	defer database.CloseDB(db)
@@ -161,9 +242,9 @@ func testBatchCommitGasAndCalldataSizeEstimation(t *testing.T) {
 		MaxRowConsumptionPerChunk:       1000000,
 		ChunkTimeoutSec:                 300,
 		GasCostIncreaseMultiplier:       1.2,
-	}, db, nil)
-	cp.TryProposeChunk() // chunk1 contains block1
-	cp.TryProposeChunk() // chunk2 contains block2
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background()) // chunk1 contains block1
+	cp.TryProposeChunk(context.Background()) // chunk2 contains block2
 
 	chunkOrm := orm.NewChunk(db)
 	chunks, err := chunkOrm.GetChunksInRange(context.Background(), 0, 1)
@@ -173,14 +254,14 @@ func testBatchCommitGasAndCalldataSizeEstimation(t *testing.T) {
 	assert.Equal(t, uint64(94586), chunks[1].TotalL1CommitGas)
 	assert.Equal(t, uint32(5735), chunks[1].TotalL1CommitCalldataSize)
 
-	bp := NewBatchProposer(context.Background(), &config.BatchProposerConfig{
+	bp := NewBatchProposer(&config.BatchProposerConfig{
 		MaxChunkNumPerBatch:             10,
 		MaxL1CommitGasPerBatch:          50000000000,
 		MaxL1CommitCalldataSizePerBatch: 1000000,
 		BatchTimeoutSec:                 0,
 		GasCostIncreaseMultiplier:       1.2,
-	}, db, nil)
-	bp.TryProposeBatch()
+	}, nil, db, nil)
+	bp.TryProposeBatch(context.Background())
 
 	batchOrm := orm.NewBatch(db)
 	batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{}, 0)
@@ -202,3 +283,72 @@ func testBatchCommitGasAndCalldataSizeEstimation(t *testing.T) {
 	assert.Equal(t, uint64(254562), batches[0].TotalL1CommitGas)
 	assert.Equal(t, uint32(6033), batches[0].TotalL1CommitCalldataSize)
 }
+
+func testBatchProposerCodecVersion(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             1,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background()) // chunk1 contains block1
+	cp.TryProposeChunk(context.Background()) // chunk2 contains block2
+
+	batchCfg := &config.BatchProposerConfig{
+		MaxChunkNumPerBatch:             1,
+		MaxL1CommitGasPerBatch:          50000000000,
+		MaxL1CommitCalldataSizePerBatch: 1000000,
+		BatchTimeoutSec:                 1000000000000,
+		GasCostIncreaseMultiplier:       1.2,
+		CodecVersion:                    uint8(codec.CodecV1),
+	}
+	NewBatchProposer(batchCfg, nil, db, nil).TryProposeBatch(context.Background())
+
+	// a lower configured version keeps the version of the latest batch
+	batchCfg.CodecVersion = uint8(codec.CodecV0)
+	NewBatchProposer(batchCfg, nil, db, nil).TryProposeBatch(context.Background())
+
+	batchOrm := orm.NewBatch(db)
+	batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{"index ASC"}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 2)
+
+	chunkOrm := orm.NewChunk(db)
+	v1, err := codec.New(codec.CodecV1)
+	assert.NoError(t, err)
+	var parent *orm.Batch
+	for i, batch := range batches {
+		header, err := codec.DecodeBatchHeader(batch.BatchHeader)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(codec.CodecV1), header.Version())
+
+		dbChunks, err := chunkOrm.GetChunksInRange(context.Background(), batch.StartChunkIndex, batch.EndChunkIndex)
+		assert.NoError(t, err)
+		assert.Len(t, dbChunks, 1)
+		blocks, err := l2BlockOrm.GetL2BlocksInRange(context.Background(), dbChunks[0].StartBlockNumber, dbChunks[0].EndBlockNumber)
+		assert.NoError(t, err)
+		chunks := []*types.Chunk{{Blocks: blocks}}
+		expected, err := v1.NewBatchHeader(uint64(i), dbChunks[0].TotalL1MessagesPoppedBefore, header.ParentBatchHash(), chunks)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Hash().Hex(), batch.Hash)
+
+		blob, err := v1.NewBatchBlob(chunks)
+		assert.NoError(t, err)
+		assert.Equal(t, blob.VersionedHash, header.(codec.BlobBatchHeader).BlobVersionedHash())
+
+		// the auditor checks the data hash over the chunk hashes of the batch version
+		auditor := NewBatchAuditor(&config.BatchAuditorConfig{AuditIntervalSec: 1, NumBatches: 2}, nil, db, nil)
+		assert.NoError(t, auditor.auditBatch(context.Background(), parent, batch))
+		parent = batch
+	}
+}
@@ -0,0 +1,187 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// BatchReencodeResult is the outcome of re-encoding one stored batch under a new codec version.
+type BatchReencodeResult struct {
+	Index         uint64      `json:"index"`
+	StoredHash    common.Hash `json:"stored_hash"`
+	ReencodedHash common.Hash `json:"reencoded_hash"`
+	// Differences lists the batch header fields which differ from the stored encoding,
+	// besides the version and the hashes which always differ under a new codec version.
+	Differences []string `json:"differences,omitempty"`
+	// LimitViolations lists the chunk and batch limits exceeded by the re-encoded batch.
+	LimitViolations []string `json:"limit_violations,omitempty"`
+}
+
+// BatchReencoder re-encodes stored batches under a new codec version in shadow mode, nothing is
+// written to the database, so the outcome of a codec upgrade can be checked before its activation.
+type BatchReencoder struct {
+	ctx context.Context
+
+	batchOrm   *orm.Batch
+	chunkOrm   *orm.Chunk
+	l2BlockOrm *orm.L2Block
+
+	chunkCfg         *config.ChunkProposerConfig
+	batchCfg         *config.BatchProposerConfig
+	baseFeeForkBlock *uint64
+}
+
+// NewBatchReencoder creates a new BatchReencoder instance, the re-encoded batches are checked
+// against the chunk and batch proposer limits. baseFeeForkBlock is the base fee fork height of the l2 chain.
+func NewBatchReencoder(ctx context.Context, chunkCfg *config.ChunkProposerConfig, batchCfg *config.BatchProposerConfig, baseFeeForkBlock *uint64, db *gorm.DB) *BatchReencoder {
+	return &BatchReencoder{
+		ctx:              ctx,
+		batchOrm:         orm.NewBatch(db),
+		chunkOrm:         orm.NewChunk(db),
+		l2BlockOrm:       orm.NewL2Block(db),
+		chunkCfg:         chunkCfg,
+		batchCfg:         batchCfg,
+		baseFeeForkBlock: baseFeeForkBlock,
+	}
+}
+
+// Reencode re-encodes the batches in [startIndex, endIndex] under codecVersion. The first batch keeps
+// its stored parent, the following ones are chained to the re-encoded hash of their parent, as they
+// would be if the codec was activated at startIndex.
+func (r *BatchReencoder) Reencode(startIndex, endIndex uint64, codecVersion uint8) ([]*BatchReencodeResult, error) {
+	fields := map[string]interface{}{
+		"index >= ?": startIndex,
+		"index <= ?": endIndex,
+	}
+	dbBatches, err := r.batchOrm.GetBatches(r.ctx, fields, []string{"index ASC"}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	batchCodec, err := codec.New(codec.Version(codecVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*BatchReencodeResult, 0, len(dbBatches))
+	var parentHash common.Hash
+	for i, dbBatch := range dbBatches {
+		storedHeader, err := codec.DecodeBatchHeader(dbBatch.BatchHeader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode stored batch header, index: %v, err: %w", dbBatch.Index, err)
+		}
+		if i == 0 {
+			parentHash = storedHeader.ParentBatchHash()
+		}
+
+		result, reencodedHeader, err := r.reencodeBatch(dbBatch, storedHeader, parentHash, batchCodec)
+		if err != nil {
+			return nil, err
+		}
+		parentHash = reencodedHeader.Hash()
+
+		if len(result.Differences) > 0 || len(result.LimitViolations) > 0 {
+			log.Warn("re-encoded batch mismatch", "index", result.Index, "differences", result.Differences, "limit violations", result.LimitViolations)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (r *BatchReencoder) reencodeBatch(dbBatch *orm.Batch, storedHeader codec.BatchHeader, parentHash common.Hash, batchCodec codec.Codec) (*BatchReencodeResult, codec.BatchHeader, error) {
+	dbChunks, err := r.chunkOrm.GetChunksInRange(r.ctx, dbBatch.StartChunkIndex, dbBatch.EndChunkIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &BatchReencodeResult{
+		Index:      dbBatch.Index,
+		StoredHash: common.HexToHash(dbBatch.Hash),
+	}
+
+	chunks := make([]*types.Chunk, len(dbChunks))
+	var totalL1CommitCalldataSize uint64
+	for i, dbChunk := range dbChunks {
+		blocks, err := r.l2BlockOrm.GetL2BlocksInRange(r.ctx, dbChunk.StartBlockNumber, dbChunk.EndBlockNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+		chunks[i] = &types.Chunk{Blocks: blocks, BaseFeeForkBlock: r.baseFeeForkBlock}
+
+		encodedSize, err := chunks[i].EncodeTo(io.Discard, dbChunk.TotalL1MessagesPoppedBefore)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compute chunk calldata size, chunk index: %v, err: %w", dbChunk.Index, err)
+		}
+		calldataSize := uint64(encodedSize)
+		totalL1CommitCalldataSize += calldataSize
+		commitGas, err := chunks[i].EstimateL1CommitGas()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to estimate chunk commit gas, chunk index: %v, err: %w", dbChunk.Index, err)
+		}
+		result.LimitViolations = append(result.LimitViolations, r.checkChunkLimits(dbChunk.Index, chunks[i], calldataSize, commitGas)...)
+	}
+
+	if uint64(len(chunks)) > r.batchCfg.MaxChunkNumPerBatch {
+		result.LimitViolations = append(result.LimitViolations, fmt.Sprintf("chunk num %v exceeds max_chunk_num_per_batch %v", len(chunks), r.batchCfg.MaxChunkNumPerBatch))
+	}
+	if totalL1CommitCalldataSize > uint64(r.batchCfg.MaxL1CommitCalldataSizePerBatch) {
+		result.LimitViolations = append(result.LimitViolations, fmt.Sprintf("l1 commit calldata size %v exceeds max_l1_commit_calldata_size_per_batch %v", totalL1CommitCalldataSize, r.batchCfg.MaxL1CommitCalldataSizePerBatch))
+	}
+
+	totalL1MessagePoppedBefore := storedHeader.TotalL1MessagePopped() - storedHeader.L1MessagePopped()
+	reencodedHeader, err := batchCodec.NewBatchHeader(dbBatch.Index, totalL1MessagePoppedBefore, parentHash, chunks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to re-encode batch header, index: %v, err: %w", dbBatch.Index, err)
+	}
+	result.ReencodedHash = reencodedHeader.Hash()
+	result.Differences = diffBatchHeaders(storedHeader, reencodedHeader)
+	return result, reencodedHeader, nil
+}
+
+func (r *BatchReencoder) checkChunkLimits(index uint64, chunk *types.Chunk, calldataSize, commitGas uint64) []string {
+	var violations []string
+	if calldataSize > r.chunkCfg.MaxL1CommitCalldataSizePerChunk {
+		violations = append(violations, fmt.Sprintf("chunk %v l1 commit calldata size %v exceeds max_l1_commit_calldata_size_per_chunk %v", index, calldataSize, r.chunkCfg.MaxL1CommitCalldataSizePerChunk))
+	}
+	if commitGas := uint64(r.chunkCfg.GasCostIncreaseMultiplier * float64(commitGas)); commitGas > r.chunkCfg.MaxL1CommitGasPerChunk {
+		violations = append(violations, fmt.Sprintf("chunk %v l1 commit gas %v exceeds max_l1_commit_gas_per_chunk %v", index, commitGas, r.chunkCfg.MaxL1CommitGasPerChunk))
+	}
+	if blockNum := uint64(len(chunk.Blocks)); blockNum > r.chunkCfg.MaxBlockNumPerChunk {
+		violations = append(violations, fmt.Sprintf("chunk %v block num %v exceeds max_block_num_per_chunk %v", index, blockNum, r.chunkCfg.MaxBlockNumPerChunk))
+	}
+	return violations
+}
+
+// diffBatchHeaders returns the names of the fields which differ between the two batch headers,
+// the version and the parent hash are expected to differ and are not compared.
+func diffBatchHeaders(stored, reencoded codec.BatchHeader) []string {
+	var differences []string
+	if stored.BatchIndex() != reencoded.BatchIndex() {
+		differences = append(differences, "batch_index")
+	}
+	if stored.L1MessagePopped() != reencoded.L1MessagePopped() {
+		differences = append(differences, "l1_message_popped")
+	}
+	if stored.TotalL1MessagePopped() != reencoded.TotalL1MessagePopped() {
+		differences = append(differences, "total_l1_message_popped")
+	}
+	if stored.DataHash() != reencoded.DataHash() {
+		differences = append(differences, "data_hash")
+	}
+	if !bytes.Equal(stored.SkippedL1MessageBitmap(), reencoded.SkippedL1MessageBitmap()) {
+		differences = append(differences, "skipped_l1_message_bitmap")
+	}
+	return differences
+}
@@ -0,0 +1,78 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func testBatchReencoder(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	chunkCfg := &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             1,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}
+	cp := NewChunkProposer(context.Background(), chunkCfg, nil, db, nil)
+	cp.TryProposeChunk(context.Background()) // chunk1 contains block1
+	cp.TryProposeChunk(context.Background()) // chunk2 contains block2
+
+	batchCfg := &config.BatchProposerConfig{
+		MaxChunkNumPerBatch:             10,
+		MaxL1CommitGasPerBatch:          50000000000,
+		MaxL1CommitCalldataSizePerBatch: 1000000,
+		BatchTimeoutSec:                 0,
+		GasCostIncreaseMultiplier:       1.2,
+	}
+	bp := NewBatchProposer(batchCfg, nil, db, nil)
+	bp.TryProposeBatch(context.Background())
+
+	batchOrm := orm.NewBatch(db)
+	batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+
+	// re-encoding under the current codec version reproduces the stored batch
+	reencoder := NewBatchReencoder(context.Background(), chunkCfg, batchCfg, nil, db)
+	results, err := reencoder.Reencode(0, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, common.HexToHash(batches[0].Hash), results[0].StoredHash)
+	assert.Equal(t, results[0].StoredHash, results[0].ReencodedHash)
+	assert.Empty(t, results[0].Differences)
+	assert.Empty(t, results[0].LimitViolations)
+
+	results, err = reencoder.Reencode(0, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.NotEqual(t, results[0].StoredHash, results[0].ReencodedHash)
+	assert.Empty(t, results[0].Differences)
+
+	strictChunkCfg := *chunkCfg
+	strictChunkCfg.MaxL1CommitCalldataSizePerChunk = 1000
+	strictBatchCfg := *batchCfg
+	strictBatchCfg.MaxChunkNumPerBatch = 1
+	reencoder = NewBatchReencoder(context.Background(), &strictChunkCfg, &strictBatchCfg, nil, db)
+	results, err = reencoder.Reencode(0, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Len(t, results[0].LimitViolations, 2)
+}
@@ -0,0 +1,266 @@
+package watcher
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+var batchReportFileRegexp = regexp.MustCompile(`^batch_report_(\d+)_(\d+)\.csv$`)
+
+var batchReportHeader = []string{
+	"batch_index",
+	"batch_hash",
+	"commit_tx_hash",
+	"finalize_tx_hash",
+	"commit_gas_used",
+	"commit_blob_gas_used",
+	"finalize_gas_used",
+	"eth_spent_wei",
+	"l2_tx_num",
+	"cost_per_tx_wei",
+	"finalized_at",
+}
+
+// ReceiptFetcher is the subset of the layer 1 client used by the batch reporter.
+type ReceiptFetcher interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error)
+}
+
+// BatchReporter summarizes the layer 1 cost of every finalized batch and exports the summaries
+// as csv files into a directory consumed by BI pipelines.
+type BatchReporter struct {
+	l1Client ReceiptFetcher
+	batchOrm orm.BatchRepo
+	chunkOrm orm.ChunkRepo
+
+	exportDir           string
+	maxBatchesPerReport uint64
+	// nextBatchIndex is the index of the first batch not reported yet.
+	nextBatchIndex uint64
+
+	batchReporterCircleTotal      prometheus.Counter
+	batchReporterFailureTotal     prometheus.Counter
+	batchReporterReportedTotal    prometheus.Counter
+	batchReporterNextBatchIndex   prometheus.Gauge
+	batchReporterEthSpentWeiTotal prometheus.Counter
+}
+
+// NewBatchReporter creates a new BatchReporter instance, reporting resumes after the last batch
+// found in the export directory.
+func NewBatchReporter(cfg *config.BatchReportConfig, l1Client ReceiptFetcher, db *gorm.DB, reg prometheus.Registerer) (*BatchReporter, error) {
+	log.Debug("new batch reporter",
+		"reportIntervalSec", cfg.ReportIntervalSec,
+		"exportDir", cfg.ExportDir,
+		"maxBatchesPerReport", cfg.MaxBatchesPerReport)
+
+	if err := os.MkdirAll(cfg.ExportDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create batch report export dir %v: %w", cfg.ExportDir, err)
+	}
+	nextBatchIndex, err := nextReportedBatchIndex(cfg.ExportDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchReporter{
+		l1Client:            l1Client,
+		batchOrm:            orm.NewBatch(db),
+		chunkOrm:            orm.NewChunk(db),
+		exportDir:           cfg.ExportDir,
+		maxBatchesPerReport: cfg.MaxBatchesPerReport,
+		nextBatchIndex:      nextBatchIndex,
+
+		batchReporterCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_batch_reporter_circle_total",
+			Help: "Total number of batch reporter rounds.",
+		}),
+		batchReporterFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_batch_reporter_failure_total",
+			Help: "Total number of batch reporter rounds failed to complete.",
+		}),
+		batchReporterReportedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_batch_reporter_reported_batch_total",
+			Help: "Total number of batches exported by the batch reporter.",
+		}),
+		batchReporterNextBatchIndex: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_batch_reporter_next_batch_index",
+			Help: "The index of the first batch not reported yet.",
+		}),
+		batchReporterEthSpentWeiTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_batch_reporter_eth_spent_wei_total",
+			Help: "Total layer 1 fees in wei paid for the reported batches.",
+		}),
+	}, nil
+}
+
+// TryReportBatches exports the summaries of the finalized batches not reported yet into one csv file.
+func (r *BatchReporter) TryReportBatches(ctx context.Context) {
+	r.batchReporterCircleTotal.Inc()
+
+	fields := map[string]interface{}{
+		"index >= ?":        r.nextBatchIndex,
+		"rollup_status = ?": types.RollupFinalized,
+	}
+	batches, err := r.batchOrm.GetBatches(ctx, fields, nil, int(r.maxBatchesPerReport))
+	if err != nil {
+		r.batchReporterFailureTotal.Inc()
+		log.Error("batch reporter failed to get finalized batches", "next batch index", r.nextBatchIndex, "err", err)
+		return
+	}
+
+	// batches are finalized in order, only report the consecutive ones to keep the files gapless
+	var rows [][]string
+	var ethSpent float64
+	for i, batch := range batches {
+		if batch.Index != r.nextBatchIndex+uint64(i) {
+			break
+		}
+		row, spent, err := r.summarizeBatch(ctx, batch)
+		if err != nil {
+			r.batchReporterFailureTotal.Inc()
+			log.Error("batch reporter failed to summarize batch", "index", batch.Index, "hash", batch.Hash, "err", err)
+			return
+		}
+		rows = append(rows, row)
+		spentFloat, _ := new(big.Float).SetInt(spent).Float64()
+		ethSpent += spentFloat
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	startIndex, endIndex := r.nextBatchIndex, r.nextBatchIndex+uint64(len(rows))-1
+	if err := r.writeReport(startIndex, endIndex, rows); err != nil {
+		r.batchReporterFailureTotal.Inc()
+		log.Error("batch reporter failed to write report", "start index", startIndex, "end index", endIndex, "err", err)
+		return
+	}
+
+	r.nextBatchIndex = endIndex + 1
+	r.batchReporterReportedTotal.Add(float64(len(rows)))
+	r.batchReporterEthSpentWeiTotal.Add(ethSpent)
+	r.batchReporterNextBatchIndex.Set(float64(r.nextBatchIndex))
+	log.Info("batch reporter exported batches", "start index", startIndex, "end index", endIndex)
+}
+
+func (r *BatchReporter) summarizeBatch(ctx context.Context, batch *orm.Batch) ([]string, *big.Int, error) {
+	commitReceipt, err := r.l1Client.TransactionReceipt(ctx, common.HexToHash(batch.CommitTxHash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get commit tx receipt %v: %w", batch.CommitTxHash, err)
+	}
+	finalizeReceipt, err := r.l1Client.TransactionReceipt(ctx, common.HexToHash(batch.FinalizeTxHash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get finalize tx receipt %v: %w", batch.FinalizeTxHash, err)
+	}
+
+	chunks, err := r.chunkOrm.GetChunksInRange(ctx, batch.StartChunkIndex, batch.EndChunkIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	var l2TxNum uint64
+	for _, chunk := range chunks {
+		l2TxNum += uint64(chunk.TotalL2TxNum)
+	}
+
+	spent := new(big.Int).Add(receiptFee(commitReceipt), receiptFee(finalizeReceipt))
+	costPerTx := new(big.Int)
+	if l2TxNum > 0 {
+		costPerTx.Div(spent, new(big.Int).SetUint64(l2TxNum))
+	}
+
+	var finalizedAt string
+	if batch.FinalizedAt != nil {
+		finalizedAt = strconv.FormatInt(batch.FinalizedAt.Unix(), 10)
+	}
+
+	row := []string{
+		strconv.FormatUint(batch.Index, 10),
+		batch.Hash,
+		batch.CommitTxHash,
+		batch.FinalizeTxHash,
+		strconv.FormatUint(commitReceipt.GasUsed, 10),
+		strconv.FormatUint(commitReceipt.BlobGasUsed, 10),
+		strconv.FormatUint(finalizeReceipt.GasUsed, 10),
+		spent.String(),
+		strconv.FormatUint(l2TxNum, 10),
+		costPerTx.String(),
+		finalizedAt,
+	}
+	return row, spent, nil
+}
+
+// writeReport writes the rows into a temporary file first, so consumers never read a partial report.
+func (r *BatchReporter) writeReport(startIndex, endIndex uint64, rows [][]string) error {
+	name := filepath.Join(r.exportDir, fmt.Sprintf("batch_report_%d_%d.csv", startIndex, endIndex))
+	tmpName := name + ".tmp"
+
+	f, err := os.Create(tmpName)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err = w.Write(batchReportHeader); err == nil {
+		err = w.WriteAll(rows)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, name)
+}
+
+// receiptFee returns the execution and blob fees paid by a transaction.
+func receiptFee(receipt *gethTypes.Receipt) *big.Int {
+	fee := new(big.Int)
+	if receipt.EffectiveGasPrice != nil {
+		fee.Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	}
+	if receipt.BlobGasPrice != nil {
+		fee.Add(fee, new(big.Int).Mul(receipt.BlobGasPrice, new(big.Int).SetUint64(receipt.BlobGasUsed)))
+	}
+	return fee
+}
+
+// nextReportedBatchIndex returns the index following the last batch found in the existing reports.
+func nextReportedBatchIndex(exportDir string) (uint64, error) {
+	entries, err := os.ReadDir(exportDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read batch report export dir %v: %w", exportDir, err)
+	}
+
+	var next uint64
+	for _, entry := range entries {
+		matches := batchReportFileRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		endIndex, err := strconv.ParseUint(matches[2], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid batch report file name %v: %w", entry.Name(), err)
+		}
+		if endIndex+1 > next {
+			next = endIndex + 1
+		}
+	}
+	return next, nil
+}
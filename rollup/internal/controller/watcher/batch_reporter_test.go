@@ -0,0 +1,62 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/orm/fake"
+)
+
+type mockReceiptFetcher struct{}
+
+func (mockReceiptFetcher) TransactionReceipt(ctx context.Context, txHash common.Hash) (*gethTypes.Receipt, error) {
+	return &gethTypes.Receipt{GasUsed: 100000, EffectiveGasPrice: big.NewInt(10)}, nil
+}
+
+func testBatchReporter(t *testing.T) {
+	batchRepo := fake.NewBatchRepo()
+	batchRepo.AddBatches(
+		&orm.Batch{Index: 0, Hash: "0x00", StartChunkIndex: 0, EndChunkIndex: 0, RollupStatus: int16(types.RollupFinalized), CommitTxHash: "0x01", FinalizeTxHash: "0x02"},
+		&orm.Batch{Index: 1, Hash: "0x10", StartChunkIndex: 1, EndChunkIndex: 1, RollupStatus: int16(types.RollupFinalized), CommitTxHash: "0x11", FinalizeTxHash: "0x12"},
+		&orm.Batch{Index: 2, Hash: "0x20", StartChunkIndex: 2, EndChunkIndex: 2, RollupStatus: int16(types.RollupCommitted), CommitTxHash: "0x21"},
+	)
+	chunkRepo := fake.NewChunkRepo()
+	chunkRepo.AddChunks(&orm.Chunk{Index: 0, TotalL2TxNum: 4}, &orm.Chunk{Index: 1, TotalL2TxNum: 0}, &orm.Chunk{Index: 2, TotalL2TxNum: 1})
+
+	exportDir := t.TempDir()
+	reporter, err := NewBatchReporter(&config.BatchReportConfig{ExportDir: exportDir, MaxBatchesPerReport: 10}, mockReceiptFetcher{}, nil, nil)
+	assert.NoError(t, err)
+	reporter.batchOrm = batchRepo
+	reporter.chunkOrm = chunkRepo
+
+	reporter.TryReportBatches(context.Background())
+	assert.Equal(t, uint64(2), reporter.nextBatchIndex)
+	report, err := os.ReadFile(filepath.Join(exportDir, "batch_report_0_1.csv"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(report), "0,0x00,0x01,0x02,100000,0,100000,2000000,4,500000,\n")
+	assert.Contains(t, string(report), "1,0x10,0x11,0x12,100000,0,100000,2000000,0,0,\n")
+
+	// nothing new to report until the next batch is finalized
+	reporter.TryReportBatches(context.Background())
+	assert.Equal(t, uint64(2), reporter.nextBatchIndex)
+
+	assert.NoError(t, batchRepo.UpdateRollupStatus(context.Background(), "0x20", types.RollupFinalized))
+	reporter.TryReportBatches(context.Background())
+	assert.Equal(t, uint64(3), reporter.nextBatchIndex)
+
+	// reporting resumes after the last exported batch
+	reporter, err = NewBatchReporter(&config.BatchReportConfig{ExportDir: exportDir, MaxBatchesPerReport: 10}, mockReceiptFetcher{}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), reporter.nextBatchIndex)
+}
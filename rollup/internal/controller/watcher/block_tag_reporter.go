@@ -0,0 +1,265 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/log"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// committedRollupStatuses are the statuses of the batches whose data is available on layer 1.
+var committedRollupStatuses = []types.RollupStatus{types.RollupCommitted, types.RollupFinalizing, types.RollupFinalizeFailed, types.RollupFinalized}
+
+// HeaderFetcher is the subset of the layer 2 client used by the block tag reporter.
+type HeaderFetcher interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error)
+}
+
+// RPCCaller is the subset of the layer 2 admin rpc client used by the block tag reporter.
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// BlockTags are the block boundaries reported to the layer 2 node: the safe block is the last block
+// of the latest committed batch, the finalized block is the last block of the latest finalized batch.
+type BlockTags struct {
+	SafeBlockNumber      hexutil.Uint64 `json:"safeBlockNumber"`
+	SafeBlockHash        common.Hash    `json:"safeBlockHash"`
+	FinalizedBlockNumber hexutil.Uint64 `json:"finalizedBlockNumber"`
+	FinalizedBlockHash   common.Hash    `json:"finalizedBlockHash"`
+}
+
+// BlockTagReporter reports the committed and finalized batch boundaries to the layer 2 node,
+// so it serves the `safe` and `finalized` block tags accurately.
+type BlockTagReporter struct {
+	l2Client  HeaderFetcher
+	rpcCaller RPCCaller
+	batchOrm  orm.BatchRepo
+	chunkOrm  orm.ChunkRepo
+
+	method     string
+	maxRetries uint64
+	// retryInterval is the interval before the first retry, it doubles on each retry.
+	retryInterval time.Duration
+	// reported are the tags acknowledged by the layer 2 node, nil until the first report or after a failed check.
+	reported *BlockTags
+
+	blockTagReporterCircleTotal        prometheus.Counter
+	blockTagReporterFailureTotal       prometheus.Counter
+	blockTagReporterInconsistencyTotal prometheus.Counter
+	blockTagReporterSafeBlock          prometheus.Gauge
+	blockTagReporterFinalizedBlock     prometheus.Gauge
+}
+
+// NewBlockTagReporter creates a new BlockTagReporter instance.
+func NewBlockTagReporter(cfg *config.BlockTagConfig, l2Client HeaderFetcher, rpcCaller RPCCaller, db *gorm.DB, reg prometheus.Registerer) *BlockTagReporter {
+	log.Debug("new block tag reporter",
+		"method", cfg.Method,
+		"reportIntervalSec", cfg.ReportIntervalSec,
+		"maxRetries", cfg.MaxRetries)
+
+	return &BlockTagReporter{
+		l2Client:      l2Client,
+		rpcCaller:     rpcCaller,
+		batchOrm:      orm.NewBatch(db),
+		chunkOrm:      orm.NewChunk(db),
+		method:        cfg.Method,
+		maxRetries:    cfg.MaxRetries,
+		retryInterval: time.Second,
+
+		blockTagReporterCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_block_tag_reporter_circle_total",
+			Help: "Total number of block tag reporter rounds.",
+		}),
+		blockTagReporterFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_block_tag_reporter_failure_total",
+			Help: "Total number of block tag reporter rounds failed to complete.",
+		}),
+		blockTagReporterInconsistencyTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_block_tag_reporter_inconsistency_total",
+			Help: "Total number of batch boundaries not matching the blocks of the layer 2 node.",
+		}),
+		blockTagReporterSafeBlock: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_block_tag_reporter_safe_block_number",
+			Help: "The safe block number acknowledged by the layer 2 node.",
+		}),
+		blockTagReporterFinalizedBlock: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_block_tag_reporter_finalized_block_number",
+			Help: "The finalized block number acknowledged by the layer 2 node.",
+		}),
+	}
+}
+
+// TryReportBlockTags reports the latest batch boundaries to the layer 2 node if they changed, or if
+// the node no longer serves the reported finalized block, e.g. after a restart.
+func (r *BlockTagReporter) TryReportBlockTags(ctx context.Context) {
+	r.blockTagReporterCircleTotal.Inc()
+
+	tags, err := r.currentBlockTags(ctx)
+	if err != nil {
+		r.blockTagReporterFailureTotal.Inc()
+		log.Error("block tag reporter failed to get the batch boundaries", "err", err)
+		return
+	}
+	if tags == nil {
+		return
+	}
+
+	if r.reported != nil {
+		if tags.FinalizedBlockNumber < r.reported.FinalizedBlockNumber || tags.SafeBlockNumber < r.reported.SafeBlockNumber {
+			r.blockTagReporterInconsistencyTotal.Inc()
+			log.Error("block tag reporter found batch boundaries going backwards, not reporting them",
+				"finalized", tags.FinalizedBlockNumber, "reported finalized", r.reported.FinalizedBlockNumber,
+				"safe", tags.SafeBlockNumber, "reported safe", r.reported.SafeBlockNumber)
+			return
+		}
+		if *tags == *r.reported && r.checkFinalizedBlock(ctx, tags) {
+			return
+		}
+	}
+
+	if err := r.checkBoundaries(ctx, tags); err != nil {
+		r.blockTagReporterInconsistencyTotal.Inc()
+		log.Error("block tag reporter found batch boundaries inconsistent with the layer 2 node, not reporting them", "err", err)
+		return
+	}
+
+	if err := r.report(ctx, tags); err != nil {
+		r.reported = nil
+		r.blockTagReporterFailureTotal.Inc()
+		log.Error("block tag reporter failed to report the block tags", "method", r.method, "retries", r.maxRetries, "err", err)
+		return
+	}
+
+	r.reported = tags
+	r.blockTagReporterSafeBlock.Set(float64(tags.SafeBlockNumber))
+	r.blockTagReporterFinalizedBlock.Set(float64(tags.FinalizedBlockNumber))
+	log.Info("block tag reporter reported the block tags", "safe", tags.SafeBlockNumber, "finalized", tags.FinalizedBlockNumber)
+}
+
+// currentBlockTags returns the boundaries of the latest committed and finalized batches, nil if no batch is finalized yet.
+func (r *BlockTagReporter) currentBlockTags(ctx context.Context) (*BlockTags, error) {
+	var finalized, safe *orm.Batch
+	for _, status := range committedRollupStatuses {
+		fields := map[string]interface{}{"rollup_status = ?": status}
+		batches, err := r.batchOrm.GetBatches(ctx, fields, []string{"index DESC"}, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest batch of rollup status %v: %w", status, err)
+		}
+		if len(batches) == 0 {
+			continue
+		}
+		if status == types.RollupFinalized {
+			finalized = batches[0]
+		}
+		if safe == nil || batches[0].Index > safe.Index {
+			safe = batches[0]
+		}
+	}
+	if finalized == nil {
+		return nil, nil
+	}
+
+	safeNumber, safeHash, err := r.batchBoundary(ctx, safe)
+	if err != nil {
+		return nil, err
+	}
+	finalizedNumber, finalizedHash, err := r.batchBoundary(ctx, finalized)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockTags{
+		SafeBlockNumber:      hexutil.Uint64(safeNumber),
+		SafeBlockHash:        safeHash,
+		FinalizedBlockNumber: hexutil.Uint64(finalizedNumber),
+		FinalizedBlockHash:   finalizedHash,
+	}, nil
+}
+
+// batchBoundary returns the number and the hash of the last block of the batch.
+func (r *BlockTagReporter) batchBoundary(ctx context.Context, batch *orm.Batch) (uint64, common.Hash, error) {
+	chunks, err := r.chunkOrm.GetChunksInRange(ctx, batch.EndChunkIndex, batch.EndChunkIndex)
+	if err != nil {
+		return 0, common.Hash{}, fmt.Errorf("failed to get the last chunk of batch %v: %w", batch.Index, err)
+	}
+	if len(chunks) != 1 {
+		return 0, common.Hash{}, fmt.Errorf("last chunk %v of batch %v not found", batch.EndChunkIndex, batch.Index)
+	}
+	return chunks[0].EndBlockNumber, common.HexToHash(chunks[0].EndBlockHash), nil
+}
+
+// checkBoundaries checks the boundary blocks are the canonical blocks of the layer 2 node, so a
+// diverged node is never told to finalize blocks of another chain.
+func (r *BlockTagReporter) checkBoundaries(ctx context.Context, tags *BlockTags) error {
+	if tags.SafeBlockNumber < tags.FinalizedBlockNumber {
+		return fmt.Errorf("safe block %v is behind finalized block %v", tags.SafeBlockNumber, tags.FinalizedBlockNumber)
+	}
+	boundaries := []struct {
+		number uint64
+		hash   common.Hash
+	}{
+		{uint64(tags.FinalizedBlockNumber), tags.FinalizedBlockHash},
+		{uint64(tags.SafeBlockNumber), tags.SafeBlockHash},
+	}
+	for _, boundary := range boundaries {
+		header, err := r.l2Client.HeaderByNumber(ctx, new(big.Int).SetUint64(boundary.number))
+		if err != nil {
+			return fmt.Errorf("failed to get layer 2 block %v: %w", boundary.number, err)
+		}
+		if header.Hash() != boundary.hash {
+			return fmt.Errorf("layer 2 block %v hash mismatch, node: %v, batch: %v", boundary.number, header.Hash(), boundary.hash)
+		}
+	}
+	return nil
+}
+
+// checkFinalizedBlock returns whether the layer 2 node serves the reported finalized block.
+func (r *BlockTagReporter) checkFinalizedBlock(ctx context.Context, tags *BlockTags) bool {
+	header, err := r.l2Client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		log.Warn("block tag reporter failed to get the finalized block of the layer 2 node, reporting again", "err", err)
+		return false
+	}
+	if header.Hash() != tags.FinalizedBlockHash {
+		log.Warn("layer 2 node does not serve the reported finalized block, reporting again",
+			"node finalized", header.Number, "reported finalized", tags.FinalizedBlockNumber)
+		return false
+	}
+	return true
+}
+
+func (r *BlockTagReporter) report(ctx context.Context, tags *BlockTags) error {
+	retryInterval := r.retryInterval
+	var err error
+	for attempt := uint64(0); ; attempt++ {
+		if err = r.rpcCaller.CallContext(ctx, nil, r.method, tags); err == nil {
+			return nil
+		}
+		if attempt >= r.maxRetries {
+			return err
+		}
+		log.Warn("block tag reporter failed to report the block tags, retrying", "attempt", attempt+1, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(retryInterval):
+		}
+		retryInterval *= 2
+	}
+}
@@ -0,0 +1,117 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/orm/fake"
+)
+
+// mockL2Node serves the blocks of its chain and the finalized block set through the admin rpc.
+type mockL2Node struct {
+	headers   map[uint64]*gethTypes.Header
+	finalized *gethTypes.Header
+	failures  int
+	calls     int
+}
+
+func newMockL2Node(blockNum uint64) *mockL2Node {
+	node := &mockL2Node{headers: make(map[uint64]*gethTypes.Header)}
+	for i := uint64(0); i <= blockNum; i++ {
+		node.headers[i] = &gethTypes.Header{Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(1)}
+	}
+	return node
+}
+
+func (n *mockL2Node) HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error) {
+	if number.Int64() == int64(rpc.FinalizedBlockNumber) {
+		if n.finalized == nil {
+			return nil, errors.New("finalized block not found")
+		}
+		return n.finalized, nil
+	}
+	header, ok := n.headers[number.Uint64()]
+	if !ok {
+		return nil, errors.New("block not found")
+	}
+	return header, nil
+}
+
+func (n *mockL2Node) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	n.calls++
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("connection refused")
+	}
+	n.finalized = n.headers[uint64(args[0].(*BlockTags).FinalizedBlockNumber)]
+	return nil
+}
+
+func testBlockTagReporter(t *testing.T) {
+	node := newMockL2Node(10)
+	batchRepo := fake.NewBatchRepo()
+	batchRepo.AddBatches(
+		&orm.Batch{Index: 0, Hash: "0x00", StartChunkIndex: 0, EndChunkIndex: 0, RollupStatus: int16(types.RollupFinalized)},
+		&orm.Batch{Index: 1, Hash: "0x10", StartChunkIndex: 1, EndChunkIndex: 1, RollupStatus: int16(types.RollupCommitted)},
+		&orm.Batch{Index: 2, Hash: "0x20", StartChunkIndex: 2, EndChunkIndex: 2, RollupStatus: int16(types.RollupPending)},
+	)
+	chunkRepo := fake.NewChunkRepo()
+	chunkRepo.AddChunks(
+		&orm.Chunk{Index: 0, EndBlockNumber: 3, EndBlockHash: node.headers[3].Hash().Hex()},
+		&orm.Chunk{Index: 1, EndBlockNumber: 6, EndBlockHash: node.headers[6].Hash().Hex()},
+		&orm.Chunk{Index: 2, EndBlockNumber: 10, EndBlockHash: node.headers[10].Hash().Hex()},
+	)
+
+	reporter := NewBlockTagReporter(&config.BlockTagConfig{Method: "scroll_setBlockTags", MaxRetries: 1}, node, node, nil, nil)
+	reporter.batchOrm = batchRepo
+	reporter.chunkOrm = chunkRepo
+	reporter.retryInterval = time.Millisecond
+
+	// a failed report is retried within the round
+	node.failures = 1
+	reporter.TryReportBlockTags(context.Background())
+	assert.Equal(t, 2, node.calls)
+	assert.NotNil(t, reporter.reported)
+	assert.Equal(t, uint64(6), uint64(reporter.reported.SafeBlockNumber))
+	assert.Equal(t, uint64(3), uint64(reporter.reported.FinalizedBlockNumber))
+	assert.Equal(t, node.headers[3].Hash(), node.finalized.Hash())
+
+	// unchanged tags served by the node are not reported again
+	reporter.TryReportBlockTags(context.Background())
+	assert.Equal(t, 2, node.calls)
+
+	// the tags are reported again once the node lost them
+	node.finalized = nil
+	reporter.TryReportBlockTags(context.Background())
+	assert.Equal(t, 3, node.calls)
+	assert.Equal(t, node.headers[3].Hash(), node.finalized.Hash())
+
+	// the retries are exhausted
+	assert.NoError(t, batchRepo.UpdateRollupStatus(context.Background(), "0x10", types.RollupFinalized))
+	node.failures = 2
+	reporter.TryReportBlockTags(context.Background())
+	assert.Equal(t, 5, node.calls)
+	assert.Nil(t, reporter.reported)
+
+	reporter.TryReportBlockTags(context.Background())
+	assert.Equal(t, 6, node.calls)
+	assert.Equal(t, uint64(6), uint64(reporter.reported.FinalizedBlockNumber))
+
+	// the boundaries of a diverged node are not reported
+	assert.NoError(t, batchRepo.UpdateRollupStatus(context.Background(), "0x20", types.RollupCommitted))
+	node.headers[10] = &gethTypes.Header{Number: big.NewInt(10), Difficulty: big.NewInt(2)}
+	reporter.TryReportBlockTags(context.Background())
+	assert.Equal(t, 6, node.calls)
+	assert.Equal(t, uint64(6), uint64(reporter.reported.SafeBlockNumber))
+}
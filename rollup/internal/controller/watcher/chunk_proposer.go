@@ -4,44 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/common"
 	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/observability"
 	"scroll-tech/common/types"
 
 	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/orm"
 )
 
-// chunkRowConsumption is map(sub-circuit name => sub-circuit row count)
-type chunkRowConsumption map[string]uint64
-
-// add accumulates row consumption per sub-circuit
-func (crc *chunkRowConsumption) add(rowConsumption *gethTypes.RowConsumption) error {
-	if rowConsumption == nil {
-		return errors.New("rowConsumption is <nil>")
-	}
-	for _, subCircuit := range *rowConsumption {
-		(*crc)[subCircuit.Name] += subCircuit.RowNumber
-	}
-	return nil
-}
-
-// max finds the maximum row consumption among all sub-circuits
-func (crc *chunkRowConsumption) max() uint64 {
-	var max uint64
-	for _, value := range *crc {
-		if value > max {
-			max = value
-		}
-	}
-	return max
-}
+// ErrChunkWhatIfTooManyBlocks is returned when a what-if chunk holds more blocks than a chunk can,
+// so a request can't make the proposer load and encode an unbounded block range.
+var ErrChunkWhatIfTooManyBlocks = errors.New("what-if chunk exceeds the max number of blocks per chunk")
 
 // ChunkProposer proposes chunks based on available unchunked blocks.
 type ChunkProposer struct {
@@ -53,11 +36,29 @@ type ChunkProposer struct {
 
 	maxBlockNumPerChunk             uint64
 	maxTxNumPerChunk                uint64
+	maxL1MessageNumPerChunk         uint64
+	forks                           []*config.ChunkForkConfig
 	maxL1CommitGasPerChunk          uint64
 	maxL1CommitCalldataSizePerChunk uint64
+	maxCompressedSizePerChunk       uint64
 	maxRowConsumptionPerChunk       uint64
+	rowConsumptionRequiredFromBlock uint64
 	chunkTimeoutSec                 uint64
 	gasCostIncreaseMultiplier       float64
+	exactL1CommitCalldataSize       bool
+	quarantineBlockAfterFailures    uint64
+	recordEstimatorError            bool
+	estimatorErrorOrm               *orm.EstimatorError
+	// baseFeeForkBlock is the first block whose BlockContext carries the base fee, nil before the fork is scheduled
+	baseFeeForkBlock *uint64
+	// minBlockNum is changed at runtime through the admin api
+	minBlockNum atomic.Pointer[ProposalMinimum]
+	// the custom constraints checked along with the chunk limits
+	packingConstraints []namedPackingConstraint
+	// compressedSizes caches the compressed sizes of the unchunked blocks by block hash, which are
+	// estimated again on every tick until the blocks are chunked
+	compressedSizesMu sync.Mutex
+	compressedSizes   map[common.Hash]compressedSizeEntry
 
 	chunkProposerCircleTotal           prometheus.Counter
 	proposeChunkFailureTotal           prometheus.Counter
@@ -71,35 +72,68 @@ type ChunkProposer struct {
 	chunkBlocksNum                     prometheus.Gauge
 	chunkFirstBlockTimeoutReached      prometheus.Counter
 	chunkBlocksProposeNotEnoughTotal   prometheus.Counter
+	chunkBelowMinBlockNumTotal         prometheus.Counter
+	chunkSubCircuitRowConsumption      *prometheus.HistogramVec
+	chunkMaxSubCircuitTotal            *prometheus.CounterVec
+	chunkRowConsumptionLimitReached    *prometheus.CounterVec
+	chunkBlockQuarantinedTotal         prometheus.Counter
+	chunkInvalidBlockTotal             prometheus.Counter
+	chunkRowConsumptionMissingTotal    prometheus.Counter
+	chunkPackingConstraintReached      *prometheus.CounterVec
+	chunkReproposedTotal               prometheus.Counter
 }
 
-// NewChunkProposer creates a new ChunkProposer instance.
-func NewChunkProposer(ctx context.Context, cfg *config.ChunkProposerConfig, db *gorm.DB, reg prometheus.Registerer) *ChunkProposer {
+// NewChunkProposer creates a new ChunkProposer instance, baseFeeForkBlock is the base fee fork height of the l2 chain.
+func NewChunkProposer(ctx context.Context, cfg *config.ChunkProposerConfig, baseFeeForkBlock *uint64, db *gorm.DB, reg prometheus.Registerer) *ChunkProposer {
 	log.Debug("new chunk proposer",
 		"maxTxNumPerChunk", cfg.MaxTxNumPerChunk,
 		"maxL1CommitGasPerChunk", cfg.MaxL1CommitGasPerChunk,
 		"maxL1CommitCalldataSizePerChunk", cfg.MaxL1CommitCalldataSizePerChunk,
 		"maxRowConsumptionPerChunk", cfg.MaxRowConsumptionPerChunk,
 		"chunkTimeoutSec", cfg.ChunkTimeoutSec,
-		"gasCostIncreaseMultiplier", cfg.GasCostIncreaseMultiplier)
+		"gasCostIncreaseMultiplier", cfg.GasCostIncreaseMultiplier,
+		"exactL1CommitCalldataSize", cfg.ExactL1CommitCalldataSize,
+		"quarantineBlockAfterFailures", cfg.QuarantineBlockAfterFailures,
+		"recordEstimatorError", cfg.RecordEstimatorError,
+		"maxL1MessageNumPerChunk", cfg.MaxL1MessageNumPerChunk,
+		"minBlockNumPerChunk", cfg.MinBlockNumPerChunk,
+		"minBlockNumTimeoutSec", cfg.MinBlockNumTimeoutSec,
+		"maxCompressedSizePerChunk", cfg.MaxCompressedSizePerChunk,
+		"rowConsumptionRequiredFromBlock", cfg.RowConsumptionRequiredFromBlock,
+		"maxL2GasPerChunk", cfg.MaxL2GasPerChunk,
+		"forks", len(cfg.Forks))
 
-	return &ChunkProposer{
+	p := &ChunkProposer{
 		ctx:                             ctx,
 		db:                              db,
 		chunkOrm:                        orm.NewChunk(db),
 		l2BlockOrm:                      orm.NewL2Block(db),
 		maxBlockNumPerChunk:             cfg.MaxBlockNumPerChunk,
 		maxTxNumPerChunk:                cfg.MaxTxNumPerChunk,
+		maxL1MessageNumPerChunk:         cfg.MaxL1MessageNumPerChunk,
+		forks:                           cfg.Forks,
 		maxL1CommitGasPerChunk:          cfg.MaxL1CommitGasPerChunk,
 		maxL1CommitCalldataSizePerChunk: cfg.MaxL1CommitCalldataSizePerChunk,
+		maxCompressedSizePerChunk:       cfg.MaxCompressedSizePerChunk,
 		maxRowConsumptionPerChunk:       cfg.MaxRowConsumptionPerChunk,
+		rowConsumptionRequiredFromBlock: cfg.RowConsumptionRequiredFromBlock,
 		chunkTimeoutSec:                 cfg.ChunkTimeoutSec,
 		gasCostIncreaseMultiplier:       cfg.GasCostIncreaseMultiplier,
+		exactL1CommitCalldataSize:       cfg.ExactL1CommitCalldataSize,
+		quarantineBlockAfterFailures:    cfg.QuarantineBlockAfterFailures,
+		recordEstimatorError:            cfg.RecordEstimatorError,
+		estimatorErrorOrm:               orm.NewEstimatorError(db),
+		baseFeeForkBlock:                baseFeeForkBlock,
+		compressedSizes:                 make(map[common.Hash]compressedSizeEntry),
 
 		chunkProposerCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "rollup_propose_chunk_circle_total",
 			Help: "Total number of propose chunk total.",
 		}),
+		chunkReproposedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_reproposed_total",
+			Help: "Total number of unbatched chunks deleted to be proposed again under the current row consumption limit.",
+		}),
 		proposeChunkFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Name: "rollup_propose_chunk_failure_circle_total",
 			Help: "Total number of propose chunk failure total.",
@@ -144,147 +178,428 @@ func NewChunkProposer(ctx context.Context, cfg *config.ChunkProposerConfig, db *
 			Name: "rollup_propose_chunk_blocks_propose_not_enough_total",
 			Help: "Total number of chunk block propose not enough",
 		}),
+		chunkBelowMinBlockNumTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_below_min_block_num_total",
+			Help: "Total times of a timed out chunk waiting for more blocks because it is below the min number of blocks",
+		}),
+		chunkSubCircuitRowConsumption: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rollup_propose_chunk_sub_circuit_row_consumption",
+			Help:    "The row consumption of each sub-circuit in proposed chunks",
+			Buckets: prometheus.ExponentialBuckets(1024, 2, 11),
+		}, []string{"sub_circuit"}),
+		chunkMaxSubCircuitTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_max_sub_circuit_total",
+			Help: "Total times of a sub-circuit having the maximum row consumption in a proposed chunk",
+		}, []string{"sub_circuit"}),
+		chunkRowConsumptionLimitReached: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_row_consumption_limit_reached_total",
+			Help: "Total times of a sub-circuit reaching the row consumption limit and closing a chunk",
+		}, []string{"sub_circuit"}),
+		chunkBlockQuarantinedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_block_quarantined_total",
+			Help: "Total number of blocks quarantined into single-block chunks after failing too many times",
+		}),
+		chunkInvalidBlockTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_invalid_block_total",
+			Help: "Total number of chunk proposals failed by an invalid block",
+		}),
+		chunkRowConsumptionMissingTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_row_consumption_missing_total",
+			Help: "Total number of chunk proposals halted by a block missing its required row consumption",
+		}),
+		chunkPackingConstraintReached: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_propose_chunk_packing_constraint_reached_total",
+			Help: "Total times of a packing constraint rejecting a block and closing a chunk",
+		}, []string{"constraint"}),
 	}
+	p.minBlockNum.Store(&ProposalMinimum{Num: cfg.MinBlockNumPerChunk, TimeoutSec: cfg.MinBlockNumTimeoutSec})
+	if cfg.MaxL2GasPerChunk != 0 {
+		p.AddPackingConstraint("max_l2_gas", MaxL2GasConstraint(cfg.MaxL2GasPerChunk))
+	}
+	return p
+}
+
+// MinBlockNum returns the min number of blocks of a chunk proposed at the chunk timeout.
+func (p *ChunkProposer) MinBlockNum() ProposalMinimum {
+	return *p.minBlockNum.Load()
+}
+
+// DebugState returns the state of the chunk proposer for the debug server, the open chunk starts
+// at the unchunked block height.
+func (p *ChunkProposer) DebugState() interface{} {
+	ctx, cancel := context.WithTimeout(p.ctx, observability.StateDumpTimeout)
+	defer cancel()
+
+	state := map[string]interface{}{"min_block_num": p.MinBlockNum()}
+	if height, err := p.chunkOrm.GetUnchunkedBlockHeight(ctx); err != nil {
+		state["unchunked_block_height_error"] = err.Error()
+	} else {
+		state["unchunked_block_height"] = height
+	}
+	if height, err := p.l2BlockOrm.GetL2BlocksLatestHeight(ctx); err != nil {
+		state["latest_block_height_error"] = err.Error()
+	} else {
+		state["latest_block_height"] = height
+	}
+	return state
+}
+
+// SetMinBlockNum changes the min number of blocks of a chunk proposed at the chunk timeout
+// until the next restart, it applies from the next proposal round on.
+func (p *ChunkProposer) SetMinBlockNum(minimum ProposalMinimum) error {
+	if err := minimum.validate(p.maxBlockNumPerChunk, p.chunkTimeoutSec); err != nil {
+		return err
+	}
+	p.minBlockNum.Store(&minimum)
+	log.Info("chunk proposer min number of blocks changed", "num", minimum.Num, "timeoutSec", minimum.TimeoutSec)
+	return nil
 }
 
 // TryProposeChunk tries to propose a new chunk.
-func (p *ChunkProposer) TryProposeChunk() {
+func (p *ChunkProposer) TryProposeChunk(ctx context.Context) {
 	p.chunkProposerCircleTotal.Inc()
-	proposedChunk, err := p.proposeChunk()
+	proposedChunk, err := p.proposeChunk(ctx)
 	if err != nil {
 		p.proposeChunkFailureTotal.Inc()
 		log.Error("propose new chunk failed", "err", err)
 		return
 	}
 
-	if err := p.updateChunkInfoInDB(proposedChunk); err != nil {
+	if err := p.updateChunkInfoInDB(ctx, proposedChunk); err != nil {
 		p.proposeChunkUpdateInfoFailureTotal.Inc()
 		log.Error("update chunk info in orm failed", "err", err)
 	}
 }
 
-func (p *ChunkProposer) updateChunkInfoInDB(chunk *types.Chunk) error {
+// ReproposeChunksExceedingRowConsumption re-evaluates the chunks not yet in a batch against the current row
+// consumption limit, e.g. after the circuit capacity changed. The first chunk breaking it, unless already
+// proven, is deleted along with the chunks after it, so that their blocks are proposed again and split under
+// the current limit instead of failing at proving time. A failed chunk including a block which reached the
+// quarantine threshold through the prover failures is proposed again the same way, so the block is quarantined. It refuses to delete anything and returns an error if
+// one of these chunks is already batched, assigned to provers or proven; such chunks must be handled manually.
+// It must not run along with the batch proposer.
+func (p *ChunkProposer) ReproposeChunksExceedingRowConsumption() error {
+	chunks, err := p.chunkOrm.GetUnbatchedChunks(p.ctx)
+	if err != nil {
+		return fmt.Errorf("chunk-proposer failed to get unbatched chunks: %w", err)
+	}
+
+	for _, chunk := range chunks {
+		if types.ProvingStatus(chunk.ProvingStatus) == types.ProvingTaskVerified {
+			continue
+		}
+
+		blocks, err := p.l2BlockOrm.GetL2BlocksInRange(p.ctx, chunk.StartBlockNumber, chunk.EndBlockNumber)
+		if err != nil {
+			return fmt.Errorf("chunk-proposer failed to get blocks of chunk %v: %w", chunk.Index, err)
+		}
+		crc := types.ChunkRowConsumption{}
+		for _, block := range blocks {
+			if err := p.addRowConsumption(crc, block); err != nil {
+				return fmt.Errorf("chunk-proposer failed to add row consumption of block %v: %w", block.Header.Number, err)
+			}
+		}
+		var failures uint64
+		if p.quarantineBlockAfterFailures != 0 && types.ProvingStatus(chunk.ProvingStatus) == types.ProvingTaskFailed {
+			if failures, err = p.l2BlockOrm.GetMaxChunkProposalFailuresInRange(p.ctx, chunk.StartBlockNumber, chunk.EndBlockNumber); err != nil {
+				return fmt.Errorf("chunk-proposer failed to get the block failures of chunk %v: %w", chunk.Index, err)
+			}
+		}
+		if crc.Max() <= p.maxRowConsumptionPerChunk && (p.quarantineBlockAfterFailures == 0 || failures < p.quarantineBlockAfterFailures) {
+			continue
+		}
+
+		log.Warn("chunk exceeds the row consumption limit or includes a block to quarantine, proposing it again", "index", chunk.Index, "hash", chunk.Hash,
+			"startBlockNumber", chunk.StartBlockNumber, "endBlockNumber", chunk.EndBlockNumber,
+			"rowConsumption", crc.Max(), "maxRowConsumptionPerChunk", p.maxRowConsumptionPerChunk,
+			"blockFailures", failures, "quarantineBlockAfterFailures", p.quarantineBlockAfterFailures)
+
+		var deleted int64
+		err = p.db.Transaction(func(dbTX *gorm.DB) error {
+			var dbErr error
+			if deleted, dbErr = p.chunkOrm.DeleteUnbatchedChunksGEIndex(p.ctx, chunk.Index, dbTX); dbErr != nil {
+				return dbErr
+			}
+			return p.l2BlockOrm.ResetChunkHashGEHeight(p.ctx, chunk.StartBlockNumber, dbTX)
+		})
+		if err != nil {
+			return fmt.Errorf("chunk-proposer failed to delete chunks from index %v: %w", chunk.Index, err)
+		}
+		p.chunkReproposedTotal.Add(float64(deleted))
+		log.Info("deleted chunks to be proposed again", "fromIndex", chunk.Index, "deleted", deleted)
+		return nil
+	}
+	return nil
+}
+
+func (p *ChunkProposer) updateChunkInfoInDB(ctx context.Context, chunk *types.Chunk) error {
 	if chunk == nil {
 		return nil
 	}
 
 	p.proposeChunkUpdateInfoTotal.Inc()
-	err := p.db.Transaction(func(dbTX *gorm.DB) error {
-		dbChunk, err := p.chunkOrm.InsertChunk(p.ctx, chunk, dbTX)
+
+	var estimatorErrors []*orm.EstimatorError
+	if p.recordEstimatorError {
+		var err error
+		if estimatorErrors, err = collectEstimatorErrors(chunk); err != nil {
+			log.Warn("failed to collect estimator errors", "err", err)
+		}
+	}
+
+	err := p.db.WithContext(ctx).Transaction(func(dbTX *gorm.DB) error {
+		dbChunk, err := p.chunkOrm.InsertChunk(ctx, chunk, dbTX)
 		if err != nil {
 			log.Warn("ChunkProposer.InsertChunk failed", "chunk hash", chunk.Hash)
 			return err
 		}
-		if err := p.l2BlockOrm.UpdateChunkHashInRange(p.ctx, dbChunk.StartBlockNumber, dbChunk.EndBlockNumber, dbChunk.Hash, dbTX); err != nil {
+		if err := p.l2BlockOrm.UpdateChunkHashInRange(ctx, dbChunk.StartBlockNumber, dbChunk.EndBlockNumber, dbChunk.Hash, dbTX); err != nil {
 			log.Error("failed to update chunk_hash for l2_blocks", "chunk hash", chunk.Hash, "start block", 0, "end block", 0, "err", err)
 			return err
 		}
+		if err := p.estimatorErrorOrm.Accumulate(ctx, estimatorErrors, dbTX); err != nil {
+			log.Error("failed to accumulate estimator errors", "chunk hash", dbChunk.Hash, "err", err)
+			return err
+		}
 		return nil
 	})
 	return err
 }
 
-func (p *ChunkProposer) proposeChunk() (*types.Chunk, error) {
-	unchunkedBlockHeight, err := p.chunkOrm.GetUnchunkedBlockHeight(p.ctx)
+// collectEstimatorErrors sums up the estimated and actual commit cost of the chunk's l2 transactions
+// by transaction type and target contract.
+func collectEstimatorErrors(chunk *types.Chunk) ([]*orm.EstimatorError, error) {
+	type groupKey struct {
+		txType uint8
+		target string
+	}
+	groups := make(map[groupKey]*orm.EstimatorError)
+	var estimatorErrors []*orm.EstimatorError
+	for _, block := range chunk.Blocks {
+		costs, err := block.L1CommitCosts()
+		if err != nil {
+			return nil, err
+		}
+		for _, cost := range costs {
+			key := groupKey{txType: cost.TxType}
+			if cost.Target != nil {
+				key.target = cost.Target.Hex()
+			}
+			group, exists := groups[key]
+			if !exists {
+				group = &orm.EstimatorError{TxType: key.txType, Target: key.target}
+				groups[key] = group
+				estimatorErrors = append(estimatorErrors, group)
+			}
+			group.SampleCount++
+			group.EstimatedSizeSum += cost.EstimatedSize
+			group.ActualSizeSum += cost.ActualSize
+			group.EstimatedGasSum += cost.EstimatedGas
+			group.ActualGasSum += cost.ActualGas
+		}
+	}
+	return estimatorErrors, nil
+}
+
+func (p *ChunkProposer) proposeChunk(ctx context.Context) (*types.Chunk, error) {
+	unchunkedBlockHeight, err := p.chunkOrm.GetUnchunkedBlockHeight(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// select at most p.maxBlockNumPerChunk blocks
-	blocks, err := p.l2BlockOrm.GetL2WrappedBlocksGEHeight(p.ctx, unchunkedBlockHeight, int(p.maxBlockNumPerChunk))
+	blocks, err := p.l2BlockOrm.GetL2WrappedBlocksGEHeight(ctx, unchunkedBlockHeight, int(p.maxBlockNumPerChunk))
 	if err != nil {
 		return nil, err
 	}
+	p.pruneCompressedSizes(unchunkedBlockHeight)
 
 	if len(blocks) == 0 {
 		return nil, nil
 	}
 
-	var chunk types.Chunk
+	if err = p.validateBlocks(ctx, blocks); err != nil {
+		return nil, err
+	}
+
+	// a block which failed too many times, e.g. in a chunk failing proving, is proposed alone
+	if p.quarantineBlockAfterFailures != 0 {
+		failures, err := p.l2BlockOrm.GetChunkProposalFailures(ctx, blocks[0].Header.Number.Uint64())
+		if err != nil {
+			return nil, fmt.Errorf("chunk-proposer failed to get the failures of block %v: %w", blocks[0].Header.Number, err)
+		}
+		if failures >= p.quarantineBlockAfterFailures {
+			return p.quarantineBlock(ctx, blocks[0], failures, nil)
+		}
+	}
+
+	// a chunk never spans two forks, the blocks of the next fork start a new chunk
+	limits := p.chunkLimitsAt(blocks[0].Header.Number.Uint64())
+	var forkBoundaryReached bool
+	for i, block := range blocks {
+		if limits.nextForkBlock != 0 && block.Header.Number.Uint64() >= limits.nextForkBlock {
+			blocks = blocks[:i]
+			forkBoundaryReached = true
+			break
+		}
+	}
+
+	chunk := types.Chunk{BaseFeeForkBlock: p.baseFeeForkBlock}
 	var totalTxGasUsed uint64
 	var totalTxNum uint64
+	var totalL1MessageNum uint64
 	var totalL1CommitCalldataSize uint64
 	var totalL1CommitGas uint64
-	crc := chunkRowConsumption{}
+	var totalCompressedSize uint64
+	crc := types.ChunkRowConsumption{}
+
+	if p.exactL1CommitCalldataSize {
+		totalL1CommitCalldataSize = 1 // 1 byte numBlocks
+	}
 
 	for i, block := range blocks {
 		// metric values
 		lastTotalTxNum := totalTxNum
 		lastTotalL1CommitGas := totalL1CommitGas
-		lastCrcMax := crc.max()
+		lastCrc := crc.Clone()
 		lastTotalL1CommitCalldataSize := totalL1CommitCalldataSize
 		lastTotalTxGasUsed := totalTxGasUsed
 
 		totalTxGasUsed += block.Header.GasUsed
 		totalTxNum += uint64(len(block.Transactions))
-		totalL1CommitCalldataSize += block.EstimateL1CommitCalldataSize()
-		totalL1CommitGas = chunk.EstimateL1CommitGas()
+		totalL1MessageNum += numL1MessageTxs(block)
+		blockL1CommitCalldataSize, err := p.blockL1CommitCalldataSize(block)
+		if err != nil {
+			return nil, fmt.Errorf("chunk-proposer failed to calculate l1 commit calldata size: %w", err)
+		}
+		totalL1CommitCalldataSize += blockL1CommitCalldataSize
+		if p.maxCompressedSizePerChunk != 0 {
+			blockCompressedSize, err := p.blockCompressedSize(block)
+			if err != nil {
+				return nil, fmt.Errorf("chunk-proposer failed to estimate compressed size: %w", err)
+			}
+			totalCompressedSize += blockCompressedSize
+		}
+		totalL1CommitGas, err = chunk.EstimateL1CommitGas()
+		if err != nil {
+			return nil, fmt.Errorf("chunk-proposer failed to estimate l1 commit gas: %w", err)
+		}
 		totalOverEstimateL1CommitGas := uint64(p.gasCostIncreaseMultiplier * float64(totalL1CommitGas))
-		if err := crc.add(block.RowConsumption); err != nil {
+		if err := p.addRowConsumption(crc, block); err != nil {
 			return nil, fmt.Errorf("chunk-proposer failed to update chunk row consumption: %v", err)
 		}
-		crcMax := crc.max()
+		crcMax := crc.Max()
+		constraintName, constraintErr := p.checkPackingConstraints(&chunk, block)
 
-		if totalTxNum > p.maxTxNumPerChunk ||
+		if totalTxNum > limits.maxTxNum ||
+			(limits.maxL1MessageNum != 0 && totalL1MessageNum > limits.maxL1MessageNum) ||
 			totalL1CommitCalldataSize > p.maxL1CommitCalldataSizePerChunk ||
+			(p.maxCompressedSizePerChunk != 0 && totalCompressedSize > p.maxCompressedSizePerChunk) ||
 			totalOverEstimateL1CommitGas > p.maxL1CommitGasPerChunk ||
-			crcMax > p.maxRowConsumptionPerChunk {
+			crcMax > p.maxRowConsumptionPerChunk ||
+			constraintErr != nil {
 			// Check if the first block breaks hard limits.
 			// If so, it indicates there are bugs in sequencer, manual fix is needed.
 			if i == 0 {
-				if totalTxNum > p.maxTxNumPerChunk {
-					return nil, fmt.Errorf(
-						"the first block exceeds l2 tx number limit; block number: %v, number of transactions: %v, max transaction number limit: %v",
+				if totalTxNum > limits.maxTxNum {
+					return p.handleFirstBlockFailure(ctx, block, "max_tx_num_per_chunk", fmt.Errorf(
+						"the first block exceeds l2 tx number limit; block number: %v, number of transactions: %v, max transaction number limit: %v, fork: %v",
 						block.Header.Number,
 						totalTxNum,
-						p.maxTxNumPerChunk,
-					)
+						limits.maxTxNum,
+						limits.fork,
+					))
+				}
+
+				if limits.maxL1MessageNum != 0 && totalL1MessageNum > limits.maxL1MessageNum {
+					return p.handleFirstBlockFailure(ctx, block, "max_l1_message_num_per_chunk", fmt.Errorf(
+						"the first block exceeds l1 message number limit; block number: %v, number of l1 messages: %v, max l1 message number limit: %v, fork: %v",
+						block.Header.Number,
+						totalL1MessageNum,
+						limits.maxL1MessageNum,
+						limits.fork,
+					))
 				}
 
 				if totalOverEstimateL1CommitGas > p.maxL1CommitGasPerChunk {
-					return nil, fmt.Errorf(
+					return p.handleFirstBlockFailure(ctx, block, "max_l1_commit_gas_per_chunk", fmt.Errorf(
 						"the first block exceeds l1 commit gas limit; block number: %v, commit gas: %v, max commit gas limit: %v",
 						block.Header.Number,
 						totalL1CommitGas,
 						p.maxL1CommitGasPerChunk,
-					)
+					))
 				}
 
 				if totalL1CommitCalldataSize > p.maxL1CommitCalldataSizePerChunk {
-					return nil, fmt.Errorf(
+					return p.handleFirstBlockFailure(ctx, block, "max_l1_commit_calldata_size_per_chunk", fmt.Errorf(
 						"the first block exceeds l1 commit calldata size limit; block number: %v, calldata size: %v, max calldata size limit: %v",
 						block.Header.Number,
 						totalL1CommitCalldataSize,
 						p.maxL1CommitCalldataSizePerChunk,
-					)
+					))
+				}
+
+				if p.maxCompressedSizePerChunk != 0 && totalCompressedSize > p.maxCompressedSizePerChunk {
+					return p.handleFirstBlockFailure(ctx, block, "max_compressed_size_per_chunk", fmt.Errorf(
+						"the first block exceeds compressed size limit; block number: %v, compressed size: %v, max compressed size limit: %v",
+						block.Header.Number,
+						totalCompressedSize,
+						p.maxCompressedSizePerChunk,
+					))
 				}
 
 				if crcMax > p.maxRowConsumptionPerChunk {
-					return nil, fmt.Errorf(
+					return p.handleFirstBlockFailure(ctx, block, "max_row_consumption_per_chunk", fmt.Errorf(
 						"the first block exceeds row consumption limit; block number: %v, row consumption: %v, max: %v, limit: %v",
 						block.Header.Number,
 						crc,
 						crcMax,
 						p.maxRowConsumptionPerChunk,
-					)
+					))
+				}
+
+				if constraintErr != nil {
+					return p.handleFirstBlockFailure(ctx, block, constraintName, fmt.Errorf(
+						"the first block violates packing constraint %v; block number: %v: %w",
+						constraintName,
+						block.Header.Number,
+						constraintErr,
+					))
 				}
 			}
 
 			log.Debug("breaking limit condition in chunking",
 				"totalTxNum", totalTxNum,
-				"maxTxNumPerChunk", p.maxTxNumPerChunk,
+				"maxTxNumPerChunk", limits.maxTxNum,
+				"totalL1MessageNum", totalL1MessageNum,
+				"maxL1MessageNumPerChunk", limits.maxL1MessageNum,
+				"fork", limits.fork,
 				"currentL1CommitCalldataSize", totalL1CommitCalldataSize,
 				"maxL1CommitCalldataSizePerChunk", p.maxL1CommitCalldataSizePerChunk,
+				"currentCompressedSize", totalCompressedSize,
+				"maxCompressedSizePerChunk", p.maxCompressedSizePerChunk,
 				"currentOverEstimateL1CommitGas", totalOverEstimateL1CommitGas,
 				"maxL1CommitGasPerChunk", p.maxL1CommitGasPerChunk,
 				"chunkRowConsumptionMax", crcMax,
 				"chunkRowConsumption", crc,
-				"p.maxRowConsumptionPerChunk", p.maxRowConsumptionPerChunk)
+				"p.maxRowConsumptionPerChunk", p.maxRowConsumptionPerChunk,
+				"packingConstraint", constraintName,
+				"packingConstraintErr", constraintErr)
 
 			p.chunkTxNum.Set(float64(lastTotalTxNum))
 			p.chunkEstimateL1CommitGas.Set(float64(lastTotalL1CommitGas))
 			p.totalL1CommitCalldataSize.Set(float64(lastTotalL1CommitCalldataSize))
-			p.maxTxConsumption.Set(float64(lastCrcMax))
+			if crcMax > p.maxRowConsumptionPerChunk {
+				for subCircuit, rows := range crc {
+					if rows > p.maxRowConsumptionPerChunk {
+						p.chunkRowConsumptionLimitReached.WithLabelValues(subCircuit).Inc()
+					}
+				}
+			}
+			if constraintErr != nil {
+				p.chunkPackingConstraintReached.WithLabelValues(constraintName).Inc()
+			}
+			p.recordChunkRowConsumption(lastCrc)
 			p.totalTxGasUsed.Set(float64(lastTotalTxGasUsed))
 			p.chunkBlocksNum.Set(float64(len(chunk.Blocks)))
 			return &chunk, nil
@@ -293,9 +608,27 @@ func (p *ChunkProposer) proposeChunk() (*types.Chunk, error) {
 	}
 
 	currentTimeSec := uint64(time.Now().Unix())
-	if chunk.Blocks[0].Header.Time+p.chunkTimeoutSec < currentTimeSec ||
-		uint64(len(chunk.Blocks)) == p.maxBlockNumPerChunk {
-		if chunk.Blocks[0].Header.Time+p.chunkTimeoutSec < currentTimeSec {
+	timedOut := chunk.Blocks[0].Header.Time+p.chunkTimeoutSec < currentTimeSec
+	// the blocks of the next fork cannot be added, the chunk is proposed whatever its size
+	if minimum := p.MinBlockNum(); timedOut && !forkBoundaryReached && minimum.waits(uint64(len(chunk.Blocks)), chunk.Blocks[0].Header.Time, currentTimeSec) {
+		log.Debug("timed out chunk is below the min number of blocks",
+			"start block number", chunk.Blocks[0].Header.Number,
+			"block count", len(chunk.Blocks),
+			"min block count", minimum.Num,
+		)
+		p.chunkBelowMinBlockNumTotal.Inc()
+		return nil, nil
+	}
+
+	if timedOut || uint64(len(chunk.Blocks)) == p.maxBlockNumPerChunk || forkBoundaryReached {
+		if forkBoundaryReached {
+			log.Info("reached fork boundary in chunk",
+				"start block number", chunk.Blocks[0].Header.Number,
+				"block count", len(chunk.Blocks),
+				"fork", limits.fork,
+				"next fork block", limits.nextForkBlock,
+			)
+		} else if timedOut {
 			log.Warn("first block timeout",
 				"block number", chunk.Blocks[0].Header.Number,
 				"block timestamp", chunk.Blocks[0].Header.Time,
@@ -312,7 +645,7 @@ func (p *ChunkProposer) proposeChunk() (*types.Chunk, error) {
 		p.chunkTxNum.Set(float64(totalTxNum))
 		p.chunkEstimateL1CommitGas.Set(float64(totalL1CommitGas))
 		p.totalL1CommitCalldataSize.Set(float64(totalL1CommitCalldataSize))
-		p.maxTxConsumption.Set(float64(crc.max()))
+		p.recordChunkRowConsumption(crc)
 		p.totalTxGasUsed.Set(float64(totalTxGasUsed))
 		p.chunkBlocksNum.Set(float64(len(chunk.Blocks)))
 		return &chunk, nil
@@ -322,3 +655,424 @@ func (p *ChunkProposer) proposeChunk() (*types.Chunk, error) {
 	p.chunkBlocksProposeNotEnoughTotal.Inc()
 	return nil, nil
 }
+
+// chunkLimits are the protocol chunk limits of a fork.
+type chunkLimits struct {
+	fork            string
+	maxTxNum        uint64
+	maxL1MessageNum uint64
+	// nextForkBlock is the first block of the next fork, 0 if there is no next fork.
+	nextForkBlock uint64
+}
+
+// chunkLimitsAt returns the chunk limits of the fork active at the given block.
+func (p *ChunkProposer) chunkLimitsAt(blockNumber uint64) chunkLimits {
+	limits := chunkLimits{
+		fork:            "genesis",
+		maxTxNum:        p.maxTxNumPerChunk,
+		maxL1MessageNum: p.maxL1MessageNumPerChunk,
+	}
+	for _, fork := range p.forks {
+		if blockNumber < fork.Block {
+			limits.nextForkBlock = fork.Block
+			break
+		}
+		limits = chunkLimits{
+			fork:            fork.Name,
+			maxTxNum:        fork.MaxTxNumPerChunk,
+			maxL1MessageNum: fork.MaxL1MessageNumPerChunk,
+		}
+	}
+	return limits
+}
+
+// validateBlocks validates the blocks following the latest chunk, an invalid block fails the proposal before
+// the chunk is committed and proven.
+func (p *ChunkProposer) validateBlocks(ctx context.Context, blocks []*types.WrappedBlock) error {
+	var totalL1MessagePoppedBefore uint64
+	latestChunk, err := p.chunkOrm.GetLatestChunk(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("chunk-proposer failed to get latest chunk: %w", err)
+	}
+	if latestChunk != nil {
+		totalL1MessagePoppedBefore = latestChunk.TotalL1MessagesPoppedBefore + uint64(latestChunk.TotalL1MessagesPoppedInChunk)
+	}
+
+	for i, block := range blocks {
+		var parent *gethTypes.Header
+		if i > 0 {
+			parent = blocks[i-1].Header
+		}
+		// the row consumption is required by the row consumption limit of the chunk, a chunk of a block
+		// without it may exceed the circuit capacity and never be proven, so the proposal halts on it
+		if err = block.Validate(parent, totalL1MessagePoppedBefore, p.requiresRowConsumption(block)); err != nil {
+			p.chunkInvalidBlockTotal.Inc()
+			if errors.Is(err, types.ErrRowConsumptionMissing) {
+				p.chunkRowConsumptionMissingTotal.Inc()
+				log.Error("chunk proposal halted, the row consumption of the block is missing",
+					"block number", block.Header.Number,
+					"block hash", block.Header.Hash().Hex(),
+					"rowConsumptionRequiredFromBlock", p.rowConsumptionRequiredFromBlock)
+			}
+			return fmt.Errorf("chunk-proposer found an invalid block: %w", err)
+		}
+		totalL1MessagePoppedBefore += block.NumL1Messages(totalL1MessagePoppedBefore)
+	}
+	return nil
+}
+
+// requiresRowConsumption returns whether the row consumption of the block is required.
+func (p *ChunkProposer) requiresRowConsumption(block *types.WrappedBlock) bool {
+	return block.Header.Number.Uint64() >= p.rowConsumptionRequiredFromBlock
+}
+
+// addRowConsumption adds the row consumption of the block to the chunk row consumption, a block before
+// rowConsumptionRequiredFromBlock without it adds nothing.
+func (p *ChunkProposer) addRowConsumption(crc types.ChunkRowConsumption, block *types.WrappedBlock) error {
+	if block.RowConsumption == nil && !p.requiresRowConsumption(block) {
+		return nil
+	}
+	return crc.Add(block.RowConsumption)
+}
+
+// numL1MessageTxs returns the number of l1 messages included in a block.
+func numL1MessageTxs(block *types.WrappedBlock) uint64 {
+	var num uint64
+	for _, txData := range block.Transactions {
+		if txData.Type == gethTypes.L1MessageTxType {
+			num++
+		}
+	}
+	return num
+}
+
+// blockL1CommitCalldataSize returns the l1 commit calldata size of a block with the configured precision.
+func (p *ChunkProposer) blockL1CommitCalldataSize(block *types.WrappedBlock) (uint64, error) {
+	if p.exactL1CommitCalldataSize {
+		return block.L1CommitCalldataSize()
+	}
+	return block.EstimateL1CommitCalldataSize()
+}
+
+// compressedSizeEntry is the cached compressed size of a block.
+type compressedSizeEntry struct {
+	number uint64
+	size   uint64
+}
+
+// blockCompressedSize returns the compressed size of the block, compressing its tx payload only on the first call.
+func (p *ChunkProposer) blockCompressedSize(block *types.WrappedBlock) (uint64, error) {
+	hash := block.Header.Hash()
+	p.compressedSizesMu.Lock()
+	cached, exists := p.compressedSizes[hash]
+	p.compressedSizesMu.Unlock()
+	if exists {
+		return cached.size, nil
+	}
+
+	size, err := block.EstimateCompressedSize()
+	if err != nil {
+		return 0, err
+	}
+
+	p.compressedSizesMu.Lock()
+	defer p.compressedSizesMu.Unlock()
+	p.compressedSizes[hash] = compressedSizeEntry{number: block.Header.Number.Uint64(), size: size}
+	return size, nil
+}
+
+// pruneCompressedSizes drops the cached compressed sizes of the blocks below the given height, which are chunked already.
+func (p *ChunkProposer) pruneCompressedSizes(height uint64) {
+	p.compressedSizesMu.Lock()
+	defer p.compressedSizesMu.Unlock()
+	for hash, cached := range p.compressedSizes {
+		if cached.number < height {
+			delete(p.compressedSizes, hash)
+		}
+	}
+}
+
+// handleFirstBlockFailure counts the chunk limit broken by the first block of a chunk as a failure of the block.
+// Every failure is persisted, so a block breaking the same limit on every tick reaches quarantineBlockAfterFailures
+// failures and is then quarantined into a single-block chunk instead of stalling the chunk proposal.
+func (p *ChunkProposer) handleFirstBlockFailure(ctx context.Context, block *types.WrappedBlock, reason string, err error) (*types.Chunk, error) {
+	if p.quarantineBlockAfterFailures == 0 {
+		return nil, err
+	}
+
+	blockNumber := block.Header.Number.Uint64()
+	failures, dbErr := p.l2BlockOrm.RecordChunkProposalFailure(ctx, blockNumber, reason)
+	if dbErr != nil {
+		return nil, fmt.Errorf("%w, failed to record the failure: %v", err, dbErr)
+	}
+	if failures < p.quarantineBlockAfterFailures {
+		return nil, fmt.Errorf("%w, failures: %v, quarantine threshold: %v", err, failures, p.quarantineBlockAfterFailures)
+	}
+	return p.quarantineBlock(ctx, block, failures, err)
+}
+
+// quarantineBlock proposes the block alone in a chunk, so that the blocks after it are chunked again,
+// and notifies the operator through the error log and the quarantine metric.
+func (p *ChunkProposer) quarantineBlock(ctx context.Context, block *types.WrappedBlock, failures uint64, err error) (*types.Chunk, error) {
+	blockNumber := block.Header.Number.Uint64()
+	if dbErr := p.l2BlockOrm.UpdateChunkProposalStatus(ctx, blockNumber, types.ChunkProposalStatusQuarantined); dbErr != nil {
+		return nil, fmt.Errorf("chunk-proposer failed to quarantine block %v: %w", blockNumber, dbErr)
+	}
+	log.Error("quarantining block into a single-block chunk, manual check is needed",
+		"block number", blockNumber,
+		"block hash", block.Header.Hash().Hex(),
+		"failures", failures,
+		"err", err)
+	p.chunkBlockQuarantinedTotal.Inc()
+	p.chunkBlocksNum.Set(1)
+	return &types.Chunk{Blocks: []*types.WrappedBlock{block}, BaseFeeForkBlock: p.baseFeeForkBlock}, nil
+}
+
+// recordChunkRowConsumption records the row consumption metrics of a proposed chunk.
+func (p *ChunkProposer) recordChunkRowConsumption(crc types.ChunkRowConsumption) {
+	for subCircuit, rows := range crc {
+		p.chunkSubCircuitRowConsumption.WithLabelValues(subCircuit).Observe(float64(rows))
+	}
+	subCircuit, max := crc.MaxSubCircuit()
+	p.maxTxConsumption.Set(float64(max))
+	if subCircuit != "" {
+		p.chunkMaxSubCircuitTotal.WithLabelValues(subCircuit).Inc()
+	}
+}
+
+// ChunkEstimation is the estimated l1 commit cost and row consumption of a chunk.
+type ChunkEstimation struct {
+	StartBlockNumber          uint64            `json:"start_block_number"`
+	EndBlockNumber            uint64            `json:"end_block_number"`
+	NumBlocks                 uint64            `json:"num_blocks"`
+	TotalTxNum                uint64            `json:"total_tx_num"`
+	TotalL1MessageNum         uint64            `json:"total_l1_message_num"`
+	TotalL1CommitCalldataSize uint64            `json:"total_l1_commit_calldata_size"`
+	TotalL1CommitGas          uint64            `json:"total_l1_commit_gas"`
+	RowConsumption            map[string]uint64 `json:"row_consumption"`
+	MaxRowConsumption         uint64            `json:"max_row_consumption"`
+	// TotalCompressedSize is the sum of the compressed sizes of the blocks, only estimated with the compressed size limit.
+	TotalCompressedSize uint64 `json:"total_compressed_size,omitempty"`
+	// ExceededLimits lists the chunk limits that are broken by the chunk.
+	ExceededLimits []string `json:"exceeded_limits"`
+}
+
+// EstimateChunk estimates the l1 commit calldata size, l1 commit gas and row consumption of the given blocks
+// if they were proposed as one chunk, and checks them against the configured chunk limits.
+func (p *ChunkProposer) EstimateChunk(blocks []*types.WrappedBlock) (*ChunkEstimation, error) {
+	estimation := &ChunkEstimation{
+		RowConsumption: map[string]uint64{},
+		ExceededLimits: []string{},
+	}
+	if len(blocks) == 0 {
+		return estimation, nil
+	}
+
+	chunk := types.Chunk{Blocks: blocks, BaseFeeForkBlock: p.baseFeeForkBlock}
+	crc := types.ChunkRowConsumption{}
+	if p.exactL1CommitCalldataSize {
+		estimation.TotalL1CommitCalldataSize = 1 // 1 byte numBlocks
+	}
+	for _, block := range blocks {
+		blockL1CommitCalldataSize, err := p.blockL1CommitCalldataSize(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate l1 commit calldata size of block %v: %w", block.Header.Number, err)
+		}
+		if err := p.addRowConsumption(crc, block); err != nil {
+			return nil, fmt.Errorf("failed to add row consumption of block %v: %w", block.Header.Number, err)
+		}
+		estimation.TotalTxNum += uint64(len(block.Transactions))
+		estimation.TotalL1MessageNum += numL1MessageTxs(block)
+		estimation.TotalL1CommitCalldataSize += blockL1CommitCalldataSize
+		if p.maxCompressedSizePerChunk != 0 {
+			blockCompressedSize, err := p.blockCompressedSize(block)
+			if err != nil {
+				return nil, fmt.Errorf("failed to estimate compressed size of block %v: %w", block.Header.Number, err)
+			}
+			estimation.TotalCompressedSize += blockCompressedSize
+		}
+	}
+
+	estimation.StartBlockNumber = blocks[0].Header.Number.Uint64()
+	estimation.EndBlockNumber = blocks[len(blocks)-1].Header.Number.Uint64()
+	estimation.NumBlocks = uint64(len(blocks))
+	totalL1CommitGas, err := chunk.EstimateL1CommitGas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate l1 commit gas: %w", err)
+	}
+	estimation.TotalL1CommitGas = uint64(p.gasCostIncreaseMultiplier * float64(totalL1CommitGas))
+	estimation.RowConsumption = crc
+	estimation.MaxRowConsumption = crc.Max()
+
+	if estimation.NumBlocks > p.maxBlockNumPerChunk {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "max_block_num_per_chunk")
+	}
+	limits := p.chunkLimitsAt(estimation.StartBlockNumber)
+	if limits.nextForkBlock != 0 && estimation.EndBlockNumber >= limits.nextForkBlock {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "fork_boundary")
+	}
+	if estimation.TotalTxNum > limits.maxTxNum {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "max_tx_num_per_chunk")
+	}
+	if limits.maxL1MessageNum != 0 && estimation.TotalL1MessageNum > limits.maxL1MessageNum {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "max_l1_message_num_per_chunk")
+	}
+	if estimation.TotalL1CommitGas > p.maxL1CommitGasPerChunk {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "max_l1_commit_gas_per_chunk")
+	}
+	if estimation.TotalL1CommitCalldataSize > p.maxL1CommitCalldataSizePerChunk {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "max_l1_commit_calldata_size_per_chunk")
+	}
+	if p.maxCompressedSizePerChunk != 0 && estimation.TotalCompressedSize > p.maxCompressedSizePerChunk {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "max_compressed_size_per_chunk")
+	}
+	if estimation.MaxRowConsumption > p.maxRowConsumptionPerChunk {
+		estimation.ExceededLimits = append(estimation.ExceededLimits, "max_row_consumption_per_chunk")
+	}
+	for i, block := range blocks {
+		if constraintName, constraintErr := p.checkPackingConstraints(&types.Chunk{Blocks: blocks[:i], BaseFeeForkBlock: p.baseFeeForkBlock}, block); constraintErr != nil {
+			estimation.ExceededLimits = append(estimation.ExceededLimits, constraintName)
+			break
+		}
+	}
+	return estimation, nil
+}
+
+// WhatIf estimates the current open chunk, i.e. the unchunked blocks before startBlockNumber, and the chunk
+// it would become if the candidate blocks were added to it. If candidates is empty, the blocks in the range
+// [startBlockNumber, endBlockNumber] are loaded from the database. The open chunk and the candidate blocks
+// together can't hold more than the max number of blocks per chunk.
+func (p *ChunkProposer) WhatIf(ctx context.Context, startBlockNumber, endBlockNumber uint64, candidates []*types.WrappedBlock) (*ChunkEstimation, *ChunkEstimation, error) {
+	unchunkedBlockHeight, err := p.chunkOrm.GetUnchunkedBlockHeight(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if startBlockNumber < unchunkedBlockHeight {
+		return nil, nil, fmt.Errorf("start block number %v is already chunked, unchunked block height: %v", startBlockNumber, unchunkedBlockHeight)
+	}
+
+	numCandidates := uint64(len(candidates))
+	if numCandidates == 0 {
+		if endBlockNumber < startBlockNumber {
+			return nil, nil, fmt.Errorf("end block number %v is less than start block number %v", endBlockNumber, startBlockNumber)
+		}
+		numCandidates = endBlockNumber - startBlockNumber + 1
+	}
+	numOpenBlocks := startBlockNumber - unchunkedBlockHeight
+	if numOpenBlocks > p.maxBlockNumPerChunk || numCandidates > p.maxBlockNumPerChunk-numOpenBlocks {
+		return nil, nil, fmt.Errorf("%w, open blocks: %v, candidate blocks: %v, max: %v",
+			ErrChunkWhatIfTooManyBlocks, numOpenBlocks, numCandidates, p.maxBlockNumPerChunk)
+	}
+
+	var openBlocks []*types.WrappedBlock
+	if startBlockNumber > unchunkedBlockHeight {
+		openBlocks, err = p.l2BlockOrm.GetL2BlocksInRange(ctx, unchunkedBlockHeight, startBlockNumber-1)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates, err = p.l2BlockOrm.GetL2BlocksInRange(ctx, startBlockNumber, endBlockNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	for i, block := range candidates {
+		if block.Header == nil || block.Header.Number == nil || block.Header.Number.Uint64() != startBlockNumber+uint64(i) {
+			return nil, nil, fmt.Errorf("candidate block %v is not consecutive, expected block number: %v", i, startBlockNumber+uint64(i))
+		}
+	}
+
+	current, err := p.EstimateChunk(openBlocks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proposed, err := p.EstimateChunk(append(openBlocks, candidates...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, proposed, nil
+}
+
+// ChunkBudget is the remaining room of the open chunk before each chunk limit is reached.
+type ChunkBudget struct {
+	NumBlocks uint64 `json:"num_blocks"`
+	TxNum     uint64 `json:"tx_num"`
+	// L1MessageNum is omitted if the number of l1 messages is unlimited.
+	L1MessageNum         uint64 `json:"l1_message_num,omitempty"`
+	L1CommitGas          uint64 `json:"l1_commit_gas"`
+	L1CommitCalldataSize uint64 `json:"l1_commit_calldata_size"`
+	RowConsumption       uint64 `json:"row_consumption"`
+}
+
+// ChunkPreview is the status of the chunk the proposer is currently filling.
+type ChunkPreview struct {
+	// UnchunkedBlockHeight is the first block of the open chunk.
+	UnchunkedBlockHeight uint64           `json:"unchunked_block_height"`
+	Open                 *ChunkEstimation `json:"open"`
+	Remaining            *ChunkBudget     `json:"remaining"`
+	// TimeoutAt is the unix time at which the open chunk is proposed regardless of its size, 0 if it is empty.
+	// It is pushed back to the min number of blocks timeout while the open chunk is below the min number of blocks.
+	TimeoutAt uint64 `json:"timeout_at"`
+}
+
+// Preview returns the blocks the next chunk would include if it were proposed now
+// and the remaining budget of each chunk limit.
+func (p *ChunkProposer) Preview(ctx context.Context) (*ChunkPreview, error) {
+	unchunkedBlockHeight, err := p.chunkOrm.GetUnchunkedBlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := p.l2BlockOrm.GetL2WrappedBlocksGEHeight(ctx, unchunkedBlockHeight, int(p.maxBlockNumPerChunk))
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := p.EstimateChunk(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		estimation, err := p.EstimateChunk(blocks[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		if len(estimation.ExceededLimits) > 0 {
+			break
+		}
+		open = estimation
+	}
+
+	limits := p.chunkLimitsAt(unchunkedBlockHeight)
+	preview := &ChunkPreview{
+		UnchunkedBlockHeight: unchunkedBlockHeight,
+		Open:                 open,
+		Remaining: &ChunkBudget{
+			NumBlocks:            remainingBudget(p.maxBlockNumPerChunk, open.NumBlocks),
+			TxNum:                remainingBudget(limits.maxTxNum, open.TotalTxNum),
+			L1CommitGas:          remainingBudget(p.maxL1CommitGasPerChunk, open.TotalL1CommitGas),
+			L1CommitCalldataSize: remainingBudget(p.maxL1CommitCalldataSizePerChunk, open.TotalL1CommitCalldataSize),
+			RowConsumption:       remainingBudget(p.maxRowConsumptionPerChunk, open.MaxRowConsumption),
+		},
+	}
+	if limits.maxL1MessageNum != 0 {
+		preview.Remaining.L1MessageNum = remainingBudget(limits.maxL1MessageNum, open.TotalL1MessageNum)
+	}
+	if open.NumBlocks > 0 {
+		preview.TimeoutAt = blocks[0].Header.Time + p.chunkTimeoutSec
+		if minimum := p.MinBlockNum(); open.NumBlocks < minimum.Num {
+			preview.TimeoutAt = blocks[0].Header.Time + minimum.TimeoutSec
+		}
+	}
+	return preview, nil
+}
+
+func remainingBudget(limit, used uint64) uint64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
@@ -2,8 +2,12 @@ package watcher
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 
 	"scroll-tech/common/database"
@@ -21,7 +25,9 @@ func testChunkProposerLimits(t *testing.T) {
 		maxL1CommitGas             uint64
 		maxL1CommitCalldataSize    uint64
 		maxRowConsumption          uint64
+		maxCompressedSize          uint64
 		chunkTimeoutSec            uint64
+		forks                      []*config.ChunkForkConfig
 		expectedChunksLen          int
 		expectedBlocksInFirstChunk int // only be checked when expectedChunksLen > 0
 	}{
@@ -141,6 +147,41 @@ func testChunkProposerLimits(t *testing.T) {
 			expectedChunksLen:          1,
 			expectedBlocksInFirstChunk: 1,
 		},
+		{
+			name:                       "MaxCompressedSizePerChunkIsFirstBlock",
+			maxBlockNum:                10,
+			maxTxNum:                   10000,
+			maxL1CommitGas:             50000000000,
+			maxL1CommitCalldataSize:    1000000,
+			maxRowConsumption:          1000000,
+			maxCompressedSize:          223,
+			chunkTimeoutSec:            1000000000000,
+			expectedChunksLen:          1,
+			expectedBlocksInFirstChunk: 1,
+		},
+		{
+			name:                       "ForkBoundaryReached",
+			maxBlockNum:                10,
+			maxTxNum:                   10000,
+			maxL1CommitGas:             50000000000,
+			maxL1CommitCalldataSize:    1000000,
+			maxRowConsumption:          1000000,
+			chunkTimeoutSec:            1000000000000,
+			forks:                      []*config.ChunkForkConfig{{Name: "next", Block: 3, MaxTxNumPerChunk: 10000}},
+			expectedChunksLen:          1,
+			expectedBlocksInFirstChunk: 1,
+		},
+		{
+			name:                    "ForkMaxTxNumPerChunkIs0",
+			maxBlockNum:             10,
+			maxTxNum:                10000,
+			maxL1CommitGas:          50000000000,
+			maxL1CommitCalldataSize: 1000000,
+			maxRowConsumption:       1000000,
+			chunkTimeoutSec:         1000000000000,
+			forks:                   []*config.ChunkForkConfig{{Name: "next", Block: 0, MaxTxNumPerChunk: 0}},
+			expectedChunksLen:       0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -158,10 +199,12 @@ func testChunkProposerLimits(t *testing.T) {
 				MaxL1CommitGasPerChunk:          tt.maxL1CommitGas,
 				MaxL1CommitCalldataSizePerChunk: tt.maxL1CommitCalldataSize,
 				MaxRowConsumptionPerChunk:       tt.maxRowConsumption,
+				MaxCompressedSizePerChunk:       tt.maxCompressedSize,
 				ChunkTimeoutSec:                 tt.chunkTimeoutSec,
 				GasCostIncreaseMultiplier:       1.2,
-			}, db, nil)
-			cp.TryProposeChunk()
+				Forks:                           tt.forks,
+			}, nil, db, nil)
+			cp.TryProposeChunk(context.Background())
 
 			chunkOrm := orm.NewChunk(db)
 			chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
@@ -180,3 +223,568 @@ func testChunkProposerLimits(t *testing.T) {
 		})
 	}
 }
+
+func testChunkProposerPreview(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+
+	preview, err := cp.Preview(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), preview.Open.NumBlocks)
+	assert.Equal(t, uint64(8), preview.Remaining.NumBlocks)
+	assert.Equal(t, 10000-preview.Open.TotalTxNum, preview.Remaining.TxNum)
+	assert.Equal(t, wrappedBlock1.Header.Time+300, preview.TimeoutAt)
+
+	// the open chunk is empty once all blocks are chunked.
+	cp.TryProposeChunk(context.Background())
+	preview, err = cp.Preview(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), preview.Open.NumBlocks)
+	assert.Equal(t, uint64(10), preview.Remaining.NumBlocks)
+	assert.Equal(t, uint64(0), preview.TimeoutAt)
+}
+
+func testChunkProposerWhatIf(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             1,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background()) // chunk1 contains block2
+
+	number := wrappedBlock2.Header.Number.Uint64()
+	current, proposed, err := cp.WhatIf(context.Background(), number, number, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), current.TotalTxNum)
+	expected, err := cp.EstimateChunk([]*types.WrappedBlock{wrappedBlock2})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, proposed)
+
+	// the chunked blocks can't be estimated again
+	_, _, err = cp.WhatIf(context.Background(), number-1, number-1, nil)
+	assert.Error(t, err)
+
+	// the block range is capped by the max number of blocks per chunk, before any block is loaded
+	_, _, err = cp.WhatIf(context.Background(), number, number+1000000, nil)
+	assert.ErrorIs(t, err, ErrChunkWhatIfTooManyBlocks)
+	_, _, err = cp.WhatIf(context.Background(), number+1, number+1, nil)
+	assert.ErrorIs(t, err, ErrChunkWhatIfTooManyBlocks)
+	_, _, err = cp.WhatIf(context.Background(), number, number, []*types.WrappedBlock{wrappedBlock2, wrappedBlock2})
+	assert.ErrorIs(t, err, ErrChunkWhatIfTooManyBlocks)
+}
+
+func testChunkProposerMinBlockNum(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 0,
+		GasCostIncreaseMultiplier:       1.2,
+		MinBlockNumPerChunk:             3,
+		MinBlockNumTimeoutSec:           1 << 40,
+	}, nil, db, nil)
+
+	// the timed out chunk waits for a third block
+	cp.TryProposeChunk(context.Background())
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	preview, err := cp.Preview(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, wrappedBlock1.Header.Time+1<<40, preview.TimeoutAt)
+
+	// invalid minimums are rejected
+	assert.Error(t, cp.SetMinBlockNum(ProposalMinimum{Num: 11}))
+
+	// the chunk below the minimum is proposed once the min block number timeout is reached
+	assert.NoError(t, cp.SetMinBlockNum(ProposalMinimum{Num: 3}))
+	assert.Equal(t, ProposalMinimum{Num: 3}, cp.MinBlockNum())
+	cp.TryProposeChunk(context.Background())
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, uint64(2), chunks[0].EndBlockNumber-chunks[0].StartBlockNumber+1)
+}
+
+func testChunkProposerBaseFeeFork(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	// the base fee of the second block is encoded from the fork on
+	forkBlock := wrappedBlock2.Header.Number.Uint64()
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             2,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}, &forkBlock, db, nil)
+	cp.TryProposeChunk(context.Background())
+
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+
+	blocks := []*types.WrappedBlock{wrappedBlock1, wrappedBlock2}
+	forkHash, err := (&types.Chunk{Blocks: blocks, BaseFeeForkBlock: &forkBlock}).Hash(0)
+	assert.NoError(t, err)
+	assert.Equal(t, forkHash.Hex(), chunks[0].Hash)
+	noForkHash, err := (&types.Chunk{Blocks: blocks}).Hash(0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, noForkHash, forkHash)
+}
+
+func testChunkProposerEmptyBlocks(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	var emptyBlocks []*types.WrappedBlock
+	for _, block := range []*types.WrappedBlock{wrappedBlock1, wrappedBlock2} {
+		emptyBlocks = append(emptyBlocks, &types.WrappedBlock{
+			Header:         block.Header,
+			WithdrawRoot:   block.WithdrawRoot,
+			RowConsumption: &gethTypes.RowConsumption{},
+		})
+	}
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), emptyBlocks)
+	assert.NoError(t, err)
+
+	// the empty blocks count for their block contexts only
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             2,
+		MaxTxNumPerChunk:                1,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 2 * 60,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 1000000000000,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background())
+
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, emptyBlocks[0].Header.Number.Uint64(), chunks[0].StartBlockNumber)
+	assert.Equal(t, emptyBlocks[1].Header.Number.Uint64(), chunks[0].EndBlockNumber)
+	assert.Equal(t, uint32(0), chunks[0].TotalL2TxNum)
+	assert.Equal(t, uint32(0), chunks[0].TotalL1MessagesPoppedInChunk)
+	assert.Equal(t, uint32(2*60), chunks[0].TotalL1CommitCalldataSize)
+
+	chunk := &types.Chunk{Blocks: emptyBlocks}
+	l1CommitGas, err := chunk.EstimateL1CommitGas()
+	assert.NoError(t, err)
+	assert.Equal(t, l1CommitGas, chunks[0].TotalL1CommitGas)
+}
+
+func testChunkProposerPackingConstraint(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 1000000000000,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+
+	// at most one block with more than one transaction per chunk
+	cp.AddPackingConstraint("max_one_busy_block", PackingConstraintFunc(func(chunkSoFar *types.Chunk, candidate *types.WrappedBlock) error {
+		if len(candidate.Transactions) <= 1 {
+			return nil
+		}
+		for _, block := range chunkSoFar.Blocks {
+			if len(block.Transactions) > 1 {
+				return errors.New("the chunk has a busy block")
+			}
+		}
+		return nil
+	}))
+	// block 3 doesn't follow another block
+	cp.AddPackingConstraint("block_3_first", PackingConstraintFunc(func(chunkSoFar *types.Chunk, candidate *types.WrappedBlock) error {
+		if candidate.Header.Number.Uint64() == 3 && len(chunkSoFar.Blocks) > 0 {
+			return errors.New("block 3 starts a chunk")
+		}
+		return nil
+	}))
+
+	estimation, err := cp.EstimateChunk([]*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"block_3_first"}, estimation.ExceededLimits)
+
+	cp.TryProposeChunk(context.Background())
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, uint64(2), chunks[0].StartBlockNumber)
+	assert.Equal(t, uint64(2), chunks[0].EndBlockNumber)
+}
+
+func testChunkProposerMaxL2Gas(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 1000000000000,
+		GasCostIncreaseMultiplier:       1.2,
+		MaxL2GasPerChunk:                wrappedBlock1.Header.GasUsed + wrappedBlock2.Header.GasUsed - 1,
+	}, nil, db, nil)
+
+	estimation, err := cp.EstimateChunk([]*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"max_l2_gas"}, estimation.ExceededLimits)
+
+	cp.TryProposeChunk(context.Background())
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, uint64(2), chunks[0].StartBlockNumber)
+	assert.Equal(t, uint64(2), chunks[0].EndBlockNumber)
+}
+
+func testChunkProposerReproposeChunks(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cfg := &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 0,
+		GasCostIncreaseMultiplier:       1.2,
+	}
+	cp := NewChunkProposer(context.Background(), cfg, nil, db, nil)
+	cp.TryProposeChunk(context.Background())
+
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, uint64(2), chunks[0].StartBlockNumber)
+	assert.Equal(t, uint64(3), chunks[0].EndBlockNumber)
+
+	// the chunk within the limit is kept
+	assert.NoError(t, cp.ReproposeChunksExceedingRowConsumption())
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+
+	// the circuit capacity decreases below the rows of the chunk
+	estimation, err := cp.EstimateChunk([]*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+	cfg.MaxRowConsumptionPerChunk = estimation.MaxRowConsumption - 1
+	cp = NewChunkProposer(context.Background(), cfg, nil, db, nil)
+
+	// the chunk assigned to provers is not touched
+	assert.NoError(t, chunkOrm.UpdateProvingStatus(context.Background(), chunks[0].Hash, types.ProvingTaskAssigned))
+	assert.ErrorContains(t, cp.ReproposeChunksExceedingRowConsumption(), "already assigned to provers or proven")
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	blocks, err := l2BlockOrm.GetL2Blocks(context.Background(), map[string]interface{}{}, nil, 0)
+	assert.NoError(t, err)
+	for _, block := range blocks {
+		assert.Equal(t, chunks[0].Hash, block.ChunkHash)
+	}
+
+	// the chunk failed to be proven is proposed again
+	assert.NoError(t, chunkOrm.UpdateProvingStatus(context.Background(), chunks[0].Hash, types.ProvingTaskFailed))
+	assert.NoError(t, cp.ReproposeChunksExceedingRowConsumption())
+
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 0)
+	blocks, err = l2BlockOrm.GetL2Blocks(context.Background(), map[string]interface{}{}, nil, 0)
+	assert.NoError(t, err)
+	for _, block := range blocks {
+		assert.Empty(t, block.ChunkHash)
+	}
+
+	// the blocks are proposed again under the new limit
+	cp.TryProposeChunk(context.Background())
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, uint64(0), chunks[0].Index)
+	assert.Equal(t, uint64(2), chunks[0].StartBlockNumber)
+	assert.Equal(t, uint64(2), chunks[0].EndBlockNumber)
+}
+
+func testChunkProposerRowConsumptionMissing(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	var blocks []*types.WrappedBlock
+	for _, block := range []*types.WrappedBlock{wrappedBlock1, wrappedBlock2} {
+		blocks = append(blocks, &types.WrappedBlock{
+			Header:       block.Header,
+			Transactions: block.Transactions,
+			WithdrawRoot: block.WithdrawRoot,
+		})
+	}
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), blocks)
+	assert.NoError(t, err)
+
+	cfg := &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 0,
+		GasCostIncreaseMultiplier:       1.2,
+		RowConsumptionRequiredFromBlock: blocks[1].Header.Number.Uint64(),
+	}
+
+	// the proposal halts on the block requiring its row consumption
+	cp := NewChunkProposer(context.Background(), cfg, nil, db, nil)
+	chunk, err := cp.proposeChunk(context.Background())
+	assert.ErrorIs(t, err, types.ErrRowConsumptionMissing)
+	assert.Nil(t, chunk)
+	cp.TryProposeChunk(context.Background())
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	// the blocks before the required block are chunked without their row consumption
+	cfg.RowConsumptionRequiredFromBlock = blocks[1].Header.Number.Uint64() + 1
+	cp = NewChunkProposer(context.Background(), cfg, nil, db, nil)
+	cp.TryProposeChunk(context.Background())
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, blocks[0].Header.Number.Uint64(), chunks[0].StartBlockNumber)
+	assert.Equal(t, blocks[1].Header.Number.Uint64(), chunks[0].EndBlockNumber)
+}
+
+func testChunkProposerBlockFailure(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	// every block exceeds the l2 tx number limit on its own
+	newChunkProposer := func() *ChunkProposer {
+		return NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+			MaxBlockNumPerChunk:             10,
+			MaxTxNumPerChunk:                0,
+			MaxL1CommitGasPerChunk:          50000000000,
+			MaxL1CommitCalldataSizePerChunk: 1000000,
+			MaxRowConsumptionPerChunk:       1000000,
+			ChunkTimeoutSec:                 0,
+			GasCostIncreaseMultiplier:       1.2,
+			QuarantineBlockAfterFailures:    3,
+		}, nil, db, nil)
+	}
+
+	// breaking the same limit in a row counts every failure, also across proposer instances
+	for i := 1; i < 3; i++ {
+		chunk, err := newChunkProposer().proposeChunk(context.Background())
+		assert.ErrorContains(t, err, fmt.Sprintf("failures: %v, quarantine threshold: 3", i))
+		assert.Nil(t, chunk)
+	}
+	blocks, err := l2BlockOrm.GetL2Blocks(context.Background(), map[string]interface{}{"number": 2}, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), blocks[0].ChunkProposalFailures)
+	assert.Equal(t, "max_tx_num_per_chunk", blocks[0].ChunkProposalFailureReasons)
+	assert.Equal(t, int16(types.ChunkProposalStatusUndefined), blocks[0].ChunkProposalStatus)
+
+	// the third failure quarantines the block into a single-block chunk
+	cp := newChunkProposer()
+	cp.TryProposeChunk(context.Background())
+	assert.Equal(t, float64(1), testutil.ToFloat64(cp.chunkBlockQuarantinedTotal))
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, uint64(2), chunks[0].StartBlockNumber)
+	assert.Equal(t, uint64(2), chunks[0].EndBlockNumber)
+	blocks, err = l2BlockOrm.GetL2Blocks(context.Background(), map[string]interface{}{"number": 2}, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), blocks[0].ChunkProposalFailures)
+	assert.Equal(t, int16(types.ChunkProposalStatusQuarantined), blocks[0].ChunkProposalStatus)
+
+	// the proposal moves on to the next block
+	chunk, err := cp.proposeChunk(context.Background())
+	assert.ErrorContains(t, err, "failures: 1, quarantine threshold: 3")
+	assert.Nil(t, chunk)
+}
+
+func testChunkProposerProverFailureQuarantine(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 0,
+		GasCostIncreaseMultiplier:       1.2,
+		QuarantineBlockAfterFailures:    2,
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background())
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.NoError(t, chunkOrm.UpdateProvingStatus(context.Background(), chunks[0].Hash, types.ProvingTaskFailed))
+
+	// the coordinator counts the failed proofs of the chunk as failures of its blocks
+	recordProverFailure := func() {
+		for _, number := range []uint64{2, 3} {
+			_, err := l2BlockOrm.RecordChunkProposalFailure(context.Background(), number, "prover_failure")
+			assert.NoError(t, err)
+		}
+	}
+
+	// the failed chunk is kept below the quarantine threshold
+	recordProverFailure()
+	assert.NoError(t, cp.ReproposeChunksExceedingRowConsumption())
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+
+	// the failed chunk is proposed again once its blocks reach the threshold
+	recordProverFailure()
+	assert.NoError(t, cp.ReproposeChunksExceedingRowConsumption())
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	// each block is quarantined into its own chunk
+	cp.TryProposeChunk(context.Background())
+	cp.TryProposeChunk(context.Background())
+	assert.Equal(t, float64(2), testutil.ToFloat64(cp.chunkBlockQuarantinedTotal))
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	for i, chunk := range chunks {
+		assert.Equal(t, uint64(2+i), chunk.StartBlockNumber)
+		assert.Equal(t, uint64(2+i), chunk.EndBlockNumber)
+	}
+}
+
+func testChunkProposerCompressedSizeCache(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             10,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxCompressedSizePerChunk:       1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 1000000000000,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+
+	expectedSize, err := wrappedBlock1.EstimateCompressedSize()
+	assert.NoError(t, err)
+	size, err := cp.blockCompressedSize(wrappedBlock1)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSize, size)
+
+	// the cached size is returned without compressing the block again
+	emptied := &types.WrappedBlock{Header: wrappedBlock1.Header, WithdrawRoot: wrappedBlock1.WithdrawRoot}
+	size, err = cp.blockCompressedSize(emptied)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSize, size)
+
+	// the unchunked blocks are cached across the ticks
+	cp.TryProposeChunk(context.Background())
+	chunks, err := orm.NewChunk(db).GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 0)
+	assert.Len(t, cp.compressedSizes, 2)
+
+	// the chunked blocks are dropped from the cache
+	cp.pruneCompressedSizes(wrappedBlock2.Header.Number.Uint64())
+	assert.Len(t, cp.compressedSizes, 1)
+	_, exists := cp.compressedSizes[wrappedBlock2.Header.Hash()]
+	assert.True(t, exists)
+	cp.pruneCompressedSizes(wrappedBlock2.Header.Number.Uint64() + 1)
+	assert.Len(t, cp.compressedSizes, 0)
+}
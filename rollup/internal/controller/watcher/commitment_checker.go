@@ -0,0 +1,206 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/accounts/abi"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// uncheckedRollupStatuses are the statuses of the batches committed and not yet finalized on layer 1.
+var uncheckedRollupStatuses = []types.RollupStatus{types.RollupCommitted, types.RollupFinalizing, types.RollupFinalizeFailed}
+
+// resubmittableRollupStatuses are the statuses of the batches committed again when their commitment is missing,
+// a finalizing batch has its finalize transaction in flight and is never marked as commit failed.
+var resubmittableRollupStatuses = []types.RollupStatus{types.RollupCommitted, types.RollupFinalizeFailed}
+
+// defaultCommitmentMissingRounds is the default number of consecutive rounds a commitment must be missing
+// before the batch is committed again.
+const defaultCommitmentMissingRounds = 3
+
+// CommitmentChecker compares the batches committed locally with the commitments stored by the rollup
+// contract, a batch whose commit transaction was dropped by a layer 1 reorg or replaced is committed
+// locally while its commitment is missing on layer 1.
+type CommitmentChecker struct {
+	l1Caller ethereum.ContractCaller
+	batchOrm orm.BatchRepo
+
+	rollupContractAddress common.Address
+	rollupABI             *abi.ABI
+	numBatches            uint64
+	resubmit              bool
+	missingRounds         uint64
+
+	// missing counts the consecutive rounds the commitment of a batch is missing, by batch hash.
+	missing map[string]uint64
+
+	commitmentCheckerCircleTotal      prometheus.Counter
+	commitmentCheckerFailureTotal     prometheus.Counter
+	commitmentCheckerMissingTotal     prometheus.Counter
+	commitmentCheckerMismatchTotal    prometheus.Counter
+	commitmentCheckerResubmittedTotal prometheus.Counter
+}
+
+// NewCommitmentChecker creates a new CommitmentChecker instance.
+func NewCommitmentChecker(cfg *config.CommitmentCheckConfig, rollupContractAddress common.Address, l1Caller ethereum.ContractCaller, db *gorm.DB, reg prometheus.Registerer) *CommitmentChecker {
+	log.Debug("new commitment checker",
+		"checkIntervalSec", cfg.CheckIntervalSec,
+		"numBatches", cfg.NumBatches,
+		"resubmit", cfg.Resubmit,
+		"missingRounds", cfg.MissingRounds)
+
+	missingRounds := cfg.MissingRounds
+	if missingRounds == 0 {
+		missingRounds = defaultCommitmentMissingRounds
+	}
+
+	return &CommitmentChecker{
+		l1Caller:              l1Caller,
+		batchOrm:              orm.NewBatch(db),
+		rollupContractAddress: rollupContractAddress,
+		rollupABI:             bridgeAbi.ScrollChainABI,
+		numBatches:            cfg.NumBatches,
+		resubmit:              cfg.Resubmit,
+		missingRounds:         missingRounds,
+		missing:               make(map[string]uint64),
+
+		commitmentCheckerCircleTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_commitment_checker_circle_total",
+			Help: "Total number of commitment checker rounds.",
+		}),
+		commitmentCheckerFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_commitment_checker_failure_total",
+			Help: "Total number of commitment checker rounds failed to complete.",
+		}),
+		commitmentCheckerMissingTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_commitment_checker_missing_total",
+			Help: "Total number of batches committed locally whose commitment is missing on layer 1.",
+		}),
+		commitmentCheckerMismatchTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_commitment_checker_mismatch_total",
+			Help: "Total number of batches committed locally while another batch is committed at their index on layer 1.",
+		}),
+		commitmentCheckerResubmittedTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_commitment_checker_resubmitted_total",
+			Help: "Total number of batches marked as commit failed to be committed again.",
+		}),
+	}
+}
+
+// TryCheckCommitments checks the commitments of the latest committed and not yet finalized batches.
+func (c *CommitmentChecker) TryCheckCommitments(ctx context.Context) {
+	c.commitmentCheckerCircleTotal.Inc()
+
+	batches, err := c.uncheckedBatches(ctx)
+	if err != nil {
+		c.commitmentCheckerFailureTotal.Inc()
+		log.Error("commitment checker failed to get the committed batches", "err", err)
+		return
+	}
+
+	checked := make(map[string]bool, len(batches))
+	for _, batch := range batches {
+		checked[batch.Hash] = true
+	}
+	// the batches finalized or committed again since are no longer missing
+	for hash := range c.missing {
+		if !checked[hash] {
+			delete(c.missing, hash)
+		}
+	}
+
+	for _, batch := range batches {
+		commitment, err := c.committedBatchHash(ctx, batch.Index)
+		if err != nil {
+			c.commitmentCheckerFailureTotal.Inc()
+			log.Error("commitment checker failed to get the commitment on layer 1", "index", batch.Index, "err", err)
+			return
+		}
+
+		switch {
+		case commitment == common.HexToHash(batch.Hash):
+			delete(c.missing, batch.Hash)
+			continue
+		case commitment != (common.Hash{}):
+			// the rollup contract holds another batch, committing again would revert, manual fix is needed
+			delete(c.missing, batch.Hash)
+			c.commitmentCheckerMismatchTotal.Inc()
+			log.Error("commitment checker found another batch committed on layer 1",
+				"index", batch.Index, "hash", batch.Hash, "commitment", commitment.Hex(), "commit tx hash", batch.CommitTxHash)
+			continue
+		}
+
+		c.missing[batch.Hash]++
+		c.commitmentCheckerMissingTotal.Inc()
+		log.Error("commitment checker found the commitment of a committed batch missing on layer 1",
+			"index", batch.Index, "hash", batch.Hash, "commit tx hash", batch.CommitTxHash, "rollup status", types.RollupStatus(batch.RollupStatus),
+			"missing rounds", c.missing[batch.Hash], "resubmit", c.resubmit)
+		if !c.resubmit || c.missing[batch.Hash] < c.missingRounds {
+			continue
+		}
+		if types.RollupStatus(batch.RollupStatus) == types.RollupFinalizing {
+			log.Error("commitment checker does not commit a finalizing batch again, manual fix is needed", "index", batch.Index, "hash", batch.Hash)
+			continue
+		}
+		// the relayer may have moved the batch on since it was read, it is only marked from the resubmittable statuses
+		updated, err := c.batchOrm.UpdateRollupStatusFrom(ctx, batch.Hash, resubmittableRollupStatuses, types.RollupCommitFailed)
+		if err != nil {
+			c.commitmentCheckerFailureTotal.Inc()
+			log.Error("commitment checker failed to mark the batch as commit failed", "index", batch.Index, "hash", batch.Hash, "err", err)
+			return
+		}
+		delete(c.missing, batch.Hash)
+		if !updated {
+			log.Warn("commitment checker skipped the batch whose rollup status changed meanwhile", "index", batch.Index, "hash", batch.Hash)
+			continue
+		}
+		c.commitmentCheckerResubmittedTotal.Inc()
+		log.Warn("commitment checker marked the batch as commit failed to commit it again", "index", batch.Index, "hash", batch.Hash)
+	}
+}
+
+// uncheckedBatches returns the latest committed and not yet finalized batches in ascending index order.
+func (c *CommitmentChecker) uncheckedBatches(ctx context.Context) ([]*orm.Batch, error) {
+	var batches []*orm.Batch
+	for _, status := range uncheckedRollupStatuses {
+		fields := map[string]interface{}{"rollup_status = ?": status}
+		statusBatches, err := c.batchOrm.GetBatches(ctx, fields, []string{"index DESC"}, int(c.numBatches))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest batches of rollup status %v: %w", status, err)
+		}
+		batches = append(batches, statusBatches...)
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].Index > batches[j].Index })
+	if uint64(len(batches)) > c.numBatches {
+		batches = batches[:c.numBatches]
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].Index < batches[j].Index })
+	return batches, nil
+}
+
+// committedBatchHash returns the batch hash committed at the index on layer 1, zero if there is none.
+func (c *CommitmentChecker) committedBatchHash(ctx context.Context, index uint64) (common.Hash, error) {
+	calldata, err := c.rollupABI.Pack("committedBatches", new(big.Int).SetUint64(index))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	output, err := c.l1Caller.CallContract(ctx, ethereum.CallMsg{To: &c.rollupContractAddress, Data: calldata}, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(output), nil
+}
@@ -0,0 +1,95 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/types"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/orm/fake"
+)
+
+// mockRollupContract serves the batch hashes committed on layer 1 by index.
+type mockRollupContract struct {
+	committed map[uint64]common.Hash
+	fail      bool
+}
+
+func (m *mockRollupContract) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if m.fail {
+		return nil, errors.New("connection refused")
+	}
+	args, err := bridgeAbi.ScrollChainABI.Methods["committedBatches"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	hash := m.committed[args[0].(*big.Int).Uint64()]
+	return hash.Bytes(), nil
+}
+
+func testCommitmentChecker(t *testing.T) {
+	batchRepo := fake.NewBatchRepo()
+	batchRepo.AddBatches(
+		&orm.Batch{Index: 1, Hash: common.BigToHash(big.NewInt(1)).Hex(), RollupStatus: int16(types.RollupFinalized)},
+		&orm.Batch{Index: 2, Hash: common.BigToHash(big.NewInt(2)).Hex(), RollupStatus: int16(types.RollupFinalizing)},
+		&orm.Batch{Index: 3, Hash: common.BigToHash(big.NewInt(3)).Hex(), RollupStatus: int16(types.RollupCommitted)},
+		&orm.Batch{Index: 4, Hash: common.BigToHash(big.NewInt(4)).Hex(), RollupStatus: int16(types.RollupCommitted)},
+		&orm.Batch{Index: 5, Hash: common.BigToHash(big.NewInt(5)).Hex(), RollupStatus: int16(types.RollupFinalizing)},
+	)
+	contract := &mockRollupContract{committed: map[uint64]common.Hash{
+		1: common.BigToHash(big.NewInt(1)),
+		2: common.BigToHash(big.NewInt(2)),
+		3: common.BigToHash(big.NewInt(33)),
+	}}
+	hashes := []string{common.BigToHash(big.NewInt(3)).Hex(), common.BigToHash(big.NewInt(4)).Hex(), common.BigToHash(big.NewInt(5)).Hex()}
+
+	checker := NewCommitmentChecker(&config.CommitmentCheckConfig{CheckIntervalSec: 1, NumBatches: 10}, common.Address{}, contract, nil, nil)
+	checker.batchOrm = batchRepo
+	assert.Equal(t, uint64(defaultCommitmentMissingRounds), checker.missingRounds)
+	checker.missingRounds = 2
+
+	// the missing and mismatching commitments are only reported
+	checker.TryCheckCommitments(context.Background())
+	statuses, err := batchRepo.GetRollupStatusByHashList(context.Background(), hashes)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitted, types.RollupCommitted, types.RollupFinalizing}, statuses)
+
+	// a commitment found again resets the missing rounds of the batch
+	checker.resubmit = true
+	contract.committed[4] = common.BigToHash(big.NewInt(4))
+	checker.TryCheckCommitments(context.Background())
+	assert.NotContains(t, checker.missing, common.BigToHash(big.NewInt(4)).Hex())
+	delete(contract.committed, 4)
+
+	// the batch is not committed again before its commitment is missing for enough consecutive rounds
+	checker.TryCheckCommitments(context.Background())
+	statuses, err = batchRepo.GetRollupStatusByHashList(context.Background(), hashes)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitted, types.RollupCommitted, types.RollupFinalizing}, statuses)
+
+	// the committed batch missing its commitment is committed again, the mismatching one needs a manual fix
+	// and the finalizing one is never marked as commit failed
+	checker.TryCheckCommitments(context.Background())
+	statuses, err = batchRepo.GetRollupStatusByHashList(context.Background(), hashes)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitted, types.RollupCommitFailed, types.RollupFinalizing}, statuses)
+	assert.NotContains(t, checker.missing, common.BigToHash(big.NewInt(4)).Hex())
+
+	// a failed call stops the round
+	contract.fail = true
+	assert.NoError(t, batchRepo.UpdateRollupStatus(context.Background(), common.BigToHash(big.NewInt(4)).Hex(), types.RollupCommitted))
+	checker.TryCheckCommitments(context.Background())
+	checker.TryCheckCommitments(context.Background())
+	statuses, err = batchRepo.GetRollupStatusByHashList(context.Background(), []string{common.BigToHash(big.NewInt(4)).Hex()})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitted}, statuses)
+}
@@ -1,3 +1,20 @@
 package watcher
 
 const contractEventsBlocksFetchLimit = int64(10)
+
+// commitBatchCalldataSize returns the size of the abi encoded commitBatch(uint8,bytes,bytes[],bytes) calldata,
+// given the sizes of the parent batch header, of each chunk encoding and of the skipped l1 message bitmap.
+func commitBatchCalldataSize(parentBatchHeaderSize uint64, chunkSizes []uint64, skippedL1MessageBitmapSize uint64) uint64 {
+	size := uint64(4) + 4*32 // function selector and the head of the 4 arguments
+	size += 32 + paddedSize(parentBatchHeaderSize)
+	size += 32 + 32*uint64(len(chunkSizes)) // chunks array length and offsets
+	for _, chunkSize := range chunkSizes {
+		size += 32 + paddedSize(chunkSize)
+	}
+	size += 32 + paddedSize(skippedL1MessageBitmapSize)
+	return size
+}
+
+func paddedSize(size uint64) uint64 {
+	return (size + 31) / 32 * 32
+}
@@ -0,0 +1,157 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// L1MessageQueueMonitor tracks the gap between the l1 message queue tail on layer 1 and the
+// highest queue index included on layer 2, which is the primary censorship and liveness indicator.
+type L1MessageQueueMonitor struct {
+	l1MessageOrm orm.L1MessageRepo
+	chunkOrm     orm.ChunkRepo
+	l2BlockOrm   *orm.L2Block
+
+	maxQueueLag   uint64
+	maxStalledSec uint64
+
+	// lastNextQueueIndex and lastProgressTime track when layer 2 last included a new l1 message
+	lastNextQueueIndex uint64
+	lastProgressTime   time.Time
+
+	l1MessageQueueCheckTotal         prometheus.Counter
+	l1MessageQueueCheckFailureTotal  prometheus.Counter
+	l1MessageQueueLength             prometheus.Gauge
+	l1MessageQueueL2NextIndex        prometheus.Gauge
+	l1MessageQueueLag                prometheus.Gauge
+	l1MessageQueueStalledSeconds     prometheus.Gauge
+	l1MessageQueueLagThresholdExceed prometheus.Gauge
+}
+
+// NewL1MessageQueueMonitor creates a new L1MessageQueueMonitor instance.
+func NewL1MessageQueueMonitor(cfg *config.L1MessageQueueMonitorConfig, db *gorm.DB, reg prometheus.Registerer) *L1MessageQueueMonitor {
+	log.Debug("new l1 message queue monitor",
+		"checkIntervalSec", cfg.CheckIntervalSec,
+		"maxQueueLag", cfg.MaxQueueLag,
+		"maxStalledSec", cfg.MaxStalledSec)
+
+	return &L1MessageQueueMonitor{
+		l1MessageOrm:     orm.NewL1Message(db),
+		chunkOrm:         orm.NewChunk(db),
+		l2BlockOrm:       orm.NewL2Block(db),
+		maxQueueLag:      cfg.MaxQueueLag,
+		maxStalledSec:    cfg.MaxStalledSec,
+		lastProgressTime: time.Now(),
+
+		l1MessageQueueCheckTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_l1_message_queue_check_total",
+			Help: "Total number of l1 message queue lag checks.",
+		}),
+		l1MessageQueueCheckFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_l1_message_queue_check_failure_total",
+			Help: "Total number of failed l1 message queue lag checks.",
+		}),
+		l1MessageQueueLength: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_l1_message_queue_length",
+			Help: "The length of the l1 message queue observed on layer 1.",
+		}),
+		l1MessageQueueL2NextIndex: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_l1_message_queue_l2_next_index",
+			Help: "The next l1 message queue index to be included on layer 2.",
+		}),
+		l1MessageQueueLag: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_l1_message_queue_lag",
+			Help: "The number of l1 messages queued on layer 1 but not yet included on layer 2.",
+		}),
+		l1MessageQueueStalledSeconds: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_l1_message_queue_stalled_seconds",
+			Help: "The duration in which pending l1 messages are not included on layer 2.",
+		}),
+		l1MessageQueueLagThresholdExceed: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "rollup_l1_message_queue_lag_threshold_exceeded",
+			Help: "Whether the l1 message queue lag exceeds the alerting thresholds, 1 means exceeded.",
+		}),
+	}
+}
+
+// TryCheckQueueLag computes the l1 message queue lag and updates the metrics.
+func (m *L1MessageQueueMonitor) TryCheckQueueLag(ctx context.Context) {
+	m.l1MessageQueueCheckTotal.Inc()
+
+	queueLength, err := m.l1MessageOrm.GetL1MessageQueueLength(ctx)
+	if err != nil {
+		m.l1MessageQueueCheckFailureTotal.Inc()
+		log.Error("failed to get l1 message queue length", "err", err)
+		return
+	}
+
+	nextQueueIndex, err := m.l2NextQueueIndex(ctx)
+	if err != nil {
+		m.l1MessageQueueCheckFailureTotal.Inc()
+		log.Error("failed to get the next l1 message queue index on l2", "err", err)
+		return
+	}
+
+	var lag uint64
+	if queueLength > nextQueueIndex {
+		lag = queueLength - nextQueueIndex
+	}
+
+	now := time.Now()
+	if nextQueueIndex > m.lastNextQueueIndex || lag == 0 {
+		m.lastNextQueueIndex = nextQueueIndex
+		m.lastProgressTime = now
+	}
+	stalled := now.Sub(m.lastProgressTime)
+
+	m.l1MessageQueueLength.Set(float64(queueLength))
+	m.l1MessageQueueL2NextIndex.Set(float64(nextQueueIndex))
+	m.l1MessageQueueLag.Set(float64(lag))
+	m.l1MessageQueueStalledSeconds.Set(stalled.Seconds())
+
+	lagExceeded := m.maxQueueLag > 0 && lag > m.maxQueueLag
+	stallExceeded := m.maxStalledSec > 0 && stalled > time.Duration(m.maxStalledSec)*time.Second
+	if lagExceeded || stallExceeded {
+		m.l1MessageQueueLagThresholdExceed.Set(1)
+		log.Error("l1 message queue lag exceeds the threshold",
+			"queueLength", queueLength, "l2NextQueueIndex", nextQueueIndex, "lag", lag, "stalled", stalled,
+			"maxQueueLag", m.maxQueueLag, "maxStalledSec", m.maxStalledSec)
+		return
+	}
+	m.l1MessageQueueLagThresholdExceed.Set(0)
+}
+
+// l2NextQueueIndex returns the next l1 message queue index to be included on layer 2,
+// based on the latest chunk and the l1 messages included in the unchunked blocks after it.
+func (m *L1MessageQueueMonitor) l2NextQueueIndex(ctx context.Context) (uint64, error) {
+	var nextQueueIndex uint64
+	startBlockNumber := uint64(1)
+
+	latestChunk, err := m.chunkOrm.GetLatestChunk(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	if latestChunk != nil {
+		nextQueueIndex = latestChunk.TotalL1MessagesPoppedBefore + uint64(latestChunk.TotalL1MessagesPoppedInChunk)
+		startBlockNumber = latestChunk.EndBlockNumber + 1
+	}
+
+	latestQueueIndex, found, err := m.l2BlockOrm.GetLatestL1MessageQueueIndexGEHeight(ctx, startBlockNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the latest l1 message queue index of the unchunked blocks: %w", err)
+	}
+	if found && latestQueueIndex+1 > nextQueueIndex {
+		nextQueueIndex = latestQueueIndex + 1
+	}
+	return nextQueueIndex, nil
+}
@@ -0,0 +1,67 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+	"scroll-tech/rollup/internal/orm/fake"
+)
+
+func testL1MessageQueueMonitor(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	// block 4 follows the latest chunk and includes the l1 messages 5 and 6
+	header := *wrappedBlock2.Header
+	header.Number = big.NewInt(4)
+	l1MessageBlock := &types.WrappedBlock{
+		Header: &header,
+		Transactions: []*gethTypes.TransactionData{
+			{Type: gethTypes.L1MessageTxType, Nonce: 5, TxHash: common.HexToHash("0x05").Hex()},
+			{Type: gethTypes.L1MessageTxType, Nonce: 6, TxHash: common.HexToHash("0x06").Hex()},
+			{Type: gethTypes.LegacyTxType, Nonce: 100, TxHash: common.HexToHash("0x64").Hex()},
+		},
+		WithdrawRoot:   wrappedBlock2.WithdrawRoot,
+		RowConsumption: wrappedBlock2.RowConsumption,
+	}
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2, l1MessageBlock})
+	assert.NoError(t, err)
+
+	l1MessageRepo := fake.NewL1MessageRepo()
+	var messages []*orm.L1Message
+	for i := uint64(0); i < 10; i++ {
+		messages = append(messages, &orm.L1Message{QueueIndex: i, MsgHash: common.BigToHash(new(big.Int).SetUint64(i)).Hex()})
+	}
+	assert.NoError(t, l1MessageRepo.SaveL1Messages(context.Background(), messages))
+
+	monitor := NewL1MessageQueueMonitor(&config.L1MessageQueueMonitorConfig{CheckIntervalSec: 1, MaxQueueLag: 2}, db, nil)
+	monitor.l1MessageOrm = l1MessageRepo
+
+	// no chunk yet, the queue index comes from the blocks only
+	monitor.chunkOrm = fake.NewChunkRepo()
+	monitor.TryCheckQueueLag(context.Background())
+	assert.Equal(t, float64(7), testutil.ToFloat64(monitor.l1MessageQueueL2NextIndex))
+	assert.Equal(t, float64(3), testutil.ToFloat64(monitor.l1MessageQueueLag))
+	assert.Equal(t, float64(1), testutil.ToFloat64(monitor.l1MessageQueueLagThresholdExceed))
+
+	// the l1 messages included in the chunked blocks are not scanned again
+	chunkRepo := fake.NewChunkRepo()
+	chunkRepo.AddChunks(&orm.Chunk{Index: 0, StartBlockNumber: 1, EndBlockNumber: 4, TotalL1MessagesPoppedBefore: 0, TotalL1MessagesPoppedInChunk: 8})
+	monitor.chunkOrm = chunkRepo
+	monitor.TryCheckQueueLag(context.Background())
+	assert.Equal(t, float64(8), testutil.ToFloat64(monitor.l1MessageQueueL2NextIndex))
+	assert.Equal(t, float64(2), testutil.ToFloat64(monitor.l1MessageQueueLag))
+	assert.Equal(t, float64(0), testutil.ToFloat64(monitor.l1MessageQueueLagThresholdExceed))
+}
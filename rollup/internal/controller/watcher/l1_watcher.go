@@ -3,6 +3,7 @@ package watcher
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,6 +17,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/observability"
 	"scroll-tech/common/types"
 
 	bridgeAbi "scroll-tech/rollup/abi"
@@ -23,19 +25,27 @@ import (
 	"scroll-tech/rollup/internal/utils"
 )
 
+// ErrL1MessageQueueIndexAnomaly is returned when the queue indexes of the fetched QueueTransaction events have
+// a gap or a duplicate, the events of the block range are not ingested until a re-fetch of the range is verified.
+var ErrL1MessageQueueIndexAnomaly = errors.New("l1 message queue index anomaly")
+
 type rollupEvent struct {
-	batchHash common.Hash
-	txHash    common.Hash
-	status    types.RollupStatus
+	batchIndex   *big.Int
+	batchHash    common.Hash
+	withdrawRoot common.Hash
+	txHash       common.Hash
+	blockNumber  uint64
+	status       types.RollupStatus
 }
 
 // L1WatcherClient will listen for smart contract events from Eth L1.
 type L1WatcherClient struct {
-	ctx          context.Context
 	client       *ethclient.Client
 	l1MessageOrm *orm.L1Message
 	l1BlockOrm   *orm.L1Block
 	batchOrm     *orm.Batch
+	// records the rollup events, so the batch state at any past l1 block can be queried.
+	batchEventOrm *orm.BatchEvent
 
 	// The number of new blocks to wait for a block to be confirmed
 	confirmations rpc.BlockNumber
@@ -46,6 +56,12 @@ type L1WatcherClient struct {
 	scrollChainAddress common.Address
 	scrollChainABI     *abi.ABI
 
+	// cross-checks finalized batches against multiple l1 endpoints, nil if disabled.
+	quorumCaller *utils.QuorumCaller
+
+	// reads the logs pruned by the l1 endpoint, nil if disabled.
+	archiveClient *ethclient.Client
+
 	// The height of the block that the watcher has retrieved event logs
 	processedMsgHeight uint64
 	// The height of the block that the watcher has retrieved header rlp
@@ -77,11 +93,11 @@ func NewL1WatcherClient(ctx context.Context, client *ethclient.Client, startHeig
 	}
 
 	return &L1WatcherClient{
-		ctx:           ctx,
 		client:        client,
 		l1MessageOrm:  l1MessageOrm,
 		l1BlockOrm:    l1BlockOrm,
 		batchOrm:      orm.NewBatch(db),
+		batchEventOrm: orm.NewBatchEvent(db),
 		confirmations: confirmations,
 
 		messageQueueAddress: messageQueueAddress,
@@ -96,6 +112,41 @@ func NewL1WatcherClient(ctx context.Context, client *ethclient.Client, startHeig
 	}
 }
 
+// DebugState returns the state of the l1 watcher for the debug server, the heights are the ones stored.
+func (w *L1WatcherClient) DebugState() interface{} {
+	ctx, cancel := context.WithTimeout(context.Background(), observability.StateDumpTimeout)
+	defer cancel()
+
+	state := map[string]interface{}{
+		"confirmations": w.confirmations.Int64(),
+		"quorum":        w.quorumCaller != nil,
+		"archive":       w.archiveClient != nil,
+	}
+	if height, err := w.l1BlockOrm.GetLatestL1BlockHeight(ctx); err != nil {
+		state["latest_block_height_error"] = err.Error()
+	} else {
+		state["latest_block_height"] = height
+	}
+	if length, err := w.l1MessageOrm.GetL1MessageQueueLength(ctx); err != nil {
+		state["message_queue_length_error"] = err.Error()
+	} else {
+		state["message_queue_length"] = length
+	}
+	return state
+}
+
+// SetQuorumCaller enables verifying finalized batch hashes and withdraw roots
+// against multiple l1 endpoints before they are trusted.
+func (w *L1WatcherClient) SetQuorumCaller(quorumCaller *utils.QuorumCaller) {
+	w.quorumCaller = quorumCaller
+}
+
+// SetArchiveClient enables reading the logs of the blocks pruned by the l1 endpoint from an archive endpoint,
+// instead of failing the same block range forever.
+func (w *L1WatcherClient) SetArchiveClient(archiveClient *ethclient.Client) {
+	w.archiveClient = archiveClient
+}
+
 // ProcessedBlockHeight get processedBlockHeight
 // Currently only use for unit test
 func (w *L1WatcherClient) ProcessedBlockHeight() uint64 {
@@ -115,11 +166,11 @@ func (w *L1WatcherClient) SetConfirmations(confirmations rpc.BlockNumber) {
 }
 
 // FetchBlockHeader pull latest L1 blocks and save in DB
-func (w *L1WatcherClient) FetchBlockHeader(blockHeight uint64) error {
+func (w *L1WatcherClient) FetchBlockHeader(ctx context.Context, blockHeight uint64) error {
 	w.metrics.l1WatcherFetchBlockHeaderTotal.Inc()
 
 	var block *gethTypes.Header
-	block, err := w.client.HeaderByNumber(w.ctx, big.NewInt(int64(blockHeight)))
+	block, err := w.client.HeaderByNumber(ctx, big.NewInt(int64(blockHeight)))
 	if err != nil {
 		log.Warn("Failed to get block", "height", blockHeight, "err", err)
 		return err
@@ -135,14 +186,20 @@ func (w *L1WatcherClient) FetchBlockHeader(blockHeight uint64) error {
 		baseFee = block.BaseFee.Uint64()
 	}
 
+	var blobBaseFee uint64
+	if block.ExcessBlobGas != nil {
+		blobBaseFee = utils.CalcBlobBaseFee(*block.ExcessBlobGas).Uint64()
+	}
+
 	l1Block := orm.L1Block{
 		Number:          blockHeight,
 		Hash:            block.Hash().String(),
 		BaseFee:         baseFee,
+		BlobBaseFee:     blobBaseFee,
 		GasOracleStatus: int16(types.GasOraclePending),
 	}
 
-	err = w.l1BlockOrm.InsertL1Blocks(w.ctx, []orm.L1Block{l1Block})
+	err = w.l1BlockOrm.InsertL1Blocks(ctx, []orm.L1Block{l1Block})
 	if err != nil {
 		log.Warn("Failed to insert L1 block to db", "blockHeight", blockHeight, "err", err)
 		return err
@@ -155,11 +212,11 @@ func (w *L1WatcherClient) FetchBlockHeader(blockHeight uint64) error {
 }
 
 // FetchContractEvent pull latest event logs from given contract address and save in DB
-func (w *L1WatcherClient) FetchContractEvent() error {
+func (w *L1WatcherClient) FetchContractEvent(ctx context.Context) error {
 	defer func() {
 		log.Info("l1 watcher fetchContractEvent", "w.processedMsgHeight", w.processedMsgHeight)
 	}()
-	blockHeight, err := utils.GetLatestConfirmedBlockNumber(w.ctx, w.client, w.confirmations)
+	blockHeight, err := utils.GetLatestConfirmedBlockNumber(ctx, w.client, w.confirmations)
 	if err != nil {
 		log.Error("failed to get block number", "err", err)
 		return err
@@ -191,7 +248,7 @@ func (w *L1WatcherClient) FetchContractEvent() error {
 		query.Topics[0][1] = bridgeAbi.L1CommitBatchEventSignature
 		query.Topics[0][2] = bridgeAbi.L1FinalizeBatchEventSignature
 
-		logs, err := w.client.FilterLogs(w.ctx, query)
+		logs, err := w.filterLogs(ctx, query)
 		if err != nil {
 			log.Warn("Failed to get event logs", "err", err)
 			return err
@@ -204,23 +261,42 @@ func (w *L1WatcherClient) FetchContractEvent() error {
 
 		log.Info("Received new L1 events", "fromBlock", from, "toBlock", to, "cnt", len(logs))
 
-		sentMessageEvents, rollupEvents, err := w.parseBridgeEventLogs(logs)
+		sentMessageEvents, rollupEvents, err := w.parseBridgeEventLogs(ctx, logs)
 		if err != nil {
 			log.Error("Failed to parse emitted events log", "err", err)
 			return err
 		}
+		if sentMessageEvents, rollupEvents, err = w.verifyQueueIndexes(ctx, query, sentMessageEvents, rollupEvents); err != nil {
+			log.Error("Quarantined l1 message ingestion", "fromBlock", from, "toBlock", to, "err", err)
+			return err
+		}
 		sentMessageCount := int64(len(sentMessageEvents))
 		rollupEventCount := int64(len(rollupEvents))
 		w.metrics.l1WatcherFetchContractEventSentEventsTotal.Add(float64(sentMessageCount))
 		w.metrics.l1WatcherFetchContractEventRollupEventsTotal.Add(float64(rollupEventCount))
 		log.Info("L1 events types", "SentMessageCount", sentMessageCount, "RollupEventCount", rollupEventCount)
 
+		batchEvents := make([]*orm.BatchEvent, 0, len(rollupEvents))
+		for _, event := range rollupEvents {
+			batchEvents = append(batchEvents, &orm.BatchEvent{
+				BatchIndex:    event.batchIndex.Uint64(),
+				BatchHash:     event.batchHash.String(),
+				RollupStatus:  int16(event.status),
+				L1BlockNumber: event.blockNumber,
+				L1TxHash:      event.txHash.String(),
+			})
+		}
+		if err = w.batchEventOrm.InsertBatchEvents(ctx, batchEvents); err != nil {
+			log.Error("Failed to insert batch events", "err", err)
+			return err
+		}
+
 		// use rollup event to update rollup results db status
 		var batchHashes []string
 		for _, event := range rollupEvents {
 			batchHashes = append(batchHashes, event.batchHash.String())
 		}
-		statuses, err := w.batchOrm.GetRollupStatusByHashList(w.ctx, batchHashes)
+		statuses, err := w.batchOrm.GetRollupStatusByHashList(ctx, batchHashes)
 		if err != nil {
 			log.Error("Failed to GetRollupStatusByHashList", "err", err)
 			return err
@@ -236,9 +312,13 @@ func (w *L1WatcherClient) FetchContractEvent() error {
 			// only update when db status is before event status
 			if event.status > status {
 				if event.status == types.RollupFinalized {
-					err = w.batchOrm.UpdateFinalizeTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
+					if err = w.verifyFinalizedBatch(ctx, event); err != nil {
+						log.Error("Failed to verify finalized batch against quorum", "batchIndex", event.batchIndex, "batchHash", batchHash, "err", err)
+						return err
+					}
+					err = w.batchOrm.UpdateFinalizeTxHashAndRollupStatus(ctx, batchHash, event.txHash.String(), event.status)
 				} else if event.status == types.RollupCommitted {
-					err = w.batchOrm.UpdateCommitTxHashAndRollupStatus(w.ctx, batchHash, event.txHash.String(), event.status)
+					err = w.batchOrm.UpdateCommitTxHashAndRollupStatus(ctx, batchHash, event.txHash.String(), event.status)
 				}
 				if err != nil {
 					log.Error("Failed to update Rollup/Finalize TxHash and Status", "err", err)
@@ -247,7 +327,7 @@ func (w *L1WatcherClient) FetchContractEvent() error {
 			}
 		}
 
-		if err = w.l1MessageOrm.SaveL1Messages(w.ctx, sentMessageEvents); err != nil {
+		if err = w.l1MessageOrm.SaveL1Messages(ctx, sentMessageEvents); err != nil {
 			return err
 		}
 
@@ -259,7 +339,104 @@ func (w *L1WatcherClient) FetchContractEvent() error {
 	return nil
 }
 
-func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1Message, []rollupEvent, error) {
+// verifyQueueIndexes checks that the queue indexes of the fetched l1 messages follow the stored ones without
+// gap or duplicate. A provider glitch is retried by re-fetching the block range once, the re-fetched events
+// replace the fetched ones if their queue indexes are consistent and include all the fetched ones.
+func (w *L1WatcherClient) verifyQueueIndexes(ctx context.Context, query geth.FilterQuery, l1Messages []*orm.L1Message, rollupEvents []rollupEvent) ([]*orm.L1Message, []rollupEvent, error) {
+	if len(l1Messages) == 0 {
+		return l1Messages, rollupEvents, nil
+	}
+	queueLength, err := w.l1MessageOrm.GetL1MessageQueueLength(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	anomaly := checkQueueIndexes(queueLength, l1Messages)
+	if anomaly == nil {
+		w.metrics.l1WatcherQueueIndexQuarantined.Set(0)
+		return l1Messages, rollupEvents, nil
+	}
+
+	w.metrics.l1WatcherQueueIndexAnomalyTotal.WithLabelValues(anomaly.kind).Inc()
+	log.Warn("l1 message queue index anomaly, re-fetching the block range", "fromBlock", query.FromBlock, "toBlock", query.ToBlock, "err", anomaly)
+
+	logs, err := w.filterLogs(ctx, query)
+	if err != nil {
+		w.metrics.l1WatcherQueueIndexQuarantined.Set(1)
+		return nil, nil, fmt.Errorf("%w: %v, failed to re-fetch the block range: %v", ErrL1MessageQueueIndexAnomaly, anomaly, err)
+	}
+	refetchedMessages, refetchedRollupEvents, err := w.parseBridgeEventLogs(ctx, logs)
+	if err != nil {
+		w.metrics.l1WatcherQueueIndexQuarantined.Set(1)
+		return nil, nil, fmt.Errorf("%w: %v, failed to parse the re-fetched block range: %v", ErrL1MessageQueueIndexAnomaly, anomaly, err)
+	}
+	if refetchedAnomaly := checkQueueIndexes(queueLength, refetchedMessages); refetchedAnomaly != nil {
+		w.metrics.l1WatcherQueueIndexAnomalyTotal.WithLabelValues(refetchedAnomaly.kind).Inc()
+		w.metrics.l1WatcherQueueIndexQuarantined.Set(1)
+		return nil, nil, fmt.Errorf("%w: %v, re-fetched: %v", ErrL1MessageQueueIndexAnomaly, anomaly, refetchedAnomaly)
+	}
+	refetchedIndexes := make(map[uint64]struct{}, len(refetchedMessages))
+	for _, msg := range refetchedMessages {
+		refetchedIndexes[msg.QueueIndex] = struct{}{}
+	}
+	for _, msg := range l1Messages {
+		if _, ok := refetchedIndexes[msg.QueueIndex]; !ok && msg.QueueIndex >= queueLength {
+			w.metrics.l1WatcherQueueIndexQuarantined.Set(1)
+			return nil, nil, fmt.Errorf("%w: %v, queue index %v is missing from the re-fetched block range", ErrL1MessageQueueIndexAnomaly, anomaly, msg.QueueIndex)
+		}
+	}
+
+	w.metrics.l1WatcherQueueIndexQuarantined.Set(0)
+	log.Info("verified the re-fetched block range", "fromBlock", query.FromBlock, "toBlock", query.ToBlock, "l1 messages", len(refetchedMessages))
+	return refetchedMessages, refetchedRollupEvents, nil
+}
+
+// queueIndexAnomaly is a gap or a duplicate in the queue indexes of the fetched l1 messages.
+type queueIndexAnomaly struct {
+	kind     string
+	expected uint64
+	actual   uint64
+}
+
+func (a *queueIndexAnomaly) Error() string {
+	return fmt.Sprintf("%v queue index: expected %v, got %v", a.kind, a.expected, a.actual)
+}
+
+// checkQueueIndexes checks that the queue indexes of the l1 messages, in log order, increase one by one from
+// the stored queue length. The first message of an empty queue may have any queue index, so the watcher can
+// start after the first messages.
+func checkQueueIndexes(queueLength uint64, l1Messages []*orm.L1Message) *queueIndexAnomaly {
+	expected := queueLength
+	for i, msg := range l1Messages {
+		if i == 0 && queueLength == 0 {
+			expected = msg.QueueIndex
+		}
+		switch {
+		case msg.QueueIndex < expected:
+			return &queueIndexAnomaly{kind: "duplicate", expected: expected, actual: msg.QueueIndex}
+		case msg.QueueIndex > expected:
+			return &queueIndexAnomaly{kind: "gap", expected: expected, actual: msg.QueueIndex}
+		}
+		expected++
+	}
+	return nil
+}
+
+// filterLogs filters the logs from the l1 endpoint, the logs it pruned from the archive endpoint.
+func (w *L1WatcherClient) filterLogs(ctx context.Context, query geth.FilterQuery) ([]gethTypes.Log, error) {
+	logs, err := w.client.FilterLogs(ctx, query)
+	if err == nil || !utils.IsMissingHistoryError(err) {
+		return logs, err
+	}
+
+	w.metrics.l1WatcherMissingHistoryTotal.Inc()
+	if w.archiveClient == nil {
+		return nil, fmt.Errorf("%w: the l1 endpoint pruned the logs of blocks %v-%v, configure an archive_endpoint: %v", utils.ErrMissingHistory, query.FromBlock, query.ToBlock, err)
+	}
+	log.Warn("the l1 endpoint pruned the logs, reading them from the archive endpoint", "fromBlock", query.FromBlock, "toBlock", query.ToBlock, "err", err)
+	return w.archiveClient.FilterLogs(ctx, query)
+}
+
+func (w *L1WatcherClient) parseBridgeEventLogs(ctx context.Context, logs []gethTypes.Log) ([]*orm.L1Message, []rollupEvent, error) {
 	// Need use contract abi to parse event Log
 	// Can only be tested after we have our contracts set up
 	var l1Messages []*orm.L1Message
@@ -274,17 +451,30 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 				return l1Messages, rollupEvents, err
 			}
 
-			msgHash := common.BytesToHash(crypto.Keccak256(event.Data))
+			if !event.GasLimit.IsUint64() {
+				log.Error("QueueTransaction gas limit overflows uint64", "queueIndex", event.QueueIndex, "gasLimit", event.GasLimit, "txHash", vLog.TxHash)
+				return l1Messages, rollupEvents, fmt.Errorf("QueueTransaction gas limit %v overflows uint64, queue index: %v", event.GasLimit, event.QueueIndex)
+			}
+
+			msg := &types.L1Message{
+				QueueIndex: event.QueueIndex,
+				Gas:        event.GasLimit.Uint64(),
+				To:         event.Target,
+				Value:      event.Value,
+				Data:       event.Data,
+				Sender:     event.Sender,
+			}
 
 			l1Messages = append(l1Messages, &orm.L1Message{
 				QueueIndex: event.QueueIndex,
-				MsgHash:    msgHash.String(),
+				MsgHash:    common.BytesToHash(crypto.Keccak256(event.Data)).String(),
+				TxHash:     msg.Hash().String(),
 				Height:     vLog.BlockNumber,
 				Sender:     event.Sender.String(),
 				Value:      event.Value.String(),
 				Target:     event.Target.String(),
 				Calldata:   common.Bytes2Hex(event.Data),
-				GasLimit:   event.GasLimit.Uint64(),
+				GasLimit:   msg.Gas,
 				Layer1Hash: vLog.TxHash.Hex(),
 			})
 		case bridgeAbi.L1CommitBatchEventSignature:
@@ -296,9 +486,11 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 			}
 
 			rollupEvents = append(rollupEvents, rollupEvent{
-				batchHash: event.BatchHash,
-				txHash:    vLog.TxHash,
-				status:    types.RollupCommitted,
+				batchIndex:  event.BatchIndex,
+				batchHash:   event.BatchHash,
+				txHash:      vLog.TxHash,
+				blockNumber: vLog.BlockNumber,
+				status:      types.RollupCommitted,
 			})
 		case bridgeAbi.L1FinalizeBatchEventSignature:
 			event := bridgeAbi.L1FinalizeBatchEvent{}
@@ -309,9 +501,12 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 			}
 
 			rollupEvents = append(rollupEvents, rollupEvent{
-				batchHash: event.BatchHash,
-				txHash:    vLog.TxHash,
-				status:    types.RollupFinalized,
+				batchIndex:   event.BatchIndex,
+				batchHash:    event.BatchHash,
+				withdrawRoot: event.WithdrawRoot,
+				txHash:       vLog.TxHash,
+				blockNumber:  vLog.BlockNumber,
+				status:       types.RollupFinalized,
 			})
 		default:
 			log.Error("Unknown event", "topic", vLog.Topics[0], "txHash", vLog.TxHash)
@@ -320,3 +515,39 @@ func (w *L1WatcherClient) parseBridgeEventLogs(logs []gethTypes.Log) ([]*orm.L1M
 
 	return l1Messages, rollupEvents, nil
 }
+
+// verifyFinalizedBatch checks the finalized batch hash and withdraw root reported
+// by the event against a quorum of l1 endpoints.
+func (w *L1WatcherClient) verifyFinalizedBatch(ctx context.Context, event rollupEvent) error {
+	if w.quorumCaller == nil {
+		return nil
+	}
+	if err := w.verifyScrollChainValue(ctx, "committedBatches", event.batchIndex, event.batchHash); err != nil {
+		return err
+	}
+	return w.verifyScrollChainValue(ctx, "withdrawRoots", event.batchIndex, event.withdrawRoot)
+}
+
+func (w *L1WatcherClient) verifyScrollChainValue(ctx context.Context, method string, batchIndex *big.Int, expected common.Hash) error {
+	data, err := w.scrollChainABI.Pack(method, batchIndex)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+
+	w.metrics.l1WatcherQuorumReadTotal.WithLabelValues(method).Inc()
+	result, err := w.quorumCaller.CallContract(ctx, geth.CallMsg{To: &w.scrollChainAddress, Data: data}, nil)
+	if result != nil && result.Diverged {
+		w.metrics.l1WatcherQuorumDivergenceTotal.WithLabelValues(method).Inc()
+		log.Warn("L1 endpoints diverged on quorum read", "method", method, "batchIndex", batchIndex, "agreed", result.Agreed, "responded", result.Responded)
+	}
+	if err != nil {
+		w.metrics.l1WatcherQuorumFailureTotal.WithLabelValues(method).Inc()
+		return fmt.Errorf("quorum read %s failed: %w", method, err)
+	}
+
+	if actual := common.BytesToHash(result.Data); actual != expected {
+		w.metrics.l1WatcherQuorumFailureTotal.WithLabelValues(method).Inc()
+		return fmt.Errorf("quorum read %s mismatch, expected: %s, actual: %s", method, expected.Hex(), actual.Hex())
+	}
+	return nil
+}
@@ -15,6 +15,12 @@ type l1WatcherMetrics struct {
 	l1WatcherFetchContractEventProcessedBlockHeight prometheus.Gauge
 	l1WatcherFetchContractEventSentEventsTotal      prometheus.Counter
 	l1WatcherFetchContractEventRollupEventsTotal    prometheus.Counter
+	l1WatcherQuorumReadTotal                        *prometheus.CounterVec
+	l1WatcherQuorumDivergenceTotal                  *prometheus.CounterVec
+	l1WatcherQuorumFailureTotal                     *prometheus.CounterVec
+	l1WatcherMissingHistoryTotal                    prometheus.Counter
+	l1WatcherQueueIndexAnomalyTotal                 *prometheus.CounterVec
+	l1WatcherQueueIndexQuarantined                  prometheus.Gauge
 }
 
 var (
@@ -53,6 +59,30 @@ func initL1WatcherMetrics(reg prometheus.Registerer) *l1WatcherMetrics {
 				Name: "rollup_l1_watcher_fetch_block_contract_event_rollup_event_total",
 				Help: "The current processed block height of l1 watcher fetch contract rollup event",
 			}),
+			l1WatcherQuorumReadTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_quorum_read_total",
+				Help: "The total number of l1 watcher quorum reads",
+			}, []string{"method"}),
+			l1WatcherQuorumDivergenceTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_quorum_divergence_total",
+				Help: "The total number of l1 watcher quorum reads where endpoints returned different results",
+			}, []string{"method"}),
+			l1WatcherQuorumFailureTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_quorum_failure_total",
+				Help: "The total number of l1 watcher quorum reads that failed or mismatched the event",
+			}, []string{"method"}),
+			l1WatcherMissingHistoryTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_missing_history_total",
+				Help: "The total number of l1 watcher log queries answered with missing history by the l1 endpoint",
+			}),
+			l1WatcherQueueIndexAnomalyTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_l1_watcher_queue_index_anomaly_total",
+				Help: "The total number of fetched block ranges whose l1 message queue indexes have a gap or a duplicate",
+			}, []string{"kind"}),
+			l1WatcherQueueIndexQuarantined: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+				Name: "rollup_l1_watcher_queue_index_quarantined",
+				Help: "Whether the l1 message ingestion is quarantined on a queue index anomaly not resolved by a re-fetch",
+			}),
 		}
 	})
 	return l1WatcherMetric
@@ -11,6 +11,7 @@ import (
 	"github.com/scroll-tech/go-ethereum/accounts/abi"
 	"github.com/scroll-tech/go-ethereum/common"
 	"github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/crypto"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/smartystreets/goconvey/convey"
@@ -31,14 +32,14 @@ func setupL1Watcher(t *testing.T) (*L1WatcherClient, *gorm.DB) {
 	assert.NoError(t, err)
 	l1Cfg := cfg.L1Config
 	watcher := NewL1WatcherClient(context.Background(), client, l1Cfg.StartHeight, l1Cfg.Confirmations, l1Cfg.L1MessageQueueAddress, l1Cfg.RelayerConfig.RollupContractAddress, db, nil)
-	assert.NoError(t, watcher.FetchContractEvent())
+	assert.NoError(t, watcher.FetchContractEvent(context.Background()))
 	return watcher, db
 }
 
 func testFetchContractEvent(t *testing.T) {
 	watcher, db := setupL1Watcher(t)
 	defer database.CloseDB(db)
-	assert.NoError(t, watcher.FetchContractEvent())
+	assert.NoError(t, watcher.FetchContractEvent(context.Background()))
 }
 
 func testL1WatcherClientFetchBlockHeader(t *testing.T) {
@@ -51,7 +52,7 @@ func testL1WatcherClientFetchBlockHeader(t *testing.T) {
 		} else {
 			blockHeight = watcher.ProcessedBlockHeight() - 1
 		}
-		err := watcher.FetchBlockHeader(blockHeight)
+		err := watcher.FetchBlockHeader(context.Background(), blockHeight)
 		assert.NoError(t, err)
 	})
 
@@ -63,7 +64,7 @@ func testL1WatcherClientFetchBlockHeader(t *testing.T) {
 		defer patchGuard.Reset()
 
 		var blockHeight uint64 = 10
-		err := watcher.FetchBlockHeader(blockHeight)
+		err := watcher.FetchBlockHeader(context.Background(), blockHeight)
 		assert.Error(t, err)
 	})
 
@@ -86,7 +87,7 @@ func testL1WatcherClientFetchBlockHeader(t *testing.T) {
 		})
 
 		var blockHeight uint64 = 10
-		err := watcher.FetchBlockHeader(blockHeight)
+		err := watcher.FetchBlockHeader(context.Background(), blockHeight)
 		assert.Error(t, err)
 	})
 
@@ -108,7 +109,7 @@ func testL1WatcherClientFetchBlockHeader(t *testing.T) {
 		})
 
 		var blockHeight uint64 = 10
-		err := watcher.FetchBlockHeader(blockHeight)
+		err := watcher.FetchBlockHeader(context.Background(), blockHeight)
 		assert.NoError(t, err)
 	})
 }
@@ -124,7 +125,7 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 			return nil, ethereum.NotFound
 		})
 		defer patchGuard.Reset()
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.Error(t, err)
 	})
 
@@ -146,10 +147,30 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 		patchGuard.ApplyMethodFunc(c, "FilterLogs", func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
 			return nil, targetErr
 		})
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.EqualError(t, err, targetErr.Error())
 	})
 
+	convey.Convey("filter logs missing history", t, func() {
+		processedMsgHeight := watcher.processedMsgHeight
+		defer func() { watcher.processedMsgHeight = processedMsgHeight }()
+
+		archiveClient := &ethclient.Client{}
+		patchGuard.ApplyMethod(c, "FilterLogs", func(client *ethclient.Client, ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+			if client == archiveClient {
+				return nil, nil
+			}
+			return nil, errors.New("block #1 has been pruned")
+		})
+		err := watcher.FetchContractEvent(context.Background())
+		assert.ErrorIs(t, err, utils.ErrMissingHistory)
+
+		watcher.SetArchiveClient(archiveClient)
+		defer watcher.SetArchiveClient(nil)
+		assert.NoError(t, watcher.FetchContractEvent(context.Background()))
+		assert.Equal(t, uint64(100), watcher.processedMsgHeight)
+	})
+
 	patchGuard.ApplyMethodFunc(c, "FilterLogs", func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
 		return []types.Log{
 			{
@@ -160,14 +181,14 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 
 	convey.Convey("parse bridge event logs failure", t, func() {
 		targetErr := errors.New("parse log failure")
-		patchGuard.ApplyPrivateMethod(watcher, "parseBridgeEventLogs", func(*L1WatcherClient, []types.Log) ([]*orm.L1Message, []rollupEvent, error) {
+		patchGuard.ApplyPrivateMethod(watcher, "parseBridgeEventLogs", func(*L1WatcherClient, context.Context, []types.Log) ([]*orm.L1Message, []rollupEvent, error) {
 			return nil, nil, targetErr
 		})
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.EqualError(t, err, targetErr.Error())
 	})
 
-	patchGuard.ApplyPrivateMethod(watcher, "parseBridgeEventLogs", func(*L1WatcherClient, []types.Log) ([]*orm.L1Message, []rollupEvent, error) {
+	patchGuard.ApplyPrivateMethod(watcher, "parseBridgeEventLogs", func(*L1WatcherClient, context.Context, []types.Log) ([]*orm.L1Message, []rollupEvent, error) {
 		rollupEvents := []rollupEvent{
 			{
 				batchHash: common.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"),
@@ -189,7 +210,7 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 		patchGuard.ApplyMethodFunc(batchOrm, "GetRollupStatusByHashList", func(context.Context, []string) ([]commonTypes.RollupStatus, error) {
 			return nil, targetErr
 		})
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.Equal(t, err.Error(), targetErr.Error())
 	})
 
@@ -200,7 +221,7 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 			}
 			return s, nil
 		})
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.NoError(t, err)
 	})
 
@@ -217,7 +238,7 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 		patchGuard.ApplyMethodFunc(batchOrm, "UpdateFinalizeTxHashAndRollupStatus", func(context.Context, string, string, commonTypes.RollupStatus) error {
 			return targetErr
 		})
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.Equal(t, targetErr.Error(), err.Error())
 	})
 
@@ -230,7 +251,7 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 		patchGuard.ApplyMethodFunc(batchOrm, "UpdateCommitTxHashAndRollupStatus", func(context.Context, string, string, commonTypes.RollupStatus) error {
 			return targetErr
 		})
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.Equal(t, targetErr.Error(), err.Error())
 	})
 
@@ -244,7 +265,7 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 		patchGuard.ApplyMethodFunc(l1MessageOrm, "SaveL1Messages", func(context.Context, []*orm.L1Message) error {
 			return targetErr
 		})
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.Equal(t, targetErr.Error(), err.Error())
 	})
 
@@ -253,7 +274,7 @@ func testL1WatcherClientFetchContractEvent(t *testing.T) {
 	})
 
 	convey.Convey("FetchContractEvent success", t, func() {
-		err := watcher.FetchContractEvent()
+		err := watcher.FetchContractEvent(context.Background())
 		assert.NoError(t, err)
 	})
 }
@@ -277,7 +298,7 @@ func testParseBridgeEventLogsL1QueueTransactionEventSignature(t *testing.T) {
 		})
 		defer patchGuard.Reset()
 
-		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(context.Background(), logs)
 		assert.EqualError(t, err, targetErr.Error())
 		assert.Empty(t, l2Messages)
 		assert.Empty(t, rollupEvents)
@@ -296,11 +317,38 @@ func testParseBridgeEventLogsL1QueueTransactionEventSignature(t *testing.T) {
 		})
 		defer patchGuard.Reset()
 
-		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(context.Background(), logs)
 		assert.NoError(t, err)
 		assert.Empty(t, rollupEvents)
 		assert.Len(t, l2Messages, 1)
 		assert.Equal(t, l2Messages[0].Value, big.NewInt(1000).String())
+		assert.Equal(t, uint64(10), l2Messages[0].GasLimit)
+		assert.Equal(t, common.BytesToHash(crypto.Keccak256([]byte("test data"))).String(), l2Messages[0].MsgHash)
+		msg := &commonTypes.L1Message{
+			QueueIndex: 100,
+			Gas:        10,
+			To:         common.HexToAddress("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5"),
+			Value:      big.NewInt(1000),
+			Data:       []byte("test data"),
+			Sender:     common.HexToAddress("0xb4c11951957c6f8f642c4af61cd6b24640fec6dc7fc607ee8206a99e92410d30"),
+		}
+		assert.Equal(t, msg.Hash().String(), l2Messages[0].TxHash)
+	})
+
+	convey.Convey("QueueTransaction gas limit overflows uint64", t, func() {
+		patchGuard := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
+			tmpOut := out.(*bridgeAbi.L1QueueTransactionEvent)
+			tmpOut.QueueIndex = 100
+			tmpOut.Value = big.NewInt(1000)
+			tmpOut.GasLimit = new(big.Int).Lsh(big.NewInt(1), 64)
+			return nil
+		})
+		defer patchGuard.Reset()
+
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(context.Background(), logs)
+		assert.ErrorContains(t, err, "overflows uint64")
+		assert.Empty(t, l2Messages)
+		assert.Empty(t, rollupEvents)
 	})
 }
 
@@ -322,7 +370,7 @@ func testParseBridgeEventLogsL1CommitBatchEventSignature(t *testing.T) {
 		})
 		defer patchGuard.Reset()
 
-		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(context.Background(), logs)
 		assert.EqualError(t, err, targetErr.Error())
 		assert.Empty(t, l2Messages)
 		assert.Empty(t, rollupEvents)
@@ -337,7 +385,7 @@ func testParseBridgeEventLogsL1CommitBatchEventSignature(t *testing.T) {
 		})
 		defer patchGuard.Reset()
 
-		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(context.Background(), logs)
 		assert.NoError(t, err)
 		assert.Empty(t, l2Messages)
 		assert.Len(t, rollupEvents, 1)
@@ -364,7 +412,7 @@ func testParseBridgeEventLogsL1FinalizeBatchEventSignature(t *testing.T) {
 		})
 		defer patchGuard.Reset()
 
-		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(context.Background(), logs)
 		assert.EqualError(t, err, targetErr.Error())
 		assert.Empty(t, l2Messages)
 		assert.Empty(t, rollupEvents)
@@ -379,7 +427,7 @@ func testParseBridgeEventLogsL1FinalizeBatchEventSignature(t *testing.T) {
 		})
 		defer patchGuard.Reset()
 
-		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(logs)
+		l2Messages, rollupEvents, err := watcher.parseBridgeEventLogs(context.Background(), logs)
 		assert.NoError(t, err)
 		assert.Empty(t, l2Messages)
 		assert.Len(t, rollupEvents, 1)
@@ -387,3 +435,67 @@ func testParseBridgeEventLogsL1FinalizeBatchEventSignature(t *testing.T) {
 		assert.Equal(t, rollupEvents[0].status, commonTypes.RollupFinalized)
 	})
 }
+
+func testL1WatcherVerifyQueueIndexes(t *testing.T) {
+	watcher, db := setupL1Watcher(t)
+	defer database.CloseDB(db)
+
+	messages := func(queueIndexes ...uint64) []*orm.L1Message {
+		var l1Messages []*orm.L1Message
+		for _, queueIndex := range queueIndexes {
+			l1Messages = append(l1Messages, &orm.L1Message{QueueIndex: queueIndex})
+		}
+		return l1Messages
+	}
+	assert.Nil(t, checkQueueIndexes(0, messages(5, 6, 7)))
+	assert.Nil(t, checkQueueIndexes(5, messages(5, 6, 7)))
+	assert.Equal(t, &queueIndexAnomaly{kind: "gap", expected: 7, actual: 8}, checkQueueIndexes(5, messages(5, 6, 8)))
+	assert.Equal(t, &queueIndexAnomaly{kind: "duplicate", expected: 7, actual: 6}, checkQueueIndexes(5, messages(5, 6, 6)))
+	assert.Equal(t, &queueIndexAnomaly{kind: "duplicate", expected: 5, actual: 4}, checkQueueIndexes(5, messages(4, 5)))
+	assert.Equal(t, &queueIndexAnomaly{kind: "gap", expected: 5, actual: 6}, checkQueueIndexes(5, messages(6, 7)))
+
+	// the re-fetched logs carry their queue index as log index
+	var refetchedQueueIndexes []uint64
+	patchFilterLogs := gomonkey.ApplyMethodFunc(watcher.client, "FilterLogs", func(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+		var logs []types.Log
+		for _, queueIndex := range refetchedQueueIndexes {
+			logs = append(logs, types.Log{Topics: []common.Hash{bridgeAbi.L1QueueTransactionEventSignature}, Index: uint(queueIndex)})
+		}
+		return logs, nil
+	})
+	defer patchFilterLogs.Reset()
+	patchUnpackLog := gomonkey.ApplyFunc(utils.UnpackLog, func(c *abi.ABI, out interface{}, event string, log types.Log) error {
+		tmpOut := out.(*bridgeAbi.L1QueueTransactionEvent)
+		tmpOut.QueueIndex = uint64(log.Index)
+		tmpOut.Value = big.NewInt(0)
+		tmpOut.GasLimit = big.NewInt(0)
+		return nil
+	})
+	defer patchUnpackLog.Reset()
+
+	queueLength, err := watcher.l1MessageOrm.GetL1MessageQueueLength(context.Background())
+	assert.NoError(t, err)
+	query := ethereum.FilterQuery{FromBlock: big.NewInt(1), ToBlock: big.NewInt(10)}
+
+	// consistent queue indexes are ingested as fetched
+	l1Messages, _, err := watcher.verifyQueueIndexes(context.Background(), query, messages(queueLength, queueLength+1), nil)
+	assert.NoError(t, err)
+	assert.Len(t, l1Messages, 2)
+
+	// a gap resolved by the re-fetch
+	refetchedQueueIndexes = []uint64{queueLength, queueLength + 1, queueLength + 2}
+	l1Messages, _, err = watcher.verifyQueueIndexes(context.Background(), query, messages(queueLength, queueLength+2), nil)
+	assert.NoError(t, err)
+	assert.Len(t, l1Messages, 3)
+	assert.Equal(t, queueLength+1, l1Messages[1].QueueIndex)
+
+	// a duplicate the re-fetch still returns quarantines the ingestion
+	refetchedQueueIndexes = []uint64{queueLength, queueLength}
+	_, _, err = watcher.verifyQueueIndexes(context.Background(), query, messages(queueLength, queueLength), nil)
+	assert.True(t, errors.Is(err, ErrL1MessageQueueIndexAnomaly))
+
+	// a re-fetch missing the fetched messages is not trusted
+	refetchedQueueIndexes = []uint64{queueLength}
+	_, _, err = watcher.verifyQueueIndexes(context.Background(), query, messages(queueLength, queueLength+2), nil)
+	assert.True(t, errors.Is(err, ErrL1MessageQueueIndexAnomaly))
+}
@@ -16,15 +16,21 @@ import (
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"gorm.io/gorm"
 
+	"scroll-tech/common/observability"
 	"scroll-tech/common/types"
 
 	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
 	"scroll-tech/rollup/internal/orm"
 )
 
+// L1HeaderReader is the subset of the layer 1 client used to read the latest layer 1 time.
+type L1HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*gethTypes.Header, error)
+}
+
 // L2WatcherClient provide APIs which support others to subscribe to various event from l2geth
 type L2WatcherClient struct {
-	ctx context.Context
 	event.Feed
 
 	*ethclient.Client
@@ -37,13 +43,15 @@ type L2WatcherClient struct {
 	messageQueueABI      *abi.ABI
 	withdrawTrieRootSlot common.Hash
 
+	timestampCfg   *config.BlockTimestampConfig
+	l1HeaderReader L1HeaderReader
+
 	metrics *l2WatcherMetrics
 }
 
 // NewL2WatcherClient take a l2geth instance to generate a l2watcherclient instance
-func NewL2WatcherClient(ctx context.Context, client *ethclient.Client, confirmations rpc.BlockNumber, messageQueueAddress common.Address, withdrawTrieRootSlot common.Hash, db *gorm.DB, reg prometheus.Registerer) *L2WatcherClient {
+func NewL2WatcherClient(client *ethclient.Client, confirmations rpc.BlockNumber, messageQueueAddress common.Address, withdrawTrieRootSlot common.Hash, db *gorm.DB, reg prometheus.Registerer) *L2WatcherClient {
 	return &L2WatcherClient{
-		ctx:    ctx,
 		Client: client,
 
 		l2BlockOrm: orm.NewL2Block(db),
@@ -58,12 +66,36 @@ func NewL2WatcherClient(ctx context.Context, client *ethclient.Client, confirmat
 	}
 }
 
+// DebugState returns the state of the l2 watcher for the debug server, the height is the one stored.
+func (w *L2WatcherClient) DebugState() interface{} {
+	ctx, cancel := context.WithTimeout(context.Background(), observability.StateDumpTimeout)
+	defer cancel()
+
+	state := map[string]interface{}{
+		"confirmations":   w.confirmations.Int64(),
+		"timestamp_check": w.timestampCfg != nil,
+	}
+	if height, err := w.l2BlockOrm.GetL2BlocksLatestHeight(ctx); err != nil {
+		state["latest_block_height_error"] = err.Error()
+	} else {
+		state["latest_block_height"] = height
+	}
+	return state
+}
+
+// SetBlockTimestampCheck enables checking the timestamps of the fetched blocks against their
+// parents and the latest layer 1 block before storing them.
+func (w *L2WatcherClient) SetBlockTimestampCheck(cfg *config.BlockTimestampConfig, l1HeaderReader L1HeaderReader) {
+	w.timestampCfg = cfg
+	w.l1HeaderReader = l1HeaderReader
+}
+
 const blockTracesFetchLimit = uint64(10)
 
 // TryFetchRunningMissingBlocks attempts to fetch and store block traces for any missing blocks.
-func (w *L2WatcherClient) TryFetchRunningMissingBlocks(blockHeight uint64) {
+func (w *L2WatcherClient) TryFetchRunningMissingBlocks(ctx context.Context, blockHeight uint64) {
 	w.metrics.fetchRunningMissingBlocksTotal.Inc()
-	heightInDB, err := w.l2BlockOrm.GetL2BlocksLatestHeight(w.ctx)
+	heightInDB, err := w.l2BlockOrm.GetL2BlocksLatestHeight(ctx)
 	if err != nil {
 		log.Error("failed to GetL2BlocksLatestHeight", "err", err)
 		return
@@ -77,7 +109,7 @@ func (w *L2WatcherClient) TryFetchRunningMissingBlocks(blockHeight uint64) {
 			to = blockHeight
 		}
 
-		if err = w.getAndStoreBlockTraces(w.ctx, from, to); err != nil {
+		if err = w.getAndStoreBlockTraces(ctx, from, to); err != nil {
 			log.Error("fail to getAndStoreBlockTraces", "from", from, "to", to, "err", err)
 			return
 		}
@@ -86,9 +118,15 @@ func (w *L2WatcherClient) TryFetchRunningMissingBlocks(blockHeight uint64) {
 	}
 }
 
-func txsToTxsData(txs gethTypes.Transactions) []*gethTypes.TransactionData {
+// txsToTxsData converts the transactions to the trace ones, along with the typed fields the trace lacks by tx hash.
+func txsToTxsData(txs gethTypes.Transactions) ([]*gethTypes.TransactionData, map[common.Hash]*types.TypedTxFields) {
 	txsData := make([]*gethTypes.TransactionData, len(txs))
+	typedTxFields := make(map[common.Hash]*types.TypedTxFields)
 	for i, tx := range txs {
+		if fields := types.NewTypedTxFields(tx); fields != nil {
+			typedTxFields[tx.Hash()] = fields
+		}
+
 		v, r, s := tx.RawSignatureValues()
 
 		nonce := tx.Nonce()
@@ -116,43 +154,126 @@ func txsToTxsData(txs gethTypes.Transactions) []*gethTypes.TransactionData {
 			S:        (*hexutil.Big)(s),
 		}
 	}
-	return txsData
+	return txsData, typedTxFields
+}
+
+// FetchWrappedBlock fetches the block of the given number from the layer 2 node, along with its row
+// consumption and the withdraw trie root stored by the L2MessageQueue contract at the block.
+func FetchWrappedBlock(ctx context.Context, client *ethclient.Client, number uint64, messageQueueAddress common.Address, withdrawTrieRootSlot common.Hash) (*types.WrappedBlock, error) {
+	block, err := client.GetBlockByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetBlockByNumberOrHash: %v. number: %v", err, number)
+	}
+	if block.RowConsumption == nil {
+		return nil, fmt.Errorf("fetched block does not contain RowConsumption. number: %v", number)
+	}
+
+	log.Info("retrieved block", "height", block.Header().Number, "hash", block.Header().Hash().String())
+
+	withdrawRoot, err := client.StorageAt(ctx, messageQueueAddress, withdrawTrieRootSlot, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawRoot: %v. number: %v", err, number)
+	}
+	txsData, typedTxFields := txsToTxsData(block.Transactions())
+	return &types.WrappedBlock{
+		Header:         block.Header(),
+		Transactions:   txsData,
+		TypedTxFields:  typedTxFields,
+		WithdrawRoot:   common.BytesToHash(withdrawRoot),
+		RowConsumption: block.RowConsumption,
+	}, nil
 }
 
 func (w *L2WatcherClient) getAndStoreBlockTraces(ctx context.Context, from, to uint64) error {
 	var blocks []*types.WrappedBlock
 	for number := from; number <= to; number++ {
 		log.Debug("retrieving block", "height", number)
-		block, err := w.GetBlockByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)))
+		block, err := FetchWrappedBlock(ctx, w.Client, number, w.messageQueueAddress, w.withdrawTrieRootSlot)
 		if err != nil {
-			return fmt.Errorf("failed to GetBlockByNumberOrHash: %v. number: %v", err, number)
+			return err
 		}
-		if block.RowConsumption == nil {
-			return fmt.Errorf("fetched block does not contain RowConsumption. number: %v", number)
-		}
-
-		log.Info("retrieved block", "height", block.Header().Number, "hash", block.Header().Hash().String())
+		blocks = append(blocks, block)
+	}
 
-		withdrawRoot, err3 := w.StorageAt(ctx, w.messageQueueAddress, w.withdrawTrieRootSlot, big.NewInt(int64(number)))
-		if err3 != nil {
-			return fmt.Errorf("failed to get withdrawRoot: %v. number: %v", err3, number)
+	if len(blocks) > 0 && w.timestampCfg != nil {
+		if err := w.checkBlockTimestamps(ctx, blocks); err != nil {
+			return err
 		}
-		blocks = append(blocks, &types.WrappedBlock{
-			Header:         block.Header(),
-			Transactions:   txsToTxsData(block.Transactions()),
-			WithdrawRoot:   common.BytesToHash(withdrawRoot),
-			RowConsumption: block.RowConsumption,
-		})
 	}
 
 	if len(blocks) > 0 {
 		for _, block := range blocks {
-			w.metrics.rollupL2BlockL1CommitCalldataSize.Set(float64(block.EstimateL1CommitCalldataSize()))
+			blockL1CommitCalldataSize, err := block.EstimateL1CommitCalldataSize()
+			if err != nil {
+				return fmt.Errorf("failed to estimate l1 commit calldata size of block %v: %w", block.Header.Number, err)
+			}
+			w.metrics.rollupL2BlockL1CommitCalldataSize.Set(float64(blockL1CommitCalldataSize))
 		}
-		if err := w.l2BlockOrm.InsertL2Blocks(w.ctx, blocks); err != nil {
+		if err := w.l2BlockOrm.InsertL2Blocks(ctx, blocks); err != nil {
 			return fmt.Errorf("failed to batch insert BlockTraces: %v", err)
 		}
 	}
 
 	return nil
 }
+
+const (
+	timestampAnomalyNonMonotonic = "non_monotonic"
+	timestampAnomalyAheadOfL1    = "ahead_of_l1"
+)
+
+type timestampAnomaly struct {
+	number    uint64
+	timestamp uint64
+	kind      string
+}
+
+// checkBlockTimestamps flags the blocks whose timestamp is before their parent's or too far ahead
+// of the latest layer 1 block, and rejects them if configured to, since such blocks end up in
+// chunk encodings the rollup contract would not accept.
+func (w *L2WatcherClient) checkBlockTimestamps(ctx context.Context, blocks []*types.WrappedBlock) error {
+	var parentTimestamp uint64
+	if parentNumber := blocks[0].Header.Number.Uint64() - 1; parentNumber > 0 {
+		parents, err := w.l2BlockOrm.GetL2Blocks(ctx, map[string]interface{}{"number = ?": parentNumber}, nil, 1)
+		if err != nil {
+			return fmt.Errorf("failed to get parent block: %w, number: %v", err, parentNumber)
+		}
+		if len(parents) > 0 {
+			parentTimestamp = parents[0].BlockTimestamp
+		}
+	}
+
+	l1Header, err := w.l1HeaderReader.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest l1 header: %w", err)
+	}
+
+	anomalies := findTimestampAnomalies(blocks, parentTimestamp, l1Header.Time, w.timestampCfg.MaxL1DriftSec)
+	for _, anomaly := range anomalies {
+		w.metrics.rollupL2BlockTimestampAnomalyTotal.WithLabelValues(anomaly.kind).Inc()
+		log.Warn("anomalous l2 block timestamp", "number", anomaly.number, "timestamp", anomaly.timestamp,
+			"kind", anomaly.kind, "parent timestamp", parentTimestamp, "l1 timestamp", l1Header.Time)
+	}
+	if len(anomalies) > 0 && w.timestampCfg.RejectAnomalies {
+		return fmt.Errorf("rejected block %v with %v timestamp %v", anomalies[0].number, anomalies[0].kind, anomalies[0].timestamp)
+	}
+	return nil
+}
+
+// findTimestampAnomalies checks that the timestamps never decrease, starting from the parent timestamp
+// (skipped if zero), and are at most maxL1Drift seconds ahead of l1Timestamp.
+func findTimestampAnomalies(blocks []*types.WrappedBlock, parentTimestamp, l1Timestamp, maxL1Drift uint64) []timestampAnomaly {
+	var anomalies []timestampAnomaly
+	prev := parentTimestamp
+	for _, block := range blocks {
+		number, timestamp := block.Header.Number.Uint64(), block.Header.Time
+		if timestamp < prev {
+			anomalies = append(anomalies, timestampAnomaly{number: number, timestamp: timestamp, kind: timestampAnomalyNonMonotonic})
+		}
+		if timestamp > l1Timestamp+maxL1Drift {
+			anomalies = append(anomalies, timestampAnomaly{number: number, timestamp: timestamp, kind: timestampAnomalyAheadOfL1})
+		}
+		prev = timestamp
+	}
+	return anomalies
+}
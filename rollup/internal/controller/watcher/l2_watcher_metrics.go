@@ -12,6 +12,8 @@ type l2WatcherMetrics struct {
 	fetchRunningMissingBlocksHeight   prometheus.Gauge
 	rollupL2BlocksFetchedGap          prometheus.Gauge
 	rollupL2BlockL1CommitCalldataSize prometheus.Gauge
+
+	rollupL2BlockTimestampAnomalyTotal *prometheus.CounterVec
 }
 
 var (
@@ -38,6 +40,10 @@ func initL2WatcherMetrics(reg prometheus.Registerer) *l2WatcherMetrics {
 				Name: "rollup_l2_block_l1_commit_calldata_size",
 				Help: "The l1 commitBatch calldata size of the l2 block",
 			}),
+			rollupL2BlockTimestampAnomalyTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+				Name: "rollup_l2_block_timestamp_anomaly_total",
+				Help: "The total number of fetched l2 blocks with an anomalous timestamp",
+			}, []string{"kind"}),
 		}
 	})
 	return l2WatcherMetric
@@ -10,11 +10,13 @@ import (
 
 	"github.com/scroll-tech/go-ethereum/accounts/abi/bind"
 	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
 	"github.com/scroll-tech/go-ethereum/ethclient"
 	"github.com/scroll-tech/go-ethereum/rpc"
 	"github.com/stretchr/testify/assert"
 
 	"scroll-tech/common/database"
+	"scroll-tech/common/types"
 	cutils "scroll-tech/common/utils"
 
 	"scroll-tech/rollup/internal/orm"
@@ -24,7 +26,7 @@ import (
 func setupL2Watcher(t *testing.T) (*L2WatcherClient, *gorm.DB) {
 	db := setupDB(t)
 	l2cfg := cfg.L2Config
-	watcher := NewL2WatcherClient(context.Background(), l2Cli, l2cfg.Confirmations, l2cfg.L2MessageQueueAddress, l2cfg.WithdrawTrieRootSlot, db, nil)
+	watcher := NewL2WatcherClient(l2Cli, l2cfg.Confirmations, l2cfg.L2MessageQueueAddress, l2cfg.WithdrawTrieRootSlot, db, nil)
 	return watcher, db
 }
 
@@ -47,7 +49,7 @@ func testFetchRunningMissingBlocks(t *testing.T) {
 			return false
 		}
 		wc := prepareWatcherClient(l2Cli, db, address)
-		wc.TryFetchRunningMissingBlocks(latestHeight)
+		wc.TryFetchRunningMissingBlocks(context.Background(), latestHeight)
 		fetchedHeight, err := l2BlockOrm.GetL2BlocksLatestHeight(context.Background())
 		return err == nil && fetchedHeight == latestHeight
 	})
@@ -56,7 +58,7 @@ func testFetchRunningMissingBlocks(t *testing.T) {
 
 func prepareWatcherClient(l2Cli *ethclient.Client, db *gorm.DB, contractAddr common.Address) *L2WatcherClient {
 	confirmations := rpc.LatestBlockNumber
-	return NewL2WatcherClient(context.Background(), l2Cli, confirmations, contractAddr, common.Hash{}, db, nil)
+	return NewL2WatcherClient(l2Cli, confirmations, contractAddr, common.Hash{}, db, nil)
 }
 
 func prepareAuth(t *testing.T, l2Cli *ethclient.Client, privateKey *ecdsa.PrivateKey) *bind.TransactOpts {
@@ -69,3 +71,22 @@ func prepareAuth(t *testing.T, l2Cli *ethclient.Client, privateKey *ecdsa.Privat
 	auth.GasLimit = 500000
 	return auth
 }
+
+func testFindBlockTimestampAnomalies(t *testing.T) {
+	newBlock := func(number, timestamp uint64) *types.WrappedBlock {
+		return &types.WrappedBlock{Header: &gethTypes.Header{Number: new(big.Int).SetUint64(number), Time: timestamp}}
+	}
+
+	anomalies := findTimestampAnomalies([]*types.WrappedBlock{newBlock(11, 100), newBlock(12, 103), newBlock(13, 103)}, 97, 100, 10)
+	assert.Len(t, anomalies, 0)
+
+	// the parent timestamp is skipped if unknown
+	anomalies = findTimestampAnomalies([]*types.WrappedBlock{newBlock(1, 100)}, 0, 100, 0)
+	assert.Len(t, anomalies, 0)
+
+	anomalies = findTimestampAnomalies([]*types.WrappedBlock{newBlock(11, 95), newBlock(12, 98), newBlock(13, 111)}, 97, 100, 10)
+	assert.Equal(t, []timestampAnomaly{
+		{number: 11, timestamp: 95, kind: timestampAnomalyNonMonotonic},
+		{number: 13, timestamp: 111, kind: timestampAnomalyAheadOfL1},
+	}, anomalies)
+}
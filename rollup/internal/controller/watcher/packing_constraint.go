@@ -0,0 +1,59 @@
+package watcher
+
+import (
+	"fmt"
+
+	"scroll-tech/common/types"
+)
+
+// PackingConstraint is a custom constraint on the blocks packed into a chunk, checked by the chunk proposer along
+// with the configured chunk limits, so operators add their own constraints without forking the proposer.
+type PackingConstraint interface {
+	// Check returns an error if the candidate block can't be appended to the chunk of the blocks packed so far,
+	// the chunk is then proposed without the candidate, which starts the next chunk. A candidate rejected by an
+	// empty chunk fails the proposal like a first block breaking the chunk limits.
+	Check(chunkSoFar *types.Chunk, candidate *types.WrappedBlock) error
+}
+
+// PackingConstraintFunc adapts a function to a PackingConstraint.
+type PackingConstraintFunc func(chunkSoFar *types.Chunk, candidate *types.WrappedBlock) error
+
+// Check calls f(chunkSoFar, candidate).
+func (f PackingConstraintFunc) Check(chunkSoFar *types.Chunk, candidate *types.WrappedBlock) error {
+	return f(chunkSoFar, candidate)
+}
+
+// MaxL2GasConstraint limits the sum of the gas used by the blocks of a chunk.
+func MaxL2GasConstraint(maxL2Gas uint64) PackingConstraint {
+	return PackingConstraintFunc(func(chunkSoFar *types.Chunk, candidate *types.WrappedBlock) error {
+		totalL2Gas := candidate.Header.GasUsed
+		for _, block := range chunkSoFar.Blocks {
+			totalL2Gas += block.Header.GasUsed
+		}
+		if totalL2Gas > maxL2Gas {
+			return fmt.Errorf("l2 gas used %v exceeds the limit %v", totalL2Gas, maxL2Gas)
+		}
+		return nil
+	})
+}
+
+type namedPackingConstraint struct {
+	name       string
+	constraint PackingConstraint
+}
+
+// AddPackingConstraint adds a packing constraint to the chunk proposer, the name identifies it in the logs,
+// the metrics and the exceeded limits of the chunk estimations. It is called before the proposer starts.
+func (p *ChunkProposer) AddPackingConstraint(name string, constraint PackingConstraint) {
+	p.packingConstraints = append(p.packingConstraints, namedPackingConstraint{name: name, constraint: constraint})
+}
+
+// checkPackingConstraints returns the name and the error of the first packing constraint rejecting the candidate.
+func (p *ChunkProposer) checkPackingConstraints(chunkSoFar *types.Chunk, candidate *types.WrappedBlock) (string, error) {
+	for _, c := range p.packingConstraints {
+		if err := c.constraint.Check(chunkSoFar, candidate); err != nil {
+			return c.name, err
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,238 @@
+package watcher
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// proofArchiveFormatVersion is the version of the proof archive layout.
+const proofArchiveFormatVersion = 1
+
+// ErrProofMissing is returned when a batch or chunk of the archived range has no verified proof.
+var ErrProofMissing = errors.New("proof is missing")
+
+// ProofArchiveManifest is the manifest.json of a proof archive, it lists the archived batches and the
+// sha256 checksums of the archive files, the SHA256SUMS file of the archive lists the same checksums.
+type ProofArchiveManifest struct {
+	FormatVersion int                  `json:"format_version"`
+	CreatedAt     time.Time            `json:"created_at"`
+	StartBatch    uint64               `json:"start_batch"`
+	EndBatch      uint64               `json:"end_batch"`
+	Batches       []common.Hash        `json:"batches"`
+	Files         []*ProofArchiveEntry `json:"files"`
+}
+
+// ProofArchiveEntry is a file of a proof archive.
+type ProofArchiveEntry struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BatchPublicInput is the public_input.json of an archived batch, the inputs finalizeBatchWithProof
+// verifies the batch proof against, and the public inputs of its chunks.
+type BatchPublicInput struct {
+	Index           uint64              `json:"index"`
+	Hash            common.Hash         `json:"hash"`
+	BatchHeader     string              `json:"batch_header"`
+	ParentBatchHash common.Hash         `json:"parent_batch_hash"`
+	PrevStateRoot   common.Hash         `json:"prev_state_root"`
+	PostStateRoot   common.Hash         `json:"post_state_root"`
+	WithdrawRoot    common.Hash         `json:"withdraw_root"`
+	Chunks          []*ChunkPublicInput `json:"chunks"`
+	FinalizeTxHash  string              `json:"finalize_tx_hash,omitempty"`
+	RollupStatus    types.RollupStatus  `json:"rollup_status"`
+	ProvingStatus   types.ProvingStatus `json:"proving_status"`
+	ProvedAt        *time.Time          `json:"proved_at,omitempty"`
+}
+
+// ChunkPublicInput is the public input of an archived chunk.
+type ChunkPublicInput struct {
+	Index            uint64      `json:"index"`
+	Hash             common.Hash `json:"hash"`
+	StartBlockNumber uint64      `json:"start_block_number"`
+	EndBlockNumber   uint64      `json:"end_block_number"`
+	PrevStateRoot    common.Hash `json:"prev_state_root"`
+	PostStateRoot    common.Hash `json:"post_state_root"`
+	WithdrawRoot     common.Hash `json:"withdraw_root"`
+}
+
+// ProofArchiver exports the proofs and the public inputs of a batch range as an archive, for the long
+// term archival of the proofs and the third party verification services.
+//
+// The archive is a gzip compressed tar of
+//
+//	batch_<index>/public_input.json
+//	batch_<index>/batch_proof.json
+//	batch_<index>/chunk_<index>_proof.json
+//	manifest.json
+//	SHA256SUMS
+//
+// the proofs are stored as they were submitted by the provers.
+type ProofArchiver struct {
+	ctx context.Context
+
+	batchOrm *orm.Batch
+	chunkOrm *orm.Chunk
+}
+
+// NewProofArchiver creates a new ProofArchiver instance.
+func NewProofArchiver(ctx context.Context, db *gorm.DB) *ProofArchiver {
+	return &ProofArchiver{
+		ctx:      ctx,
+		batchOrm: orm.NewBatch(db),
+		chunkOrm: orm.NewChunk(db),
+	}
+}
+
+// Archive writes the archive of the batches in [startIndex, endIndex] to w, every batch and chunk of the
+// range must have its verified proof.
+func (a *ProofArchiver) Archive(w io.Writer, startIndex, endIndex uint64) (*ProofArchiveManifest, error) {
+	if endIndex < startIndex {
+		return nil, fmt.Errorf("end batch %v is less than start batch %v", endIndex, startIndex)
+	}
+	fields := map[string]interface{}{
+		"index >= ?": startIndex,
+		"index <= ?": endIndex,
+	}
+	dbBatches, err := a.batchOrm.GetBatches(a.ctx, fields, []string{"index ASC"}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(dbBatches)) != endIndex-startIndex+1 {
+		return nil, fmt.Errorf("found %v batches in [%v, %v]", len(dbBatches), startIndex, endIndex)
+	}
+
+	var prevStateRoot common.Hash
+	if startIndex > 0 {
+		parent, parentErr := a.batchOrm.GetBatchByIndex(a.ctx, startIndex-1)
+		if parentErr != nil {
+			return nil, fmt.Errorf("failed to get parent batch %v: %w", startIndex-1, parentErr)
+		}
+		prevStateRoot = common.HexToHash(parent.StateRoot)
+	}
+
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	manifest := &ProofArchiveManifest{
+		FormatVersion: proofArchiveFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		StartBatch:    startIndex,
+		EndBatch:      endIndex,
+	}
+	addFile := func(path string, data []byte) error {
+		if err := writeArchiveFile(tw, path, data, manifest.CreatedAt); err != nil {
+			return fmt.Errorf("failed to write %v: %w", path, err)
+		}
+		checksum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, &ProofArchiveEntry{Path: path, Size: len(data), SHA256: hex.EncodeToString(checksum[:])})
+		return nil
+	}
+
+	for _, dbBatch := range dbBatches {
+		if types.ProvingStatus(dbBatch.ProvingStatus) != types.ProvingTaskVerified || len(dbBatch.Proof) == 0 {
+			return nil, fmt.Errorf("%w: batch %v", ErrProofMissing, dbBatch.Index)
+		}
+		dbChunks, chunksErr := a.chunkOrm.GetChunksInRange(a.ctx, dbBatch.StartChunkIndex, dbBatch.EndChunkIndex)
+		if chunksErr != nil {
+			return nil, fmt.Errorf("failed to get chunks of batch %v: %w", dbBatch.Index, chunksErr)
+		}
+
+		dir := fmt.Sprintf("batch_%d/", dbBatch.Index)
+		publicInput := &BatchPublicInput{
+			Index:           dbBatch.Index,
+			Hash:            common.HexToHash(dbBatch.Hash),
+			BatchHeader:     "0x" + common.Bytes2Hex(dbBatch.BatchHeader),
+			ParentBatchHash: common.HexToHash(dbBatch.ParentBatchHash),
+			PrevStateRoot:   prevStateRoot,
+			PostStateRoot:   common.HexToHash(dbBatch.StateRoot),
+			WithdrawRoot:    common.HexToHash(dbBatch.WithdrawRoot),
+			FinalizeTxHash:  dbBatch.FinalizeTxHash,
+			RollupStatus:    types.RollupStatus(dbBatch.RollupStatus),
+			ProvingStatus:   types.ProvingStatus(dbBatch.ProvingStatus),
+			ProvedAt:        dbBatch.ProvedAt,
+		}
+		for _, dbChunk := range dbChunks {
+			if types.ProvingStatus(dbChunk.ProvingStatus) != types.ProvingTaskVerified || len(dbChunk.Proof) == 0 {
+				return nil, fmt.Errorf("%w: chunk %v of batch %v", ErrProofMissing, dbChunk.Index, dbBatch.Index)
+			}
+			publicInput.Chunks = append(publicInput.Chunks, &ChunkPublicInput{
+				Index:            dbChunk.Index,
+				Hash:             common.HexToHash(dbChunk.Hash),
+				StartBlockNumber: dbChunk.StartBlockNumber,
+				EndBlockNumber:   dbChunk.EndBlockNumber,
+				PrevStateRoot:    common.HexToHash(dbChunk.ParentChunkStateRoot),
+				PostStateRoot:    common.HexToHash(dbChunk.StateRoot),
+				WithdrawRoot:     common.HexToHash(dbChunk.WithdrawRoot),
+			})
+			if err = addFile(fmt.Sprintf("%schunk_%d_proof.json", dir, dbChunk.Index), dbChunk.Proof); err != nil {
+				return nil, err
+			}
+		}
+
+		publicInputBytes, marshalErr := json.MarshalIndent(publicInput, "", "  ")
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		if err = addFile(dir+"public_input.json", publicInputBytes); err != nil {
+			return nil, err
+		}
+		if err = addFile(dir+"batch_proof.json", dbBatch.Proof); err != nil {
+			return nil, err
+		}
+		manifest.Batches = append(manifest.Batches, publicInput.Hash)
+		prevStateRoot = publicInput.PostStateRoot
+	}
+
+	var checksums strings.Builder
+	for _, entry := range manifest.Files {
+		fmt.Fprintf(&checksums, "%s  %s\n", entry.SHA256, entry.Path)
+	}
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = writeArchiveFile(tw, "manifest.json", manifestBytes, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err = writeArchiveFile(tw, "SHA256SUMS", []byte(checksums.String()), manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeArchiveFile(tw *tar.Writer, path string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    path,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
@@ -0,0 +1,119 @@
+package watcher
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/orm"
+)
+
+func testProofArchiver(t *testing.T) {
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	l2BlockOrm := orm.NewL2Block(db)
+	err := l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{wrappedBlock1, wrappedBlock2})
+	assert.NoError(t, err)
+
+	cp := NewChunkProposer(context.Background(), &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             1,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1000000,
+		ChunkTimeoutSec:                 300,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background()) // chunk1 contains block1
+	cp.TryProposeChunk(context.Background()) // chunk2 contains block2
+
+	bp := NewBatchProposer(&config.BatchProposerConfig{
+		MaxChunkNumPerBatch:             10,
+		MaxL1CommitGasPerBatch:          50000000000,
+		MaxL1CommitCalldataSizePerBatch: 1000000,
+		BatchTimeoutSec:                 0,
+		GasCostIncreaseMultiplier:       1.2,
+	}, nil, db, nil)
+	bp.TryProposeBatch(context.Background())
+
+	batchOrm := orm.NewBatch(db)
+	batches, err := batchOrm.GetBatches(context.Background(), map[string]interface{}{}, []string{"index ASC"}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	batch := batches[0]
+
+	archiver := NewProofArchiver(context.Background(), db)
+	_, err = archiver.Archive(io.Discard, 0, 0)
+	assert.True(t, errors.Is(err, ErrProofMissing))
+	_, err = archiver.Archive(io.Discard, 0, 1)
+	assert.Error(t, err)
+
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksInRange(context.Background(), batch.StartChunkIndex, batch.EndChunkIndex)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	for _, chunk := range chunks {
+		err = db.Model(&orm.Chunk{}).Where("hash", chunk.Hash).Update("proof", []byte(`{"chunk":"`+chunk.Hash+`"}`)).Error
+		assert.NoError(t, err)
+		assert.NoError(t, chunkOrm.UpdateProvingStatus(context.Background(), chunk.Hash, types.ProvingTaskVerified))
+	}
+	// the chunks are verified, the batch is not
+	_, err = archiver.Archive(io.Discard, 0, 0)
+	assert.True(t, errors.Is(err, ErrProofMissing))
+
+	err = batchOrm.UpdateProofByHash(context.Background(), batch.Hash, &message.BatchProof{Proof: []byte{1, 2, 3}}, 100)
+	assert.NoError(t, err)
+	assert.NoError(t, batchOrm.UpdateProvingStatus(context.Background(), batch.Hash, types.ProvingTaskVerified))
+
+	var buf bytes.Buffer
+	manifest, err := archiver.Archive(&buf, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Hash{common.HexToHash(batch.Hash)}, manifest.Batches)
+	assert.Len(t, manifest.Files, 4)
+
+	zr, err := gzip.NewReader(&buf)
+	assert.NoError(t, err)
+	tr := tar.NewReader(zr)
+	files := make(map[string][]byte)
+	for {
+		header, readErr := tr.Next()
+		if readErr == io.EOF {
+			break
+		}
+		assert.NoError(t, readErr)
+		data, readErr := io.ReadAll(tr)
+		assert.NoError(t, readErr)
+		files[header.Name] = data
+	}
+	assert.Len(t, files, 6)
+
+	for _, entry := range manifest.Files {
+		checksum := sha256.Sum256(files[entry.Path])
+		assert.Equal(t, hex.EncodeToString(checksum[:]), entry.SHA256)
+		assert.Contains(t, string(files["SHA256SUMS"]), entry.SHA256+"  "+entry.Path)
+	}
+	assert.Len(t, strings.Split(strings.TrimSpace(string(files["SHA256SUMS"])), "\n"), 4)
+
+	var publicInput BatchPublicInput
+	assert.NoError(t, json.Unmarshal(files["batch_0/public_input.json"], &publicInput))
+	assert.Equal(t, common.Hash{}, publicInput.PrevStateRoot)
+	assert.Equal(t, common.HexToHash(batch.StateRoot), publicInput.PostStateRoot)
+	assert.Len(t, publicInput.Chunks, 2)
+}
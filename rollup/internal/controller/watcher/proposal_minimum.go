@@ -0,0 +1,29 @@
+package watcher
+
+import "fmt"
+
+// ProposalMinimum is the min number of entries of a chunk or batch proposed at its timeout,
+// a timed out proposal below it waits for more entries until TimeoutSec. Num 0 disables it.
+type ProposalMinimum struct {
+	Num uint64 `json:"num"`
+	// TimeoutSec is the age of the first entry after which a proposal below Num is made anyway.
+	TimeoutSec uint64 `json:"timeout_sec"`
+}
+
+func (m ProposalMinimum) validate(maxNum, timeoutSec uint64) error {
+	if m.Num == 0 {
+		return nil
+	}
+	if m.Num > maxNum {
+		return fmt.Errorf("min number %v is above the max number %v", m.Num, maxNum)
+	}
+	if m.TimeoutSec < timeoutSec {
+		return fmt.Errorf("min number timeout %v is below the timeout %v", m.TimeoutSec, timeoutSec)
+	}
+	return nil
+}
+
+// waits reports whether a timed out proposal of num entries, whose first entry is at startTime, waits for more entries.
+func (m ProposalMinimum) waits(num, startTime, currentTime uint64) bool {
+	return num < m.Num && startTime+m.TimeoutSec >= currentTime
+}
@@ -108,14 +108,37 @@ func TestFunction(t *testing.T) {
 	t.Run("TestParseBridgeEventLogsL1QueueTransactionEventSignature", testParseBridgeEventLogsL1QueueTransactionEventSignature)
 	t.Run("TestParseBridgeEventLogsL1CommitBatchEventSignature", testParseBridgeEventLogsL1CommitBatchEventSignature)
 	t.Run("TestParseBridgeEventLogsL1FinalizeBatchEventSignature", testParseBridgeEventLogsL1FinalizeBatchEventSignature)
+	t.Run("TestL1WatcherVerifyQueueIndexes", testL1WatcherVerifyQueueIndexes)
 
 	// Run l2 watcher test cases.
 	t.Run("TestFetchRunningMissingBlocks", testFetchRunningMissingBlocks)
+	t.Run("TestFindBlockTimestampAnomalies", testFindBlockTimestampAnomalies)
 
 	// Run chunk proposer test cases.
 	t.Run("TestChunkProposerLimits", testChunkProposerLimits)
+	t.Run("TestChunkProposerPreview", testChunkProposerPreview)
+	t.Run("TestChunkProposerWhatIf", testChunkProposerWhatIf)
+	t.Run("TestChunkProposerMinBlockNum", testChunkProposerMinBlockNum)
+	t.Run("TestChunkProposerEmptyBlocks", testChunkProposerEmptyBlocks)
+	t.Run("TestChunkProposerBaseFeeFork", testChunkProposerBaseFeeFork)
+	t.Run("TestChunkProposerPackingConstraint", testChunkProposerPackingConstraint)
+	t.Run("TestChunkProposerMaxL2Gas", testChunkProposerMaxL2Gas)
+	t.Run("TestChunkProposerCompressedSizeCache", testChunkProposerCompressedSizeCache)
+	t.Run("TestChunkProposerReproposeChunks", testChunkProposerReproposeChunks)
+	t.Run("TestChunkProposerRowConsumptionMissing", testChunkProposerRowConsumptionMissing)
+	t.Run("TestChunkProposerBlockFailure", testChunkProposerBlockFailure)
+	t.Run("TestChunkProposerProverFailureQuarantine", testChunkProposerProverFailureQuarantine)
 
 	// Run chunk proposer test cases.
 	t.Run("TestBatchProposerLimits", testBatchProposerLimits)
+	t.Run("TestBatchProposerMaxL1MessagePopped", testBatchProposerMaxL1MessagePopped)
+	t.Run("TestBatchProposerCodecVersion", testBatchProposerCodecVersion)
 	t.Run("TestBatchCommitGasAndCalldataSizeEstimation", testBatchCommitGasAndCalldataSizeEstimation)
+	t.Run("TestBatchAuditorAuditBatch", testBatchAuditorAuditBatch)
+	t.Run("TestBatchReencoder", testBatchReencoder)
+	t.Run("TestProofArchiver", testProofArchiver)
+	t.Run("TestBatchReporter", testBatchReporter)
+	t.Run("TestBlockTagReporter", testBlockTagReporter)
+	t.Run("TestCommitmentChecker", testCommitmentChecker)
+	t.Run("TestL1MessageQueueMonitor", testL1MessageQueueMonitor)
 }
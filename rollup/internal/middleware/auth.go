@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"scroll-tech/common/types"
+)
+
+// BearerAuthMiddleware checks the bearer token of the request against the given secret
+func BearerAuthMiddleware(secret string) gin.HandlerFunc {
+	expected := []byte("Bearer " + secret)
+	return func(c *gin.Context) {
+		token := []byte(c.GetHeader("Authorization"))
+		if subtle.ConstantTimeCompare(token, expected) != 1 {
+			types.RenderFailure(c, types.ErrRollupAPIUnauthorized, errors.New("rollup api unauthorized"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,194 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APIKey is a self-service key of the public api, only the sha256 hash of the key is stored.
+type APIKey struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID       uint64 `json:"id" gorm:"column:id;primaryKey"`
+	KeyHash  string `json:"key_hash" gorm:"column:key_hash"`
+	Owner    string `json:"owner" gorm:"column:owner"`
+	Tier     string `json:"tier" gorm:"column:tier"`
+	IssuedIP string `json:"issued_ip" gorm:"column:issued_ip"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewAPIKey creates a new APIKey database instance.
+func NewAPIKey(db *gorm.DB) *APIKey {
+	return &APIKey{db: db}
+}
+
+// TableName returns the table name for the APIKey model.
+func (*APIKey) TableName() string {
+	return "api_key"
+}
+
+// GetAPIKeyByHash returns the api key of the given key hash, nil if the key is unknown.
+func (o *APIKey) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&APIKey{})
+	db = db.Where("key_hash = ?", keyHash)
+
+	var apiKey APIKey
+	if err := db.First(&apiKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("APIKey.GetAPIKeyByHash error: %w", err)
+	}
+	return &apiKey, nil
+}
+
+// CountAPIKeysIssuedSince returns the number of keys issued to the given ip since the given time.
+func (o *APIKey) CountAPIKeysIssuedSince(ctx context.Context, issuedIP string, since time.Time, dbTX ...*gorm.DB) (uint64, error) {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&APIKey{})
+	db = db.Where("issued_ip = ?", issuedIP)
+	db = db.Where("created_at >= ?", since)
+
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("APIKey.CountAPIKeysIssuedSince error: %w, issued ip: %v", err, issuedIP)
+	}
+	return uint64(count), nil
+}
+
+// InsertAPIKey inserts a new api key and returns it with its assigned id.
+func (o *APIKey) InsertAPIKey(ctx context.Context, keyHash, owner, tier, issuedIP string, dbTX ...*gorm.DB) (*APIKey, error) {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&APIKey{})
+
+	apiKey := APIKey{
+		KeyHash:  keyHash,
+		Owner:    owner,
+		Tier:     tier,
+		IssuedIP: issuedIP,
+	}
+	if err := db.Create(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("APIKey.InsertAPIKey error: %w, owner: %v", err, owner)
+	}
+	return &apiKey, nil
+}
+
+// InsertAPIKeyWithinLimit inserts a new api key unless maxIssued keys have been issued to the ip since the given time,
+// it returns nil if the limit is reached. The count and the insert run in a transaction holding an advisory lock
+// on the ip, so concurrent requests from the same ip cannot all pass the count.
+func (o *APIKey) InsertAPIKeyWithinLimit(ctx context.Context, keyHash, owner, tier, issuedIP string, since time.Time, maxIssued uint64) (*APIKey, error) {
+	var apiKey *APIKey
+	err := o.db.WithContext(ctx).Transaction(func(dbTX *gorm.DB) error {
+		if err := dbTX.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "api_key_issued_ip:"+issuedIP).Error; err != nil {
+			return err
+		}
+
+		issued, err := o.CountAPIKeysIssuedSince(ctx, issuedIP, since, dbTX)
+		if err != nil {
+			return err
+		}
+		if issued >= maxIssued {
+			return nil
+		}
+
+		apiKey, err = o.InsertAPIKey(ctx, keyHash, owner, tier, issuedIP, dbTX)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("APIKey.InsertAPIKeyWithinLimit error: %w, issued ip: %v", err, issuedIP)
+	}
+	return apiKey, nil
+}
+
+// UpdateAPIKeyTier moves an api key to another quota tier, it returns false if the key is unknown.
+func (o *APIKey) UpdateAPIKeyTier(ctx context.Context, id uint64, tier string) (bool, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&APIKey{})
+	db = db.Where("id = ?", id)
+
+	result := db.Update("tier", tier)
+	if result.Error != nil {
+		return false, fmt.Errorf("APIKey.UpdateAPIKeyTier error: %w, id: %v, tier: %v", result.Error, id, tier)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// APIKeyUsage is the number of requests made with an api key on a day.
+type APIKeyUsage struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	APIKeyID     uint64    `json:"api_key_id" gorm:"column:api_key_id;primaryKey"`
+	Day          time.Time `json:"day" gorm:"column:day;primaryKey"`
+	RequestCount uint64    `json:"request_count" gorm:"column:request_count"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewAPIKeyUsage creates a new APIKeyUsage database instance.
+func NewAPIKeyUsage(db *gorm.DB) *APIKeyUsage {
+	return &APIKeyUsage{db: db}
+}
+
+// TableName returns the table name for the APIKeyUsage model.
+func (*APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
+
+// IncreaseUsage counts a request made with the api key on the given day and returns the request count of the day.
+func (o *APIKeyUsage) IncreaseUsage(ctx context.Context, apiKeyID uint64, day time.Time) (uint64, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&APIKeyUsage{})
+	db = db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "api_key_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"request_count": gorm.Expr("api_key_usage.request_count + excluded.request_count"),
+			"updated_at":    gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	}, clause.Returning{Columns: []clause.Column{{Name: "request_count"}}})
+
+	usage := APIKeyUsage{
+		APIKeyID:     apiKeyID,
+		Day:          day,
+		RequestCount: 1,
+	}
+	if err := db.Create(&usage).Error; err != nil {
+		return 0, fmt.Errorf("APIKeyUsage.IncreaseUsage error: %w, api key id: %v", err, apiKeyID)
+	}
+	return usage.RequestCount, nil
+}
+
+// GetUsages returns the daily usages of the api key since the given day, ordered by day.
+func (o *APIKeyUsage) GetUsages(ctx context.Context, apiKeyID uint64, since time.Time) ([]*APIKeyUsage, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&APIKeyUsage{})
+	db = db.Where("api_key_id = ?", apiKeyID)
+	db = db.Where("day >= ?", since)
+	db = db.Order("day ASC")
+
+	var usages []*APIKeyUsage
+	if err := db.Find(&usages).Error; err != nil {
+		return nil, fmt.Errorf("APIKeyUsage.GetUsages error: %w, api key id: %v", err, apiKeyID)
+	}
+	return usages, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
 	"scroll-tech/common/types/message"
 	"scroll-tech/common/utils"
 
@@ -226,6 +227,23 @@ func (o *Batch) GetBatchByIndex(ctx context.Context, index uint64) (*Batch, erro
 	return &batch, nil
 }
 
+// GetBatchTimelines retrieves the lifecycle timestamps of the batches proposed in [start, end).
+// Only the index, hash, created_at, committed_at, proved_at and finalized_at fields are set.
+// The returned batches are sorted in ascending order by their index.
+func (o *Batch) GetBatchTimelines(ctx context.Context, start, end time.Time) ([]*Batch, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Select("index, hash, created_at, committed_at, proved_at, finalized_at")
+	db = db.Where("created_at >= ? AND created_at < ?", start, end)
+	db = db.Order("index ASC")
+
+	var batches []*Batch
+	if err := db.Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("Batch.GetBatchTimelines error: %w, start: %v, end: %v", err, start, end)
+	}
+	return batches, nil
+}
+
 // InsertBatch inserts a new batch into the database.
 func (o *Batch) InsertBatch(ctx context.Context, chunks []*types.Chunk, batchMeta *types.BatchMeta, dbTX ...*gorm.DB) (*Batch, error) {
 	if len(chunks) == 0 {
@@ -250,8 +268,8 @@ func (o *Batch) InsertBatch(ctx context.Context, chunks []*types.Chunk, batchMet
 		batchIndex = parentBatch.Index + 1
 		parentBatchHash = common.HexToHash(parentBatch.Hash)
 
-		var parentBatchHeader *types.BatchHeader
-		parentBatchHeader, err = types.DecodeBatchHeader(parentBatch.BatchHeader)
+		var parentBatchHeader codec.BatchHeader
+		parentBatchHeader, err = codec.DecodeBatchHeader(parentBatch.BatchHeader)
 		if err != nil {
 			log.Error("failed to decode parent batch header", "index", parentBatch.Index, "hash", parentBatch.Hash, "err", err)
 			return nil, err
@@ -260,8 +278,16 @@ func (o *Batch) InsertBatch(ctx context.Context, chunks []*types.Chunk, batchMet
 		totalL1MessagePoppedBefore = parentBatchHeader.TotalL1MessagePopped()
 		version = parentBatchHeader.Version()
 	}
+	if batchMeta.CodecVersion > version {
+		version = batchMeta.CodecVersion
+	}
 
-	batchHeader, err := types.NewBatchHeader(version, batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
+	batchCodec, err := codec.New(codec.Version(version))
+	if err != nil {
+		log.Error("failed to get batch codec", "index", batchIndex, "version", version, "err", err)
+		return nil, err
+	}
+	batchHeader, err := batchCodec.NewBatchHeader(batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
 	if err != nil {
 		log.Error("failed to create batch header",
 			"index", batchIndex, "total l1 message popped before", totalL1MessagePoppedBefore,
@@ -295,7 +321,7 @@ func (o *Batch) InsertBatch(ctx context.Context, chunks []*types.Chunk, batchMet
 	if len(dbTX) > 0 && dbTX[0] != nil {
 		db = dbTX[0]
 	}
-	db.WithContext(ctx)
+	db = db.WithContext(ctx)
 	db = db.Model(&Batch{})
 
 	if err := db.Create(&newBatch).Error; err != nil {
@@ -375,6 +401,30 @@ func (o *Batch) UpdateRollupStatus(ctx context.Context, hash string, status type
 	return nil
 }
 
+// UpdateRollupStatusFrom updates the rollup status of the batch only while it is in one of the from statuses,
+// it returns whether the batch is updated.
+func (o *Batch) UpdateRollupStatusFrom(ctx context.Context, hash string, from []types.RollupStatus, status types.RollupStatus) (bool, error) {
+	updateFields := make(map[string]interface{})
+	updateFields["rollup_status"] = int(status)
+	switch status {
+	case types.RollupCommitted:
+		updateFields["committed_at"] = utils.NowUTC()
+	case types.RollupFinalized:
+		updateFields["finalized_at"] = utils.NowUTC()
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Batch{})
+	db = db.Where("hash", hash)
+	db = db.Where("rollup_status IN ?", from)
+
+	result := db.Updates(updateFields)
+	if result.Error != nil {
+		return false, fmt.Errorf("Batch.UpdateRollupStatusFrom error: %w, batch hash: %v, status: %v", result.Error, hash, status.String())
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // UpdateCommitTxHashAndRollupStatus updates the commit transaction hash and rollup status for a batch.
 func (o *Batch) UpdateCommitTxHashAndRollupStatus(ctx context.Context, hash string, commitTxHash string, status types.RollupStatus) error {
 	updateFields := make(map[string]interface{})
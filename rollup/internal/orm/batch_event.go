@@ -0,0 +1,83 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"scroll-tech/common/types"
+)
+
+// BatchEvent is a commit or finalize event of a batch observed on layer 1, the history of the events
+// answers how the batches looked like at any past layer 1 block.
+type BatchEvent struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID            uint64 `json:"id" gorm:"column:id;primaryKey"`
+	BatchIndex    uint64 `json:"batch_index" gorm:"column:batch_index"`
+	BatchHash     string `json:"batch_hash" gorm:"column:batch_hash"`
+	RollupStatus  int16  `json:"rollup_status" gorm:"column:rollup_status"`
+	L1BlockNumber uint64 `json:"l1_block_number" gorm:"column:l1_block_number"`
+	L1TxHash      string `json:"l1_tx_hash" gorm:"column:l1_tx_hash"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewBatchEvent creates a new BatchEvent database instance.
+func NewBatchEvent(db *gorm.DB) *BatchEvent {
+	return &BatchEvent{db: db}
+}
+
+// TableName returns the table name for the BatchEvent model.
+func (*BatchEvent) TableName() string {
+	return "batch_event"
+}
+
+// InsertBatchEvents inserts the batch events, the events already stored are skipped
+// so the layer 1 blocks can be watched again after a restart.
+func (o *BatchEvent) InsertBatchEvents(ctx context.Context, events []*BatchEvent, dbTX ...*gorm.DB) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&BatchEvent{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "rollup_status"}, {Name: "batch_hash"}},
+		DoNothing: true,
+	})
+	if err := db.Create(&events).Error; err != nil {
+		return fmt.Errorf("BatchEvent.InsertBatchEvents error: %w, events count: %v", err, len(events))
+	}
+	return nil
+}
+
+// GetLatestBatchIndexAt returns the highest index of the batches which reached the rollup status
+// at or before the given layer 1 block, found is false if there is none.
+func (o *BatchEvent) GetLatestBatchIndexAt(ctx context.Context, status types.RollupStatus, l1BlockNumber uint64) (index uint64, found bool, err error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&BatchEvent{})
+	db = db.Select("MAX(batch_index)")
+	db = db.Where("rollup_status = ?", int16(status))
+	db = db.Where("l1_block_number <= ?", l1BlockNumber)
+
+	var maxIndex sql.NullInt64
+	if err := db.Scan(&maxIndex).Error; err != nil {
+		return 0, false, fmt.Errorf("BatchEvent.GetLatestBatchIndexAt error: %w, status: %v, l1 block number: %v", err, status, l1BlockNumber)
+	}
+	if !maxIndex.Valid {
+		return 0, false, nil
+	}
+	return uint64(maxIndex.Int64), true, nil
+}
@@ -134,6 +134,55 @@ func (o *Chunk) GetChunksGEIndex(ctx context.Context, index uint64, limit int) (
 	return chunks, nil
 }
 
+// GetUnbatchedChunks retrieves the chunks not yet included in a batch.
+// The returned chunks are sorted in ascending order by their index.
+func (o *Chunk) GetUnbatchedChunks(ctx context.Context) ([]*Chunk, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&Chunk{})
+	db = db.Where("batch_hash IS NULL")
+	db = db.Order("index ASC")
+
+	var chunks []*Chunk
+	if err := db.Find(&chunks).Error; err != nil {
+		return nil, fmt.Errorf("Chunk.GetUnbatchedChunks error: %w", err)
+	}
+	return chunks, nil
+}
+
+// DeleteUnbatchedChunksGEIndex soft deletes the chunks that have a chunk index greater than or equal to the given index.
+// It fails without deleting any chunk if one of them is already included in a batch, assigned to provers or proven,
+// only the unassigned and the failed chunks are deleted. It returns the number of deleted chunks.
+func (o *Chunk) DeleteUnbatchedChunksGEIndex(ctx context.Context, index uint64, dbTX ...*gorm.DB) (int64, error) {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+
+	var batchedChunks int64
+	if err := db.Model(&Chunk{}).Where("index >= ? AND batch_hash IS NOT NULL", index).Count(&batchedChunks).Error; err != nil {
+		return 0, fmt.Errorf("Chunk.DeleteUnbatchedChunksGEIndex error: %w, index: %v", err, index)
+	}
+	if batchedChunks > 0 {
+		return 0, fmt.Errorf("Chunk.DeleteUnbatchedChunksGEIndex: %v chunks from index %v are already batched", batchedChunks, index)
+	}
+
+	var provingChunks int64
+	deletableStatuses := []int{int(types.ProvingTaskUnassigned), int(types.ProvingTaskFailed)}
+	if err := db.Model(&Chunk{}).Where("index >= ? AND proving_status NOT IN ?", index, deletableStatuses).Count(&provingChunks).Error; err != nil {
+		return 0, fmt.Errorf("Chunk.DeleteUnbatchedChunksGEIndex error: %w, index: %v", err, index)
+	}
+	if provingChunks > 0 {
+		return 0, fmt.Errorf("Chunk.DeleteUnbatchedChunksGEIndex: %v chunks from index %v are already assigned to provers or proven", provingChunks, index)
+	}
+
+	result := db.Model(&Chunk{}).Where("index >= ?", index).Delete(&Chunk{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("Chunk.DeleteUnbatchedChunksGEIndex error: %w, index: %v", result.Error, index)
+	}
+	return result.RowsAffected, nil
+}
+
 // InsertChunk inserts a new chunk into the database.
 func (o *Chunk) InsertChunk(ctx context.Context, chunk *types.Chunk, dbTX ...*gorm.DB) (*Chunk, error) {
 	if chunk == nil || len(chunk.Blocks) == 0 {
@@ -172,7 +221,16 @@ func (o *Chunk) InsertChunk(ctx context.Context, chunk *types.Chunk, dbTX ...*go
 	for _, block := range chunk.Blocks {
 		totalL2TxGas += block.Header.GasUsed
 		totalL2TxNum += block.NumL2Transactions()
-		totalL1CommitCalldataSize += block.EstimateL1CommitCalldataSize()
+		blockL1CommitCalldataSize, err := block.EstimateL1CommitCalldataSize()
+		if err != nil {
+			return nil, fmt.Errorf("Chunk.InsertChunk error: %w", err)
+		}
+		totalL1CommitCalldataSize += blockL1CommitCalldataSize
+	}
+
+	totalL1CommitGas, err := chunk.EstimateL1CommitGas()
+	if err != nil {
+		return nil, fmt.Errorf("Chunk.InsertChunk error: %w", err)
 	}
 
 	numBlocks := len(chunk.Blocks)
@@ -186,7 +244,7 @@ func (o *Chunk) InsertChunk(ctx context.Context, chunk *types.Chunk, dbTX ...*go
 		TotalL2TxGas:                 totalL2TxGas,
 		TotalL2TxNum:                 uint32(totalL2TxNum),
 		TotalL1CommitCalldataSize:    uint32(totalL1CommitCalldataSize),
-		TotalL1CommitGas:             chunk.EstimateL1CommitGas(),
+		TotalL1CommitGas:             totalL1CommitGas,
 		StartBlockTime:               chunk.Blocks[0].Header.Time,
 		TotalL1MessagesPoppedBefore:  totalL1MessagePoppedBefore,
 		TotalL1MessagesPoppedInChunk: uint32(chunk.NumL1Messages(totalL1MessagePoppedBefore)),
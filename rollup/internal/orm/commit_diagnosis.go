@@ -0,0 +1,79 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CommitDiagnosis is the diagnosis of a reverted commit transaction, it records the decoded revert,
+// the parent batch hashes known locally and on layer 1 and the most likely cause of the failure.
+type CommitDiagnosis struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID                     uint64 `json:"id" gorm:"column:id;primaryKey"`
+	BatchIndex             uint64 `json:"batch_index" gorm:"column:batch_index"`
+	BatchHash              string `json:"batch_hash" gorm:"column:batch_hash"`
+	CommitTxHash           string `json:"commit_tx_hash" gorm:"column:commit_tx_hash"`
+	RevertReason           string `json:"revert_reason" gorm:"column:revert_reason"`
+	LocalParentBatchHash   string `json:"local_parent_batch_hash" gorm:"column:local_parent_batch_hash"`
+	OnchainParentBatchHash string `json:"onchain_parent_batch_hash" gorm:"column:onchain_parent_batch_hash"`
+	OnchainBatchHash       string `json:"onchain_batch_hash" gorm:"column:onchain_batch_hash"`
+	Cause                  string `json:"cause" gorm:"column:cause"`
+	Detail                 string `json:"detail" gorm:"column:detail"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewCommitDiagnosis creates a new CommitDiagnosis database instance.
+func NewCommitDiagnosis(db *gorm.DB) *CommitDiagnosis {
+	return &CommitDiagnosis{db: db}
+}
+
+// TableName returns the table name for the CommitDiagnosis model.
+func (*CommitDiagnosis) TableName() string {
+	return "commit_diagnosis"
+}
+
+// InsertCommitDiagnosis inserts the diagnosis of a commit transaction, a transaction already diagnosed is skipped.
+func (o *CommitDiagnosis) InsertCommitDiagnosis(ctx context.Context, diagnosis *CommitDiagnosis, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&CommitDiagnosis{})
+	db = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "commit_tx_hash"}},
+		DoNothing: true,
+	})
+	if err := db.Create(diagnosis).Error; err != nil {
+		return fmt.Errorf("CommitDiagnosis.InsertCommitDiagnosis error: %w, batch hash: %v, commit tx hash: %v", err, diagnosis.BatchHash, diagnosis.CommitTxHash)
+	}
+	return nil
+}
+
+// GetCommitDiagnoses returns the latest diagnoses first, only the ones of the batch if the batch hash is not empty.
+func (o *CommitDiagnosis) GetCommitDiagnoses(ctx context.Context, batchHash string, limit int) ([]*CommitDiagnosis, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&CommitDiagnosis{})
+	if batchHash != "" {
+		db = db.Where("batch_hash = ?", batchHash)
+	}
+	db = db.Order("id DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var diagnoses []*CommitDiagnosis
+	if err := db.Find(&diagnoses).Error; err != nil {
+		return nil, fmt.Errorf("CommitDiagnosis.GetCommitDiagnoses error: %w, batch hash: %v", err, batchHash)
+	}
+	return diagnoses, nil
+}
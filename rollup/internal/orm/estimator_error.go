@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EstimatorError accumulates the estimated and actual l1 commit cost of l2 transactions,
+// grouped by transaction type and target contract.
+type EstimatorError struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID               uint64 `json:"id" gorm:"column:id;primaryKey"`
+	TxType           uint8  `json:"tx_type" gorm:"column:tx_type"`
+	Target           string `json:"target" gorm:"column:target"`
+	SampleCount      uint64 `json:"sample_count" gorm:"column:sample_count"`
+	EstimatedSizeSum uint64 `json:"estimated_size_sum" gorm:"column:estimated_size_sum"`
+	ActualSizeSum    uint64 `json:"actual_size_sum" gorm:"column:actual_size_sum"`
+	EstimatedGasSum  uint64 `json:"estimated_gas_sum" gorm:"column:estimated_gas_sum"`
+	ActualGasSum     uint64 `json:"actual_gas_sum" gorm:"column:actual_gas_sum"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewEstimatorError creates a new EstimatorError database instance.
+func NewEstimatorError(db *gorm.DB) *EstimatorError {
+	return &EstimatorError{db: db}
+}
+
+// TableName returns the table name for the EstimatorError model.
+func (*EstimatorError) TableName() string {
+	return "estimator_error"
+}
+
+// Accumulate adds the given samples to the stored sums of their (tx_type, target) group.
+func (o *EstimatorError) Accumulate(ctx context.Context, samples []*EstimatorError, dbTX ...*gorm.DB) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&EstimatorError{})
+	db = db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tx_type"}, {Name: "target"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"sample_count":       gorm.Expr("estimator_error.sample_count + excluded.sample_count"),
+			"estimated_size_sum": gorm.Expr("estimator_error.estimated_size_sum + excluded.estimated_size_sum"),
+			"actual_size_sum":    gorm.Expr("estimator_error.actual_size_sum + excluded.actual_size_sum"),
+			"estimated_gas_sum":  gorm.Expr("estimator_error.estimated_gas_sum + excluded.estimated_gas_sum"),
+			"actual_gas_sum":     gorm.Expr("estimator_error.actual_gas_sum + excluded.actual_gas_sum"),
+			"updated_at":         gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	})
+	if err := db.Create(&samples).Error; err != nil {
+		return fmt.Errorf("EstimatorError.Accumulate error: %w, samples count: %v", err, len(samples))
+	}
+	return nil
+}
+
+// GetWorstEstimatorErrors returns the groups with the largest relative gas estimation error.
+func (o *EstimatorError) GetWorstEstimatorErrors(ctx context.Context, limit int) ([]*EstimatorError, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&EstimatorError{})
+	db = db.Order("ABS(estimated_gas_sum - actual_gas_sum)::DOUBLE PRECISION / GREATEST(actual_gas_sum, 1) DESC")
+	db = db.Order("sample_count DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var estimatorErrors []*EstimatorError
+	if err := db.Find(&estimatorErrors).Error; err != nil {
+		return nil, fmt.Errorf("EstimatorError.GetWorstEstimatorErrors error: %w", err)
+	}
+	return estimatorErrors, nil
+}
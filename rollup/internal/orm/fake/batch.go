@@ -0,0 +1,257 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/codec"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// BatchRepo is an in-memory orm.BatchRepo.
+type BatchRepo struct {
+	mu      sync.Mutex
+	batches []*orm.Batch
+}
+
+// NewBatchRepo creates an empty in-memory batch repo.
+func NewBatchRepo() *BatchRepo {
+	return &BatchRepo{}
+}
+
+// AddBatches stores the batches as is, they must be added in index order.
+func (r *BatchRepo) AddBatches(batches ...*orm.Batch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, batch := range batches {
+		copied := *batch
+		r.batches = append(r.batches, &copied)
+	}
+}
+
+// GetBatches returns the batches matching all the fields.
+func (r *BatchRepo) GetBatches(ctx context.Context, fields map[string]interface{}, orderByList []string, limit int) ([]*orm.Batch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var batches []*orm.Batch
+	for _, batch := range r.batches {
+		match, err := matchBatch(batch, fields)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			copied := *batch
+			batches = append(batches, &copied)
+		}
+	}
+
+	for _, orderBy := range orderByList {
+		switch orderBy {
+		case "index ASC", "index asc":
+		case "index DESC", "index desc":
+			sort.Slice(batches, func(i, j int) bool { return batches[i].Index > batches[j].Index })
+		default:
+			return nil, fmt.Errorf("fake.BatchRepo.GetBatches: unsupported order %v", orderBy)
+		}
+	}
+	if limit > 0 && len(batches) > limit {
+		batches = batches[:limit]
+	}
+	// the postgres implementation sorts the limited rows by index at last
+	sort.SliceStable(batches, func(i, j int) bool { return batches[i].Index < batches[j].Index })
+	return batches, nil
+}
+
+// GetBatchByIndex returns the batch with the given index.
+func (r *BatchRepo) GetBatchByIndex(ctx context.Context, index uint64) (*orm.Batch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, batch := range r.batches {
+		if batch.Index == index {
+			copied := *batch
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("fake.BatchRepo.GetBatchByIndex error: %w, index: %v", gorm.ErrRecordNotFound, index)
+}
+
+// GetLatestBatch returns the batch with the highest index, nil if there is no batch.
+func (r *BatchRepo) GetLatestBatch(ctx context.Context) (*orm.Batch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.batches) == 0 {
+		return nil, nil
+	}
+	copied := *r.batches[len(r.batches)-1]
+	return &copied, nil
+}
+
+// GetFirstUnbatchedChunkIndex returns the index of the chunk following the latest batch.
+func (r *BatchRepo) GetFirstUnbatchedChunkIndex(ctx context.Context) (uint64, error) {
+	latestBatch, err := r.GetLatestBatch(ctx)
+	if err != nil || latestBatch == nil {
+		return 0, err
+	}
+	return latestBatch.EndChunkIndex + 1, nil
+}
+
+// GetRollupStatusByHashList returns the rollup statuses of the batches in the order of the hashes.
+func (r *BatchRepo) GetRollupStatusByHashList(ctx context.Context, hashes []string) ([]types.RollupStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var statuses []types.RollupStatus
+	for _, hash := range hashes {
+		batch := r.findByHash(hash)
+		if batch == nil {
+			return nil, fmt.Errorf("fake.BatchRepo.GetRollupStatusByHashList: hash not found: %s", hash)
+		}
+		statuses = append(statuses, types.RollupStatus(batch.RollupStatus))
+	}
+	return statuses, nil
+}
+
+// InsertBatch builds the batch header the same way as the postgres implementation and stores the batch.
+func (r *BatchRepo) InsertBatch(ctx context.Context, chunks []*types.Chunk, batchMeta *types.BatchMeta, dbTX ...*gorm.DB) (*orm.Batch, error) {
+	if len(chunks) == 0 {
+		return nil, errors.New("invalid args")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var batchIndex uint64
+	var parentBatchHash common.Hash
+	var totalL1MessagePoppedBefore uint64
+	var version uint8
+	if len(r.batches) > 0 {
+		parentBatch := r.batches[len(r.batches)-1]
+		parentBatchHeader, err := codec.DecodeBatchHeader(parentBatch.BatchHeader)
+		if err != nil {
+			return nil, err
+		}
+		batchIndex = parentBatch.Index + 1
+		parentBatchHash = common.HexToHash(parentBatch.Hash)
+		totalL1MessagePoppedBefore = parentBatchHeader.TotalL1MessagePopped()
+		version = parentBatchHeader.Version()
+	}
+	if batchMeta.CodecVersion > version {
+		version = batchMeta.CodecVersion
+	}
+
+	batchCodec, err := codec.New(codec.Version(version))
+	if err != nil {
+		return nil, err
+	}
+	batchHeader, err := batchCodec.NewBatchHeader(batchIndex, totalL1MessagePoppedBefore, parentBatchHash, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	lastChunk := chunks[len(chunks)-1]
+	lastBlock := lastChunk.Blocks[len(lastChunk.Blocks)-1]
+	newBatch := orm.Batch{
+		Index:                     batchIndex,
+		Hash:                      batchHeader.Hash().Hex(),
+		StartChunkHash:            batchMeta.StartChunkHash,
+		StartChunkIndex:           batchMeta.StartChunkIndex,
+		EndChunkHash:              batchMeta.EndChunkHash,
+		EndChunkIndex:             batchMeta.EndChunkIndex,
+		StateRoot:                 lastBlock.Header.Root.Hex(),
+		WithdrawRoot:              lastBlock.WithdrawRoot.Hex(),
+		ParentBatchHash:           parentBatchHash.Hex(),
+		BatchHeader:               batchHeader.Encode(),
+		ChunkProofsStatus:         int16(types.ChunkProofsStatusPending),
+		ProvingStatus:             int16(types.ProvingTaskUnassigned),
+		RollupStatus:              int16(types.RollupPending),
+		OracleStatus:              int16(types.GasOraclePending),
+		TotalL1CommitGas:          batchMeta.TotalL1CommitGas,
+		TotalL1CommitCalldataSize: batchMeta.TotalL1CommitCalldataSize,
+	}
+	stored := newBatch
+	r.batches = append(r.batches, &stored)
+	return &newBatch, nil
+}
+
+// UpdateRollupStatus updates the rollup status of the batch with the given hash.
+func (r *BatchRepo) UpdateRollupStatus(ctx context.Context, hash string, status types.RollupStatus, dbTX ...*gorm.DB) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if batch := r.findByHash(hash); batch != nil {
+		setRollupStatus(batch, status)
+	}
+	return nil
+}
+
+// UpdateRollupStatusFrom updates the rollup status of the batch only while it is in one of the from statuses.
+func (r *BatchRepo) UpdateRollupStatusFrom(ctx context.Context, hash string, from []types.RollupStatus, status types.RollupStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	batch := r.findByHash(hash)
+	if batch == nil {
+		return false, nil
+	}
+	for _, fromStatus := range from {
+		if types.RollupStatus(batch.RollupStatus) == fromStatus {
+			setRollupStatus(batch, status)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func setRollupStatus(batch *orm.Batch, status types.RollupStatus) {
+	batch.RollupStatus = int16(status)
+	now := time.Now().UTC()
+	switch status {
+	case types.RollupCommitted:
+		batch.CommittedAt = &now
+	case types.RollupFinalized:
+		batch.FinalizedAt = &now
+	}
+}
+
+func (r *BatchRepo) findByHash(hash string) *orm.Batch {
+	for _, batch := range r.batches {
+		if batch.Hash == hash {
+			return batch
+		}
+	}
+	return nil
+}
+
+func matchBatch(batch *orm.Batch, fields map[string]interface{}) (bool, error) {
+	for key, value := range fields {
+		var match bool
+		switch key {
+		case "index >= ?":
+			match = batch.Index >= toUint64(value)
+		case "index <= ?":
+			match = batch.Index <= toUint64(value)
+		case "index = ?":
+			match = batch.Index == toUint64(value)
+		case "hash = ?":
+			match = batch.Hash == value
+		case "rollup_status = ?":
+			match = int64(batch.RollupStatus) == toInt64(value)
+		default:
+			return false, fmt.Errorf("fake.BatchRepo.GetBatches: unsupported field %v", key)
+		}
+		if !match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,180 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// ChunkRepo is an in-memory orm.ChunkRepo.
+type ChunkRepo struct {
+	mu     sync.Mutex
+	chunks []*orm.Chunk
+}
+
+// NewChunkRepo creates an empty in-memory chunk repo.
+func NewChunkRepo() *ChunkRepo {
+	return &ChunkRepo{}
+}
+
+// AddChunks stores the chunks as is, they must be added in index order.
+func (r *ChunkRepo) AddChunks(chunks ...*orm.Chunk) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, chunk := range chunks {
+		copied := *chunk
+		r.chunks = append(r.chunks, &copied)
+	}
+}
+
+// GetChunksInRange returns the chunks in [startIndex, endIndex], all of them must exist.
+func (r *ChunkRepo) GetChunksInRange(ctx context.Context, startIndex uint64, endIndex uint64) ([]*orm.Chunk, error) {
+	if startIndex > endIndex {
+		return nil, fmt.Errorf("fake.ChunkRepo.GetChunksInRange: start index should be less than or equal to end index, start index: %v, end index: %v", startIndex, endIndex)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var chunks []*orm.Chunk
+	for _, chunk := range r.chunks {
+		if chunk.Index >= startIndex && chunk.Index <= endIndex {
+			copied := *chunk
+			chunks = append(chunks, &copied)
+		}
+	}
+	if uint64(len(chunks)) != endIndex-startIndex+1 {
+		return nil, fmt.Errorf("fake.ChunkRepo.GetChunksInRange: incorrect number of chunks, expected: %v, got: %v, start index: %v, end index: %v", endIndex-startIndex+1, len(chunks), startIndex, endIndex)
+	}
+	return chunks, nil
+}
+
+// GetLatestChunk returns the chunk with the highest index, the error wraps gorm.ErrRecordNotFound if there is no chunk.
+func (r *ChunkRepo) GetLatestChunk(ctx context.Context) (*orm.Chunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.chunks) == 0 {
+		return nil, fmt.Errorf("fake.ChunkRepo.GetLatestChunk error: %w", gorm.ErrRecordNotFound)
+	}
+	copied := *r.chunks[len(r.chunks)-1]
+	return &copied, nil
+}
+
+// GetUnchunkedBlockHeight returns the block number following the latest chunk.
+func (r *ChunkRepo) GetUnchunkedBlockHeight(ctx context.Context) (uint64, error) {
+	latestChunk, err := r.GetLatestChunk(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 1, nil
+		}
+		return 0, err
+	}
+	return latestChunk.EndBlockNumber + 1, nil
+}
+
+// GetChunksGEIndex returns at most limit chunks from the given index, all of them if limit is 0.
+func (r *ChunkRepo) GetChunksGEIndex(ctx context.Context, index uint64, limit int) ([]*orm.Chunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var chunks []*orm.Chunk
+	for _, chunk := range r.chunks {
+		if limit > 0 && len(chunks) == limit {
+			break
+		}
+		if chunk.Index >= index {
+			copied := *chunk
+			chunks = append(chunks, &copied)
+		}
+	}
+	return chunks, nil
+}
+
+// InsertChunk builds the chunk row the same way as the postgres implementation and stores it.
+func (r *ChunkRepo) InsertChunk(ctx context.Context, chunk *types.Chunk, dbTX ...*gorm.DB) (*orm.Chunk, error) {
+	if chunk == nil || len(chunk.Blocks) == 0 {
+		return nil, errors.New("invalid args")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var chunkIndex uint64
+	var totalL1MessagePoppedBefore uint64
+	var parentChunkHash string
+	var parentChunkStateRoot string
+	if len(r.chunks) > 0 {
+		parentChunk := r.chunks[len(r.chunks)-1]
+		chunkIndex = parentChunk.Index + 1
+		totalL1MessagePoppedBefore = parentChunk.TotalL1MessagesPoppedBefore + uint64(parentChunk.TotalL1MessagesPoppedInChunk)
+		parentChunkHash = parentChunk.Hash
+		parentChunkStateRoot = parentChunk.StateRoot
+	}
+
+	hash, err := chunk.Hash(totalL1MessagePoppedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalL2TxGas uint64
+	var totalL2TxNum uint64
+	var totalL1CommitCalldataSize uint64
+	for _, block := range chunk.Blocks {
+		totalL2TxGas += block.Header.GasUsed
+		totalL2TxNum += block.NumL2Transactions()
+		blockL1CommitCalldataSize, err := block.EstimateL1CommitCalldataSize()
+		if err != nil {
+			return nil, err
+		}
+		totalL1CommitCalldataSize += blockL1CommitCalldataSize
+	}
+
+	totalL1CommitGas, err := chunk.EstimateL1CommitGas()
+	if err != nil {
+		return nil, err
+	}
+
+	numBlocks := len(chunk.Blocks)
+	newChunk := orm.Chunk{
+		Index:                        chunkIndex,
+		Hash:                         hash.Hex(),
+		StartBlockNumber:             chunk.Blocks[0].Header.Number.Uint64(),
+		StartBlockHash:               chunk.Blocks[0].Header.Hash().Hex(),
+		EndBlockNumber:               chunk.Blocks[numBlocks-1].Header.Number.Uint64(),
+		EndBlockHash:                 chunk.Blocks[numBlocks-1].Header.Hash().Hex(),
+		TotalL2TxGas:                 totalL2TxGas,
+		TotalL2TxNum:                 uint32(totalL2TxNum),
+		TotalL1CommitCalldataSize:    uint32(totalL1CommitCalldataSize),
+		TotalL1CommitGas:             totalL1CommitGas,
+		StartBlockTime:               chunk.Blocks[0].Header.Time,
+		TotalL1MessagesPoppedBefore:  totalL1MessagePoppedBefore,
+		TotalL1MessagesPoppedInChunk: uint32(chunk.NumL1Messages(totalL1MessagePoppedBefore)),
+		ParentChunkHash:              parentChunkHash,
+		StateRoot:                    chunk.Blocks[numBlocks-1].Header.Root.Hex(),
+		ParentChunkStateRoot:         parentChunkStateRoot,
+		WithdrawRoot:                 chunk.Blocks[numBlocks-1].WithdrawRoot.Hex(),
+		ProvingStatus:                int16(types.ProvingTaskUnassigned),
+	}
+	stored := newChunk
+	r.chunks = append(r.chunks, &stored)
+	return &newChunk, nil
+}
+
+// UpdateBatchHashInRange sets the batch hash of the chunks in [startIndex, endIndex].
+func (r *ChunkRepo) UpdateBatchHashInRange(ctx context.Context, startIndex uint64, endIndex uint64, batchHash string, dbTX ...*gorm.DB) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, chunk := range r.chunks {
+		if chunk.Index >= startIndex && chunk.Index <= endIndex {
+			chunk.BatchHash = batchHash
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Package fake provides in-memory implementations of the orm repositories,
+// so the business logic can be tested without a database.
+package fake
+
+import (
+	"reflect"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// toUint64 converts the integer argument of a field condition, whatever its integer type.
+func toUint64(value interface{}) uint64 {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	}
+	return 0
+}
+
+func toInt64(value interface{}) int64 {
+	return int64(toUint64(value))
+}
+
+var (
+	_ orm.BatchRepo     = (*BatchRepo)(nil)
+	_ orm.ChunkRepo     = (*ChunkRepo)(nil)
+	_ orm.L1MessageRepo = (*L1MessageRepo)(nil)
+)
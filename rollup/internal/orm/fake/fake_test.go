@@ -0,0 +1,117 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+func readWrappedBlock(t *testing.T, file string) *types.WrappedBlock {
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	block := &types.WrappedBlock{}
+	assert.NoError(t, json.Unmarshal(data, block))
+	return block
+}
+
+func TestChunkAndBatchRepo(t *testing.T) {
+	block1 := readWrappedBlock(t, "../../../../common/testdata/blockTrace_02.json")
+	block2 := readWrappedBlock(t, "../../../../common/testdata/blockTrace_03.json")
+	ctx := context.Background()
+
+	chunkRepo := NewChunkRepo()
+	_, err := chunkRepo.GetLatestChunk(ctx)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	height, err := chunkRepo.GetUnchunkedBlockHeight(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+
+	chunk1 := &types.Chunk{Blocks: []*types.WrappedBlock{block1}}
+	chunk2 := &types.Chunk{Blocks: []*types.WrappedBlock{block2}}
+	dbChunk1, err := chunkRepo.InsertChunk(ctx, chunk1)
+	assert.NoError(t, err)
+	dbChunk2, err := chunkRepo.InsertChunk(ctx, chunk2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), dbChunk2.Index)
+	assert.Equal(t, dbChunk1.Hash, dbChunk2.ParentChunkHash)
+
+	height, err = chunkRepo.GetUnchunkedBlockHeight(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, block2.Header.Number.Uint64()+1, height)
+
+	chunks, err := chunkRepo.GetChunksInRange(ctx, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	_, err = chunkRepo.GetChunksInRange(ctx, 0, 2)
+	assert.Error(t, err)
+
+	batchRepo := NewBatchRepo()
+	batchMeta := &types.BatchMeta{StartChunkIndex: 0, StartChunkHash: dbChunk1.Hash, EndChunkIndex: 0, EndChunkHash: dbChunk1.Hash}
+	batch0, err := batchRepo.InsertBatch(ctx, []*types.Chunk{chunk1}, batchMeta)
+	assert.NoError(t, err)
+	batchMeta = &types.BatchMeta{StartChunkIndex: 1, StartChunkHash: dbChunk2.Hash, EndChunkIndex: 1, EndChunkHash: dbChunk2.Hash}
+	batch1, err := batchRepo.InsertBatch(ctx, []*types.Chunk{chunk2}, batchMeta)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), batch1.Index)
+	assert.Equal(t, batch0.Hash, batch1.ParentBatchHash)
+	assert.NoError(t, chunkRepo.UpdateBatchHashInRange(ctx, 1, 1, batch1.Hash))
+
+	index, err := batchRepo.GetFirstUnbatchedChunkIndex(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), index)
+
+	assert.NoError(t, batchRepo.UpdateRollupStatus(ctx, batch0.Hash, types.RollupFinalized))
+	batches, err := batchRepo.GetBatches(ctx, map[string]interface{}{"rollup_status = ?": types.RollupFinalized}, nil, 0)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, batch0.Hash, batches[0].Hash)
+	assert.NotNil(t, batches[0].FinalizedAt)
+
+	batches, err = batchRepo.GetBatches(ctx, map[string]interface{}{}, []string{"index DESC"}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, uint64(1), batches[0].Index)
+
+	_, err = batchRepo.GetBatches(ctx, map[string]interface{}{"proving_status = ?": 1}, nil, 0)
+	assert.Error(t, err)
+
+	statuses, err := batchRepo.GetRollupStatusByHashList(ctx, []string{batch1.Hash, batch0.Hash})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupPending, types.RollupFinalized}, statuses)
+
+	// the batch is only updated from the given statuses
+	updated, err := batchRepo.UpdateRollupStatusFrom(ctx, batch1.Hash, []types.RollupStatus{types.RollupCommitted}, types.RollupCommitFailed)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	updated, err = batchRepo.UpdateRollupStatusFrom(ctx, batch1.Hash, []types.RollupStatus{types.RollupCommitted, types.RollupPending}, types.RollupCommitting)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	statuses, err = batchRepo.GetRollupStatusByHashList(ctx, []string{batch1.Hash})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupCommitting}, statuses)
+}
+
+func TestL1MessageRepo(t *testing.T) {
+	repo := NewL1MessageRepo()
+	height, err := repo.GetLayer1LatestWatchedHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), height)
+
+	err = repo.SaveL1Messages(context.Background(), []*orm.L1Message{{QueueIndex: 0, Height: 10}, {QueueIndex: 1, Height: 12}})
+	assert.NoError(t, err)
+
+	height, err = repo.GetLayer1LatestWatchedHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), height)
+	length, err := repo.GetL1MessageQueueLength(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), length)
+}
@@ -0,0 +1,56 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// L1MessageRepo is an in-memory orm.L1MessageRepo.
+type L1MessageRepo struct {
+	mu       sync.Mutex
+	messages []*orm.L1Message
+}
+
+// NewL1MessageRepo creates an empty in-memory l1 message repo.
+func NewL1MessageRepo() *L1MessageRepo {
+	return &L1MessageRepo{}
+}
+
+// GetLayer1LatestWatchedHeight returns the highest layer 1 height of the stored messages, -1 if there is none.
+func (r *L1MessageRepo) GetLayer1LatestWatchedHeight() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	height := int64(-1)
+	for _, message := range r.messages {
+		if int64(message.Height) > height {
+			height = int64(message.Height)
+		}
+	}
+	return height, nil
+}
+
+// GetL1MessageQueueLength returns the highest stored queue index plus one.
+func (r *L1MessageRepo) GetL1MessageQueueLength(ctx context.Context) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var length uint64
+	for _, message := range r.messages {
+		if message.QueueIndex+1 > length {
+			length = message.QueueIndex + 1
+		}
+	}
+	return length, nil
+}
+
+// SaveL1Messages stores the messages.
+func (r *L1MessageRepo) SaveL1Messages(ctx context.Context, messages []*orm.L1Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, message := range messages {
+		copied := *message
+		r.messages = append(r.messages, &copied)
+	}
+	return nil
+}
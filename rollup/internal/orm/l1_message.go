@@ -3,6 +3,7 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/log"
@@ -15,6 +16,7 @@ type L1Message struct {
 
 	QueueIndex uint64 `json:"queue_index" gorm:"column:queue_index"`
 	MsgHash    string `json:"msg_hash" gorm:"column:msg_hash"`
+	TxHash     string `json:"tx_hash" gorm:"column:tx_hash;default:NULL"`
 	Height     uint64 `json:"height" gorm:"column:height"`
 	GasLimit   uint64 `json:"gas_limit" gorm:"column:gas_limit"`
 	Sender     string `json:"sender" gorm:"column:sender"`
@@ -56,6 +58,21 @@ func (m *L1Message) GetLayer1LatestWatchedHeight() (int64, error) {
 	return -1, nil
 }
 
+// GetL1MessageQueueLength returns the length of the l1 message queue observed on layer 1,
+// i.e. the latest stored queue index plus one.
+func (m *L1Message) GetL1MessageQueueLength(ctx context.Context) (uint64, error) {
+	var maxQueueIndex sql.NullInt64
+	db := m.db.WithContext(ctx)
+	db = db.Model(&L1Message{})
+	if err := db.Select("MAX(queue_index)").Scan(&maxQueueIndex).Error; err != nil {
+		return 0, fmt.Errorf("L1Message.GetL1MessageQueueLength error: %w", err)
+	}
+	if !maxQueueIndex.Valid {
+		return 0, nil
+	}
+	return uint64(maxQueueIndex.Int64) + 1, nil
+}
+
 // SaveL1Messages batch save a list of layer1 messages
 func (m *L1Message) SaveL1Messages(ctx context.Context, messages []*L1Message) error {
 	if len(messages) == 0 {
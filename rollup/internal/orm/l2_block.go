@@ -2,8 +2,10 @@ package orm
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
@@ -32,7 +34,10 @@ type L2Block struct {
 	RowConsumption string `json:"row_consumption" gorm:"row_consumption"`
 
 	// chunk
-	ChunkHash string `json:"chunk_hash" gorm:"chunk_hash;default:NULL"`
+	ChunkHash                   string `json:"chunk_hash" gorm:"chunk_hash;default:NULL"`
+	ChunkProposalFailures       uint64 `json:"chunk_proposal_failures" gorm:"chunk_proposal_failures;default:0"`
+	ChunkProposalFailureReasons string `json:"chunk_proposal_failure_reasons" gorm:"chunk_proposal_failure_reasons;default:''"`
+	ChunkProposalStatus         int16  `json:"chunk_proposal_status" gorm:"chunk_proposal_status;default:0"`
 
 	// metadata
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
@@ -87,7 +92,7 @@ func (o *L2Block) GetL2WrappedBlocksGEHeight(ctx context.Context, height uint64,
 	for _, v := range l2Blocks {
 		var wrappedBlock types.WrappedBlock
 
-		if err := json.Unmarshal([]byte(v.Transactions), &wrappedBlock.Transactions); err != nil {
+		if err := wrappedBlock.UnmarshalTransactions([]byte(v.Transactions)); err != nil {
 			return nil, fmt.Errorf("L2Block.GetL2WrappedBlocksGEHeight error: %w", err)
 		}
 
@@ -163,7 +168,7 @@ func (o *L2Block) GetL2BlocksInRange(ctx context.Context, startBlockNumber uint6
 	for _, v := range l2Blocks {
 		var wrappedBlock types.WrappedBlock
 
-		if err := json.Unmarshal([]byte(v.Transactions), &wrappedBlock.Transactions); err != nil {
+		if err := wrappedBlock.UnmarshalTransactions([]byte(v.Transactions)); err != nil {
 			return nil, fmt.Errorf("L2Block.GetL2BlocksInRange error: %w, start block: %v, end block: %v", err, startBlockNumber, endBlockNumber)
 		}
 
@@ -184,6 +189,27 @@ func (o *L2Block) GetL2BlocksInRange(ctx context.Context, startBlockNumber uint6
 	return wrappedBlocks, nil
 }
 
+// GetLatestL1MessageQueueIndexGEHeight returns the highest queue index of the l1 messages included in the
+// blocks from the given height on, found is false if these blocks include no l1 message.
+func (o *L2Block) GetLatestL1MessageQueueIndexGEHeight(ctx context.Context, height uint64) (index uint64, found bool, err error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	// the queue index of l1 message tx is stored in the nonce field
+	db = db.Select("MAX((tx->>'nonce')::BIGINT)")
+	db = db.Joins("CROSS JOIN LATERAL jsonb_array_elements(l2_block.transactions::jsonb) AS tx")
+	db = db.Where("l2_block.number >= ?", height)
+	db = db.Where("(tx->>'type')::INTEGER = ?", gethTypes.L1MessageTxType)
+
+	var maxQueueIndex sql.NullInt64
+	if err := db.Scan(&maxQueueIndex).Error; err != nil {
+		return 0, false, fmt.Errorf("L2Block.GetLatestL1MessageQueueIndexGEHeight error: %w, height: %v", err, height)
+	}
+	if !maxQueueIndex.Valid {
+		return 0, false, nil
+	}
+	return uint64(maxQueueIndex.Int64), true, nil
+}
+
 // InsertL2Blocks inserts l2 blocks into the "l2_block" table.
 func (o *L2Block) InsertL2Blocks(ctx context.Context, blocks []*types.WrappedBlock) error {
 	var l2Blocks []L2Block
@@ -194,7 +220,7 @@ func (o *L2Block) InsertL2Blocks(ctx context.Context, blocks []*types.WrappedBlo
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)
 		}
 
-		txs, err := json.Marshal(block.Transactions)
+		txs, err := block.MarshalTransactions()
 		if err != nil {
 			log.Error("failed to marshal transactions", "hash", block.Header.Hash().String(), "err", err)
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)
@@ -256,3 +282,88 @@ func (o *L2Block) UpdateChunkHashInRange(ctx context.Context, startIndex uint64,
 
 	return nil
 }
+
+// ResetChunkHashGEHeight clears the chunk_hash of the blocks with a height greater than or equal to the given height,
+// so that they are chunked again.
+func (o *L2Block) ResetChunkHashGEHeight(ctx context.Context, height uint64, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Where("number >= ?", height)
+
+	if err := db.Update("chunk_hash", nil).Error; err != nil {
+		return fmt.Errorf("L2Block.ResetChunkHashGEHeight error: %w, height: %v", err, height)
+	}
+	return nil
+}
+
+// RecordChunkProposalFailure counts a failure of a block, either a chunk limit the block broke on its own or a
+// failed proof of a chunk including it. Every failure is counted, so a block breaking the same limit on every tick
+// reaches the quarantine threshold. The reason is added to the distinct failure reasons of the block for the operators.
+// It returns the number of failures recorded for the block.
+func (o *L2Block) RecordChunkProposalFailure(ctx context.Context, number uint64, reason string) (uint64, error) {
+	if reason == "" || strings.Contains(reason, ",") {
+		return 0, fmt.Errorf("L2Block.RecordChunkProposalFailure: invalid failure reason %q, block number: %v", reason, number)
+	}
+
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Where("number = ?", number)
+	result := db.Updates(map[string]interface{}{
+		"chunk_proposal_failures": gorm.Expr("chunk_proposal_failures + 1"),
+		"chunk_proposal_failure_reasons": gorm.Expr("CASE WHEN ? = ANY(string_to_array(chunk_proposal_failure_reasons, ',')) THEN chunk_proposal_failure_reasons "+
+			"WHEN chunk_proposal_failure_reasons = '' THEN ? ELSE chunk_proposal_failure_reasons || ',' || ? END", reason, reason, reason),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("L2Block.RecordChunkProposalFailure error: %w, block number: %v, reason: %v", result.Error, number, reason)
+	}
+	if result.RowsAffected != 1 {
+		return 0, fmt.Errorf("L2Block.RecordChunkProposalFailure: block not found, block number: %v, reason: %v", number, reason)
+	}
+	return o.GetChunkProposalFailures(ctx, number)
+}
+
+// GetChunkProposalFailures returns the number of failures recorded for a block.
+func (o *L2Block) GetChunkProposalFailures(ctx context.Context, number uint64) (uint64, error) {
+	var block L2Block
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Select("chunk_proposal_failures")
+	db = db.Where("number = ?", number)
+	if err := db.First(&block).Error; err != nil {
+		return 0, fmt.Errorf("L2Block.GetChunkProposalFailures error: %w, block number: %v", err, number)
+	}
+	return block.ChunkProposalFailures, nil
+}
+
+// GetMaxChunkProposalFailuresInRange returns the highest number of failures recorded for the blocks in the given range.
+func (o *L2Block) GetMaxChunkProposalFailuresInRange(ctx context.Context, startBlockNumber uint64, endBlockNumber uint64) (uint64, error) {
+	var maxFailures uint64
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Select("COALESCE(MAX(chunk_proposal_failures), 0)")
+	db = db.Where("number >= ? AND number <= ?", startBlockNumber, endBlockNumber)
+	if err := db.Scan(&maxFailures).Error; err != nil {
+		return 0, fmt.Errorf("L2Block.GetMaxChunkProposalFailuresInRange error: %w, start block number: %v, end block number: %v", err, startBlockNumber, endBlockNumber)
+	}
+	return maxFailures, nil
+}
+
+// UpdateChunkProposalStatus updates the chunk proposal status of a block.
+func (o *L2Block) UpdateChunkProposalStatus(ctx context.Context, number uint64, status types.ChunkProposalStatus) error {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&L2Block{})
+	db = db.Where("number = ?", number)
+
+	tx := db.Update("chunk_proposal_status", int16(status))
+	if tx.Error != nil {
+		return fmt.Errorf("L2Block.UpdateChunkProposalStatus error: %w, block number: %v, status: %v", tx.Error, number, status.String())
+	}
+	if tx.RowsAffected != 1 {
+		return fmt.Errorf("L2Block.UpdateChunkProposalStatus: block not found, block number: %v, status: %v", number, status.String())
+	}
+	return nil
+}
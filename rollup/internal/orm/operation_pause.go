@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// OperationPropose proposes chunks and batches.
+	OperationPropose = "propose"
+	// OperationCommit commits batches to layer 1.
+	OperationCommit = "commit"
+	// OperationFinalize finalizes batches on layer 1.
+	OperationFinalize = "finalize"
+	// OperationGasOracle updates the layer 1 and layer 2 gas price oracles.
+	OperationGasOracle = "gas_oracle"
+)
+
+// Operations are the operations which can be paused.
+var Operations = []string{OperationPropose, OperationCommit, OperationFinalize, OperationGasOracle}
+
+// OperationPause is the persisted pause switch of an operation.
+type OperationPause struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	Operation string `json:"operation" gorm:"column:operation;primaryKey"`
+	Paused    bool   `json:"paused" gorm:"column:paused"`
+	Reason    string `json:"reason" gorm:"column:reason"`
+
+	// metadata
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewOperationPause creates a new OperationPause database instance.
+func NewOperationPause(db *gorm.DB) *OperationPause {
+	return &OperationPause{db: db}
+}
+
+// TableName returns the table name for the OperationPause model.
+func (*OperationPause) TableName() string {
+	return "operation_pause"
+}
+
+// GetOperationPauses returns the pause switches set so far, operations never set are not paused.
+func (o *OperationPause) GetOperationPauses(ctx context.Context) ([]*OperationPause, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&OperationPause{})
+	db = db.Order("operation ASC")
+
+	var operationPauses []*OperationPause
+	if err := db.Find(&operationPauses).Error; err != nil {
+		return nil, fmt.Errorf("OperationPause.GetOperationPauses error: %w", err)
+	}
+	return operationPauses, nil
+}
+
+// SetOperationPause pauses or resumes an operation.
+func (o *OperationPause) SetOperationPause(ctx context.Context, operation string, paused bool, reason string, dbTX ...*gorm.DB) error {
+	db := o.db
+	if len(dbTX) > 0 && dbTX[0] != nil {
+		db = dbTX[0]
+	}
+	db = db.WithContext(ctx)
+	db = db.Model(&OperationPause{})
+	db = db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "operation"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"paused":     paused,
+			"reason":     reason,
+			"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	})
+	operationPause := OperationPause{Operation: operation, Paused: paused, Reason: reason}
+	if err := db.Create(&operationPause).Error; err != nil {
+		return fmt.Errorf("OperationPause.SetOperationPause error: %w, operation: %v, paused: %v", err, operation, paused)
+	}
+	return nil
+}
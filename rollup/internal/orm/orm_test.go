@@ -3,9 +3,13 @@ package orm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/scroll-tech/go-ethereum/common"
 	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
@@ -171,6 +175,75 @@ func TestL2BlockOrm(t *testing.T) {
 	assert.Len(t, blocks, 2)
 	assert.Equal(t, "test hash", blocks[0].ChunkHash)
 	assert.Equal(t, "", blocks[1].ChunkHash)
+
+	// every failure is counted, the reasons are kept distinct
+	failures, err := l2BlockOrm.RecordChunkProposalFailure(context.Background(), 3, "max_tx_num_per_chunk")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), failures)
+	failures, err = l2BlockOrm.RecordChunkProposalFailure(context.Background(), 3, "max_tx_num_per_chunk")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), failures)
+	failures, err = l2BlockOrm.RecordChunkProposalFailure(context.Background(), 3, "prover_failure")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), failures)
+	failures, err = l2BlockOrm.GetChunkProposalFailures(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), failures)
+	failures, err = l2BlockOrm.GetChunkProposalFailures(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), failures)
+
+	maxFailures, err := l2BlockOrm.GetMaxChunkProposalFailuresInRange(context.Background(), 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), maxFailures)
+	maxFailures, err = l2BlockOrm.GetMaxChunkProposalFailuresInRange(context.Background(), 100, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), maxFailures)
+
+	_, err = l2BlockOrm.RecordChunkProposalFailure(context.Background(), 3, "a,b")
+	assert.Error(t, err)
+	_, err = l2BlockOrm.RecordChunkProposalFailure(context.Background(), 100, "max_tx_num_per_chunk")
+	assert.Error(t, err)
+	_, err = l2BlockOrm.GetChunkProposalFailures(context.Background(), 100)
+	assert.Error(t, err)
+
+	assert.NoError(t, l2BlockOrm.UpdateChunkProposalStatus(context.Background(), 3, types.ChunkProposalStatusQuarantined))
+	assert.Error(t, l2BlockOrm.UpdateChunkProposalStatus(context.Background(), 100, types.ChunkProposalStatusQuarantined))
+
+	blocks, err = l2BlockOrm.GetL2Blocks(context.Background(), map[string]interface{}{}, []string{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "", blocks[0].ChunkProposalFailureReasons)
+	assert.Equal(t, int16(types.ChunkProposalStatusUndefined), blocks[0].ChunkProposalStatus)
+	assert.Equal(t, "max_tx_num_per_chunk,prover_failure", blocks[1].ChunkProposalFailureReasons)
+	assert.Equal(t, uint64(3), blocks[1].ChunkProposalFailures)
+	assert.Equal(t, int16(types.ChunkProposalStatusQuarantined), blocks[1].ChunkProposalStatus)
+
+	_, found, err := l2BlockOrm.GetLatestL1MessageQueueIndexGEHeight(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	header := *wrappedBlock2.Header
+	header.Number = big.NewInt(4)
+	l1MessageBlock := &types.WrappedBlock{
+		Header: &header,
+		Transactions: []*gethTypes.TransactionData{
+			{Type: gethTypes.L1MessageTxType, Nonce: 5, TxHash: common.HexToHash("0x05").Hex()},
+			{Type: gethTypes.L1MessageTxType, Nonce: 6, TxHash: common.HexToHash("0x06").Hex()},
+			{Type: gethTypes.LegacyTxType, Nonce: 100, TxHash: common.HexToHash("0x64").Hex()},
+		},
+		WithdrawRoot:   wrappedBlock2.WithdrawRoot,
+		RowConsumption: wrappedBlock2.RowConsumption,
+	}
+	assert.NoError(t, l2BlockOrm.InsertL2Blocks(context.Background(), []*types.WrappedBlock{l1MessageBlock}))
+
+	queueIndex, found, err := l2BlockOrm.GetLatestL1MessageQueueIndexGEHeight(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(6), queueIndex)
+
+	_, found, err = l2BlockOrm.GetLatestL1MessageQueueIndexGEHeight(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.False(t, found)
 }
 
 func TestChunkOrm(t *testing.T) {
@@ -216,6 +289,28 @@ func TestChunkOrm(t *testing.T) {
 	assert.Equal(t, chunkHash2.Hex(), chunks[1].Hash)
 	assert.Equal(t, "test hash", chunks[0].BatchHash)
 	assert.Equal(t, "", chunks[1].BatchHash)
+
+	// the batched and the assigned chunks are not deleted
+	deleted, err := chunkOrm.DeleteUnbatchedChunksGEIndex(context.Background(), 0)
+	assert.ErrorContains(t, err, "already batched")
+	assert.Equal(t, int64(0), deleted)
+	deleted, err = chunkOrm.DeleteUnbatchedChunksGEIndex(context.Background(), 1)
+	assert.ErrorContains(t, err, "already assigned to provers or proven")
+	assert.Equal(t, int64(0), deleted)
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+
+	// the failed chunk is deleted
+	err = chunkOrm.UpdateProvingStatus(context.Background(), chunkHash2.Hex(), types.ProvingTaskFailed)
+	assert.NoError(t, err)
+	deleted, err = chunkOrm.DeleteUnbatchedChunksGEIndex(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	chunks, err = chunkOrm.GetChunksGEIndex(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	assert.Equal(t, chunkHash1.Hex(), chunks[0].Hash)
 }
 
 func TestBatchOrm(t *testing.T) {
@@ -264,6 +359,12 @@ func TestBatchOrm(t *testing.T) {
 	err = batchOrm.UpdateRollupStatus(context.Background(), batchHash1, types.RollupCommitFailed)
 	assert.NoError(t, err)
 
+	// undefined statuses are rejected by the check constraints.
+	err = batchOrm.UpdateRollupStatus(context.Background(), batchHash1, types.RollupUndefined)
+	assert.Error(t, err)
+	err = batchOrm.UpdateProvingStatus(context.Background(), batchHash1, types.ProvingStatus(99))
+	assert.Error(t, err)
+
 	pendingBatches, err := batchOrm.GetFailedAndPendingBatches(context.Background(), 100)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(pendingBatches))
@@ -312,6 +413,31 @@ func TestBatchOrm(t *testing.T) {
 	assert.NotNil(t, updatedBatch)
 	assert.Equal(t, "finalizeTxHash", updatedBatch.FinalizeTxHash)
 	assert.Equal(t, types.RollupFinalizeFailed, types.RollupStatus(updatedBatch.RollupStatus))
+
+	// the batch is only updated from the given statuses
+	updated, err := batchOrm.UpdateRollupStatusFrom(context.Background(), batchHash2, []types.RollupStatus{types.RollupCommitted, types.RollupFinalizing}, types.RollupCommitFailed)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	updated, err = batchOrm.UpdateRollupStatusFrom(context.Background(), batchHash2, []types.RollupStatus{types.RollupFinalizeFailed}, types.RollupFinalizing)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	rollupStatus, err = batchOrm.GetRollupStatusByHashList(context.Background(), []string{batchHash2})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RollupStatus{types.RollupFinalizing}, rollupStatus)
+
+	timelines, err := batchOrm.GetBatchTimelines(context.Background(), time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, timelines, 2)
+	assert.Equal(t, batchHash1, timelines[0].Hash)
+	assert.Nil(t, timelines[0].CommittedAt)
+	assert.Equal(t, batchHash2, timelines[1].Hash)
+	assert.NotNil(t, timelines[1].CommittedAt)
+	assert.NotNil(t, timelines[1].ProvedAt)
+	assert.Empty(t, timelines[1].BatchHeader)
+
+	timelines, err = batchOrm.GetBatchTimelines(context.Background(), time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, timelines)
 }
 
 func TestTransactionOrm(t *testing.T) {
@@ -395,3 +521,207 @@ func TestTransactionOrm(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, types.TxStatusConfirmedFailed, status)
 }
+
+func TestEstimatorErrorOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	estimatorErrorOrm := NewEstimatorError(db)
+
+	err = estimatorErrorOrm.Accumulate(context.Background(), []*EstimatorError{
+		{TxType: 0, Target: "target1", SampleCount: 1, EstimatedSizeSum: 100, ActualSizeSum: 100, EstimatedGasSum: 1600, ActualGasSum: 1000},
+		{TxType: 2, Target: "target2", SampleCount: 1, EstimatedSizeSum: 100, ActualSizeSum: 100, EstimatedGasSum: 1600, ActualGasSum: 1500},
+	})
+	assert.NoError(t, err)
+
+	err = estimatorErrorOrm.Accumulate(context.Background(), []*EstimatorError{
+		{TxType: 0, Target: "target1", SampleCount: 2, EstimatedSizeSum: 200, ActualSizeSum: 200, EstimatedGasSum: 3200, ActualGasSum: 2000},
+	})
+	assert.NoError(t, err)
+
+	estimatorErrors, err := estimatorErrorOrm.GetWorstEstimatorErrors(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, estimatorErrors, 2)
+	assert.Equal(t, "target1", estimatorErrors[0].Target)
+	assert.Equal(t, uint64(3), estimatorErrors[0].SampleCount)
+	assert.Equal(t, uint64(4800), estimatorErrors[0].EstimatedGasSum)
+	assert.Equal(t, uint64(3000), estimatorErrors[0].ActualGasSum)
+	assert.Equal(t, "target2", estimatorErrors[1].Target)
+}
+
+func TestOperationPauseOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	operationPauseOrm := NewOperationPause(db)
+
+	operationPauses, err := operationPauseOrm.GetOperationPauses(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, operationPauses, 0)
+
+	err = operationPauseOrm.SetOperationPause(context.Background(), OperationCommit, true, "l1 congestion")
+	assert.NoError(t, err)
+	err = operationPauseOrm.SetOperationPause(context.Background(), OperationFinalize, true, "")
+	assert.NoError(t, err)
+	err = operationPauseOrm.SetOperationPause(context.Background(), OperationFinalize, false, "resumed")
+	assert.NoError(t, err)
+
+	operationPauses, err = operationPauseOrm.GetOperationPauses(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, operationPauses, 2)
+	assert.Equal(t, OperationCommit, operationPauses[0].Operation)
+	assert.True(t, operationPauses[0].Paused)
+	assert.Equal(t, "l1 congestion", operationPauses[0].Reason)
+	assert.Equal(t, OperationFinalize, operationPauses[1].Operation)
+	assert.False(t, operationPauses[1].Paused)
+	assert.Equal(t, "resumed", operationPauses[1].Reason)
+}
+
+func TestBatchEventOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	batchEventOrm := NewBatchEvent(db)
+
+	_, found, err := batchEventOrm.GetLatestBatchIndexAt(context.Background(), types.RollupFinalized, 100)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	events := []*BatchEvent{
+		{BatchIndex: 1, BatchHash: "0x1", RollupStatus: int16(types.RollupCommitted), L1BlockNumber: 10, L1TxHash: "0xa"},
+		{BatchIndex: 2, BatchHash: "0x2", RollupStatus: int16(types.RollupCommitted), L1BlockNumber: 20, L1TxHash: "0xb"},
+		{BatchIndex: 1, BatchHash: "0x1", RollupStatus: int16(types.RollupFinalized), L1BlockNumber: 30, L1TxHash: "0xc"},
+	}
+	assert.NoError(t, batchEventOrm.InsertBatchEvents(context.Background(), events))
+	// the events of the layer 1 blocks watched again are skipped
+	assert.NoError(t, batchEventOrm.InsertBatchEvents(context.Background(), events[:1]))
+
+	index, found, err := batchEventOrm.GetLatestBatchIndexAt(context.Background(), types.RollupCommitted, 19)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(1), index)
+
+	index, found, err = batchEventOrm.GetLatestBatchIndexAt(context.Background(), types.RollupCommitted, 20)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(2), index)
+
+	_, found, err = batchEventOrm.GetLatestBatchIndexAt(context.Background(), types.RollupFinalized, 29)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	index, found, err = batchEventOrm.GetLatestBatchIndexAt(context.Background(), types.RollupFinalized, 30)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(1), index)
+}
+
+func TestCommitDiagnosisOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	commitDiagnosisOrm := NewCommitDiagnosis(db)
+
+	diagnoses := []*CommitDiagnosis{
+		{BatchIndex: 1, BatchHash: "0x1", CommitTxHash: "0xa", Cause: "parent_batch_mismatch"},
+		{BatchIndex: 2, BatchHash: "0x2", CommitTxHash: "0xb", Cause: "unknown"},
+		{BatchIndex: 1, BatchHash: "0x1", CommitTxHash: "0xc", Cause: "batch_already_committed"},
+	}
+	for _, diagnosis := range diagnoses {
+		assert.NoError(t, commitDiagnosisOrm.InsertCommitDiagnosis(context.Background(), diagnosis))
+	}
+	// a transaction diagnosed twice is stored once
+	assert.NoError(t, commitDiagnosisOrm.InsertCommitDiagnosis(context.Background(), &CommitDiagnosis{BatchIndex: 2, BatchHash: "0x2", CommitTxHash: "0xb", Cause: "unknown"}))
+
+	stored, err := commitDiagnosisOrm.GetCommitDiagnoses(context.Background(), "", 0)
+	assert.NoError(t, err)
+	assert.Len(t, stored, 3)
+
+	stored, err = commitDiagnosisOrm.GetCommitDiagnoses(context.Background(), "0x1", 1)
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+	assert.Equal(t, "0xc", stored[0].CommitTxHash)
+	assert.Equal(t, "batch_already_committed", stored[0].Cause)
+}
+
+func TestAPIKeyOrm(t *testing.T) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+
+	apiKeyOrm := NewAPIKey(db)
+	apiKeyUsageOrm := NewAPIKeyUsage(db)
+
+	apiKey, err := apiKeyOrm.GetAPIKeyByHash(context.Background(), "0x1")
+	assert.NoError(t, err)
+	assert.Nil(t, apiKey)
+
+	inserted, err := apiKeyOrm.InsertAPIKey(context.Background(), "0x1", "owner", "free", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.NotZero(t, inserted.ID)
+
+	apiKey, err = apiKeyOrm.GetAPIKeyByHash(context.Background(), "0x1")
+	assert.NoError(t, err)
+	assert.NotNil(t, apiKey)
+	assert.Equal(t, inserted.ID, apiKey.ID)
+	assert.Equal(t, "free", apiKey.Tier)
+
+	issued, err := apiKeyOrm.CountAPIKeysIssuedSince(context.Background(), "127.0.0.1", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), issued)
+	issued, err = apiKeyOrm.CountAPIKeysIssuedSince(context.Background(), "127.0.0.2", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), issued)
+
+	// concurrent issuances to an ip can't exceed the limit
+	var wg sync.WaitGroup
+	var issuedKeys atomic.Int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key, insertErr := apiKeyOrm.InsertAPIKeyWithinLimit(context.Background(), fmt.Sprintf("0x1%d", i), "owner", "free", "127.0.0.3", time.Now().Add(-time.Hour), 3)
+			assert.NoError(t, insertErr)
+			if key != nil {
+				issuedKeys.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(3), issuedKeys.Load())
+	issued, err = apiKeyOrm.CountAPIKeysIssuedSince(context.Background(), "127.0.0.3", time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), issued)
+
+	found, err := apiKeyOrm.UpdateAPIKeyTier(context.Background(), apiKey.ID, "pro")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	found, err = apiKeyOrm.UpdateAPIKeyTier(context.Background(), apiKey.ID+1, "pro")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	for i := uint64(1); i <= 3; i++ {
+		count, err := apiKeyUsageOrm.IncreaseUsage(context.Background(), apiKey.ID, today)
+		assert.NoError(t, err)
+		assert.Equal(t, i, count)
+	}
+	count, err := apiKeyUsageOrm.IncreaseUsage(context.Background(), apiKey.ID, yesterday)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	usages, err := apiKeyUsageOrm.GetUsages(context.Background(), apiKey.ID, yesterday)
+	assert.NoError(t, err)
+	assert.Len(t, usages, 2)
+	assert.Equal(t, uint64(1), usages[0].RequestCount)
+	assert.Equal(t, uint64(3), usages[1].RequestCount)
+
+	usages, err = apiKeyUsageOrm.GetUsages(context.Background(), apiKey.ID, today)
+	assert.NoError(t, err)
+	assert.Len(t, usages, 1)
+}
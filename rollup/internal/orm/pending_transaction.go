@@ -69,6 +69,19 @@ func (o *PendingTransaction) GetTxStatusByTxHash(ctx context.Context, hash commo
 	return status, nil
 }
 
+// GetPendingTransactionByTxHash retrieves a transaction by its hash.
+func (o *PendingTransaction) GetPendingTransactionByTxHash(ctx context.Context, hash common.Hash) (*PendingTransaction, error) {
+	db := o.db.WithContext(ctx)
+	db = db.Model(&PendingTransaction{})
+	db = db.Where("hash = ?", hash.String())
+
+	var transaction PendingTransaction
+	if err := db.First(&transaction).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending transaction by hash, hash: %v, err: %w", hash, err)
+	}
+	return &transaction, nil
+}
+
 // GetPendingOrReplacedTransactionsBySenderType retrieves pending or replaced transactions filtered by sender type, ordered by nonce, then gas_fee_cap (gas_price in legacy tx), and limited to a specified count.
 func (o *PendingTransaction) GetPendingOrReplacedTransactionsBySenderType(ctx context.Context, senderType types.SenderType, limit int) ([]PendingTransaction, error) {
 	var transactions []PendingTransaction
@@ -0,0 +1,49 @@
+package orm
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"scroll-tech/common/types"
+)
+
+// BatchRepo is the batch storage used by the business logic, Batch is its postgres implementation
+// and the fake package provides an in-memory one for tests.
+type BatchRepo interface {
+	// GetBatches supports the "index >= ?", "index <= ?", "index = ?", "hash = ?" and "rollup_status = ?" fields
+	// and the "index ASC" and "index DESC" orders in all the implementations.
+	GetBatches(ctx context.Context, fields map[string]interface{}, orderByList []string, limit int) ([]*Batch, error)
+	GetBatchByIndex(ctx context.Context, index uint64) (*Batch, error)
+	GetLatestBatch(ctx context.Context) (*Batch, error)
+	GetFirstUnbatchedChunkIndex(ctx context.Context) (uint64, error)
+	GetRollupStatusByHashList(ctx context.Context, hashes []string) ([]types.RollupStatus, error)
+	InsertBatch(ctx context.Context, chunks []*types.Chunk, batchMeta *types.BatchMeta, dbTX ...*gorm.DB) (*Batch, error)
+	UpdateRollupStatus(ctx context.Context, hash string, status types.RollupStatus, dbTX ...*gorm.DB) error
+	UpdateRollupStatusFrom(ctx context.Context, hash string, from []types.RollupStatus, status types.RollupStatus) (bool, error)
+}
+
+// ChunkRepo is the chunk storage used by the business logic, Chunk is its postgres implementation
+// and the fake package provides an in-memory one for tests.
+type ChunkRepo interface {
+	GetChunksInRange(ctx context.Context, startIndex uint64, endIndex uint64) ([]*Chunk, error)
+	GetLatestChunk(ctx context.Context) (*Chunk, error)
+	GetUnchunkedBlockHeight(ctx context.Context) (uint64, error)
+	GetChunksGEIndex(ctx context.Context, index uint64, limit int) ([]*Chunk, error)
+	InsertChunk(ctx context.Context, chunk *types.Chunk, dbTX ...*gorm.DB) (*Chunk, error)
+	UpdateBatchHashInRange(ctx context.Context, startIndex uint64, endIndex uint64, batchHash string, dbTX ...*gorm.DB) error
+}
+
+// L1MessageRepo is the l1 message storage used by the business logic, L1Message is its postgres implementation
+// and the fake package provides an in-memory one for tests.
+type L1MessageRepo interface {
+	GetLayer1LatestWatchedHeight() (int64, error)
+	GetL1MessageQueueLength(ctx context.Context) (uint64, error)
+	SaveL1Messages(ctx context.Context, messages []*L1Message) error
+}
+
+var (
+	_ BatchRepo     = (*Batch)(nil)
+	_ ChunkRepo     = (*Chunk)(nil)
+	_ L1MessageRepo = (*L1Message)(nil)
+)
@@ -0,0 +1,64 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"scroll-tech/common/observability"
+
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/api"
+	"scroll-tech/rollup/internal/middleware"
+)
+
+// Route register route for rollup
+func Route(router *gin.Engine, cfg *config.Config, reg prometheus.Registerer) {
+	router.Use(gin.Recovery())
+
+	observability.Use(router, "rollup", reg)
+
+	r := router.Group("rollup")
+
+	v1(r, cfg)
+}
+
+func v1(router *gin.RouterGroup, conf *config.Config) {
+	r := router.Group("/v1")
+
+	public := r.Group("")
+	if api.APIKey != nil {
+		r.POST("/api_key", api.APIKey.Issue)
+		r.GET("/api_key/usage", api.APIKey.Usage)
+		public.Use(api.APIKey.Authorize)
+		// the what-if estimations load and encode blocks on demand, they are only served to api key holders
+		public.POST("/chunk/what_if", api.Chunk.WhatIf)
+	}
+	public.GET("/estimator_errors", api.EstimatorError.List)
+	public.GET("/batch/:index/commit_data", api.Batch.CommitData)
+	public.GET("/batch/state_at", api.Batch.StateAt)
+	public.GET("/batch/timeline", api.Batch.Timeline)
+
+	if conf.APIConfig != nil && conf.APIConfig.PreviewSecret != "" {
+		r.GET("/chunk/preview", middleware.BearerAuthMiddleware(conf.APIConfig.PreviewSecret), api.Chunk.Preview)
+	}
+
+	if conf.APIConfig != nil && conf.APIConfig.AdminSecret != "" {
+		admin(r, conf)
+	}
+}
+
+func admin(router *gin.RouterGroup, conf *config.Config) {
+	r := router.Group("/admin")
+
+	r.Use(middleware.BearerAuthMiddleware(conf.APIConfig.AdminSecret))
+	{
+		r.GET("/pause", api.Pause.List)
+		r.PUT("/pause/:operation", api.Pause.Set)
+		r.GET("/proposer_minimum", api.Proposer.Minimum)
+		r.PUT("/proposer_minimum/:proposer", api.Proposer.SetMinimum)
+		r.GET("/commit_diagnoses", api.CommitDiagnosis.List)
+		if api.APIKey != nil {
+			r.PUT("/api_key/:id/tier", api.APIKey.SetTier)
+		}
+	}
+}
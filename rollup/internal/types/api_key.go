@@ -0,0 +1,41 @@
+package types
+
+// APIKeyIssueRequest for /api_key request body
+type APIKeyIssueRequest struct {
+	// Owner is a contact of the key owner, e.g. an email address or a project name.
+	Owner string `json:"owner" binding:"required,max=256"`
+}
+
+// APIKeyIssueSchema the schema data return for /api_key, the key is only returned once
+type APIKeyIssueSchema struct {
+	ID         uint64 `json:"id"`
+	Key        string `json:"key"`
+	Tier       string `json:"tier"`
+	DailyQuota uint64 `json:"daily_quota"`
+}
+
+// APIKeyUsageSchema the schema data return for /api_key/usage
+type APIKeyUsageSchema struct {
+	ID         uint64 `json:"id"`
+	Tier       string `json:"tier"`
+	DailyQuota uint64 `json:"daily_quota"`
+	// Days are the request counts of the last days, ordered by day.
+	Days []*APIKeyDailyUsageSchema `json:"days"`
+}
+
+// APIKeyDailyUsageSchema is the request count of an api key on a day
+type APIKeyDailyUsageSchema struct {
+	// Day is formatted as 2006-01-02, in UTC.
+	Day          string `json:"day"`
+	RequestCount uint64 `json:"request_count"`
+}
+
+// APIKeyTierParameter for /admin/api_key/:id/tier request parameter
+type APIKeyTierParameter struct {
+	ID uint64 `uri:"id" binding:"required"`
+}
+
+// APIKeyTierRequest for /admin/api_key/:id/tier request body
+type APIKeyTierRequest struct {
+	Tier string `json:"tier" binding:"required"`
+}
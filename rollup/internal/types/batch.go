@@ -0,0 +1,76 @@
+package types
+
+import (
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+)
+
+// BatchCommitDataParameter for /batch/:index/commit_data request parameter
+type BatchCommitDataParameter struct {
+	Index uint64 `uri:"index"`
+}
+
+// BatchCommitDataSchema the schema data return for /batch/:index/commit_data
+type BatchCommitDataSchema struct {
+	Index        uint64 `json:"index"`
+	Hash         string `json:"hash"`
+	CommitTxHash string `json:"commit_tx_hash"`
+	// Data is the calldata of the commit transaction sent to layer 1.
+	Data hexutil.Bytes `json:"data"`
+	// ContentHash is the keccak256 hash of Data.
+	ContentHash common.Hash `json:"content_hash"`
+	// BlobVersionedHashes are the versioned hashes of the blobs carried by the commit transaction, if any.
+	BlobVersionedHashes []common.Hash `json:"blob_versioned_hashes,omitempty"`
+}
+
+// BatchStateAtParameter for /batch/state_at request parameter
+type BatchStateAtParameter struct {
+	L1BlockNumber uint64 `form:"l1_block_number" json:"l1_block_number" binding:"required"`
+}
+
+// BatchStateAtSchema the schema data return for /batch/state_at, the indexes are
+// null if no batch was committed or finalized at the layer 1 block.
+type BatchStateAtSchema struct {
+	L1BlockNumber           uint64  `json:"l1_block_number"`
+	LastCommittedBatchIndex *uint64 `json:"last_committed_batch_index"`
+	LastFinalizedBatchIndex *uint64 `json:"last_finalized_batch_index"`
+}
+
+// BatchTimelineParameter for /batch/timeline request parameter, the days are formatted as
+// 2006-01-02 in UTC and both included, EndDay defaults to StartDay.
+type BatchTimelineParameter struct {
+	StartDay string `form:"start_day" json:"start_day" binding:"required"`
+	EndDay   string `form:"end_day" json:"end_day"`
+}
+
+// BatchTimelineDaySchema the batches proposed on a day and their average stage durations in
+// seconds, a stage is averaged over the batches which completed it.
+type BatchTimelineDaySchema struct {
+	Day                    string                 `json:"day"`
+	BatchCount             int                    `json:"batch_count"`
+	FinalizedBatchCount    int                    `json:"finalized_batch_count"`
+	AvgCommitDurationSec   float64                `json:"avg_commit_duration_sec"`
+	AvgProveDurationSec    float64                `json:"avg_prove_duration_sec"`
+	AvgFinalizeDurationSec float64                `json:"avg_finalize_duration_sec"`
+	AvgTotalDurationSec    float64                `json:"avg_total_duration_sec"`
+	Batches                []*BatchTimelineSchema `json:"batches"`
+}
+
+// BatchTimelineSchema the lifecycle of a batch, the timestamps are unix seconds and the stage
+// durations are null until the batch reaches the end of the stage.
+//
+// The commit stage runs from the proposal to the commit, the prove stage from the commit to the
+// proof, zero if the batch was proved before it was committed, the finalize stage from the later
+// of both to the finalization, and the total from the proposal to the finalization.
+type BatchTimelineSchema struct {
+	Index               uint64 `json:"index"`
+	Hash                string `json:"hash"`
+	ProposedAt          int64  `json:"proposed_at"`
+	CommittedAt         *int64 `json:"committed_at"`
+	ProvedAt            *int64 `json:"proved_at"`
+	FinalizedAt         *int64 `json:"finalized_at"`
+	CommitDurationSec   *int64 `json:"commit_duration_sec"`
+	ProveDurationSec    *int64 `json:"prove_duration_sec"`
+	FinalizeDurationSec *int64 `json:"finalize_duration_sec"`
+	TotalDurationSec    *int64 `json:"total_duration_sec"`
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"scroll-tech/common/types"
+
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+// ChunkWhatIfParameter for /chunk/what_if request parameter
+type ChunkWhatIfParameter struct {
+	// StartBlockNumber is a pointer so that the required check accepts block 0.
+	StartBlockNumber *uint64 `form:"start_block_number" json:"start_block_number" binding:"required"`
+	EndBlockNumber   uint64  `form:"end_block_number" json:"end_block_number"`
+	// Blocks are the candidate blocks, they are loaded from database by the block range if empty.
+	Blocks []*types.WrappedBlock `json:"blocks"`
+}
+
+// ChunkWhatIfSchema the schema data return for /chunk/what_if
+type ChunkWhatIfSchema struct {
+	Current  *watcher.ChunkEstimation `json:"current"`
+	Proposed *watcher.ChunkEstimation `json:"proposed"`
+}
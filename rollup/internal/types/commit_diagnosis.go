@@ -0,0 +1,22 @@
+package types
+
+// CommitDiagnosisParameter for /admin/commit_diagnoses request parameter
+type CommitDiagnosisParameter struct {
+	BatchHash string `form:"batch_hash" json:"batch_hash"`
+	Limit     int    `form:"limit" json:"limit"`
+}
+
+// CommitDiagnosisSchema is the diagnosis of a reverted commit tx
+type CommitDiagnosisSchema struct {
+	BatchIndex             uint64 `json:"batch_index"`
+	BatchHash              string `json:"batch_hash"`
+	CommitTxHash           string `json:"commit_tx_hash"`
+	RevertReason           string `json:"revert_reason,omitempty"`
+	LocalParentBatchHash   string `json:"local_parent_batch_hash,omitempty"`
+	OnchainParentBatchHash string `json:"onchain_parent_batch_hash,omitempty"`
+	OnchainBatchHash       string `json:"onchain_batch_hash,omitempty"`
+	Cause                  string `json:"cause"`
+	Detail                 string `json:"detail"`
+	// DiagnosedAt is the unix timestamp of the diagnosis.
+	DiagnosedAt int64 `json:"diagnosed_at"`
+}
@@ -0,0 +1,18 @@
+package types
+
+// EstimatorErrorParameter for /estimator_errors request parameter
+type EstimatorErrorParameter struct {
+	Limit int `form:"limit" json:"limit"`
+}
+
+// EstimatorErrorSchema is the accumulated commit cost estimation error of a transaction type and target
+type EstimatorErrorSchema struct {
+	TxType           uint8   `json:"tx_type"`
+	Target           string  `json:"target"`
+	SampleCount      uint64  `json:"sample_count"`
+	EstimatedSizeSum uint64  `json:"estimated_size_sum"`
+	ActualSizeSum    uint64  `json:"actual_size_sum"`
+	EstimatedGasSum  uint64  `json:"estimated_gas_sum"`
+	ActualGasSum     uint64  `json:"actual_gas_sum"`
+	GasErrorRatio    float64 `json:"gas_error_ratio"`
+}
@@ -0,0 +1,21 @@
+package types
+
+// OperationPauseParameter for /admin/pause/:operation request parameter
+type OperationPauseParameter struct {
+	Operation string `uri:"operation" binding:"required"`
+}
+
+// OperationPauseRequest for /admin/pause/:operation request body
+type OperationPauseRequest struct {
+	Paused bool   `json:"paused"`
+	Reason string `json:"reason"`
+}
+
+// OperationPauseSchema is the pause switch of an operation
+type OperationPauseSchema struct {
+	Operation string `json:"operation"`
+	Paused    bool   `json:"paused"`
+	Reason    string `json:"reason,omitempty"`
+	// UpdatedAt is the unix timestamp of the last switch, zero if the switch was never set.
+	UpdatedAt int64 `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"scroll-tech/rollup/internal/controller/watcher"
+)
+
+// ProposerMinimumParameter for /admin/proposer_minimum/:proposer request parameter
+type ProposerMinimumParameter struct {
+	Proposer string `uri:"proposer" binding:"required,oneof=chunk batch"`
+}
+
+// ProposerMinimumSchema is the min number of blocks per chunk and of chunks per batch proposed at the timeout
+type ProposerMinimumSchema struct {
+	Chunk watcher.ProposalMinimum `json:"chunk"`
+	Batch watcher.ProposalMinimum `json:"batch"`
+}
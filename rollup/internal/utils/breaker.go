@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/ethclient"
+	"github.com/scroll-tech/go-ethereum/rpc"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// ErrCircuitOpen is returned without reaching the endpoint when its circuit is open.
+var ErrCircuitOpen = errors.New("rpc circuit breaker is open")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker opens after failureThreshold consecutive failures, and lets a single
+// probe through once openTimeout has elapsed. The probe result closes or re-opens it.
+type circuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if now.Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		return true
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(success bool, now time.Time) circuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.state = circuitClosed
+		b.failures = 0
+		return b.state
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.failureThreshold {
+		b.state = circuitOpen
+		b.openedAt = now
+	}
+	return b.state
+}
+
+// retryBudget is a token bucket, every request earns ratio tokens and every retry spends one,
+// so retries stay a bounded fraction of the traffic when the endpoints are degraded.
+type retryBudget struct {
+	mu        sync.Mutex
+	ratio     float64
+	maxTokens float64
+	tokens    float64
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RPCGuard dials rpc clients guarded by a circuit breaker per endpoint and a retry budget shared by all of them.
+type RPCGuard struct {
+	cfg    *config.RPCBreakerConfig
+	budget *retryBudget
+
+	rpcCircuitState         *prometheus.GaugeVec
+	rpcCircuitRejectedTotal *prometheus.CounterVec
+	rpcRetryTotal           *prometheus.CounterVec
+	rpcRetryExhaustedTotal  *prometheus.CounterVec
+	rpcNoDeadlineTotal      *prometheus.CounterVec
+}
+
+// NewRPCGuard creates a new RPCGuard, the clients are dialed without guard if cfg is nil.
+// The requests made without a deadline are counted in any case.
+func NewRPCGuard(cfg *config.RPCBreakerConfig, reg prometheus.Registerer) *RPCGuard {
+	g := &RPCGuard{
+		cfg: cfg,
+
+		rpcCircuitState: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rollup_rpc_circuit_state",
+			Help: "The circuit breaker state of the rpc endpoint, 0: closed, 1: open, 2: half-open.",
+		}, []string{"name"}),
+		rpcCircuitRejectedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_rpc_circuit_rejected_total",
+			Help: "Total number of rpc requests rejected by an open circuit breaker.",
+		}, []string{"name"}),
+		rpcRetryTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_rpc_retry_total",
+			Help: "Total number of rpc requests retried within the retry budget.",
+		}, []string{"name"}),
+		rpcRetryExhaustedTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_rpc_retry_budget_exhausted_total",
+			Help: "Total number of failed rpc requests not retried because the retry budget is exhausted.",
+		}, []string{"name"}),
+		rpcNoDeadlineTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_rpc_calls_without_deadline_total",
+			Help: "Total number of rpc requests made with a context without deadline.",
+		}, []string{"name"}),
+	}
+	if cfg != nil {
+		g.budget = &retryBudget{ratio: cfg.RetryRatio, maxTokens: cfg.MaxRetryTokens, tokens: cfg.MaxRetryTokens}
+	}
+	return g
+}
+
+// Dial connects to an endpoint, http endpoints are guarded, the others are dialed as is.
+func (g *RPCGuard) Dial(name, endpoint string) (*ethclient.Client, error) {
+	if !strings.HasPrefix(endpoint, "http") {
+		return ethclient.Dial(endpoint)
+	}
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if g.cfg != nil {
+		transport = &guardedTransport{
+			name: name,
+			base: transport,
+			breaker: &circuitBreaker{
+				failureThreshold: g.cfg.FailureThreshold,
+				openTimeout:      time.Duration(g.cfg.OpenTimeoutSec) * time.Second,
+			},
+			guard: g,
+		}
+	}
+	transport = &deadlineTransport{name: name, base: transport, guard: g}
+	rpcClient, err := rpc.DialHTTPWithClient(endpoint, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcClient), nil
+}
+
+// deadlineTransport counts the requests without deadline, which may block a loop for as long as
+// the endpoint does not answer.
+type deadlineTransport struct {
+	name  string
+	base  http.RoundTripper
+	guard *RPCGuard
+}
+
+func (t *deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := req.Context().Deadline(); !ok {
+		t.guard.rpcNoDeadlineTotal.WithLabelValues(t.name).Inc()
+	}
+	return t.base.RoundTrip(req)
+}
+
+type guardedTransport struct {
+	name    string
+	base    http.RoundTripper
+	breaker *circuitBreaker
+	guard   *RPCGuard
+}
+
+// RoundTrip retries a request failed at the transport level once, if the retry budget allows it.
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.guard.budget.deposit()
+
+	resp, err := t.roundTrip(req)
+	if err == nil || errors.Is(err, ErrCircuitOpen) || req.GetBody == nil || req.Context().Err() != nil {
+		return resp, err
+	}
+	if !t.guard.budget.withdraw() {
+		t.guard.rpcRetryExhaustedTotal.WithLabelValues(t.name).Inc()
+		return resp, err
+	}
+
+	body, bodyErr := req.GetBody()
+	if bodyErr != nil {
+		return resp, err
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	t.guard.rpcRetryTotal.WithLabelValues(t.name).Inc()
+	return t.roundTrip(retry)
+}
+
+func (t *guardedTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if !t.breaker.allow(time.Now()) {
+		t.guard.rpcCircuitRejectedTotal.WithLabelValues(t.name).Inc()
+		return nil, fmt.Errorf("%w, endpoint: %v", ErrCircuitOpen, t.name)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	success := err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests
+	state := t.breaker.record(success, time.Now())
+	t.guard.rpcCircuitState.WithLabelValues(t.name).Set(float64(state))
+	return resp, err
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := &circuitBreaker{failureThreshold: 2, openTimeout: time.Minute}
+	now := time.Now()
+
+	assert.True(t, b.allow(now))
+	assert.Equal(t, circuitClosed, b.record(false, now))
+	assert.Equal(t, circuitOpen, b.record(false, now))
+	assert.False(t, b.allow(now.Add(time.Second)))
+
+	// a single probe is let through once the open timeout elapsed
+	assert.True(t, b.allow(now.Add(time.Minute)))
+	assert.False(t, b.allow(now.Add(time.Minute)))
+	assert.Equal(t, circuitOpen, b.record(false, now.Add(time.Minute)))
+
+	assert.True(t, b.allow(now.Add(2*time.Minute)))
+	assert.Equal(t, circuitClosed, b.record(true, now.Add(2*time.Minute)))
+	assert.True(t, b.allow(now.Add(2*time.Minute)))
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := &retryBudget{ratio: 0.5, maxTokens: 1, tokens: 1}
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+
+	b.deposit()
+	assert.False(t, b.withdraw())
+	b.deposit()
+	b.deposit()
+	b.deposit()
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+}
+
+func TestRPCGuardDial(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := &config.RPCBreakerConfig{FailureThreshold: 2, OpenTimeoutSec: 60, RetryRatio: 0.1, MaxRetryTokens: 10}
+	client, err := NewRPCGuard(cfg, prometheus.NewRegistry()).Dial("test", server.URL)
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.ChainID(context.Background())
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	_, err = client.ChainID(context.Background())
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), ErrCircuitOpen.Error()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestRPCGuardNoDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	g := NewRPCGuard(nil, prometheus.NewRegistry())
+	client, err := g.Dial("test", server.URL)
+	assert.NoError(t, err)
+
+	_, err = client.ChainID(context.Background())
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err = client.ChainID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(g.rpcNoDeadlineTotal.WithLabelValues("test")))
+}
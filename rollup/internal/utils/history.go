@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrMissingHistory is returned when the endpoint pruned the logs, receipts or state of the requested blocks.
+var ErrMissingHistory = errors.New("missing history")
+
+// missingHistoryMessages are the error messages of the clients that don't keep the history of old blocks.
+var missingHistoryMessages = []string{
+	"pruned",              // erigon, nethermind and reth
+	"missing trie node",   // geth without the state of the block
+	"history unavailable", // providers serving the recent blocks only
+	"historical state",    // geth "historical state ... is not available"
+	"receipts not found",  // geth with the receipts of the ancient blocks dropped
+	"ancient block",       // geth with the ancient blocks dropped
+}
+
+// IsMissingHistoryError reports whether err is the response of an endpoint that pruned the history of the
+// requested blocks, which an endpoint keeping the full history answers.
+func IsMissingHistoryError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrMissingHistory) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, missingHistoryMessage := range missingHistoryMessages {
+		if strings.Contains(msg, missingHistoryMessage) {
+			return true
+		}
+	}
+	return false
+}
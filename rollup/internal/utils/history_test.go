@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMissingHistoryError(t *testing.T) {
+	assert.False(t, IsMissingHistoryError(nil))
+	assert.False(t, IsMissingHistoryError(errors.New("connection refused")))
+	assert.False(t, IsMissingHistoryError(errors.New("header not found")))
+
+	assert.True(t, IsMissingHistoryError(ErrMissingHistory))
+	assert.True(t, IsMissingHistoryError(fmt.Errorf("failed to filter logs: %w", ErrMissingHistory)))
+	assert.True(t, IsMissingHistoryError(errors.New("block #100 has been pruned")))
+	assert.True(t, IsMissingHistoryError(errors.New("missing trie node 0x1234 (path )")))
+	assert.True(t, IsMissingHistoryError(errors.New("Pruned history unavailable")))
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"scroll-tech/rollup/internal/orm"
+)
+
+// pauseRefreshInterval bounds how long a pause switch set through the admin api takes to apply.
+const pauseRefreshInterval = 5 * time.Second
+
+// Pauser skips the paused operations, the pause switches are persisted in the database
+// so they are shared by all services and survive restarts.
+type Pauser struct {
+	operationPauseOrm *orm.OperationPause
+
+	mu          sync.Mutex
+	paused      map[string]bool
+	refreshedAt time.Time
+
+	operationPaused                   *prometheus.GaugeVec
+	operationPausedSkipTotal          *prometheus.CounterVec
+	operationPauseRefreshFailureTotal prometheus.Counter
+}
+
+// NewPauser creates a new Pauser instance.
+func NewPauser(db *gorm.DB, reg prometheus.Registerer) *Pauser {
+	return &Pauser{
+		operationPauseOrm: orm.NewOperationPause(db),
+		paused:            make(map[string]bool),
+
+		operationPaused: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rollup_operation_paused",
+			Help: "Whether the operation is paused, 0: running, 1: paused.",
+		}, []string{"operation"}),
+		operationPausedSkipTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_operation_paused_skip_total",
+			Help: "Total number of operation rounds skipped because the operation is paused.",
+		}, []string{"operation"}),
+		operationPauseRefreshFailureTotal: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "rollup_operation_pause_refresh_failure_total",
+			Help: "Total number of failures to load the pause switches from the database.",
+		}),
+	}
+}
+
+// IsPaused returns whether the operation is paused, the last known switches are kept if they fail to load.
+func (p *Pauser) IsPaused(ctx context.Context, operation string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.refreshedAt) >= pauseRefreshInterval {
+		p.refresh(ctx)
+	}
+	return p.paused[operation]
+}
+
+func (p *Pauser) refresh(ctx context.Context) {
+	operationPauses, err := p.operationPauseOrm.GetOperationPauses(ctx)
+	if err != nil {
+		p.operationPauseRefreshFailureTotal.Inc()
+		log.Error("failed to load operation pause switches", "err", err)
+		return
+	}
+
+	p.refreshedAt = time.Now()
+	for _, operationPause := range operationPauses {
+		if p.paused[operationPause.Operation] != operationPause.Paused {
+			log.Info("operation pause switch changed", "operation", operationPause.Operation, "paused", operationPause.Paused, "reason", operationPause.Reason)
+		}
+		p.paused[operationPause.Operation] = operationPause.Paused
+		if operationPause.Paused {
+			p.operationPaused.WithLabelValues(operationPause.Operation).Set(1)
+		} else {
+			p.operationPaused.WithLabelValues(operationPause.Operation).Set(0)
+		}
+	}
+}
+
+// Guard wraps fn so it is skipped while the operation is paused.
+func (p *Pauser) Guard(operation string, fn func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		if p.IsPaused(ctx, operation) {
+			p.operationPausedSkipTotal.WithLabelValues(operation).Inc()
+			log.Debug("operation is paused, skip", "operation", operation)
+			return
+		}
+		fn(ctx)
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/scroll-tech/go-ethereum/common"
+)
+
+// ErrQuorumNotReached is returned when not enough endpoints agree on a result.
+var ErrQuorumNotReached = errors.New("quorum not reached")
+
+// ContractCaller is the subset of the eth client used by quorum reads.
+type ContractCaller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// QuorumResult is the agreed result of a quorum read.
+type QuorumResult struct {
+	Data []byte
+	// Agreed is the number of endpoints that returned Data.
+	Agreed int
+	// Responded is the number of endpoints that returned without error.
+	Responded int
+	// Diverged reports whether any endpoint returned a different result.
+	Diverged bool
+}
+
+// QuorumCaller executes the same call against multiple endpoints and requires
+// at least threshold of them to return identical results.
+type QuorumCaller struct {
+	callers   []ContractCaller
+	threshold int
+}
+
+// NewQuorumCaller creates a QuorumCaller, a threshold of 0 means a simple majority.
+func NewQuorumCaller(callers []ContractCaller, threshold int) (*QuorumCaller, error) {
+	if len(callers) == 0 {
+		return nil, errors.New("no endpoints for quorum reads")
+	}
+	if threshold == 0 {
+		threshold = len(callers)/2 + 1
+	}
+	if threshold < 0 || threshold > len(callers) {
+		return nil, fmt.Errorf("invalid quorum threshold %d for %d endpoints", threshold, len(callers))
+	}
+	return &QuorumCaller{callers: callers, threshold: threshold}, nil
+}
+
+// CallContract executes msg on all endpoints concurrently and returns the result
+// agreed on by at least threshold endpoints.
+func (q *QuorumCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (*QuorumResult, error) {
+	results := make([][]byte, len(q.callers))
+	errs := make([]error, len(q.callers))
+
+	var wg sync.WaitGroup
+	for i, caller := range q.callers {
+		wg.Add(1)
+		go func(i int, caller ContractCaller) {
+			defer wg.Done()
+			results[i], errs[i] = caller.CallContract(ctx, msg, blockNumber)
+		}(i, caller)
+	}
+	wg.Wait()
+
+	votes := make(map[string]int)
+	var responded int
+	var best string
+	for i, data := range results {
+		if errs[i] != nil {
+			continue
+		}
+		responded++
+		key := common.Bytes2Hex(data)
+		votes[key]++
+		if votes[key] > votes[best] {
+			best = key
+		}
+	}
+
+	result := &QuorumResult{
+		Data:      common.Hex2Bytes(best),
+		Agreed:    votes[best],
+		Responded: responded,
+		Diverged:  len(votes) > 1,
+	}
+	if result.Agreed < q.threshold {
+		return result, fmt.Errorf("%w: %d of %d endpoints agreed, %d responded, threshold: %d", ErrQuorumNotReached, result.Agreed, len(q.callers), responded, q.threshold)
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockContractCaller struct {
+	data []byte
+	err  error
+}
+
+func (m mockContractCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return m.data, m.err
+}
+
+func TestNewQuorumCaller(t *testing.T) {
+	_, err := NewQuorumCaller(nil, 0)
+	assert.Error(t, err)
+
+	callers := []ContractCaller{mockContractCaller{}, mockContractCaller{}, mockContractCaller{}}
+	q, err := NewQuorumCaller(callers, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, q.threshold)
+
+	_, err = NewQuorumCaller(callers, 4)
+	assert.Error(t, err)
+}
+
+func TestQuorumCallerCallContract(t *testing.T) {
+	a := mockContractCaller{data: []byte{0x01}}
+	b := mockContractCaller{data: []byte{0x02}}
+	failed := mockContractCaller{err: errors.New("connection refused")}
+
+	testCases := []struct {
+		name      string
+		callers   []ContractCaller
+		threshold int
+		data      []byte
+		diverged  bool
+		err       error
+	}{
+		{"all agree", []ContractCaller{a, a, a}, 0, []byte{0x01}, false, nil},
+		{"majority agree", []ContractCaller{a, b, a}, 0, []byte{0x01}, true, nil},
+		{"one endpoint down", []ContractCaller{a, failed, a}, 0, []byte{0x01}, false, nil},
+		{"unanimity required", []ContractCaller{a, b, a}, 3, []byte{0x01}, true, ErrQuorumNotReached},
+		{"no majority", []ContractCaller{a, b, failed}, 0, []byte{0x01}, true, ErrQuorumNotReached},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := NewQuorumCaller(tc.callers, tc.threshold)
+			assert.NoError(t, err)
+
+			result, err := q.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.data, result.Data)
+			assert.Equal(t, tc.diverged, result.Diverged)
+		})
+	}
+}
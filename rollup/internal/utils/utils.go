@@ -63,3 +63,31 @@ func UnpackLog(c *abi.ABI, out interface{}, event string, log types.Log) error {
 	}
 	return abi.ParseTopics(out, indexed, log.Topics[1:])
 }
+
+const (
+	// minBlobGasPrice is the minimum blob gas price defined in EIP-4844.
+	minBlobGasPrice = 1
+	// blobGasPriceUpdateFraction controls the maximum rate of change for blob gas price, as defined in EIP-4844.
+	blobGasPriceUpdateFraction = 3338477
+)
+
+// CalcBlobBaseFee calculates the blob base fee from the header's excess blob gas field (EIP-4844).
+func CalcBlobBaseFee(excessBlobGas uint64) *big.Int {
+	return fakeExponential(big.NewInt(minBlobGasPrice), new(big.Int).SetUint64(excessBlobGas), big.NewInt(blobGasPriceUpdateFraction))
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using Taylor expansion.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
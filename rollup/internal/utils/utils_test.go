@@ -47,3 +47,18 @@ func TestBufferToUint256Le(t *testing.T) {
 	result := BufferToUint256Le(input)
 	assert.Equal(t, expectedOutput, result)
 }
+
+func TestCalcBlobBaseFee(t *testing.T) {
+	tests := []struct {
+		excessBlobGas uint64
+		blobBaseFee   int64
+	}{
+		{0, 1},
+		{2314057, 1},
+		{2314058, 2},
+		{10 * 1024 * 1024, 23},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, big.NewInt(tt.blobBaseFee), CalcBlobBaseFee(tt.excessBlobGas), "excessBlobGas: %d", tt.excessBlobGas)
+	}
+}
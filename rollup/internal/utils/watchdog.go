@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/scroll-tech/go-ethereum/log"
+
+	"scroll-tech/common/utils"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+// ExitCodeStuckLoop is the exit code of a process stopped by the watchdog because of a stuck loop.
+const ExitCodeStuckLoop = 3
+
+// Watchdog supervises the internal loops: each round of a loop reports its progress, and a loop
+// whose round has not returned within its deadline is either restarted or makes the process exit.
+type Watchdog struct {
+	cfg  *config.WatchdogConfig
+	exit func(code int)
+
+	mu    sync.Mutex
+	loops map[*supervisedLoop]struct{}
+
+	watchdogLoopLastProgress *prometheus.GaugeVec
+	watchdogLoopStuckTotal   *prometheus.CounterVec
+}
+
+type supervisedLoop struct {
+	name     string
+	deadline time.Duration
+	// honoursContext is set for the loops of LoopWithContext, whose rounds return once their context is cancelled.
+	honoursContext bool
+
+	running        bool
+	roundStartedAt time.Time
+	cancelRound    context.CancelFunc
+	// cancelledAt is set when the running round is cancelled to restart the loop.
+	cancelledAt time.Time
+}
+
+// NewWatchdog creates a new Watchdog instance, the loops are not supervised if cfg is nil.
+func NewWatchdog(ctx context.Context, cfg *config.WatchdogConfig, reg prometheus.Registerer) *Watchdog {
+	w := &Watchdog{
+		cfg:   cfg,
+		exit:  os.Exit,
+		loops: make(map[*supervisedLoop]struct{}),
+
+		watchdogLoopLastProgress: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "rollup_watchdog_loop_last_progress_timestamp",
+			Help: "The unix timestamp of the last completed round of the loop.",
+		}, []string{"loop"}),
+		watchdogLoopStuckTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rollup_watchdog_loop_stuck_total",
+			Help: "Total number of rounds which did not return within the deadline of the loop.",
+		}, []string{"loop"}),
+	}
+	if cfg != nil {
+		go w.supervise(ctx, time.Duration(cfg.CheckIntervalSec)*time.Second)
+	}
+	return w
+}
+
+func (w *Watchdog) supervise(ctx context.Context, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			w.check()
+		}
+	}
+}
+
+// Loop runs fn periodically like utils.Loop, under the supervision of the watchdog. The round of fn
+// can't be cancelled, so a stuck round makes the process exit whatever the action of the watchdog.
+func (w *Watchdog) Loop(ctx context.Context, name string, period time.Duration, fn func()) {
+	w.loop(ctx, name, period, func(context.Context) { fn() }, false)
+}
+
+// LoopWithContext runs fn periodically like Loop, each round is given a context expiring at the
+// deadline of the loop, so the db and rpc calls made with it are cancelled instead of getting the
+// round stuck. The context of a round is ctx itself if the loops are not supervised.
+//
+// Restarting the loop cancels the context of the stuck round and the next round starts once it
+// returns, so two rounds of the loop never overlap. A round still running a check interval after
+// its cancellation doesn't honour its context and makes the process exit.
+func (w *Watchdog) LoopWithContext(ctx context.Context, name string, period time.Duration, fn func(ctx context.Context)) {
+	w.loop(ctx, name, period, fn, true)
+}
+
+func (w *Watchdog) loop(ctx context.Context, name string, period time.Duration, fn func(ctx context.Context), honoursContext bool) {
+	if w.cfg == nil {
+		utils.LoopWithContext(ctx, period, fn)
+		return
+	}
+
+	l := &supervisedLoop{
+		name:           name,
+		deadline:       w.deadline(name),
+		honoursContext: honoursContext,
+	}
+
+	w.mu.Lock()
+	w.loops[l] = struct{}{}
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.loops, l)
+		w.mu.Unlock()
+	}()
+
+	tick := time.NewTicker(period)
+	defer tick.Stop()
+	for ; ; <-tick.C {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		roundCtx, cancel := context.WithTimeout(ctx, l.deadline)
+		w.beginRound(l, cancel)
+		fn(roundCtx)
+		cancel()
+		w.endRound(l)
+	}
+}
+
+func (w *Watchdog) deadline(name string) time.Duration {
+	deadline := w.cfg.DeadlineSec
+	if loopDeadline, ok := w.cfg.LoopDeadlineSec[name]; ok {
+		deadline = loopDeadline
+	}
+	return time.Duration(deadline) * time.Second
+}
+
+func (w *Watchdog) beginRound(l *supervisedLoop, cancel context.CancelFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	l.running = true
+	l.roundStartedAt = time.Now()
+	l.cancelRound = cancel
+	l.cancelledAt = time.Time{}
+}
+
+func (w *Watchdog) endRound(l *supervisedLoop) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !l.cancelledAt.IsZero() {
+		log.Info("cancelled round of a restarted loop returned", "loop", l.name, "took", time.Since(l.roundStartedAt))
+	}
+	l.running = false
+	l.cancelRound = nil
+	w.watchdogLoopLastProgress.WithLabelValues(l.name).Set(float64(time.Now().Unix()))
+}
+
+// check applies the configured action to the loops whose round exceeded its deadline. A loop is only
+// restarted if its round honours its context and returns once cancelled, otherwise the process exits.
+func (w *Watchdog) check() {
+	w.mu.Lock()
+	var stuck []string
+	exit := false
+	for l := range w.loops {
+		if !l.running || time.Since(l.roundStartedAt) <= l.deadline {
+			continue
+		}
+		stuck = append(stuck, l.name)
+
+		if !l.cancelledAt.IsZero() {
+			log.Error("cancelled round of the loop did not return", "loop", l.name, "cancelled for", time.Since(l.cancelledAt))
+			exit = true
+			continue
+		}
+		w.watchdogLoopStuckTotal.WithLabelValues(l.name).Inc()
+		restart := w.cfg.Action == config.WatchdogActionRestart && l.honoursContext
+		log.Error("loop is stuck", "loop", l.name, "stuck for", time.Since(l.roundStartedAt), "deadline", l.deadline, "action", w.cfg.Action, "restart", restart)
+		if !restart {
+			exit = true
+			continue
+		}
+		l.cancelledAt = time.Now()
+		l.cancelRound()
+	}
+	w.mu.Unlock()
+
+	if len(stuck) == 0 {
+		return
+	}
+
+	// the stuck rounds are still running, so the dump shows where they are stuck
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 2); err != nil {
+		log.Warn("failed to dump goroutines", "err", err)
+	}
+	log.Error("goroutine dump of the stuck loops", "loops", stuck, "goroutines", goroutines.String())
+
+	if exit {
+		log.Error("exiting because of stuck loops", "loops", stuck, "exit code", ExitCodeStuckLoop)
+		w.exit(ExitCodeStuckLoop)
+	}
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/rollup/internal/config"
+)
+
+func TestWatchdogRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &config.WatchdogConfig{
+		DeadlineSec:      3600,
+		LoopDeadlineSec:  map[string]uint64{"stuck": 0},
+		CheckIntervalSec: 3600,
+		Action:           config.WatchdogActionRestart,
+	}
+	w := NewWatchdog(ctx, cfg, prometheus.NewRegistry())
+	exitCode := int64(-1)
+	w.exit = func(code int) { atomic.StoreInt64(&exitCode, int64(code)) }
+
+	// the first round is stuck until released, then lingers after its cancellation before returning
+	var calls, active, overlaps int64
+	release := make(chan struct{})
+	go w.LoopWithContext(ctx, "stuck", time.Millisecond, func(roundCtx context.Context) {
+		if atomic.AddInt64(&active, 1) > 1 {
+			atomic.AddInt64(&overlaps, 1)
+		}
+		defer atomic.AddInt64(&active, -1)
+		if atomic.AddInt64(&calls, 1) == 1 {
+			<-release
+			<-roundCtx.Done()
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&calls) == 1 }, time.Second, time.Millisecond)
+
+	// the stuck round is cancelled, the next round waits for it to return
+	w.check()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	close(release)
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&calls) > 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&overlaps))
+	assert.Equal(t, int64(-1), atomic.LoadInt64(&exitCode))
+}
+
+func TestWatchdogRestartRoundIgnoringContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &config.WatchdogConfig{
+		DeadlineSec:      0,
+		CheckIntervalSec: 3600,
+		Action:           config.WatchdogActionRestart,
+	}
+	w := NewWatchdog(ctx, cfg, prometheus.NewRegistry())
+	exitCode := int64(-1)
+	w.exit = func(code int) { atomic.StoreInt64(&exitCode, int64(code)) }
+
+	release := make(chan struct{})
+	defer close(release)
+	var calls int64
+	started := make(chan struct{}, 1)
+	go w.LoopWithContext(ctx, "stuck", time.Millisecond, func(context.Context) {
+		atomic.AddInt64(&calls, 1)
+		started <- struct{}{}
+		<-release
+	})
+	<-started
+
+	// the round is cancelled first, and exits the process once it didn't return by the next check
+	w.check()
+	assert.Equal(t, int64(-1), atomic.LoadInt64(&exitCode))
+	w.check()
+	assert.Equal(t, int64(ExitCodeStuckLoop), atomic.LoadInt64(&exitCode))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// a loop whose round takes no context is never restarted
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	w = NewWatchdog(ctx2, cfg, prometheus.NewRegistry())
+	atomic.StoreInt64(&exitCode, -1)
+	w.exit = func(code int) { atomic.StoreInt64(&exitCode, int64(code)) }
+	go w.Loop(ctx2, "stuck", time.Millisecond, func() {
+		started <- struct{}{}
+		<-release
+	})
+	<-started
+	w.check()
+	assert.Equal(t, int64(ExitCodeStuckLoop), atomic.LoadInt64(&exitCode))
+}
+
+func TestWatchdogExit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &config.WatchdogConfig{
+		DeadlineSec:      0,
+		CheckIntervalSec: 3600,
+		Action:           config.WatchdogActionExit,
+	}
+	w := NewWatchdog(ctx, cfg, prometheus.NewRegistry())
+	exitCode := int64(-1)
+	w.exit = func(code int) { atomic.StoreInt64(&exitCode, int64(code)) }
+
+	w.check()
+	assert.Equal(t, int64(-1), atomic.LoadInt64(&exitCode))
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	go w.Loop(ctx, "stuck", 10*time.Millisecond, func() {
+		started <- struct{}{}
+		<-release
+	})
+	<-started
+
+	w.check()
+	assert.Equal(t, int64(ExitCodeStuckLoop), atomic.LoadInt64(&exitCode))
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := NewWatchdog(ctx, nil, prometheus.NewRegistry())
+
+	var calls int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Loop(ctx, "loop", 10*time.Millisecond, func() { atomic.AddInt64(&calls, 1) })
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&calls) > 1 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestWatchdogRoundDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &config.WatchdogConfig{
+		DeadlineSec:      3600,
+		LoopDeadlineSec:  map[string]uint64{"short": 60},
+		CheckIntervalSec: 3600,
+		Action:           config.WatchdogActionRestart,
+	}
+	w := NewWatchdog(ctx, cfg, prometheus.NewRegistry())
+
+	deadlines := make(chan time.Time, 1)
+	go w.LoopWithContext(ctx, "short", 10*time.Millisecond, func(roundCtx context.Context) {
+		deadline, ok := roundCtx.Deadline()
+		assert.True(t, ok)
+		select {
+		case deadlines <- deadline:
+		default:
+		}
+	})
+	deadline := <-deadlines
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+}
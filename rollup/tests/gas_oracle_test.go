@@ -38,7 +38,7 @@ func testImportL1GasPrice(t *testing.T) {
 	number, err := l1Client.BlockNumber(context.Background())
 	assert.Greater(t, number, startHeight-1)
 	assert.NoError(t, err)
-	err = l1Watcher.FetchBlockHeader(number)
+	err = l1Watcher.FetchBlockHeader(context.Background(), number)
 	assert.NoError(t, err)
 
 	l1BlockOrm := orm.NewL1Block(db)
@@ -53,7 +53,7 @@ func testImportL1GasPrice(t *testing.T) {
 	assert.Equal(t, types.GasOracleStatus(blocks[0].GasOracleStatus), types.GasOraclePending)
 
 	// relay gas price
-	l1Relayer.ProcessGasPriceOracle()
+	l1Relayer.ProcessGasPriceOracle(context.Background())
 	blocks, err = l1BlockOrm.GetL1Blocks(context.Background(), map[string]interface{}{"number": latestBlockHeight})
 	assert.NoError(t, err)
 	assert.Equal(t, len(blocks), 1)
@@ -67,7 +67,7 @@ func testImportL2GasPrice(t *testing.T) {
 	prepareContracts(t)
 
 	l2Cfg := rollupApp.Config.L2Config
-	l2Relayer, err := relayer.NewLayer2Relayer(context.Background(), l2Client, db, l2Cfg.RelayerConfig, false, relayer.ServiceTypeL2GasOracle, nil)
+	l2Relayer, err := relayer.NewLayer2Relayer(context.Background(), l2Client, db, l2Cfg.RelayerConfig, nil, false, relayer.ServiceTypeL2GasOracle, nil)
 	assert.NoError(t, err)
 
 	// add fake chunk
@@ -107,7 +107,7 @@ func testImportL2GasPrice(t *testing.T) {
 	assert.Equal(t, types.GasOracleStatus(batch.OracleStatus), types.GasOraclePending)
 
 	// relay gas price
-	l2Relayer.ProcessGasPriceOracle()
+	l2Relayer.ProcessGasPriceOracle(context.Background())
 	batch, err = batchOrm.GetLatestBatch(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, batch)
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/types"
+	"scroll-tech/common/types/message"
+	"scroll-tech/common/utils"
+
+	bridgeAbi "scroll-tech/rollup/abi"
+	"scroll-tech/rollup/internal/config"
+	"scroll-tech/rollup/internal/controller/relayer"
+	"scroll-tech/rollup/internal/controller/watcher"
+	"scroll-tech/rollup/internal/orm"
+)
+
+// TestInMemoryPipeline runs watcher -> proposer -> prover -> relayer in a single process.
+// Layer 1 is replaced by a simulated node and proofs are produced by a mock prover,
+// only the database is external.
+func TestInMemoryPipeline(t *testing.T) {
+	base.RunDBImage(t)
+	db := setupDB(t)
+	defer database.CloseDB(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l1 := newSimulatedL1(t, big.NewInt(31337))
+	defer l1.Close()
+
+	relayerCfg := *rollupApp.Config.L2Config.RelayerConfig
+	senderCfg := *relayerCfg.SenderConfig
+	senderCfg.Endpoint = l1.Endpoint()
+	senderCfg.TxType = "LegacyTx"
+	senderCfg.Confirmations = rpc.LatestBlockNumber
+	senderCfg.CheckPendingTime = 1
+	relayerCfg.SenderConfig = &senderCfg
+	relayerCfg.ChainMonitor = &config.ChainMonitor{}
+	relayerCfg.EnableTestEnvBypassFeatures = false
+
+	l2Relayer, err := relayer.NewLayer2Relayer(ctx, nil, db, &relayerCfg, nil, false, relayer.ServiceTypeL2RollupRelayer, nil)
+	assert.NoError(t, err)
+
+	// watcher: persist l2 blocks.
+	var wrappedBlocks []*types.WrappedBlock
+	for i := 0; i < 10; i++ {
+		header := gethTypes.Header{
+			Number:     big.NewInt(int64(i)),
+			ParentHash: common.Hash{},
+			Difficulty: big.NewInt(0),
+			BaseFee:    big.NewInt(0),
+		}
+		wrappedBlocks = append(wrappedBlocks, &types.WrappedBlock{
+			Header:         &header,
+			Transactions:   nil,
+			WithdrawRoot:   common.Hash{},
+			RowConsumption: &gethTypes.RowConsumption{},
+		})
+	}
+	l2BlockOrm := orm.NewL2Block(db)
+	assert.NoError(t, l2BlockOrm.InsertL2Blocks(ctx, wrappedBlocks))
+
+	// proposer: pack blocks into a chunk and the chunk into a batch.
+	cp := watcher.NewChunkProposer(ctx, &config.ChunkProposerConfig{
+		MaxBlockNumPerChunk:             100,
+		MaxTxNumPerChunk:                10000,
+		MaxL1CommitGasPerChunk:          50000000000,
+		MaxL1CommitCalldataSizePerChunk: 1000000,
+		MaxRowConsumptionPerChunk:       1048319,
+		ChunkTimeoutSec:                 300,
+	}, nil, db, nil)
+	cp.TryProposeChunk(ctx)
+
+	bp := watcher.NewBatchProposer(&config.BatchProposerConfig{
+		MaxChunkNumPerBatch:             10,
+		MaxL1CommitGasPerBatch:          50000000000,
+		MaxL1CommitCalldataSizePerBatch: 1000000,
+		BatchTimeoutSec:                 300,
+	}, nil, db, nil)
+	bp.TryProposeBatch(ctx)
+
+	chunkOrm := orm.NewChunk(db)
+	chunks, err := chunkOrm.GetChunksGEIndex(ctx, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+
+	batchOrm := orm.NewBatch(db)
+	batch, err := batchOrm.GetLatestBatch(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, batch)
+	batchHash := batch.Hash
+
+	// relayer: commit the batch to the simulated layer 1.
+	l2Relayer.ProcessPendingBatches(ctx)
+	assert.True(t, utils.TryTimes(30, func() bool {
+		statuses, getErr := batchOrm.GetRollupStatusByHashList(ctx, []string{batchHash})
+		return getErr == nil && len(statuses) == 1 && statuses[0] == types.RollupCommitted
+	}))
+
+	// mock prover: prove the chunk and the batch.
+	assert.NoError(t, chunkOrm.UpdateProvingStatus(ctx, chunks[0].Hash, types.ProvingTaskVerified))
+
+	batchProof := &message.BatchProof{
+		Proof: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31},
+	}
+	assert.NoError(t, batchOrm.UpdateProofByHash(ctx, batchHash, batchProof, 100))
+	assert.NoError(t, batchOrm.UpdateProvingStatus(ctx, batchHash, types.ProvingTaskVerified))
+
+	// relayer: finalize the batch with its proof.
+	l2Relayer.ProcessCommittedBatches(ctx)
+	assert.True(t, utils.TryTimes(30, func() bool {
+		statuses, getErr := batchOrm.GetRollupStatusByHashList(ctx, []string{batchHash})
+		return getErr == nil && len(statuses) == 1 && statuses[0] == types.RollupFinalized
+	}))
+
+	// layer 1 received exactly one commit and one finalize transaction.
+	txs := l1.Transactions()
+	assert.Len(t, txs, 2)
+	var methods []string
+	for _, tx := range txs {
+		method, methodErr := bridgeAbi.ScrollChainABI.MethodById(tx.Data())
+		assert.NoError(t, methodErr)
+		methods = append(methods, method.Name)
+	}
+	assert.Equal(t, []string{"commitBatch", "finalizeBatchWithProof"}, methods)
+}
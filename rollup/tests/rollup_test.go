@@ -27,7 +27,7 @@ func testCommitAndFinalizeGenesisBatch(t *testing.T) {
 	prepareContracts(t)
 
 	l2Cfg := rollupApp.Config.L2Config
-	l2Relayer, err := relayer.NewLayer2Relayer(context.Background(), l2Client, db, l2Cfg.RelayerConfig, true, relayer.ServiceTypeL2RollupRelayer, nil)
+	l2Relayer, err := relayer.NewLayer2Relayer(context.Background(), l2Client, db, l2Cfg.RelayerConfig, nil, true, relayer.ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, l2Relayer)
 
@@ -56,7 +56,7 @@ func testCommitBatchAndFinalizeBatch(t *testing.T) {
 
 	// Create L2Relayer
 	l2Cfg := rollupApp.Config.L2Config
-	l2Relayer, err := relayer.NewLayer2Relayer(context.Background(), l2Client, db, l2Cfg.RelayerConfig, false, relayer.ServiceTypeL2RollupRelayer, nil)
+	l2Relayer, err := relayer.NewLayer2Relayer(context.Background(), l2Client, db, l2Cfg.RelayerConfig, nil, false, relayer.ServiceTypeL2RollupRelayer, nil)
 	assert.NoError(t, err)
 
 	// Create L1Watcher
@@ -91,8 +91,8 @@ func testCommitBatchAndFinalizeBatch(t *testing.T) {
 		MaxL1CommitCalldataSizePerChunk: 1000000,
 		MaxRowConsumptionPerChunk:       1048319,
 		ChunkTimeoutSec:                 300,
-	}, db, nil)
-	cp.TryProposeChunk()
+	}, nil, db, nil)
+	cp.TryProposeChunk(context.Background())
 
 	batchOrm := orm.NewBatch(db)
 	unbatchedChunkIndex, err := batchOrm.GetFirstUnbatchedChunkIndex(context.Background())
@@ -103,15 +103,15 @@ func testCommitBatchAndFinalizeBatch(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, chunks, 1)
 
-	bp := watcher.NewBatchProposer(context.Background(), &config.BatchProposerConfig{
+	bp := watcher.NewBatchProposer(&config.BatchProposerConfig{
 		MaxChunkNumPerBatch:             10,
 		MaxL1CommitGasPerBatch:          50000000000,
 		MaxL1CommitCalldataSizePerBatch: 1000000,
 		BatchTimeoutSec:                 300,
-	}, db, nil)
-	bp.TryProposeBatch()
+	}, nil, db, nil)
+	bp.TryProposeBatch(context.Background())
 
-	l2Relayer.ProcessPendingBatches()
+	l2Relayer.ProcessPendingBatches(context.Background())
 
 	batch, err := batchOrm.GetLatestBatch(context.Background())
 	assert.NoError(t, err)
@@ -129,7 +129,7 @@ func testCommitBatchAndFinalizeBatch(t *testing.T) {
 
 	// fetch rollup events
 	success = utils.TryTimes(30, func() bool {
-		err = l1Watcher.FetchContractEvent()
+		err = l1Watcher.FetchContractEvent(context.Background())
 		assert.NoError(t, err)
 		var statuses []types.RollupStatus
 		statuses, err = batchOrm.GetRollupStatusByHashList(context.Background(), []string{batchHash})
@@ -147,7 +147,7 @@ func testCommitBatchAndFinalizeBatch(t *testing.T) {
 	assert.NoError(t, err)
 
 	// process committed batch and check status
-	l2Relayer.ProcessCommittedBatches()
+	l2Relayer.ProcessCommittedBatches(context.Background())
 
 	statuses, err := batchOrm.GetRollupStatusByHashList(context.Background(), []string{batchHash})
 	assert.NoError(t, err)
@@ -168,7 +168,7 @@ func testCommitBatchAndFinalizeBatch(t *testing.T) {
 
 	// fetch rollup events
 	success = utils.TryTimes(30, func() bool {
-		err = l1Watcher.FetchContractEvent()
+		err = l1Watcher.FetchContractEvent(context.Background())
 		assert.NoError(t, err)
 		var statuses []types.RollupStatus
 		statuses, err = batchOrm.GetRollupStatusByHashList(context.Background(), []string{batchHash})
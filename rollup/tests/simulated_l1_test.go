@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/scroll-tech/go-ethereum/common"
+	"github.com/scroll-tech/go-ethereum/common/hexutil"
+	gethTypes "github.com/scroll-tech/go-ethereum/core/types"
+	"github.com/scroll-tech/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// simulatedL1 is an in-memory layer 1 node exposing the subset of the eth
+// json-rpc namespace used by the sender. Every accepted transaction is
+// included in a new block and always succeeds.
+type simulatedL1 struct {
+	chainID *big.Int
+	signer  gethTypes.Signer
+
+	mu       sync.Mutex
+	number   uint64
+	nonces   map[common.Address]uint64
+	txs      []*gethTypes.Transaction
+	receipts map[common.Hash]*gethTypes.Receipt
+
+	server *httptest.Server
+}
+
+func newSimulatedL1(t *testing.T, chainID *big.Int) *simulatedL1 {
+	l1 := &simulatedL1{
+		chainID:  chainID,
+		signer:   gethTypes.LatestSignerForChainID(chainID),
+		nonces:   make(map[common.Address]uint64),
+		receipts: make(map[common.Hash]*gethTypes.Receipt),
+	}
+
+	rpcServer := rpc.NewServer()
+	assert.NoError(t, rpcServer.RegisterName("eth", &simulatedL1API{l1: l1}))
+	l1.server = httptest.NewServer(rpcServer)
+	return l1
+}
+
+// Endpoint returns the http endpoint of the simulated node.
+func (l1 *simulatedL1) Endpoint() string {
+	return l1.server.URL
+}
+
+// Close stops the simulated node.
+func (l1 *simulatedL1) Close() {
+	l1.server.Close()
+}
+
+// Transactions returns all transactions included so far.
+func (l1 *simulatedL1) Transactions() []*gethTypes.Transaction {
+	l1.mu.Lock()
+	defer l1.mu.Unlock()
+	return append([]*gethTypes.Transaction(nil), l1.txs...)
+}
+
+type simulatedL1API struct {
+	l1 *simulatedL1
+}
+
+func (api *simulatedL1API) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(api.l1.chainID)
+}
+
+func (api *simulatedL1API) BlockNumber() hexutil.Uint64 {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+	return hexutil.Uint64(api.l1.number)
+}
+
+func (api *simulatedL1API) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) *gethTypes.Header {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+	height := api.l1.number
+	if number >= 0 && uint64(number) < height {
+		height = uint64(number)
+	}
+	return &gethTypes.Header{
+		Number:     new(big.Int).SetUint64(height),
+		Difficulty: big.NewInt(0),
+		GasLimit:   30000000,
+		BaseFee:    big.NewInt(1),
+		Extra:      []byte{},
+	}
+}
+
+func (api *simulatedL1API) GetTransactionCount(address common.Address, blockNrOrHash rpc.BlockNumberOrHash) hexutil.Uint64 {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+	return hexutil.Uint64(api.l1.nonces[address])
+}
+
+func (api *simulatedL1API) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1000000000))
+}
+
+func (api *simulatedL1API) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1000000000))
+}
+
+func (api *simulatedL1API) EstimateGas(args map[string]interface{}) hexutil.Uint64 {
+	return hexutil.Uint64(1000000)
+}
+
+func (api *simulatedL1API) Call(args map[string]interface{}, blockNrOrHash rpc.BlockNumberOrHash) hexutil.Bytes {
+	return hexutil.Bytes{}
+}
+
+func (api *simulatedL1API) SendRawTransaction(input hexutil.Bytes) (common.Hash, error) {
+	tx := new(gethTypes.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	from, err := gethTypes.Sender(api.l1.signer, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+	if tx.Nonce() != api.l1.nonces[from] {
+		return common.Hash{}, errors.New("invalid nonce")
+	}
+	api.l1.nonces[from]++
+	api.l1.number++
+	api.l1.txs = append(api.l1.txs, tx)
+	api.l1.receipts[tx.Hash()] = &gethTypes.Receipt{
+		Status:            gethTypes.ReceiptStatusSuccessful,
+		CumulativeGasUsed: tx.Gas(),
+		Logs:              []*gethTypes.Log{},
+		TxHash:            tx.Hash(),
+		GasUsed:           tx.Gas(),
+		BlockNumber:       new(big.Int).SetUint64(api.l1.number),
+	}
+	return tx.Hash(), nil
+}
+
+func (api *simulatedL1API) GetTransactionReceipt(hash common.Hash) *gethTypes.Receipt {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+	return api.l1.receipts[hash]
+}
@@ -114,8 +114,16 @@ func (o *Chunk) InsertChunk(ctx context.Context, chunk *types.Chunk, dbTX ...*go
 	for _, block := range chunk.Blocks {
 		totalL2TxGas += block.Header.GasUsed
 		totalL2TxNum += block.NumL2Transactions()
-		totalL1CommitCalldataSize += block.EstimateL1CommitCalldataSize()
-		totalL1CommitGas += block.EstimateL1CommitGas()
+		blockL1CommitCalldataSize, err := block.EstimateL1CommitCalldataSize()
+		if err != nil {
+			return nil, fmt.Errorf("Chunk.InsertChunk error: %w", err)
+		}
+		totalL1CommitCalldataSize += blockL1CommitCalldataSize
+		blockL1CommitGas, err := block.EstimateL1CommitGas()
+		if err != nil {
+			return nil, fmt.Errorf("Chunk.InsertChunk error: %w", err)
+		}
+		totalL1CommitGas += blockL1CommitGas
 	}
 
 	numBlocks := len(chunk.Blocks)
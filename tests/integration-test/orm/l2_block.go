@@ -59,7 +59,7 @@ func (o *L2Block) InsertL2Blocks(ctx context.Context, blocks []*types.WrappedBlo
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)
 		}
 
-		txs, err := json.Marshal(block.Transactions)
+		txs, err := block.MarshalTransactions()
 		if err != nil {
 			log.Error("failed to marshal transactions", "hash", block.Header.Hash().String(), "err", err)
 			return fmt.Errorf("L2Block.InsertL2Blocks error: %w", err)